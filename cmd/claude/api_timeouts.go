@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+)
+
+// resolveAPITimeout returns the per-call cap from --api-timeout, falling back
+// to API_TIMEOUT_MS like Claude Code; zero defers to the provider config.
+func resolveAPITimeout(opts *options) time.Duration {
+	if opts != nil && opts.APITimeout > 0 {
+		return opts.APITimeout
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv("API_TIMEOUT_MS")))
+	if err != nil || value < 0 {
+		return 0
+	}
+	return time.Duration(value) * time.Millisecond
+}
+
+// apiTimeouts derives the client limits. A cap from the flag, environment, or
+// api_timeout_ms applies to every call; without one, non-streaming calls keep
+// timeout_ms and streams run as long as events keep arriving within
+// stream_idle_timeout_ms, so long generations are not cut off.
+func apiTimeouts(providerCfg *config.ProviderConfig, override time.Duration) openai.Timeouts {
+	timeouts := openai.Timeouts{Request: time.Duration(providerCfg.TimeoutMS) * time.Millisecond}
+	if override <= 0 {
+		override = time.Duration(providerCfg.APITimeoutMS) * time.Millisecond
+	}
+	if override > 0 {
+		timeouts.Request = override
+		timeouts.Stream = override
+	}
+	if providerCfg.StreamIdleTimeoutMS > 0 {
+		timeouts.StreamIdle = time.Duration(providerCfg.StreamIdleTimeoutMS) * time.Millisecond
+	}
+	return timeouts
+}
+
+// resolveTurnTimeout returns the turn deadline from --turn-timeout or turn_timeout_ms.
+func resolveTurnTimeout(opts *options, providerCfg *config.ProviderConfig) time.Duration {
+	if opts != nil && opts.TurnTimeout > 0 {
+		return opts.TurnTimeout
+	}
+	return time.Duration(providerCfg.TurnTimeoutMS) * time.Millisecond
+}
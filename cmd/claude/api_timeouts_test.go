@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestAPITimeouts verifies the defaults for each call kind and the override order.
+func TestAPITimeouts(testingHandle *testing.T) {
+	// Arrange
+	providerCfg := &config.ProviderConfig{TimeoutMS: 600000, StreamIdleTimeoutMS: 120000, TurnTimeoutMS: 300000}
+	testingHandle.Setenv("API_TIMEOUT_MS", "45000")
+
+	// Act
+	defaults := apiTimeouts(providerCfg, 0)
+	fromEnv := apiTimeouts(providerCfg, resolveAPITimeout(&options{}))
+	fromFlag := apiTimeouts(providerCfg, resolveAPITimeout(&options{APITimeout: 90 * time.Second}))
+	providerCfg.APITimeoutMS = 30000
+	providerCfg.StreamIdleTimeoutMS = -1
+	fromConfig := apiTimeouts(providerCfg, 0)
+
+	// Assert
+	testutil.RequireEqual(testingHandle, defaults, openai.Timeouts{Request: 10 * time.Minute, StreamIdle: 2 * time.Minute}, "streams have no total cap by default")
+	testutil.RequireEqual(testingHandle, fromEnv, openai.Timeouts{Request: 45 * time.Second, Stream: 45 * time.Second, StreamIdle: 2 * time.Minute}, "API_TIMEOUT_MS")
+	testutil.RequireEqual(testingHandle, fromFlag, openai.Timeouts{Request: 90 * time.Second, Stream: 90 * time.Second, StreamIdle: 2 * time.Minute}, "--api-timeout")
+	testutil.RequireEqual(testingHandle, fromConfig, openai.Timeouts{Request: 30 * time.Second, Stream: 30 * time.Second}, "api_timeout_ms without an idle limit")
+	testutil.RequireEqual(testingHandle, resolveTurnTimeout(&options{}, providerCfg), 5*time.Minute, "turn_timeout_ms")
+	testutil.RequireEqual(testingHandle, resolveTurnTimeout(&options{TurnTimeout: time.Minute}, providerCfg), time.Minute, "--turn-timeout")
+}
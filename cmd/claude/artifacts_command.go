@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/streamjson"
+	"github.com/spf13/cobra"
+)
+
+// artifactsCommand lists a session's artifacts and copies them out for automation.
+func artifactsCommand() *cobra.Command {
+	var (
+		jsonMode   bool
+		extractDir string
+	)
+	cmd := &cobra.Command{
+		Use:   "artifacts <session-id> [name...]",
+		Short: "List or extract a session's artifacts",
+		Long: "List the files kept in a session's artifacts directory: full tool output that was truncated, saved Python figures, and files commands wrote to $OPENCLAUDE_ARTIFACTS_DIR.\n" +
+			"With --extract, copy them (or only the named ones) into a directory.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := session.NewStore()
+			if err != nil {
+				return err
+			}
+			sessionID, err := resolveViewSession(store, args[:1])
+			if err != nil {
+				return err
+			}
+			artifacts, err := selectArtifacts(store, sessionID, args[1:])
+			if err != nil {
+				return err
+			}
+			if extractDir != "" {
+				for _, artifact := range artifacts {
+					target, err := extractArtifact(artifact, extractDir)
+					if err != nil {
+						return err
+					}
+					if !jsonMode {
+						fmt.Fprintln(cmd.OutOrStdout(), target)
+					}
+				}
+				if !jsonMode {
+					return nil
+				}
+			}
+			if jsonMode {
+				return writeArtifactsJSON(cmd.OutOrStdout(), artifacts)
+			}
+			return writeArtifactsTable(cmd.OutOrStdout(), artifacts)
+		},
+	}
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print artifacts as a JSON array")
+	cmd.Flags().StringVar(&extractDir, "extract", "", "Copy the artifacts into this directory")
+	return cmd
+}
+
+// selectArtifacts lists a session's artifacts, keeping only names when given.
+func selectArtifacts(store *session.Store, sessionID string, names []string) ([]session.Artifact, error) {
+	artifacts, err := store.ListArtifacts(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return artifacts, nil
+	}
+	byName := make(map[string]session.Artifact, len(artifacts))
+	for _, artifact := range artifacts {
+		byName[artifact.Name] = artifact
+	}
+	selected := make([]session.Artifact, 0, len(names))
+	for _, name := range names {
+		artifact, ok := byName[filepath.ToSlash(name)]
+		if !ok {
+			return nil, fmt.Errorf("session %s has no artifact %q", sessionID, name)
+		}
+		selected = append(selected, artifact)
+	}
+	return selected, nil
+}
+
+// extractArtifact copies an artifact into dir, keeping its relative name, and
+// returns the written path.
+func extractArtifact(artifact session.Artifact, dir string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(artifact.Name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("create extract dir: %w", err)
+	}
+	data, err := os.ReadFile(artifact.Path)
+	if err != nil {
+		return "", fmt.Errorf("read artifact: %w", err)
+	}
+	// Artifacts can hold raw command output, so keep the copies private.
+	if err := os.WriteFile(target, data, 0o600); err != nil {
+		return "", fmt.Errorf("write artifact: %w", err)
+	}
+	return target, nil
+}
+
+// writeArtifactsJSON prints artifacts as an indented JSON array.
+func writeArtifactsJSON(out io.Writer, artifacts []session.Artifact) error {
+	if artifacts == nil {
+		artifacts = []session.Artifact{}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(artifacts)
+}
+
+// writeArtifactsTable prints artifacts as an aligned table.
+func writeArtifactsTable(out io.Writer, artifacts []session.Artifact) error {
+	if len(artifacts) == 0 {
+		_, err := fmt.Fprintln(out, "No artifacts found.")
+		return err
+	}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tSIZE\tMODIFIED\tPATH")
+	for _, artifact := range artifacts {
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\n", artifact.Name, artifact.SizeBytes, formatSessionTime(artifact.ModifiedAt), artifact.Path)
+	}
+	return writer.Flush()
+}
+
+// resultArtifacts lists a session's artifacts for json and stream-json results.
+// Listing failures are ignored so a result is always emitted.
+func resultArtifacts(sessionID string) []streamjson.Artifact {
+	store, err := session.NewStore()
+	if err != nil || sessionID == "" {
+		return nil
+	}
+	artifacts, err := store.ListArtifacts(sessionID)
+	if err != nil {
+		return nil
+	}
+	converted := make([]streamjson.Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		converted = append(converted, streamjson.Artifact{Name: artifact.Name, Path: artifact.Path, SizeBytes: artifact.SizeBytes})
+	}
+	return converted
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// artifactsHome points the session store at a temp home with one artifact for s1.
+func artifactsHome(testingHandle *testing.T) *session.Store {
+	testingHandle.Setenv("HOME", testingHandle.TempDir())
+	store, err := session.NewStore()
+	testutil.RequireNoError(testingHandle, err, "new store")
+	_, err = store.WriteArtifact("s1", "report.md", []byte("# report\n"))
+	testutil.RequireNoError(testingHandle, err, "write artifact")
+	return store
+}
+
+// TestArtifactsCommand verifies listing and extracting a session's artifacts.
+func TestArtifactsCommand(testingHandle *testing.T) {
+	store := artifactsHome(testingHandle)
+
+	cmd := artifactsCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"s1", "--json"})
+	testutil.RequireNoError(testingHandle, cmd.Execute(), "list json")
+	var listed []session.Artifact
+	testutil.RequireNoError(testingHandle, json.Unmarshal(out.Bytes(), &listed), "decode list")
+	testutil.RequireEqual(testingHandle, len(listed), 1, "one artifact")
+	testutil.RequireEqual(testingHandle, listed[0].Path, filepath.Join(store.ArtifactsDir("s1"), "report.md"), "artifact path")
+	testutil.RequireEqual(testingHandle, listed[0].SizeBytes, int64(9), "artifact size")
+
+	extractDir := testingHandle.TempDir()
+	cmd = artifactsCommand()
+	out.Reset()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"s1", "report.md", "--extract", extractDir})
+	testutil.RequireNoError(testingHandle, cmd.Execute(), "extract")
+	data, err := os.ReadFile(filepath.Join(extractDir, "report.md"))
+	testutil.RequireNoError(testingHandle, err, "read extracted")
+	testutil.RequireEqual(testingHandle, string(data), "# report\n", "extracted content")
+	testutil.RequireTrue(testingHandle, strings.Contains(out.String(), extractDir), "prints extracted paths")
+
+	cmd = artifactsCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"s1", "missing.txt"})
+	testutil.RequireTrue(testingHandle, cmd.Execute() != nil, "unknown artifact")
+	cmd = artifactsCommand()
+	cmd.SetArgs([]string{"../s1"})
+	testutil.RequireTrue(testingHandle, cmd.Execute() != nil, "invalid session id")
+}
+
+// TestResultEventListsArtifacts verifies result events carry the session's artifacts.
+func TestResultEventListsArtifacts(testingHandle *testing.T) {
+	artifactsHome(testingHandle)
+	result := &agent.RunResult{Final: llm.Message{Role: "assistant", Content: "done"}}
+
+	event := buildStreamJSONResultEvent(result, "s1", "model")
+	testutil.RequireEqual(testingHandle, len(event.Artifacts), 1, "artifacts listed")
+	testutil.RequireEqual(testingHandle, event.Artifacts[0].Name, "report.md", "artifact name")
+
+	event = buildStreamJSONResultEvent(result, "s2", "model")
+	encoded, err := json.Marshal(event)
+	testutil.RequireNoError(testingHandle, err, "encode event")
+	testutil.RequireTrue(testingHandle, !strings.Contains(string(encoded), "artifacts"), "omitted without artifacts")
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/keyring"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// defaultCredentialName is the keyring entry used when auth subcommands get no name.
+const defaultCredentialName = "default"
+
+// authCommand manages API keys kept in the OS keyring instead of the provider config.
+func authCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage API keys stored in the OS keyring",
+		Long: "Store API keys in the macOS Keychain, the Secret Service (libsecret), or the Windows Credential Manager.\n" +
+			"The provider config then names the entry with \"api_key_keyring\" instead of holding the key.",
+	}
+	cmd.AddCommand(authSetCommand())
+	cmd.AddCommand(authGetCommand())
+	cmd.AddCommand(authRemoveCommand())
+	return cmd
+}
+
+// authSetCommand stores an API key read from the terminal or stdin, never from arguments.
+func authSetCommand() *cobra.Command {
+	var (
+		use        bool
+		fromConfig bool
+	)
+	cmd := &cobra.Command{
+		Use:   "set [name]",
+		Short: "Store an API key in the keyring (read from the terminal or stdin)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := credentialName(args)
+			if err := keyring.ValidateName(name); err != nil {
+				return err
+			}
+			path := mustProviderPath()
+			var secret string
+			if fromConfig {
+				key, err := readConfigAPIKey(path)
+				if err != nil {
+					return err
+				}
+				secret = key
+			} else {
+				key, err := readSecret(cmd.InOrStdin(), cmd.ErrOrStderr(), fmt.Sprintf("API key for %s: ", name))
+				if err != nil {
+					return err
+				}
+				secret = key
+			}
+			if err := keyring.Set(name, secret); err != nil {
+				return fmt.Errorf("store API key: %w", err)
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Stored API key %q in the keyring.\n", name)
+			if !use && !fromConfig {
+				fmt.Fprintf(out, "Reference it from %s with \"api_key_keyring\": %q (and remove \"api_key\"), or rerun with --use.\n", path, name)
+				return nil
+			}
+			if err := useKeyringEntry(path, name); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Updated %s to read the key from the keyring.\n", path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&use, "use", false, "Point api_key_keyring in the provider config at this entry and remove api_key")
+	cmd.Flags().BoolVar(&fromConfig, "from-config", false, "Move the api_key from the provider config into the keyring (implies --use)")
+	return cmd
+}
+
+// authGetCommand shows a stored key, masked unless --show is given.
+func authGetCommand() *cobra.Command {
+	var show bool
+	cmd := &cobra.Command{
+		Use:   "get [name]",
+		Short: "Show a stored API key (masked unless --show)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := credentialName(args)
+			secret, err := keyring.Get(name)
+			if errors.Is(err, keyring.ErrNotFound) {
+				return fmt.Errorf("no API key named %q in the keyring", name)
+			}
+			if err != nil {
+				return err
+			}
+			if show {
+				fmt.Fprintln(cmd.OutOrStdout(), secret)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", name, maskSecret(secret))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&show, "show", false, "Print the full key")
+	return cmd
+}
+
+// authRemoveCommand deletes a stored key.
+func authRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove an API key from the keyring",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := credentialName(args)
+			err := keyring.Delete(name)
+			if errors.Is(err, keyring.ErrNotFound) {
+				return fmt.Errorf("no API key named %q in the keyring", name)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed API key %q from the keyring.\n", name)
+			return nil
+		},
+	}
+}
+
+// credentialName returns the name argument or the default entry.
+func credentialName(args []string) string {
+	if len(args) == 0 {
+		return defaultCredentialName
+	}
+	return args[0]
+}
+
+// readSecret reads one line without echo from a terminal, or the first line of piped input.
+func readSecret(in io.Reader, prompt io.Writer, label string) (string, error) {
+	if file, ok := in.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		fmt.Fprint(prompt, label)
+		data, err := term.ReadPassword(int(file.Fd()))
+		fmt.Fprintln(prompt)
+		if err != nil {
+			return "", fmt.Errorf("read API key: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read API key: %w", err)
+	}
+	secret := strings.TrimSpace(line)
+	if secret == "" {
+		return "", errors.New("no API key given on stdin")
+	}
+	return secret, nil
+}
+
+// maskSecret keeps the first and last four characters of long secrets.
+func maskSecret(secret string) string {
+	if len(secret) <= 12 {
+		return strings.Repeat("*", len(secret))
+	}
+	return fmt.Sprintf("%s…%s (%d characters)", secret[:4], secret[len(secret)-4:], len(secret))
+}
+
+// readConfigAPIKey returns the api_key stored in the provider config file.
+func readConfigAPIKey(path string) (string, error) {
+	fields, err := readConfigFields(path)
+	if err != nil {
+		return "", err
+	}
+	var key string
+	if raw, ok := fields["api_key"]; ok {
+		if err := json.Unmarshal(raw, &key); err != nil {
+			return "", fmt.Errorf("parse api_key in %s: %w", path, err)
+		}
+	}
+	if key == "" {
+		return "", fmt.Errorf("%s has no api_key to move", path)
+	}
+	return key, nil
+}
+
+// useKeyringEntry rewrites the provider config to reference the keyring entry
+// in place of api_key.
+func useKeyringEntry(path string, name string) error {
+	fields, err := readConfigFields(path)
+	if err != nil {
+		return err
+	}
+	delete(fields, "api_key")
+	reference, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	fields["api_key_keyring"] = reference
+	return writeConfigFields(path, fields)
+}
+
+// readConfigFields reads the provider config as raw top-level fields so a
+// rewrite keeps every other setting as written.
+func readConfigFields(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read provider config: %w", err)
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("parse provider config: %w", err)
+	}
+	return fields, nil
+}
+
+// writeConfigFields atomically replaces the provider config, keeping it at 0600.
+func writeConfigFields(path string, fields map[string]json.RawMessage) error {
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode provider config: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), ".config-*.json")
+	if err != nil {
+		return fmt.Errorf("write provider config: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(append(data, '\n')); err != nil {
+		temp.Close()
+		return fmt.Errorf("write provider config: %w", err)
+	}
+	if err := temp.Chmod(0o600); err != nil {
+		temp.Close()
+		return fmt.Errorf("write provider config: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("write provider config: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("write provider config: %w", err)
+	}
+	return nil
+}
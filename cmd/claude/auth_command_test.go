@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/keyring"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestAuthCommandMovesKeyIntoKeyring verifies set --from-config, get, and remove.
+func TestAuthCommandMovesKeyIntoKeyring(testingHandle *testing.T) {
+	// Arrange: a provider config holding a plain key.
+	testingHandle.Cleanup(keyring.UseMemory())
+	home := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".openclaude", "config.json")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Dir(configPath), 0o700), "create config dir")
+	raw := `{"api_base_url": "http://localhost", "api_key": "sk-test-0123456789", "default_model": "m"}`
+	testutil.RequireNoError(testingHandle, os.WriteFile(configPath, []byte(raw), 0o600), "write config")
+	run := func(stdin string, args ...string) (string, error) {
+		cmd := authCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetIn(strings.NewReader(stdin))
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	// Act
+	_, err := run("", "set", "--from-config")
+	testutil.RequireNoError(testingHandle, err, "set --from-config")
+
+	// Assert: the key moved and the config only references it.
+	secret, err := keyring.Get(defaultCredentialName)
+	testutil.RequireNoError(testingHandle, err, "keyring get")
+	testutil.RequireEqual(testingHandle, secret, "sk-test-0123456789", "stored key")
+	data, err := os.ReadFile(configPath)
+	testutil.RequireNoError(testingHandle, err, "read config")
+	var fields map[string]any
+	testutil.RequireNoError(testingHandle, json.Unmarshal(data, &fields), "parse config")
+	_, hasKey := fields["api_key"]
+	testutil.RequireTrue(testingHandle, !hasKey && fields["api_key_keyring"] == "default" && fields["default_model"] == "m", string(data))
+	info, err := os.Stat(configPath)
+	testutil.RequireNoError(testingHandle, err, "stat config")
+	testutil.RequireEqual(testingHandle, info.Mode().Perm(), os.FileMode(0o600), "config mode")
+
+	output, err := run("", "get")
+	testutil.RequireNoError(testingHandle, err, "get")
+	testutil.RequireEqual(testingHandle, output, "default: sk-t…6789 (18 characters)\n", "masked key")
+	_, err = run("sk-other\n", "set", "other")
+	testutil.RequireNoError(testingHandle, err, "set from stdin")
+	output, err = run("", "get", "other", "--show")
+	testutil.RequireNoError(testingHandle, err, "get --show")
+	testutil.RequireEqual(testingHandle, output, "sk-other\n", "shown key")
+	_, err = run("", "remove", "other")
+	testutil.RequireNoError(testingHandle, err, "remove")
+	_, err = run("", "get", "other")
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), `no API key named "other"`), "removed key")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+)
+
+// bashHeredoc is a here-document opened by "<<" whose body is still being typed.
+type bashHeredoc struct {
+	// delimiter is the word that ends the body.
+	delimiter string
+	// stripTabs reports "<<-", which ignores leading tabs on body lines.
+	stripTabs bool
+}
+
+// bashCommandIncomplete reports whether a bash-mode command needs more lines:
+// its last line ends with a backslash continuation, or a here-document has no
+// terminating delimiter yet. Enter then starts a new line instead of running it.
+func bashCommandIncomplete(command string) bool {
+	if len(openHeredocs(command)) > 0 {
+		return true
+	}
+	lines := strings.Split(command, "\n")
+	last := strings.TrimRight(lines[len(lines)-1], " \t")
+	trailing := len(last) - len(strings.TrimRight(last, "\\"))
+	return trailing%2 == 1
+}
+
+// openHeredocs returns the here-documents in command whose delimiter line has
+// not been typed yet, in the order bash reads their bodies.
+func openHeredocs(command string) []bashHeredoc {
+	var pending []bashHeredoc
+	for _, line := range strings.Split(command, "\n") {
+		if len(pending) > 0 {
+			body := line
+			if pending[0].stripTabs {
+				body = strings.TrimLeft(body, "\t")
+			}
+			if body == pending[0].delimiter {
+				pending = pending[1:]
+			}
+			continue
+		}
+		pending = append(pending, scanHeredocs(line)...)
+	}
+	return pending
+}
+
+// scanHeredocs finds "<<" and "<<-" redirections in one line of shell, skipping
+// quoted text, comments, and "<<<" here-strings.
+func scanHeredocs(line string) []bashHeredoc {
+	var found []bashHeredoc
+	var quote byte
+	for index := 0; index < len(line); index++ {
+		char := line[index]
+		switch {
+		case quote != 0:
+			if char == '\\' && quote == '"' {
+				index++
+			} else if char == quote {
+				quote = 0
+			}
+		case char == '\\':
+			index++
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '#' && (index == 0 || line[index-1] == ' ' || line[index-1] == '\t'):
+			return found
+		case strings.HasPrefix(line[index:], "<<<"):
+			index += 2
+		case strings.HasPrefix(line[index:], "<<"):
+			index += 2
+			heredoc := bashHeredoc{}
+			if index < len(line) && line[index] == '-' {
+				heredoc.stripTabs = true
+				index++
+			}
+			for index < len(line) && (line[index] == ' ' || line[index] == '\t') {
+				index++
+			}
+			word, next := heredocWord(line, index)
+			if word != "" {
+				heredoc.delimiter = word
+				found = append(found, heredoc)
+			}
+			index = next - 1
+		}
+	}
+	return found
+}
+
+// heredocWord reads the delimiter word starting at index, removing quotes and
+// backslashes the way bash does, and returns it with the index after it.
+func heredocWord(line string, index int) (string, int) {
+	var word strings.Builder
+	var quote byte
+	for ; index < len(line); index++ {
+		char := line[index]
+		if quote != 0 {
+			if char == quote {
+				quote = 0
+			} else {
+				word.WriteByte(char)
+			}
+			continue
+		}
+		switch char {
+		case '\'', '"':
+			quote = char
+		case '\\':
+			if index+1 < len(line) {
+				index++
+				word.WriteByte(line[index])
+			}
+		case ' ', '\t', ';', '|', '&', '<', '>', '(', ')':
+			return word.String(), index
+		default:
+			word.WriteByte(char)
+		}
+	}
+	return word.String(), index
+}
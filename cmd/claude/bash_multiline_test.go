@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestBashCommandIncomplete verifies heredocs and backslash continuations keep
+// bash mode composing, and finished commands run.
+func TestBashCommandIncomplete(testingHandle *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    bool
+	}{
+		{name: "single line", command: "ls -la", want: false},
+		{name: "continuation", command: "go test \\", want: true},
+		{name: "continued then finished", command: "go test \\\n  ./...", want: false},
+		{name: "escaped backslash", command: "echo \\\\", want: false},
+		{name: "open heredoc", command: "cat <<EOF > notes.txt", want: true},
+		{name: "heredoc body", command: "cat <<'EOF'\nhello $USER", want: true},
+		{name: "closed heredoc", command: "cat <<'EOF'\nhello\nEOF", want: false},
+		{name: "indented delimiter without dash", command: "cat <<EOF\nhi\n\tEOF", want: true},
+		{name: "tab-stripped delimiter", command: "cat <<-END\n\thi\n\tEND", want: false},
+		{name: "two heredocs", command: "diff <(cat <<A\nx\nA\n) <(cat <<\"B\"\ny", want: true},
+		{name: "here-string", command: "grep x <<< \"$text\"", want: false},
+		{name: "quoted operator", command: "echo '<<EOF'", want: false},
+		{name: "comment", command: "ls # <<EOF", want: false},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			testutil.RequireEqual(testingHandle, bashCommandIncomplete(tt.command), tt.want, tt.command)
+		})
+	}
+}
+
+// TestBashModeComposesHeredoc verifies Enter adds lines until the heredoc is
+// closed, and the command is kept verbatim in history and the chat view.
+func TestBashModeComposesHeredoc(testingHandle *testing.T) {
+	// Arrange.
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	model.setInputMode(tuiInputBash)
+	model.input.SetValue("cat <<'EOF' > notes.txt")
+	model.input.CursorEnd()
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// Act.
+	model.Update(enter)
+	afterOpen := model.input.Value()
+	model.input.InsertString("  indented $HOME\nEOF")
+	model.Update(enter)
+
+	// Assert.
+	command := "cat <<'EOF' > notes.txt\n  indented $HOME\nEOF"
+	testutil.RequireEqual(testingHandle, afterOpen, "cat <<'EOF' > notes.txt\n", "enter starts a new line")
+	testutil.RequireEqual(testingHandle, model.input.Value(), "", "closed heredoc is submitted")
+	testutil.RequireEqual(testingHandle, model.inputHistory[len(model.inputHistory)-1], "!"+command, "history keeps the command verbatim")
+	rendered := model.renderUserBashMessage(tuiMessage{Kind: tuiMessageUserBash, Content: command})
+	for _, line := range []string{"cat <<'EOF' > notes.txt", "  indented $HOME", "EOF"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(rendered, line), rendered)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestBuildToolsRunPythonOptIn verifies RunPython stays out of the default set until enabled.
+func TestBuildToolsRunPythonOptIn(testingHandle *testing.T) {
+	tests := []struct {
+		name string
+		opts *options
+		want bool
+	}{
+		{name: "default set", opts: &options{}, want: false},
+		{name: "enabled in config", opts: &options{ProviderConfig: &config.ProviderConfig{Python: config.PythonConfig{Enabled: true}}}, want: true},
+		{name: "named in tools", opts: &options{Tools: []string{"Read,run_python"}}, want: true},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			_, names, err := buildTools(tt.opts, nil, "", nil, "", tools.PermissionDefault)
+			testutil.RequireNoError(testingHandle, err, "build tools")
+			testutil.RequireEqual(testingHandle, slices.Contains(names, tools.RunPythonToolName), tt.want, "RunPython offered")
+		})
+	}
+}
+
+// TestBuildToolsOSSandbox verifies the sandbox setting confines Bash and resolves writable dirs.
+func TestBuildToolsOSSandbox(testingHandle *testing.T) {
+	runner, _, err := buildTools(&options{}, nil, "/work", nil, "", tools.PermissionDefault)
+	testutil.RequireNoError(testingHandle, err, "build tools")
+	testutil.RequireTrue(testingHandle, runner.Tools["Bash"].(*tools.BashTool).OSSandbox == nil, "unconfined by default")
+
+	testingHandle.Setenv("HOME", "/home/dev")
+	settings := &config.Settings{Sandbox: config.SandboxSettings{Enabled: true, DisableNetwork: true, WritableDirs: []string{"build", "~/.cache", "/opt/out"}}}
+	runner, _, err = buildTools(&options{ClaudeSettings: settings}, nil, "/work", nil, "", tools.PermissionDefault)
+	testutil.RequireNoError(testingHandle, err, "build tools")
+	osSandbox := runner.Tools["Bash"].(*tools.BashTool).OSSandbox
+	testutil.RequireEqual(testingHandle, osSandbox, &tools.OSSandbox{WritableDirs: []string{"/work/build", "/home/dev/.cache", "/opt/out"}, DisableNetwork: true}, "sandbox")
+}
+
+// TestBuildToolsAppliesSettingsOverrides verifies settings toolOverrides change what the model sees, not how tools run.
+func TestBuildToolsAppliesSettingsOverrides(testingHandle *testing.T) {
+	// Arrange
+	schema := map[string]any{"type": "object", "properties": map[string]any{"command": map[string]any{"type": "string"}}}
+	settings := &config.Settings{ToolOverrides: map[string]config.ToolOverride{
+		"bash": {Description: "Last resort; prefer Grep, Glob, and Read.", Schema: schema},
+		"Read": {Description: "Read one file."},
+	}}
+
+	// Act
+	runner, _, err := buildTools(&options{ClaudeSettings: settings}, nil, "/work", nil, "", tools.PermissionDefault)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "build tools")
+	specs := map[string]llm.ToolFunction{}
+	for _, spec := range runner.ToolSpecs() {
+		specs[spec.Function.Name] = spec.Function
+	}
+	testutil.RequireEqual(testingHandle, specs["Bash"].Description, "Last resort; prefer Grep, Glob, and Read.", "Bash description")
+	testutil.RequireEqual(testingHandle, specs["Bash"].Parameters, schema, "Bash schema")
+	testutil.RequireEqual(testingHandle, specs["Read"].Description, "Read one file.", "Read description")
+	testutil.RequireEqual(testingHandle, specs["Read"].Parameters, (&tools.ReadTool{}).Schema(), "Read schema kept")
+	testutil.RequireEqual(testingHandle, specs["Glob"].Description, (&tools.GlobTool{}).Description(), "Glob untouched")
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/project"
+	"github.com/openclaude/openclaude/internal/session"
+)
+
+// maxListedRestoredFiles caps how many restored paths /restore prints.
+const maxListedRestoredFiles = 10
+
+// createCheckpoint handles /checkpoint [name]: without a name it lists the
+// session's checkpoints, otherwise it saves the conversation and, inside a git
+// work tree, the files under that name.
+func (m *tuiModel) createCheckpoint(args string) string {
+	if m.store == nil {
+		return "Checkpoints need session storage."
+	}
+	name := strings.TrimSpace(args)
+	if name == "" {
+		return m.formatCheckpoints()
+	}
+	if err := session.ValidateCheckpointName(name); err != nil {
+		return err.Error()
+	}
+	checkpoint := session.Checkpoint{
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+		Messages:  append([]llm.Message(nil), m.history...),
+	}
+	snapshot, snapshotErr := project.SnapshotWorkTree(context.Background(), m.promptCWD())
+	if snapshotErr == nil {
+		checkpoint.WorkTree = snapshot.Root
+		checkpoint.Tree = snapshot.Tree
+	}
+	if err := m.store.SaveCheckpoint(m.sessionID, checkpoint); err != nil {
+		return fmt.Sprintf("Checkpoint failed: %v", err)
+	}
+	saved := fmt.Sprintf("Checkpoint %s saved: %d message(s)", name, countConversationMessages(checkpoint.Messages))
+	if snapshotErr != nil {
+		saved += fmt.Sprintf("; files not captured (%v)", snapshotErr)
+	} else {
+		saved += " and the files in " + snapshot.Root
+	}
+	return saved + fmt.Sprintf(". Run /restore %s [conversation|files] to go back.", name)
+}
+
+// formatCheckpoints lists the session's checkpoints, oldest first.
+func (m *tuiModel) formatCheckpoints() string {
+	checkpoints, err := m.store.ListCheckpoints(m.sessionID)
+	if err != nil {
+		return fmt.Sprintf("List checkpoints: %v", err)
+	}
+	if len(checkpoints) == 0 {
+		return "No checkpoints yet. Run /checkpoint <name> to save the conversation and files."
+	}
+	var builder strings.Builder
+	builder.WriteString("Checkpoints:")
+	for _, checkpoint := range checkpoints {
+		files := "conversation only"
+		if checkpoint.Tree != "" {
+			files = "conversation and files"
+		}
+		fmt.Fprintf(&builder, "\n  %s  %s  %d message(s), %s",
+			checkpoint.Name, checkpoint.CreatedAt.Local().Format("2006-01-02 15:04"), countConversationMessages(checkpoint.Messages), files)
+	}
+	builder.WriteString("\nRun /restore <name> [conversation|files] to go back.")
+	return builder.String()
+}
+
+// restoreCheckpoint handles /restore <name> [conversation|files|both].
+func (m *tuiModel) restoreCheckpoint(args string) string {
+	const usage = "Usage: /restore <name> [conversation|files|both]"
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		if m.store == nil {
+			return usage
+		}
+		return m.formatCheckpoints()
+	}
+	if len(fields) > 2 {
+		return usage
+	}
+	scope := "both"
+	if len(fields) == 2 {
+		scope = strings.ToLower(fields[1])
+	}
+	if scope != "both" && scope != "conversation" && scope != "files" {
+		return usage
+	}
+	if m.store == nil {
+		return "Checkpoints need session storage."
+	}
+	name := fields[0]
+	checkpoint, err := m.store.LoadCheckpoint(m.sessionID, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Sprintf("No checkpoint named %s. Run /checkpoint to list checkpoints.", name)
+	}
+	if err != nil {
+		return fmt.Sprintf("Restore failed: %v", err)
+	}
+
+	var report []string
+	if scope != "conversation" {
+		if checkpoint.Tree == "" {
+			if scope == "files" {
+				return fmt.Sprintf("Checkpoint %s has no file snapshot; only the conversation can be restored.", name)
+			}
+			report = append(report, "Files were not captured by this checkpoint and were left as they are.")
+		} else {
+			restored, err := project.RestoreWorkTree(context.Background(), project.WorkTreeSnapshot{Root: checkpoint.WorkTree, Tree: checkpoint.Tree})
+			if err != nil {
+				return fmt.Sprintf("Restore failed: %v", err)
+			}
+			report = append(report, formatRestoredFiles(checkpoint.WorkTree, restored))
+		}
+	}
+	if scope != "files" {
+		m.restoreConversation(checkpoint.Messages)
+		report = append([]string{fmt.Sprintf("Conversation restored to %d message(s).", countConversationMessages(checkpoint.Messages))}, report...)
+	}
+	return fmt.Sprintf("Restored checkpoint %s.\n%s", name, strings.Join(report, "\n"))
+}
+
+// restoreConversation replaces the history with a checkpoint's messages while
+// keeping the current system prompt, mirroring a fork from the message selector.
+func (m *tuiModel) restoreConversation(messages []llm.Message) {
+	restored := append([]llm.Message(nil), messages...)
+	if len(restored) > 0 && restored[0].Role == "system" {
+		restored = restored[1:]
+	}
+	m.history = ensureSystem(restored, m.systemPrompt)
+	// The session log keeps the conversation as it was; only new messages follow.
+	m.persisted = len(m.history)
+	// The checkpoint holds exactly what the model saw, so older pages no longer apply.
+	m.historyCursor = sessionHistoryCursor{}
+	m.pendingPermission = nil
+	m.streamBuffer.Reset()
+	m.toolLines = nil
+	m.toolView.SetContent("No tool activity yet.")
+	m.bootstrapHistory()
+}
+
+// formatRestoredFiles summarizes the paths a file restore touched.
+func formatRestoredFiles(root string, paths []string) string {
+	if len(paths) == 0 {
+		return "Files in " + root + " already matched the checkpoint."
+	}
+	lines := []string{fmt.Sprintf("Restored %d file(s) in %s:", len(paths), root)}
+	for index, path := range paths {
+		if index == maxListedRestoredFiles {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(paths)-index))
+			break
+		}
+		lines = append(lines, "  "+path)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// countConversationMessages counts messages other than the system prompt.
+func countConversationMessages(messages []llm.Message) int {
+	count := 0
+	for _, message := range messages {
+		if message.Role != "system" {
+			count++
+		}
+	}
+	return count
+}
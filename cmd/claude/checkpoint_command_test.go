@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestCheckpointRestoresConversationAndFiles verifies /checkpoint and /restore with each scope.
+func TestCheckpointRestoresConversationAndFiles(testingHandle *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		testingHandle.Skip("git not installed")
+	}
+	// Arrange: a git workspace and a session with one exchange.
+	root := testingHandle.TempDir()
+	testingHandle.Chdir(root)
+	cmd := exec.Command("git", "init", "--quiet")
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL="+filepath.Join(root, ".gitconfig-none"), "GIT_CONFIG_NOSYSTEM=1")
+	output, err := cmd.CombinedOutput()
+	testutil.RequireNoError(testingHandle, err, "git init: "+string(output))
+	mainPath := filepath.Join(root, "main.go")
+	testutil.RequireNoError(testingHandle, os.WriteFile(mainPath, []byte("package main\n"), 0o644), "write main.go")
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	history := []llm.Message{{Role: "user", Content: "refactor main"}, {Role: "assistant", Content: "Sure."}}
+	model := newTUIModel(&options{}, nil, history, sessionHistoryCursor{}, "sys", "model", "session", store)
+
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(model.createCheckpoint(""), "No checkpoints yet."), "empty list")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(model.createCheckpoint("../bad"), "invalid checkpoint name"), "bad name")
+	saved := model.createCheckpoint("before-refactor")
+	testutil.RequireTrue(testingHandle, strings.Contains(saved, "Checkpoint before-refactor saved: 2 message(s) and the files in"), saved)
+
+	// Act: change files and the conversation, then restore only the files.
+	testutil.RequireNoError(testingHandle, os.WriteFile(mainPath, []byte("package broken\n"), 0o644), "edit main.go")
+	model.history = append(model.history, llm.Message{Role: "user", Content: "now break it"})
+	restored := model.restoreCheckpoint("before-refactor files")
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.Contains(restored, "Restored 1 file(s) in "), restored)
+	data, err := os.ReadFile(mainPath)
+	testutil.RequireNoError(testingHandle, err, "read main.go")
+	testutil.RequireEqual(testingHandle, string(data), "package main\n", "file restored")
+	testutil.RequireEqual(testingHandle, len(model.history), 4, "conversation kept for files scope")
+
+	restored = model.restoreCheckpoint("before-refactor conversation")
+	testutil.RequireTrue(testingHandle, strings.Contains(restored, "Conversation restored to 2 message(s)."), restored)
+	testutil.RequireEqual(testingHandle, len(model.history), 3, "history rewound")
+	testutil.RequireEqual(testingHandle, model.history[0], llm.Message{Role: "system", Content: "sys"}, "system prompt kept")
+	testutil.RequireEqual(testingHandle, len(model.chatMessages), 2, "chat rebuilt")
+
+	listing := model.createCheckpoint("")
+	testutil.RequireTrue(testingHandle, strings.Contains(listing, "before-refactor") && strings.Contains(listing, "2 message(s), conversation and files"), listing)
+	testutil.RequireEqual(testingHandle, model.restoreCheckpoint("missing"), "No checkpoint named missing. Run /checkpoint to list checkpoints.", "unknown")
+	testutil.RequireEqual(testingHandle, model.restoreCheckpoint("before-refactor everything"), "Usage: /restore <name> [conversation|files|both]", "bad scope")
+}
+
+// TestCheckpointOutsideGitKeepsConversationOnly verifies checkpoints still work without a repository.
+func TestCheckpointOutsideGitKeepsConversationOnly(testingHandle *testing.T) {
+	testingHandle.Chdir(testingHandle.TempDir())
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	model := newTUIModel(&options{}, nil, []llm.Message{{Role: "user", Content: "hi"}}, sessionHistoryCursor{}, "", "model", "session", store)
+
+	saved := model.createCheckpoint("start")
+	testutil.RequireTrue(testingHandle, strings.Contains(saved, "files not captured"), saved)
+	testutil.RequireEqual(testingHandle, model.restoreCheckpoint("start files"), "Checkpoint start has no file snapshot; only the conversation can be restored.", "files scope")
+	restored := model.restoreCheckpoint("start")
+	testutil.RequireTrue(testingHandle, strings.Contains(restored, "Files were not captured by this checkpoint"), restored)
+}
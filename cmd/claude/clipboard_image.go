@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clipboardToolTimeout bounds a clipboard tool run.
+const clipboardToolTimeout = 5 * time.Second
+
+// errNoClipboardImage reports a clipboard without an image.
+var errNoClipboardImage = errors.New("no image on the clipboard")
+
+// clipboardImageMsg reports the outcome of reading a clipboard image.
+type clipboardImageMsg struct {
+	// Path is the saved PNG; empty on failure.
+	Path string
+	// Err explains why no image was saved.
+	Err error
+	// FallbackText pastes the clipboard as text when it holds no image.
+	FallbackText bool
+}
+
+// clipboardTool is a platform command that reads the clipboard image as PNG.
+type clipboardTool struct {
+	// Name is the executable.
+	Name string
+	// Args are its arguments.
+	Args []string
+	// Stdout marks tools that print the image instead of writing the target file.
+	Stdout bool
+}
+
+// clipboardImageTool picks the clipboard tool for a platform. Tools that
+// cannot print binary data write the PNG to target themselves. OSC 52 only
+// carries text, so images always come from a platform tool.
+func clipboardImageTool(goos string, getenv func(string) string, target string) clipboardTool {
+	switch goos {
+	case "darwin":
+		file := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(target)
+		return clipboardTool{Name: "osascript", Args: []string{
+			"-e", "set png to (the clipboard as «class PNGf»)",
+			"-e", `set out to open for access POSIX file "` + file + `" with write permission`,
+			"-e", "write png to out",
+			"-e", "close access out",
+		}}
+	case "windows":
+		file := strings.ReplaceAll(target, "'", "''")
+		script := "Add-Type -AssemblyName System.Windows.Forms; " +
+			"$image = [System.Windows.Forms.Clipboard]::GetImage(); " +
+			"if ($image -eq $null) { exit 1 }; " +
+			"$image.Save('" + file + "', [System.Drawing.Imaging.ImageFormat]::Png)"
+		return clipboardTool{Name: "powershell", Args: []string{"-NoProfile", "-STA", "-Command", script}}
+	}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		return clipboardTool{Name: "wl-paste", Args: []string{"--no-newline", "--type", "image/png"}, Stdout: true}
+	}
+	return clipboardTool{Name: "xclip", Args: []string{"-selection", "clipboard", "-target", "image/png", "-out"}, Stdout: true}
+}
+
+// clipboardImageDir holds saved clipboard images.
+func clipboardImageDir() string {
+	return filepath.Join(os.TempDir(), "openclaude-clipboard")
+}
+
+// saveClipboardImage saves the clipboard image as a PNG in dir and returns
+// its path. It returns errNoClipboardImage when the clipboard holds anything
+// else and names the missing tool when there is none to ask.
+func saveClipboardImage(dir string, toolFor func(target string) clipboardTool) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create clipboard image dir: %w", err)
+	}
+	file, err := os.CreateTemp(dir, "clipboard-*.png")
+	if err != nil {
+		return "", fmt.Errorf("create clipboard image: %w", err)
+	}
+	path := file.Name()
+	_ = file.Close()
+	if err := runClipboardTool(toolFor(path), path); err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || http.DetectContentType(data) != "image/png" {
+		_ = os.Remove(path)
+		return "", errNoClipboardImage
+	}
+	return path, nil
+}
+
+// runClipboardTool runs a clipboard tool, saving printed output to target.
+// Tools fail when the clipboard holds no image, so any failure other than a
+// missing tool means there is none.
+func runClipboardTool(tool clipboardTool, target string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clipboardToolTimeout)
+	defer cancel()
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, tool.Name, tool.Args...)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("pasting images needs %s on PATH", tool.Name)
+		}
+		return errNoClipboardImage
+	}
+	if !tool.Stdout {
+		return nil
+	}
+	return os.WriteFile(target, stdout.Bytes(), 0o600)
+}
+
+// pasteClipboardImage reads the clipboard image in the background.
+func (m *tuiModel) pasteClipboardImage(fallbackText bool) tea.Cmd {
+	return func() tea.Msg {
+		path, err := saveClipboardImage(clipboardImageDir(), func(target string) clipboardTool {
+			return clipboardImageTool(runtime.GOOS, os.Getenv, target)
+		})
+		return clipboardImageMsg{Path: path, Err: err, FallbackText: fallbackText}
+	}
+}
+
+// insertClipboardImage adds a saved clipboard image to the prompt as an
+// @-mention, which attaches it on submit. Without an image, ctrl+v falls back
+// to pasting the clipboard text and an empty paste does nothing.
+func (m *tuiModel) insertClipboardImage(msg clipboardImageMsg) tea.Cmd {
+	if msg.Err != nil {
+		if !errors.Is(msg.Err, errNoClipboardImage) {
+			m.inputHint = "Paste image: " + msg.Err.Error()
+		}
+		if msg.FallbackText {
+			return textarea.Paste
+		}
+		return nil
+	}
+	label := mentionLabel(msg.Path, m.promptCWD())
+	if strings.ContainsAny(label, " \t") {
+		label = `"` + label + `"`
+	}
+	if m.clipboardImages == nil {
+		m.clipboardImages = map[string]string{}
+	}
+	m.clipboardImages[msg.Path] = "@" + label
+	mention := "@" + label + " "
+	value := m.input.Value()
+	if value != "" && !strings.HasSuffix(value, " ") && !strings.HasSuffix(value, "\n") {
+		mention = " " + mention
+	}
+	m.input.SetValue(value + mention)
+	m.input.CursorEnd()
+	m.syncInputState()
+	m.inputHint = "Pasted clipboard image · attached when you submit"
+	return nil
+}
+
+// releaseClipboardImages removes the saved clipboard images mentioned in a
+// sent prompt. Images still waiting in the input are kept.
+func (m *tuiModel) releaseClipboardImages(sent string) {
+	for path, mention := range m.clipboardImages {
+		if strings.Contains(sent, mention) {
+			_ = os.Remove(path)
+			delete(m.clipboardImages, path)
+		}
+	}
+}
+
+// removeClipboardImages removes every saved clipboard image that was never
+// sent, when the TUI exits.
+func (m *tuiModel) removeClipboardImages() {
+	for path := range m.clipboardImages {
+		_ = os.Remove(path)
+	}
+	m.clipboardImages = nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestClipboardImageTool verifies each platform reads the clipboard with its own tool.
+func TestClipboardImageTool(testingHandle *testing.T) {
+	noEnv := func(string) string { return "" }
+	wayland := func(name string) string {
+		if name == "WAYLAND_DISPLAY" {
+			return "wayland-0"
+		}
+		return ""
+	}
+	mac := clipboardImageTool("darwin", noEnv, `/tmp/a "b".png`)
+	testutil.RequireEqual(testingHandle, mac.Name, "osascript", "macOS tool")
+	testutil.RequireTrue(testingHandle, strings.Contains(strings.Join(mac.Args, " "), `POSIX file "/tmp/a \"b\".png"`), "macOS writes the quoted target")
+	windows := clipboardImageTool("windows", noEnv, `C:\Temp\it's.png`)
+	testutil.RequireEqual(testingHandle, windows.Name, "powershell", "Windows tool")
+	testutil.RequireTrue(testingHandle, strings.Contains(windows.Args[len(windows.Args)-1], `'C:\Temp\it''s.png'`), "Windows writes the quoted target")
+	testutil.RequireEqual(testingHandle, clipboardImageTool("linux", wayland, "x").Name, "wl-paste", "Wayland tool")
+	testutil.RequireEqual(testingHandle, clipboardImageTool("linux", noEnv, "x").Name, "xclip", "X11 tool")
+	testutil.RequireTrue(testingHandle, clipboardImageTool("linux", noEnv, "x").Stdout, "xclip prints the image")
+}
+
+// TestSaveClipboardImage verifies PNG output is saved and anything else is discarded.
+func TestSaveClipboardImage(testingHandle *testing.T) {
+	if runtime.GOOS == "windows" {
+		testingHandle.Skip("uses a POSIX shell")
+	}
+	printing := func(output string) func(string) clipboardTool {
+		return func(string) clipboardTool {
+			return clipboardTool{Name: "sh", Args: []string{"-c", "printf '" + output + "'"}, Stdout: true}
+		}
+	}
+
+	testingHandle.Run("png", func(testingHandle *testing.T) {
+		path, err := saveClipboardImage(testingHandle.TempDir(), printing(`\211PNG\r\n\032\n`))
+		testutil.RequireNoError(testingHandle, err, "save image")
+		data, err := os.ReadFile(path)
+		testutil.RequireNoError(testingHandle, err, "read image")
+		testutil.RequireEqual(testingHandle, string(data), "\x89PNG\r\n\x1a\n", "image bytes")
+		testutil.RequireEqual(testingHandle, filepath.Ext(path), ".png", "extension")
+	})
+	testingHandle.Run("text", func(testingHandle *testing.T) {
+		dir := testingHandle.TempDir()
+		_, err := saveClipboardImage(dir, printing("hello"))
+		testutil.RequireTrue(testingHandle, errors.Is(err, errNoClipboardImage), "text is not an image")
+		entries, _ := os.ReadDir(dir)
+		testutil.RequireEqual(testingHandle, len(entries), 0, "temp file removed")
+	})
+	testingHandle.Run("missing tool", func(testingHandle *testing.T) {
+		_, err := saveClipboardImage(testingHandle.TempDir(), func(string) clipboardTool {
+			return clipboardTool{Name: "openclaude-no-such-clipboard-tool"}
+		})
+		testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "needs openclaude-no-such-clipboard-tool"), "missing tool named")
+	})
+}
+
+// TestInsertClipboardImage verifies a saved image becomes an @-mention and
+// ctrl+v falls back to a text paste without one.
+func TestInsertClipboardImage(testingHandle *testing.T) {
+	// Arrange
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	model.input.SetValue("describe")
+	path := filepath.Join(testingHandle.TempDir(), "clipboard-1.png")
+
+	// Act
+	model.insertClipboardImage(clipboardImageMsg{Path: path})
+	fallback := model.insertClipboardImage(clipboardImageMsg{Err: errNoClipboardImage, FallbackText: true})
+	ignored := model.insertClipboardImage(clipboardImageMsg{Err: errNoClipboardImage})
+
+	// Assert
+	testutil.RequireEqual(testingHandle, model.input.Value(), "describe @"+path+" ", "mention appended")
+	testutil.RequireTrue(testingHandle, fallback != nil, "ctrl+v pastes text without an image")
+	testutil.RequireTrue(testingHandle, ignored == nil, "empty paste without an image does nothing")
+}
+
+// TestClipboardImagesRemovedOnceSent verifies saved clipboard images are
+// deleted after the prompt mentioning them is sent, and the rest on exit.
+func TestClipboardImagesRemovedOnceSent(testingHandle *testing.T) {
+	// Arrange two pasted images.
+	dir := testingHandle.TempDir()
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	sent := filepath.Join(dir, "clipboard-1.png")
+	kept := filepath.Join(dir, "clipboard-12.png")
+	for _, path := range []string{sent, kept} {
+		testutil.RequireNoError(testingHandle, os.WriteFile(path, []byte("png"), 0o600), "write image")
+		model.insertClipboardImage(clipboardImageMsg{Path: path})
+	}
+
+	// Act.
+	model.releaseClipboardImages("describe @" + sent)
+	_, sentErr := os.Stat(sent)
+	_, keptErr := os.Stat(kept)
+	model.removeClipboardImages()
+	_, exitErr := os.Stat(kept)
+
+	// Assert.
+	testutil.RequireTrue(testingHandle, os.IsNotExist(sentErr), "sent image removed")
+	testutil.RequireNoError(testingHandle, keptErr, "unsent image kept")
+	testutil.RequireTrue(testingHandle, os.IsNotExist(exitErr), "unsent image removed on exit")
+}
+
+// TestLargePasteBecomesPlaceholder verifies a long bracketed paste is shown
+// as a placeholder and submitted in full.
+func TestLargePasteBecomesPlaceholder(testingHandle *testing.T) {
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	model.input.SetValue("see ")
+	model.input.CursorEnd()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("one\ntwo\nthree"), Paste: true})
+
+	testutil.RequireEqual(testingHandle, model.input.Value(), "see [Pasted text +2 lines] ", "placeholder shown")
+	testutil.RequireEqual(testingHandle, model.resolvePastedInput(model.input.Value()), "see one\ntwo\nthree", "full text submitted")
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+const (
+	// darkCodeTheme is the chroma style for code on dark backgrounds.
+	darkCodeTheme = "monokai"
+	// lightCodeTheme is the chroma style for code on light backgrounds.
+	lightCodeTheme = "github"
+)
+
+// codeStyle is how code blocks and diffs are colored, chosen from the
+// terminal's background and color support like the adaptive TUI colors.
+type codeStyle struct {
+	// Dark reports a dark terminal background.
+	Dark bool
+	// Theme is the chroma style; "" leaves code uncolored.
+	Theme string
+	// Formatter is the chroma formatter matching the terminal's colors.
+	Formatter string
+}
+
+// detectCodeStyle returns the code style for the current terminal.
+func detectCodeStyle() codeStyle {
+	return newCodeStyle(lipgloss.ColorProfile(), lipgloss.HasDarkBackground())
+}
+
+// newCodeStyle picks the chroma theme for the background and the formatter
+// for the color profile. Terminals without color get no theme.
+func newCodeStyle(profile termenv.Profile, dark bool) codeStyle {
+	style := codeStyle{Dark: dark}
+	switch profile {
+	case termenv.TrueColor:
+		style.Formatter = "terminal16m"
+	case termenv.ANSI256:
+		style.Formatter = "terminal256"
+	case termenv.ANSI:
+		style.Formatter = "terminal16"
+	default:
+		return style
+	}
+	style.Theme = lightCodeTheme
+	if dark {
+		style.Theme = darkCodeTheme
+	}
+	return style
+}
+
+// markdownStyles returns the glamour styles for the background with code
+// blocks colored by the chroma theme instead of glamour's built-in palette.
+func (s codeStyle) markdownStyles() ansi.StyleConfig {
+	if s.Theme == "" {
+		return styles.NoTTYStyleConfig
+	}
+	config := styles.LightStyleConfig
+	if s.Dark {
+		config = styles.DarkStyleConfig
+	}
+	config.CodeBlock.Chroma = nil
+	config.CodeBlock.Theme = s.Theme
+	return config
+}
+
+// Highlight colors code as language, detecting the language when it is
+// empty. Code comes back unchanged when colors are off or highlighting fails.
+func (s codeStyle) Highlight(code string, language string) string {
+	if s.Theme == "" {
+		return code
+	}
+	var builder strings.Builder
+	if err := quick.Highlight(&builder, code, detectCodeLanguage(language, code), s.Formatter, s.Theme); err != nil {
+		return code
+	}
+	// Reset colors before each line break rather than after it, so indenting
+	// the lines afterwards leaves the indentation uncolored.
+	highlighted := strings.ReplaceAll(builder.String(), "\n\x1b[0m", "\x1b[0m\n")
+	return strings.TrimSuffix(highlighted, "\n")
+}
+
+// codeFencePattern matches a fence line: indentation, the fence, and the info string.
+var codeFencePattern = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*(.*)$")
+
+// labelCodeFences gives unlabeled fenced code blocks a detected language, so
+// diffs and JSON are highlighted even when the model leaves the fence bare.
+func labelCodeFences(content string) string {
+	lines := strings.Split(content, "\n")
+	for index := 0; index < len(lines); index++ {
+		match := codeFencePattern.FindStringSubmatch(lines[index])
+		if match == nil || strings.Contains(match[3], "`") {
+			continue
+		}
+		opening, fence, info := index, match[2], strings.TrimSpace(match[3])
+		end := len(lines)
+		for index++; index < len(lines); index++ {
+			trimmed := strings.TrimSpace(lines[index])
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				end = index
+				break
+			}
+		}
+		if info != "" {
+			continue
+		}
+		if language := detectCodeLanguage("", strings.Join(lines[opening+1:end], "\n")); language != "" {
+			lines[opening] = match[1] + fence + language
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// detectCodeLanguage returns the lexer for a fence's info string, which may
+// be a language or a file name, or guesses one from the code. It returns ""
+// when nothing matches.
+func detectCodeLanguage(info string, code string) string {
+	if fields := strings.Fields(info); len(fields) > 0 {
+		if lexer := lexers.Get(fields[0]); lexer != nil {
+			return lexer.Config().Name
+		}
+		return ""
+	}
+	trimmed := strings.TrimSpace(code)
+	switch {
+	case looksLikeUnifiedDiff(trimmed):
+		return "diff"
+	case (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)):
+		return "json"
+	}
+	if lexer := lexers.Analyse(code); lexer != nil {
+		return lexer.Config().Name
+	}
+	return ""
+}
+
+// looksLikeUnifiedDiff reports whether text is a unified diff: git's header
+// or file headers followed by a hunk.
+func looksLikeUnifiedDiff(text string) bool {
+	if strings.HasPrefix(text, "diff --git ") {
+		return true
+	}
+	sawHeader := false
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ "):
+			sawHeader = true
+		case strings.HasPrefix(line, "@@ -"):
+			return sawHeader
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestNewCodeStyleFollowsTerminal verifies the theme follows the background
+// and the formatter follows the color profile.
+func TestNewCodeStyleFollowsTerminal(testingHandle *testing.T) {
+	testutil.RequireEqual(testingHandle, newCodeStyle(termenv.TrueColor, true), codeStyle{Dark: true, Theme: darkCodeTheme, Formatter: "terminal16m"}, "dark truecolor")
+	testutil.RequireEqual(testingHandle, newCodeStyle(termenv.ANSI256, false), codeStyle{Theme: lightCodeTheme, Formatter: "terminal256"}, "light 256 colors")
+	testutil.RequireEqual(testingHandle, newCodeStyle(termenv.Ascii, true), codeStyle{Dark: true}, "no colors")
+}
+
+// TestDetectCodeLanguage verifies fence info strings and content guesses.
+func TestDetectCodeLanguage(testingHandle *testing.T) {
+	diff := "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new"
+	cases := []struct {
+		name     string
+		info     string
+		code     string
+		expected string
+	}{
+		{name: "language", info: "go", code: "x := 1", expected: "Go"},
+		{name: "file name", info: "cmd/main.py", code: "x = 1", expected: "Python"},
+		{name: "unknown info", info: "nonsense-language", code: "x", expected: ""},
+		{name: "diff", code: diff, expected: "diff"},
+		{name: "git diff", code: "diff --git a/x b/x\nindex 1..2", expected: "diff"},
+		{name: "json", code: `{"a": [1, 2]}`, expected: "json"},
+		{name: "shebang", code: "#!/bin/bash\necho hi", expected: "Bash"},
+		{name: "prose", code: "just some words", expected: ""},
+	}
+	for _, testCase := range cases {
+		testingHandle.Run(testCase.name, func(testingHandle *testing.T) {
+			testutil.RequireEqual(testingHandle, detectCodeLanguage(testCase.info, testCase.code), testCase.expected, "language")
+		})
+	}
+}
+
+// TestLabelCodeFences verifies bare fences get a detected language and
+// labeled fences are left alone.
+func TestLabelCodeFences(testingHandle *testing.T) {
+	// Arrange
+	content := "Here:\n\n```\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n```\n\n~~~~\n{\"ok\": true}\n~~~~\n\n```go\nfunc main() {}\n```\n\n```\nplain words\n```"
+
+	// Act
+	labeled := labelCodeFences(content)
+
+	// Assert
+	expected := "Here:\n\n```diff\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n```\n\n~~~~json\n{\"ok\": true}\n~~~~\n\n```go\nfunc main() {}\n```\n\n```\nplain words\n```"
+	testutil.RequireEqual(testingHandle, labeled, expected, "labeled fences")
+}
+
+// TestCodeStyleHighlight verifies code is colored only when the terminal has colors.
+func TestCodeStyleHighlight(testingHandle *testing.T) {
+	code := "-removed\n+added"
+	colored := newCodeStyle(termenv.TrueColor, true).Highlight(code, "diff")
+	testutil.RequireTrue(testingHandle, colored != code && strings.Contains(colored, "\x1b["), "diff colored")
+	testutil.RequireTrue(testingHandle, strings.Contains(colored, "removed") && strings.Contains(colored, "added"), "text kept")
+	testutil.RequireTrue(testingHandle, !strings.Contains(colored, "\n\x1b[0m"), "colors reset before line breaks")
+	testutil.RequireEqual(testingHandle, newCodeStyle(termenv.Ascii, true).Highlight(code, "diff"), code, "no colors")
+}
+
+// TestRenderToolDiffResult verifies diff output from tools is highlighted
+// while other output keeps the plain result style.
+func TestRenderToolDiffResult(testingHandle *testing.T) {
+	// Arrange
+	model := &tuiModel{theme: defaultTUITheme(), codeStyle: newCodeStyle(termenv.TrueColor, true)}
+	diff := "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b"
+
+	// Act
+	rendered := model.renderIndentedResultLine(diff, false)
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(rendered, "  ⎿ "), "result marker")
+	testutil.RequireEqual(testingHandle, rendered, "  ⎿ "+indentMultiline(model.codeStyle.Highlight(diff, "diff"), strings.Repeat(" ", len("  ⎿ "))), "highlighted diff")
+	testutil.RequireTrue(testingHandle, !strings.Contains(model.renderIndentedResultLine("ok", false), "\x1b[38;2"), "plain output untouched")
+}
+
+// TestMarkdownRendererHighlightsCode verifies fenced code is colored by the code theme.
+func TestMarkdownRendererHighlightsCode(testingHandle *testing.T) {
+	// Arrange
+	renderer := newMarkdownRenderer(newCodeStyle(termenv.TrueColor, true))
+
+	// Act
+	rendered := renderer.Render("```go\nfunc main() {}\n```")
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.Contains(rendered, "\x1b[38;2;"), "truecolor escapes")
+	testutil.RequireTrue(testingHandle, !strings.Contains(rendered, "shown as plain text"), "rendered as markdown")
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+// commandMode is a bitmask of the surfaces where a command is available.
+type commandMode uint8
+
+const (
+	// commandModeTUI exposes a slash command in the interactive UI and /help.
+	commandModeTUI commandMode = 1 << iota
+	// commandModeInit lists a slash command in stream-json init events.
+	// Only commands mirrored from Claude Code carry it so the list stays compatible.
+	commandModeInit
+	// commandModeControl handles a stream-json control request subtype.
+	commandModeControl
+)
+
+// commandArg describes one positional argument in a command's schema.
+type commandArg struct {
+	// Name is the placeholder shown in usage hints.
+	Name string
+	// Required marks arguments the command cannot run without.
+	Required bool
+}
+
+// commandSpec describes one capability: a slash command or a control request.
+// Exactly one of Run, TUI, Prompt, or Control is set for implemented commands; slash
+// commands with no handler are Claude Code names OpenClaude does not run yet.
+type commandSpec struct {
+	// Name is the slash command (without "/") or control request subtype.
+	Name string
+	// Description is the one-line summary for suggestions, /help, and init events.
+	Description string
+	// Category groups slash commands in /help output.
+	Category string
+	// Args is the positional argument schema.
+	Args []commandArg
+	// Modes lists the surfaces exposing the command.
+	Modes commandMode
+	// Run handles a slash command that only needs options.
+	Run func(opts *options, args string) string
+	// TUI handles a slash command that needs live TUI state.
+	TUI func(m *tuiModel, args string) string
+	// Prompt builds a model prompt that the TUI submits as a normal turn.
+	Prompt func(m *tuiModel, args string) (string, error)
+	// Control handles a stream-json control request.
+	Control func(state *controlState, request streamJSONControlRequest) error
+}
+
+// commandRegistry returns every built-in command in display order.
+// Init-mode slash commands keep Claude Code's ordering for stream-json consumers.
+func commandRegistry() []commandSpec {
+	return []commandSpec{
+		{
+			Name:        "keybindings-help",
+			Description: "Show keybindings.",
+			Category:    slashCategoryBuiltin,
+			Modes:       commandModeTUI | commandModeInit,
+			Run: func(_ *options, _ string) string {
+				return formatKeybindings(tuiKeybindings)
+			},
+		},
+		{
+			Name:        "commit",
+			Description: "Commit this session's changes with a generated Conventional Commits message.",
+			Category:    slashCategoryBuiltin,
+			Args:        []commandArg{{Name: "instructions"}},
+			Modes:       commandModeTUI,
+			Prompt: func(m *tuiModel, args string) (string, error) {
+				prompt, err := buildCommitPrompt(context.Background(), m.promptCWD(), args, m.editedFiles)
+				if err == nil {
+					m.editedFiles = nil
+				}
+				return prompt, err
+			},
+		},
+		{Name: "compact", Description: "Compact the conversation.", Category: slashCategoryBuiltin, Modes: commandModeTUI | commandModeInit},
+		{
+			Name:        "context",
+			Description: "Show the workspace roots and whether each is read-only or read-write.",
+			Category:    slashCategoryBuiltin,
+			Modes:       commandModeTUI | commandModeInit,
+			TUI: func(m *tuiModel, args string) string {
+				return m.showContext(args)
+			},
+		},
+		{
+			Name:        "cost",
+			Description: "Show token usage and cost by model and by turn.",
+			Category:    slashCategoryBuiltin,
+			Modes:       commandModeTUI | commandModeInit,
+			TUI: func(m *tuiModel, args string) string {
+				return m.showCost(args)
+			},
+		},
+		{Name: "init", Description: "Initialize session setup.", Category: slashCategoryBuiltin, Modes: commandModeTUI | commandModeInit},
+		{
+			Name:        "pr-comments",
+			Description: "Fetch pull request comments with gh and triage them.",
+			Category:    slashCategoryBuiltin,
+			Args:        []commandArg{{Name: "pr"}},
+			Modes:       commandModeTUI | commandModeInit,
+			Prompt: func(m *tuiModel, args string) (string, error) {
+				return buildPRCommentsPrompt(context.Background(), m.promptCWD(), args)
+			},
+		},
+		{
+			Name:        "resolve-conflicts",
+			Description: "Walk through merge conflicts and resolve each one with an approved edit.",
+			Category:    slashCategoryBuiltin,
+			Args:        []commandArg{{Name: "paths"}},
+			Modes:       commandModeTUI,
+			Prompt: func(m *tuiModel, args string) (string, error) {
+				prompt, err := buildResolveConflictsPrompt(context.Background(), m.promptCWD(), args)
+				if err == nil {
+					m.warnUnapprovedEdits()
+				}
+				return prompt, err
+			},
+		},
+		{Name: "release-notes", Description: "Show release notes.", Category: slashCategoryBuiltin, Modes: commandModeTUI | commandModeInit},
+		{
+			Name:        "review",
+			Description: "Review branch changes, staged changes, or a pull request.",
+			Category:    slashCategoryBuiltin,
+			Args:        []commandArg{{Name: "pr"}},
+			Modes:       commandModeTUI | commandModeInit,
+			Prompt: func(m *tuiModel, args string) (string, error) {
+				return buildReviewPrompt(context.Background(), m.promptCWD(), args)
+			},
+		},
+		{Name: "security-review", Description: "Run a security review.", Category: slashCategoryBuiltin, Args: []commandArg{{Name: "args"}}, Modes: commandModeTUI | commandModeInit},
+		{
+			Name:        "install-github-app",
+			Description: "Generate a GitHub Actions workflow that runs OpenClaude on @claude mentions.",
+			Category:    slashCategoryBuiltin,
+			Args:        []commandArg{{Name: "--dry-run"}},
+			Modes:       commandModeTUI,
+			Run:         runInstallGitHubAppSlash,
+		},
+		{
+			Name:        "agents",
+			Description: "List available subagents.",
+			Category:    slashCategoryInteractive,
+			Modes:       commandModeTUI,
+			Run: func(opts *options, _ string) string {
+				return formatAgentList(opts)
+			},
+		},
+		{
+			Name:        "checkpoint",
+			Description: "Save the conversation and files as a named checkpoint, or list checkpoints.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "name"}},
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.createCheckpoint(args)
+			},
+		},
+		{
+			Name:        "fork",
+			Description: "Continue in a new session copied from this one, keeping the original as it is.",
+			Category:    slashCategoryInteractive,
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.forkConversation(args)
+			},
+		},
+		{
+			Name:        "help",
+			Description: "Show commands and keybindings; /help <filter> narrows the list.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "filter"}},
+			Modes:       commandModeTUI,
+			Run:         formatHelp,
+		},
+		{
+			Name:        "history",
+			Description: "Browse input history; /history <text> filters, /history <n> recalls.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "filter or number"}},
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.showInputHistory(args)
+			},
+		},
+		{
+			Name:        "model",
+			Description: "List or switch models.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "name or alias"}},
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.switchModel(args)
+			},
+		},
+		{
+			Name:        "permissions",
+			Description: "List approval rules or export session approvals as a policy file.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "export [file]"}},
+			Modes:       commandModeTUI,
+			Run:         runPermissionsCommand,
+		},
+		{
+			Name:        "plain",
+			Description: "Toggle plain-text output instead of rendered markdown.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "on|off"}},
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.togglePlainMarkdown(args)
+			},
+		},
+		{
+			Name:        "reload",
+			Description: "Reload provider config and settings.",
+			Category:    slashCategoryInteractive,
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, _ string) string {
+				return m.reloadConfig("/reload")
+			},
+		},
+		{
+			Name:        "restore",
+			Description: "Restore a named checkpoint's conversation, files, or both.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "name"}, {Name: "conversation|files|both"}},
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.restoreCheckpoint(args)
+			},
+		},
+		{
+			Name:        "set",
+			Description: "Show or override max_tokens, temperature, top_p, or stop for this session.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "parameter"}, {Name: "value|default"}},
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.setRequestParam(args)
+			},
+		},
+		{
+			Name:        "stats",
+			Description: "Show API latency, time to first token, and wall time per turn.",
+			Category:    slashCategoryInteractive,
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.showStats(args)
+			},
+		},
+		{
+			Name:        "theme",
+			Description: "Preview themes or switch to one and save it to user settings.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "auto|dark|light|ansi|custom"}},
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.switchTheme(args)
+			},
+		},
+		{
+			Name:        "trust",
+			Description: "Trust this folder, enabling Bash and file edits.",
+			Category:    slashCategoryInteractive,
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.trustWorkspace(args)
+			},
+		},
+		{
+			Name:        "undo",
+			Description: "Remove the last prompt and its response from the conversation.",
+			Category:    slashCategoryInteractive,
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.undoLastExchange(args)
+			},
+		},
+		{
+			Name:        "verbose",
+			Description: "Toggle full tool inputs and outputs for new tool messages.",
+			Category:    slashCategoryInteractive,
+			Args:        []commandArg{{Name: "on|off"}},
+			Modes:       commandModeTUI,
+			TUI: func(m *tuiModel, args string) string {
+				return m.setVerbose(args)
+			},
+		},
+		{Name: "initialize", Description: "Start a stream-json session and report capabilities.", Modes: commandModeControl, Control: controlInitialize},
+		{Name: "set_permission_mode", Description: "Change the permission mode.", Args: []commandArg{{Name: "mode", Required: true}}, Modes: commandModeControl, Control: controlSetPermissionMode},
+		{Name: "set_model", Description: "Change the model for subsequent turns.", Args: []commandArg{{Name: "model", Required: true}}, Modes: commandModeControl, Control: controlSetModel},
+		{Name: "set_max_thinking_tokens", Description: "Change the thinking token budget.", Args: []commandArg{{Name: "max_thinking_tokens", Required: true}}, Modes: commandModeControl, Control: controlSetMaxThinkingTokens},
+		{Name: "interrupt", Description: "Interrupt the current turn.", Modes: commandModeControl, Control: controlInterrupt},
+	}
+}
+
+// lookupCommand finds a command by case-insensitive name on a surface.
+func lookupCommand(name string, mode commandMode) (commandSpec, bool) {
+	for _, spec := range commandRegistry() {
+		if spec.Modes&mode != 0 && strings.EqualFold(spec.Name, name) {
+			return spec, true
+		}
+	}
+	return commandSpec{}, false
+}
+
+// commandsForMode lists registry entries available on a surface, in registry order.
+func commandsForMode(mode commandMode) []commandSpec {
+	var specs []commandSpec
+	for _, spec := range commandRegistry() {
+		if spec.Modes&mode != 0 {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// commandNames returns the names of commands available on a surface.
+func commandNames(mode commandMode) []string {
+	specs := commandsForMode(mode)
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	return names
+}
+
+// argumentHint renders the argument schema, e.g. "<mode>" or "[filter]".
+func (spec commandSpec) argumentHint() string {
+	hints := make([]string, 0, len(spec.Args))
+	for _, arg := range spec.Args {
+		if arg.Required {
+			hints = append(hints, "<"+arg.Name+">")
+		} else {
+			hints = append(hints, "["+arg.Name+"]")
+		}
+	}
+	return strings.Join(hints, " ")
+}
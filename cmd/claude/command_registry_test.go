@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestCommandRegistrySurfaces verifies each surface reads its commands from the registry.
+func TestCommandRegistrySurfaces(testingHandle *testing.T) {
+	testingHandle.Setenv("HOME", testingHandle.TempDir())
+	testingHandle.Chdir(testingHandle.TempDir())
+
+	testutil.RequireEqual(testingHandle, listSlashCommands(&options{}), commandNames(commandModeInit), "init slash commands")
+	testutil.RequireEqual(testingHandle, len(listSlashCommands(&options{DisableSlashCommands: true})), 0, "disabled slash commands")
+	testutil.RequireEqual(testingHandle, commandNames(commandModeControl), []string{
+		"initialize", "set_permission_mode", "set_model", "set_max_thinking_tokens", "interrupt",
+	}, "control subtypes")
+
+	suggestions := buildSlashSuggestions(&options{})
+	help := formatHelp(&options{}, "")
+	for index, spec := range commandsForMode(commandModeTUI) {
+		testutil.RequireEqual(testingHandle, suggestions[index].Name, spec.Name, "suggestion order")
+		testutil.RequireEqual(testingHandle, suggestions[index].AcceptsArgs, len(spec.Args) > 0, "suggestion args for "+spec.Name)
+		testutil.RequireTrue(testingHandle, strings.Contains(help, "/"+spec.Name), "help lists "+spec.Name)
+		if spec.Control != nil {
+			testingHandle.Fatalf("slash command %s should not handle control requests", spec.Name)
+		}
+	}
+
+	_, ok := lookupCommand("interrupt", commandModeTUI)
+	testutil.RequireTrue(testingHandle, !ok, "control subtypes are not slash commands")
+	spec, ok := lookupCommand("Set_Model", commandModeControl)
+	testutil.RequireTrue(testingHandle, ok && spec.argumentHint() == "<model>", "control lookup is case-insensitive")
+
+	handled, output := handleSlashCommand("/model", &options{})
+	testutil.RequireTrue(testingHandle, handled, "stateful command handled")
+	testutil.RequireEqual(testingHandle, output, "Command /model is only available in the interactive UI.", "stateful command outside the TUI")
+}
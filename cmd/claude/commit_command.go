@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/session"
+)
+
+// editedFiles returns the files changed by successful edit tool calls in events, in first-edit order.
+func editedFiles(events []agent.ToolEvent) []string {
+	paths := map[string]string{}
+	var files []string
+	seen := map[string]bool{}
+	for _, event := range events {
+		switch event.Type {
+		case "tool_call":
+			if path := session.EditToolPath(event.ToolName, event.Arguments); path != "" {
+				paths[event.ToolID] = path
+			}
+		case "tool_result":
+			path, ok := paths[event.ToolID]
+			if !ok || event.IsError || seen[path] {
+				continue
+			}
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// noteEditedFiles remembers the files a run edited for /commit and, when the
+// suggestCommits setting is on, offers to commit them.
+func (m *tuiModel) noteEditedFiles(events []agent.ToolEvent) {
+	files := editedFiles(events)
+	if len(files) == 0 {
+		return
+	}
+	for _, file := range files {
+		if !slices.Contains(m.editedFiles, file) {
+			m.editedFiles = append(m.editedFiles, file)
+		}
+	}
+	if m.opts != nil && m.opts.ClaudeSettings != nil && m.opts.ClaudeSettings.SuggestCommits {
+		m.appendSystemMessage(fmt.Sprintf("%d file(s) edited. Run /commit to commit them with a generated message.", len(m.editedFiles)))
+	}
+}
+
+// buildCommitPrompt gathers the repository state for /commit and asks the model to
+// write a Conventional Commits message and commit with one Bash call, so the exact
+// git command goes through the usual approval prompt. Staged changes are committed
+// as they are; otherwise the model stages the files that belong to this session's work.
+func buildCommitPrompt(ctx context.Context, dir string, instructions string, sessionFiles []string) (string, error) {
+	status, err := runReviewCommand(ctx, dir, "git", "status", "--short")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(status) == "" {
+		return "", fmt.Errorf("No changes to commit.")
+	}
+	staged, err := runReviewCommand(ctx, dir, "git", "diff", "--cached")
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Write a commit message for the changes below in the Conventional Commits format: a `type(scope): summary` subject of at most 72 characters (types: feat, fix, refactor, docs, test, build, ci, chore, perf), a blank line, then a short body explaining what changed and why. Base it on what we did in this conversation and on the diff.\n\n")
+	diff := staged
+	if strings.TrimSpace(staged) != "" {
+		builder.WriteString("Only the staged changes are committed. Commit them with a single Bash call: `git commit -m \"<subject>\" -m \"<body>\"`.\n")
+	} else {
+		if diff, err = runReviewCommand(ctx, dir, "git", "diff", "HEAD"); err != nil {
+			// A repository without commits has no HEAD to diff against.
+			if diff, err = runReviewCommand(ctx, dir, "git", "diff"); err != nil {
+				return "", err
+			}
+		}
+		builder.WriteString("Nothing is staged. Stage the files that belong to this work and commit in a single Bash call: `git add -- <files> && git commit -m \"<subject>\" -m \"<body>\"`. Leave out changes that look unrelated and mention them afterwards.\n")
+	}
+	builder.WriteString("Keep it to one command so I can approve exactly what runs. Do not push, amend, or pass --no-verify; if a hook rejects the commit, report the failure instead of working around it.\n")
+	if instructions = strings.TrimSpace(instructions); instructions != "" {
+		builder.WriteString("\nAdditional instructions: " + instructions + "\n")
+	}
+	if len(sessionFiles) > 0 {
+		builder.WriteString("\nFiles edited in this session:\n")
+		for _, file := range sessionFiles {
+			builder.WriteString("- " + file + "\n")
+		}
+	}
+	builder.WriteString("\ngit status --short:\n```\n" + status + "```\n")
+	if strings.TrimSpace(diff) != "" {
+		builder.WriteString("\n```diff\n" + truncateReviewDiff(diff) + "```\n")
+	}
+	return builder.String(), nil
+}
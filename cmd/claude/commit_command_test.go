@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestEditedFilesKeepsSuccessfulEdits verifies failed edits and other tools are ignored.
+func TestEditedFilesKeepsSuccessfulEdits(testingHandle *testing.T) {
+	call := func(id string, tool string, args string) agent.ToolEvent {
+		return agent.ToolEvent{Type: "tool_call", ToolName: tool, ToolID: id, Arguments: json.RawMessage(args)}
+	}
+	result := func(id string, isError bool) agent.ToolEvent {
+		return agent.ToolEvent{Type: "tool_result", ToolID: id, IsError: isError}
+	}
+	events := []agent.ToolEvent{
+		call("1", "Edit", `{"file_path":"main.go"}`), result("1", false),
+		call("2", "Write", `{"file_path":"broken.go"}`), result("2", true),
+		call("3", "Read", `{"file_path":"README.md"}`), result("3", false),
+		call("4", "NotebookEdit", `{"notebook_path":"nb.ipynb"}`), result("4", false),
+		call("5", "Edit", `{"file_path":"main.go"}`), result("5", false),
+	}
+	testutil.RequireEqual(testingHandle, editedFiles(events), []string{"main.go", "nb.ipynb"}, "edited files")
+
+	model := newTUIModel(&options{ClaudeSettings: &config.Settings{SuggestCommits: true}}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	model.noteEditedFiles(events)
+	model.noteEditedFiles(events[:2])
+	testutil.RequireEqual(testingHandle, model.editedFiles, []string{"main.go", "nb.ipynb"}, "files deduplicated across runs")
+	last := model.chatMessages[len(model.chatMessages)-1]
+	testutil.RequireTrue(testingHandle, strings.Contains(last.Content, "Run /commit"), "commit offered")
+}
+
+// TestBuildCommitPrompt verifies staged and unstaged changes shape the commit instructions.
+func TestBuildCommitPrompt(testingHandle *testing.T) {
+	root := reviewGitRepo(testingHandle)
+	ctx := context.Background()
+	_, err := buildCommitPrompt(ctx, root, "", nil)
+	testutil.RequireTrue(testingHandle, err != nil && err.Error() == "No changes to commit.", "clean tree")
+
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "base.txt"), []byte("base\nfixed line\n"), 0o644), "edit base")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "scratch.txt"), []byte("notes\n"), 0o644), "write scratch")
+	prompt, err := buildCommitPrompt(ctx, root, "mention issue 12", []string{"base.txt"})
+	testutil.RequireNoError(testingHandle, err, "unstaged prompt")
+	for _, want := range []string{"Conventional Commits", "Nothing is staged", "git add -- <files> && git commit", "Do not push, amend, or pass --no-verify", "Additional instructions: mention issue 12", "Files edited in this session:\n- base.txt", "?? scratch.txt", "+fixed line"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(prompt, want), "prompt contains "+want+"\n"+prompt)
+	}
+
+	cmd := exec.Command("git", "add", "base.txt")
+	cmd.Dir = root
+	testutil.RequireNoError(testingHandle, cmd.Run(), "stage base")
+	prompt, err = buildCommitPrompt(ctx, root, "", nil)
+	testutil.RequireNoError(testingHandle, err, "staged prompt")
+	testutil.RequireTrue(testingHandle, strings.Contains(prompt, "Only the staged changes are committed") && !strings.Contains(prompt, "git add --"), "staged prompt commits the index as is")
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configScopeFlags selects the settings file a config subcommand works on.
+type configScopeFlags struct {
+	// scope is user, project, or local.
+	scope string
+	// global is Claude Code's shorthand for the user scope.
+	global bool
+	// jsonMode prints machine-readable output.
+	jsonMode bool
+}
+
+// register adds the scope flags to a subcommand.
+func (flags *configScopeFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flags.scope, "scope", "project", "Settings scope: user, project, or local")
+	cmd.Flags().BoolVarP(&flags.global, "global", "g", false, "Use the user scope (~/.claude/settings.json)")
+	cmd.Flags().BoolVar(&flags.jsonMode, "json", false, "Print JSON output")
+}
+
+// path resolves the settings file for the selected scope.
+func (flags *configScopeFlags) path() (string, error) {
+	scope := flags.scope
+	if flags.global {
+		scope = "user"
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("resolve cwd: %w", err)
+	}
+	return config.SettingsPath(cwd, scope)
+}
+
+// configCommand reads and writes settings files like Claude Code's config command.
+func configCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage configuration (eg. claude config set -g model opus)",
+		Long: "Read and write settings in the user (~/.claude/settings.json), project (<repo>/.claude/settings.json), or local (./.claude/settings.json) scope.\n" +
+			"Keys may be dotted (permissions.defaultMode). Values are parsed as JSON when valid, otherwise stored as strings.",
+	}
+	cmd.AddCommand(configGetCommand())
+	cmd.AddCommand(configSetCommand())
+	cmd.AddCommand(configListCommand())
+	cmd.AddCommand(configAddCommand())
+	cmd.AddCommand(configRemoveCommand())
+	return cmd
+}
+
+// configGetCommand prints one setting.
+func configGetCommand() *cobra.Command {
+	var flags configScopeFlags
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Get a config value",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, path, err := loadConfigScope(&flags, args[0])
+			if err != nil {
+				return err
+			}
+			value, ok := config.LookupSetting(data, args[0])
+			if !ok {
+				return fmt.Errorf("%s is not set in %s", args[0], path)
+			}
+			return writeConfigValue(cmd.OutOrStdout(), value, flags.jsonMode)
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// configSetCommand stores one setting.
+func configSetCommand() *cobra.Command {
+	var flags configScopeFlags
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a config value",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfigScope(&flags, args[0], func(data map[string]any) (string, error) {
+				if err := config.SetSetting(data, args[0], config.ParseSettingValue(args[1])); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Set %s", args[0]), nil
+			}, cmd.OutOrStdout())
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// configListCommand prints every setting in a scope.
+func configListCommand() *cobra.Command {
+	var flags configScopeFlags
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List all config values",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, _, err := loadConfigScope(&flags, "")
+			if err != nil {
+				return err
+			}
+			if flags.jsonMode {
+				return writeConfigValue(cmd.OutOrStdout(), data, true)
+			}
+			lines := flattenConfig("", data)
+			sort.Strings(lines)
+			for _, line := range lines {
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+			return nil
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// configAddCommand appends values to a list setting.
+func configAddCommand() *cobra.Command {
+	var flags configScopeFlags
+	cmd := &cobra.Command{
+		Use:   "add <key> <values...>",
+		Short: "Add items to a config array (space or comma separated)",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values := configListValues(args[1:])
+			return updateConfigScope(&flags, args[0], func(data map[string]any) (string, error) {
+				if err := config.AddSettingValues(data, args[0], values); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Added to %s", args[0]), nil
+			}, cmd.OutOrStdout())
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// configRemoveCommand deletes a setting, or only the given values from a list setting.
+func configRemoveCommand() *cobra.Command {
+	var flags configScopeFlags
+	cmd := &cobra.Command{
+		Use:     "remove <key> [values...]",
+		Aliases: []string{"rm"},
+		Short:   "Remove a config value or items from a config array",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateConfigScope(&flags, args[0], func(data map[string]any) (string, error) {
+				if len(args) == 1 {
+					if !config.RemoveSetting(data, args[0]) {
+						return "", fmt.Errorf("%s is not set", args[0])
+					}
+					return fmt.Sprintf("Removed %s", args[0]), nil
+				}
+				removed, err := config.RemoveSettingValues(data, args[0], configListValues(args[1:]))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Removed %d item(s) from %s", removed, args[0]), nil
+			}, cmd.OutOrStdout())
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// loadConfigScope validates key (when given) and reads the scope's settings.
+func loadConfigScope(flags *configScopeFlags, key string) (map[string]any, string, error) {
+	if key != "" {
+		if err := validateConfigKey(key); err != nil {
+			return nil, "", err
+		}
+	}
+	path, err := flags.path()
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := config.ReadSettingsMap(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, path, nil
+}
+
+// updateConfigScope applies edit to the scope's settings and writes them back.
+func updateConfigScope(flags *configScopeFlags, key string, edit func(map[string]any) (string, error), out io.Writer) error {
+	data, path, err := loadConfigScope(flags, key)
+	if err != nil {
+		return err
+	}
+	summary, err := edit(data)
+	if err != nil {
+		return err
+	}
+	if err := config.WriteSettingsMap(path, data); err != nil {
+		return err
+	}
+	if flags.jsonMode {
+		return writeConfigValue(out, map[string]any{"path": path, "key": key, "message": summary}, true)
+	}
+	fmt.Fprintf(out, "%s in %s\n", summary, path)
+	return nil
+}
+
+// validateConfigKey rejects empty keys and empty dotted segments.
+func validateConfigKey(key string) error {
+	for _, part := range strings.Split(key, ".") {
+		if strings.TrimSpace(part) == "" {
+			return fmt.Errorf("invalid config key %q", key)
+		}
+	}
+	return nil
+}
+
+// configListValues splits add/remove arguments on commas and parses each item.
+func configListValues(args []string) []any {
+	var values []any
+	for _, arg := range args {
+		for _, item := range strings.Split(arg, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, config.ParseSettingValue(item))
+			}
+		}
+	}
+	return values
+}
+
+// writeConfigValue prints strings as-is and everything else (or anything in JSON mode) as JSON.
+func writeConfigValue(out io.Writer, value any, jsonMode bool) error {
+	if text, ok := value.(string); ok && !jsonMode {
+		_, err := fmt.Fprintln(out, text)
+		return err
+	}
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode config value: %w", err)
+	}
+	_, err = fmt.Fprintln(out, string(encoded))
+	return err
+}
+
+// flattenConfig renders nested settings as "dotted.key = value" lines.
+func flattenConfig(prefix string, data map[string]any) []string {
+	var lines []string
+	for key, value := range data {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			lines = append(lines, flattenConfig(key, nested)...)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s = %s", key, encoded))
+	}
+	return lines
+}
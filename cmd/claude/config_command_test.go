@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestConfigCommandEditsScopes verifies get/set/list/add/remove against user and project settings.
+func TestConfigCommandEditsScopes(testingHandle *testing.T) {
+	// Arrange: a home directory and a repository to hold the two scopes.
+	home := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", home)
+	repo := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.Mkdir(filepath.Join(repo, ".git"), 0o755), "create repo")
+	testingHandle.Chdir(repo)
+	run := func(args ...string) (string, error) {
+		cmd := configCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	// Act
+	for _, args := range [][]string{
+		{"set", "-g", "model", "opus"},
+		{"set", "permissions.defaultMode", "plan"},
+		{"set", "suggestCommits", "true"},
+		{"add", "permissions.allow", "Bash,Read", "Read", "Glob"},
+		{"remove", "permissions.allow", "Bash"},
+	} {
+		_, err := run(args...)
+		testutil.RequireNoError(testingHandle, err, strings.Join(args, " "))
+	}
+
+	// Assert: each scope holds its own values and the loader sees them merged.
+	output, err := run("get", "model", "--global")
+	testutil.RequireNoError(testingHandle, err, "get model")
+	testutil.RequireEqual(testingHandle, output, "opus\n", "user model")
+	output, err = run("get", "permissions.allow", "--json")
+	testutil.RequireNoError(testingHandle, err, "get allow")
+	testutil.RequireEqual(testingHandle, output, "[\n  \"Read\",\n  \"Glob\"\n]\n", "allow list")
+	output, err = run("list")
+	testutil.RequireNoError(testingHandle, err, "list")
+	testutil.RequireEqual(testingHandle, output, "permissions.allow = [\"Read\",\"Glob\"]\npermissions.defaultMode = \"plan\"\nsuggestCommits = true\n", "project listing")
+	settings, err := config.LoadClaudeSettings(repo, nil, "")
+	testutil.RequireNoError(testingHandle, err, "load settings")
+	testutil.RequireTrue(testingHandle, settings.Model == "opus" && settings.SuggestCommits && settings.Permissions.DefaultMode == "plan", "merged settings")
+
+	_, err = run("remove", "suggestCommits")
+	testutil.RequireNoError(testingHandle, err, "remove key")
+	_, err = run("get", "suggestCommits")
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "is not set"), "removed key")
+	_, err = run("set", "permissions.defaultMode.extra", "x")
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "is not an object"), "set through a string")
+	_, err = run("get", "model", "--scope", "team")
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "unknown settings scope"), "unknown scope")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// tuiCostTurn records usage for one prompt submitted in the TUI.
+type tuiCostTurn struct {
+	// Prompt is the submitted text, used as the turn label.
+	Prompt string
+	// ModelUsage breaks the turn's tokens down by model, including subagents.
+	ModelUsage map[string]llm.Usage
+	// CostUSD is the estimated cost reported by the run.
+	CostUSD float64
+	// Duration is the turn's wall time.
+	Duration time.Duration
+	// Turns times each API request the prompt needed, for /stats.
+	Turns []agent.TurnMetrics
+}
+
+// recordCostTurn appends a finished run to the session cost log.
+func (m *tuiModel) recordCostTurn(result *agent.RunResult) {
+	usage := make(map[string]llm.Usage, len(result.ModelUsage))
+	for model, modelUsage := range result.ModelUsage {
+		usage[model] = modelUsage
+	}
+	m.costTurns = append(m.costTurns, tuiCostTurn{
+		Prompt:     m.runPrompt,
+		ModelUsage: usage,
+		CostUSD:    result.CostUSD,
+		Duration:   result.Duration,
+		Turns:      result.Turns,
+	})
+	m.totalCost += result.CostUSD
+}
+
+// showCost renders /cost for the current TUI session.
+func (m *tuiModel) showCost(_ string) string {
+	var pricing map[string]config.ModelPricing
+	if m.runner != nil {
+		pricing = m.runner.Pricing
+	}
+	return formatCostReport(m.costTurns, pricing)
+}
+
+// formatCostReport renders per-model and per-turn token usage and cost.
+// Models without a pricing entry show token counts and "unpriced".
+func formatCostReport(turns []tuiCostTurn, pricing map[string]config.ModelPricing) string {
+	if len(turns) == 0 {
+		return "No usage yet in this session."
+	}
+	totals := map[string]llm.Usage{}
+	var totalCost float64
+	var totalDuration time.Duration
+	for _, turn := range turns {
+		for model, usage := range turn.ModelUsage {
+			totals[model] = totals[model].Add(usage)
+		}
+		totalCost += turn.CostUSD
+		totalDuration += turn.Duration
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Total cost:     %s\n", formatUSD(totalCost))
+	fmt.Fprintf(&builder, "Total duration: %s over %d turn(s)\n", totalDuration.Round(time.Second), len(turns))
+	builder.WriteString("\nUsage by model:")
+	for _, model := range sortedUsageModels(totals) {
+		usage := totals[model]
+		fmt.Fprintf(&builder, "\n  %s\n    %s", model, formatUsageTokens(usage))
+		if _, priced := pricing[model]; priced {
+			fmt.Fprintf(&builder, "\n    cost: %s", formatUSD(agent.EstimateCost(model, usage, pricing)))
+		} else {
+			builder.WriteString("\n    cost: unpriced (add it to pricing or models in ~/.openclaude/config.json)")
+		}
+	}
+
+	builder.WriteString("\n\nUsage by turn:")
+	for index, turn := range turns {
+		var usage llm.Usage
+		models := sortedUsageModels(turn.ModelUsage)
+		for _, model := range models {
+			usage = usage.Add(turn.ModelUsage[model])
+		}
+		fmt.Fprintf(&builder, "\n  %d. %s\n    %s · %s · %s · %s", index+1, costTurnLabel(turn.Prompt),
+			formatUsageTokens(usage), formatUSD(turn.CostUSD), turn.Duration.Round(100*time.Millisecond), strings.Join(models, ", "))
+	}
+	return builder.String()
+}
+
+// formatUsageTokens renders token counts, including cache and reasoning tokens when present.
+func formatUsageTokens(usage llm.Usage) string {
+	text := fmt.Sprintf("input %d · output %d", usage.PromptTokens, usage.CompletionTokens)
+	if usage.CacheReadTokens > 0 || usage.CacheWriteTokens > 0 {
+		text += fmt.Sprintf(" · cache read %d · cache write %d", usage.CacheReadTokens, usage.CacheWriteTokens)
+	}
+	if usage.ReasoningTokens > 0 {
+		text += fmt.Sprintf(" · reasoning %d", usage.ReasoningTokens)
+	}
+	if usage.ServerToolUse.WebSearchRequests > 0 {
+		text += fmt.Sprintf(" · web searches %d", usage.ServerToolUse.WebSearchRequests)
+	}
+	if usage.ServerToolUse.WebFetchRequests > 0 {
+		text += fmt.Sprintf(" · web fetches %d", usage.ServerToolUse.WebFetchRequests)
+	}
+	return text
+}
+
+// formatUSD renders a dollar amount with enough precision for small turns.
+func formatUSD(amount float64) string {
+	return fmt.Sprintf("$%.4f", amount)
+}
+
+// costTurnLabel shortens a prompt to one line for the per-turn list.
+func costTurnLabel(prompt string) string {
+	label, _, multiline := strings.Cut(strings.TrimSpace(prompt), "\n")
+	if runes := []rune(label); len(runes) > 60 {
+		label = string(runes[:60])
+		multiline = true
+	}
+	if multiline {
+		label += "…"
+	}
+	if label == "" {
+		return "(prompt)"
+	}
+	return label
+}
+
+// sortedUsageModels returns model names in a stable order.
+func sortedUsageModels(usage map[string]llm.Usage) []string {
+	models := make([]string, 0, len(usage))
+	for model := range usage {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
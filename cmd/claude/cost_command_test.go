@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestFormatCostReportBreaksDownModelsAndTurns verifies /cost totals, cache tokens, server tools, and unpriced models.
+func TestFormatCostReportBreaksDownModelsAndTurns(testingHandle *testing.T) {
+	testutil.RequireEqual(testingHandle, formatCostReport(nil, nil), "No usage yet in this session.", "empty session")
+
+	turns := []tuiCostTurn{
+		{
+			Prompt:     "explain the parser\nin detail",
+			ModelUsage: map[string]llm.Usage{"big": {PromptTokens: 1000, CompletionTokens: 200, CacheReadTokens: 600}},
+			CostUSD:    0.0123,
+			Duration:   2 * time.Second,
+		},
+		{
+			Prompt: "run the tests",
+			ModelUsage: map[string]llm.Usage{
+				"big":   {PromptTokens: 500, CompletionTokens: 100},
+				"small": {PromptTokens: 300, CompletionTokens: 50, ReasoningTokens: 20, ServerToolUse: llm.ServerToolUse{WebSearchRequests: 2}},
+			},
+			CostUSD:  0.0050,
+			Duration: time.Second,
+		},
+	}
+	pricing := map[string]config.ModelPricing{"big": {InputPer1M: 10, OutputPer1M: 30}}
+	report := formatCostReport(turns, pricing)
+	for _, want := range []string{
+		"Total cost:     $0.0173",
+		"over 2 turn(s)",
+		"  big\n    input 1500 · output 300 · cache read 600 · cache write 0",
+		"  small\n    input 300 · output 50 · reasoning 20 · web searches 2\n    cost: unpriced",
+		"1. explain the parser…",
+		"2. run the tests\n    input 800 · output 150 · reasoning 20 · web searches 2 · $0.0050 · 1s · big, small",
+	} {
+		testutil.RequireTrue(testingHandle, strings.Contains(report, want), "report contains "+want+"\n"+report)
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/session"
+)
+
+const (
+	// doctorPass, doctorWarn, and doctorFail are the check outcomes.
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	// doctorNetworkTimeout bounds each gateway request made by the checks.
+	doctorNetworkTimeout = 15 * time.Second
+)
+
+// doctorCheck is one row of the doctor report.
+type doctorCheck struct {
+	// Name identifies the check.
+	Name string `json:"name"`
+	// Status is pass, warn, or fail.
+	Status string `json:"status"`
+	// Detail explains the outcome.
+	Detail string `json:"detail"`
+}
+
+// doctorReport is the full doctor result, printed as a table or as JSON.
+type doctorReport struct {
+	// OK is false when any check failed; warnings do not count.
+	OK bool `json:"ok"`
+	// Checks lists the results in run order.
+	Checks []doctorCheck `json:"checks"`
+}
+
+// doctorEnvironment is what the checks inspect, gathered by the caller so tests can substitute it.
+type doctorEnvironment struct {
+	// ProviderPath is the provider config file.
+	ProviderPath string
+	// Model overrides the provider default model.
+	Model string
+	// Store is the session store whose directory must be writable.
+	Store *session.Store
+	// LookPath finds executables; nil uses exec.LookPath.
+	LookPath func(string) (string, error)
+	// Terminal reports whether stdout is a terminal.
+	Terminal bool
+	// TermName is $TERM.
+	TermName string
+	// Width is the terminal width in columns (zero when unknown).
+	Width int
+}
+
+// add records one check result.
+func (r *doctorReport) add(name string, status string, detail string) {
+	r.Checks = append(r.Checks, doctorCheck{Name: name, Status: status, Detail: detail})
+	if status == doctorFail {
+		r.OK = false
+	}
+}
+
+// count returns how many checks ended with status.
+func (r *doctorReport) count(status string) int {
+	total := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			total++
+		}
+	}
+	return total
+}
+
+// runDoctorChecks runs every check and returns the report with the provider
+// config, which is nil when it could not be loaded.
+func runDoctorChecks(ctx context.Context, env doctorEnvironment) (doctorReport, *config.ProviderConfig) {
+	report := doctorReport{OK: true}
+	providerCfg := checkProviderConfig(&report, env.ProviderPath)
+	if providerCfg == nil {
+		report.add("api reachability", doctorWarn, "skipped: provider config is unusable")
+		report.add("model", doctorWarn, "skipped: provider config is unusable")
+	} else {
+		checkGateway(ctx, &report, providerCfg, env.Model)
+		checkPricing(&report, providerCfg)
+	}
+	checkSessionStore(&report, env.Store)
+	checkTerminal(&report, env)
+	lookPath := env.LookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+	if path, err := lookPath("rg"); err == nil {
+		report.add("ripgrep", doctorPass, path)
+	} else {
+		report.add("ripgrep", doctorWarn, "rg not found on PATH; optional, but faster for Bash searches")
+	}
+	checkGit(ctx, &report, lookPath)
+	return report, providerCfg
+}
+
+// checkProviderConfig verifies the config file exists, is private, and parses.
+func checkProviderConfig(report *doctorReport, path string) *config.ProviderConfig {
+	info, err := os.Stat(path)
+	if err != nil {
+		report.add("provider config", doctorFail, fmt.Sprintf("missing at %s", path))
+		return nil
+	}
+	if mode := info.Mode().Perm(); mode&0o077 != 0 {
+		report.add("provider config", doctorFail, fmt.Sprintf("permissions too open: %s (run chmod 600 %s)", mode, path))
+		return nil
+	}
+	providerCfg, err := config.LoadProviderConfig(path)
+	if err != nil {
+		report.add("provider config", doctorFail, fmt.Sprintf("invalid: %v", err))
+		return nil
+	}
+	report.add("provider config", doctorPass, path)
+	return providerCfg
+}
+
+// checkGateway asks the gateway for its models, then confirms the model is
+// listed or, when it is not or the gateway has no /models, answers a tiny request.
+func checkGateway(ctx context.Context, report *doctorReport, providerCfg *config.ProviderConfig, override string) {
+	client := newGatewayClient(providerCfg)
+	endpoint := gatewayDisplayURL(providerCfg.APIBaseURL)
+	listCtx, cancel := context.WithTimeout(ctx, doctorNetworkTimeout)
+	started := time.Now()
+	models, err := client.ListModels(listCtx)
+	elapsed := time.Since(started)
+	cancel()
+	listed := err == nil
+	var apiErr *openai.APIError
+	switch {
+	case err == nil:
+		report.add("api reachability", doctorPass, fmt.Sprintf("%s listed %d models in %s", endpoint, len(models), formatProbeDuration(elapsed)))
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden):
+		report.add("api reachability", doctorFail, fmt.Sprintf("%s rejected the credentials (%d)", endpoint, apiErr.StatusCode))
+		report.add("model", doctorWarn, "skipped: gateway rejected the credentials")
+		return
+	case errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError:
+		report.add("api reachability", doctorPass, fmt.Sprintf("%s answered in %s (no /models endpoint: %d)", endpoint, formatProbeDuration(elapsed), apiErr.StatusCode))
+	case errors.As(err, &apiErr):
+		report.add("api reachability", doctorWarn, fmt.Sprintf("%s answered /models with %d", endpoint, apiErr.StatusCode))
+	default:
+		report.add("api reachability", doctorFail, fmt.Sprintf("%s unreachable: %s", endpoint, doctorErrorText(err, providerCfg)))
+		report.add("model", doctorWarn, "skipped: gateway unreachable")
+		return
+	}
+
+	model := config.ResolveModel(providerCfg, override, "")
+	if model == "" {
+		report.add("model", doctorFail, "no model configured; set default_model in the provider config")
+		return
+	}
+	if listed && slices.Contains(models, model) {
+		report.add("model", doctorPass, model+" is listed by the gateway")
+		return
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, doctorNetworkTimeout)
+	defer cancel()
+	result := gatewayProber{client: client, model: model}.probeCompletion(probeCtx)
+	if result.Err != nil {
+		report.add("model", doctorFail, fmt.Sprintf("%s failed a test request: %s", model, doctorErrorText(result.Err, providerCfg)))
+		return
+	}
+	detail := fmt.Sprintf("%s answered a test request in %s", model, formatProbeDuration(result.Latency))
+	if listed {
+		detail += " (not listed by /models)"
+	}
+	report.add("model", doctorPass, detail)
+}
+
+// checkPricing warns about models without pricing, which disables cost
+// reporting and --max-budget-usd for them.
+func checkPricing(report *doctorReport, providerCfg *config.ProviderConfig) {
+	catalog := config.ModelCatalog(providerCfg)
+	var missing []string
+	for _, entry := range catalog {
+		if entry.Info.Pricing == nil {
+			missing = append(missing, entry.Name)
+		}
+	}
+	switch {
+	case len(catalog) == 0:
+		report.add("pricing", doctorWarn, "no models configured")
+	case len(missing) > 0:
+		report.add("pricing", doctorWarn, fmt.Sprintf("no pricing for %s; costs and --max-budget-usd are unavailable for them", strings.Join(missing, ", ")))
+	default:
+		report.add("pricing", doctorPass, fmt.Sprintf("%d models priced", len(catalog)))
+	}
+}
+
+// checkSessionStore writes and removes a probe file in the sessions directory.
+func checkSessionStore(report *doctorReport, store *session.Store) {
+	if store == nil {
+		report.add("session store", doctorFail, "home directory unavailable")
+		return
+	}
+	dir := filepath.Join(store.BaseDir, "sessions")
+	err := os.MkdirAll(dir, 0o700)
+	if err == nil {
+		var probe *os.File
+		if probe, err = os.CreateTemp(dir, ".doctor-*"); err == nil {
+			_, err = probe.WriteString("ok\n")
+			probe.Close()
+			os.Remove(probe.Name())
+		}
+	}
+	if err != nil {
+		report.add("session store", doctorFail, fmt.Sprintf("%s is not writable: %v", dir, err))
+		return
+	}
+	report.add("session store", doctorPass, dir+" is writable")
+}
+
+// checkTerminal reports whether the interactive UI can run in this terminal.
+func checkTerminal(report *doctorReport, env doctorEnvironment) {
+	switch {
+	case !env.Terminal:
+		report.add("terminal", doctorWarn, "stdout is not a terminal; the interactive UI needs one (print mode works)")
+	case env.TermName == "" || env.TermName == "dumb":
+		report.add("terminal", doctorWarn, fmt.Sprintf("TERM=%q lacks cursor control; set TERM=xterm-256color", env.TermName))
+	case env.Width > 0 && env.Width < 60:
+		report.add("terminal", doctorWarn, fmt.Sprintf("TERM=%s, only %d columns wide", env.TermName, env.Width))
+	default:
+		detail := "TERM=" + env.TermName
+		if env.Width > 0 {
+			detail += fmt.Sprintf(", %d columns", env.Width)
+		}
+		report.add("terminal", doctorPass, detail)
+	}
+}
+
+// checkGit verifies git is installed; git status, /commit, and /review need it.
+func checkGit(ctx context.Context, report *doctorReport, lookPath func(string) (string, error)) {
+	path, err := lookPath("git")
+	if err != nil {
+		report.add("git", doctorWarn, "git not found on PATH; git status, /commit, and /review need it")
+		return
+	}
+	versionCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(versionCtx, path, "--version").Output()
+	if err != nil {
+		report.add("git", doctorWarn, fmt.Sprintf("%s --version failed: %v", path, err))
+		return
+	}
+	report.add("git", doctorPass, strings.TrimSpace(string(output)))
+}
+
+// doctorErrorText shortens err and strips the gateway URL's query and the API
+// key so credentials never reach the report.
+func doctorErrorText(err error, providerCfg *config.ProviderConfig) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = gatewayDisplayURL(urlErr.URL)
+	}
+	text := truncateProbeError(err)
+	if providerCfg.APIKey != "" {
+		text = strings.ReplaceAll(text, providerCfg.APIKey, "[redacted]")
+	}
+	return text
+}
+
+// writeDoctorReport prints the report as a table, or as indented JSON.
+func writeDoctorReport(out io.Writer, report doctorReport, jsonMode bool) error {
+	if jsonMode {
+		encoded, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode doctor report: %w", err)
+		}
+		_, err = fmt.Fprintln(out, string(encoded))
+		return err
+	}
+	width := len("check")
+	for _, check := range report.Checks {
+		width = max(width, len(check.Name))
+	}
+	fmt.Fprintf(out, "%-6s %-*s %s\n", "STATUS", width, "CHECK", "DETAIL")
+	for _, check := range report.Checks {
+		fmt.Fprintf(out, "%-6s %-*s %s\n", check.Status, width, check.Name, check.Detail)
+	}
+	_, err := fmt.Fprintf(out, "\n%d passed, %d warnings, %d failed\n", report.count(doctorPass), report.count(doctorWarn), report.count(doctorFail))
+	return err
+}
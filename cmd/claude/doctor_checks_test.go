@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestRunDoctorChecksReportsEachCheck verifies the checks against a gateway
+// that lists other models but answers the configured one.
+func TestRunDoctorChecksReportsEachCheck(testingHandle *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer secret" {
+			responseWriter.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if request.URL.Path == "/models" {
+			_, _ = fmt.Fprint(responseWriter, `{"data":[{"id":"other-model"}]}`)
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"pong"},"finish_reason":"stop"}]}`)
+	}))
+	testingHandle.Cleanup(server.Close)
+	dir := testingHandle.TempDir()
+	providerPath := filepath.Join(dir, "config.json")
+	providerJSON := fmt.Sprintf(`{"api_base_url":%q,"api_key":"secret","default_model":"probe-model","pricing":{"other-model":{"input_per_1m":1,"output_per_1m":2}}}`, server.URL+"?key=secret")
+	testutil.RequireNoError(testingHandle, os.WriteFile(providerPath, []byte(providerJSON), 0o600), "write config")
+	env := doctorEnvironment{
+		ProviderPath: providerPath,
+		Store:        &session.Store{BaseDir: filepath.Join(dir, "store")},
+		LookPath:     func(name string) (string, error) { return "", errors.New("not found") },
+		Terminal:     true,
+		TermName:     "xterm-256color",
+		Width:        120,
+	}
+
+	// Act
+	report, providerCfg := runDoctorChecks(context.Background(), env)
+	var output bytes.Buffer
+	err := writeDoctorReport(&output, report, true)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "write report")
+	testutil.RequireTrue(testingHandle, report.OK && providerCfg != nil, "no failures")
+	statuses := map[string]string{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status + ": " + check.Detail
+	}
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(statuses["api reachability"], "pass: ") && strings.Contains(statuses["api reachability"], "listed 1 models"), statuses["api reachability"])
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(statuses["model"], "pass: probe-model answered") && strings.Contains(statuses["model"], "not listed"), statuses["model"])
+	testutil.RequireEqual(testingHandle, statuses["pricing"], "warn: no pricing for probe-model; costs and --max-budget-usd are unavailable for them", "pricing")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(statuses["session store"], "pass: "), statuses["session store"])
+	testutil.RequireEqual(testingHandle, statuses["terminal"], "pass: TERM=xterm-256color, 120 columns", "terminal")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(statuses["ripgrep"], "warn: ") && strings.HasPrefix(statuses["git"], "warn: "), "missing tools warn")
+	var decoded doctorReport
+	testutil.RequireNoError(testingHandle, json.Unmarshal(output.Bytes(), &decoded), "decode JSON")
+	testutil.RequireEqual(testingHandle, decoded, report, "JSON round trip")
+	testutil.RequireTrue(testingHandle, !strings.Contains(output.String(), "secret"), "credentials stay out of the report")
+}
+
+// TestRunDoctorChecksFailures verifies failing checks, skipped gateway checks, and the table output.
+func TestRunDoctorChecksFailures(testingHandle *testing.T) {
+	// Arrange
+	dir := testingHandle.TempDir()
+	providerPath := filepath.Join(dir, "config.json")
+	testutil.RequireNoError(testingHandle, os.WriteFile(providerPath, []byte(`{}`), 0o644), "write config")
+	env := doctorEnvironment{ProviderPath: providerPath, LookPath: func(name string) (string, error) { return "/usr/bin/" + name, errors.New("skip") }}
+
+	// Act
+	report, providerCfg := runDoctorChecks(context.Background(), env)
+	var output bytes.Buffer
+	err := writeDoctorReport(&output, report, false)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "write report")
+	testutil.RequireTrue(testingHandle, !report.OK && providerCfg == nil, "config failure")
+	text := output.String()
+	testutil.RequireTrue(testingHandle, strings.Contains(text, "fail   provider config  permissions too open: -rw-r--r--"), text)
+	testutil.RequireTrue(testingHandle, strings.Contains(text, "warn   api reachability skipped: provider config is unusable"), text)
+	testutil.RequireTrue(testingHandle, strings.Contains(text, "fail   session store"), text)
+	testutil.RequireTrue(testingHandle, strings.Contains(text, "warn   terminal         stdout is not a terminal"), text)
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(text, "\n0 passed, 5 warnings, 2 failed\n"), text)
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+)
+
+const (
+	// doctorWatchWindow is how many recent probes the dashboard aggregates.
+	doctorWatchWindow = 20
+	// doctorWatchStallGap flags a stream whose chunks arrive further apart than this.
+	doctorWatchStallGap = 5 * time.Second
+	// doctorWatchProbeTokens caps the output of each probe request.
+	doctorWatchProbeTokens = 16
+)
+
+// doctorWatchOptions controls claude doctor --watch.
+type doctorWatchOptions struct {
+	// Model is probed; empty uses the provider default.
+	Model string
+	// Interval is the pause between probe rounds.
+	Interval time.Duration
+	// Count stops after this many rounds; zero runs until interrupted.
+	Count int
+	// Clear redraws the dashboard in place instead of appending frames.
+	Clear bool
+}
+
+// probeResult records one probe request.
+type probeResult struct {
+	// At is when the probe started.
+	At time.Time
+	// Latency is the full request time.
+	Latency time.Duration
+	// FirstChunk is the time to the first streamed chunk; zero for non-streaming probes.
+	FirstChunk time.Duration
+	// Chunks counts streamed chunks.
+	Chunks int
+	// MaxGap is the longest pause between streamed chunks.
+	MaxGap time.Duration
+	// Finished reports whether the stream delivered a finish_reason.
+	Finished bool
+	// Err is the probe failure, if any.
+	Err error
+}
+
+// probeSeries keeps the most recent results of one probe kind.
+type probeSeries struct {
+	// results holds up to doctorWatchWindow entries, oldest first.
+	results []probeResult
+	// total counts every probe, including those that left the window.
+	total int
+}
+
+// add appends result and drops the oldest entry once the window is full.
+func (s *probeSeries) add(result probeResult) {
+	s.total++
+	s.results = append(s.results, result)
+	if len(s.results) > doctorWatchWindow {
+		s.results = s.results[len(s.results)-doctorWatchWindow:]
+	}
+}
+
+// errorRate is the fraction of failed probes in the window.
+func (s *probeSeries) errorRate() float64 {
+	if len(s.results) == 0 {
+		return 0
+	}
+	failed := 0
+	for _, result := range s.results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(s.results))
+}
+
+// lastError returns the most recent failure in the window.
+func (s *probeSeries) lastError() (probeResult, bool) {
+	for index := len(s.results) - 1; index >= 0; index-- {
+		if s.results[index].Err != nil {
+			return s.results[index], true
+		}
+	}
+	return probeResult{}, false
+}
+
+// successful returns durations picked from successful probes.
+func (s *probeSeries) successful(pick func(probeResult) time.Duration) []time.Duration {
+	var values []time.Duration
+	for _, result := range s.results {
+		if result.Err == nil {
+			values = append(values, pick(result))
+		}
+	}
+	return values
+}
+
+// gatewayProber sends the probe requests.
+type gatewayProber struct {
+	// client talks to the configured gateway.
+	client *openai.Client
+	// model is sent with each probe.
+	model string
+}
+
+// probeRequest builds the tiny request every probe sends.
+func (p gatewayProber) probeRequest() *openai.ChatRequest {
+	maxTokens := doctorWatchProbeTokens
+	return &openai.ChatRequest{
+		Model:     p.model,
+		Messages:  []llm.Message{{Role: "user", Content: "Reply with the single word: pong"}},
+		MaxTokens: &maxTokens,
+	}
+}
+
+// probeCompletion times a non-streaming request.
+func (p gatewayProber) probeCompletion(ctx context.Context) probeResult {
+	result := probeResult{At: time.Now()}
+	_, result.Err = p.client.ChatCompletions(ctx, p.probeRequest())
+	result.Latency = time.Since(result.At)
+	return result
+}
+
+// probeStream times a streaming request, recording time to first chunk,
+// gaps between chunks, and whether the stream finished cleanly.
+func (p gatewayProber) probeStream(ctx context.Context) probeResult {
+	result := probeResult{At: time.Now()}
+	last := result.At
+	_, err := p.client.ChatCompletionsStream(ctx, p.probeRequest(), func(event openai.StreamResponse) error {
+		now := time.Now()
+		if result.Chunks == 0 {
+			result.FirstChunk = now.Sub(result.At)
+		} else if gap := now.Sub(last); gap > result.MaxGap {
+			result.MaxGap = gap
+		}
+		last = now
+		result.Chunks++
+		for _, choice := range event.Choices {
+			if choice.FinishReason != nil && *choice.FinishReason != "" {
+				result.Finished = true
+			}
+		}
+		return nil
+	})
+	result.Latency = time.Since(result.At)
+	switch {
+	case err != nil:
+		result.Err = err
+	case result.Chunks == 0:
+		result.Err = errors.New("stream ended without chunks")
+	case !result.Finished:
+		result.Err = errors.New("stream ended without finish_reason")
+	}
+	return result
+}
+
+// doctorDashboard aggregates probe rounds for rendering.
+type doctorDashboard struct {
+	// endpoint and model identify what is probed.
+	endpoint, model string
+	// started is when watching began.
+	started time.Time
+	// completion and stream hold the two probe series.
+	completion, stream probeSeries
+}
+
+// runDoctorWatch probes the gateway every interval and redraws the dashboard
+// until ctx is canceled or Count rounds have run.
+func runDoctorWatch(ctx context.Context, out io.Writer, providerCfg *config.ProviderConfig, watch doctorWatchOptions) error {
+	if watch.Interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+	if watch.Count < 0 {
+		return errors.New("--count must not be negative")
+	}
+	model := config.ResolveModel(providerCfg, watch.Model, "")
+	if model == "" {
+		return errors.New("no model to probe; set default_model in the provider config or pass --model")
+	}
+	prober := gatewayProber{
+		client: newGatewayClient(providerCfg),
+		model:  model,
+	}
+	dashboard := &doctorDashboard{endpoint: gatewayDisplayURL(providerCfg.APIBaseURL), model: model, started: time.Now()}
+	for round := 1; watch.Count == 0 || round <= watch.Count; round++ {
+		dashboard.completion.add(prober.probeCompletion(ctx))
+		dashboard.stream.add(prober.probeStream(ctx))
+		if ctx.Err() != nil {
+			return nil
+		}
+		frame := dashboard.render(time.Now())
+		if watch.Clear {
+			frame = "\x1b[H\x1b[2J" + frame + "\nPress Ctrl+C to stop.\n"
+		} else if round > 1 {
+			frame = "\n" + frame
+		}
+		if _, err := io.WriteString(out, frame); err != nil {
+			return err
+		}
+		if watch.Count > 0 && round == watch.Count {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watch.Interval):
+		}
+	}
+	return nil
+}
+
+// render draws the dashboard as plain text.
+func (d *doctorDashboard) render(now time.Time) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Gateway %s  model %s  (%s, %d rounds)\n", d.endpoint, d.model, now.Sub(d.started).Round(time.Second), d.stream.total)
+	fmt.Fprintf(&builder, "%-10s %-8s %8s %8s %8s %7s\n", "probe", "status", "last", "p50", "p95", "errors")
+	d.renderRow(&builder, "request", &d.completion)
+	d.renderRow(&builder, "stream", &d.stream)
+	firstChunks := d.stream.successful(func(result probeResult) time.Duration { return result.FirstChunk })
+	gaps := d.stream.successful(func(result probeResult) time.Duration { return result.MaxGap })
+	if len(firstChunks) > 0 {
+		fmt.Fprintf(&builder, "Streaming: first chunk p50 %s p95 %s, longest gap %s", formatProbeDuration(percentile(firstChunks, 50)), formatProbeDuration(percentile(firstChunks, 95)), formatProbeDuration(percentile(gaps, 100)))
+		if percentile(gaps, 100) > doctorWatchStallGap {
+			builder.WriteString(" (stalls)")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("Verdict: " + d.verdict() + "\n")
+	for _, series := range []struct {
+		name   string
+		series *probeSeries
+	}{{"request", &d.completion}, {"stream", &d.stream}} {
+		if failure, ok := series.series.lastError(); ok {
+			fmt.Fprintf(&builder, "Last %s error (%s): %s\n", series.name, failure.At.Format("15:04:05"), truncateProbeError(failure.Err))
+		}
+	}
+	return builder.String()
+}
+
+// renderRow writes one probe row.
+func (d *doctorDashboard) renderRow(builder *strings.Builder, name string, series *probeSeries) {
+	if len(series.results) == 0 {
+		return
+	}
+	last := series.results[len(series.results)-1]
+	status, lastLatency := "ok", formatProbeDuration(last.Latency)
+	if last.Err != nil {
+		status, lastLatency = "FAIL", "-"
+	}
+	latencies := series.successful(func(result probeResult) time.Duration { return result.Latency })
+	p50, p95 := "-", "-"
+	if len(latencies) > 0 {
+		p50, p95 = formatProbeDuration(percentile(latencies, 50)), formatProbeDuration(percentile(latencies, 95))
+	}
+	fmt.Fprintf(builder, "%-10s %-8s %8s %8s %8s %6.0f%%\n", name, status, lastLatency, p50, p95, series.errorRate()*100)
+}
+
+// verdict summarizes gateway health in one line.
+func (d *doctorDashboard) verdict() string {
+	completionRate, streamRate := d.completion.errorRate(), d.stream.errorRate()
+	switch {
+	case completionRate == 1 && streamRate == 1:
+		return "gateway unreachable or rejecting requests"
+	case completionRate == 0 && streamRate > 0:
+		return "streaming is unreliable while plain requests succeed; check gateway or proxy buffering"
+	case completionRate > 0.2 || streamRate > 0.2:
+		return "gateway is flaky; failures here are not OpenClaude bugs"
+	case completionRate > 0 || streamRate > 0:
+		return "mostly healthy with occasional errors"
+	default:
+		return "healthy"
+	}
+}
+
+// percentile returns the nearest-rank percentile of values.
+func percentile(values []time.Duration, rank int) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := (rank*len(sorted)+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+// formatProbeDuration renders latencies in milliseconds below a second.
+func formatProbeDuration(duration time.Duration) string {
+	if duration < time.Second {
+		return fmt.Sprintf("%dms", duration.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", duration.Seconds())
+}
+
+// truncateProbeError keeps error lines readable on the dashboard.
+func truncateProbeError(err error) string {
+	message := strings.Join(strings.Fields(err.Error()), " ")
+	if len(message) > 160 {
+		message = message[:160] + "…"
+	}
+	return message
+}
+
+// gatewayDisplayURL drops credentials and query parameters from the gateway URL
+// so keys embedded in it never reach the screen.
+func gatewayDisplayURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "(invalid api_base_url)"
+	}
+	parsed.User = nil
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String()
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+const (
+	// maxMentionBytes caps how much of a mentioned file is inlined into the prompt.
+	maxMentionBytes = 256 * 1024
+	// maxMentionEntries caps directory listings inlined for @dir mentions.
+	maxMentionEntries = 200
+	// maxMentionSuggestions caps @-completion candidates.
+	maxMentionSuggestions = tuiSlashSuggestionLimit
+)
+
+// fileMentionPattern matches @path and @"path with spaces" at word starts.
+var fileMentionPattern = regexp.MustCompile(`(^|\s)@("[^"]+"|[^\s"]+)`)
+
+// mentionAccess decides which paths @-mentions may inline. A mention is
+// expanded only when the Read tool could read the path without asking: it must
+// sit inside the sandbox roots, not be hidden or denied, pass the permission
+// policy, and need no approval prompt.
+type mentionAccess struct {
+	// cwd is the directory relative mentions resolve against.
+	cwd string
+	// sandbox holds the workspace roots and hidden patterns.
+	sandbox *tools.Sandbox
+	// permissions are the session's tool permissions, checked as a Read call.
+	permissions tools.Permissions
+}
+
+// newMentionAccess builds the mention checks for a runner, falling back to a
+// sandbox rooted at cwd when the runner has none.
+func newMentionAccess(runner *agent.Runner, cwd string, hidePatterns []string) mentionAccess {
+	access := mentionAccess{cwd: cwd}
+	if runner != nil {
+		access.sandbox = runner.ToolContext.Sandbox
+		access.permissions = runner.Permissions
+	}
+	if access.sandbox == nil {
+		access.sandbox = tools.NewSandbox([]string{cwd})
+		access.sandbox.HidePatterns = hidePatterns
+	}
+	return access
+}
+
+// allows reports whether a resolved mention may be read into the prompt.
+func (a mentionAccess) allows(path string) bool {
+	if _, err := a.sandbox.ResolveFilePath(path, true); err != nil {
+		return false
+	}
+	args, err := json.Marshal(map[string]string{"file_path": path})
+	if err != nil {
+		return false
+	}
+	if a.permissions.CheckDeny("Read", args) != nil || a.permissions.Policy.Check("Read", args, a.cwd) != nil {
+		return false
+	}
+	return !a.permissions.ShouldPromptCall("Read", args)
+}
+
+// buildPromptContent expands @-mentions and attaches images for a user prompt,
+// returning the attached image labels and warnings for images left out.
+// Images are detected on the original prompt so inlined file text never adds attachments.
+func buildPromptContent(prompt string, access mentionAccess) (any, []string, []string) {
+	content, labels, warnings := promptImageContent(prompt, access.cwd)
+	expanded := expandFileMentions(prompt, access)
+	if parts, ok := content.([]llm.ContentPart); ok {
+		parts[0].Text = expanded
+		return parts, labels, warnings
+	}
+	return expanded, labels, warnings
+}
+
+// expandFileMentions inlines files and directories referenced as @path.
+// The mention stays in the prompt text and the content is appended in <file>
+// or <directory> blocks; unknown paths (e.g., @username) and paths access
+// refuses (outside the sandbox roots, hidden, or denied) are left untouched.
+func expandFileMentions(prompt string, access mentionAccess) string {
+	var blocks []string
+	seen := map[string]bool{}
+	cwd := access.cwd
+	for _, match := range fileMentionPattern.FindAllStringSubmatch(prompt, -1) {
+		mention := strings.Trim(match[2], `"`)
+		path, info, ok := resolveMention(mention, cwd)
+		if !ok || seen[path] || !access.allows(path) {
+			continue
+		}
+		seen[path] = true
+		if info.IsDir() {
+			blocks = append(blocks, formatDirectoryMention(path, mentionLabel(path, cwd), access.sandbox))
+			continue
+		}
+		// Images are attached as image parts rather than inlined as text.
+		if _, isImage := tools.ImageMediaType(path); isImage {
+			continue
+		}
+		if block, ok := formatFileMention(path, mentionLabel(path, cwd)); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return prompt
+	}
+	return prompt + "\n\n" + strings.Join(blocks, "\n\n")
+}
+
+// resolveMention finds the file a mention refers to, retrying without trailing punctuation.
+func resolveMention(mention string, cwd string) (string, os.FileInfo, bool) {
+	candidates := []string{mention}
+	if trimmed := strings.TrimRight(mention, ",.;:!?)"); trimmed != mention && trimmed != "" {
+		candidates = append(candidates, trimmed)
+	}
+	for _, candidate := range candidates {
+		path := resolvePromptPath(candidate, cwd)
+		if info, err := os.Stat(path); err == nil {
+			return path, info, true
+		}
+	}
+	return "", nil, false
+}
+
+// mentionLabel renders a path relative to cwd when it lives underneath it.
+func mentionLabel(path string, cwd string) string {
+	if relative, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(relative, "..") {
+		return filepath.ToSlash(relative)
+	}
+	return path
+}
+
+// formatFileMention reads a text file into a <file> block, truncating large files.
+func formatFileMention(path string, label string) (string, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+	// Read one byte past the cap to detect truncation.
+	data, err := io.ReadAll(io.LimitReader(file, maxMentionBytes+1))
+	if err != nil {
+		return "", false
+	}
+	// Binary files would only add noise; leave the mention as plain text.
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "", false
+	}
+	truncated := len(data) > maxMentionBytes
+	if truncated {
+		data = data[:maxMentionBytes]
+	}
+	content := strings.TrimRight(string(data), "\n")
+	if truncated {
+		content += fmt.Sprintf("\n... (truncated at %d bytes)", maxMentionBytes)
+	}
+	return fmt.Sprintf("<file path=%q>\n%s\n</file>", label, content), true
+}
+
+// formatDirectoryMention lists a directory's entries in a <directory> block,
+// leaving out hidden ones.
+func formatDirectoryMention(path string, label string, sandbox *tools.Sandbox) string {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Sprintf("<directory path=%q>\n(unreadable: %v)\n</directory>", label, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if sandbox.IsHidden(filepath.Join(path, entry.Name())) {
+			continue
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > maxMentionEntries {
+		remaining := len(names) - maxMentionEntries
+		names = append(names[:maxMentionEntries], fmt.Sprintf("... (%d more)", remaining))
+	}
+	return fmt.Sprintf("<directory path=%q>\n%s\n</directory>", label, strings.Join(names, "\n"))
+}
+
+// expandPrintMentions inlines @path mentions in a print-mode prompt when
+// --expand-file-mentions is set, relative to the runner's working directory.
+func expandPrintMentions(prompt string, opts *options, runner *agent.Runner) string {
+	if opts == nil || !opts.ExpandFileMentions {
+		return prompt
+	}
+	cwd := ""
+	if runner != nil {
+		cwd = runner.ToolContext.CWD
+	}
+	if cwd == "" {
+		var err error
+		if cwd, err = os.Getwd(); err != nil {
+			return prompt
+		}
+	}
+	return expandFileMentions(prompt, newMentionAccess(runner, cwd, settingsHidePatterns(opts)))
+}
+
+// settingsHidePatterns returns the hidePatterns setting, if settings are loaded.
+func settingsHidePatterns(opts *options) []string {
+	if opts == nil || opts.ClaudeSettings == nil {
+		return nil
+	}
+	return opts.ClaudeSettings.HidePatterns
+}
+
+// trailingMentionQuery returns the partial path after a trailing "@" token.
+func trailingMentionQuery(inputValue string) (string, bool) {
+	token := trailingInputToken(inputValue)
+	if !strings.HasPrefix(token, "@") {
+		return "", false
+	}
+	return strings.TrimPrefix(token, "@"), true
+}
+
+// fileMentionSuggestions lists paths completing a partial @-mention.
+// Completion is per directory level: "src/ma" lists entries of src starting with "ma".
+// Paths hidden by ignore files or the ignore patterns are not offered.
+func fileMentionSuggestions(cwd string, query string, ignorePatterns []string) []tuiSlashSuggestion {
+	dirPart, prefix := "", query
+	if index := strings.LastIndex(query, "/"); index >= 0 {
+		dirPart, prefix = query[:index+1], query[index+1:]
+	}
+	dir := resolvePromptPath(dirPart, cwd)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	ignore := tools.NewIgnoreMatcher(dir, ignorePatterns)
+	lowerPrefix := strings.ToLower(prefix)
+	var suggestions []tuiSlashSuggestion
+	for _, entry := range entries {
+		name := entry.Name()
+		// Hidden entries only appear when the user starts typing a dot.
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		if !strings.HasPrefix(strings.ToLower(name), lowerPrefix) || ignore.Ignored(filepath.Join(dir, name), entry.IsDir()) {
+			continue
+		}
+		completion := dirPart + name
+		description := "file"
+		if entry.IsDir() {
+			completion += "/"
+			description = "directory"
+		}
+		suggestions = append(suggestions, tuiSlashSuggestion{
+			Name:        completion,
+			Description: description,
+			AcceptsArgs: true,
+			Mention:     true,
+		})
+		if len(suggestions) >= maxMentionSuggestions {
+			break
+		}
+	}
+	return suggestions
+}
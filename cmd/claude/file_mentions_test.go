@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestExpandFileMentions verifies @path mentions inline files and directories with caps.
+func TestExpandFileMentions(testingHandle *testing.T) {
+	dir := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(dir, "src"), 0o755), "create src")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n"), 0o600), "write main.go")
+	large := strings.Repeat("x", maxMentionBytes+10)
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "big.txt"), []byte(large), 0o600), "write big.txt")
+
+	expanded := expandFileMentions("review @src/main.go, @big.txt and @src then ping @someone", newMentionAccess(nil, dir, nil))
+
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(expanded, "review @src/main.go, @big.txt"), "mentions stay in the prompt")
+	testutil.RequireTrue(testingHandle, strings.Contains(expanded, "<file path=\"src/main.go\">\npackage main\n</file>"), "file inlined")
+	testutil.RequireTrue(testingHandle, strings.Contains(expanded, "(truncated at 262144 bytes)"), "large file truncated")
+	testutil.RequireTrue(testingHandle, strings.Contains(expanded, "<directory path=\"src\">\nmain.go\n</directory>"), "directory listed")
+	testutil.RequireTrue(testingHandle, !strings.Contains(expanded, "someone\">"), "unknown mentions ignored")
+
+	testutil.RequireEqual(testingHandle, expandFileMentions("email me@example.com", newMentionAccess(nil, dir, nil)), "email me@example.com", "non-mentions unchanged")
+}
+
+// TestMentionSuggestionsComplete verifies typing @ suggests paths and accepting fills the input.
+func TestMentionSuggestionsComplete(testingHandle *testing.T) {
+	dir := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(dir, "src"), 0o755), "create src")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n"), 0o600), "write main.go")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, ".env"), []byte("SECRET=1\n"), 0o600), "write .env")
+
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	testingHandle.Chdir(dir)
+
+	model.input.SetValue("explain @s")
+	model.syncInputState()
+	testutil.RequireEqual(testingHandle, len(model.slashSuggestions), 1, "one suggestion for @s")
+	testutil.RequireEqual(testingHandle, suggestionLabel(model.slashSuggestions[0]), "@src/", "directory suggestion")
+
+	model.applySlashSuggestion()
+	testutil.RequireEqual(testingHandle, model.input.Value(), "explain @src/", "directory completion keeps going")
+	testutil.RequireEqual(testingHandle, len(model.slashSuggestions), 1, "next level suggested")
+
+	model.applySlashSuggestion()
+	testutil.RequireEqual(testingHandle, model.input.Value(), "explain @src/main.go ", "file completion")
+	testutil.RequireEqual(testingHandle, len(model.slashSuggestions), 0, "suggestions cleared")
+}
+
+// TestMentionSuggestionsSkipIgnored verifies @-completion hides paths from
+// ignore files and the ignorePatterns setting.
+func TestMentionSuggestionsSkipIgnored(testingHandle *testing.T) {
+	// Arrange
+	dir := testingHandle.TempDir()
+	for _, name := range []string{"build", "bundle"} {
+		testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(dir, name), 0o755), "create "+name)
+	}
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "budget.md"), []byte("plan\n"), 0o600), "write budget.md")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, ".openclaudeignore"), []byte("build/\n"), 0o600), "write openclaudeignore")
+
+	// Act
+	suggestions := fileMentionSuggestions(dir, "bu", []string{"bundle"})
+
+	// Assert
+	testutil.RequireEqual(testingHandle, len(suggestions), 1, "suggestions")
+	testutil.RequireEqual(testingHandle, suggestions[0].Name, "budget.md", "only the unignored entry")
+}
+
+// TestExpandFileMentionsSkipsHidden verifies @-mentions never inline files
+// hidden by .claudeignore or hidePatterns, nor list them in a directory.
+func TestExpandFileMentionsSkipsHidden(testingHandle *testing.T) {
+	// Arrange
+	dir := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(dir, "config"), 0o755), "create config")
+	files := map[string]string{
+		".claudeignore":      "*.key\n",
+		"config/app.yaml":    "port: 80\n",
+		"config/signing.key": "PRIVATE\n",
+		"config/prod.yaml":   "password: hunter2\n",
+	}
+	for name, content := range files {
+		testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600), "write "+name)
+	}
+
+	// Act
+	expanded := expandFileMentions("check @config/signing.key @config/prod.yaml @config", newMentionAccess(nil, dir, []string{"config/prod.yaml"}))
+
+	// Assert
+	testutil.RequireTrue(testingHandle, !strings.Contains(expanded, "PRIVATE") && !strings.Contains(expanded, "hunter2"), "hidden files not inlined: "+expanded)
+	testutil.RequireTrue(testingHandle, strings.Contains(expanded, "<directory path=\"config\">\napp.yaml\n</directory>"), "directory lists visible files: "+expanded)
+}
+
+// TestFileMentionsStayInsideWorkspace verifies mentions outside the sandbox
+// roots, or of paths a deny rule covers, are left as plain text, and print
+// mode only expands mentions with --expand-file-mentions.
+func TestFileMentionsStayInsideWorkspace(testingHandle *testing.T) {
+	// Arrange
+	outside := testingHandle.TempDir()
+	dir := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(outside, "credentials"), []byte("aws_secret\n"), 0o600), "write credentials")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, ".env"), []byte("TOKEN=1\n"), 0o600), "write .env")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes\n"), 0o600), "write notes")
+	runner := &agent.Runner{
+		ToolContext: tools.ToolContext{Sandbox: tools.NewSandbox([]string{dir}), CWD: dir},
+		Permissions: tools.Permissions{Mode: tools.PermissionDefault, Deny: []string{"Read(" + filepath.Join(dir, ".env") + ")"}},
+	}
+	prompt := "see @" + filepath.Join(outside, "credentials") + " @.env @notes.txt"
+
+	// Act
+	expanded := expandFileMentions(prompt, newMentionAccess(runner, dir, nil))
+	printDefault := expandPrintMentions(prompt, &options{}, runner)
+	printOptIn := expandPrintMentions(prompt, &options{ExpandFileMentions: true}, runner)
+
+	// Assert
+	testutil.RequireTrue(testingHandle, !strings.Contains(expanded, "aws_secret"), "outside roots not inlined: "+expanded)
+	testutil.RequireTrue(testingHandle, !strings.Contains(expanded, "TOKEN=1"), "denied path not inlined: "+expanded)
+	testutil.RequireTrue(testingHandle, strings.Contains(expanded, "<file path=\"notes.txt\">\nnotes\n</file>"), "workspace file inlined: "+expanded)
+	testutil.RequireEqual(testingHandle, printDefault, prompt, "print mode leaves mentions alone by default")
+	testutil.RequireEqual(testingHandle, printOptIn, expanded, "print mode expands with the flag")
+}
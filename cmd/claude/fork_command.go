@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/openclaude/openclaude/internal/session"
+)
+
+// forkConversation handles /fork: the conversation so far is copied into a
+// new session that the TUI continues in, so the original thread stays as it
+// was. Esc opens the message selector, where f forks at an earlier message.
+func (m *tuiModel) forkConversation(_ string) string {
+	if m.running {
+		return "Wait for the response to finish, or press Esc to interrupt it, before forking."
+	}
+	parent := m.sessionID
+	forkID, err := m.forkSession(0)
+	if err != nil {
+		return fmt.Sprintf("Fork failed: %v", err)
+	}
+	return formatForkNotice(parent, forkID)
+}
+
+// forkSession stores the history, minus its last drop messages, as a new
+// session branched from the current one and switches to it. Older pages not
+// loaded yet are read first, so the fork holds the whole conversation.
+func (m *tuiModel) forkSession(drop int) (string, error) {
+	if m.store == nil || m.sessionID == "" {
+		return "", errors.New("session persistence is off")
+	}
+	for m.historyCursor.HasMore {
+		if _, err := m.prependOlderHistory(); err != nil {
+			return "", fmt.Errorf("load older history: %w", err)
+		}
+	}
+	m.historyCursor = sessionHistoryCursor{}
+	forkID := uuid.NewString()
+	if err := m.store.ForkSession(m.sessionID, forkID, m.history[:len(m.history)-drop]); err != nil {
+		return "", err
+	}
+	m.sessionID = forkID
+	m.persisted = len(m.history) - drop
+	if m.runner != nil {
+		m.runner.ToolContext.SessionID = forkID
+	}
+	_ = m.store.SaveLastSession(session.ProjectHash(mustCwd()), forkID)
+	return forkID, nil
+}
+
+// formatForkNotice tells the user where the conversation continues and how
+// to get back to the original.
+func formatForkNotice(parent string, forkID string) string {
+	return fmt.Sprintf("Forked into session %s. Session %s is unchanged; resume it with --resume %s.", forkID, parent, parent)
+}
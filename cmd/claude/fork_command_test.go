@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestForkConversation verifies /fork copies the whole conversation, older
+// pages included, into a new session, and that f in the message selector
+// forks at the selected message.
+func TestForkConversation(testingHandle *testing.T) {
+	// Arrange a resumed session whose first exchange is still on disk only.
+	testingHandle.Chdir(testingHandle.TempDir())
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	stored := []llm.Message{
+		{Role: "user", Content: "first"}, {Role: "assistant", Content: "one"},
+		{Role: "user", Content: "second"}, {Role: "assistant", Content: "two"},
+	}
+	testutil.RequireNoError(testingHandle, persistSession(store, "parent", stored, nil), "persist parent")
+	history, cursor, err := loadSessionMessages(store, "parent", 2)
+	testutil.RequireNoError(testingHandle, err, "load parent")
+	testutil.RequireTrue(testingHandle, cursor.HasMore, "older page left on disk")
+	model := newTUIModel(&options{}, nil, history, cursor, "sys", "model", "parent", store)
+
+	// Act
+	notice := model.forkConversation("")
+	forkID := model.sessionID
+	model.openMessageSelector()
+	model.selectorIndex = 1
+	model.handleSelectorKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+
+	// Assert
+	testutil.RequireTrue(testingHandle, forkID != "parent", "switched to the fork")
+	testutil.RequireTrue(testingHandle, strings.Contains(notice, "Session parent is unchanged; resume it with --resume parent."), notice)
+	forked, _, err := loadSessionMessages(store, forkID, 0)
+	testutil.RequireNoError(testingHandle, err, "load fork")
+	testutil.RequireEqual(testingHandle, forked, stored, "fork holds the whole conversation")
+
+	testutil.RequireTrue(testingHandle, model.sessionID != forkID, "selector fork is a new session")
+	testutil.RequireEqual(testingHandle, model.input.Value(), "second", "selected message back in the input")
+	testutil.RequireEqual(testingHandle, len(model.history), 3, "history rewound to the selected message")
+	rewound, _, err := loadSessionMessages(store, model.sessionID, 0)
+	testutil.RequireNoError(testingHandle, err, "load selector fork")
+	testutil.RequireEqual(testingHandle, rewound, stored[:2], "selector fork holds the messages before the selection")
+	parent, _, err := loadSessionMessages(store, "parent", 0)
+	testutil.RequireNoError(testingHandle, err, "load parent")
+	testutil.RequireEqual(testingHandle, parent, stored, "parent untouched")
+
+	metas, err := store.ListSessionMeta(0)
+	testutil.RequireNoError(testingHandle, err, "list sessions")
+	forks := sessionForks(metas, forkID, 0)
+	testutil.RequireEqual(testingHandle, len(forks), 1, "forks of the first fork")
+	testutil.RequireEqual(testingHandle, forks[0].ID, model.sessionID, "selector fork listed")
+}
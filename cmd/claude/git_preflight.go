@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/openclaude/openclaude/internal/errcode"
+	"github.com/openclaude/openclaude/internal/project"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/streamjson"
+)
+
+// gitPreflightStashMessage labels stashes made by the pre-flight check.
+const gitPreflightStashMessage = "openclaude: uncommitted changes before agent edits"
+
+// preflightChoice is the user's answer to a pre-flight warning.
+type preflightChoice int
+
+const (
+	// preflightProceed lets the edit run on top of the uncommitted changes.
+	preflightProceed preflightChoice = iota
+	// preflightStash stashes the uncommitted changes, then runs the edit.
+	preflightStash
+	// preflightCancel stops the run before the edit.
+	preflightCancel
+)
+
+// gitPreflightWarning describes an edit that would touch uncommitted work.
+type gitPreflightWarning struct {
+	// ToolName is the edit tool about to run.
+	ToolName string
+	// Files lists the uncommitted files the edit touches, relative to the working directory.
+	Files []string
+	// Dirty counts all uncommitted files in the repository.
+	Dirty int
+}
+
+// gitPreflightAsker decides what to do about a warning.
+type gitPreflightAsker func(warning gitPreflightWarning) (preflightChoice, error)
+
+// gitPreflight warns, once per session, before the agent edits a file that
+// already had uncommitted changes. The working tree is snapshotted at the
+// first edit call, so files the agent changes itself never trigger it.
+type gitPreflight struct {
+	// dir is the working directory the repository is looked up from.
+	dir string
+	// mu serializes checks, including parallel subagents.
+	mu sync.Mutex
+	// dirty holds the uncommitted files at the first edit; nil until then.
+	dirty map[string]bool
+	// resolved is set once a warning was answered; later edits are not checked.
+	resolved bool
+}
+
+// newGitPreflight returns the pre-flight check for a session, or nil when git
+// status is disabled or the directory is not a git work tree.
+func newGitPreflight(opts *options, dir string) *gitPreflight {
+	if opts == nil || opts.GitStatus == nil {
+		return nil
+	}
+	return &gitPreflight{dir: dir}
+}
+
+// Check runs before each tool call. Edits of files that had uncommitted
+// changes are reported to ask; cancelling returns an *errcode.ToolDeniedError.
+func (p *gitPreflight) Check(ctx context.Context, toolName string, args json.RawMessage, ask gitPreflightAsker) error {
+	if p == nil {
+		return nil
+	}
+	target := session.EditToolPath(toolName, args)
+	if target == "" {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resolved {
+		return nil
+	}
+	if p.dirty == nil {
+		p.dirty = map[string]bool{}
+		files, err := project.GitDirtyFiles(ctx, p.dir)
+		if err != nil {
+			// Outside a work tree there is nothing to protect.
+			p.resolved = true
+			return nil
+		}
+		for _, file := range files {
+			p.dirty[canonicalPreflightPath(file)] = true
+		}
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(p.dir, target)
+	}
+	if !p.dirty[canonicalPreflightPath(target)] {
+		return nil
+	}
+
+	p.resolved = true
+	display := target
+	if rel, err := filepath.Rel(p.dir, target); err == nil {
+		display = rel
+	}
+	choice, err := ask(gitPreflightWarning{ToolName: toolName, Files: []string{display}, Dirty: len(p.dirty)})
+	if err != nil {
+		return err
+	}
+	switch choice {
+	case preflightCancel:
+		return &errcode.ToolDeniedError{ToolName: toolName, Detail: "uncommitted changes in " + display}
+	case preflightStash:
+		return project.GitStash(ctx, p.dir, gitPreflightStashMessage)
+	}
+	return nil
+}
+
+// canonicalPreflightPath resolves symlinks so tool paths compare equal to git's paths.
+func canonicalPreflightPath(path string) string {
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		return filepath.Join(dir, filepath.Base(path))
+	}
+	return path
+}
+
+// String renders the warning for notices and stderr.
+func (w gitPreflightWarning) String() string {
+	return fmt.Sprintf("%s is about to modify %s, which has uncommitted changes (%d uncommitted file(s) in the repository).", w.ToolName, w.Files[0], w.Dirty)
+}
+
+// warnPreflightTo returns an asker for non-interactive runs that writes the
+// warning to out and proceeds.
+func warnPreflightTo(out io.Writer) gitPreflightAsker {
+	return func(warning gitPreflightWarning) (preflightChoice, error) {
+		fmt.Fprintf(out, "Warning: %s Proceeding; agent edits will mix with your work.\n", warning)
+		return preflightProceed, nil
+	}
+}
+
+// emitPreflightEvent returns an asker for stream-json runs that reports the
+// warning as a system git_preflight event and proceeds.
+func emitPreflightEvent(writer *streamjson.Writer, sessionID string) gitPreflightAsker {
+	return func(warning gitPreflightWarning) (preflightChoice, error) {
+		err := writer.Write(streamjson.GitPreflightEvent{
+			Type:       "system",
+			Subtype:    "git_preflight",
+			ToolName:   warning.ToolName,
+			Files:      warning.Files,
+			DirtyCount: warning.Dirty,
+			Action:     "proceed",
+			SessionID:  sessionID,
+			UUID:       streamjson.NewUUID(),
+		})
+		return preflightProceed, err
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/project"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestGitPreflightWarnsOnDirtyEdits verifies only edits of files that were
+// already uncommitted are reported, once, and that each answer is honored.
+func TestGitPreflightWarnsOnDirtyEdits(testingHandle *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		testingHandle.Skip("git not installed")
+	}
+	tests := []struct {
+		name      string
+		choice    preflightChoice
+		wantErr   error
+		wantDirty int
+	}{
+		{name: "proceed", choice: preflightProceed, wantDirty: 1},
+		{name: "stash", choice: preflightStash, wantDirty: 0},
+		{name: "cancel", choice: preflightCancel, wantErr: agent.ErrToolDenied, wantDirty: 1},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Arrange a repository where base.txt has uncommitted work.
+			root := reviewGitRepo(testingHandle)
+			testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "base.txt"), []byte("user draft\n"), 0o644), "write draft")
+			preflight := newGitPreflight(&options{GitStatus: &project.GitStatus{}}, root)
+			var warnings []gitPreflightWarning
+			ask := func(warning gitPreflightWarning) (preflightChoice, error) {
+				warnings = append(warnings, warning)
+				return tt.choice, nil
+			}
+			edit := func(path string) json.RawMessage {
+				payload, _ := json.Marshal(map[string]string{"file_path": path})
+				return payload
+			}
+
+			// Act.
+			readErr := preflight.Check(context.Background(), "Read", edit("base.txt"), ask)
+			cleanErr := preflight.Check(context.Background(), "Write", edit(filepath.Join(root, "new.txt")), ask)
+			dirtyErr := preflight.Check(context.Background(), "Edit", edit("base.txt"), ask)
+			againErr := preflight.Check(context.Background(), "Edit", edit("base.txt"), ask)
+
+			// Assert.
+			testutil.RequireNoError(testingHandle, readErr, "read is not an edit")
+			testutil.RequireNoError(testingHandle, cleanErr, "clean file")
+			testutil.RequireNoError(testingHandle, againErr, "warned once")
+			if tt.wantErr == nil {
+				testutil.RequireNoError(testingHandle, dirtyErr, "dirty edit")
+			} else {
+				testutil.RequireTrue(testingHandle, errors.Is(dirtyErr, tt.wantErr), fmt.Sprintf("dirty edit error: %v", dirtyErr))
+			}
+			testutil.RequireEqual(testingHandle, len(warnings), 1, "warnings")
+			testutil.RequireEqual(testingHandle, warnings[0].Files, []string{"base.txt"}, "files")
+			testutil.RequireTrue(testingHandle, strings.Contains(warnings[0].String(), "Edit is about to modify base.txt"), warnings[0].String())
+			testutil.RequireEqual(testingHandle, project.LoadGitStatus(context.Background(), root).Dirty, tt.wantDirty, "dirty after answer")
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultWorkflowPath is where the generated workflow is written, relative to the repository root.
+const defaultWorkflowPath = ".github/workflows/openclaude.yml"
+
+// githubWorkflowOptions customizes the generated GitHub Actions workflow.
+type githubWorkflowOptions struct {
+	// Trigger is the mention that starts a run (e.g., @claude).
+	Trigger string
+	// Model overrides the provider default model; empty defers to the OPENCLAUDE_MODEL variable.
+	Model string
+	// AllowedTools is passed to --allowedTools.
+	AllowedTools string
+	// MaxTurns caps agent turns per run.
+	MaxTurns int
+}
+
+// defaultGitHubWorkflowOptions returns the generator defaults.
+func defaultGitHubWorkflowOptions() githubWorkflowOptions {
+	return githubWorkflowOptions{
+		Trigger:      "@claude",
+		AllowedTools: "Bash,Read,Edit,Write,Glob,Grep",
+		MaxTurns:     30,
+	}
+}
+
+// installGitHubAppCommand scaffolds a GitHub Actions workflow that answers
+// @claude mentions on issues and pull requests by running OpenClaude in print mode.
+func installGitHubAppCommand() *cobra.Command {
+	workflow := defaultGitHubWorkflowOptions()
+	var dryRun, force bool
+	var path string
+	cmd := &cobra.Command{
+		Use:   "install-github-app",
+		Short: "Generate a GitHub Actions workflow that runs OpenClaude on @claude mentions",
+		Long: "Write a GitHub Actions workflow that runs OpenClaude in print mode with stream-json output when an issue or pull request comment mentions the trigger.\n" +
+			"The workflow reads the gateway URL and key from repository secrets; see docs/github-actions.md.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := validateGitHubWorkflowOptions(workflow); err != nil {
+				return err
+			}
+			content := renderGitHubWorkflow(workflow)
+			if dryRun {
+				_, err := io.WriteString(cmd.OutOrStdout(), content)
+				return err
+			}
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get cwd: %w", err)
+			}
+			target, err := writeGitHubWorkflow(cwd, path, content, force)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n\n%s\n", target, githubWorkflowNextSteps)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the workflow instead of writing it")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing workflow file")
+	cmd.Flags().StringVar(&path, "path", defaultWorkflowPath, "Workflow file path, relative to the repository root")
+	cmd.Flags().StringVar(&workflow.Trigger, "trigger", workflow.Trigger, "Mention that starts a run")
+	cmd.Flags().StringVar(&workflow.Model, "model", "", "Model for CI runs (defaults to the OPENCLAUDE_MODEL repository variable, then the provider default)")
+	cmd.Flags().StringVar(&workflow.AllowedTools, "allowed-tools", workflow.AllowedTools, "Tools the workflow may use without prompting")
+	cmd.Flags().IntVar(&workflow.MaxTurns, "max-turns", workflow.MaxTurns, "Maximum agent turns per run")
+	return cmd
+}
+
+// runInstallGitHubAppSlash handles /install-github-app [--dry-run] [--force] in the TUI.
+func runInstallGitHubAppSlash(_ *options, args string) string {
+	var dryRun, force bool
+	for _, field := range strings.Fields(args) {
+		switch field {
+		case "--dry-run":
+			dryRun = true
+		case "--force":
+			force = true
+		default:
+			return "Usage: /install-github-app [--dry-run] [--force]. Run claude install-github-app --help for more options."
+		}
+	}
+	content := renderGitHubWorkflow(defaultGitHubWorkflowOptions())
+	if dryRun {
+		return content
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Sprintf("get cwd: %v", err)
+	}
+	target, err := writeGitHubWorkflow(cwd, defaultWorkflowPath, content, force)
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("Wrote %s\n\n%s", target, githubWorkflowNextSteps)
+}
+
+// validateGitHubWorkflowOptions rejects values that would break the generated YAML.
+func validateGitHubWorkflowOptions(workflow githubWorkflowOptions) error {
+	for name, value := range map[string]string{"trigger": workflow.Trigger, "model": workflow.Model, "allowed-tools": workflow.AllowedTools} {
+		if strings.ContainsAny(value, "'\"\n\r$`\\") {
+			return fmt.Errorf("--%s must not contain quotes, backslashes, $, backticks, or newlines", name)
+		}
+	}
+	if strings.TrimSpace(workflow.Trigger) == "" {
+		return errors.New("--trigger must not be empty")
+	}
+	if workflow.MaxTurns <= 0 {
+		return errors.New("--max-turns must be positive")
+	}
+	return nil
+}
+
+// writeGitHubWorkflow writes content under the repository root containing cwd
+// and returns the written path. Existing files are kept unless force is set.
+func writeGitHubWorkflow(cwd string, path string, content string, force bool) (string, error) {
+	root := cwd
+	if output, err := runReviewCommand(context.Background(), cwd, "git", "rev-parse", "--show-toplevel"); err == nil {
+		root = strings.TrimSpace(output)
+	}
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, path)
+	}
+	if _, err := os.Stat(target); err == nil && !force {
+		return "", fmt.Errorf("%s already exists; use --force to overwrite or --dry-run to compare", target)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("create workflow dir: %w", err)
+	}
+	if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("write workflow: %w", err)
+	}
+	return target, nil
+}
+
+// renderGitHubWorkflow fills the workflow template.
+func renderGitHubWorkflow(workflow githubWorkflowOptions) string {
+	model := "${{ vars.OPENCLAUDE_MODEL }}"
+	if workflow.Model != "" {
+		model = workflow.Model
+	}
+	return strings.NewReplacer(
+		"__TRIGGER__", workflow.Trigger,
+		"__MODEL__", model,
+		"__ALLOWED_TOOLS__", workflow.AllowedTools,
+		"__MAX_TURNS__", strconv.Itoa(workflow.MaxTurns),
+	).Replace(githubWorkflowTemplate)
+}
+
+// githubWorkflowNextSteps lists the repository setup the workflow needs.
+const githubWorkflowNextSteps = `Next steps:
+  1. Add repository secrets (Settings > Secrets and variables > Actions):
+       gh secret set OPENCLAUDE_API_BASE_URL   # your OpenAI-compatible gateway URL
+       gh secret set OPENCLAUDE_API_KEY        # gateway API key
+     Optionally set a default model: gh variable set OPENCLAUDE_MODEL --body <model>
+  2. Allow the workflow to push branches and comment (Settings > Actions > General >
+     Workflow permissions: "Read and write permissions").
+  3. Commit the workflow and mention the trigger in an issue or pull request comment.
+See docs/github-actions.md for details.`
+
+// githubWorkflowTemplate is the generated workflow. Event text reaches the shell
+// only through environment variables, never through ${{ }} interpolation in
+// run scripts, so comment bodies cannot inject commands.
+const githubWorkflowTemplate = `# Generated by claude install-github-app. Runs OpenClaude when a comment mentions __TRIGGER__.
+# Required secrets: OPENCLAUDE_API_BASE_URL, OPENCLAUDE_API_KEY. Optional variable: OPENCLAUDE_MODEL.
+name: OpenClaude
+
+on:
+  issue_comment:
+    types: [created]
+  pull_request_review_comment:
+    types: [created]
+  pull_request_review:
+    types: [submitted]
+  issues:
+    types: [opened, assigned]
+
+permissions:
+  contents: write
+  issues: write
+  pull-requests: write
+
+concurrency:
+  group: openclaude-${{ github.event.issue.number || github.event.pull_request.number }}
+  cancel-in-progress: false
+
+jobs:
+  openclaude:
+    if: >-
+      contains(github.event.comment.body || github.event.review.body || github.event.issue.body || '', '__TRIGGER__') &&
+      contains(fromJSON('["OWNER","MEMBER","COLLABORATOR"]'), github.event.comment.author_association || github.event.review.author_association || github.event.issue.author_association)
+    runs-on: ubuntu-latest
+    timeout-minutes: 30
+    env:
+      GH_TOKEN: ${{ github.token }}
+      NUMBER: ${{ github.event.issue.number || github.event.pull_request.number }}
+      IS_PR: ${{ github.event.issue.pull_request != null || github.event.pull_request != null }}
+      REQUEST: ${{ github.event.comment.body || github.event.review.body || github.event.issue.body }}
+      TITLE: ${{ github.event.issue.title || github.event.pull_request.title }}
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          fetch-depth: 0
+
+      - name: Check out the pull request branch
+        if: env.IS_PR == 'true'
+        run: gh pr checkout "$NUMBER"
+
+      - uses: actions/setup-go@v5
+        with:
+          go-version: stable
+
+      - name: Install OpenClaude
+        run: go install github.com/openclaude/openclaude/cmd/claude@latest
+
+      - name: Configure the gateway
+        env:
+          OPENCLAUDE_API_BASE_URL: ${{ secrets.OPENCLAUDE_API_BASE_URL }}
+          OPENCLAUDE_API_KEY: ${{ secrets.OPENCLAUDE_API_KEY }}
+          OPENCLAUDE_MODEL: __MODEL__
+        run: |
+          umask 077
+          mkdir -p ~/.openclaude
+          jq -n --arg url "$OPENCLAUDE_API_BASE_URL" --arg key "$OPENCLAUDE_API_KEY" --arg model "$OPENCLAUDE_MODEL" \
+            '{api_base_url: $url, api_key: $key} + (if $model == "" then {} else {default_model: $model} end)' > ~/.openclaude/config.json
+          chmod 600 ~/.openclaude/config.json
+
+      - name: Run OpenClaude
+        run: |
+          kind=issue
+          if [ "$IS_PR" = "true" ]; then kind="pull request"; fi
+          prompt=$(printf 'You were mentioned on GitHub %s #%s ("%s"). Request:\n\n%s\n\nWork in the checked-out repository. Make the requested changes if any, and end with a short summary for the thread.' "$kind" "$NUMBER" "$TITLE" "$REQUEST")
+          claude -p "$prompt" --output-format stream-json --verbose \
+            --permission-mode acceptEdits --allowedTools "__ALLOWED_TOOLS__" --max-turns __MAX_TURNS__ \
+            > openclaude.jsonl || echo "::warning::OpenClaude exited with an error"
+          jq -r 'select(.type == "result") | .result // empty' openclaude.jsonl > reply.md
+          if [ ! -s reply.md ]; then echo "OpenClaude did not produce a result. See the workflow logs." > reply.md; fi
+
+      - name: Push changes
+        run: |
+          if [ -z "$(git status --porcelain)" ]; then exit 0; fi
+          git config user.name "openclaude[bot]"
+          git config user.email "openclaude[bot]@users.noreply.github.com"
+          git add -A
+          git commit -m "OpenClaude: changes for #$NUMBER"
+          if [ "$IS_PR" = "true" ]; then
+            git push
+            printf '\n\nPushed the changes to this pull request.' >> reply.md
+          else
+            branch="openclaude/issue-$NUMBER-$GITHUB_RUN_ID"
+            git push origin "HEAD:$branch"
+            printf '\n\nPushed the changes to [%s](%s/%s/compare/%s?expand=1).' "$branch" "$GITHUB_SERVER_URL" "$GITHUB_REPOSITORY" "$branch" >> reply.md
+          fi
+
+      - name: Reply
+        if: always()
+        run: |
+          if [ ! -s reply.md ]; then echo "OpenClaude failed before producing a result. See the workflow logs." > reply.md; fi
+          gh issue comment "$NUMBER" --body-file reply.md
+
+      - uses: actions/upload-artifact@v4
+        if: always()
+        with:
+          name: openclaude-transcript
+          path: openclaude.jsonl
+`
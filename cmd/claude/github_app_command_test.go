@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestRenderGitHubWorkflow verifies the workflow runs print mode with stream-json
+// and keeps event text out of run scripts.
+func TestRenderGitHubWorkflow(testingHandle *testing.T) {
+	options := defaultGitHubWorkflowOptions()
+	options.Trigger = "@openclaude"
+	options.MaxTurns = 12
+	workflow := renderGitHubWorkflow(options)
+	for _, want := range []string{
+		"'@openclaude')",
+		"claude -p \"$prompt\" --output-format stream-json --verbose",
+		"--allowedTools \"Bash,Read,Edit,Write,Glob,Grep\" --max-turns 12",
+		"OPENCLAUDE_API_KEY: ${{ secrets.OPENCLAUDE_API_KEY }}",
+		"OPENCLAUDE_MODEL: ${{ vars.OPENCLAUDE_MODEL }}",
+		"chmod 600 ~/.openclaude/config.json",
+	} {
+		testutil.RequireTrue(testingHandle, strings.Contains(workflow, want), "workflow contains "+want)
+	}
+	testutil.RequireTrue(testingHandle, !strings.Contains(workflow, "__"), "all placeholders replaced")
+
+	runIndent := -1
+	for _, line := range strings.Split(workflow, "\n") {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if runIndent >= 0 && strings.TrimSpace(line) != "" && indent <= runIndent {
+			runIndent = -1
+		}
+		if runIndent >= 0 || strings.HasPrefix(strings.TrimSpace(line), "run:") {
+			testutil.RequireTrue(testingHandle, !strings.Contains(line, "${{"), "no expressions in run scripts: "+line)
+		}
+		if strings.TrimSpace(line) == "run: |" {
+			runIndent = indent
+		}
+	}
+
+	options.Model = "gpt-4.1"
+	testutil.RequireTrue(testingHandle, strings.Contains(renderGitHubWorkflow(options), "OPENCLAUDE_MODEL: gpt-4.1\n"), "model override")
+}
+
+// TestValidateGitHubWorkflowOptions verifies values that could break the YAML are rejected.
+func TestValidateGitHubWorkflowOptions(testingHandle *testing.T) {
+	testutil.RequireNoError(testingHandle, validateGitHubWorkflowOptions(defaultGitHubWorkflowOptions()), "defaults")
+	for name, mutate := range map[string]func(*githubWorkflowOptions){
+		"dollar trigger": func(options *githubWorkflowOptions) { options.Trigger = "$(id)" },
+		"quoted model":   func(options *githubWorkflowOptions) { options.Model = "a'b" },
+		"newline tools":  func(options *githubWorkflowOptions) { options.AllowedTools = "Bash\nRead" },
+		"empty trigger":  func(options *githubWorkflowOptions) { options.Trigger = " " },
+		"zero turns":     func(options *githubWorkflowOptions) { options.MaxTurns = 0 },
+	} {
+		options := defaultGitHubWorkflowOptions()
+		mutate(&options)
+		testutil.RequireTrue(testingHandle, validateGitHubWorkflowOptions(options) != nil, "rejects "+name)
+	}
+}
+
+// TestWriteGitHubWorkflow verifies the file is created and not overwritten without force.
+func TestWriteGitHubWorkflow(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	target, err := writeGitHubWorkflow(root, defaultWorkflowPath, "first", false)
+	testutil.RequireNoError(testingHandle, err, "write workflow")
+	testutil.RequireEqual(testingHandle, target, filepath.Join(root, defaultWorkflowPath), "target path")
+
+	_, err = writeGitHubWorkflow(root, defaultWorkflowPath, "second", false)
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "--force"), "refuses overwrite")
+	_, err = writeGitHubWorkflow(root, defaultWorkflowPath, "second", true)
+	testutil.RequireNoError(testingHandle, err, "forced overwrite")
+	content, err := os.ReadFile(target)
+	testutil.RequireNoError(testingHandle, err, "read workflow")
+	testutil.RequireEqual(testingHandle, string(content), "second", "overwritten content")
+}
+
+// TestInstallGitHubAppDryRun verifies --dry-run prints the workflow without writing it.
+func TestInstallGitHubAppDryRun(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	testingHandle.Chdir(root)
+	cmd := installGitHubAppCommand()
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--dry-run", "--trigger", "@bot"})
+	testutil.RequireNoError(testingHandle, cmd.Execute(), "dry run")
+	testutil.RequireTrue(testingHandle, strings.Contains(stdout.String(), "'@bot')"), "printed workflow")
+	_, err := os.Stat(filepath.Join(root, defaultWorkflowPath))
+	testutil.RequireTrue(testingHandle, os.IsNotExist(err), "nothing written")
+
+	cmd = installGitHubAppCommand()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stdout)
+	cmd.SetArgs([]string{"--dry-run", "--trigger", "@x`id`"})
+	testutil.RequireTrue(testingHandle, cmd.Execute() != nil, "invalid trigger")
+
+	testutil.RequireEqual(testingHandle, runInstallGitHubAppSlash(nil, "--dry-run"), renderGitHubWorkflow(defaultGitHubWorkflowOptions()), "slash dry run")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(runInstallGitHubAppSlash(nil, "--bogus"), "Usage:"), "slash usage")
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// slashCategoryBuiltin groups commands mirrored from Claude Code.
+	slashCategoryBuiltin = "Built-in commands"
+	// slashCategoryInteractive groups OpenClaude-only TUI commands.
+	slashCategoryInteractive = "OpenClaude commands"
+	// slashCategoryCustom groups .claude/commands markdown files.
+	slashCategoryCustom = "Custom commands"
+	// slashCategoryPlugin groups commands shipped in --plugin-dir directories.
+	slashCategoryPlugin = "Plugin commands"
+)
+
+// slashCommandInfo describes one slash command for suggestions and /help.
+type slashCommandInfo struct {
+	// Name is the command without its leading slash.
+	Name string
+	// Description is the one-line summary shown next to the name.
+	Description string
+	// Category groups the command in /help output.
+	Category string
+	// AcceptsArgs reports whether the command takes arguments.
+	AcceptsArgs bool
+	// ArgumentHint renders the argument schema, e.g. "[filter]".
+	ArgumentHint string
+	// Source is the markdown file defining a custom or plugin command.
+	Source string
+}
+
+// tuiKeybinding documents one TUI key for /help and /keybindings-help.
+type tuiKeybinding struct {
+	// Keys is the key or chord as displayed.
+	Keys string
+	// Action describes what the key does.
+	Action string
+}
+
+// tuiKeybindings lists the TUI keys handled by tuiModel.handleKey.
+var tuiKeybindings = []tuiKeybinding{
+	{Keys: "enter", Action: "Submit the prompt"},
+	{Keys: "alt+enter, ctrl+j, \\⏎", Action: "Insert a newline"},
+	{Keys: "/", Action: "Start a slash command"},
+	{Keys: "!", Action: "Switch to bash mode"},
+	{Keys: "@", Action: "Mention a file or directory"},
+	{Keys: "tab", Action: "Complete a path or tool name, accept a suggestion, or cycle panes"},
+	{Keys: "shift+tab", Action: "Select the previous suggestion or cycle panes"},
+	{Keys: "up, down, ctrl+p, ctrl+n", Action: "Browse input history"},
+	{Keys: "pgup, pgdown, home, end", Action: "Scroll the active pane"},
+	{Keys: "mouse wheel", Action: "Scroll the chat or the transcript"},
+	{Keys: "click", Action: "Focus the chat or the input; expand or collapse a long tool result"},
+	{Keys: "shift+drag", Action: "Select text while the TUI captures the mouse"},
+	{Keys: "esc", Action: "Revert pasted paths, clear input (twice), or open the message selector"},
+	{Keys: "ctrl+v", Action: "Paste a clipboard image as an @-mention, or clipboard text"},
+	{Keys: "ctrl+t", Action: "Toggle thinking output"},
+	{Keys: "ctrl+o", Action: "Toggle verbose tool inputs and outputs for new messages"},
+	{Keys: "ctrl+x", Action: "Expand or collapse the newest long tool output in view"},
+	{Keys: "ctrl+r", Action: "Show the full transcript (/ to search, esc to close)"},
+	{Keys: "ctrl+l", Action: "Clear the input"},
+	{Keys: "ctrl+c", Action: "Cancel the response, or exit (twice)"},
+	{Keys: "ctrl+d", Action: "Exit on empty input (twice)"},
+	{Keys: "ctrl+q", Action: "Quit"},
+}
+
+// slashCommandCatalog lists every slash command available in this session.
+// Registry commands come first, followed by markdown commands
+// discovered in .claude/commands directories and --plugin-dir plugins.
+func slashCommandCatalog(opts *options) []slashCommandInfo {
+	var catalog []slashCommandInfo
+	for _, spec := range commandsForMode(commandModeTUI) {
+		catalog = append(catalog, slashCommandInfo{
+			Name:         spec.Name,
+			Description:  spec.Description,
+			Category:     spec.Category,
+			AcceptsArgs:  len(spec.Args) > 0,
+			ArgumentHint: spec.argumentHint(),
+		})
+	}
+	seen := map[string]bool{}
+	for _, info := range catalog {
+		seen[info.Name] = true
+	}
+	for _, info := range discoverMarkdownCommands(opts) {
+		if seen[info.Name] {
+			continue
+		}
+		seen[info.Name] = true
+		catalog = append(catalog, info)
+	}
+	return catalog
+}
+
+// discoverMarkdownCommands finds custom and plugin command files.
+// Project commands shadow user commands of the same name, matching Claude Code.
+func discoverMarkdownCommands(opts *options) []slashCommandInfo {
+	var dirs []string
+	var categories []string
+	if cwd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, filepath.Join(cwd, ".claude", "commands"))
+		categories = append(categories, slashCategoryCustom)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".claude", "commands"))
+		categories = append(categories, slashCategoryCustom)
+	}
+	if opts != nil {
+		for _, pluginDir := range opts.PluginDir {
+			if pluginDir == "" {
+				continue
+			}
+			dirs = append(dirs, filepath.Join(pluginDir, "commands"))
+			categories = append(categories, slashCategoryPlugin)
+		}
+	}
+
+	var commands []slashCommandInfo
+	seen := map[string]bool{}
+	for index, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".md")
+			if entry.IsDir() || !ok || name == "" || seen[strings.ToLower(name)] {
+				continue
+			}
+			seen[strings.ToLower(name)] = true
+			path := filepath.Join(dir, entry.Name())
+			commands = append(commands, slashCommandInfo{
+				Name:         strings.ToLower(name),
+				Description:  markdownCommandDescription(path),
+				Category:     categories[index],
+				AcceptsArgs:  true,
+				ArgumentHint: "[args]",
+				Source:       path,
+			})
+		}
+	}
+	sort.SliceStable(commands, func(left int, right int) bool {
+		if commands[left].Category != commands[right].Category {
+			return commands[left].Category < commands[right].Category
+		}
+		return commands[left].Name < commands[right].Name
+	})
+	return commands
+}
+
+// markdownCommandDescription reads a command description from frontmatter or the first line.
+func markdownCommandDescription(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	inFrontmatter := false
+	for lineIndex := 0; scanner.Scan(); lineIndex++ {
+		line := strings.TrimSpace(scanner.Text())
+		if lineIndex == 0 && line == "---" {
+			inFrontmatter = true
+			continue
+		}
+		if inFrontmatter {
+			if line == "---" {
+				inFrontmatter = false
+				continue
+			}
+			if value, ok := strings.CutPrefix(line, "description:"); ok {
+				return strings.Trim(strings.TrimSpace(value), `"'`)
+			}
+			continue
+		}
+		if line != "" {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+	}
+	return ""
+}
+
+// formatHelp renders /help output, optionally narrowed by a case-insensitive filter.
+func formatHelp(opts *options, filter string) string {
+	query := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(filter), "/")))
+	matches := func(fields ...string) bool {
+		if query == "" {
+			return true
+		}
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), query) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var commands []slashCommandInfo
+	for _, info := range slashCommandCatalog(opts) {
+		if matches(info.Name, info.Description) {
+			commands = append(commands, info)
+		}
+	}
+	var keys []tuiKeybinding
+	for _, binding := range tuiKeybindings {
+		if matches(binding.Keys, binding.Action) {
+			keys = append(keys, binding)
+		}
+	}
+	if len(commands) == 0 && len(keys) == 0 {
+		return fmt.Sprintf("No commands or keybindings match %q.", strings.TrimSpace(filter))
+	}
+
+	var sections []string
+	var category string
+	var builder strings.Builder
+	for _, info := range commands {
+		if info.Category != category {
+			if builder.Len() > 0 {
+				sections = append(sections, builder.String())
+				builder.Reset()
+			}
+			category = info.Category
+			builder.WriteString(category + ":")
+		}
+		label := "/" + info.Name
+		if info.ArgumentHint != "" {
+			label += " " + info.ArgumentHint
+		}
+		fmt.Fprintf(&builder, "\n  %-24s %s", label, info.Description)
+	}
+	if builder.Len() > 0 {
+		sections = append(sections, builder.String())
+	}
+	if len(keys) > 0 {
+		sections = append(sections, formatKeybindings(keys))
+	}
+	if query == "" {
+		sections = append(sections, "Custom and plugin commands are listed for reference; OpenClaude does not run them yet. MCP servers are not supported.")
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// formatKeybindings renders a keybinding table.
+func formatKeybindings(bindings []tuiKeybinding) string {
+	var builder strings.Builder
+	builder.WriteString("Keybindings:")
+	for _, binding := range bindings {
+		fmt.Fprintf(&builder, "\n  %-24s %s", binding.Keys, binding.Action)
+	}
+	return builder.String()
+}
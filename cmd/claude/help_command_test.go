@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestFormatHelpCategoriesAndFilter verifies /help groups commands and narrows by filter.
+func TestFormatHelpCategoriesAndFilter(testingHandle *testing.T) {
+	projectDir := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", testingHandle.TempDir())
+	testingHandle.Chdir(projectDir)
+	commandsDir := filepath.Join(projectDir, ".claude", "commands")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(commandsDir, 0o755), "create commands dir")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(commandsDir, "Deploy.md"),
+		[]byte("---\ndescription: \"Ship the build\"\n---\nRun the deploy.\n"), 0o600), "write command")
+	pluginDir := filepath.Join(testingHandle.TempDir(), "lint-kit")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(pluginDir, "commands"), 0o755), "create plugin dir")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(pluginDir, "commands", "lint.md"),
+		[]byte("# Lint the diff\n"), 0o600), "write plugin command")
+	opts := &options{PluginDir: []string{pluginDir}}
+
+	full := formatHelp(opts, "")
+	for _, expected := range []string{
+		"Built-in commands:", "OpenClaude commands:", "Custom commands:", "Plugin commands:", "Keybindings:",
+		"/deploy [args]", "Ship the build", "/lint [args]", "Lint the diff", "/reload", "ctrl+t",
+	} {
+		testutil.RequireTrue(testingHandle, strings.Contains(full, expected), "help includes "+expected)
+	}
+
+	filtered := formatHelp(opts, "/review")
+	testutil.RequireTrue(testingHandle, strings.Contains(filtered, "/review [pr]"), "filter keeps review")
+	testutil.RequireTrue(testingHandle, strings.Contains(filtered, "/security-review [args]"), "filter matches substrings")
+	testutil.RequireTrue(testingHandle, !strings.Contains(filtered, "/reload"), "filter drops other commands")
+	testutil.RequireTrue(testingHandle, !strings.Contains(filtered, "Keybindings:"), "filter drops unmatched keybindings")
+
+	testutil.RequireEqual(testingHandle, formatHelp(opts, "zzz"), `No commands or keybindings match "zzz".`, "no matches")
+
+	handled, output := handleSlashCommand("/deploy now", opts)
+	testutil.RequireTrue(testingHandle, handled, "custom command handled")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(output, "Custom command /deploy"), "custom command reported")
+}
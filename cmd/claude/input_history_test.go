@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestInputHistoryPersistsAcrossSessions verifies a new TUI recalls prompts and /history browses them.
+func TestInputHistoryPersistsAcrossSessions(testingHandle *testing.T) {
+	testingHandle.Chdir(testingHandle.TempDir())
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	opts := &options{ProviderConfig: &config.ProviderConfig{InputHistorySize: 5}}
+	first := newTUIModel(opts, nil, nil, sessionHistoryCursor{}, "", "model", "first", store)
+	first.appendInputHistory("explain main.go", tuiInputPrompt)
+	first.appendInputHistory("go test ./...", tuiInputBash)
+
+	second := newTUIModel(opts, nil, nil, sessionHistoryCursor{}, "", "model", "second", store)
+	testutil.RequireEqual(testingHandle, second.inputHistory, []string{"explain main.go", "!go test ./..."}, "history restored")
+	testutil.RequireEqual(testingHandle, second.historyIndex, 2, "history cursor starts after restored entries")
+
+	listing := second.showInputHistory("")
+	testutil.RequireTrue(testingHandle, strings.Contains(listing, "1  explain main.go"), "listing numbers entries")
+	filtered := second.showInputHistory("EXPLAIN")
+	testutil.RequireTrue(testingHandle, !strings.Contains(filtered, "go test"), "filter narrows entries")
+
+	second.showInputHistory("2")
+	testutil.RequireEqual(testingHandle, second.input.Value(), "go test ./...", "entry recalled into input")
+	testutil.RequireEqual(testingHandle, second.inputMode, tuiInputBash, "bash entry restores bash mode")
+
+	disabled := &options{ProviderConfig: &config.ProviderConfig{InputHistorySize: -1}}
+	memoryOnly := newTUIModel(disabled, nil, nil, sessionHistoryCursor{}, "", "model", "third", store)
+	testutil.RequireEqual(testingHandle, len(memoryOnly.inputHistory), 0, "negative size skips persisted history")
+}
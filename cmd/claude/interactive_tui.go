@@ -5,21 +5,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/google/uuid"
 	"golang.org/x/term"
 
 	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
 	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/project"
 	"github.com/openclaude/openclaude/internal/session"
 	"github.com/openclaude/openclaude/internal/tools"
 )
@@ -71,11 +79,9 @@ const (
 )
 
 const (
-	// tuiPasteIdleDelay defines the quiet window before a paste is finalized.
-	tuiPasteIdleDelay = 100 * time.Millisecond
-	// tuiPasteRuneThreshold triggers paste buffering for large rune counts.
+	// tuiPasteRuneThreshold collapses pastes with this many runes into a placeholder.
 	tuiPasteRuneThreshold = 800
-	// tuiPasteLineThreshold triggers paste buffering for multi-line input.
+	// tuiPasteLineThreshold collapses pastes with this many lines into a placeholder.
 	tuiPasteLineThreshold = 3
 	// tuiLogoMinWidth matches Claude Code's minimum logo width.
 	tuiLogoMinWidth = 46
@@ -87,8 +93,10 @@ const (
 	tuiToolSpinnerInterval = 600 * time.Millisecond
 	// tuiSpinnerInterval defines the "thinking" spinner cadence.
 	tuiSpinnerInterval = 120 * time.Millisecond
-	// tuiMaxRenderedLines caps rendered tool output lines.
+	// tuiMaxRenderedLines caps rendered bash-mode output lines.
 	tuiMaxRenderedLines = 50
+	// tuiLiveOutputLines is how many of a running tool's latest output lines are shown.
+	tuiLiveOutputLines = 5
 )
 
 // tuiSpinnerMessages mirrors the playful Claude Code spinner verbs.
@@ -203,6 +211,31 @@ type tuiMessage struct {
 	ToolStatus tuiToolStatus
 	// ToolError marks tool-result output as an error.
 	ToolError bool
+	// Stderr is the standard error a command tool wrote; it ends Content.
+	Stderr string
+	// ExitCode is a command tool's exit status; 0 when it succeeded or ran no process.
+	ExitCode int
+	// Expanded shows full tool input or output; set from verbose mode when the
+	// message is added and toggled per message with ctrl+x or a click.
+	Expanded bool
+	// ToolProgress is the latest progress reported by a running tool; zero when none.
+	ToolProgress tools.Progress
+	// ToolOutput holds the latest output lines of a running tool; empty when none.
+	ToolOutput string
+}
+
+// tuiRenderedMessage caches the rendered form of a chat message.
+// Entries are reused while the message and viewport width are unchanged so
+// long sessions do not re-render markdown for every streamed delta.
+type tuiRenderedMessage struct {
+	// Message is the message snapshot the cached output was rendered from.
+	Message tuiMessage
+	// Width is the viewport width at render time.
+	Width int
+	// Rendered is the cached rendered block.
+	Rendered string
+	// Valid reports whether the entry holds a usable render.
+	Valid bool
 }
 
 // streamDeltaMsg carries streamed text chunks into the TUI event loop.
@@ -211,6 +244,12 @@ type streamDeltaMsg struct {
 	Text string
 }
 
+// streamThinkingMsg carries streamed reasoning chunks into the TUI event loop.
+type streamThinkingMsg struct {
+	// Text is the reasoning delta text chunk.
+	Text string
+}
+
 // streamDoneMsg signals a completed streaming run with the final result.
 type streamDoneMsg struct {
 	// Result is the full run result to reconcile history.
@@ -223,15 +262,18 @@ type streamErrorMsg struct {
 	Err error
 }
 
+// reloadRequestMsg asks the TUI to reload provider config and settings.
+type reloadRequestMsg struct {
+	// Source names what triggered the reload (e.g., SIGHUP).
+	Source string
+}
+
 // spinnerTickMsg toggles tool-use animation frames.
 type spinnerTickMsg struct{}
 
 // spinnerFrameMsg advances the main "thinking" spinner animation.
 type spinnerFrameMsg struct{}
 
-// pasteDoneMsg signals that a buffered paste should be finalized.
-type pasteDoneMsg struct{}
-
 // bashDoneMsg delivers the result of a direct bash invocation.
 type bashDoneMsg struct {
 	// ToolID ties the bash run to its tool-use line.
@@ -242,12 +284,46 @@ type bashDoneMsg struct {
 	IsError bool
 }
 
+// gitStatusMsg delivers a refreshed repository state after a turn.
+type gitStatusMsg struct {
+	// Status is the new state, or nil outside a git work tree.
+	Status *project.GitStatus
+}
+
 // toolEventMsg wraps a tool event for the UI.
 type toolEventMsg struct {
 	// Event is the tool event emitted by the agent.
 	Event agent.ToolEvent
 }
 
+// toolProgressMsg carries progress from a running tool call.
+type toolProgressMsg struct {
+	// ToolID identifies the tool call.
+	ToolID string
+	// Progress is the latest report.
+	Progress tools.Progress
+}
+
+// toolOutputMsg carries output lines from a running tool call.
+type toolOutputMsg struct {
+	// ToolID identifies the tool call.
+	ToolID string
+	// Text is the next batch of output lines.
+	Text string
+}
+
+// runProgressMsg carries a run heartbeat or retry notice.
+type runProgressMsg struct {
+	// Event is the latest progress report.
+	Event agent.ProgressEvent
+}
+
+// contextTrimmedMsg reports old tool results dropped after a context overflow.
+type contextTrimmedMsg struct {
+	// Dropped lists every result dropped so far in the run.
+	Dropped []agent.DroppedToolResult
+}
+
 // permissionRequest describes a tool permission prompt issued by the agent.
 type permissionRequest struct {
 	// ToolName is the tool being requested.
@@ -256,6 +332,11 @@ type permissionRequest struct {
 	Args json.RawMessage
 	// Response is used to return the user's decision.
 	Response chan bool
+	// Preflight, when set, makes this a git pre-flight warning instead of a
+	// tool approval; the user may also stash their changes.
+	Preflight *gitPreflightWarning
+	// Stash records that the user chose to stash before proceeding.
+	Stash bool
 }
 
 // permissionRequestMsg delivers a permission prompt to the UI loop.
@@ -274,6 +355,10 @@ type tuiSlashSuggestion struct {
 	Aliases []string
 	// AcceptsArgs reports whether the command expects arguments.
 	AcceptsArgs bool
+	// Mention marks an @-file completion; Name then holds the path.
+	Mention bool
+	// Completion marks a Tab completion; Name replaces the trailing input token.
+	Completion bool
 }
 
 // tuiSelectorItem captures a selectable message for history forking.
@@ -298,18 +383,6 @@ type tuiPendingPaste struct {
 	Content string
 }
 
-// tuiPasteBuffer accumulates large paste chunks before finalizing.
-type tuiPasteBuffer struct {
-	// Chunks holds buffered paste chunks.
-	Chunks []string
-	// BaseValue stores the input value before paste buffering began.
-	BaseValue string
-	// Active reports whether a paste buffer is currently running.
-	Active bool
-	// Last marks the time of the most recent paste chunk.
-	Last time.Time
-}
-
 // tuiToolState tracks tool-use UI state to allow updates on completion.
 type tuiToolState struct {
 	// Index points at the message entry representing the tool use.
@@ -389,10 +462,21 @@ type tuiModel struct {
 	model string
 	// systemPrompt is the resolved system prompt string.
 	systemPrompt string
+	// persisted counts the leading history messages already in the session log.
+	persisted int
+	// resend is the previous message being edited for re-running, if any.
+	resend *tuiResend
 	// history is the full message history used for agent calls.
-	history []openai.Message
+	history []llm.Message
+	// historyCursor pages in older session history that was not loaded at startup.
+	historyCursor sessionHistoryCursor
 	// chatMessages holds display-friendly message entries.
 	chatMessages []tuiMessage
+	// renderCache holds rendered chat blocks aligned with chatMessages.
+	renderCache []tuiRenderedMessage
+	// chatMessageLines holds the first chat viewport line of each chat
+	// message, so mouse clicks can find the message under the pointer.
+	chatMessageLines []int
 	// toolLines keeps a rolling log of tool events.
 	toolLines []string
 	// toolStates tracks tool-use message indices for updates.
@@ -423,20 +507,44 @@ type tuiModel struct {
 	selectorItems []tuiSelectorItem
 	// selectorIndex tracks the active selection in selectorItems.
 	selectorIndex int
+	// transcript is the Ctrl+R raw transcript overlay, nil when closed.
+	transcript *tuiTranscript
 	// pendingPaste holds a large paste placeholder awaiting submission.
 	pendingPaste *tuiPendingPaste
-	// pasteBuffer accumulates large paste chunks before finalizing.
-	pasteBuffer tuiPasteBuffer
-	// markdownRenderer formats assistant output when available.
-	markdownRenderer *glamour.TermRenderer
+	// largeMessage is set while an oversized prompt waits for the user's choice.
+	largeMessage *tuiLargeMessage
+	// largeMessageApproved lets the next submission through the size check.
+	largeMessageApproved bool
+	// providerFailures counts runs in a row that failed with the provider
+	// overloaded, unavailable, or rate limiting.
+	providerFailures int
+	// failover is set while the offer to switch to the fallback model waits.
+	failover *tuiFailover
+	// pathPaste holds pasted file paths attached as @-mentions until the next edit.
+	pathPaste *tuiPathPaste
+	// clipboardImages maps each saved clipboard image not sent yet to the
+	// @-mention inserted for it. The files are removed once sent or on exit.
+	clipboardImages map[string]string
+	// codeStyle colors fenced code and diffs for the terminal's background.
+	codeStyle codeStyle
+	// markdownRenderer formats assistant output, falling back to plain text per message.
+	markdownRenderer *markdownRenderer
 	// statusText is the bottom status line.
 	statusText string
 	// inputHint shows short-lived messages under the input.
 	inputHint string
 	// lastUsage tracks token usage for the most recent run.
-	lastUsage openai.Usage
+	lastUsage llm.Usage
 	// totalCost tracks accumulated cost across runs.
 	totalCost float64
+	// gitStatus is the latest repository state shown in the status line.
+	gitStatus *project.GitStatus
+	// costTurns records per-turn usage and timing for /cost and /stats.
+	costTurns []tuiCostTurn
+	// editedFiles lists files changed by edit tools since the last /commit.
+	editedFiles []string
+	// runPrompt is the prompt that started the in-flight run.
+	runPrompt string
 	// chatAutoScroll keeps the chat viewport pinned to the bottom.
 	chatAutoScroll bool
 	// toolAutoScroll keeps the tool viewport pinned to the bottom.
@@ -453,8 +561,18 @@ type tuiModel struct {
 	planMode bool
 	// running indicates an in-flight request.
 	running bool
+	// pendingReload holds the source of a reload deferred until the current run finishes.
+	pendingReload string
 	// streamBuffer accumulates streamed assistant text.
 	streamBuffer strings.Builder
+	// streamRender holds the streamed text's markdown between deltas.
+	streamRender tuiStreamRender
+	// thinkingBuffer accumulates streamed reasoning until visible output follows.
+	thinkingBuffer strings.Builder
+	// thinkingExpanded shows full thinking blocks instead of collapsed headers.
+	thinkingExpanded bool
+	// verbose adds new tool messages with full inputs and outputs (ctrl+o or /verbose), persisted per session.
+	verbose bool
 	// streamCh delivers stream messages into the update loop.
 	streamCh chan tea.Msg
 	// cancel cancels the current request when present.
@@ -469,23 +587,44 @@ type tuiModel struct {
 	spinnerFrames []string
 	// spinnerFrame indexes the current spinner frame.
 	spinnerFrame int
-	// spinnerMessage stores the current spinner verb.
+	// spinnerMessage stores the playful verb shown while no tool is running.
 	spinnerMessage string
+	// activeTools lists running tool calls in start order; the newest one
+	// names the spinner status.
+	activeTools []tuiActiveTool
+	// agentRun tracks the running agent turn's completed messages; nil when
+	// no agent turn is running.
+	agentRun *tuiRunState
+	// interrupted is set from an Escape or Ctrl+C interrupt until the run
+	// reports that it stopped; run output arriving in between is dropped.
+	interrupted bool
 	// spinnerStarted records when the spinner began.
 	spinnerStarted time.Time
+	// queuedMessages holds input submitted during a run, sent when it ends.
+	queuedMessages []tuiQueuedMessage
+	// spinnerDetail describes a long wait or retry from the latest heartbeat;
+	// new output clears it.
+	spinnerDetail string
 	// spinnerEnabled gates whether the main spinner should be shown.
 	spinnerEnabled bool
 	// doublePress tracks exit/clear key timing.
 	doublePress tuiDoublePress
 	// theme holds colors for rendering.
 	theme tuiTheme
+	// themeName is the theme /theme last applied, e.g. "auto" or "dark".
+	themeName string
+	// blurred is set while the terminal reports that it lost focus.
+	blurred bool
+	// notifyOut receives bell and OSC 9 notifications; nil drops them.
+	notifyOut io.Writer
 }
 
 // runInteractiveTUI starts the full-screen terminal UI for interactive sessions.
 func runInteractiveTUI(
 	opts *options,
 	runner *agent.Runner,
-	history []openai.Message,
+	history []llm.Message,
+	historyCursor sessionHistoryCursor,
 	systemPrompt string,
 	model string,
 	sessionID string,
@@ -494,8 +633,25 @@ func runInteractiveTUI(
 	if !term.IsTerminal(int(0)) || !term.IsTerminal(int(1)) {
 		return errors.New("interactive TUI requires a TTY")
 	}
-	modelState := newTUIModel(opts, runner, history, systemPrompt, model, sessionID, store)
-	program := tea.NewProgram(modelState, tea.WithAltScreen())
+	modelState := newTUIModel(opts, runner, history, historyCursor, systemPrompt, model, sessionID, store)
+	defer modelState.removeClipboardImages()
+	modelState.notifyOut = os.Stdout
+	// Focus reports tell notifications whether the user is looking.
+	program := tea.NewProgram(modelState, tea.WithAltScreen(), tea.WithReportFocus(), tea.WithMouseCellMotion())
+
+	// SIGHUP reloads provider config and settings without restarting the session.
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	defer func() {
+		signal.Stop(reloadSignals)
+		close(reloadSignals)
+	}()
+	go func() {
+		for range reloadSignals {
+			program.Send(reloadRequestMsg{Source: "SIGHUP"})
+		}
+	}()
+
 	_, err := program.Run()
 	return err
 }
@@ -504,7 +660,8 @@ func runInteractiveTUI(
 func newTUIModel(
 	opts *options,
 	runner *agent.Runner,
-	history []openai.Message,
+	history []llm.Message,
+	historyCursor sessionHistoryCursor,
 	systemPrompt string,
 	model string,
 	sessionID string,
@@ -521,40 +678,60 @@ func newTUIModel(
 	toolView := viewport.New(20, 10)
 	toolView.SetContent("No tool activity yet.")
 
-	var renderer *glamour.TermRenderer
-	if glam, err := glamour.NewTermRenderer(glamour.WithAutoStyle()); err == nil {
-		renderer = glam
-	}
-
 	modelState := &tuiModel{
-		opts:             opts,
-		runner:           runner,
-		store:            store,
-		sessionID:        sessionID,
-		model:            model,
-		systemPrompt:     systemPrompt,
-		history:          ensureSystem(history, systemPrompt),
-		chatView:         chatView,
-		toolView:         toolView,
-		input:            input,
-		inputMode:        tuiInputPrompt,
-		toolStates:       map[string]tuiToolState{},
-		slashSelection:   -1,
-		spinnerFrames:    spinnerFrames(),
-		theme:            defaultTUITheme(),
-		markdownRenderer: renderer,
-		statusText:       "",
-		activePane:       "input",
-		chatAutoScroll:   true,
-		toolAutoScroll:   true,
+		opts:           opts,
+		runner:         runner,
+		store:          store,
+		sessionID:      sessionID,
+		model:          model,
+		systemPrompt:   systemPrompt,
+		history:        ensureSystem(history, systemPrompt),
+		historyCursor:  historyCursor,
+		chatView:       chatView,
+		toolView:       toolView,
+		input:          input,
+		inputMode:      tuiInputPrompt,
+		toolStates:     map[string]tuiToolState{},
+		slashSelection: -1,
+		spinnerFrames:  spinnerFrames(),
+		statusText:     "",
+		activePane:     "input",
+		chatAutoScroll: true,
+		toolAutoScroll: true,
+	}
+	modelState.persisted = len(modelState.history)
+	themeErr := modelState.applyTheme(settingsTheme(opts))
+	if themeErr != nil {
+		_ = modelState.applyTheme("auto", nil)
 	}
 	if runner != nil {
 		modelState.permissionMode = string(runner.Permissions.Mode)
 	}
+	if opts != nil {
+		modelState.gitStatus = opts.GitStatus
+	}
+	if store != nil {
+		if state, err := store.LoadUIState(sessionID); err == nil {
+			modelState.verbose = state.Verbose
+		}
+	}
 	modelState.syncInputPrompt()
 	modelState.refreshPlanMode()
+	modelState.loadInputHistory()
 	modelState.historyIndex = len(modelState.inputHistory)
 	modelState.bootstrapHistory()
+	if notice := resumedHistoryNotice(len(history), historyCursor); notice != "" {
+		modelState.appendSystemMessage(notice + " Scroll past the oldest message in the message selector (Esc) to load them.")
+	}
+	if runner != nil && runner.Permissions.Untrusted {
+		modelState.appendSystemMessage(untrustedWorkspaceNotice)
+		if notice := skippedHooksNotice(opts); notice != "" {
+			modelState.appendSystemMessage(notice)
+		}
+	}
+	if themeErr != nil {
+		modelState.appendSystemMessage(fmt.Sprintf("Theme settings ignored: %v; using the auto theme.", themeErr))
+	}
 	return modelState
 }
 
@@ -565,12 +742,23 @@ func (m *tuiModel) Init() tea.Cmd {
 
 // Update handles UI events and streaming updates.
 func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.staleRunMsg(msg) {
+		return m, m.listenStream()
+	}
 	switch typed := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.applyWindowSize(typed)
 		return m, nil
 	case tea.KeyMsg:
 		return m.handleKey(typed)
+	case tea.MouseMsg:
+		return m, m.handleMouse(typed)
+	case tea.FocusMsg:
+		m.blurred = false
+		return m, nil
+	case tea.BlurMsg:
+		m.blurred = true
+		return m, nil
 	case spinnerTickMsg:
 		m.spinnerOn = !m.spinnerOn
 		m.refreshChat()
@@ -578,26 +766,81 @@ func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case spinnerFrameMsg:
 		m.advanceSpinnerFrame()
 		return m, m.scheduleSpinnerFrameTick()
-	case pasteDoneMsg:
-		return m, m.finalizePaste()
+	case clipboardImageMsg:
+		return m, m.insertClipboardImage(typed)
+	case streamThinkingMsg:
+		m.spinnerDetail = ""
+		m.thinkingBuffer.WriteString(typed.Text)
+		m.refreshChat()
+		return m, m.listenStream()
 	case streamDeltaMsg:
+		m.spinnerDetail = ""
+		m.flushThinking()
 		m.streamBuffer.WriteString(typed.Text)
 		m.refreshChat()
-		return m, m.listenStream()
+		return m, tea.Batch(m.listenStream(), m.scheduleStreamRender())
+	case streamRenderMsg:
+		return m, m.handleStreamRender()
 	case toolEventMsg:
+		m.spinnerDetail = ""
+		m.flushThinking()
+		m.trackToolStatus(typed.Event)
 		m.appendToolEvent(typed.Event)
 		return m, tea.Batch(m.listenStream(), m.scheduleSpinnerTick())
+	case toolProgressMsg:
+		m.updateToolProgress(typed.ToolID, typed.Progress)
+		return m, m.listenStream()
+	case toolOutputMsg:
+		m.appendToolOutput(typed.ToolID, typed.Text)
+		return m, m.listenStream()
+	case runMessageMsg:
+		m.recordRunMessage(typed.Message)
+		if len(typed.Message.ServerToolCalls) > 0 {
+			m.appendServerToolCalls(typed.Message)
+			m.refreshChat()
+		}
+		return m, m.listenStream()
+	case runProgressMsg:
+		// Streaming output is already visible, so only waits and retries are shown.
+		if typed.Event.Phase != agent.ProgressStreaming {
+			m.spinnerDetail = typed.Event.String()
+		}
+		return m, m.listenStream()
+	case contextTrimmedMsg:
+		m.appendSystemMessage(contextTrimmedNotice(typed.Dropped))
+		m.refreshChat()
+		return m, m.listenStream()
 	case permissionRequestMsg:
 		m.handlePermissionRequest(typed.Request)
-		return m, m.listenStream()
+		if typed.Request == nil {
+			return m, m.listenStream()
+		}
+		return m, tea.Batch(m.listenStream(), m.notify("OpenClaude needs your permission to use "+typed.Request.ToolName))
 	case bashDoneMsg:
 		m.finishBash(typed)
-		return m, nil
+		return m, tea.Batch(m.refreshGitStatus(), m.sendQueued())
 	case streamDoneMsg:
+		if m.interrupted {
+			// The run finished as it was interrupted; the partial turn is kept.
+			m.finishError(context.Canceled)
+			return m, tea.Batch(m.refreshGitStatus(), m.sendQueued())
+		}
+		notify := m.notifyRunEnd(m.spinnerStarted, "finished")
 		m.finishRun(typed.Result)
-		return m, nil
+		return m, tea.Batch(m.refreshGitStatus(), m.sendQueued(), notify)
 	case streamErrorMsg:
+		notify := m.notifyRunEnd(m.spinnerStarted, "failed")
 		m.finishError(typed.Err)
+		if m.offerFailover(typed.Err) {
+			// Queued prompts wait for the answer, so they run on the chosen model.
+			return m, tea.Batch(m.refreshGitStatus(), notify)
+		}
+		return m, tea.Batch(m.refreshGitStatus(), m.sendQueued(), notify)
+	case gitStatusMsg:
+		m.gitStatus = typed.Status
+		return m, nil
+	case reloadRequestMsg:
+		m.requestReload(typed.Source)
 		return m, nil
 	}
 
@@ -614,6 +857,9 @@ func (m *tuiModel) View() string {
 	if m.width == 0 {
 		return "Initializing..."
 	}
+	if m.transcript != nil {
+		return m.renderTranscript()
+	}
 	m.updateLayout()
 
 	sections := []string{m.renderBody()}
@@ -626,6 +872,9 @@ func (m *tuiModel) View() string {
 	if m.showMessageSelector {
 		sections = append(sections, m.renderMessageSelector())
 	}
+	if queued := m.renderQueuedMessages(); queued != "" {
+		sections = append(sections, queued)
+	}
 	if input := m.renderInput(); input != "" {
 		sections = append(sections, input)
 	}
@@ -634,21 +883,61 @@ func (m *tuiModel) View() string {
 
 // handleKey routes keyboard input and command submission.
 func (m *tuiModel) handleKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
-	if m.pendingPermission != nil {
+	if m.pendingPermission != nil && m.pendingPermission.Preflight != nil {
+		switch strings.ToLower(key.String()) {
+		case "s":
+			m.pendingPermission.Stash = true
+			m.resolvePermission(true)
+			m.statusText = "Stashing uncommitted changes."
+			return m, nil
+		case "p", "y":
+			m.resolvePermission(true)
+			m.statusText = "Proceeding with uncommitted changes."
+			return m, nil
+		case "n", "esc", "enter":
+			m.resolvePermission(false)
+			m.statusText = "Edit cancelled."
+			return m, nil
+		}
+	} else if m.pendingPermission != nil {
 		switch strings.ToLower(key.String()) {
 		case "y":
 			m.resolvePermission(true)
 			return m, nil
+		case "a":
+			m.resolvePermissionAlways()
+			return m, nil
 		case "n", "esc", "enter":
 			m.resolvePermission(false)
 			return m, nil
 		}
 	}
 
+	if m.largeMessage != nil {
+		if handled, cmd := m.handleLargeMessageKey(key.String()); handled {
+			return m, cmd
+		}
+	}
+
+	if m.failover != nil {
+		if handled, cmd := m.handleFailoverKey(key.String()); handled {
+			return m, cmd
+		}
+	}
+
+	if m.transcript != nil {
+		return m.handleTranscriptKey(key)
+	}
+
 	if m.showMessageSelector {
 		return m.handleSelectorKey(key)
 	}
 
+	if m.pathPaste != nil && key.String() == "esc" {
+		m.revertPathPaste()
+		return m, nil
+	}
+
 	if handled, cmd := m.handleSuggestionKey(key); handled {
 		return m, cmd
 	}
@@ -656,9 +945,8 @@ func (m *tuiModel) handleKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch key.String() {
 	case "ctrl+c":
 		if m.running {
-			m.cancelRun("Cancelled.")
-			m.appendInterruptMessage()
-			m.refreshChat()
+			m.restoreQueued()
+			m.interruptRun("Cancelled.")
 			return m, nil
 		}
 		if m.doublePressTriggered("ctrl+c", "Press Ctrl-C again to exit.") {
@@ -682,17 +970,44 @@ func (m *tuiModel) handleKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+q":
 		m.quitting = true
 		return m, tea.Quit
+	case "ctrl+t":
+		m.toggleThinking()
+		return m, nil
+	case "ctrl+o":
+		m.toggleVerbose()
+		return m, nil
+	case "ctrl+x":
+		m.toggleToolOutput()
+		return m, nil
+	case "ctrl+r":
+		m.openTranscript()
+		return m, nil
 	case "tab":
+		if m.activePane == "input" && m.completeInputToken() {
+			return m, nil
+		}
 		m.cyclePane(1)
 		return m, nil
 	case "shift+tab":
 		m.cyclePane(-1)
 		return m, nil
 	case "esc":
+		if m.resend != nil {
+			m.cancelResend()
+			return m, nil
+		}
 		if m.input.Value() == "" && !m.running && len(m.chatMessages) > 0 {
 			m.openMessageSelector()
 			return m, nil
 		}
+		if m.running {
+			if len(m.queuedMessages) > 0 {
+				m.interruptForQueued()
+			} else {
+				m.interruptRun("Interrupted.")
+			}
+			return m, nil
+		}
 		if m.doublePressTriggered("esc", "Press Escape again to clear.") {
 			m.input.SetValue("")
 			m.input.CursorEnd()
@@ -762,20 +1077,23 @@ func (m *tuiModel) handleKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if key.String() == "ctrl+v" || (isPasteKey(key) && len(key.Runes) == 0) {
+		// Terminals paste nothing, or ignore ctrl+v, when the clipboard holds an image.
+		return m, m.pasteClipboardImage(key.String() == "ctrl+v")
+	}
+
 	var cmd tea.Cmd
 	previousValue := m.input.Value()
 	m.input, cmd = m.input.Update(key)
 	if m.input.Value() != previousValue {
-		pasteCmd := m.handlePasteKey(key, previousValue)
+		if m.attachPastedPaths(key, previousValue) {
+			return m, cmd
+		}
+		m.placeholdLargePaste(key, previousValue)
 		m.syncInputState()
 		if m.input.Value() == "" && (key.String() == "backspace" || key.String() == "delete") {
 			m.setInputMode(tuiInputPrompt)
 		}
-		if pasteCmd != nil && cmd != nil {
-			cmd = tea.Batch(cmd, pasteCmd)
-		} else if pasteCmd != nil {
-			cmd = pasteCmd
-		}
 	}
 	return m, cmd
 }
@@ -783,14 +1101,26 @@ func (m *tuiModel) handleKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 // submitInput sends the current input as a new user message.
 func (m *tuiModel) submitInput() (tea.Model, tea.Cmd) {
 	if m.running {
-		m.statusText = "Wait for the current response or cancel with Ctrl+C."
+		// Input typed during a run waits for it, like Claude Code's queue.
+		if !m.queueInput() {
+			m.statusText = "Wait for the current response or cancel with Ctrl+C."
+		}
 		return m, nil
 	}
 	rawValue := m.input.Value()
+	if m.holdLargeMessage(rawValue) {
+		return m, nil
+	}
 	value := strings.TrimSpace(m.resolvePastedInput(rawValue))
 	if value == "" {
 		return m, nil
 	}
+	if m.resend != nil {
+		if err := m.applyResend(); err != nil {
+			m.statusText = err.Error()
+			return m, nil
+		}
+	}
 	modeAtSubmit := m.inputMode
 	m.submitCount++
 	m.input.SetValue("")
@@ -803,20 +1133,52 @@ func (m *tuiModel) submitInput() (tea.Model, tea.Cmd) {
 		return m.submitBash(value)
 	}
 
-	if handled, output := handleSlashCommand(value, m.opts); handled {
+	// Prompt commands such as /review run as a turn; generated prompts embed
+	// diffs and comments, so @-mentions are not expanded in them.
+	display := value
+	prompt, expanded, err := m.expandPromptCommand(value)
+	if expanded && err != nil {
 		m.appendUserCommand(value)
-		if output != "" {
-			m.appendSystemMessage(output)
-		}
+		m.appendSystemMessage(err.Error())
 		m.refreshChat()
 		return m, nil
 	}
-
-	m.appendUserPrompt(value)
+	var content any = prompt
+	var imageWarnings []string
+	if !expanded {
+		if handled, output := m.handleTUISlashCommand(value); handled {
+			m.appendUserCommand(value)
+			if output != "" {
+				m.appendSystemMessage(output)
+			}
+			m.refreshChat()
+			return m, nil
+		}
+		// @-mentions are inlined and image paths or URLs are sent as image parts.
+		var attached []string
+		content, attached, imageWarnings = buildPromptContent(value, newMentionAccess(m.runner, m.promptCWD(), settingsHidePatterns(m.opts)))
+		// Attached images are now held in content, so their files can go.
+		m.releaseClipboardImages(value)
+		for _, label := range attached {
+			display += "\n[Image: " + label + "]"
+		}
+	}
+	m.appendUserPrompt(display)
+	for _, warning := range imageWarnings {
+		m.appendSystemMessage(warning)
+	}
 	m.refreshChat()
 
-	m.history = append(m.history, openai.Message{Role: "user", Content: value})
+	// A session paused in print mode on AskUserQuestion takes this prompt as the answer.
+	m.history = append(m.history, answerPendingQuestion(m.history, []llm.Message{{Role: "user", Content: content}})...)
+	return m, m.startRun(value)
+}
+
+// startRun runs the agent over the history, which ends with the user's prompt.
+func (m *tuiModel) startRun(prompt string) tea.Cmd {
+	m.runPrompt = prompt
 	m.running = true
+	m.agentRun = &tuiRunState{}
 	m.startSpinner()
 	m.streamBuffer.Reset()
 	m.toolLines = nil
@@ -828,13 +1190,15 @@ func (m *tuiModel) submitInput() (tea.Model, tea.Cmd) {
 	m.configureAuthorizer(ctx)
 
 	cmd := m.startStream(ctx)
-	return m, tea.Batch(cmd, m.listenStream(), m.scheduleSpinnerTick(), m.scheduleSpinnerFrameTick())
+	return tea.Batch(cmd, m.listenStream(), m.scheduleSpinnerTick(), m.scheduleSpinnerFrameTick())
 }
 
 // startSpinner initializes the "thinking" spinner state for a new run.
 func (m *tuiModel) startSpinner() {
 	m.spinnerMessage = pickSpinnerMessage()
+	m.activeTools = nil
 	m.spinnerStarted = time.Now()
+	m.spinnerDetail = ""
 	m.spinnerFrame = 0
 	m.spinnerEnabled = true
 }
@@ -857,26 +1221,18 @@ func (m *tuiModel) submitBash(command string) (tea.Model, tea.Cmd) {
 	// Mirror Claude Code by storing bash input as a tagged user message.
 	userTag := fmt.Sprintf("<bash-input>%s</bash-input>", command)
 	m.appendUserBash(command)
-	m.history = append(m.history, openai.Message{Role: "user", Content: userTag})
-	if m.store != nil {
-		// Persist the user message immediately so session history stays ordered.
-		if err := persistSession(m.store, m.sessionID, []openai.Message{{Role: "user", Content: userTag}}, nil); err != nil {
-			m.statusText = err.Error()
-		}
-	}
+	m.history = append(m.history, llm.Message{Role: "user", Content: userTag})
+	// Persist the user message immediately so session history stays ordered.
+	m.persistHistory(nil)
 
 	// Handle local "cd" commands without invoking the Bash tool.
 	if handled, output, isError := m.handleBashCD(command); handled {
 		resultTag := wrapBashOutput(output, isError)
 		m.appendAssistantText(resultTag)
-		assistantMessage := openai.Message{Role: "assistant", Content: resultTag}
+		assistantMessage := llm.Message{Role: "assistant", Content: resultTag}
 		m.history = append(m.history, assistantMessage)
-		if m.store != nil {
-			// Persist the synthetic assistant output for the cd operation.
-			if err := persistSession(m.store, m.sessionID, []openai.Message{assistantMessage}, nil); err != nil {
-				m.statusText = err.Error()
-			}
-		}
+		// Persist the synthetic assistant output for the cd operation.
+		m.persistHistory(nil)
 		m.refreshChat()
 		return m, nil
 	}
@@ -914,7 +1270,7 @@ func (m *tuiModel) submitBash(command string) (tea.Model, tea.Cmd) {
 
 	cmd := func() tea.Msg {
 		// Ask for permission if required by the configured policy.
-		if m.runner.AuthorizeTool != nil && m.runner.Permissions.ShouldPrompt("Bash") {
+		if m.runner.AuthorizeTool != nil && m.runner.Permissions.ShouldPromptCall("Bash", argsPayload) {
 			allowed, err := m.runner.AuthorizeTool("Bash", argsPayload)
 			if err != nil {
 				m.streamCh <- bashDoneMsg{ToolID: toolID, Output: err.Error(), IsError: true}
@@ -949,6 +1305,7 @@ func (m *tuiModel) finishBash(message bashDoneMsg) {
 	m.statusText = ""
 	m.cancel = nil
 	m.pendingPermission = nil
+	m.interrupted = false
 	m.streamCh = nil
 
 	if message.ToolID != "" {
@@ -963,21 +1320,17 @@ func (m *tuiModel) finishBash(message bashDoneMsg) {
 	// Wrap tool output in bash tags so the renderer can display it consistently.
 	resultTag := wrapBashOutput(message.Output, message.IsError)
 	m.appendAssistantText(resultTag)
-	assistantMessage := openai.Message{Role: "assistant", Content: resultTag}
+	assistantMessage := llm.Message{Role: "assistant", Content: resultTag}
 	m.history = append(m.history, assistantMessage)
-	if m.store != nil {
-		// Persist the assistant output so the session can be replayed.
-		if err := persistSession(m.store, m.sessionID, []openai.Message{assistantMessage}, nil); err != nil {
-			m.statusText = err.Error()
-		}
-	}
+	// Persist the assistant output so the session can be replayed.
+	m.persistHistory(nil)
 	m.refreshChat()
 }
 
 // handleBashCD handles a direct "cd" command, updating the tool context.
 func (m *tuiModel) handleBashCD(command string) (bool, string, bool) {
 	trimmed := strings.TrimSpace(command)
-	if !strings.HasPrefix(trimmed, "cd ") {
+	if !strings.HasPrefix(trimmed, "cd ") || strings.Contains(trimmed, "\n") {
 		return false, "", false
 	}
 	// Resolve "cd" paths relative to the current tool context.
@@ -1045,12 +1398,92 @@ func (m *tuiModel) appendInputHistory(value string, mode tuiInputMode) {
 	if mode == tuiInputBash {
 		historyValue = "!" + value
 	}
+	size, persist := m.inputHistorySize()
 	m.inputHistory = append(m.inputHistory, historyValue)
-	if len(m.inputHistory) > 200 {
-		m.inputHistory = m.inputHistory[len(m.inputHistory)-200:]
+	if len(m.inputHistory) > size {
+		m.inputHistory = m.inputHistory[len(m.inputHistory)-size:]
 	}
 	m.historyIndex = len(m.inputHistory)
 	m.historyDraft = ""
+	if persist {
+		// History is a convenience; a failed write must not interrupt the prompt.
+		_ = m.store.AppendInputHistory(session.ProjectHash(mustCwd()), historyValue, size)
+	}
+}
+
+// inputHistorySize returns the history cap and whether history is persisted.
+// A negative input_history_size keeps history in memory only.
+func (m *tuiModel) inputHistorySize() (int, bool) {
+	size := config.DefaultInputHistorySize
+	if m.opts != nil && m.opts.ProviderConfig != nil && m.opts.ProviderConfig.InputHistorySize != 0 {
+		size = m.opts.ProviderConfig.InputHistorySize
+	}
+	if size < 0 {
+		return config.DefaultInputHistorySize, false
+	}
+	return size, m.store != nil
+}
+
+// loadInputHistory restores input history persisted by earlier sessions in this project.
+func (m *tuiModel) loadInputHistory() {
+	size, persist := m.inputHistorySize()
+	if !persist {
+		return
+	}
+	entries, err := m.store.LoadInputHistory(session.ProjectHash(mustCwd()), size)
+	if err != nil {
+		return
+	}
+	m.inputHistory = entries
+}
+
+// showInputHistory lists, filters, or recalls input history entries for /history.
+// "/history" lists recent entries, "/history <text>" filters them, and
+// "/history <n>" loads entry n into the input for editing.
+func (m *tuiModel) showInputHistory(args string) string {
+	const listLimit = 20
+	query := strings.TrimSpace(args)
+	if number, err := strconv.Atoi(query); err == nil {
+		if number < 1 || number > len(m.inputHistory) {
+			return fmt.Sprintf("No history entry #%d.", number)
+		}
+		entry := m.inputHistory[number-1]
+		if bashEntry, ok := strings.CutPrefix(entry, "!"); ok {
+			m.setInputMode(tuiInputBash)
+			entry = bashEntry
+		}
+		m.input.SetValue(entry)
+		m.input.CursorEnd()
+		return fmt.Sprintf("Loaded history entry #%d into the input.", number)
+	}
+
+	var lines []string
+	lowerQuery := strings.ToLower(query)
+	// Skip the /history command just recorded for this invocation.
+	entries := m.inputHistory
+	if count := len(entries); count > 0 && strings.HasPrefix(entries[count-1], "/history") {
+		entries = entries[:count-1]
+	}
+	for index, entry := range entries {
+		if query != "" && !strings.Contains(strings.ToLower(entry), lowerQuery) {
+			continue
+		}
+		firstLine, _, multiline := strings.Cut(entry, "\n")
+		if multiline {
+			firstLine += " …"
+		}
+		lines = append(lines, fmt.Sprintf("%4d  %s", index+1, firstLine))
+	}
+	if len(lines) == 0 {
+		if query != "" {
+			return fmt.Sprintf("No history entries match %q.", query)
+		}
+		return "Input history is empty."
+	}
+	if len(lines) > listLimit {
+		lines = lines[len(lines)-listLimit:]
+	}
+	return "Input history:\n" + strings.Join(lines, "\n") + "\nUse /history <n> to edit an entry, or ctrl+p/up to step through it."
 }
 
 // cycleInputHistory moves the input buffer through stored history entries.
@@ -1092,7 +1525,7 @@ func (m *tuiModel) configureAuthorizer(ctx context.Context) {
 	}
 	streamCh := m.streamCh
 	m.runner.AuthorizeTool = func(name string, args json.RawMessage) (bool, error) {
-		if !m.runner.Permissions.ShouldPrompt(name) {
+		if !m.runner.Permissions.ShouldPromptCall(name, args) {
 			return true, nil
 		}
 		request := &permissionRequest{
@@ -1112,11 +1545,164 @@ func (m *tuiModel) configureAuthorizer(ctx context.Context) {
 			return allowed, nil
 		}
 	}
+	var preflight *gitPreflight
+	if m.opts != nil {
+		preflight = m.opts.GitPreflight
+	}
+	m.runner.BeforeTool = func(name string, args json.RawMessage) error {
+		return preflight.Check(ctx, name, args, func(warning gitPreflightWarning) (preflightChoice, error) {
+			request := &permissionRequest{
+				ToolName:  name,
+				Args:      args,
+				Response:  make(chan bool, 1),
+				Preflight: &warning,
+			}
+			select {
+			case <-ctx.Done():
+				return preflightCancel, ctx.Err()
+			case streamCh <- permissionRequestMsg{Request: request}:
+			}
+			select {
+			case <-ctx.Done():
+				return preflightCancel, ctx.Err()
+			case proceed := <-request.Response:
+				switch {
+				case !proceed:
+					return preflightCancel, nil
+				case request.Stash:
+					return preflightStash, nil
+				}
+				return preflightProceed, nil
+			}
+		})
+	}
+}
+
+// promptCWD returns the directory prompt file references resolve against.
+func (m *tuiModel) promptCWD() string {
+	if m.runner != nil && m.runner.ToolContext.CWD != "" {
+		return m.runner.ToolContext.CWD
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// ignorePatterns returns the ignorePatterns and hidePatterns settings that
+// searches apply on top of ignore files.
+func (m *tuiModel) ignorePatterns() []string {
+	if m.opts == nil || m.opts.ClaudeSettings == nil {
+		return nil
+	}
+	return slices.Concat(m.opts.ClaudeSettings.IgnorePatterns, m.opts.ClaudeSettings.HidePatterns)
+}
+
+// expandPromptCommand builds the model prompt for slash commands that run as a turn.
+func (m *tuiModel) expandPromptCommand(line string) (string, bool, error) {
+	if m.opts != nil && m.opts.DisableSlashCommands {
+		return "", false, nil
+	}
+	command, args, ok := parseSlashCommand(line)
+	if !ok {
+		return "", false, nil
+	}
+	spec, known := lookupCommand(command, commandModeTUI)
+	if !known || spec.Prompt == nil {
+		return "", false, nil
+	}
+	prompt, err := spec.Prompt(m, args)
+	return prompt, true, err
+}
+
+// handleTUISlashCommand routes slash commands that need live TUI state before the stateless handlers.
+func (m *tuiModel) handleTUISlashCommand(line string) (bool, string) {
+	command, args, ok := parseSlashCommand(line)
+	if ok && (m.opts == nil || !m.opts.DisableSlashCommands) {
+		if spec, known := lookupCommand(command, commandModeTUI); known && spec.TUI != nil {
+			return true, spec.TUI(m, args)
+		}
+	}
+	return handleSlashCommand(line, m.opts)
+}
+
+// switchModel lists the model catalog or switches the session model for subsequent turns.
+func (m *tuiModel) switchModel(args string) string {
+	var providerCfg *config.ProviderConfig
+	if m.opts != nil {
+		providerCfg = m.opts.ProviderConfig
+	}
+	requested := strings.TrimSpace(args)
+	if requested == "" {
+		return formatModelList(providerCfg, m.model)
+	}
+	resolved := config.ResolveModel(providerCfg, requested, "")
+	if resolved == "" {
+		return "No model resolved for " + requested + "."
+	}
+	if m.runner != nil && m.runner.MaxBudgetUSD > 0 {
+		// Budget enforcement needs pricing, mirroring the startup check.
+		if _, ok := m.runner.Pricing[resolved]; !ok {
+			return fmt.Sprintf("Pricing missing for model %s; configure pricing to use max-budget-usd.", resolved)
+		}
+	}
+	m.model = resolved
+	m.providerFailures = 0
+	message := "Switched model to " + resolved
+	if resolved != requested {
+		message += " (alias " + requested + ")"
+	}
+	if !isCatalogModel(providerCfg, resolved) {
+		message += "; not listed in the provider catalog"
+	}
+	return message + "."
+}
+
+// requestReload reloads configuration now, or after the in-flight run when one is active.
+func (m *tuiModel) requestReload(source string) {
+	if m.running {
+		// Swapping the client and tools mid-run would race with the agent loop.
+		m.pendingReload = source
+		m.statusText = "Reload queued until the current response finishes."
+		return
+	}
+	m.appendSystemMessage(m.reloadConfig(source))
+	m.refreshChat()
+}
+
+// applyPendingReload runs a reload that was deferred while a response was streaming.
+func (m *tuiModel) applyPendingReload() {
+	if m.pendingReload == "" {
+		return
+	}
+	source := m.pendingReload
+	m.pendingReload = ""
+	m.appendSystemMessage(m.reloadConfig(source))
+	m.refreshChat()
+}
+
+// reloadConfig re-reads provider config, settings, and agents and applies them to the session.
+// The previous configuration stays active when loading or applying fails.
+func (m *tuiModel) reloadConfig(source string) string {
+	if m.opts == nil {
+		return "Reload is unavailable in this session."
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Sprintf("Reload failed: get cwd: %v; keeping the previous configuration.", err)
+	}
+	next, err := loadRuntimeConfig(cwd, m.opts)
+	if err != nil {
+		return fmt.Sprintf("Reload failed: %v; keeping the previous configuration.", err)
+	}
+	previous := currentRuntimeConfig(m.opts)
+	if err := applyRuntimeConfig(m.runner, m.opts, next, m.model); err != nil {
+		return fmt.Sprintf("Reload failed: %v; keeping the previous configuration.", err)
+	}
+	return formatReloadReport(source, describeConfigChanges(previous, next), next.Warnings)
 }
 
 // startStream launches the agent run and feeds updates into the stream channel.
 func (m *tuiModel) startStream(ctx context.Context) tea.Cmd {
-	history := append([]openai.Message(nil), m.history...)
+	history := append([]llm.Message(nil), m.history...)
 	runner := m.runner
 	modelName := m.model
 	toolsEnabled := runner != nil && runner.ToolRunner != nil
@@ -1138,6 +1724,13 @@ func (m *tuiModel) startStream(ctx context.Context) tea.Cmd {
 					if choice.Index != 0 {
 						continue
 					}
+					if reasoning := choice.Delta.ReasoningText(); reasoning != "" {
+						select {
+						case <-ctx.Done():
+							return ctx.Err()
+						case streamCh <- streamThinkingMsg{Text: reasoning}:
+						}
+					}
 					if choice.Delta.Content == "" {
 						continue
 					}
@@ -1157,12 +1750,49 @@ func (m *tuiModel) startStream(ctx context.Context) tea.Cmd {
 				}
 				return nil
 			},
-			OnToolResult: func(event agent.ToolEvent, _ openai.Message) error {
+			OnToolProgress: func(event agent.ToolEvent, progress tools.Progress) {
+				select {
+				case <-ctx.Done():
+				case streamCh <- toolProgressMsg{ToolID: event.ToolID, Progress: progress}:
+				}
+			},
+			OnToolOutput: func(event agent.ToolEvent, text string) {
+				select {
+				case <-ctx.Done():
+				case streamCh <- toolOutputMsg{ToolID: event.ToolID, Text: text}:
+				}
+			},
+			OnContextTrimmed: func(dropped []agent.DroppedToolResult) {
+				select {
+				case <-ctx.Done():
+				case streamCh <- contextTrimmedMsg{Dropped: dropped}:
+				}
+			},
+			OnProgress: func(event agent.ProgressEvent) {
+				select {
+				case <-ctx.Done():
+				case streamCh <- runProgressMsg{Event: event}:
+				}
+			},
+			OnStreamComplete: func(summary agent.StreamSummary) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case streamCh <- runMessageMsg{Message: summary.Message}:
+				}
+				return nil
+			},
+			OnToolResult: func(event agent.ToolEvent, message llm.Message) error {
 				select {
 				case <-ctx.Done():
 					return ctx.Err()
 				case streamCh <- toolEventMsg{Event: event}:
 				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case streamCh <- runMessageMsg{Message: message}:
+				}
 				return nil
 			},
 		}
@@ -1200,25 +1830,32 @@ func (m *tuiModel) finishRun(result *agent.RunResult) {
 	m.statusText = ""
 	m.cancel = nil
 	m.pendingPermission = nil
+	m.agentRun = nil
+	m.interrupted = false
+	m.flushThinking()
 	if result == nil {
 		m.appendAssistantText(m.streamBuffer.String())
 		m.streamBuffer.Reset()
 		m.refreshChat()
+		m.applyPendingReload()
 		return
 	}
 	m.history = result.Messages
 	m.lastUsage = result.Usage
-	m.totalCost = result.CostUSD
+	m.providerFailures = 0
+	m.recordCostTurn(result)
 	finalText := formatContent(result.Final.Content)
 	if finalText == "" {
 		finalText = m.streamBuffer.String()
 	}
 	m.appendAssistantText(finalText)
 	m.streamBuffer.Reset()
+	m.noteEditedFiles(result.Events)
 	m.refreshChat()
 	if m.store != nil {
 		m.persistRun(result)
 	}
+	m.applyPendingReload()
 }
 
 // finishError handles errors from the streaming run.
@@ -1228,7 +1865,11 @@ func (m *tuiModel) finishError(err error) {
 	m.statusText = formatInteractiveError(err)
 	m.cancel = nil
 	m.pendingPermission = nil
+	m.agentRun = nil
+	m.interrupted = false
+	m.flushThinking()
 	m.streamBuffer.Reset()
+	m.applyPendingReload()
 }
 
 // cancelRun cancels an in-flight request and updates status.
@@ -1249,13 +1890,18 @@ func (m *tuiModel) handlePermissionRequest(request *permissionRequest) {
 		return
 	}
 	m.pendingPermission = request
+	// The prompt must be visible to be answered.
+	m.closeTranscript()
 	m.input.Blur()
 	summary := summarizeToolArgs(request.Args, 160)
 	if summary != "" {
 		m.toolLines = append(m.toolLines, fmt.Sprintf("%s args: %s", request.ToolName, summary))
 		m.refreshTools()
 	}
-	m.statusText = fmt.Sprintf("Allow tool %s? [y/N]", request.ToolName)
+	m.statusText = fmt.Sprintf("Allow tool %s? [y/N/a=always]", request.ToolName)
+	if request.Preflight != nil {
+		m.statusText = "Uncommitted changes. [s=stash/p=proceed/N]"
+	}
 }
 
 // resolvePermission sends the user's decision back to the agent loop.
@@ -1276,6 +1922,25 @@ func (m *tuiModel) resolvePermission(allowed bool) {
 	}
 }
 
+// resolvePermissionAlways allows the pending call and remembers the approval.
+// Bash approvals cover the exact command; /permissions export saves them for CI.
+func (m *tuiModel) resolvePermissionAlways() {
+	request := m.pendingPermission
+	if request == nil {
+		return
+	}
+	rule := tools.PermissionRuleFor(request.ToolName, request.Args)
+	if m.opts != nil && !slices.Contains(m.opts.SessionApprovals, rule) {
+		m.opts.SessionApprovals = append(m.opts.SessionApprovals, rule)
+	}
+	// Record the rule before answering so the agent loop sees it on its next call.
+	if m.runner != nil {
+		m.runner.Permissions.AlwaysAllow = append(m.runner.Permissions.AlwaysAllow, rule)
+	}
+	m.resolvePermission(true)
+	m.statusText = fmt.Sprintf("Always allowing %s this session; /permissions export saves it.", rule)
+}
+
 // appendMessage adds a new chat message to the display list.
 func (m *tuiModel) appendMessage(role string, content string) {
 	m.chatMessages = append(m.chatMessages, tuiMessage{Role: role, Content: content})
@@ -1352,6 +2017,55 @@ func (m *tuiModel) appendAssistantText(text string) {
 	})
 }
 
+// appendThinking stores a completed thinking block in the chat view.
+func (m *tuiModel) appendThinking(text string) {
+	m.chatMessages = append(m.chatMessages, tuiMessage{
+		Kind:    tuiMessageAssistantThinking,
+		Role:    "assistant",
+		Content: text,
+	})
+}
+
+// flushThinking moves streamed reasoning into a chat block once visible output follows it.
+func (m *tuiModel) flushThinking() {
+	if m.thinkingBuffer.Len() == 0 {
+		return
+	}
+	if text := m.thinkingBuffer.String(); strings.TrimSpace(text) != "" {
+		m.appendThinking(text)
+	}
+	m.thinkingBuffer.Reset()
+}
+
+// toggleThinking expands or collapses every thinking block.
+func (m *tuiModel) toggleThinking() {
+	m.thinkingExpanded = !m.thinkingExpanded
+	// Thinking renders depend on the toggle, which is not part of the cache key.
+	m.invalidateRenderCache()
+	m.refreshChat()
+	if m.thinkingExpanded {
+		m.inputHint = "Showing thinking · ctrl+t to collapse"
+	} else {
+		m.inputHint = "Thinking collapsed · ctrl+t to expand"
+	}
+}
+
+// toggleVerbose switches between condensed and full tool inputs and outputs for
+// subsequent tool messages and saves the choice with the session.
+func (m *tuiModel) toggleVerbose() {
+	m.verbose = !m.verbose
+	if m.store != nil {
+		if err := m.store.SaveUIState(m.sessionID, session.UIState{Verbose: m.verbose}); err != nil {
+			m.statusText = err.Error()
+		}
+	}
+	if m.verbose {
+		m.inputHint = "Verbose tool output · ctrl+o to condense"
+	} else {
+		m.inputHint = "Condensed tool output · ctrl+o for verbose"
+	}
+}
+
 // appendSystemMessage stores a system informational message in the chat view.
 func (m *tuiModel) appendSystemMessage(text string) {
 	m.chatMessages = append(m.chatMessages, tuiMessage{
@@ -1372,6 +2086,9 @@ func (m *tuiModel) appendToolUseMessage(event agent.ToolEvent, status tuiToolSta
 		return
 	}
 	toolArgs := summarizeToolArgs(event.Arguments, 120)
+	if m.verbose {
+		toolArgs = formatVerboseToolArgs(event.Arguments)
+	}
 	message := tuiMessage{
 		Kind:       tuiMessageAssistantToolUse,
 		Role:       "assistant",
@@ -1380,12 +2097,39 @@ func (m *tuiModel) appendToolUseMessage(event agent.ToolEvent, status tuiToolSta
 		ToolID:     event.ToolID,
 		ToolArgs:   toolArgs,
 		ToolStatus: status,
+		Expanded:   m.verbose,
+	}
+	index := len(m.chatMessages)
+	m.chatMessages = append(m.chatMessages, message)
+	if event.ToolID != "" {
+		m.toolStates[event.ToolID] = tuiToolState{Index: index, Status: status}
+	}
+}
+
+// updateToolProgress shows the latest progress on a running tool's line.
+func (m *tuiModel) updateToolProgress(toolID string, progress tools.Progress) {
+	state, ok := m.toolStates[toolID]
+	if !ok || state.Status != tuiToolRunning || state.Index < 0 || state.Index >= len(m.chatMessages) {
+		return
+	}
+	m.chatMessages[state.Index].ToolProgress = progress
+	m.refreshChat()
+}
+
+// appendToolOutput shows a running tool's latest output lines under its line;
+// the full output appears with the result.
+func (m *tuiModel) appendToolOutput(toolID string, text string) {
+	state, ok := m.toolStates[toolID]
+	if !ok || state.Status != tuiToolRunning || state.Index < 0 || state.Index >= len(m.chatMessages) {
+		return
 	}
-	index := len(m.chatMessages)
-	m.chatMessages = append(m.chatMessages, message)
-	if event.ToolID != "" {
-		m.toolStates[event.ToolID] = tuiToolState{Index: index, Status: status}
+	combined := m.chatMessages[state.Index].ToolOutput + text
+	lines := strings.Split(strings.TrimRight(combined, "\n"), "\n")
+	if len(lines) > tuiLiveOutputLines {
+		lines = lines[len(lines)-tuiLiveOutputLines:]
 	}
+	m.chatMessages[state.Index].ToolOutput = strings.Join(lines, "\n") + "\n"
+	m.refreshChat()
 }
 
 // appendToolResultMessage records a tool result and updates tool status.
@@ -1399,24 +2143,32 @@ func (m *tuiModel) appendToolResultMessage(event agent.ToolEvent) {
 			if state.Index >= 0 && state.Index < len(m.chatMessages) {
 				updated := m.chatMessages[state.Index]
 				updated.ToolStatus = status
+				updated.ToolProgress = tools.Progress{}
+				updated.ToolOutput = ""
 				m.chatMessages[state.Index] = updated
 			}
 			state.Status = status
 			m.toolStates[event.ToolID] = state
 		}
 	}
-	m.chatMessages = append(m.chatMessages, tuiMessage{
+	message := tuiMessage{
 		Kind:      tuiMessageToolResult,
 		Role:      "tool",
 		Content:   event.Result,
 		ToolName:  event.ToolName,
 		ToolID:    event.ToolID,
 		ToolError: event.IsError,
-	})
+		Stderr:    event.Stderr,
+		Expanded:  m.verbose,
+	}
+	if event.ExitCode != nil {
+		message.ExitCode = *event.ExitCode
+	}
+	m.chatMessages = append(m.chatMessages, message)
 }
 
 // appendUserMessageFromHistory reconstructs a user message from stored history.
-func (m *tuiModel) appendUserMessageFromHistory(message openai.Message) {
+func (m *tuiModel) appendUserMessageFromHistory(message llm.Message) {
 	rawText := extractMessageText(message)
 	if rawText == "" && message.Content != nil {
 		rawText = formatContent(message.Content)
@@ -1441,7 +2193,7 @@ func (m *tuiModel) appendUserMessageFromHistory(message openai.Message) {
 }
 
 // appendAssistantMessageFromHistory reconstructs assistant output from history.
-func (m *tuiModel) appendAssistantMessageFromHistory(message openai.Message) {
+func (m *tuiModel) appendAssistantMessageFromHistory(message llm.Message) {
 	// Recreate tool-use lines before rendering assistant text.
 	for _, call := range message.ToolCalls {
 		arguments := json.RawMessage(call.Function.Arguments)
@@ -1452,6 +2204,10 @@ func (m *tuiModel) appendAssistantMessageFromHistory(message openai.Message) {
 			Arguments: arguments,
 		}, tuiToolRunning)
 	}
+	m.appendServerToolCalls(message)
+	if strings.TrimSpace(message.Reasoning) != "" {
+		m.appendThinking(message.Reasoning)
+	}
 	text := extractMessageText(message)
 	if text == "" && message.Content != nil {
 		text = formatContent(message.Content)
@@ -1462,8 +2218,20 @@ func (m *tuiModel) appendAssistantMessageFromHistory(message openai.Message) {
 	m.appendAssistantText(text)
 }
 
+// appendServerToolCalls shows the tools the provider ran for a reply as
+// finished tool lines.
+func (m *tuiModel) appendServerToolCalls(message llm.Message) {
+	for _, call := range message.ServerToolCalls {
+		event := agent.ToolEvent{Type: "tool_call", ToolName: call.Name, ToolID: call.ID, Arguments: json.RawMessage(call.Input)}
+		m.appendToolUseMessage(event, tuiToolCompleted)
+		event.Type = "tool_result"
+		event.Result = "Ran on the provider"
+		m.appendToolResultMessage(event)
+	}
+}
+
 // appendToolResultFromHistory reconstructs tool result lines from history.
-func (m *tuiModel) appendToolResultFromHistory(message openai.Message, toolNames map[string]string) {
+func (m *tuiModel) appendToolResultFromHistory(message llm.Message, toolNames map[string]string) {
 	content := formatContent(message.Content)
 	if content == "" {
 		content = extractMessageText(message)
@@ -1486,11 +2254,21 @@ func (m *tuiModel) refreshChat() {
 		builder.WriteString(welcome)
 		builder.WriteString("\n\n")
 	}
-	for _, msg := range m.chatMessages {
-		builder.WriteString(m.renderMessage(msg, false))
+	line := strings.Count(builder.String(), "\n")
+	m.chatMessageLines = m.chatMessageLines[:0]
+	for index, msg := range m.chatMessages {
+		rendered := m.renderCachedMessage(index, msg)
+		m.chatMessageLines = append(m.chatMessageLines, line)
+		line += strings.Count(rendered, "\n") + 2
+		builder.WriteString(rendered)
 		builder.WriteString("\n\n")
 	}
 	if m.running {
+		// The streaming tail is the only block that changes on every delta.
+		if thinking := m.thinkingBuffer.String(); thinking != "" {
+			builder.WriteString(m.renderMessage(tuiMessage{Kind: tuiMessageAssistantThinking, Role: "assistant", Content: thinking}, true))
+			builder.WriteString("\n\n")
+		}
 		streamText := m.streamBuffer.String()
 		if streamText != "" {
 			builder.WriteString(
@@ -1508,6 +2286,44 @@ func (m *tuiModel) refreshChat() {
 	}
 }
 
+// renderCachedMessage returns the rendered block for a chat message, reusing
+// the cached render when the message and width have not changed.
+func (m *tuiModel) renderCachedMessage(index int, msg tuiMessage) string {
+	if len(m.renderCache) > len(m.chatMessages) {
+		// Drop stale entries after history resets or forks.
+		m.renderCache = m.renderCache[:len(m.chatMessages)]
+	}
+	for len(m.renderCache) <= index {
+		m.renderCache = append(m.renderCache, tuiRenderedMessage{})
+	}
+	// Unresolved tool-use lines blink with the tool spinner, so never cache them.
+	if isVolatileTUIMessage(msg) {
+		m.renderCache[index] = tuiRenderedMessage{}
+		return m.renderMessage(msg, false)
+	}
+	cached := m.renderCache[index]
+	if cached.Valid && cached.Width == m.width && cached.Message == msg {
+		return cached.Rendered
+	}
+	rendered := m.renderMessage(msg, false)
+	m.renderCache[index] = tuiRenderedMessage{Message: msg, Width: m.width, Rendered: rendered, Valid: true}
+	return rendered
+}
+
+// invalidateRenderCache forces every chat message to re-render on the next refresh.
+func (m *tuiModel) invalidateRenderCache() {
+	m.renderCache = nil
+	m.streamRender = tuiStreamRender{}
+}
+
+// isVolatileTUIMessage reports whether a message's rendering depends on animation state.
+func isVolatileTUIMessage(msg tuiMessage) bool {
+	if msg.Kind != tuiMessageAssistantToolUse {
+		return false
+	}
+	return msg.ToolStatus == tuiToolQueued || msg.ToolStatus == tuiToolRunning
+}
+
 // scheduleSpinnerTick schedules tool-use animation ticks when needed.
 func (m *tuiModel) scheduleSpinnerTick() tea.Cmd {
 	if !m.shouldAnimateTools() {
@@ -1602,6 +2418,11 @@ func (m *tuiModel) renderWelcome() string {
 		"  " + secondaryStyle.Render("/help for help"),
 		"  " + secondaryStyle.Render("cwd: "+cwd),
 	}
+	if m.opts != nil && m.opts.Toolchain != nil {
+		if brief := m.opts.Toolchain.Brief(); brief != "" {
+			lines = append(lines, "  "+secondaryStyle.Render("detected: "+brief))
+		}
+	}
 
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -1616,6 +2437,7 @@ func (m *tuiModel) renderWelcome() string {
 // bootstrapHistory seeds the chat view with previous session messages.
 func (m *tuiModel) bootstrapHistory() {
 	m.chatMessages = nil
+	m.invalidateRenderCache()
 	m.toolStates = map[string]tuiToolState{}
 
 	toolNames := map[string]string{}
@@ -1648,15 +2470,37 @@ func (m *tuiModel) bootstrapHistory() {
 
 // persistRun appends new session messages and events to storage.
 func (m *tuiModel) persistRun(result *agent.RunResult) {
-	previousLen := len(m.history)
-	newMessages := result.Messages
-	if previousLen > 0 && len(result.Messages) >= previousLen {
-		newMessages = result.Messages[previousLen:]
+	m.persistTurn(result.Events, result.CostUSD)
+}
+
+// persistTurn stores the end of a turn, finished or interrupted: the history
+// not stored yet, its tool events, and its cost, then marks the session as the
+// one --continue resumes.
+func (m *tuiModel) persistTurn(events []agent.ToolEvent, costUSD float64) {
+	m.persistHistory(events)
+	_ = m.store.AppendCost(m.sessionID, costUSD)
+	_ = m.store.SaveLastSession(session.ProjectHash(mustCwd()), m.sessionID)
+	_ = m.store.CloseSession(m.sessionID)
+}
+
+// persistHistory appends the history messages not stored yet, the prompt of
+// the run that produced them included, to the session log with events.
+func (m *tuiModel) persistHistory(events []agent.ToolEvent) {
+	if m.store == nil {
+		return
+	}
+	// A rewound history is shorter than what was stored; store what follows it.
+	start := min(m.persisted, len(m.history))
+	var messages []llm.Message
+	for _, message := range m.history[start:] {
+		if message.Role != "system" {
+			messages = append(messages, message)
+		}
 	}
-	if err := persistSession(m.store, m.sessionID, newMessages, result.Events); err != nil {
+	m.persisted = len(m.history)
+	if err := persistSession(m.store, m.sessionID, messages, events); err != nil {
 		m.statusText = err.Error()
 	}
-	_ = m.store.SaveLastSession(session.ProjectHash(mustCwd()), m.sessionID)
 }
 
 // applyWindowSize recalculates the layout for a new window size.
@@ -1665,6 +2509,7 @@ func (m *tuiModel) applyWindowSize(msg tea.WindowSizeMsg) {
 	m.height = msg.Height
 	m.updateLayout()
 	m.refreshChat()
+	m.layoutTranscript()
 }
 
 // updateLayout recalculates viewport sizing based on visible UI sections.
@@ -1688,6 +2533,9 @@ func (m *tuiModel) updateLayout() {
 	if m.showMessageSelector {
 		occupied += lipgloss.Height(m.renderMessageSelector())
 	}
+	if len(m.queuedMessages) > 0 {
+		occupied += lipgloss.Height(m.renderQueuedMessages())
+	}
 	if m.shouldShowInput() {
 		occupied += lipgloss.Height(m.renderInput())
 	}
@@ -1736,10 +2584,7 @@ func (m *tuiModel) renderSpinner() string {
 	if len(m.spinnerFrames) > 0 {
 		frame = m.spinnerFrames[m.spinnerFrame%len(m.spinnerFrames)]
 	}
-	message := m.spinnerMessage
-	if message == "" {
-		message = "Thinking"
-	}
+	message := m.spinnerStatus()
 	elapsed := 0
 	if !m.spinnerStarted.IsZero() {
 		elapsed = int(time.Since(m.spinnerStarted).Seconds())
@@ -1748,8 +2593,12 @@ func (m *tuiModel) renderSpinner() string {
 	frameText := lipgloss.NewStyle().Foreground(m.theme.Claude).Render(frame)
 	messageText := lipgloss.NewStyle().Foreground(m.theme.Claude).Render(message + "… ")
 	escText := lipgloss.NewStyle().Foreground(m.theme.Secondary).Bold(true).Render("esc")
+	detail := ""
+	if m.spinnerDetail != "" {
+		detail = m.spinnerDetail + " · "
+	}
 	metaText := lipgloss.NewStyle().Foreground(m.theme.Secondary).Render(
-		fmt.Sprintf("(%ds · %s to interrupt)", elapsed, escText),
+		fmt.Sprintf("(%ds · %s%s to interrupt)", elapsed, detail, escText),
 	)
 
 	line := fmt.Sprintf("%s %s%s", frameText, messageText, metaText)
@@ -1831,7 +2680,61 @@ func (m *tuiModel) renderMessageSelector() string {
 	boxStyle := lipgloss.NewStyle().Border(m.border()).Padding(0, 1)
 	boxWidth := maxInt(20, m.width-2)
 	box := boxStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
-	hint := m.renderInputHintLine("↑/↓ to select · Enter to confirm · Tab/Esc to cancel")
+	hint := m.renderInputHintLine("↑/↓ to select · Enter to edit and re-run · f to fork into a new session · Tab/Esc to cancel")
+	return lipgloss.JoinVertical(lipgloss.Left, box, hint)
+}
+
+// renderPermissionRequest draws the tool approval prompt when one is pending.
+func (m *tuiModel) renderPermissionRequest() string {
+	request := m.pendingPermission
+	if request == nil {
+		return ""
+	}
+	if request.Preflight != nil {
+		return m.renderPreflightWarning(request.Preflight)
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Foreground(m.theme.Permission).Bold(true).Render("Tool use"),
+		"",
+		fmt.Sprintf("  %s", request.ToolName),
+	}
+	// Summaries keep long tool inputs from pushing the prompt off screen.
+	if summary := summarizeToolArgs(request.Args, 160); summary != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(m.theme.Secondary).Render("  "+summary))
+	}
+	lines = append(lines, "", "Do you want to proceed?")
+
+	boxStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(m.theme.Permission).
+		Padding(0, 1)
+	boxWidth := maxInt(20, m.width-2)
+	box := boxStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	hint := m.renderInputHintLine("y to allow · n/Esc to deny")
+	return lipgloss.JoinVertical(lipgloss.Left, box, hint)
+}
+
+// renderPreflightWarning draws the git pre-flight prompt shown before the
+// first edit of a file with uncommitted changes.
+func (m *tuiModel) renderPreflightWarning(warning *gitPreflightWarning) string {
+	lines := []string{
+		lipgloss.NewStyle().Foreground(m.theme.Permission).Bold(true).Render("Uncommitted changes"),
+		"",
+		fmt.Sprintf("  %s is about to modify:", warning.ToolName),
+	}
+	for _, file := range warning.Files {
+		lines = append(lines, lipgloss.NewStyle().Foreground(m.theme.Secondary).Render("  "+file))
+	}
+	lines = append(lines, "", fmt.Sprintf("%d file(s) in the working tree have uncommitted changes that agent edits would mix with.", warning.Dirty))
+
+	boxStyle := lipgloss.NewStyle().
+		Border(m.border()).
+		BorderForeground(m.theme.Permission).
+		Padding(0, 1)
+	boxWidth := maxInt(20, m.width-2)
+	box := boxStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	hint := m.renderInputHintLine("s to stash them and continue · p to proceed · n/Esc to cancel")
 	return lipgloss.JoinVertical(lipgloss.Left, box, hint)
 }
 
@@ -1844,6 +2747,8 @@ func (m *tuiModel) handleSelectorKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "up":
 		if m.selectorIndex > 0 {
 			m.selectorIndex--
+		} else {
+			m.loadOlderHistory()
 		}
 		return m, nil
 	case "down":
@@ -1852,13 +2757,17 @@ func (m *tuiModel) handleSelectorKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case "home":
+		m.loadOlderHistory()
 		m.selectorIndex = 0
 		return m, nil
 	case "end":
 		m.selectorIndex = len(m.selectorItems) - 1
 		return m, nil
 	case "enter":
-		m.applySelectorSelection()
+		m.applySelectorSelection(false)
+		return m, nil
+	case "f":
+		m.applySelectorSelection(true)
 		return m, nil
 	default:
 	}
@@ -1869,7 +2778,7 @@ func (m *tuiModel) handleSelectorKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 			index := int(runeValue - '1')
 			if index >= 0 && index < len(m.selectorItems) {
 				m.selectorIndex = index
-				m.applySelectorSelection()
+				m.applySelectorSelection(false)
 				return m, nil
 			}
 		}
@@ -1878,14 +2787,84 @@ func (m *tuiModel) handleSelectorKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// loadOlderHistory pages the previous window of session messages into history.
+// It is used when the selector scrolls past the oldest loaded message.
+func (m *tuiModel) loadOlderHistory() {
+	if !m.historyCursor.HasMore || m.store == nil || m.running {
+		return
+	}
+	loaded, err := m.prependOlderHistory()
+	if err != nil {
+		m.statusText = fmt.Sprintf("Load older history: %v", err)
+		return
+	}
+	if !loaded {
+		return
+	}
+
+	// Preserve the selection on the same message after the list grows.
+	previousCount := len(m.selectorItems)
+	m.bootstrapHistory()
+	m.selectorItems = m.buildSelectorItems()
+	added := len(m.selectorItems) - previousCount
+	if added > 0 {
+		m.selectorIndex += added - 1
+	}
+	if m.selectorIndex < 0 {
+		m.selectorIndex = 0
+	}
+}
+
+// prependOlderHistory inserts the previous window of session messages into
+// history without redrawing, reporting whether any were added.
+func (m *tuiModel) prependOlderHistory() (bool, error) {
+	older, cursor, err := loadOlderSessionMessages(m.store, m.historyCursor, sessionHistoryPageSize)
+	if err != nil {
+		return false, err
+	}
+	m.historyCursor = cursor
+	if len(older) == 0 {
+		return false, nil
+	}
+
+	previous := len(m.history)
+	m.history = mergeOlderHistory(m.history, older)
+	m.persisted += len(m.history) - previous
+	return true, nil
+}
+
+// mergeOlderHistory inserts an older page of messages before history, keeping
+// the leading system prompt of history in place and dropping the page's own.
+// It returns a new slice; history itself is not modified.
+func mergeOlderHistory(history []llm.Message, older []llm.Message) []llm.Message {
+	var prefix []llm.Message
+	rest := history
+	if len(rest) > 0 && rest[0].Role == "system" {
+		prefix = rest[:1]
+		rest = rest[1:]
+	}
+	if len(older) > 0 && older[0].Role == "system" {
+		older = older[1:]
+	}
+	merged := make([]llm.Message, 0, len(prefix)+len(older)+len(rest))
+	merged = append(merged, prefix...)
+	merged = append(merged, older...)
+	merged = append(merged, rest...)
+	return merged
+}
+
 // applySelectorSelection forks the conversation at the selected message.
-func (m *tuiModel) applySelectorSelection() {
+// Without newSession the message is put in the input box to edit, and the
+// conversation is rewound when the edit is sent (see beginResend). With
+// newSession the fork is stored as a new session right away, and the TUI
+// continues in it, leaving the current session as it was.
+func (m *tuiModel) applySelectorSelection(newSession bool) {
 	if len(m.selectorItems) == 0 || m.selectorIndex < 0 || m.selectorIndex >= len(m.selectorItems) {
 		m.closeMessageSelector()
 		return
 	}
 	selected := m.selectorItems[m.selectorIndex]
-	if selected.IsCurrent {
+	if selected.IsCurrent && !newSession {
 		m.closeMessageSelector()
 		return
 	}
@@ -1895,17 +2874,34 @@ func (m *tuiModel) applySelectorSelection() {
 		m.cancelRun("Cancelled.")
 		m.statusText = ""
 	}
+	if selected.Index < 0 || selected.Index > len(m.history) {
+		m.closeMessageSelector()
+		return
+	}
+	if !newSession {
+		m.beginResend(selected)
+		m.closeMessageSelector()
+		return
+	}
 	// Reset transient state so the forked conversation is clean.
 	m.pendingPermission = nil
 	m.streamBuffer.Reset()
 	m.toolLines = nil
 	m.toolView.SetContent("No tool activity yet.")
-	if selected.Index >= 0 && selected.Index <= len(m.history) {
-		// Exclude the selected message so it can be edited and re-sent.
-		m.history = m.history[:selected.Index]
+	// Exclude the selected message so it can be edited and re-sent.
+	drop := len(m.history) - selected.Index
+	parent := m.sessionID
+	forkID, err := m.forkSession(drop)
+	if err != nil {
+		m.statusText = fmt.Sprintf("Fork failed: %v", err)
+		m.closeMessageSelector()
+		return
 	}
+	m.history = m.history[:len(m.history)-drop]
 
 	m.bootstrapHistory()
+	m.appendSystemMessage(formatForkNotice(parent, forkID))
+	m.refreshChat()
 	m.input.SetValue(selected.Input)
 	m.input.CursorEnd()
 	m.setInputMode(selected.Mode)
@@ -1917,7 +2913,7 @@ func (m *tuiModel) applySelectorSelection() {
 func (m *tuiModel) buildSelectorItems() []tuiSelectorItem {
 	items := make([]tuiSelectorItem, 0, len(m.history)+1)
 	for index, message := range m.history {
-		if message.Role != "user" {
+		if message.Role != "user" || message.Content == tuiInterruptMessage {
 			continue
 		}
 		// Only user messages are eligible for conversation forks.
@@ -1941,7 +2937,7 @@ func (m *tuiModel) buildSelectorItems() []tuiSelectorItem {
 }
 
 // selectorTextForMessage extracts the selector preview and input restore values.
-func selectorTextForMessage(message openai.Message) (string, string, tuiInputMode) {
+func selectorTextForMessage(message llm.Message) (string, string, tuiInputMode) {
 	rawText := extractMessageText(message)
 	if rawText == "" && message.Content != nil {
 		rawText = formatContent(message.Content)
@@ -2119,12 +3115,50 @@ func (m *tuiModel) renderStatusInfo() string {
 	if m.lastUsage.TotalTokens > 0 {
 		parts = append(parts, fmt.Sprintf("tokens:%d", m.lastUsage.TotalTokens))
 	}
+	if m.runner != nil {
+		if metrics, ok := m.runner.Status.Snapshot().Metrics(time.Now()); ok {
+			parts = append(parts, formatStatusMetrics(metrics))
+		}
+	}
 	if m.totalCost > 0 {
 		parts = append(parts, fmt.Sprintf("cost:$%.4f", m.totalCost))
 	}
+	if m.gitStatus != nil {
+		parts = append(parts, "git:"+m.gitStatus.Brief())
+	}
 	return strings.Join(parts, " ")
 }
 
+// formatStatusMetrics renders API speed for the status line: latency (live
+// while a request is in flight), time to first token, and output throughput.
+func formatStatusMetrics(metrics agent.RequestMetrics) string {
+	text := "api:" + formatProbeDuration(metrics.Latency)
+	if metrics.TimeToFirstToken > 0 {
+		text += " ttft:" + formatProbeDuration(metrics.TimeToFirstToken)
+	}
+	if rate := metrics.TokensPerSecond(); rate > 0 {
+		approx := ""
+		if metrics.Estimated {
+			approx = "~"
+		}
+		text += fmt.Sprintf(" %s%.0ftok/s", approx, rate)
+	}
+	return text
+}
+
+// refreshGitStatus reloads the repository state in the background after a
+// turn, since tools and bash commands may have changed it. Refreshing stays
+// off when startup found no repository or detection is disabled.
+func (m *tuiModel) refreshGitStatus() tea.Cmd {
+	if m.opts == nil || m.opts.GitStatus == nil {
+		return nil
+	}
+	dir := m.promptCWD()
+	return func() tea.Msg {
+		return gitStatusMsg{Status: project.LoadGitStatus(context.Background(), dir)}
+	}
+}
+
 // refreshPlanMode syncs the plan-only indicator from the session store.
 func (m *tuiModel) refreshPlanMode() {
 	if m.store == nil || m.sessionID == "" {
@@ -2180,8 +3214,18 @@ func (m *tuiModel) renderUserBashMessage(message tuiMessage) string {
 	prefixStyle := lipgloss.NewStyle().Foreground(m.theme.Bash)
 	textStyle := lipgloss.NewStyle().Foreground(m.theme.Secondary)
 	prefix := prefixStyle.Render("!")
-	content := indentMultiline(message.Content, "  ")
-	return fmt.Sprintf("%s %s", prefix, textStyle.Render(content))
+	if !strings.Contains(message.Content, "\n") {
+		return fmt.Sprintf("%s %s", prefix, textStyle.Render(message.Content))
+	}
+	// Multi-line commands (heredocs, continuations) render verbatim as a code
+	// block so indentation and delimiter lines stay intact.
+	block := lipgloss.NewStyle().
+		Foreground(m.theme.Text).
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(m.theme.Bash).
+		PaddingLeft(1).
+		Render(strings.ReplaceAll(message.Content, "\t", "    "))
+	return fmt.Sprintf("%s %s\n%s", prefix, textStyle.Render("bash"), indentMultiline("  "+block, "  "))
 }
 
 // renderUserCommandMessage draws a user slash command entry.
@@ -2202,7 +3246,9 @@ func (m *tuiModel) renderAssistantTextMessage(message tuiMessage, streaming bool
 	if rendered, handled := m.renderAssistantSpecial(content, streaming); handled {
 		return rendered
 	}
-	if !streaming {
+	if streaming {
+		content = m.renderStreamingMarkdown(content)
+	} else {
 		content = m.renderMarkdown(content)
 	}
 	indent := ""
@@ -2239,27 +3285,99 @@ func (m *tuiModel) renderAssistantToolUseMessage(message tuiMessage) string {
 		indicatorText = lipgloss.NewStyle().Foreground(color).Render(indicator) + " "
 	}
 	nameText := lipgloss.NewStyle().Foreground(color).Bold(message.ToolStatus != tuiToolQueued).Render(message.ToolName)
-	args := ""
-	if message.ToolArgs != "" {
-		args = fmt.Sprintf("(%s)", message.ToolArgs)
+	var line string
+	if message.Expanded && message.ToolArgs != "" {
+		// Verbose tool inputs are shown in full below the tool name.
+		body := lipgloss.NewStyle().Foreground(m.theme.Secondary).Render(indentMultiline(message.ToolArgs, "    "))
+		line = fmt.Sprintf("%s%s…\n%s", indicatorText, nameText, body)
+	} else {
+		args := ""
+		if message.ToolArgs != "" {
+			args = fmt.Sprintf("(%s)", message.ToolArgs)
+		}
+		if args != "" {
+			args = " " + lipgloss.NewStyle().Foreground(color).Render(args)
+		}
+		line = fmt.Sprintf("%s%s%s…", indicatorText, nameText, args)
+	}
+	if isUnresolved && message.ToolProgress.Unit != "" {
+		line += "\n" + lipgloss.NewStyle().Foreground(m.theme.Secondary).Render("  ⎿  "+formatToolProgressBar(message.ToolProgress))
+	}
+	if isUnresolved && message.ToolOutput != "" {
+		output := clipLines(strings.TrimRight(message.ToolOutput, "\n"), m.width-6)
+		line += "\n" + lipgloss.NewStyle().Foreground(m.theme.Secondary).Render("  ⎿  "+indentMultiline(output, "     "))
+	}
+	return line
+}
+
+// clipLines cuts each line to width runes, marking cut lines with "…", so live
+// output never wraps; a non-positive width leaves lines whole.
+func clipLines(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		if runes := []rune(line); len(runes) > width {
+			lines[index] = string(runes[:width-1]) + "…"
+		}
 	}
-	if args != "" {
-		args = " " + lipgloss.NewStyle().Foreground(color).Render(args)
+	return strings.Join(lines, "\n")
+}
+
+// tuiProgressBarWidth is the number of cells in a tool progress bar.
+const tuiProgressBarWidth = 20
+
+// formatToolProgressBar renders progress as a bar with a percentage when the
+// total is known, or as a running count otherwise.
+func formatToolProgressBar(progress tools.Progress) string {
+	percent := progress.Percent()
+	if percent < 0 {
+		return progress.String()
 	}
-	return fmt.Sprintf("%s%s%s…", indicatorText, nameText, args)
+	filled := percent * tuiProgressBarWidth / 100
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", tuiProgressBarWidth-filled)
+	return fmt.Sprintf("%s %3d%% · %s", bar, percent, progress)
 }
 
-// renderToolResultMessage renders tool result output lines.
+// renderToolResultMessage renders tool result output lines, collapsed to
+// their first lines unless the message is expanded.
+// Command stderr is split from the output and shown after it in the warning
+// color (the error color when the command failed), with a non-zero exit code.
 func (m *tuiModel) renderToolResultMessage(message tuiMessage) string {
-	content := strings.TrimSpace(message.Content)
-	if content == "" {
+	content, stderr := splitToolResult(message)
+	if content == "" && stderr == "" {
 		content = "(No content)"
 	}
-	content = truncateOutputLines(content, tuiMaxRenderedLines)
-	return m.renderIndentedResultLine(content, message.ToolError)
+	hidden := 0
+	if !message.Expanded {
+		var hiddenContent, hiddenStderr int
+		content, hiddenContent = collapseOutputLines(content, tuiCollapsedLines)
+		stderr, hiddenStderr = collapseOutputLines(stderr, tuiCollapsedLines)
+		hidden = hiddenContent + hiddenStderr
+	}
+	lines := []string{}
+	if content != "" {
+		lines = append(lines, m.renderIndentedResultLine(content, message.ToolError))
+	}
+	if stderr != "" {
+		color := m.theme.Warning
+		if message.ToolError {
+			color = m.theme.Error
+		}
+		lines = append(lines, "  ⎿ "+lipgloss.NewStyle().Foreground(color).Render(indentMultiline(stderr, "    ")))
+	}
+	if hidden > 0 {
+		lines = append(lines, m.renderCollapsedHint(hidden))
+	}
+	if message.ExitCode != 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(m.theme.Secondary).Render(fmt.Sprintf("    exit code %d", message.ExitCode)))
+	}
+	return strings.Join(lines, "\n")
 }
 
 // renderAssistantThinkingMessage renders a "thinking" block.
+// Blocks are collapsed to a header with a line count unless expanded with ctrl+t.
 func (m *tuiModel) renderAssistantThinkingMessage(message tuiMessage) string {
 	style := lipgloss.NewStyle().Foreground(m.theme.Secondary).Italic(true)
 	heading := style.Render("✻ Thinking…")
@@ -2267,6 +3385,17 @@ func (m *tuiModel) renderAssistantThinkingMessage(message tuiMessage) string {
 	if content == "" {
 		return heading
 	}
+	if !m.thinkingExpanded {
+		lines := strings.Count(content, "\n") + 1
+		unit := "lines"
+		if lines == 1 {
+			unit = "line"
+		}
+		hint := lipgloss.NewStyle().Foreground(m.theme.Secondary).Render(
+			fmt.Sprintf(" (%d %s · ctrl+t to expand)", lines, unit),
+		)
+		return heading + hint
+	}
 	body := style.Render(indentMultiline(content, "  "))
 	return strings.Join([]string{heading, body}, "\n")
 }
@@ -2389,6 +3518,10 @@ func (m *tuiModel) renderIndentedResultLine(content string, isError bool) string
 	} else if trimmed == "(No content)" {
 		lineColor = m.theme.Secondary
 	}
+	if !isError && m.codeStyle.Theme != "" && looksLikeUnifiedDiff(trimmed) {
+		// Diffs from tools keep their own colors for added and removed lines.
+		return prefix + indentMultiline(m.codeStyle.Highlight(trimmed, "diff"), indent)
+	}
 	style := lipgloss.NewStyle().Foreground(lineColor)
 	rendered := indentMultiline(trimmed, indent)
 	return prefix + style.Render(rendered)
@@ -2463,14 +3596,32 @@ func truncateOutputLines(content string, maxLines int) string {
 
 // renderMarkdown converts markdown into terminal-friendly output when possible.
 func (m *tuiModel) renderMarkdown(content string) string {
+	return m.markdownRenderer.Render(content)
+}
+
+// togglePlainMarkdown handles /plain [on|off], which switches markdown rendering off or back on.
+func (m *tuiModel) togglePlainMarkdown(args string) string {
 	if m.markdownRenderer == nil {
-		return content
+		return "Markdown rendering is unavailable; messages are shown as plain text."
+	}
+	plain := !m.markdownRenderer.plain
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+	case "on":
+		plain = true
+	case "off":
+		plain = false
+	default:
+		return "Usage: /plain [on|off]"
 	}
-	rendered, err := m.markdownRenderer.Render(content)
-	if err != nil {
-		return content
+	m.markdownRenderer.SetPlain(plain)
+	// Cached renders do not depend on the mode, so messages on screen re-render.
+	m.invalidateRenderCache()
+	m.refreshChat()
+	if plain {
+		return "Markdown rendering off; messages are shown as plain text. Run /plain again to turn it back on."
 	}
-	return rendered
+	return "Markdown rendering on."
 }
 
 // border defines a simple ASCII border to avoid Unicode dependencies.
@@ -2487,16 +3638,20 @@ func (m *tuiModel) border() lipgloss.Border {
 	}
 }
 
-// defaultTUITheme defines the baseline adaptive colors for the TUI.
+// defaultTUITheme defines the adaptive colors of the auto theme, which the
+// other themes start from.
 func defaultTUITheme() tuiTheme {
 	return tuiTheme{
-		Text:       lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
-		Secondary:  lipgloss.AdaptiveColor{Light: "#666666", Dark: "#999999"},
-		Bash:       lipgloss.AdaptiveColor{Light: "#ff0087", Dark: "#fd5db1"},
-		Error:      lipgloss.AdaptiveColor{Light: "#ab2b3f", Dark: "#ff6b80"},
-		Success:    lipgloss.AdaptiveColor{Light: "#2c7a39", Dark: "#4eba65"},
-		Warning:    lipgloss.AdaptiveColor{Light: "#966c1e", Dark: "#ffc107"},
-		Suggestion: lipgloss.AdaptiveColor{Light: "#5769f7", Dark: "#b1b9f9"},
+		Text:            lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+		Secondary:       lipgloss.AdaptiveColor{Light: "#666666", Dark: "#999999"},
+		SecondaryBorder: lipgloss.AdaptiveColor{Light: "#999999", Dark: "#888888"},
+		Bash:            lipgloss.AdaptiveColor{Light: "#ff0087", Dark: "#fd5db1"},
+		Claude:          lipgloss.AdaptiveColor{Light: "#d77757", Dark: "#d77757"},
+		Permission:      lipgloss.AdaptiveColor{Light: "#5769f7", Dark: "#b1b9f9"},
+		Error:           lipgloss.AdaptiveColor{Light: "#ab2b3f", Dark: "#ff6b80"},
+		Success:         lipgloss.AdaptiveColor{Light: "#2c7a39", Dark: "#4eba65"},
+		Warning:         lipgloss.AdaptiveColor{Light: "#966c1e", Dark: "#ffc107"},
+		Suggestion:      lipgloss.AdaptiveColor{Light: "#5769f7", Dark: "#b1b9f9"},
 	}
 }
 
@@ -2589,6 +3744,7 @@ func stripBashPrefix(inputValue string) (string, bool) {
 // clearInputHints resets transient hints under the input box.
 func (m *tuiModel) clearInputHints() {
 	m.inputHint = ""
+	m.pathPaste = nil
 	m.doublePress = tuiDoublePress{}
 }
 
@@ -2618,6 +3774,15 @@ func (m *tuiModel) handleHistoryArrows(key tea.KeyMsg) bool {
 // shouldInsertContinuationNewline inserts a newline when a trailing continuation is detected.
 func (m *tuiModel) shouldInsertContinuationNewline() bool {
 	inputValue := m.input.Value()
+	if m.inputMode == tuiInputBash {
+		// Bash needs its continuations verbatim, so the backslash stays.
+		if !bashCommandIncomplete(inputValue) {
+			return false
+		}
+		m.input.InsertString("\n")
+		m.syncInputState()
+		return true
+	}
 	trimmedValue := strings.TrimRight(inputValue, " \t")
 	if strings.HasSuffix(trimmedValue, "\\") {
 		adjusted := strings.TrimSuffix(trimmedValue, "\\")
@@ -2697,6 +3862,14 @@ func (m *tuiModel) applySlashSuggestion() {
 		return
 	}
 	selected := m.slashSuggestions[m.slashSelection]
+	if selected.Mention {
+		m.applyMentionSuggestion(selected)
+		return
+	}
+	if selected.Completion {
+		m.applyCompletion(selected.Name)
+		return
+	}
 	commandText := "/" + selected.Name
 	commandText += " "
 	m.input.SetValue(commandText)
@@ -2705,6 +3878,71 @@ func (m *tuiModel) applySlashSuggestion() {
 	m.syncInputState()
 }
 
+// applyMentionSuggestion replaces the trailing @token with the selected path.
+// Directories keep the completion open so the next level can be chosen.
+func (m *tuiModel) applyMentionSuggestion(selected tuiSlashSuggestion) {
+	value := m.input.Value()
+	start := strings.LastIndexAny(value, " \t\n") + 1
+	value = value[:start] + "@" + selected.Name
+	if !strings.HasSuffix(selected.Name, "/") {
+		value += " "
+	}
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.clearSlashSuggestions()
+	m.syncInputState()
+}
+
+// completeInputToken completes the trailing input token from the filesystem or tool names.
+// A single match is inserted directly; several matches extend the token to their
+// common prefix and open the suggestion list. It reports false when nothing matches.
+func (m *tuiModel) completeInputToken() bool {
+	token := trailingInputToken(m.input.Value())
+	if token == "" || strings.HasPrefix(token, "@") {
+		return false
+	}
+	var sandbox *tools.Sandbox
+	var toolNames []string
+	if m.runner != nil {
+		sandbox = m.runner.ToolContext.Sandbox
+	}
+	if m.runner != nil && m.runner.ToolRunner != nil {
+		for name := range m.runner.ToolRunner.Tools {
+			toolNames = append(toolNames, name)
+		}
+	}
+	candidates := tabCompletionCandidates(token, m.promptCWD(), sandbox, toolNames)
+	switch len(candidates) {
+	case 0:
+		return false
+	case 1:
+		m.applyCompletion(candidates[0].Name)
+		return true
+	}
+	if prefix := commonCompletionPrefix(candidates); len(prefix) > len(token) {
+		value := m.input.Value()
+		m.input.SetValue(value[:len(value)-len(token)] + prefix)
+		m.input.CursorEnd()
+	}
+	m.slashSuggestions = candidates
+	m.slashSelection = 0
+	return true
+}
+
+// applyCompletion replaces the trailing input token with a completion.
+// Directories keep the cursor on the path so Tab can descend another level.
+func (m *tuiModel) applyCompletion(completion string) {
+	value := m.input.Value()
+	value = value[:len(value)-len(trailingInputToken(value))] + completion
+	if !strings.HasSuffix(completion, "/") {
+		value += " "
+	}
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.clearSlashSuggestions()
+	m.syncInputState()
+}
+
 // selectedSlashSuggestion returns the currently highlighted suggestion.
 func (m *tuiModel) selectedSlashSuggestion() *tuiSlashSuggestion {
 	if m.slashSelection < 0 || m.slashSelection >= len(m.slashSuggestions) {
@@ -2721,11 +3959,20 @@ func (m *tuiModel) clearSlashSuggestions() {
 
 // updateSlashSuggestions refreshes suggestions based on the current input value.
 func (m *tuiModel) updateSlashSuggestions(inputValue string) {
-	if m.opts != nil && m.opts.DisableSlashCommands {
+	if m.inputMode != tuiInputPrompt {
 		m.clearSlashSuggestions()
 		return
 	}
-	if m.inputMode != tuiInputPrompt {
+	// A trailing @token completes file paths, even when slash commands are disabled.
+	if query, ok := trailingMentionQuery(inputValue); ok {
+		m.slashSuggestions = fileMentionSuggestions(m.promptCWD(), query, m.ignorePatterns())
+		m.slashSelection = 0
+		if len(m.slashSuggestions) == 0 {
+			m.clearSlashSuggestions()
+		}
+		return
+	}
+	if m.opts != nil && m.opts.DisableSlashCommands {
 		m.clearSlashSuggestions()
 		return
 	}
@@ -2739,7 +3986,7 @@ func (m *tuiModel) updateSlashSuggestions(inputValue string) {
 		return
 	}
 
-	allSuggestions := buildSlashSuggestions()
+	allSuggestions := buildSlashSuggestions(m.opts)
 	filtered := filterSlashSuggestions(allSuggestions, query)
 	if len(filtered) == 0 {
 		m.clearSlashSuggestions()
@@ -2778,32 +4025,15 @@ func parseSlashInput(inputValue string) (string, bool) {
 }
 
 // buildSlashSuggestions constructs the full list of available suggestions.
-func buildSlashSuggestions() []tuiSlashSuggestion {
-	descriptions := map[string]string{
-		"keybindings-help": "Show keybindings.",
-		"compact":          "Compact the conversation.",
-		"context":          "Manage context.",
-		"cost":             "Show token usage and cost.",
-		"init":             "Initialize session setup.",
-		"pr-comments":      "Review pull request comments.",
-		"release-notes":    "Show release notes.",
-		"review":           "Review changes.",
-		"security-review":  "Run a security review.",
-	}
-	acceptsArgs := map[string]bool{
-		"context":         true,
-		"pr-comments":     true,
-		"review":          true,
-		"security-review": true,
-	}
-	commands := defaultSlashCommandList()
-	suggestions := make([]tuiSlashSuggestion, 0, len(commands))
-	for _, commandName := range commands {
+func buildSlashSuggestions(opts *options) []tuiSlashSuggestion {
+	catalog := slashCommandCatalog(opts)
+	suggestions := make([]tuiSlashSuggestion, 0, len(catalog))
+	for _, info := range catalog {
 		suggestions = append(suggestions, tuiSlashSuggestion{
-			Name:        commandName,
-			Description: descriptions[commandName],
+			Name:        info.Name,
+			Description: info.Description,
 			Aliases:     nil,
-			AcceptsArgs: acceptsArgs[commandName],
+			AcceptsArgs: info.AcceptsArgs,
 		})
 	}
 	return suggestions
@@ -2850,6 +4080,9 @@ func (m *tuiModel) renderInputFooter() string {
 	if m.inputHint != "" {
 		return m.renderInputHintLine(m.inputHint)
 	}
+	if m.resend != nil {
+		return m.renderInputHintLine(resendHint)
+	}
 	return m.renderDefaultHintLine()
 }
 
@@ -2866,7 +4099,7 @@ func (m *tuiModel) renderSlashSuggestionLines() []string {
 	commandWidth := 0
 	for index := 0; index < suggestionCount; index++ {
 		suggestion := m.slashSuggestions[index]
-		commandText := "/" + suggestion.Name
+		commandText := suggestionLabel(suggestion)
 		if len(suggestion.Aliases) > 0 {
 			commandText += fmt.Sprintf(" (%s)", strings.Join(suggestion.Aliases, ", "))
 		}
@@ -2881,11 +4114,11 @@ func (m *tuiModel) renderSlashSuggestionLines() []string {
 	secondaryStyle := lipgloss.NewStyle().Foreground(m.theme.Secondary)
 	for suggestionIndex := 0; suggestionIndex < suggestionCount; suggestionIndex++ {
 		suggestion := m.slashSuggestions[suggestionIndex]
-		commandText := "/" + suggestion.Name
+		commandText := suggestionLabel(suggestion)
 		if len(suggestion.Aliases) > 0 {
 			commandText += fmt.Sprintf(" (%s)", strings.Join(suggestion.Aliases, ", "))
 		}
-		if suggestion.AcceptsArgs {
+		if suggestion.AcceptsArgs && !suggestion.Mention {
 			commandText += " …"
 		}
 		lineText := commandText
@@ -2901,6 +4134,17 @@ func (m *tuiModel) renderSlashSuggestionLines() []string {
 	return lines
 }
 
+// suggestionLabel renders a suggestion with its trigger character.
+func suggestionLabel(suggestion tuiSlashSuggestion) string {
+	if suggestion.Mention {
+		return "@" + suggestion.Name
+	}
+	if suggestion.Completion {
+		return suggestion.Name
+	}
+	return "/" + suggestion.Name
+}
+
 // renderInputHintLine formats the transient input hint text.
 func (m *tuiModel) renderInputHintLine(hint string) string {
 	style := lipgloss.NewStyle().Foreground(m.theme.Secondary)
@@ -2965,96 +4209,73 @@ func (m *tuiModel) resolvePastedInput(inputValue string) string {
 	return resolved
 }
 
-// handlePasteKey buffers large paste payloads and schedules finalization.
-func (m *tuiModel) handlePasteKey(key tea.KeyMsg, previousValue string) tea.Cmd {
-	if !isPasteKey(key) {
-		return nil
+// attachPastedPaths replaces a paste of existing file paths with @-mentions.
+// The hint offers Esc to keep the raw text; any other edit accepts the mentions.
+func (m *tuiModel) attachPastedPaths(key tea.KeyMsg, previousValue string) bool {
+	if !isPasteKey(key) || m.inputMode != tuiInputPrompt {
+		return false
 	}
-	pasteChunk := string(key.Runes)
-	if pasteChunk == "" {
-		return nil
+	prefix, inserted, suffix := diffInsertedSegment(previousValue, m.input.Value())
+	mentions, count, ok := pastedFileMentions(inserted, m.promptCWD())
+	if !ok {
+		return false
 	}
-	if !m.pasteBuffer.Active && !shouldBufferPasteChunk(pasteChunk) {
-		return nil
+	if prefix != "" && !strings.HasSuffix(prefix, " ") && !strings.HasSuffix(prefix, "\n") {
+		mentions = " " + mentions
 	}
-	if !m.pasteBuffer.Active {
-		m.pasteBuffer = tuiPasteBuffer{
-			Chunks:    nil,
-			BaseValue: previousValue,
-			Active:    true,
-			Last:      time.Now(),
-		}
+	m.input.SetValue(prefix + mentions + suffix)
+	m.input.CursorEnd()
+	m.syncInputState()
+	m.pathPaste = &tuiPathPaste{Mentions: mentions, Raw: inserted}
+	m.inputHint = fmt.Sprintf("Attached %d pasted path(s) as @-mentions · esc to paste as text", count)
+	return true
+}
+
+// revertPathPaste swaps attached @-mentions back to the raw pasted paths.
+func (m *tuiModel) revertPathPaste() {
+	paste := m.pathPaste
+	m.pathPaste = nil
+	m.inputHint = ""
+	value := m.input.Value()
+	if index := strings.LastIndex(value, paste.Mentions); index >= 0 {
+		m.input.SetValue(value[:index] + paste.Raw + value[index+len(paste.Mentions):])
+		m.input.CursorEnd()
 	}
-	m.pasteBuffer.Chunks = append(m.pasteBuffer.Chunks, pasteChunk)
-	m.pasteBuffer.Last = time.Now()
-	return m.schedulePasteFinalize()
+	m.syncInputState()
 }
 
-// isPasteKey identifies key events that represent pasted text.
+// isPasteKey identifies key events that carry a bracketed paste. Bubble Tea
+// enables bracketed paste mode, so a paste arrives as one message however
+// long it is.
 func isPasteKey(key tea.KeyMsg) bool {
-	if key.Paste {
-		return true
-	}
-	if key.Type != tea.KeyRunes {
-		return false
-	}
-	return len(key.Runes) > 1
+	return key.Paste
 }
 
-// shouldBufferPasteChunk decides whether the chunk is large enough to buffer.
-func shouldBufferPasteChunk(chunk string) bool {
-	if strings.Count(chunk, "\n")+1 >= tuiPasteLineThreshold {
+// isLargePaste decides whether a paste is long enough for a placeholder.
+func isLargePaste(pasted string) bool {
+	if strings.Count(pasted, "\n")+1 >= tuiPasteLineThreshold {
 		return true
 	}
-	return len([]rune(chunk)) >= tuiPasteRuneThreshold
-}
-
-// schedulePasteFinalize emits a delayed message to finalize paste buffering.
-func (m *tuiModel) schedulePasteFinalize() tea.Cmd {
-	return tea.Tick(tuiPasteIdleDelay, func(time.Time) tea.Msg {
-		return pasteDoneMsg{}
-	})
+	return len([]rune(pasted)) >= tuiPasteRuneThreshold
 }
 
-// finalizePaste replaces a buffered paste with a placeholder for safer rendering.
-func (m *tuiModel) finalizePaste() tea.Cmd {
-	if !m.pasteBuffer.Active {
-		return nil
-	}
-	if time.Since(m.pasteBuffer.Last) < tuiPasteIdleDelay {
-		return m.schedulePasteFinalize()
-	}
-
-	bufferedContent := strings.Join(m.pasteBuffer.Chunks, "")
-	baseValue := m.pasteBuffer.BaseValue
-	m.pasteBuffer = tuiPasteBuffer{}
-
-	if bufferedContent == "" {
-		return nil
-	}
-
-	currentValue := m.input.Value()
-	prefix, inserted, suffix := diffInsertedSegment(baseValue, currentValue)
-	if inserted == "" {
-		inserted = currentValue
-		prefix = ""
-		suffix = ""
+// placeholdLargePaste replaces a large bracketed paste with a placeholder for
+// safer rendering; the full text is substituted on submit.
+func (m *tuiModel) placeholdLargePaste(key tea.KeyMsg, previousValue string) {
+	if !isPasteKey(key) || !isLargePaste(string(key.Runes)) {
+		return
 	}
+	prefix, inserted, suffix := diffInsertedSegment(previousValue, m.input.Value())
 	if inserted == "" {
-		return nil
+		return
 	}
-
-	placeholder := buildPastePlaceholder(inserted)
 	m.pendingPaste = &tuiPendingPaste{
-		Placeholder: placeholder,
+		Placeholder: buildPastePlaceholder(inserted),
 		Content:     inserted,
 	}
-	replacedValue := prefix + placeholder + suffix
-	m.input.SetValue(replacedValue)
+	m.input.SetValue(prefix + m.pendingPaste.Placeholder + suffix)
 	m.input.CursorEnd()
 	m.inputHint = fmt.Sprintf("Pasted %d lines. Submit to include full text.", countLines(inserted))
-	m.syncInputState()
-	return nil
 }
 
 // diffInsertedSegment derives the changed segment between a base and updated value.
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestRenderCachedMessageReusesBlocks verifies unchanged messages skip re-rendering.
+func TestRenderCachedMessageReusesBlocks(testingHandle *testing.T) {
+	model := &tuiModel{width: 80, theme: defaultTUITheme()}
+	message := tuiMessage{Kind: tuiMessageUserPrompt, Role: "user", Content: "hello"}
+	model.chatMessages = []tuiMessage{message}
+
+	first := model.renderCachedMessage(0, message)
+	if first == "" {
+		testingHandle.Fatalf("expected rendered output")
+	}
+
+	// Replace the cached render with a sentinel to detect reuse.
+	model.renderCache[0].Rendered = "cached"
+	if got := model.renderCachedMessage(0, message); got != "cached" {
+		testingHandle.Fatalf("expected cached render, got %q", got)
+	}
+
+	// A width change must invalidate the entry.
+	model.width = 100
+	if got := model.renderCachedMessage(0, message); got == "cached" {
+		testingHandle.Fatalf("expected re-render after width change")
+	}
+
+	// A content change must invalidate the entry.
+	model.renderCache[0].Rendered = "cached"
+	changed := message
+	changed.Content = "updated"
+	if got := model.renderCachedMessage(0, changed); got == "cached" {
+		testingHandle.Fatalf("expected re-render after content change")
+	}
+}
+
+// TestRenderCachedMessageSkipsRunningTools verifies animated tool lines are never cached.
+func TestRenderCachedMessageSkipsRunningTools(testingHandle *testing.T) {
+	tests := []struct {
+		name   string
+		status tuiToolStatus
+		cached bool
+	}{
+		{name: "queued", status: tuiToolQueued, cached: false},
+		{name: "running", status: tuiToolRunning, cached: false},
+		{name: "completed", status: tuiToolCompleted, cached: true},
+		{name: "failed", status: tuiToolFailed, cached: true},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			model := &tuiModel{width: 80, theme: defaultTUITheme()}
+			message := tuiMessage{Kind: tuiMessageAssistantToolUse, ToolName: "Bash", ToolStatus: tt.status, ShowDot: true}
+			model.chatMessages = []tuiMessage{message}
+
+			model.renderCachedMessage(0, message)
+			if model.renderCache[0].Valid != tt.cached {
+				testingHandle.Fatalf("expected cached=%v, got %v", tt.cached, model.renderCache[0].Valid)
+			}
+		})
+	}
+}
+
+// TestSwitchModelResolvesAliases verifies /model lists the catalog and switches via aliases.
+func TestSwitchModelResolvesAliases(testingHandle *testing.T) {
+	providerCfg := &config.ProviderConfig{
+		DefaultModel: "big",
+		ModelAliases: map[string]string{"haiku": "small"},
+		Models:       map[string]config.ModelInfo{"small": {ContextWindow: 32000}},
+	}
+	model := &tuiModel{opts: &options{ProviderConfig: providerCfg}, model: "big", runner: &agent.Runner{}}
+
+	listing := model.switchModel("")
+	if !strings.Contains(listing, "* big") || !strings.Contains(listing, "small (haiku) - context 32000") {
+		testingHandle.Fatalf("unexpected listing: %s", listing)
+	}
+
+	output := model.switchModel("haiku")
+	if model.model != "small" || !strings.Contains(output, "Switched model to small") {
+		testingHandle.Fatalf("expected switch to small, got %q (%s)", model.model, output)
+	}
+
+	// Budgeted sessions refuse models without pricing.
+	model.runner.MaxBudgetUSD = 1
+	model.switchModel("big")
+	if model.model != "small" {
+		testingHandle.Fatalf("expected unpriced switch to be refused, got %s", model.model)
+	}
+}
+
+// TestThinkingBlocksFlushAndCollapse verifies streamed reasoning becomes a collapsible block.
+func TestThinkingBlocksFlushAndCollapse(testingHandle *testing.T) {
+	model := &tuiModel{width: 80, theme: defaultTUITheme(), running: true}
+
+	model.Update(streamThinkingMsg{Text: "step one\nstep two"})
+	if len(model.chatMessages) != 0 {
+		testingHandle.Fatalf("expected thinking to stay in the streaming tail")
+	}
+	model.Update(streamDeltaMsg{Text: "answer"})
+	if len(model.chatMessages) != 1 || model.chatMessages[0].Kind != tuiMessageAssistantThinking {
+		testingHandle.Fatalf("expected a flushed thinking block, got %+v", model.chatMessages)
+	}
+
+	collapsed := model.renderCachedMessage(0, model.chatMessages[0])
+	if strings.Contains(collapsed, "step one") || !strings.Contains(collapsed, "2 lines") {
+		testingHandle.Fatalf("expected collapsed thinking header, got %q", collapsed)
+	}
+	model.toggleThinking()
+	expanded := model.renderCachedMessage(0, model.chatMessages[0])
+	if !strings.Contains(expanded, "step one") {
+		testingHandle.Fatalf("expected expanded thinking body, got %q", expanded)
+	}
+}
+
+// TestVerboseToggleExpandsNewToolMessages verifies ctrl+o affects later tool messages and persists per session.
+func TestVerboseToggleExpandsNewToolMessages(testingHandle *testing.T) {
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", store)
+	model.width = 80
+	longOutput := strings.Repeat("line\n", tuiMaxRenderedLines+5)
+	call := agent.ToolEvent{Type: "tool_call", ToolName: "Bash", ToolID: "1", Arguments: json.RawMessage(`{"command":"make test","timeout":600000}`)}
+	result := agent.ToolEvent{Type: "tool_result", ToolName: "Bash", ToolID: "1", Result: longOutput}
+	model.appendToolUseMessage(call, tuiToolRunning)
+	model.appendToolResultMessage(result)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	call.ToolID, result.ToolID = "2", "2"
+	model.appendToolUseMessage(call, tuiToolRunning)
+	model.appendToolResultMessage(result)
+
+	if model.chatMessages[0].Expanded || !model.chatMessages[2].Expanded {
+		testingHandle.Fatalf("expected only messages after the toggle to be expanded, got %+v", model.chatMessages)
+	}
+	if condensed := model.renderCachedMessage(1, model.chatMessages[1]); strings.Count(condensed, "line") > tuiMaxRenderedLines+1 {
+		testingHandle.Fatalf("expected condensed output to be truncated")
+	}
+	if verbose := model.renderCachedMessage(3, model.chatMessages[3]); strings.Count(verbose, "line") != tuiMaxRenderedLines+5 {
+		testingHandle.Fatalf("expected verbose output in full, got %d lines", strings.Count(verbose, "line"))
+	}
+	if args := model.renderCachedMessage(2, model.chatMessages[2]); !strings.Contains(args, `"timeout": 600000`) {
+		testingHandle.Fatalf("expected pretty-printed tool input, got %q", args)
+	}
+
+	resumed := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", store)
+	if !resumed.verbose {
+		testingHandle.Fatalf("expected verbose mode to be restored with the session")
+	}
+}
+
+// TestToolProgressRendersBarUntilResult verifies progress shows on running tool lines only.
+func TestToolProgressRendersBarUntilResult(testingHandle *testing.T) {
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	model.width = 80
+	model.appendToolUseMessage(agent.ToolEvent{Type: "tool_call", ToolName: "WebFetch", ToolID: "1"}, tuiToolRunning)
+
+	model.Update(toolProgressMsg{ToolID: "1", Progress: tools.Progress{Current: 500_000, Total: 2_000_000, Unit: "bytes"}})
+	running := model.renderCachedMessage(0, model.chatMessages[0])
+	if !strings.Contains(running, "█████░░░░░░░░░░░░░░░  25% · 500.0 KB of 2.0 MB") {
+		testingHandle.Fatalf("expected a progress bar, got %q", running)
+	}
+	model.Update(toolProgressMsg{ToolID: "unknown", Progress: tools.Progress{Current: 1, Unit: "files"}})
+
+	model.appendToolResultMessage(agent.ToolEvent{Type: "tool_result", ToolName: "WebFetch", ToolID: "1", Result: "ok"})
+	if done := model.renderCachedMessage(0, model.chatMessages[0]); strings.Contains(done, "%") {
+		testingHandle.Fatalf("expected progress to clear on completion, got %q", done)
+	}
+	if text := formatToolProgressBar(tools.Progress{Current: 340, Unit: "files"}); text != "340 files" {
+		testingHandle.Fatalf("expected a count without a total, got %q", text)
+	}
+}
+
+// TestToolOutputShowsLatestLinesUntilResult verifies live output keeps the last
+// lines on the running tool's line and clears once the result arrives.
+func TestToolOutputShowsLatestLinesUntilResult(testingHandle *testing.T) {
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	model.width = 40
+	model.appendToolUseMessage(agent.ToolEvent{Type: "tool_call", ToolName: "Bash", ToolID: "1"}, tuiToolRunning)
+
+	model.Update(toolOutputMsg{ToolID: "1", Text: "line 1\nline 2\nline 3\n"})
+	model.Update(toolOutputMsg{ToolID: "1", Text: "line 4\nline 5\nline 6\n" + strings.Repeat("x", 60) + "\n"})
+	running := model.renderCachedMessage(0, model.chatMessages[0])
+	if strings.Contains(running, "line 2") || !strings.Contains(running, "line 3") || !strings.Contains(running, strings.Repeat("x", 33)+"…") {
+		testingHandle.Fatalf("expected the last %d clipped lines, got %q", tuiLiveOutputLines, running)
+	}
+
+	model.appendToolResultMessage(agent.ToolEvent{Type: "tool_result", ToolName: "Bash", ToolID: "1", Result: "done"})
+	if done := model.renderCachedMessage(0, model.chatMessages[0]); strings.Contains(done, "line") {
+		testingHandle.Fatalf("expected live output to clear on completion, got %q", done)
+	}
+}
+
+// TestToolResultRendersStderrApart verifies command stderr is rendered after
+// stdout on its own line, with the exit code of a failed command.
+func TestToolResultRendersStderrApart(testingHandle *testing.T) {
+	// Arrange
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	exitCode := 3
+	model.appendToolResultMessage(agent.ToolEvent{Type: "tool_result", ToolName: "Bash", ToolID: "1", Result: "command failed: exit status 3\nout\nerr", IsError: true, Stdout: "out\n", Stderr: "err\n", ExitCode: &exitCode})
+
+	// Act
+	rendered := model.renderToolResultMessage(model.chatMessages[0])
+
+	// Assert
+	lines := strings.Split(rendered, "\n")
+	if len(lines) != 4 {
+		testingHandle.Fatalf("expected stdout, stderr, and exit code lines, got %q", rendered)
+	}
+	if !strings.Contains(lines[1], "out") || strings.Contains(lines[1], "err") {
+		testingHandle.Fatalf("expected stdout alone on its line, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "⎿") || !strings.Contains(lines[2], "err") {
+		testingHandle.Fatalf("expected a separate stderr block, got %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "exit code 3") {
+		testingHandle.Fatalf("expected the exit code, got %q", lines[3])
+	}
+}
+
+// TestFormatStatusMetrics verifies the status line shows latency, time to first token, and throughput.
+func TestFormatStatusMetrics(testingHandle *testing.T) {
+	tests := []struct {
+		name    string
+		metrics agent.RequestMetrics
+		want    string
+	}{
+		{name: "waiting", metrics: agent.RequestMetrics{Latency: 800 * time.Millisecond, Estimated: true}, want: "api:800ms"},
+		{name: "streaming", metrics: agent.RequestMetrics{Latency: 3 * time.Second, TimeToFirstToken: time.Second, OutputTokens: 90, Estimated: true}, want: "api:3.0s ttft:1.0s ~45tok/s"},
+		{name: "finished", metrics: agent.RequestMetrics{Latency: 1500 * time.Millisecond, OutputTokens: 60}, want: "api:1.5s 40tok/s"},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			if got := formatStatusMetrics(tt.metrics); got != tt.want {
+				testingHandle.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
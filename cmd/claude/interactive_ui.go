@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -11,6 +12,8 @@ import (
 	"strings"
 
 	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
 	"github.com/openclaude/openclaude/internal/llm/openai"
 )
 
@@ -107,7 +110,7 @@ func (p *interactiveStreamPrinter) OnToolCall(event agent.ToolEvent) error {
 }
 
 // OnToolResult prints tool completion status and optional output summaries.
-func (p *interactiveStreamPrinter) OnToolResult(event agent.ToolEvent, _ openai.Message) error {
+func (p *interactiveStreamPrinter) OnToolResult(event agent.ToolEvent, _ llm.Message) error {
 	if event.ToolName == "" {
 		return nil
 	}
@@ -138,40 +141,129 @@ func buildInteractiveCallbacks(printer *interactiveStreamPrinter) *agent.StreamC
 	}
 }
 
-// handleSlashCommand routes known slash commands to stub handlers.
+// handleSlashCommand runs registry slash commands that need no TUI state.
 func handleSlashCommand(line string, opts *options) (bool, string) {
 	if opts != nil && opts.DisableSlashCommands {
 		return false, ""
 	}
+	command, args, ok := parseSlashCommand(line)
+	if !ok {
+		return false, ""
+	}
+	spec, known := lookupCommand(command, commandModeTUI)
+	if !known {
+		for _, info := range discoverMarkdownCommands(opts) {
+			if info.Name == command {
+				return true, fmt.Sprintf("%s /%s (%s) is not supported in OpenClaude yet.", strings.TrimSuffix(info.Category, "s"), command, info.Source)
+			}
+		}
+		return true, fmt.Sprintf("Unknown command: /%s. Type /help to list commands.", command)
+	}
+	switch {
+	case spec.Run != nil:
+		return true, spec.Run(opts, args)
+	case spec.TUI != nil, spec.Prompt != nil:
+		return true, fmt.Sprintf("Command /%s is only available in the interactive UI.", command)
+	}
+	return true, fmt.Sprintf("Command /%s is not implemented in OpenClaude yet. See docs/compat.md.", command)
+}
+
+// parseSlashCommand splits "/name args" into a lowercase command name and raw arguments.
+func parseSlashCommand(line string) (string, string, bool) {
 	trimmed := strings.TrimSpace(line)
 	if !strings.HasPrefix(trimmed, "/") {
-		return false, ""
+		return "", "", false
 	}
 	trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "/"))
 	if trimmed == "" {
-		return false, ""
+		return "", "", false
 	}
-	parts := strings.Fields(trimmed)
-	if len(parts) == 0 {
-		return false, ""
+	name, args, _ := strings.Cut(trimmed, " ")
+	return strings.ToLower(name), strings.TrimSpace(args), true
+}
+
+// formatModelList renders the provider model catalog for /model.
+func formatModelList(cfg *config.ProviderConfig, current string) string {
+	catalog := config.ModelCatalog(cfg)
+	if len(catalog) == 0 {
+		return fmt.Sprintf("Current model: %s\nNo models configured. Add default_model, model_aliases, or models to %s.", current, mustProviderPath())
 	}
-	command := strings.ToLower(parts[0])
-	if !isKnownSlashCommand(command) {
-		return true, fmt.Sprintf("Unknown command: /%s", command)
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Current model: %s\nAvailable models:", current)
+	for _, entry := range catalog {
+		marker := " "
+		if entry.Name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(&builder, "\n %s %s", marker, entry.Name)
+		if len(entry.Aliases) > 0 {
+			fmt.Fprintf(&builder, " (%s)", strings.Join(entry.Aliases, ", "))
+		}
+		var details []string
+		if entry.Default {
+			details = append(details, "default")
+		}
+		if entry.Info.ContextWindow > 0 {
+			details = append(details, fmt.Sprintf("context %d", entry.Info.ContextWindow))
+		}
+		if entry.Info.MaxOutputTokens > 0 {
+			details = append(details, fmt.Sprintf("max output %d", entry.Info.MaxOutputTokens))
+		}
+		if entry.Info.Pricing != nil {
+			details = append(details, fmt.Sprintf("$%g/$%g per 1M in/out", entry.Info.Pricing.InputPer1M, entry.Info.Pricing.OutputPer1M))
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(&builder, " - %s", strings.Join(details, ", "))
+		}
+		if entry.Info.Description != "" {
+			fmt.Fprintf(&builder, "\n     %s", entry.Info.Description)
+		}
 	}
-	return true, fmt.Sprintf("Command /%s is not implemented in OpenClaude yet. See docs/compat.md.", command)
+	builder.WriteString("\nUse /model <name or alias> to switch.")
+	return builder.String()
 }
 
-// isKnownSlashCommand checks against the canonical Claude Code list.
-func isKnownSlashCommand(command string) bool {
-	for _, known := range defaultSlashCommandList() {
-		if strings.EqualFold(command, known) {
+// isCatalogModel reports whether a model id appears in the provider catalog.
+func isCatalogModel(cfg *config.ProviderConfig, model string) bool {
+	for _, entry := range config.ModelCatalog(cfg) {
+		if entry.Name == model {
 			return true
 		}
 	}
 	return false
 }
 
+// formatAgentList renders built-in and .claude/agents subagents for /agents.
+func formatAgentList(opts *options) string {
+	var builder strings.Builder
+	builder.WriteString("Built-in agents:\n")
+	for _, name := range defaultAgentList() {
+		fmt.Fprintf(&builder, "  %s\n", name)
+	}
+	var definitions []config.AgentDefinition
+	if opts != nil {
+		definitions = opts.AgentDefinitions
+	}
+	if len(definitions) == 0 {
+		builder.WriteString("No custom agents found. Add Markdown files to .claude/agents/ or ~/.claude/agents/.")
+		return builder.String()
+	}
+	builder.WriteString("Custom agents:")
+	for _, definition := range definitions {
+		fmt.Fprintf(&builder, "\n  %s (%s)", definition.Name, definition.Source)
+		if definition.Description != "" {
+			fmt.Fprintf(&builder, " - %s", definition.Description)
+		}
+		if definition.Model != "" {
+			fmt.Fprintf(&builder, " [model: %s]", definition.Model)
+		}
+		if len(definition.Tools) > 0 {
+			fmt.Fprintf(&builder, " [tools: %s]", strings.Join(definition.Tools, ", "))
+		}
+	}
+	return builder.String()
+}
+
 // summarizeToolArgs formats tool arguments for prompt display.
 func summarizeToolArgs(args json.RawMessage, max int) string {
 	if len(args) == 0 {
@@ -185,6 +277,19 @@ func summarizeToolArgs(args json.RawMessage, max int) string {
 	return truncateForDisplay(compact, max)
 }
 
+// formatVerboseToolArgs pretty-prints tool arguments in full for verbose mode.
+func formatVerboseToolArgs(args json.RawMessage) string {
+	trimmed := strings.TrimSpace(string(args))
+	if trimmed == "" {
+		return ""
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(trimmed), "", "  "); err != nil {
+		return trimmed
+	}
+	return indented.String()
+}
+
 // summarizeToolOutput formats tool output for optional display.
 func summarizeToolOutput(output string, max int) string {
 	trimmed := strings.TrimSpace(output)
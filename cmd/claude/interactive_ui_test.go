@@ -1,6 +1,11 @@
 package main
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/config"
+)
 
 // TestHandleSlashCommandKnown verifies known slash commands are handled.
 func TestHandleSlashCommandKnown(testingHandle *testing.T) {
@@ -53,3 +58,20 @@ func TestHandleSlashCommandNonSlash(testingHandle *testing.T) {
 		testingHandle.Fatalf("expected no output for non-slash input")
 	}
 }
+
+// TestHandleSlashCommandAgents verifies /agents lists built-in and custom agents.
+func TestHandleSlashCommandAgents(testingHandle *testing.T) {
+	opts := &options{AgentDefinitions: []config.AgentDefinition{
+		{Name: "reviewer", Description: "Reviews diffs", Source: "project", Tools: []string{"Read", "Grep"}},
+	}}
+
+	handled, output := handleSlashCommand("/agents", opts)
+	if !handled {
+		testingHandle.Fatalf("expected /agents to be handled")
+	}
+	for _, want := range []string{"general-purpose", "reviewer (project) - Reviews diffs", "[tools: Read, Grep]"} {
+		if !strings.Contains(output, want) {
+			testingHandle.Fatalf("expected %q in output: %s", want, output)
+		}
+	}
+}
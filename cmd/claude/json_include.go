@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/errcode"
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// --json-include sections added to --output-format=json results.
+const (
+	jsonIncludeTools             = "tools"
+	jsonIncludeMessages          = "messages"
+	jsonIncludePermissionDenials = "permission_denials"
+	jsonIncludeAll               = "all"
+)
+
+// jsonIncludeSections lists the sections "all" expands to, in output order.
+var jsonIncludeSections = []string{jsonIncludeTools, jsonIncludeMessages, jsonIncludePermissionDenials}
+
+// confirmationRequiredError denies a tool call that needs a permission prompt
+// print mode cannot show.
+type confirmationRequiredError struct {
+	// ToolName is the tool that asked for confirmation.
+	ToolName string
+}
+
+// Error keeps the message print mode has always reported.
+func (e *confirmationRequiredError) Error() string {
+	return fmt.Sprintf("tool %s requires confirmation in print mode", e.ToolName)
+}
+
+// Unwrap places the refusal in the errcode.ToolDenied category.
+func (e *confirmationRequiredError) Unwrap() error {
+	return errcode.ErrToolDenied
+}
+
+// denyPrintModeTool is the print-mode tool authorizer: there is nobody to ask.
+func denyPrintModeTool(name string, _ json.RawMessage) (bool, error) {
+	return false, &confirmationRequiredError{ToolName: name}
+}
+
+// validateJSONInclude checks --json-include values and that json output is selected.
+func validateJSONInclude(opts *options) error {
+	if len(opts.JSONInclude) == 0 {
+		return nil
+	}
+	if opts.OutputFormat != "json" {
+		return fmt.Errorf("Error: --json-include requires --output-format=json.")
+	}
+	for _, value := range opts.JSONInclude {
+		switch strings.TrimSpace(value) {
+		case jsonIncludeTools, jsonIncludeMessages, jsonIncludePermissionDenials, jsonIncludeAll:
+		default:
+			return fmt.Errorf("Error: Invalid --json-include value %q. Use %s, or all.", value, strings.Join(jsonIncludeSections, ", "))
+		}
+	}
+	return nil
+}
+
+// jsonIncludeSet expands --json-include values into the sections to add.
+func jsonIncludeSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == jsonIncludeAll {
+			for _, section := range jsonIncludeSections {
+				set[section] = true
+			}
+			continue
+		}
+		set[value] = true
+	}
+	return set
+}
+
+// addJSONIncludes adds the requested sections to a json result payload: the
+// run's tool events, the messages this turn added to the conversation, and
+// the tool uses that were denied. result is nil when the run failed.
+func addJSONIncludes(payload map[string]any, include []string, result *agent.RunResult, historyLen int, runErr error) {
+	set := jsonIncludeSet(include)
+	if set[jsonIncludeTools] {
+		events := []agent.ToolEvent{}
+		if result != nil {
+			events = append(events, result.Events...)
+		}
+		payload["tool_events"] = events
+	}
+	if set[jsonIncludeMessages] {
+		messages := []llm.Message{}
+		if result != nil {
+			messages = turnMessages(result.Messages, historyLen)
+		}
+		payload["messages"] = messages
+	}
+	if set[jsonIncludePermissionDenials] {
+		payload["permission_denials"] = extractPermissionDenials(runErr)
+	}
+}
+
+// turnMessages returns the non-system messages added after the first historyLen.
+func turnMessages(messages []llm.Message, historyLen int) []llm.Message {
+	if historyLen > len(messages) {
+		historyLen = 0
+	}
+	added := []llm.Message{}
+	for _, message := range messages[historyLen:] {
+		if message.Role != "system" {
+			added = append(added, message)
+		}
+	}
+	return added
+}
+
+// writeJSONError reports a failed print-mode run as a json result, so scripts
+// that asked for --json-include still get the denials.
+func writeJSONError(opts *options, runErr error, sessionID string, model string) error {
+	payload := map[string]any{
+		"session_id": sessionID,
+		"model":      model,
+		"is_error":   true,
+		"error":      runErr.Error(),
+		"error_code": errcode.Of(runErr),
+	}
+	addJSONIncludes(payload, opts.JSONInclude, nil, 0, runErr)
+	return writeJSON(payload)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestValidateJSONInclude verifies --json-include needs json output and known sections.
+func TestValidateJSONInclude(testingHandle *testing.T) {
+	tests := []struct {
+		name        string
+		opts        options
+		expectError string
+	}{
+		{name: "unset", opts: options{OutputFormat: "text"}},
+		{name: "json sections", opts: options{OutputFormat: "json", JSONInclude: []string{"tools", "messages", "permission_denials"}}},
+		{name: "all", opts: options{OutputFormat: "json", JSONInclude: []string{"all"}}},
+		{name: "needs json", opts: options{OutputFormat: "stream-json", JSONInclude: []string{"tools"}}, expectError: "requires --output-format=json"},
+		{name: "unknown section", opts: options{OutputFormat: "json", JSONInclude: []string{"usage"}}, expectError: "Invalid --json-include value \"usage\""},
+	}
+	for _, test := range tests {
+		testingHandle.Run(test.name, func(testingHandle *testing.T) {
+			// Act
+			err := validateJSONInclude(&test.opts)
+
+			// Assert
+			if test.expectError == "" {
+				testutil.RequireNoError(testingHandle, err, "validate")
+				return
+			}
+			testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), test.expectError), "expected error containing "+test.expectError)
+		})
+	}
+}
+
+// TestAddJSONIncludes verifies the json result gains tool events, the turn's
+// messages without history or system prompt, and permission denials.
+func TestAddJSONIncludes(testingHandle *testing.T) {
+	// Arrange a resumed conversation with one new tool round.
+	result := &agent.RunResult{
+		Messages: []llm.Message{
+			{Role: "system", Content: "prompt"},
+			{Role: "user", Content: "earlier"},
+			{Role: "assistant", Content: "earlier reply"},
+			{Role: "user", Content: "list files"},
+			{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "call-1", Type: "function", Function: llm.ToolCallFunction{Name: "LS", Arguments: "{}"}}}},
+			{Role: "tool", ToolCallID: "call-1", Content: "a.go"},
+			{Role: "assistant", Content: "a.go"},
+		},
+		Events: []agent.ToolEvent{
+			{Type: "tool_call", ToolName: "LS", ToolID: "call-1"},
+			{Type: "tool_result", ToolName: "LS", ToolID: "call-1", Result: "a.go"},
+		},
+	}
+	payload := map[string]any{}
+
+	// Act
+	addJSONIncludes(payload, []string{"all"}, result, 3, nil)
+	encoded, err := json.Marshal(payload)
+	testutil.RequireNoError(testingHandle, err, "marshal payload")
+	var decoded struct {
+		ToolEvents        []map[string]any `json:"tool_events"`
+		Messages          []map[string]any `json:"messages"`
+		PermissionDenials []any            `json:"permission_denials"`
+	}
+	testutil.RequireNoError(testingHandle, json.Unmarshal(encoded, &decoded), "decode payload")
+
+	// Assert
+	testutil.RequireEqual(testingHandle, len(decoded.ToolEvents), 2, "tool events")
+	testutil.RequireEqual(testingHandle, decoded.ToolEvents[1]["type"], any("tool_result"), "result event")
+	testutil.RequireEqual(testingHandle, len(decoded.Messages), 4, "turn messages")
+	testutil.RequireEqual(testingHandle, decoded.Messages[0]["content"], any("list files"), "turn starts at the new prompt")
+	testutil.RequireTrue(testingHandle, decoded.PermissionDenials != nil && len(decoded.PermissionDenials) == 0, "empty denials on success")
+}
+
+// TestPrintModeConfirmationIsDenial verifies a tool print mode cannot confirm
+// is reported as a permission denial with the original message.
+func TestPrintModeConfirmationIsDenial(testingHandle *testing.T) {
+	// Act
+	allowed, err := denyPrintModeTool("Bash", nil)
+	denials := extractPermissionDenials(err)
+
+	// Assert
+	testutil.RequireTrue(testingHandle, !allowed, "tool denied")
+	testutil.RequireEqual(testingHandle, err.Error(), "tool Bash requires confirmation in print mode", "message")
+	testutil.RequireEqual(testingHandle, len(denials), 1, "one denial")
+	testutil.RequireEqual(testingHandle, denials[0], any(permissionDenial{ToolName: "Bash", Reason: "requires_confirmation"}), "denial")
+}
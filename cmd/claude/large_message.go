@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/config"
+)
+
+// largeMessageCharsPerToken estimates prompt tokens from characters, like the
+// agent's estimate for streamed output.
+const largeMessageCharsPerToken = 4
+
+// largeMessageExcerptLines and largeMessageExcerptChars bound the excerpt
+// sent in place of an attached message.
+const (
+	largeMessageExcerptLines = 12
+	largeMessageExcerptChars = 1500
+)
+
+// tuiLargeMessage is a prompt held back because it is over the size limit.
+type tuiLargeMessage struct {
+	// Tokens is the prompt's estimated size.
+	Tokens int
+	// Limit is the configured threshold it exceeded.
+	Limit int
+}
+
+// estimateTextTokens approximates the tokens text takes in a prompt.
+func estimateTextTokens(text string) int {
+	return (utf8.RuneCountInString(text) + largeMessageCharsPerToken - 1) / largeMessageCharsPerToken
+}
+
+// largeMessageLimit returns the large-message threshold, or 0 when disabled.
+func (m *tuiModel) largeMessageLimit() int {
+	limit := config.DefaultLargeMessageTokens
+	if m.opts != nil && m.opts.ProviderConfig != nil && m.opts.ProviderConfig.LargeMessageTokens != 0 {
+		limit = m.opts.ProviderConfig.LargeMessageTokens
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// holdLargeMessage stops a prompt over the size limit before it is sent and
+// asks what to do with it. A prompt the user chose to send anyway passes.
+func (m *tuiModel) holdLargeMessage(rawValue string) bool {
+	if m.largeMessageApproved {
+		m.largeMessageApproved = false
+		return false
+	}
+	limit := m.largeMessageLimit()
+	if limit == 0 || m.inputMode != tuiInputPrompt {
+		return false
+	}
+	value := strings.TrimSpace(m.previewPastedInput(rawValue))
+	if strings.HasPrefix(value, "/") {
+		return false
+	}
+	tokens := estimateTextTokens(value)
+	if tokens <= limit {
+		return false
+	}
+	m.largeMessage = &tuiLargeMessage{Tokens: tokens, Limit: limit}
+	m.statusText = m.largeMessagePrompt()
+	return true
+}
+
+// largeMessagePrompt describes the held prompt and the available choices.
+func (m *tuiModel) largeMessagePrompt() string {
+	size := fmt.Sprintf("~%d tokens", m.largeMessage.Tokens)
+	if m.opts != nil {
+		if info, ok := config.LookupModelInfo(m.opts.ProviderConfig, m.model); ok && info.ContextWindow > 0 {
+			size += fmt.Sprintf(", %d%% of the context window", m.largeMessage.Tokens*100/info.ContextWindow)
+		}
+	}
+	return fmt.Sprintf("Large message (%s; limit %d). [a]ttach as file · [s]end anyway · [esc] keep editing", size, m.largeMessage.Limit)
+}
+
+// handleLargeMessageKey answers the large-message prompt. Other keys dismiss
+// it and edit the input as usual.
+func (m *tuiModel) handleLargeMessageKey(key string) (bool, tea.Cmd) {
+	switch strings.ToLower(key) {
+	case "a":
+		m.largeMessage = nil
+		if err := m.attachLargeMessage(); err != nil {
+			m.statusText = "Attach failed: " + err.Error()
+			return true, nil
+		}
+		m.largeMessageApproved = true
+		_, cmd := m.submitInput()
+		return true, cmd
+	case "s", "y", "enter":
+		m.largeMessage = nil
+		m.largeMessageApproved = true
+		_, cmd := m.submitInput()
+		return true, cmd
+	case "esc", "n":
+		m.largeMessage = nil
+		m.statusText = ""
+		return true, nil
+	}
+	m.largeMessage = nil
+	m.statusText = ""
+	return false, nil
+}
+
+// attachLargeMessage saves the oversized text as a session artifact and
+// replaces it in the input with a pointer and an excerpt. When the size comes
+// from a paste, only the paste is attached and the typed text is kept.
+func (m *tuiModel) attachLargeMessage() error {
+	if m.store == nil || m.sessionID == "" {
+		return fmt.Errorf("no session store to save the file in")
+	}
+	rawValue := m.input.Value()
+	content := strings.TrimSpace(m.previewPastedInput(rawValue))
+	surrounding := ""
+	if m.pendingPaste != nil && strings.Contains(rawValue, m.pendingPaste.Placeholder) {
+		content = m.pendingPaste.Content
+		surrounding = rawValue
+	}
+	name := fmt.Sprintf("message-%s.txt", time.Now().UTC().Format("20060102T150405"))
+	artifact, err := m.store.WriteArtifact(m.sessionID, name, []byte(content))
+	if err != nil {
+		return err
+	}
+	note := largeMessageNote(artifact.Path, content)
+	value := note
+	if surrounding != "" {
+		value = strings.Replace(surrounding, m.pendingPaste.Placeholder, note, 1)
+	}
+	m.pendingPaste = nil
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return nil
+}
+
+// largeMessageNote is the text sent in place of an attached message: where it
+// is, how big it is, and how it starts.
+func largeMessageNote(path string, content string) string {
+	lines := strings.Split(content, "\n")
+	excerpt := lines
+	if len(excerpt) > largeMessageExcerptLines {
+		excerpt = excerpt[:largeMessageExcerptLines]
+	}
+	text := strings.Join(excerpt, "\n")
+	if runes := []rune(text); len(runes) > largeMessageExcerptChars {
+		text = string(runes[:largeMessageExcerptChars])
+	}
+	return fmt.Sprintf("[Large message attached as %s (%d lines, ~%d tokens). It begins:\n%s\n...\nRead the file for the full text.]",
+		path, len(lines), estimateTextTokens(content), text)
+}
+
+// previewPastedInput is resolvePastedInput without consuming the paste.
+func (m *tuiModel) previewPastedInput(inputValue string) string {
+	if m.pendingPaste == nil || !strings.Contains(inputValue, m.pendingPaste.Placeholder) {
+		return inputValue
+	}
+	return strings.Replace(inputValue, m.pendingPaste.Placeholder, m.pendingPaste.Content, 1)
+}
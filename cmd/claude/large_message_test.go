@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestLargeMessageIsHeldBeforeSending verifies a prompt over the limit waits
+// for a choice: keep editing, send it anyway, or attach it as a file.
+func TestLargeMessageIsHeldBeforeSending(testingHandle *testing.T) {
+	// Arrange.
+	testingHandle.Setenv("HOME", testingHandle.TempDir())
+	store, err := session.NewStore()
+	testutil.RequireNoError(testingHandle, err, "new store")
+	opts := &options{ProviderConfig: &config.ProviderConfig{LargeMessageTokens: 10}}
+	large := strings.Repeat("log line\n", 20) + "end"
+	newModel := func() *tuiModel {
+		model := newTUIModel(opts, nil, nil, sessionHistoryCursor{}, "", "model", "session", store)
+		model.input.SetValue(large)
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return model
+	}
+	lastContent := func(model *tuiModel) string {
+		return model.history[len(model.history)-1].Content.(string)
+	}
+
+	// Act.
+	edited := newModel()
+	held := edited.largeMessage != nil
+	edited.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	sent := newModel()
+	sent.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	attached := newModel()
+	attached.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+
+	// Assert.
+	testutil.RequireTrue(testingHandle, held, "prompt held")
+	testutil.RequireTrue(testingHandle, edited.largeMessage == nil, "prompt dismissed")
+	testutil.RequireEqual(testingHandle, len(edited.history), 0, "nothing sent")
+	testutil.RequireEqual(testingHandle, edited.input.Value(), large, "input kept for editing")
+	testutil.RequireEqual(testingHandle, sent.history, []llm.Message{{Role: "user", Content: large}}, "sent anyway")
+	note := lastContent(attached)
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(note, "[Large message attached as "+store.ArtifactsDir("session")), note)
+	testutil.RequireTrue(testingHandle, strings.Contains(note, "(21 lines, ~46 tokens). It begins:\nlog line\n"), note)
+	artifacts, err := store.ListArtifacts("session")
+	testutil.RequireNoError(testingHandle, err, "list artifacts")
+	testutil.RequireEqual(testingHandle, len(artifacts), 1, "one artifact")
+	data, err := os.ReadFile(artifacts[0].Path)
+	testutil.RequireNoError(testingHandle, err, "read artifact")
+	testutil.RequireEqual(testingHandle, string(data), large, "artifact content")
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// ledgerCommand exposes the local cost ledger that records every API request.
+func ledgerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ledger",
+		Short: "Inspect the local cost ledger",
+		Long: "Every API request's model, token counts, and estimated cost are appended to ~/.openclaude/ledger.jsonl, across all sessions.\n" +
+			"Set \"disable_cost_ledger\": true in the provider config to stop recording.",
+	}
+	cmd.AddCommand(ledgerExportCommand())
+	return cmd
+}
+
+// ledgerExportCommand writes the ledger to stdout in an export format.
+func ledgerExportCommand() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the cost ledger",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "csv" {
+				return fmt.Errorf("unsupported ledger format %q (supported: csv)", format)
+			}
+			store, err := session.NewStore()
+			if err != nil {
+				return err
+			}
+			entries, err := telemetry.ReadLedger(filepath.Join(store.BaseDir, telemetry.LedgerFileName))
+			if err != nil {
+				return fmt.Errorf("read ledger: %w", err)
+			}
+			return telemetry.WriteLedgerCSV(cmd.OutOrStdout(), entries)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "csv", "Export format (csv)")
+	return cmd
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/telemetry"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestLedgerExportCommand verifies the ledger exports as CSV and other formats are rejected.
+func TestLedgerExportCommand(testingHandle *testing.T) {
+	testingHandle.Setenv("HOME", testingHandle.TempDir())
+	store, err := session.NewStore()
+	testutil.RequireNoError(testingHandle, err, "new store")
+	telemetry.NewLedger(filepath.Join(store.BaseDir, telemetry.LedgerFileName), "s1", nil).
+		RecordAPIRequest("small", llm.Usage{PromptTokens: 10, CompletionTokens: 2}, 0.5, time.Second)
+
+	cmd := ledgerCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"export", "--format", "csv"})
+	testutil.RequireNoError(testingHandle, cmd.Execute(), "export csv")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	testutil.RequireEqual(testingHandle, len(lines), 2, "header and one entry")
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(lines[1], ",s1,small,10,2,0,0,0.5,1000"), lines[1])
+
+	cmd = ledgerCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"export", "--format", "xml"})
+	testutil.RequireTrue(testingHandle, cmd.Execute() != nil, "unsupported format")
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// defaultHookTimeout bounds a settings hook command without its own timeout.
+const defaultHookTimeout = 60 * time.Second
+
+// hookBlockExitCode is the exit status a hook uses to block, as in Claude Code.
+const hookBlockExitCode = 2
+
+// lifecycleHooks runs the settings command hooks for Stop, SubagentStop, and
+// PreCompact. Hooks are read from the options on every event, so /reload
+// applies to the running session.
+type lifecycleHooks struct {
+	// opts holds the current settings.
+	opts *options
+	// sessionID and transcriptPath are passed to hooks on stdin.
+	sessionID      string
+	transcriptPath string
+	// cwd is the hook working directory and CLAUDE_PROJECT_DIR.
+	cwd string
+	// permissions are the session's; while they mark the workspace untrusted,
+	// hooks from project and local settings do not run. Nil trusts it.
+	permissions *tools.Permissions
+	// emitter reports hook lifecycle events in stream-json mode, or is nil.
+	emitter *streamJSONHookEmitter
+	// warn receives hook failures; nil drops them.
+	warn io.Writer
+}
+
+// hookOutcome is the result of one hook command.
+type hookOutcome struct {
+	// ExitCode is the command's exit status (-1 when it did not run or timed out).
+	ExitCode int
+	// Stdout and Stderr are the captured output.
+	Stdout string
+	Stderr string
+}
+
+// stopHook returns the agent Stop hook for event ("Stop" or "SubagentStop").
+// A hook blocks stopping by exiting with status 2, whose stderr is the reason,
+// or by printing {"decision": "block", "reason": "..."}.
+func (h *lifecycleHooks) stopHook(event string) func(ctx context.Context, active bool) string {
+	if h == nil {
+		return nil
+	}
+	return func(ctx context.Context, active bool) string {
+		input := h.input(event)
+		input["stop_hook_active"] = active
+		var reasons []string
+		for _, outcome := range h.run(ctx, event, "", input) {
+			if reason, block := stopDecision(outcome); block {
+				reasons = append(reasons, reason)
+			}
+		}
+		return strings.Join(reasons, "\n")
+	}
+}
+
+// preCompactHook returns the agent PreCompact hook. OpenClaude only compacts
+// automatically, by dropping old tool results on a context overflow, so the
+// trigger is always "auto".
+func (h *lifecycleHooks) preCompactHook() func(ctx context.Context) {
+	if h == nil {
+		return nil
+	}
+	return func(ctx context.Context) {
+		input := h.input("PreCompact")
+		input["trigger"] = "auto"
+		input["custom_instructions"] = ""
+		h.run(ctx, "PreCompact", "auto", input)
+	}
+}
+
+// input returns the fields common to every hook's stdin payload.
+func (h *lifecycleHooks) input(event string) map[string]any {
+	return map[string]any{
+		"session_id":      h.sessionID,
+		"transcript_path": h.transcriptPath,
+		"cwd":             h.cwd,
+		"hook_event_name": event,
+	}
+}
+
+// run executes every hook configured for event whose matcher accepts subject.
+func (h *lifecycleHooks) run(ctx context.Context, event string, subject string, input map[string]any) []hookOutcome {
+	if h.emitter != nil {
+		if err := h.emitter.emitHookEvents(event, subject, "success"); err != nil {
+			h.warnf("hook %s: %v", event, err)
+		}
+	}
+	var matchers []config.HookMatcher
+	if h.opts != nil && h.opts.ClaudeSettings != nil {
+		matchers = h.opts.ClaudeSettings.Hooks[event]
+	}
+	if len(matchers) == 0 {
+		return nil
+	}
+	untrusted := h.permissions != nil && h.permissions.Untrusted
+	payload, _ := json.Marshal(input)
+	var outcomes []hookOutcome
+	for _, matcher := range matchers {
+		if !hookMatcherMatches(matcher.Matcher, subject) {
+			continue
+		}
+		if untrusted && config.IsWorkspaceSource(matcher.Source) {
+			for _, hook := range matcher.Hooks {
+				h.warnf("%s hook %q from %s settings skipped: this folder is not trusted", event, hook.Command, matcher.Source)
+			}
+			continue
+		}
+		for _, hook := range matcher.Hooks {
+			outcome, err := runHookCommand(ctx, hook, payload, h.cwd)
+			if err != nil {
+				h.warnf("%s hook %q: %v", event, hook.Command, err)
+			} else if outcome.ExitCode != 0 && outcome.ExitCode != hookBlockExitCode {
+				h.warnf("%s hook %q exited with status %d: %s", event, hook.Command, outcome.ExitCode, strings.TrimSpace(outcome.Stderr))
+			}
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
+
+// warnf reports a hook failure; failures never stop the run.
+func (h *lifecycleHooks) warnf(format string, args ...any) {
+	if h.warn != nil {
+		fmt.Fprintf(h.warn, "Warning: "+format+"\n", args...)
+	}
+}
+
+// runHookCommand runs one hook with the event JSON on stdin. A non-zero exit
+// is reported in the outcome; err is set only when the command could not run
+// or timed out.
+func runHookCommand(ctx context.Context, hook config.HookCommand, payload []byte, cwd string) (hookOutcome, error) {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", hook.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	}
+	cmd.Dir = cwd
+	cmd.Env = append(os.Environ(), "CLAUDE_PROJECT_DIR="+cwd)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	outcome := hookOutcome{ExitCode: -1, Stdout: stdout.String(), Stderr: stderr.String()}
+	if ctx.Err() != nil {
+		return outcome, fmt.Errorf("timed out after %s", timeout)
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		outcome.ExitCode = 0
+	case errors.As(err, &exitErr):
+		outcome.ExitCode = exitErr.ExitCode()
+	default:
+		return outcome, err
+	}
+	return outcome, nil
+}
+
+// stopDecision reports whether a Stop hook blocked stopping, and why.
+func stopDecision(outcome hookOutcome) (string, bool) {
+	if outcome.ExitCode == hookBlockExitCode {
+		reason := strings.TrimSpace(outcome.Stderr)
+		if reason == "" {
+			reason = "A Stop hook asked to keep working."
+		}
+		return reason, true
+	}
+	if outcome.ExitCode != 0 {
+		return "", false
+	}
+	var decision struct {
+		Decision string `json:"decision"`
+		Reason   string `json:"reason"`
+	}
+	if json.Unmarshal([]byte(strings.TrimSpace(outcome.Stdout)), &decision) != nil || decision.Decision != "block" {
+		return "", false
+	}
+	if strings.TrimSpace(decision.Reason) == "" {
+		return "A Stop hook asked to keep working.", true
+	}
+	return strings.TrimSpace(decision.Reason), true
+}
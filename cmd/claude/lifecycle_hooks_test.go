@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestLifecycleHooksRunSettingsCommands verifies Stop hooks block with exit
+// status 2 or a JSON decision, receive the event on stdin, and PreCompact
+// hooks honor their matcher.
+func TestLifecycleHooksRunSettingsCommands(testingHandle *testing.T) {
+	if runtime.GOOS == "windows" {
+		testingHandle.Skip("hook commands use sh")
+	}
+	// Arrange
+	dir := testingHandle.TempDir()
+	command := func(text string) []config.HookCommand {
+		return []config.HookCommand{{Command: text}}
+	}
+	opts := &options{ClaudeSettings: &config.Settings{Hooks: map[string][]config.HookMatcher{
+		"SubagentStop": {
+			{Hooks: command("cat > stop-input.json; echo 'lint failed' >&2; exit 2")},
+			{Hooks: command(`echo '{"decision":"block","reason":"tests missing"}'`)},
+			{Hooks: command("echo ok")},
+		},
+		"PreCompact": {
+			{Matcher: "manual", Hooks: command("touch manual")},
+			{Matcher: "auto", Hooks: command("cat > compact-input.json")},
+		},
+	}}}
+	hooks := &lifecycleHooks{opts: opts, sessionID: "s1", transcriptPath: "/tmp/s1.jsonl", cwd: dir}
+
+	// Act
+	reason := hooks.stopHook("SubagentStop")(context.Background(), true)
+	hooks.preCompactHook()(context.Background())
+	noHooks := hooks.stopHook("Stop")(context.Background(), false)
+
+	// Assert
+	testutil.RequireEqual(testingHandle, reason, "lint failed\ntests missing", "block reasons")
+	testutil.RequireEqual(testingHandle, noHooks, "", "no Stop hooks configured")
+	var stopInput map[string]any
+	data, err := os.ReadFile(filepath.Join(dir, "stop-input.json"))
+	testutil.RequireNoError(testingHandle, err, "read stop input")
+	testutil.RequireNoError(testingHandle, json.Unmarshal(data, &stopInput), "decode stop input")
+	testutil.RequireEqual(testingHandle, stopInput["hook_event_name"], "SubagentStop", "event name")
+	testutil.RequireEqual(testingHandle, stopInput["stop_hook_active"], true, "stop_hook_active")
+	testutil.RequireEqual(testingHandle, stopInput["session_id"], "s1", "session id")
+	var compactInput map[string]any
+	data, err = os.ReadFile(filepath.Join(dir, "compact-input.json"))
+	testutil.RequireNoError(testingHandle, err, "read compact input")
+	testutil.RequireNoError(testingHandle, json.Unmarshal(data, &compactInput), "decode compact input")
+	testutil.RequireEqual(testingHandle, compactInput["trigger"], "auto", "trigger")
+	_, statErr := os.Stat(filepath.Join(dir, "manual"))
+	testutil.RequireTrue(testingHandle, os.IsNotExist(statErr), "manual matcher skipped")
+}
+
+// TestLifecycleHooksSkipWorkspaceHooksWhenUntrusted verifies an untrusted
+// project's Stop hook does not run, with a warning, while user hooks still
+// do, and that trusting the folder enables it.
+func TestLifecycleHooksSkipWorkspaceHooksWhenUntrusted(testingHandle *testing.T) {
+	if runtime.GOOS == "windows" {
+		testingHandle.Skip("hook commands use sh")
+	}
+	// Arrange
+	dir := testingHandle.TempDir()
+	opts := &options{ClaudeSettings: &config.Settings{Hooks: map[string][]config.HookMatcher{
+		"Stop": {
+			{Source: config.SettingsSourceUser, Hooks: []config.HookCommand{{Command: "touch user"}}},
+			{Source: config.SettingsSourceProject, Hooks: []config.HookCommand{{Command: "touch project; exit 2"}}},
+			{Source: config.SettingsSourceLocal, Hooks: []config.HookCommand{{Command: "touch local"}}},
+		},
+	}}}
+	permissions := &tools.Permissions{Untrusted: true}
+	var warnings bytes.Buffer
+	hooks := &lifecycleHooks{opts: opts, cwd: dir, permissions: permissions, warn: &warnings}
+
+	// Act
+	untrustedReason := hooks.stopHook("Stop")(context.Background(), false)
+	_, projectErr := os.Stat(filepath.Join(dir, "project"))
+	_, localErr := os.Stat(filepath.Join(dir, "local"))
+	_, userErr := os.Stat(filepath.Join(dir, "user"))
+	permissions.Untrusted = false
+	trustedReason := hooks.stopHook("Stop")(context.Background(), false)
+	_, trustedErr := os.Stat(filepath.Join(dir, "project"))
+
+	// Assert
+	testutil.RequireEqual(testingHandle, untrustedReason, "", "project hook did not block")
+	testutil.RequireTrue(testingHandle, os.IsNotExist(projectErr), "project hook skipped")
+	testutil.RequireTrue(testingHandle, os.IsNotExist(localErr), "local hook skipped")
+	testutil.RequireNoError(testingHandle, userErr, "user hook ran")
+	testutil.RequireTrue(testingHandle, strings.Contains(warnings.String(), `Warning: Stop hook "touch project; exit 2" from project settings skipped: this folder is not trusted`), warnings.String())
+	testutil.RequireTrue(testingHandle, strings.Contains(warnings.String(), `"touch local" from local settings skipped`), warnings.String())
+	testutil.RequireEqual(testingHandle, trustedReason, "A Stop hook asked to keep working.", "trusted project hook blocks")
+	testutil.RequireNoError(testingHandle, trustedErr, "trusted project hook ran")
+}
@@ -2,23 +2,35 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/term"
 
 	"github.com/openclaude/openclaude/internal/agent"
 	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/errcode"
+	"github.com/openclaude/openclaude/internal/faultinject"
+	"github.com/openclaude/openclaude/internal/llm"
 	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/project"
+	"github.com/openclaude/openclaude/internal/redact"
 	"github.com/openclaude/openclaude/internal/session"
 	"github.com/openclaude/openclaude/internal/streamjson"
 	"github.com/openclaude/openclaude/internal/tools"
@@ -36,7 +48,8 @@ const defaultTaskMaxTurns = 4
 
 // options holds all CLI flags for compatibility with Claude Code.
 type options struct {
-	// AddDirs are extra directories added to the sandbox allowlist.
+	// AddDirs are extra directories added to the sandbox allowlist, each
+	// optionally suffixed with :ro or :rw.
 	AddDirs []string
 	// Agent selects a named agent profile when supported.
 	Agent string
@@ -50,6 +63,8 @@ type options struct {
 	AppendSystemPrompt string
 	// AppendSystemPromptFile reads system prompt additions from a file.
 	AppendSystemPromptFile string
+	// APITimeout caps each API call, overriding api_timeout_ms.
+	APITimeout time.Duration
 	// Betas adds beta headers in upstream requests.
 	Betas []string
 	// Chrome enables Claude-in-Chrome integration.
@@ -70,6 +85,10 @@ type options struct {
 	EnableAuthStatus bool
 	// FallbackModel is used on retryable errors in print mode.
 	FallbackModel string
+	// FaultInject is the hidden --fault-inject spec used for resilience testing.
+	FaultInject string
+	// FaultInjector injects API and tool faults when --fault-inject is set.
+	FaultInjector *faultinject.Injector
 	// FileSpecs defines preloaded file resources.
 	FileSpecs []string
 	// ForkSession controls whether resume forks the session id.
@@ -86,10 +105,39 @@ type options struct {
 	InitOnly bool
 	// HookConfig stores hook definitions from stream-json control requests.
 	HookConfig *streamJSONHookConfig
+	// AgentDefinitions stores named subagents loaded from .claude/agents.
+	AgentDefinitions []config.AgentDefinition
+	// ProviderConfig exposes the loaded provider config (model catalog) to interactive commands.
+	ProviderConfig *config.ProviderConfig
+	// ClaudeSettings stores the merged settings so permission rules apply and reloads can diff them.
+	ClaudeSettings *config.Settings
+	// PermissionPolicyRules are the allow rules loaded from --permission-policy.
+	PermissionPolicyRules []string
+	// PermissionPolicyLimits are the declarative approvals and limits from --permission-policy.
+	PermissionPolicyLimits *tools.PolicyLimits
+	// SessionApprovals are rules granted with "always allow" in the TUI this session.
+	SessionApprovals []string
+	// Toolchain is the detected project toolchain summarized in the system prompt.
+	Toolchain *project.Toolchain
+	// GitStatus is the repository state at startup, or nil outside a git work tree.
+	GitStatus *project.GitStatus
+	// GitPreflight warns before the first edit of a file with uncommitted changes, or is nil.
+	GitPreflight *gitPreflight
+	// LifecycleHooks runs the settings Stop, SubagentStop, and PreCompact hooks.
+	LifecycleHooks *lifecycleHooks
 	// InputFormat controls how prompts are read in print mode.
 	InputFormat string
+	// Follow keeps reading newline-delimited text prompts from stdin, one turn each.
+	Follow bool
+	// ExpandFileMentions inlines @path mentions in print-mode prompts. It is off
+	// by default because print-mode input may be piped from untrusted sources.
+	ExpandFileMentions bool
+	// JSONInclude adds tool events, messages, or permission denials to json output.
+	JSONInclude []string
 	// JSONSchema provides structured output validation schema.
 	JSONSchema string
+	// Prefill starts the assistant's reply in print mode; the model continues it.
+	Prefill string
 	// Maintenance triggers setup hooks with maintenance trigger.
 	Maintenance bool
 	// MCPConfig holds MCP server configuration inputs.
@@ -102,12 +150,23 @@ type options struct {
 	MaxTurns int
 	// MaxThinkingTokens configures thinking token budgets for compatible models.
 	MaxThinkingTokens int
+	// MaxTokens caps output tokens per request (--max-tokens).
+	MaxTokens int
 	// Model overrides the default model selection.
 	Model string
 	// NoChrome disables Claude-in-Chrome integration.
 	NoChrome bool
 	// NoSessionPersistence disables saving session history to disk.
 	NoSessionPersistence bool
+	// Temperature is the --temperature flag value.
+	Temperature float64
+	// TopP is the --top-p flag value.
+	TopP float64
+	// Stop holds the --stop sequences.
+	Stop []string
+	// RequestParams are the session's overrides of the provider's request
+	// parameters, from the flags above and /set.
+	RequestParams config.RequestParams
 	// OutputFormat controls print mode output encoding.
 	OutputFormat string
 	// ParentSessionID scopes teammate analytics.
@@ -116,6 +175,8 @@ type options struct {
 	PermissionMode string
 	// PermissionPromptTool names the MCP tool used for permission prompts.
 	PermissionPromptTool string
+	// PermissionPolicy points at a committed permission policy file to replay approvals.
+	PermissionPolicy string
 	// PluginDir is reserved for future plugin loading.
 	PluginDir []string
 	// PlanModeRequired forces plan mode before execution.
@@ -146,12 +207,18 @@ type options struct {
 	SystemPrompt string
 	// SystemPromptFile reads the system prompt from a file.
 	SystemPromptFile string
+	// SystemPromptPreset names a template in ~/.openclaude/prompts to use as the system prompt.
+	SystemPromptPreset string
+	// PromptVars are key=value pairs filled into the preset's {{key}} placeholders.
+	PromptVars []string
 	// TeamName assigns a teammate team name.
 	TeamName string
 	// TeammateMode configures how teammates are spawned.
 	TeammateMode string
 	// Teleport resumes a teleport session.
 	Teleport string
+	// TurnTimeout caps each turn, overriding turn_timeout_ms.
+	TurnTimeout time.Duration
 	// AgentID identifies a teammate agent.
 	AgentID string
 	// AgentName is the teammate display name.
@@ -172,10 +239,14 @@ type options struct {
 
 // main wires Cobra and executes the CLI.
 func main() {
+	// The Linux Bash sandbox re-executes this binary as a helper; this returns at once otherwise.
+	tools.RunOSSandboxHelper()
 	opts := &options{}
 	rootCmd := &cobra.Command{
 		Use:   "claude [prompt]",
 		Short: "Claude Code - starts an interactive session by default, use -p/--print for non-interactive output",
+		// main prints errors so failures already reported as stream-json stay off stderr.
+		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if opts.Version {
 				fmt.Printf("%s (Claude Code)\n", version)
@@ -194,14 +265,41 @@ func main() {
 	rootCmd.AddCommand(mcpCommand())
 	rootCmd.AddCommand(pluginCommand())
 	rootCmd.AddCommand(setupTokenCommand())
+	rootCmd.AddCommand(sessionsCommand())
+	rootCmd.AddCommand(viewCommand())
+	rootCmd.AddCommand(artifactsCommand())
+	rootCmd.AddCommand(ledgerCommand())
+	rootCmd.AddCommand(authCommand())
+	rootCmd.AddCommand(configCommand())
+	rootCmd.AddCommand(promptsCommand())
+	rootCmd.AddCommand(installGitHubAppCommand())
 
 	rootCmd.SetArgs(normalizeArgs(os.Args[1:]))
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		var reported reportedError
+		if !errors.As(err, &reported) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(errcode.ExitCode(err))
 	}
 }
 
+// reportedError is a run failure already written to stdout as a stream-json
+// result; main only turns it into the exit status.
+type reportedError struct {
+	err error
+}
+
+func (e reportedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap exposes the failure so errcode.ExitCode can classify it.
+func (e reportedError) Unwrap() error {
+	return e.err
+}
+
 // normalizeArgs rewrites shorthand tokens to match Claude Code behavior.
 func normalizeArgs(args []string) []string {
 	if len(args) == 0 {
@@ -222,13 +320,14 @@ func normalizeArgs(args []string) []string {
 func applyFlags(flags *pflag.FlagSet, opts *options) {
 	flags.SetNormalizeFunc(normalizeFlagName)
 
-	flags.StringSliceVar(&opts.AddDirs, "add-dir", nil, "Additional directories to allow tool access to")
+	flags.StringSliceVar(&opts.AddDirs, "add-dir", nil, "Additional directories to allow tool access to; append :ro for read-only")
 	flags.StringVar(&opts.Agent, "agent", "", "Agent for the current session. Overrides the 'agent' setting.")
 	flags.StringVar(&opts.AgentsJSON, "agents", "", "JSON object defining custom agents (e.g. '{\"reviewer\": {\"description\": \"Reviews code\", \"prompt\": \"You are a code reviewer\"}}')")
 	flags.BoolVar(&opts.AllowDangerouslySkipPermissions, "allow-dangerously-skip-permissions", false, "Enable bypassing all permission checks as an option, without it being enabled by default. Recommended only for sandboxes with no internet access.")
 	flags.StringSliceVar(&opts.AllowedTools, "allowedTools", nil, "Comma or space-separated list of tool names to allow (e.g. \"Bash(git:*) Edit\")")
 	flags.StringVar(&opts.AppendSystemPrompt, "append-system-prompt", "", "Append a system prompt to the default system prompt")
 	flags.StringVar(&opts.AppendSystemPromptFile, "append-system-prompt-file", "", "Read system prompt from a file and append to the default system prompt")
+	flags.DurationVar(&opts.APITimeout, "api-timeout", 0, "Cap each API call, streaming ones included (e.g. 90s; defaults to api_timeout_ms or API_TIMEOUT_MS)")
 	flags.StringSliceVar(&opts.Betas, "betas", nil, "Beta headers to include in API requests (API key users only)")
 	flags.BoolVar(&opts.Chrome, "chrome", false, "Enable Claude in Chrome integration")
 	flags.BoolVarP(&opts.Continue, "continue", "c", false, "Continue the most recent conversation in the current directory")
@@ -239,6 +338,7 @@ func applyFlags(flags *pflag.FlagSet, opts *options) {
 	flags.StringSliceVar(&opts.DisallowedTools, "disallowedTools", nil, "Comma or space-separated list of tool names to deny (e.g. \"Bash(git:*) Edit\")")
 	flags.BoolVar(&opts.EnableAuthStatus, "enable-auth-status", false, "Enable auth status messages in SDK mode")
 	flags.StringVar(&opts.FallbackModel, "fallback-model", "", "Enable automatic fallback to specified model when default model is overloaded (only works with --print)")
+	flags.StringVar(&opts.FaultInject, "fault-inject", "", "Inject API and tool faults for resilience testing (e.g., \"rate=0.2,kinds=429+500+slow+tool-json+tool-timeout,seed=1,limit=3\")")
 	flags.StringSliceVar(&opts.FileSpecs, "file", nil, "File resources to download at startup. Format: file_id:relative_path (e.g., --file file_abc:doc.txt file_def:img.png)")
 	flags.BoolVar(&opts.ForkSession, "fork-session", false, "When resuming, create a new session ID instead of reusing the original (use with --resume or --continue)")
 	flags.StringVar(&opts.FromPR, "from-pr", "", "Resume a session linked to a PR by PR number/URL, or open interactive picker with optional search term")
@@ -247,19 +347,25 @@ func applyFlags(flags *pflag.FlagSet, opts *options) {
 	flags.BoolVar(&opts.Init, "init", false, "Run Setup hooks with init trigger, then continue")
 	flags.BoolVar(&opts.InitOnly, "init-only", false, "Run Setup and SessionStart:startup hooks, then exit")
 	flags.StringVar(&opts.InputFormat, "input-format", "text", "Input format (only works with --print): \"text\" (default), or \"stream-json\" (realtime streaming input)")
+	flags.BoolVar(&opts.ExpandFileMentions, "expand-file-mentions", false, "Inline @path file mentions in --print prompts, as the interactive UI does (paths must be inside the workspace and readable without a permission prompt)")
+	flags.BoolVar(&opts.Follow, "follow", false, "Keep reading prompts from stdin, one per line, and run a turn for each (only works with --print, --input-format=text, and --output-format=text)")
+	flags.StringSliceVar(&opts.JSONInclude, "json-include", nil, "Extra sections for --output-format=json: tools, messages, permission_denials, or all (comma-separated)")
 	flags.StringVar(&opts.JSONSchema, "json-schema", "", "JSON Schema for structured output validation. Example: {\"type\":\"object\",\"properties\":{\"name\":{\"type\":\"string\"}},\"required\":[\"name\"]}")
 	flags.BoolVar(&opts.Maintenance, "maintenance", false, "Run Setup hooks with maintenance trigger, then continue")
 	flags.StringSliceVar(&opts.MCPConfig, "mcp-config", nil, "Load MCP servers from JSON files or strings (space-separated)")
 	flags.BoolVar(&opts.MCPDebug, "mcp-debug", false, "[DEPRECATED. Use --debug instead] Enable MCP debug mode (shows MCP server errors)")
 	flags.Float64Var(&opts.MaxBudgetUSD, "max-budget-usd", 0, "Maximum dollar amount to spend on API calls (only works with --print)")
 	flags.IntVar(&opts.MaxThinkingTokens, "max-thinking-tokens", 0, "Maximum number of thinking tokens. (only works with --print)")
+	flags.IntVar(&opts.MaxTokens, "max-tokens", 0, "Maximum output tokens per request, overriding max_tokens in the provider config")
 	flags.IntVar(&opts.MaxTurns, "max-turns", 0, "Maximum number of agentic turns in non-interactive mode. This will early exit the conversation after the specified number of turns. (only works with --print)")
 	flags.StringVar(&opts.Model, "model", "", "Model for the current session. Provide an alias for the latest model (e.g. 'sonnet' or 'opus') or a model's full name (e.g. 'claude-sonnet-4-5-20250929').")
 	flags.BoolVar(&opts.NoChrome, "no-chrome", false, "Disable Claude in Chrome integration")
 	flags.BoolVar(&opts.NoSessionPersistence, "no-session-persistence", false, "Disable session persistence - sessions will not be saved to disk and cannot be resumed (only works with --print)")
 	flags.StringVar(&opts.OutputFormat, "output-format", "text", "Output format (only works with --print): \"text\" (default), \"json\" (single result), or \"stream-json\" (realtime streaming)")
 	flags.StringVar(&opts.PermissionMode, "permission-mode", "default", "Permission mode to use for the session")
+	flags.StringVar(&opts.PermissionPolicy, "permission-policy", "", "Load tool approvals and limits from a JSON or YAML permission policy file (see /permissions export)")
 	flags.StringVar(&opts.PermissionPromptTool, "permission-prompt-tool", "", "MCP tool to use for permission prompts (only works with --print)")
+	flags.StringVar(&opts.Prefill, "prefill", "", "Start the assistant's reply with this text and let the model continue it, e.g. \"{\" to force JSON (only works with --print)")
 	flags.StringSliceVar(&opts.PluginDir, "plugin-dir", nil, "Load plugins from directories for this session only (repeatable)")
 	flags.BoolVarP(&opts.Print, "print", "p", false, "Print response and exit (useful for pipes). Note: The workspace trust dialog is skipped when Claude is run with the -p mode. Only use this flag in directories you trust.")
 	flags.StringVar(&opts.Remote, "remote", "", "Create a remote session with the given description")
@@ -271,12 +377,17 @@ func applyFlags(flags *pflag.FlagSet, opts *options) {
 	flags.StringVar(&opts.SessionID, "session-id", "", "Use a specific session ID for the conversation (must be a valid UUID)")
 	flags.StringSliceVar(&opts.SettingSources, "setting-sources", nil, "Comma-separated list of setting sources to load (user, project, local).")
 	flags.StringVar(&opts.Settings, "settings", "", "Path to a settings JSON file or a JSON string to load additional settings from")
+	flags.StringArrayVar(&opts.Stop, "stop", nil, "Stop sequence that ends each response (repeatable, up to 4)")
 	flags.BoolVar(&opts.StrictMCPConfig, "strict-mcp-config", false, "Only use MCP servers from --mcp-config, ignoring all other MCP configurations")
 	flags.StringVar(&opts.SystemPrompt, "system-prompt", "", "System prompt to use for the session")
 	flags.StringVar(&opts.SystemPromptFile, "system-prompt-file", "", "Read system prompt from a file")
+	flags.StringVar(&opts.SystemPromptPreset, "system-prompt-preset", "", "Use a system prompt template from ~/.openclaude/prompts (see claude prompts list)")
 	flags.StringVar(&opts.TeamName, "team-name", "", "Team name for swarm coordination")
 	flags.StringVar(&opts.TeammateMode, "teammate-mode", "", "How to spawn teammates: \"tmux\", \"in-process\", or \"auto\"")
 	flags.StringVar(&opts.Teleport, "teleport", "", "Resume a teleport session, optionally specify session ID")
+	flags.Float64Var(&opts.Temperature, "temperature", 0, "Sampling temperature from 0 to 2, overriding the provider config")
+	flags.Float64Var(&opts.TopP, "top-p", 0, "Nucleus sampling probability from 0 to 1, overriding the provider config")
+	flags.DurationVar(&opts.TurnTimeout, "turn-timeout", 0, "Cap each turn, including every API call and tool run (e.g. 10m; defaults to turn_timeout_ms)")
 	flags.StringVar(&opts.AgentID, "agent-id", "", "Teammate agent ID")
 	flags.StringVar(&opts.AgentName, "agent-name", "", "Teammate display name")
 	flags.StringVar(&opts.AgentColor, "agent-color", "", "Teammate UI color")
@@ -284,6 +395,7 @@ func applyFlags(flags *pflag.FlagSet, opts *options) {
 	flags.BoolVar(&opts.PlanModeRequired, "plan-mode-required", false, "Require plan mode before implementation")
 	flags.StringVar(&opts.ParentSessionID, "parent-session-id", "", "Parent session ID for analytics correlation")
 	flags.StringSliceVar(&opts.Tools, "tools", nil, "Specify the list of available tools from the built-in set. Use \"\" to disable all tools, \"default\" to use all tools, or specify tool names (e.g. \"Bash,Edit,Read\").")
+	flags.StringArrayVar(&opts.PromptVars, "var", nil, "Set a --system-prompt-preset template variable as key=value (repeatable)")
 	flags.BoolVar(&opts.Verbose, "verbose", false, "Override verbose mode setting from config")
 	flags.BoolVarP(&opts.Version, "version", "v", false, "Output the version number")
 	flags.BoolVar(&opts.DangerouslySkipPermissions, "dangerously-skip-permissions", false, "Bypass all permission checks. Recommended only for sandboxes with no internet access.")
@@ -297,6 +409,7 @@ func applyFlags(flags *pflag.FlagSet, opts *options) {
 	flags.Lookup("append-system-prompt-file").Hidden = true
 	flags.Lookup("debug-to-stderr").Hidden = true
 	flags.Lookup("enable-auth-status").Hidden = true
+	flags.Lookup("fault-inject").Hidden = true
 	flags.Lookup("from-pr").Hidden = true
 	flags.Lookup("init").Hidden = true
 	flags.Lookup("init-only").Hidden = true
@@ -332,28 +445,54 @@ func normalizeFlagName(_ *pflag.FlagSet, name string) pflag.NormalizedName {
 	}
 }
 
-// doctorCommand validates provider configuration and permissions.
+// doctorCommand checks the provider config, gateway, and local environment.
 func doctorCommand() *cobra.Command {
-	return &cobra.Command{
+	var watch doctorWatchOptions
+	var watching bool
+	var jsonMode bool
+	cmd := &cobra.Command{
 		Use:   "doctor",
 		Short: "Check the health of your Claude Code auto-updater",
+		Long: "Check the provider config, gateway reachability, model availability, pricing, the session store, the terminal, " +
+			"and ripgrep/git, printing a pass/warn/fail table (or JSON with --json). With --watch, keep probing the gateway " +
+			"with small plain and streaming requests and show latency, error rate, and streaming health, to tell gateway " +
+			"flakiness apart from OpenClaude bugs.",
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			path := mustProviderPath()
-			info, err := os.Stat(path)
-			if err != nil {
-				return fmt.Errorf("provider config missing at %s", path)
+			store, _ := session.NewStore()
+			terminal := term.IsTerminal(int(os.Stdout.Fd()))
+			env := doctorEnvironment{
+				ProviderPath: mustProviderPath(),
+				Model:        watch.Model,
+				Store:        store,
+				Terminal:     terminal,
+				TermName:     os.Getenv("TERM"),
 			}
-			mode := info.Mode().Perm()
-			if mode&0o077 != 0 {
-				return fmt.Errorf("provider config permissions too open: %s", mode)
+			if terminal {
+				env.Width, _, _ = term.GetSize(int(os.Stdout.Fd()))
 			}
-			if _, err := config.LoadProviderConfig(path); err != nil {
-				return fmt.Errorf("provider config invalid: %w", err)
+			report, providerCfg := runDoctorChecks(cmd.Context(), env)
+			if err := writeDoctorReport(cmd.OutOrStdout(), report, jsonMode); err != nil {
+				return err
 			}
-			fmt.Fprintf(os.Stdout, "OK: provider config %s\n", path)
-			return nil
+			if !report.OK {
+				return fmt.Errorf("doctor found %d failing checks", report.count(doctorFail))
+			}
+			if !watching {
+				return nil
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			watch.Clear = terminal
+			return runDoctorWatch(ctx, cmd.OutOrStdout(), providerCfg, watch)
 		},
 	}
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print the check results as JSON")
+	cmd.Flags().BoolVar(&watching, "watch", false, "Continuously probe the gateway and show a live health dashboard")
+	cmd.Flags().DurationVar(&watch.Interval, "interval", 5*time.Second, "Pause between probe rounds with --watch")
+	cmd.Flags().IntVar(&watch.Count, "count", 0, "Stop after this many probe rounds with --watch (0 runs until Ctrl+C)")
+	cmd.Flags().StringVar(&watch.Model, "model", "", "Model to check (defaults to the provider default model)")
+	return cmd
 }
 
 // runRoot orchestrates config loading, session handling, and mode dispatch.
@@ -366,24 +505,39 @@ func runRoot(cmd *cobra.Command, opts *options, args []string) error {
 		return err
 	}
 
-	providerCfg, err := config.LoadProviderConfig("")
+	runtimeCfg, err := loadRuntimeConfig(cwd, opts)
 	if err != nil {
-		if errors.Is(err, config.ErrProviderConfigMissing) {
-			return fmt.Errorf("provider config missing; create %s", mustProviderPath())
+		return err
+	}
+	for _, warning := range runtimeCfg.Warnings {
+		fmt.Fprintln(os.Stderr, "Warning: "+warning)
+	}
+	providerCfg := runtimeCfg.Provider
+	settings := runtimeCfg.Settings
+	opts.ProviderConfig = providerCfg
+	opts.ClaudeSettings = settings
+	opts.AgentDefinitions = runtimeCfg.Agents
+	opts.PermissionPolicyRules = runtimeCfg.PolicyRules
+	opts.PermissionPolicyLimits = runtimeCfg.PolicyLimits
+	if opts.FaultInject != "" {
+		faultCfg, err := faultinject.ParseSpec(opts.FaultInject)
+		if err != nil {
+			return err
 		}
-		return fmt.Errorf("load provider config: %w", err)
+		opts.FaultInjector = faultinject.New(faultCfg)
+		defer func() {
+			fmt.Fprintf(cmd.ErrOrStderr(), "fault-inject: %s\n", opts.FaultInjector.Summary())
+		}()
 	}
 	apiKeySource := "none"
-	if providerCfg.APIKey != "" {
+	if providerCfg.Auth != nil {
+		apiKeySource = providerCfg.Auth.Type
+	} else if providerCfg.APIKeyKeyring != "" {
+		apiKeySource = "keyring"
+	} else if providerCfg.APIKey != "" {
 		apiKeySource = "config"
 	}
 
-	settingSources := splitListArgs(opts.SettingSources)
-	settings, err := config.LoadClaudeSettings(cwd, settingSources, opts.Settings)
-	if err != nil {
-		return fmt.Errorf("load settings: %w", err)
-	}
-
 	model := config.ResolveModel(providerCfg, opts.Model, settings.Model)
 	if opts.MaxBudgetUSD > 0 {
 		if _, ok := providerCfg.Pricing[model]; !ok {
@@ -391,7 +545,17 @@ func runRoot(cmd *cobra.Command, opts *options, args []string) error {
 		}
 	}
 
+	requestParams, err := requestParamFlagOverrides(cmd.Flags(), opts)
+	if err != nil {
+		return err
+	}
+	opts.RequestParams = requestParams
+
 	// Parse permission mode early to determine tool availability.
+	// Settings defaultMode applies only when --permission-mode was not given.
+	if !cmd.Flags().Changed("permission-mode") && settings.Permissions.DefaultMode != "" {
+		opts.PermissionMode = settings.Permissions.DefaultMode
+	}
 	permissionMode := parsePermissionMode(opts.PermissionMode)
 	if opts.DangerouslySkipPermissions && !opts.AllowDangerouslySkipPermissions {
 		return fmt.Errorf("dangerously-skip-permissions requires --allow-dangerously-skip-permissions")
@@ -405,28 +569,83 @@ func runRoot(cmd *cobra.Command, opts *options, args []string) error {
 		return err
 	}
 
-	sessionID, history, err := resolveSession(store, cwd, opts)
+	sessionID, history, historyCursor, err := resolveSession(store, cwd, opts, sessionHistoryTokenBudget(providerCfg, model))
 	if err != nil {
 		return err
 	}
+	pruneExpiredSessions(store, settings.CleanupPeriodDays, sessionID)
+	if notice := resumedHistoryNotice(len(history), historyCursor); notice != "" && opts.Print {
+		fmt.Fprintln(os.Stderr, notice)
+	}
 
-	rootDirs := append([]string{cwd}, opts.AddDirs...)
-	sandbox := tools.NewSandbox(rootDirs)
+	if !providerCfg.DisableToolchainDetection {
+		toolchain := project.LoadToolchain(cwd, filepath.Join(store.BaseDir, "toolchain", session.ProjectHash(cwd)+".json"))
+		opts.Toolchain = &toolchain
+	}
+	if !providerCfg.DisableGitStatus {
+		opts.GitStatus = project.LoadGitStatus(context.Background(), cwd)
+		opts.GitPreflight = newGitPreflight(opts, cwd)
+	}
+
+	sandbox := buildSandbox(cwd, opts.AddDirs)
+	if opts.ClaudeSettings != nil {
+		sandbox.HidePatterns = opts.ClaudeSettings.HidePatterns
+	}
 
 	availableTools, _, err := buildTools(opts, sandbox, cwd, store, sessionID, permissionMode)
 	if err != nil {
 		return err
 	}
+	redactor, err := secretRedactor(providerCfg)
+	if err != nil {
+		return err
+	}
 
-	client := openai.NewClient(providerCfg.APIBaseURL, providerCfg.APIKey, time.Duration(providerCfg.TimeoutMS)*time.Millisecond)
 	runner := &agent.Runner{
-		Client:       client,
-		ToolRunner:   availableTools,
-		ToolContext:  tools.ToolContext{Sandbox: sandbox, CWD: cwd, SessionID: sessionID, Store: store},
-		Permissions:  tools.Permissions{Mode: permissionMode},
-		MaxTurns:     opts.MaxTurns,
-		Pricing:      providerCfg.Pricing,
-		MaxBudgetUSD: opts.MaxBudgetUSD,
+		Client:            newAPIClient(opts, providerCfg),
+		ToolRunner:        availableTools,
+		ToolContext:       tools.ToolContext{Sandbox: sandbox, CWD: cwd, SessionID: sessionID, Store: store},
+		Permissions:       tools.Permissions{Mode: permissionMode, AlwaysAllow: permissionAllowRules(opts), Deny: settingsDenyRules(opts), Policy: opts.PermissionPolicyLimits},
+		MaxTurns:          opts.MaxTurns,
+		Pricing:           providerCfg.Pricing,
+		MaxBudgetUSD:      opts.MaxBudgetUSD,
+		Models:            providerCfg.Models,
+		RequestParams:     resolveRequestParams(opts, providerCfg),
+		MaxParallelTasks:  providerCfg.MaxParallelTasks,
+		MaxThinkingTokens: resolveMaxThinkingTokens(opts),
+		ThinkingFormat:    providerCfg.ThinkingFormat,
+		ServerTools:       providerCfg.ServerTools,
+		TurnTimeout:       resolveTurnTimeout(opts, providerCfg),
+		Redactor:          redactor,
+	}
+
+	// Record each request's cost in the local ledger, and export usage over OTLP
+	// when enabled through the settings env block or environment.
+	var recorders usageRecorders
+	if ledger := startLedger(providerCfg, store, sessionID); ledger != nil {
+		recorders = append(recorders, ledger)
+	}
+	if exporter, stopTelemetry := startTelemetry(settings, sessionID); exporter != nil {
+		defer stopTelemetry()
+		recorders = append(recorders, exporter)
+	}
+	if len(recorders) > 0 {
+		runner.Recorder = recorders
+	}
+	// Settings Stop, SubagentStop, and PreCompact hooks; print mode reports their failures on stderr.
+	opts.LifecycleHooks = &lifecycleHooks{opts: opts, sessionID: sessionID, transcriptPath: store.SessionPath(sessionID), cwd: cwd, permissions: &runner.Permissions}
+	if opts.Print {
+		opts.LifecycleHooks.warn = os.Stderr
+	}
+	runner.StopHook = opts.LifecycleHooks.stopHook("Stop")
+	runner.PreCompact = opts.LifecycleHooks.preCompactHook()
+	// SIGUSR1 dumps a diagnostic snapshot without interrupting the run.
+	defer startStatusDump(opts, runner, sessionID, store)()
+	// Verbose print mode reports each request's speed on stderr.
+	if opts.Print && opts.Verbose {
+		runner.Status.OnRequestDone = func(metrics agent.RequestMetrics) {
+			fmt.Fprintf(os.Stderr, "API request: %s\n", metrics)
+		}
 	}
 
 	// Build a base system prompt and apply overrides.
@@ -434,14 +653,19 @@ func runRoot(cmd *cobra.Command, opts *options, args []string) error {
 
 	// Configure Task tool execution with a conservative recursion limit.
 	runner.ToolContext.TaskMaxDepth = defaultTaskMaxDepth
-	runner.ToolContext.TaskExecutor = buildTaskExecutor(runner, opts, model)
-	runner.ToolContext.TaskManager = tools.NewTaskManager()
+	runner.ToolContext.TaskExecutor = buildTaskExecutor(runner, opts, providerCfg, model)
+	// Background Tasks are scoped to this invocation: shutdown cancels them and
+	// waits for their final records so exit never truncates the task log.
+	taskManager := tools.NewTaskManager()
+	defer taskManager.Shutdown()
+	runner.ToolContext.TaskManager = taskManager
+	recordRunMetadata(cmd.Flags(), opts, store, sessionID, model, providerCfg.APIBaseURL, cwd)
 
 	// Dispatch to print or interactive mode.
 	if opts.Print {
 		return runPrintMode(cmd, opts, runner, history, systemPrompt, model, sessionID, store, settings, apiKeySource)
 	}
-	return runInteractive(opts, runner, history, systemPrompt, model, sessionID, store)
+	return runInteractive(opts, runner, history, historyCursor, systemPrompt, model, sessionID, store)
 }
 
 // mustProviderPath returns the default config path or a fallback placeholder.
@@ -483,6 +707,18 @@ func applyPromptFileOverrides(opts *options, cwd string) error {
 		}
 		opts.SystemPrompt = prompt
 	}
+	if opts.SystemPromptPreset != "" {
+		if opts.SystemPrompt != "" {
+			return fmt.Errorf("Error: Cannot use --system-prompt-preset with --system-prompt or --system-prompt-file. Please use only one.")
+		}
+		prompt, err := resolvePromptPreset(cwd, opts.SystemPromptPreset, opts.PromptVars, time.Now())
+		if err != nil {
+			return err
+		}
+		opts.SystemPrompt = prompt
+	} else if len(opts.PromptVars) > 0 {
+		return fmt.Errorf("Error: --var requires --system-prompt-preset.")
+	}
 	if opts.AppendSystemPromptFile != "" && opts.AppendSystemPrompt != "" {
 		return fmt.Errorf("Error: Cannot use both --append-system-prompt and --append-system-prompt-file. Please use only one.")
 	}
@@ -548,6 +784,18 @@ func validateFormatOptions(opts *options) error {
 	if opts.IncludePartialMessages && (!opts.Print || opts.OutputFormat != "stream-json") {
 		return fmt.Errorf("Error: --include-partial-messages requires --print and --output-format=stream-json.")
 	}
+	if opts.ExpandFileMentions && (!opts.Print || opts.InputFormat != "text") {
+		return fmt.Errorf("Error: --expand-file-mentions requires --print and --input-format=text.")
+	}
+	if opts.Follow && (!opts.Print || opts.InputFormat != "text" || opts.OutputFormat != "text") {
+		return fmt.Errorf("Error: --follow requires --print, --input-format=text, and --output-format=text.")
+	}
+	if err := validateJSONInclude(opts); err != nil {
+		return err
+	}
+	if opts.Prefill != "" && !opts.Print {
+		return fmt.Errorf("Error: --prefill only works with --print.")
+	}
 	if opts.NoSessionPersistence && !opts.Print {
 		return fmt.Errorf("Error: --no-session-persistence can only be used with --print mode.")
 	}
@@ -650,20 +898,21 @@ func warnNoopOptions(opts *options) {
 }
 
 // resolveSession determines session id and loads history, if any.
-func resolveSession(store *session.Store, cwd string, opts *options) (string, []openai.Message, error) {
+func resolveSession(store *session.Store, cwd string, opts *options, historyBudget int) (string, []llm.Message, sessionHistoryCursor, error) {
 	var (
 		baseSessionID string
-		history       []openai.Message
+		history       []llm.Message
+		cursor        sessionHistoryCursor
 	)
 	projectHash := session.ProjectHash(cwd)
 	if opts.Resume != "" {
 		if opts.Resume == "picker" {
 			picked, err := pickSession(store)
 			if err != nil {
-				return "", nil, err
+				return "", nil, cursor, err
 			}
 			if picked == "" {
-				return "", nil, errors.New("no session selected")
+				return "", nil, cursor, errors.New("no session selected")
 			}
 			baseSessionID = picked
 		} else {
@@ -678,9 +927,9 @@ func resolveSession(store *session.Store, cwd string, opts *options) (string, []
 
 	if baseSessionID != "" {
 		var err error
-		history, err = loadSessionMessages(store, baseSessionID)
+		history, cursor, err = loadResumedSessionMessages(store, baseSessionID, historyBudget)
 		if err != nil {
-			return "", nil, err
+			return "", nil, cursor, err
 		}
 	}
 
@@ -695,26 +944,23 @@ func resolveSession(store *session.Store, cwd string, opts *options) (string, []
 
 	if baseSessionID != "" && targetSessionID != baseSessionID {
 		if err := store.CloneSession(baseSessionID, targetSessionID); err != nil {
-			return "", nil, err
+			return "", nil, cursor, err
 		}
 	}
 
-	return targetSessionID, history, nil
+	return targetSessionID, history, cursor, nil
 }
 
 // pickSession shows a small interactive chooser for recent sessions.
 func pickSession(store *session.Store) (string, error) {
-	ids, err := store.ListSessions(10)
+	metas, err := store.ListSessionMeta(10)
 	if err != nil {
 		return "", err
 	}
-	if len(ids) == 0 {
+	if len(metas) == 0 {
 		return "", errors.New("no sessions available")
 	}
-	fmt.Fprintln(os.Stdout, "Select a session:")
-	for i, id := range ids {
-		fmt.Fprintf(os.Stdout, "%d) %s\n", i+1, id)
-	}
+	writeSessionChoices(os.Stdout, metas)
 	fmt.Fprint(os.Stdout, "Enter number: ")
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadString('\n')
@@ -729,10 +975,24 @@ func pickSession(store *session.Store) (string, error) {
 	if _, err := fmt.Sscanf(line, "%d", &index); err != nil {
 		return "", fmt.Errorf("invalid selection")
 	}
-	if index < 1 || index > len(ids) {
+	if index < 1 || index > len(metas) {
 		return "", fmt.Errorf("selection out of range")
 	}
-	return ids[index-1], nil
+	return metas[index-1].ID, nil
+}
+
+// writeSessionChoices numbers the sessions for pickSession, each followed by
+// a preview line of its stats.
+func writeSessionChoices(out io.Writer, metas []session.SessionMeta) {
+	fmt.Fprintln(out, "Select a session:")
+	for i, meta := range metas {
+		title := meta.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		fmt.Fprintf(out, "%d) %s  %s  %d msgs  %s\n", i+1, formatSessionTime(meta.UpdatedAt), title, meta.MessageCount, meta.ID)
+		fmt.Fprintf(out, "   %s\n", formatSessionStats(meta))
+	}
 }
 
 // buildTools constructs the tool runner based on CLI filters.
@@ -755,6 +1015,26 @@ func buildTools(
 	if len(opts.Tools) == 0 {
 		toolsArg = []string{"default"}
 	}
+	if pythonTool := buildPythonTool(opts, toolsArg); pythonTool != nil {
+		toolSet = append(toolSet, pythonTool)
+	}
+	if osSandbox := buildOSSandbox(opts, cwd); osSandbox != nil {
+		for index, tool := range toolSet {
+			if _, ok := tool.(*tools.BashTool); ok {
+				toolSet[index] = &tools.BashTool{OSSandbox: osSandbox}
+			}
+		}
+	}
+	if opts.ClaudeSettings != nil && len(opts.ClaudeSettings.IgnorePatterns) > 0 {
+		for index, tool := range toolSet {
+			switch tool.(type) {
+			case *tools.GlobTool:
+				toolSet[index] = &tools.GlobTool{Ignore: opts.ClaudeSettings.IgnorePatterns}
+			case *tools.GrepTool:
+				toolSet[index] = &tools.GrepTool{Ignore: opts.ClaudeSettings.IgnorePatterns}
+			}
+		}
+	}
 	if len(toolsArg) == 1 && strings.TrimSpace(toolsArg[0]) == "" {
 		return nil, nil, nil
 	}
@@ -768,13 +1048,18 @@ func buildTools(
 	}
 
 	allowedTools := normalizeToolList(splitListArgs(opts.AllowedTools))
-	disallowedTools := normalizeToolList(splitListArgs(opts.DisallowedTools))
+	disallowedTools := append(normalizeToolList(splitListArgs(opts.DisallowedTools)), settingsDeniedTools(opts)...)
 	filtered, err := tools.FilterTools(toolSet, allowedTools, disallowedTools)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	runner := tools.NewRunner(filtered)
+	runner := tools.NewRunner(tools.ApplyOverrides(filtered, settingsToolOverrides(opts)))
+	runner.MaxResultBytes = toolResultLimits(opts)
+	if runner.Redactor, err = secretRedactor(opts.ProviderConfig); err != nil {
+		return nil, nil, err
+	}
+	opts.FaultInjector.WrapTools(runner)
 	names := make([]string, 0, len(runner.Tools))
 	for name := range runner.Tools {
 		names = append(names, name)
@@ -782,12 +1067,131 @@ func buildTools(
 	return runner, names, nil
 }
 
+// settingsToolOverrides returns the settings toolOverrides keyed by canonical
+// tool name, so aliases such as "bash" match like they do in --tools.
+func settingsToolOverrides(opts *options) map[string]tools.ToolOverride {
+	if opts == nil || opts.ClaudeSettings == nil || len(opts.ClaudeSettings.ToolOverrides) == 0 {
+		return nil
+	}
+	overrides := make(map[string]tools.ToolOverride, len(opts.ClaudeSettings.ToolOverrides))
+	for name, override := range opts.ClaudeSettings.ToolOverrides {
+		for _, canonical := range normalizeToolList([]string{name}) {
+			overrides[canonical] = tools.ToolOverride{Description: override.Description, Schema: override.Schema}
+		}
+	}
+	return overrides
+}
+
+// toolResultLimits returns the provider tool_result_max_bytes caps keyed by
+// canonical tool name, keeping the "default" entry as is.
+func toolResultLimits(opts *options) map[string]int {
+	if opts == nil || opts.ProviderConfig == nil || len(opts.ProviderConfig.ToolResultMaxBytes) == 0 {
+		return nil
+	}
+	limits := make(map[string]int, len(opts.ProviderConfig.ToolResultMaxBytes))
+	for name, limit := range opts.ProviderConfig.ToolResultMaxBytes {
+		if name == tools.DefaultResultLimitKey {
+			limits[name] = limit
+			continue
+		}
+		for _, canonical := range normalizeToolList([]string{name}) {
+			limits[canonical] = limit
+		}
+	}
+	return limits
+}
+
+// secretRedactor builds the credential filter from the provider redaction
+// settings, or returns nil when redaction is disabled.
+func secretRedactor(providerCfg *config.ProviderConfig) (*redact.Redactor, error) {
+	if providerCfg == nil || providerCfg.Redaction.Disabled {
+		return nil, nil
+	}
+	return redact.New(providerCfg.Redaction.Patterns)
+}
+
+// newAPIClient builds the gateway client, routed through the fault injector when enabled.
+func newAPIClient(opts *options, providerCfg *config.ProviderConfig) *openai.Client {
+	client := newGatewayClient(providerCfg)
+	client.SetTimeouts(apiTimeouts(providerCfg, resolveAPITimeout(opts)))
+	if opts.FaultInjector != nil {
+		client.SetTransport(opts.FaultInjector.Transport(http.DefaultTransport))
+	}
+	return client
+}
+
+// newGatewayClient builds a client for the configured gateway and API flavor.
+func newGatewayClient(providerCfg *config.ProviderConfig) *openai.Client {
+	client := openai.NewClient(providerCfg.APIBaseURL, providerCfg.APIKey, time.Duration(providerCfg.TimeoutMS)*time.Millisecond)
+	client.SetTimeouts(apiTimeouts(providerCfg, 0))
+	if providerCfg.APIFlavor == config.APIFlavorAzure {
+		client.SetAzure(openai.AzureOptions{APIVersion: providerCfg.Azure.APIVersion, Deployments: providerCfg.Azure.Deployments})
+	}
+	if auth := providerCfg.Auth; auth != nil {
+		ttl := time.Duration(auth.TokenTTLSeconds) * time.Second
+		switch auth.Type {
+		case config.AuthTypeTokenCommand:
+			client.SetTokenSource(openai.NewCommandTokenSource(auth.Command, ttl))
+		case config.AuthTypeClientCredentials:
+			credentials := openai.ClientCredentials{
+				TokenURL:     auth.TokenURL,
+				ClientID:     auth.ClientID,
+				ClientSecret: auth.ClientSecret,
+				Scopes:       auth.Scopes,
+				Audience:     auth.Audience,
+			}
+			client.SetTokenSource(openai.NewClientCredentialsTokenSource(credentials, &http.Client{Timeout: 30 * time.Second}, ttl))
+		}
+	}
+	return client
+}
+
+// buildOSSandbox returns the Bash OS sandbox when settings enable it. Relative
+// writable directories resolve against cwd and "~/" against the home directory.
+func buildOSSandbox(opts *options, cwd string) *tools.OSSandbox {
+	if opts.ClaudeSettings == nil || !opts.ClaudeSettings.Sandbox.Enabled {
+		return nil
+	}
+	sandboxSettings := opts.ClaudeSettings.Sandbox
+	writable := make([]string, 0, len(sandboxSettings.WritableDirs))
+	for _, dir := range sandboxSettings.WritableDirs {
+		if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, rest)
+			}
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cwd, dir)
+		}
+		writable = append(writable, dir)
+	}
+	return &tools.OSSandbox{WritableDirs: writable, DisableNetwork: sandboxSettings.DisableNetwork}
+}
+
+// buildPythonTool returns the RunPython tool when the provider config enables it
+// or --tools names it; it stays out of the default set to match Claude Code.
+func buildPythonTool(opts *options, toolsArg []string) tools.Tool {
+	var pythonCfg config.PythonConfig
+	if opts.ProviderConfig != nil {
+		pythonCfg = opts.ProviderConfig.Python
+	}
+	if !pythonCfg.Enabled && !slices.Contains(normalizeToolList(toolsArg), tools.RunPythonToolName) {
+		return nil
+	}
+	return &tools.RunPythonTool{
+		Interpreter:      pythonCfg.Interpreter,
+		Venv:             pythonCfg.Venv,
+		Timeout:          time.Duration(pythonCfg.TimeoutMS) * time.Millisecond,
+		MemoryLimitBytes: int64(pythonCfg.MemoryMB) << 20,
+	}
+}
+
 // runPrintMode handles one-shot requests and prints output to stdout.
 func runPrintMode(
 	cmd *cobra.Command,
 	opts *options,
 	runner *agent.Runner,
-	history []openai.Message,
+	history []llm.Message,
 	systemPrompt string,
 	model string,
 	sessionID string,
@@ -795,20 +1199,30 @@ func runPrintMode(
 	settings *config.Settings,
 	apiKeySource string,
 ) error {
+	// AskUserQuestion pauses the run with a needs_user_input result instead of failing.
+	runner.ToolContext.DeferUserInput = true
+	runner.BeforeTool = func(name string, args json.RawMessage) error {
+		return opts.GitPreflight.Check(context.Background(), name, args, warnPreflightTo(os.Stderr))
+	}
 	if opts.OutputFormat == "stream-json" {
 		return runPrintModeStreamJSON(cmd, opts, runner, history, systemPrompt, model, sessionID, store, settings, apiKeySource)
 	}
+	if opts.Follow {
+		runner.AuthorizeTool = denyPrintModeTool
+		prompt := strings.TrimSpace(strings.Join(cmd.Flags().Args(), " "))
+		return runPrintModeFollow(context.Background(), os.Stdin, os.Stdout, os.Stderr, opts, runner, history, systemPrompt, prompt, model, sessionID, store)
+	}
 
-	inputMessages, err := readInputMessages(cmd, opts)
+	inputMessages, err := readInputMessages(cmd, opts, runner)
 	if err != nil {
 		return err
 	}
+	inputMessages = answerPendingQuestion(history, inputMessages)
+	inputMessages = withPrefill(inputMessages, opts.Prefill)
 
 	messages := append(history, inputMessages...)
 	messages = ensureSystem(messages, systemPrompt)
-	runner.AuthorizeTool = func(name string, args json.RawMessage) (bool, error) {
-		return false, fmt.Errorf("tool %s requires confirmation in print mode", name)
-	}
+	runner.AuthorizeTool = denyPrintModeTool
 
 	startTime := time.Now()
 	modelUsed := model
@@ -819,12 +1233,24 @@ func runPrintMode(
 			result, err = runner.Run(context.Background(), messages, "", opts.FallbackModel, runner.ToolRunner != nil)
 		}
 	}
-	if err != nil {
+	var inputErr *tools.UserInputRequiredError
+	if err != nil && !(errors.As(err, &inputErr) && result != nil) {
 		if opts.OutputFormat == "stream-json" {
-			return writeStreamJSONError(err, opts, inputMessages, sessionID, modelUsed, time.Since(startTime))
+			if writeErr := writeStreamJSONError(err, opts, inputMessages, sessionID, modelUsed, time.Since(startTime)); writeErr != nil {
+				return writeErr
+			}
+			return reportedError{err: err}
+		}
+		if opts.OutputFormat == "json" && len(opts.JSONInclude) > 0 {
+			if writeErr := writeJSONError(opts, err, sessionID, modelUsed); writeErr != nil {
+				return writeErr
+			}
 		}
 		return err
 	}
+	if len(result.DroppedToolResults) > 0 {
+		fmt.Fprintln(os.Stderr, contextTrimmedNotice(result.DroppedToolResults))
+	}
 
 	if !opts.NoSessionPersistence {
 		newMessages := result.Messages
@@ -834,12 +1260,18 @@ func runPrintMode(
 		if err := persistSession(store, sessionID, newMessages, result.Events); err != nil {
 			return err
 		}
+		_ = store.AppendCost(sessionID, result.CostUSD)
 		_ = store.SaveLastSession(session.ProjectHash(mustCwd()), sessionID)
+		_ = store.CloseSession(sessionID)
 	}
 
+	if inputErr != nil {
+		return writeNeedsUserInput(os.Stdout, os.Stderr, opts.OutputFormat, result, inputErr.Request, sessionID, modelUsed)
+	}
 	return writeOutput(
 		opts.OutputFormat,
 		result,
+		len(history),
 		opts.ReplayUserMessages,
 		opts.IncludePartialMessages,
 		string(runner.Permissions.Mode),
@@ -857,7 +1289,7 @@ func runPrintModeStreamJSON(
 	cmd *cobra.Command,
 	opts *options,
 	runner *agent.Runner,
-	history []openai.Message,
+	history []llm.Message,
 	systemPrompt string,
 	model string,
 	sessionID string,
@@ -871,7 +1303,7 @@ func runPrintModeStreamJSON(
 	}
 
 	var (
-		inputMessages []openai.Message
+		inputMessages []llm.Message
 		streamInput   *streamJSONInput
 		err           error
 	)
@@ -883,7 +1315,7 @@ func runPrintModeStreamJSON(
 		}
 		inputMessages = streamInput.Messages
 	} else {
-		inputMessages, err = readInputMessages(cmd, opts)
+		inputMessages, err = readInputMessages(cmd, opts, runner)
 		if err != nil {
 			return err
 		}
@@ -899,6 +1331,12 @@ func runPrintModeStreamJSON(
 	modelUsed := model
 	authStatusEmitted := false
 	hookEmitter := newStreamJSONHookEmitter(writer, sessionID, opts.HookConfig)
+	if opts.LifecycleHooks != nil {
+		opts.LifecycleHooks.emitter = hookEmitter
+	}
+	runner.BeforeTool = func(name string, args json.RawMessage) error {
+		return opts.GitPreflight.Check(context.Background(), name, args, emitPreflightEvent(writer, sessionID))
+	}
 	var keepAlive *keepAliveEmitter
 
 	// Ensure keep-alive emissions are stopped before returning.
@@ -934,11 +1372,11 @@ func runPrintModeStreamJSON(
 
 	// Recompute the system prompt after any control-request overrides.
 	systemPrompt = resolveSystemPrompt(opts, runner)
+	inputMessages = answerPendingQuestion(history, inputMessages)
+	inputMessages = withPrefill(inputMessages, opts.Prefill)
 	messages := append(history, inputMessages...)
 	messages = ensureSystem(messages, systemPrompt)
-	runner.AuthorizeTool = func(name string, args json.RawMessage) (bool, error) {
-		return false, fmt.Errorf("tool %s requires confirmation in print mode", name)
-	}
+	runner.AuthorizeTool = denyPrintModeTool
 
 	initEvent := buildSystemInitEvent(opts, runner, modelUsed, sessionID, settings, apiKeySource)
 	if err := writer.Write(initEvent); err != nil {
@@ -1031,6 +1469,10 @@ func runPrintModeStreamJSON(
 	result, err := runner.RunStream(context.Background(), messages, "", modelUsed, runner.ToolRunner != nil, callbacks)
 	if err != nil && opts.FallbackModel != "" && isRetryableError(err) && !streamed {
 		modelUsed = opts.FallbackModel
+		retry := agent.ProgressEvent{Phase: agent.ProgressRetrying, Model: opts.FallbackModel, Attempt: 2, Reason: "falling back to " + opts.FallbackModel + " after: " + err.Error()}
+		if err := writeRunProgress(writer, sessionID, retry); err != nil {
+			return err
+		}
 		emitter = streamjson.NewOpenAIStreamEmitter(writer, opts.IncludePartialMessages, sessionID)
 		callbacks = buildStreamCallbacks(emitter, writer, sessionID, &streamed, hookEmitter)
 		result, err = runner.RunStream(
@@ -1042,8 +1484,12 @@ func runPrintModeStreamJSON(
 			callbacks,
 		)
 	}
-	if err != nil {
-		return writeStreamJSONErrorResult(writer, err, sessionID, modelUsed, time.Since(startTime))
+	var inputErr *tools.UserInputRequiredError
+	if err != nil && !(errors.As(err, &inputErr) && result != nil) {
+		if writeErr := writeStreamJSONErrorResult(writer, err, sessionID, modelUsed, time.Since(startTime)); writeErr != nil {
+			return writeErr
+		}
+		return reportedError{err: err}
 	}
 
 	if !opts.NoSessionPersistence {
@@ -1054,9 +1500,14 @@ func runPrintModeStreamJSON(
 		if err := persistSession(store, sessionID, newMessages, result.Events); err != nil {
 			return err
 		}
+		_ = store.AppendCost(sessionID, result.CostUSD)
 		_ = store.SaveLastSession(session.ProjectHash(mustCwd()), sessionID)
+		_ = store.CloseSession(sessionID)
 	}
 
+	if inputErr != nil {
+		return writeStreamJSONNeedsUserInput(writer, result, inputErr.Request, sessionID, modelUsed)
+	}
 	return writeStreamJSONResult(writer, result, sessionID, modelUsed)
 }
 
@@ -1064,13 +1515,19 @@ func runPrintModeStreamJSON(
 func runInteractive(
 	opts *options,
 	runner *agent.Runner,
-	history []openai.Message,
+	history []llm.Message,
+	historyCursor sessionHistoryCursor,
 	systemPrompt string,
 	model string,
 	sessionID string,
 	store *session.Store,
 ) error {
-	return runInteractiveTUI(opts, runner, history, systemPrompt, model, sessionID, store)
+	trusted, err := confirmWorkspaceTrust(store, runner.ToolContext.CWD, os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+	runner.Permissions.Untrusted = !trusted
+	return runInteractiveTUI(opts, runner, history, historyCursor, systemPrompt, model, sessionID, store)
 }
 
 // buildStreamCallbacks wires stream-json emission into the streaming agent loop.
@@ -1124,6 +1581,42 @@ func buildStreamCallbacks(
 			*streamed = true
 			return nil
 		},
+		OnToolProgress: func(event agent.ToolEvent, progress tools.Progress) {
+			data := streamjson.ProgressData{
+				Type:     "tool_progress",
+				ToolName: event.ToolName,
+				Status:   "running",
+				Message:  fmt.Sprintf("%s: %s", event.ToolName, progress),
+				Current:  progress.Current,
+				Total:    progress.Total,
+				Unit:     progress.Unit,
+			}
+			if percent := progress.Percent(); percent >= 0 {
+				data.Percent = &percent
+			}
+			// Progress is best effort; a failed write surfaces on the next event.
+			_ = writer.Write(streamjson.ProgressEvent{
+				Type:            "progress",
+				Data:            data,
+				SessionID:       sessionID,
+				ParentToolUseID: event.ToolID,
+				UUID:            streamjson.NewUUID(),
+			})
+		},
+		OnProgress: func(event agent.ProgressEvent) {
+			// Heartbeats are best effort, like tool progress.
+			_ = writeRunProgress(writer, sessionID, event)
+		},
+		OnContextTrimmed: func(dropped []agent.DroppedToolResult) {
+			// Like progress, the notice is best effort.
+			_ = writer.Write(streamjson.SystemEvent{
+				Type:      "system",
+				Subtype:   "context_trimmed",
+				Status:    map[string]any{"dropped_tool_results": dropped},
+				SessionID: sessionID,
+				UUID:      streamjson.NewUUID(),
+			})
+		},
 		OnStreamComplete: func(summary agent.StreamSummary) error {
 			message, ok, err := emitter.Finalize()
 			if err != nil {
@@ -1131,11 +1624,15 @@ func buildStreamCallbacks(
 			}
 			if !ok {
 				message = streamjson.BuildAssistantMessage(summary.Message)
+			} else {
+				// The run's message is authoritative: unlike the deltas, it
+				// carries any prefill and the provider's server tool calls.
+				message.Content = streamjson.BuildAssistantMessage(summary.Message).Content
 			}
 			stopReason := mapFinishReasonToStopReason(summary.FinishReason)
 			usage := streamjson.NewEmptyMessageUsage("")
 			if summary.HasUsage {
-				usage = streamjson.NewMessageUsageFromOpenAI(summary.Usage, "")
+				usage = streamjson.NewMessageUsage(summary.Usage, "")
 			}
 			message = buildAssistantMessageEnvelope(message, summary.Model, stopReason, usage)
 			assistantEvent := streamjson.AssistantEvent{
@@ -1152,7 +1649,7 @@ func buildStreamCallbacks(
 			*streamed = true
 			return nil
 		},
-		OnToolResult: func(event agent.ToolEvent, _ openai.Message) error {
+		OnToolResult: func(event agent.ToolEvent, _ llm.Message) error {
 			// Emit post-tool hook events after execution finishes.
 			if hookEmitter != nil {
 				var err error
@@ -1168,10 +1665,11 @@ func buildStreamCallbacks(
 			progressEvent := streamjson.ProgressEvent{
 				Type: "progress",
 				Data: streamjson.ProgressData{
-					Type:     "tool_progress",
-					ToolName: event.ToolName,
-					Status:   "completed",
-					Message:  fmt.Sprintf("Completed tool %s", event.ToolName),
+					Type:      "tool_progress",
+					ToolName:  event.ToolName,
+					Status:    "completed",
+					Message:   fmt.Sprintf("Completed tool %s", event.ToolName),
+					ElapsedMS: event.DurationMS,
 				},
 				SessionID:       sessionID,
 				ParentToolUseID: event.ToolID,
@@ -1192,6 +1690,7 @@ func buildStreamCallbacks(
 				UUID:            streamjson.NewUUID(),
 				IsReplay:        false,
 				IsSynthetic:     false,
+				ToolUseResult:   toolUseResult(event),
 			}
 			if err := writer.Write(userEvent); err != nil {
 				return err
@@ -1214,6 +1713,21 @@ func buildStreamCallbacks(
 	}
 }
 
+// toolUseResult returns the structured tool_use_result for command tools, or
+// nil for tools that run no process.
+func toolUseResult(event agent.ToolEvent) *streamjson.ToolUseResult {
+	if event.ExitCode == nil {
+		return nil
+	}
+	return &streamjson.ToolUseResult{
+		Stdout:      event.Stdout,
+		Stderr:      event.Stderr,
+		Interrupted: event.Interrupted,
+		ExitCode:    *event.ExitCode,
+		DurationMS:  event.DurationMS,
+	}
+}
+
 // buildToolUseSummary returns a compact summary for tool usage events.
 func buildToolUseSummary(event agent.ToolEvent) string {
 	if event.ToolName == "" {
@@ -1248,10 +1762,19 @@ func buildSystemInitEvent(opts *options, runner *agent.Runner, model string, ses
 		Agents:            listAgentNames(opts),
 		Skills:            listSkillNames(opts, settings),
 		Plugins:           listPluginDescriptors(opts, settings),
+		Git:               initGitStatus(opts),
 		UUID:              streamjson.NewUUID(),
 	}
 }
 
+// initGitStatus returns the git state for system:init, or nil to omit the field.
+func initGitStatus(opts *options) any {
+	if opts == nil || opts.GitStatus == nil {
+		return nil
+	}
+	return opts.GitStatus
+}
+
 // listToolNames returns tool names in the configured ordering.
 func listToolNames(runner *agent.Runner) []string {
 	if runner == nil || runner.ToolRunner == nil {
@@ -1261,7 +1784,7 @@ func listToolNames(runner *agent.Runner) []string {
 }
 
 // readInputMessages parses prompt input for print mode.
-func readInputMessages(cmd *cobra.Command, opts *options) ([]openai.Message, error) {
+func readInputMessages(cmd *cobra.Command, opts *options, runner *agent.Runner) ([]llm.Message, error) {
 	if opts.InputFormat == "stream-json" {
 		return readStreamInput(os.Stdin)
 	}
@@ -1277,11 +1800,12 @@ func readInputMessages(cmd *cobra.Command, opts *options) ([]openai.Message, err
 	if prompt == "" {
 		return nil, errors.New("prompt is required")
 	}
-	return []openai.Message{{Role: "user", Content: prompt}}, nil
+	prompt = expandPrintMentions(prompt, opts, runner)
+	return []llm.Message{{Role: "user", Content: prompt}}, nil
 }
 
 // readStreamInput consumes stream-json input into user messages.
-func readStreamInput(reader io.Reader) ([]openai.Message, error) {
+func readStreamInput(reader io.Reader) ([]llm.Message, error) {
 	parsed, err := readStreamInputWithControl(reader)
 	if err != nil {
 		return nil, err
@@ -1290,12 +1814,12 @@ func readStreamInput(reader io.Reader) ([]openai.Message, error) {
 }
 
 // parseStreamMessage extracts a user message from stream-json events.
-func parseStreamMessage(payload map[string]any) (openai.Message, bool) {
+func parseStreamMessage(payload map[string]any) (llm.Message, bool) {
 	// Support direct role/content payloads.
 	if role, ok := payload["role"].(string); ok {
 		if role == "user" {
-			content := streamjson.ExtractText(payload["content"])
-			return openai.Message{Role: "user", Content: content}, true
+			content := streamjson.ConvertUserContent(payload["content"])
+			return llm.Message{Role: "user", Content: content}, true
 		}
 	}
 
@@ -1303,8 +1827,8 @@ func parseStreamMessage(payload map[string]any) (openai.Message, bool) {
 	if msg, ok := payload["message"].(map[string]any); ok {
 		role, _ := msg["role"].(string)
 		if role == "user" {
-			content := streamjson.ExtractText(msg["content"])
-			return openai.Message{Role: "user", Content: content}, true
+			content := streamjson.ConvertUserContent(msg["content"])
+			return llm.Message{Role: "user", Content: content}, true
 		}
 	}
 
@@ -1313,20 +1837,20 @@ func parseStreamMessage(payload map[string]any) (openai.Message, bool) {
 		switch typ {
 		case "user":
 			if msg, ok := payload["message"].(map[string]any); ok {
-				content := streamjson.ExtractText(msg["content"])
-				return openai.Message{Role: "user", Content: content}, true
+				content := streamjson.ConvertUserContent(msg["content"])
+				return llm.Message{Role: "user", Content: content}, true
 			}
 		case "user_message":
-			content := streamjson.ExtractText(payload["content"])
-			return openai.Message{Role: "user", Content: content}, true
+			content := streamjson.ConvertUserContent(payload["content"])
+			return llm.Message{Role: "user", Content: content}, true
 		}
 	}
 
-	return openai.Message{}, false
+	return llm.Message{}, false
 }
 
 // persistSession writes new messages and tool events to disk.
-func persistSession(store *session.Store, sessionID string, messages []openai.Message, events []agent.ToolEvent) error {
+func persistSession(store *session.Store, sessionID string, messages []llm.Message, events []agent.ToolEvent) error {
 	for _, message := range messages {
 		event := map[string]any{
 			"type":    "message",
@@ -1344,32 +1868,182 @@ func persistSession(store *session.Store, sessionID string, messages []openai.Me
 	return nil
 }
 
-// loadSessionMessages returns previously stored messages for a session.
-func loadSessionMessages(store *session.Store, sessionID string) ([]openai.Message, error) {
-	events, err := store.LoadEvents(sessionID)
+// sessionHistoryPageSize is how many stored messages one page of session
+// history holds. Resuming reads pages until the history fills its token
+// budget; older pages are paged in on demand (for example from the message selector).
+const sessionHistoryPageSize = 100
+
+// defaultHistoryContextWindow stands in for the context window of models
+// whose size is not configured.
+const defaultHistoryContextWindow = 128_000
+
+// sessionHistoryTokenBudget returns how many estimated tokens of resumed
+// history the model's context window holds: three quarters of it, leaving
+// room for the system prompt, tool definitions, the new prompt, and the reply.
+func sessionHistoryTokenBudget(providerCfg *config.ProviderConfig, model string) int {
+	window := defaultHistoryContextWindow
+	if info, ok := config.LookupModelInfo(providerCfg, model); ok && info.ContextWindow > 0 {
+		window = info.ContextWindow
+	}
+	return window * 3 / 4
+}
+
+// estimateMessageTokens approximates the tokens a message takes in a prompt,
+// counting its text and tool call arguments.
+func estimateMessageTokens(message llm.Message) int {
+	tokens := estimateTextTokens(extractMessageText(message))
+	for _, call := range message.ToolCalls {
+		tokens += estimateTextTokens(call.Function.Name) + estimateTextTokens(call.Function.Arguments)
+	}
+	return tokens
+}
+
+// loadResumedSessionMessages returns the most recent stored messages that fit
+// in budget estimated tokens, reading the log a page at a time. The newest
+// page is always loaded, so a budget smaller than one page still resumes.
+func loadResumedSessionMessages(store *session.Store, sessionID string, budget int) ([]llm.Message, sessionHistoryCursor, error) {
+	history, cursor, err := loadSessionMessages(store, sessionID, sessionHistoryPageSize)
 	if err != nil {
-		return nil, err
+		return nil, cursor, err
+	}
+	tokens := 0
+	for _, message := range history {
+		tokens += estimateMessageTokens(message)
+	}
+	for cursor.HasMore && tokens < budget {
+		older, next, err := loadOlderSessionMessages(store, cursor, sessionHistoryPageSize)
+		if err != nil {
+			return nil, cursor, err
+		}
+		pageTokens := 0
+		for _, message := range older {
+			pageTokens += estimateMessageTokens(message)
+		}
+		if tokens+pageTokens > budget {
+			break
+		}
+		history = append(older, history...)
+		tokens += pageTokens
+		cursor = next
+	}
+	return history, cursor, nil
+}
+
+// resumedHistoryNotice tells the user that a resumed session left older
+// messages out of the model's context, or returns "" when all were loaded.
+func resumedHistoryNotice(loaded int, cursor sessionHistoryCursor) string {
+	if !cursor.HasMore {
+		return ""
+	}
+	return fmt.Sprintf("Resumed with the most recent %d messages; older messages stay in the session log but are not sent to the model.", loaded)
+}
+
+// sessionHistoryCursor tracks the portion of a resumed session not yet loaded.
+type sessionHistoryCursor struct {
+	// SessionID identifies the session log that older pages are read from.
+	SessionID string
+	// Before is the byte offset that the next older page must end before.
+	Before int64
+	// HasMore reports whether older messages remain on disk.
+	HasMore bool
+	// Retracted counts messages before Before that a tombstone already read
+	// retracts, so the next page skips them.
+	Retracted int
+}
+
+// loadSessionMessages returns the most recent stored messages for a session.
+// At most limit messages are decoded (0 loads everything); the returned cursor
+// pages in older history via loadOlderSessionMessages.
+func loadSessionMessages(store *session.Store, sessionID string, limit int) ([]llm.Message, sessionHistoryCursor, error) {
+	return loadSessionMessagesPage(store, sessionHistoryCursor{SessionID: sessionID, Before: -1}, limit)
+}
+
+// loadOlderSessionMessages loads the page of messages preceding the cursor.
+func loadOlderSessionMessages(store *session.Store, cursor sessionHistoryCursor, limit int) ([]llm.Message, sessionHistoryCursor, error) {
+	if !cursor.HasMore {
+		return nil, cursor, nil
+	}
+	return loadSessionMessagesPage(store, cursor, limit)
+}
+
+// loadSessionMessagesPage decodes one window of message events ending at the cursor.
+func loadSessionMessagesPage(store *session.Store, cursor sessionHistoryCursor, limit int) ([]llm.Message, sessionHistoryCursor, error) {
+	page, err := store.LoadEventsPage(cursor.SessionID, cursor.Before, limit, isSessionHistoryEvent)
+	if err != nil {
+		return nil, cursor, err
+	}
+	messages, retracted := decodeSessionMessages(page.Events, cursor.Retracted)
+	next := sessionHistoryCursor{SessionID: cursor.SessionID, Before: page.Start, HasMore: page.HasMore, Retracted: retracted}
+
+	// Never start a window on a tool result: its assistant tool call must come along,
+	// otherwise the gateway rejects the orphaned tool message. A page holding only
+	// retracted messages is extended the same way, so it is not mistaken for the end.
+	for next.HasMore && (len(messages) == 0 || messages[0].Role == "tool") {
+		extra, err := store.LoadEventsPage(cursor.SessionID, next.Before, 1, isSessionHistoryEvent)
+		if err != nil {
+			return nil, cursor, err
+		}
+		older, retracted := decodeSessionMessages(extra.Events, next.Retracted)
+		messages = append(older, messages...)
+		next.Before = extra.Start
+		next.HasMore = extra.HasMore
+		next.Retracted = retracted
 	}
-	var messages []openai.Message
-	for _, raw := range events {
+	return messages, next, nil
+}
+
+// isSessionHistoryEvent reports whether a raw session event is a stored
+// message or a tombstone retracting some.
+func isSessionHistoryEvent(raw json.RawMessage) bool {
+	// Cheap substring check first so tool events are skipped without decoding.
+	if !bytes.Contains(raw, []byte(`"type":"message"`)) && !bytes.Contains(raw, []byte(`"type":"`+session.TombstoneEventType+`"`)) {
+		return false
+	}
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return false
+	}
+	return envelope.Type == "message" || envelope.Type == session.TombstoneEventType
+}
+
+// decodeSessionMessages converts raw message events into chat messages,
+// leaving out the ones tombstones retract. Events are walked newest first:
+// retracted is how many messages newer tombstones still retract, and the
+// count left over for older events is returned with the messages.
+func decodeSessionMessages(events []json.RawMessage, retracted int) ([]llm.Message, int) {
+	var messages []llm.Message
+	for index := len(events) - 1; index >= 0; index-- {
+		if tombstone, ok := session.DecodeTombstone(events[index]); ok {
+			retracted += tombstone.Messages
+			continue
+		}
 		var payload struct {
-			Type    string         `json:"type"`
-			Message openai.Message `json:"message"`
+			Type    string      `json:"type"`
+			Message llm.Message `json:"message"`
+		}
+		if err := json.Unmarshal(events[index], &payload); err != nil {
+			continue
 		}
-		if err := json.Unmarshal(raw, &payload); err != nil {
+		if payload.Type != "message" || payload.Message.Role == "" {
 			continue
 		}
-		if payload.Type == "message" && payload.Message.Role != "" {
-			messages = append(messages, payload.Message)
+		if retracted > 0 {
+			retracted--
+			continue
 		}
+		messages = append(messages, payload.Message)
 	}
-	return messages, nil
+	slices.Reverse(messages)
+	return messages, retracted
 }
 
 // writeOutput formats the final response according to the selected format.
 func writeOutput(
 	format string,
 	result *agent.RunResult,
+	historyLen int,
 	replayUser bool,
 	includePartial bool,
 	permissionMode string,
@@ -1391,6 +2065,16 @@ func writeOutput(
 			"usage":      result.TotalUsage,
 			"cost_usd":   result.CostUSD,
 		}
+		if artifacts := resultArtifacts(sessionID); len(artifacts) > 0 {
+			payload["artifacts"] = artifacts
+		}
+		// Verbose results add timing, so slow gateways can be told from slow tools.
+		if opts.Verbose {
+			payload["duration_ms"] = result.Duration.Milliseconds()
+			payload["duration_api_ms"] = result.APIDuration.Milliseconds()
+			payload["turns"] = turnTimings(result)
+		}
+		addJSONIncludes(payload, opts.JSONInclude, result, historyLen, nil)
 		return writeJSON(payload)
 	case "stream-json":
 		return writeStreamJSON(result, replayUser, includePartial, permissionMode, sessionID, model, opts, runner, settings, apiKeySource)
@@ -1441,11 +2125,11 @@ func writeStreamJSON(
 		return err
 	}
 
-	// Build a tool result lookup for error flags.
-	toolErrors := make(map[string]bool)
+	// Build a tool result lookup for error flags and command output.
+	toolResults := make(map[string]agent.ToolEvent)
 	for _, event := range result.Events {
 		if event.Type == "tool_result" && event.ToolID != "" {
-			toolErrors[event.ToolID] = event.IsError
+			toolResults[event.ToolID] = event
 		}
 	}
 
@@ -1484,7 +2168,7 @@ func writeStreamJSON(
 			}
 			// Emit the full assistant message as an Anthropic-style payload.
 			stopReason := deriveStopReason(msg)
-			usage := streamjson.NewMessageUsageFromOpenAI(result.TotalUsage, "")
+			usage := streamjson.NewMessageUsage(result.TotalUsage, "")
 			assistantEvent := streamjson.AssistantEvent{
 				Type:            "assistant",
 				Message:         buildAssistantMessageEnvelope(streamjson.BuildAssistantMessage(msg), model, stopReason, usage),
@@ -1499,18 +2183,20 @@ func writeStreamJSON(
 		case "tool":
 			// Tool results are emitted as synthetic user messages with tool_result blocks.
 			toolText := formatContent(msg.Content)
+			toolEvent := toolResults[msg.ToolCallID]
 			userEvent := streamjson.UserEvent{
 				Type: "user",
 				Message: streamjson.BuildToolResultMessage(
 					msg.ToolCallID,
 					toolText,
-					toolErrors[msg.ToolCallID],
+					toolEvent.IsError,
 				),
 				SessionID:       sessionID,
 				ParentToolUseID: nil,
 				UUID:            streamjson.NewUUID(),
 				IsReplay:        false,
 				IsSynthetic:     false,
+				ToolUseResult:   toolUseResult(toolEvent),
 			}
 			if err := writer.Write(userEvent); err != nil {
 				return err
@@ -1529,10 +2215,12 @@ func writeStreamJSON(
 		Result:            formatContent(result.Final.Content),
 		SessionID:         sessionID,
 		TotalCostUSD:      result.CostUSD,
-		Usage:             streamjson.NewMessageUsageFromOpenAI(result.TotalUsage, streamjson.StandardServiceTier),
+		Usage:             streamjson.NewMessageUsage(result.TotalUsage, streamjson.StandardServiceTier),
 		ModelUsage:        convertModelUsage(model, result.ModelUsage, result.TotalUsage, streamjson.StandardServiceTier),
 		PermissionDenials: []any{},
 		UUID:              streamjson.NewUUID(),
+		Artifacts:         resultArtifacts(sessionID),
+		Turns:             turnTimings(result),
 	}
 	return writer.Write(resultEvent)
 }
@@ -1541,7 +2229,7 @@ func writeStreamJSON(
 func writeStreamJSONError(
 	err error,
 	opts *options,
-	inputMessages []openai.Message,
+	inputMessages []llm.Message,
 	sessionID string,
 	model string,
 	duration time.Duration,
@@ -1608,6 +2296,8 @@ func writeStreamJSONError(
 		PermissionDenials: permissionDenials,
 		UUID:              streamjson.NewUUID(),
 		Errors:            errorsList,
+		ErrorCode:         string(errcode.Of(err)),
+		Artifacts:         resultArtifacts(sessionID),
 	}
 	return writer.Write(resultEvent)
 }
@@ -1622,9 +2312,14 @@ func writeStreamJSONResult(
 	if writer == nil {
 		return fmt.Errorf("stream-json writer is required")
 	}
+	return writer.Write(buildStreamJSONResultEvent(result, sessionID, model))
+}
+
+// buildStreamJSONResultEvent builds the success result event for a finished run.
+func buildStreamJSONResultEvent(result *agent.RunResult, sessionID string, model string) streamjson.ResultEvent {
 	modelUsage := convertModelUsage(model, result.ModelUsage, result.TotalUsage, streamjson.StandardServiceTier)
-	usage := streamjson.NewMessageUsageFromOpenAI(result.TotalUsage, streamjson.StandardServiceTier)
-	resultEvent := streamjson.ResultEvent{
+	usage := streamjson.NewMessageUsage(result.TotalUsage, streamjson.StandardServiceTier)
+	return streamjson.ResultEvent{
 		Type:              "result",
 		Subtype:           "success",
 		IsError:           false,
@@ -1638,8 +2333,9 @@ func writeStreamJSONResult(
 		ModelUsage:        modelUsage,
 		PermissionDenials: []any{},
 		UUID:              streamjson.NewUUID(),
+		Artifacts:         resultArtifacts(sessionID),
+		Turns:             turnTimings(result),
 	}
-	return writer.Write(resultEvent)
 }
 
 // writeStreamJSONErrorResult emits a stream-json error result event without status.
@@ -1677,6 +2373,8 @@ func writeStreamJSONErrorResult(
 		PermissionDenials: permissionDenials,
 		UUID:              streamjson.NewUUID(),
 		Errors:            errorsList,
+		ErrorCode:         string(errcode.Of(err)),
+		Artifacts:         resultArtifacts(sessionID),
 	}
 	return writer.Write(resultEvent)
 }
@@ -1742,7 +2440,7 @@ func mapFinishReasonToStopReason(reason string) string {
 
 // deriveStopReason picks a best-effort stop reason for non-streaming messages.
 // Without stream metadata, tool calls are the only signal of a tool_use stop.
-func deriveStopReason(message openai.Message) string {
+func deriveStopReason(message llm.Message) string {
 	if len(message.ToolCalls) > 0 {
 		return "tool_use"
 	}
@@ -1753,28 +2451,27 @@ func deriveStopReason(message openai.Message) string {
 // The fallback usage is used when the gateway does not provide per-model breakdowns.
 func convertModelUsage(
 	model string,
-	usageMap map[string]openai.Usage,
-	fallback openai.Usage,
+	usageMap map[string]llm.Usage,
+	fallback llm.Usage,
 	serviceTier string,
 ) map[string]streamjson.MessageUsage {
 	if len(usageMap) == 0 {
 		if model == "" {
 			return map[string]streamjson.MessageUsage{}
 		}
-		return map[string]streamjson.MessageUsage{model: *streamjson.NewMessageUsageFromOpenAI(fallback, serviceTier)}
+		return map[string]streamjson.MessageUsage{model: *streamjson.NewMessageUsage(fallback, serviceTier)}
 	}
 	converted := make(map[string]streamjson.MessageUsage, len(usageMap))
 	for key, usage := range usageMap {
-		converted[key] = *streamjson.NewMessageUsageFromOpenAI(usage, serviceTier)
+		converted[key] = *streamjson.NewMessageUsage(usage, serviceTier)
 	}
 	return converted
 }
 
 // authErrorInfo detects authentication failures and returns the Claude message.
-// It recognizes common 401/403 API errors and returns a user-facing prompt.
+// It recognizes errcode.ErrAuth (401/403 API errors) and returns a user-facing prompt.
 func authErrorInfo(err error) (string, bool) {
-	var apiErr *openai.APIError
-	if errors.As(err, &apiErr) && (apiErr.StatusCode == 401 || apiErr.StatusCode == 403) {
+	if errors.Is(err, errcode.ErrAuth) {
 		return "Invalid API key · Please run /login", true
 	}
 	return "", false
@@ -1816,6 +2513,7 @@ func emitAuthErrorEvents(
 		ModelUsage:        map[string]streamjson.MessageUsage{},
 		PermissionDenials: []any{},
 		UUID:              streamjson.NewUUID(),
+		ErrorCode:         string(errcode.Auth),
 	}
 	return writer.Write(resultEvent)
 }
@@ -1845,9 +2543,17 @@ func extractPermissionDenials(err error) []any {
 	if err == nil {
 		return []any{}
 	}
-	if errors.Is(err, agent.ErrToolDenied) {
+	var confirmErr *confirmationRequiredError
+	if errors.As(err, &confirmErr) {
+		return []any{permissionDenial{
+			ToolName: confirmErr.ToolName,
+			Reason:   "requires_confirmation",
+		}}
+	}
+	var deniedErr *errcode.ToolDeniedError
+	if errors.As(err, &deniedErr) {
 		return []any{permissionDenial{
-			ToolName: extractDeniedToolName(err),
+			ToolName: deniedErr.ToolName,
 			Reason:   "user_denied",
 		}}
 	}
@@ -1859,24 +2565,14 @@ func extractPermissionDenials(err error) []any {
 	return []any{}
 }
 
-// extractDeniedToolName pulls the tool identifier from the error text when possible.
-func extractDeniedToolName(err error) string {
-	if err == nil {
-		return ""
-	}
-	message := err.Error()
-	parts := strings.SplitN(message, ":", 2)
-	if len(parts) < 2 {
-		return ""
-	}
-	return strings.TrimSpace(parts[1])
-}
-
 // extractMessageText returns the message text for partial streaming.
-func extractMessageText(message openai.Message) string {
+func extractMessageText(message llm.Message) string {
 	if text, ok := message.Content.(string); ok {
 		return text
 	}
+	if llm.HasImages(message.Content) {
+		return llm.ContentText(message.Content)
+	}
 	return streamjson.ExtractText(message.Content)
 }
 
@@ -1903,15 +2599,15 @@ func formatContent(content any) string {
 }
 
 // ensureSystem injects a system prompt if one is not present.
-func ensureSystem(messages []openai.Message, prompt string) []openai.Message {
+func ensureSystem(messages []llm.Message, prompt string) []llm.Message {
 	if prompt == "" {
 		return messages
 	}
 	if len(messages) > 0 && messages[0].Role == "system" {
 		return messages
 	}
-	system := openai.Message{Role: "system", Content: prompt}
-	return append([]openai.Message{system}, messages...)
+	system := llm.Message{Role: "system", Content: prompt}
+	return append([]llm.Message{system}, messages...)
 }
 
 // splitList parses comma/space-separated lists.
@@ -1946,37 +2642,71 @@ func splitListArgs(values []string) []string {
 }
 
 // buildTaskExecutor wires Task tool execution to a new agent run.
-func buildTaskExecutor(runner *agent.Runner, opts *options, baseModel string) tools.TaskExecutor {
+func buildTaskExecutor(runner *agent.Runner, opts *options, providerCfg *config.ProviderConfig, baseModel string) tools.TaskExecutor {
 	if runner == nil {
 		return nil
 	}
+	// Parallel subagents share the parent's authorizer, so prompts are serialized
+	// to keep interactive approvals from overlapping.
+	var authorizeMu sync.Mutex
 	return tools.TaskExecutorFunc(func(ctx context.Context, request tools.TaskRequest) (tools.TaskResult, error) {
 		if runner.Client == nil {
 			return tools.TaskResult{}, fmt.Errorf("task executor requires a client")
 		}
 
-		model := resolveTaskModel(request.Model, opts, baseModel)
+		definition, hasDefinition, err := resolveTaskAgent(request.SubagentType, opts)
+		if err != nil {
+			return tools.TaskResult{}, err
+		}
+
+		requestedModel := request.Model
+		if requestedModel == "" && hasDefinition && !strings.EqualFold(definition.Model, "inherit") {
+			requestedModel = config.ResolveModel(providerCfg, definition.Model, "")
+		}
+		model := resolveTaskModel(requestedModel, opts, baseModel)
 		if model == "" {
 			return tools.TaskResult{}, fmt.Errorf("task model is required")
 		}
 
 		systemPrompt := strings.TrimSpace(request.SystemPrompt)
+		if systemPrompt == "" && hasDefinition {
+			systemPrompt = definition.Prompt
+		}
 		if systemPrompt == "" {
 			systemPrompt = resolveSystemPrompt(opts, runner)
 		}
 
 		messages := request.Messages
 		if len(messages) == 0 {
-			messages = []openai.Message{{Role: "user", Content: request.Prompt}}
+			messages = []llm.Message{{Role: "user", Content: request.Prompt}}
 		}
 		if len(messages) > 0 && messages[0].Role == "system" {
 			systemPrompt = ""
 		}
 
 		taskRunner := *runner
-		taskRunner.ToolContext = runner.ToolContext
+		taskRunner.ToolContext = runner.ToolContext.Clone()
 		taskRunner.ToolContext.TaskDepth = runner.ToolContext.TaskDepth + 1
+		taskRunner.ToolContext.TaskLineage = request.Lineage
+		// Only the top-level run can pause for user input; subagents report an error instead.
+		taskRunner.ToolContext.DeferUserInput = false
+		// Status dumps describe the top-level run; the parent shows the Task call in flight.
+		taskRunner.Status = nil
 		taskRunner.ToolContext.TaskExecutor = runner.ToolContext.TaskExecutor
+		taskRunner.StopHook = opts.LifecycleHooks.stopHook("SubagentStop")
+		if hasDefinition && len(definition.Tools) > 0 {
+			taskRunner.ToolRunner = restrictToolRunner(runner.ToolRunner, definition.Tools)
+		}
+		if runner.AuthorizeTool != nil {
+			taskRunner.AuthorizeTool = func(toolName string, args json.RawMessage) (bool, error) {
+				authorizeMu.Lock()
+				defer authorizeMu.Unlock()
+				return runner.AuthorizeTool(toolName, args)
+			}
+		}
+		if request.MaxBudgetUSD > 0 {
+			taskRunner.MaxBudgetUSD = request.MaxBudgetUSD
+		}
 
 		if request.MaxTurns > 0 {
 			taskRunner.MaxTurns = request.MaxTurns
@@ -1996,10 +2726,65 @@ func buildTaskExecutor(runner *agent.Runner, opts *options, baseModel string) to
 		return tools.TaskResult{
 			Output:   formatContent(result.Final.Content),
 			Metadata: meta,
+			Usage: &tools.ToolUsage{
+				CostUSD:    result.CostUSD,
+				ModelUsage: result.ModelUsage,
+			},
 		}, nil
 	})
 }
 
+// resolveMaxThinkingTokens returns the thinking budget from --max-thinking-tokens,
+// falling back to MAX_THINKING_TOKENS like Claude Code.
+func resolveMaxThinkingTokens(opts *options) int {
+	if opts != nil && opts.MaxThinkingTokens > 0 {
+		return opts.MaxThinkingTokens
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv("MAX_THINKING_TOKENS")))
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
+
+// resolveTaskAgent maps a Task subagent_type to a loaded agent definition.
+// Built-in agent types run with the default task configuration; unknown types fail
+// loudly so a typo does not silently fall back to a general-purpose agent.
+func resolveTaskAgent(subagentType string, opts *options) (config.AgentDefinition, bool, error) {
+	subagentType = strings.TrimSpace(subagentType)
+	if subagentType == "" {
+		return config.AgentDefinition{}, false, nil
+	}
+	if opts != nil {
+		if definition, ok := config.FindAgentDefinition(opts.AgentDefinitions, subagentType); ok {
+			return definition, true, nil
+		}
+	}
+	for _, name := range defaultAgentList() {
+		if strings.EqualFold(name, subagentType) {
+			return config.AgentDefinition{}, false, nil
+		}
+	}
+	return config.AgentDefinition{}, false, fmt.Errorf("unknown subagent_type %q; define it in .claude/agents/%s.md", subagentType, subagentType)
+}
+
+// restrictToolRunner returns a runner limited to the named tools.
+func restrictToolRunner(base *tools.Runner, names []string) *tools.Runner {
+	if base == nil {
+		return nil
+	}
+	var selected []tools.Tool
+	for _, name := range normalizeToolList(names) {
+		if tool, ok := base.Tools[name]; ok {
+			selected = append(selected, tool)
+		}
+	}
+	restricted := tools.NewRunner(selected)
+	restricted.MaxResultBytes = base.MaxResultBytes
+	restricted.Redactor = base.Redactor
+	return restricted
+}
+
 // resolveTaskModel picks a model for Task execution.
 func resolveTaskModel(requested string, opts *options, baseModel string) string {
 	requested = strings.TrimSpace(requested)
@@ -2059,6 +2844,8 @@ func normalizeToolList(names []string) []string {
 			normalized = append(normalized, "Skill")
 		case "todowrite", "todo-write", "todo_write", "todo":
 			normalized = append(normalized, "TodoWrite")
+		case "runpython", "run-python", "run_python", "python":
+			normalized = append(normalized, tools.RunPythonToolName)
 		default:
 			normalized = append(normalized, name)
 		}
@@ -2088,11 +2875,17 @@ func parsePermissionMode(value string) tools.PermissionMode {
 
 // isRetryableError reports whether an error should trigger fallback.
 func isRetryableError(err error) bool {
-	var apiErr *openai.APIError
-	if errors.As(err, &apiErr) {
-		return apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
+	return errors.Is(err, errcode.ErrRateLimited) || errors.Is(err, errcode.ErrProviderUnavailable)
+}
+
+// contextTrimmedNotice tells the user which old tool results were dropped to
+// keep a turn going after a context overflow.
+func contextTrimmedNotice(dropped []agent.DroppedToolResult) string {
+	chars := 0
+	for _, result := range dropped {
+		chars += result.Chars
 	}
-	return false
+	return fmt.Sprintf("Context window full · dropped %d old tool results (%d characters) and retried", len(dropped), chars)
 }
 
 // mustCwd returns cwd or "." if unavailable.
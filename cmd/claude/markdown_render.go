@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+)
+
+const (
+	// maxMarkdownBytes is the largest message rendered as markdown.
+	maxMarkdownBytes = 64 << 10
+	// maxMarkdownLines caps message lines, which bounds huge tables and lists.
+	maxMarkdownLines = 1500
+	// maxMarkdownIndent caps leading whitespace, which bounds deeply nested lists and quotes.
+	maxMarkdownIndent = 48
+	// markdownRenderTimeout bounds one render before falling back to plain text.
+	markdownRenderTimeout = 500 * time.Millisecond
+	// maxMarkdownCacheEntries bounds the rendered-message cache.
+	maxMarkdownCacheEntries = 512
+)
+
+// markdownRenderer renders chat messages with glamour, falling back to plain
+// text per message when a message is too large or rendering fails or times out.
+// Results are cached because the chat view re-renders every message on refresh.
+// It is used from the TUI update loop only.
+type markdownRenderer struct {
+	// render converts markdown; nil renders everything as plain text.
+	render func(string) (string, error)
+	// newRender builds a fresh render function after a timeout, since the
+	// abandoned render may still be using the old one.
+	newRender func() func(string) (string, error)
+	// timeout bounds one render.
+	timeout time.Duration
+	// plain disables markdown for the session (/plain).
+	plain bool
+	// cache maps message content to its rendered form.
+	cache map[string]string
+}
+
+// newMarkdownRenderer builds the glamour-backed renderer used by the TUI,
+// highlighting fenced code with the code style.
+func newMarkdownRenderer(code codeStyle) *markdownRenderer {
+	newRender := func() func(string) (string, error) {
+		options := []glamour.TermRendererOption{glamour.WithStyles(code.markdownStyles())}
+		if code.Formatter != "" {
+			options = append(options, glamour.WithChromaFormatter(code.Formatter))
+		}
+		renderer, err := glamour.NewTermRenderer(options...)
+		if err != nil {
+			return nil
+		}
+		return func(content string) (string, error) {
+			return renderer.Render(labelCodeFences(content))
+		}
+	}
+	return &markdownRenderer{render: newRender(), newRender: newRender, timeout: markdownRenderTimeout}
+}
+
+// Render returns content as terminal markdown, or as plain text when
+// rendering is off, unavailable, or fails for this message.
+func (r *markdownRenderer) Render(content string) string {
+	if r == nil || r.plain || r.render == nil {
+		return content
+	}
+	if rendered, ok := r.cache[content]; ok {
+		return rendered
+	}
+	rendered := r.renderWithFallback(content)
+	if r.cache == nil || len(r.cache) >= maxMarkdownCacheEntries {
+		r.cache = map[string]string{}
+	}
+	r.cache[content] = rendered
+	return rendered
+}
+
+// SetPlain turns markdown rendering off or on for the session.
+func (r *markdownRenderer) SetPlain(plain bool) {
+	if r != nil {
+		r.plain = plain
+	}
+}
+
+// renderWithFallback applies the size limits and the render timeout.
+func (r *markdownRenderer) renderWithFallback(content string) string {
+	if reason := markdownTooComplex(content); reason != "" {
+		return plainMarkdownFallback(content, reason)
+	}
+	type result struct {
+		rendered string
+		err      error
+	}
+	done := make(chan result, 1)
+	render := r.render
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- result{err: fmt.Errorf("renderer panic: %v", recovered)}
+			}
+		}()
+		rendered, err := render(content)
+		done <- result{rendered: rendered, err: err}
+	}()
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	select {
+	case outcome := <-done:
+		if outcome.err != nil {
+			return plainMarkdownFallback(content, "markdown rendering failed")
+		}
+		return outcome.rendered
+	case <-timer.C:
+		if r.newRender != nil {
+			r.render = r.newRender()
+		}
+		return plainMarkdownFallback(content, "markdown rendering timed out")
+	}
+}
+
+// markdownTooComplex returns why content should skip markdown rendering, or "".
+func markdownTooComplex(content string) string {
+	if len(content) > maxMarkdownBytes {
+		return fmt.Sprintf("message over %d KB", maxMarkdownBytes>>10)
+	}
+	lines := strings.Split(content, "\n")
+	if len(lines) > maxMarkdownLines {
+		return fmt.Sprintf("message over %d lines", maxMarkdownLines)
+	}
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t>")
+		// Indentation inside fenced code is content, not nesting.
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && len(line)-len(trimmed) > maxMarkdownIndent && trimmed != "" {
+			return "deeply nested markdown"
+		}
+	}
+	return ""
+}
+
+// plainMarkdownFallback shows content unrendered with a note saying why.
+func plainMarkdownFallback(content string, reason string) string {
+	return content + "\n(shown as plain text: " + reason + ")"
+}
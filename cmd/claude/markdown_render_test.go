@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestMarkdownRendererCachesRenders verifies each message is rendered once.
+func TestMarkdownRendererCachesRenders(testingHandle *testing.T) {
+	calls := 0
+	renderer := &markdownRenderer{timeout: time.Second, render: func(content string) (string, error) {
+		calls++
+		return "<" + content + ">", nil
+	}}
+	testutil.RequireEqual(testingHandle, renderer.Render("# hi"), "<# hi>", "rendered")
+	testutil.RequireEqual(testingHandle, renderer.Render("# hi"), "<# hi>", "cached")
+	testutil.RequireEqual(testingHandle, calls, 1, "render calls")
+}
+
+// TestMarkdownRendererFallsBack verifies errors, panics, and timeouts fall back to plain text.
+func TestMarkdownRendererFallsBack(testingHandle *testing.T) {
+	failing := &markdownRenderer{timeout: time.Second, render: func(string) (string, error) { return "", errors.New("boom") }}
+	testutil.RequireEqual(testingHandle, failing.Render("*x*"), "*x*\n(shown as plain text: markdown rendering failed)", "error")
+
+	panicking := &markdownRenderer{timeout: time.Second, render: func(string) (string, error) { panic("nested too deep") }}
+	testutil.RequireEqual(testingHandle, panicking.Render("*x*"), "*x*\n(shown as plain text: markdown rendering failed)", "panic")
+
+	release := make(chan struct{})
+	defer close(release)
+	replaced := false
+	hanging := &markdownRenderer{
+		timeout: 10 * time.Millisecond,
+		render: func(string) (string, error) {
+			<-release
+			return "late", nil
+		},
+		newRender: func() func(string) (string, error) {
+			replaced = true
+			return func(content string) (string, error) { return "fresh " + content, nil }
+		},
+	}
+	testutil.RequireEqual(testingHandle, hanging.Render("slow"), "slow\n(shown as plain text: markdown rendering timed out)", "timeout")
+	testutil.RequireTrue(testingHandle, replaced, "renderer replaced after a timeout")
+	testutil.RequireEqual(testingHandle, hanging.Render("next"), "fresh next", "later messages render again")
+}
+
+// TestMarkdownTooComplex verifies size, line, and nesting limits.
+func TestMarkdownTooComplex(testingHandle *testing.T) {
+	testutil.RequireEqual(testingHandle, markdownTooComplex("# Title\n\n- item\n  - nested"), "", "ordinary markdown")
+	testutil.RequireEqual(testingHandle, markdownTooComplex(strings.Repeat("x", maxMarkdownBytes+1)), "message over 64 KB", "bytes")
+	testutil.RequireEqual(testingHandle, markdownTooComplex(strings.Repeat("| a | b |\n", maxMarkdownLines)), "message over 1500 lines", "huge table")
+	deep := strings.Repeat(" ", maxMarkdownIndent+2) + "- deep"
+	testutil.RequireEqual(testingHandle, markdownTooComplex(deep), "deeply nested markdown", "nesting")
+	testutil.RequireEqual(testingHandle, markdownTooComplex("```\n"+deep+"\n```"), "", "indented code is fine")
+
+	calls := 0
+	renderer := &markdownRenderer{timeout: time.Second, render: func(content string) (string, error) { calls++; return content, nil }}
+	testutil.RequireEqual(testingHandle, renderer.Render(deep), deep+"\n(shown as plain text: deeply nested markdown)", "skips rendering")
+	testutil.RequireEqual(testingHandle, calls, 0, "renderer not called")
+}
+
+// TestTogglePlainMarkdown verifies /plain switches rendering off and back on.
+func TestTogglePlainMarkdown(testingHandle *testing.T) {
+	model := &tuiModel{markdownRenderer: &markdownRenderer{timeout: time.Second, render: func(string) (string, error) { return "rendered", nil }}}
+	testutil.RequireEqual(testingHandle, model.renderMarkdown("**x**"), "rendered", "markdown on")
+
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(model.togglePlainMarkdown(""), "Markdown rendering off"), "toggle off")
+	testutil.RequireEqual(testingHandle, model.renderMarkdown("**x**"), "**x**", "plain text")
+	testutil.RequireEqual(testingHandle, model.togglePlainMarkdown("off"), "Markdown rendering on.", "explicit off")
+	testutil.RequireEqual(testingHandle, model.renderMarkdown("**x**"), "rendered", "markdown back on")
+	testutil.RequireEqual(testingHandle, model.togglePlainMarkdown("maybe"), "Usage: /plain [on|off]", "usage")
+}
+
+// TestTogglePlainMarkdownRerendersShownMessages verifies /plain changes the
+// messages already on screen, not just new ones.
+func TestTogglePlainMarkdownRerendersShownMessages(testingHandle *testing.T) {
+	// Arrange
+	model := &tuiModel{width: 80, theme: defaultTUITheme(), markdownRenderer: &markdownRenderer{timeout: time.Second, render: func(content string) (string, error) {
+		return "rendered " + strings.TrimSpace(content), nil
+	}}}
+	model.chatMessages = []tuiMessage{{Kind: tuiMessageAssistantText, Role: "assistant", Content: "**bold**"}}
+	model.refreshChat()
+	before := model.renderCachedMessage(0, model.chatMessages[0])
+
+	// Act
+	model.togglePlainMarkdown("on")
+	after := model.renderCachedMessage(0, model.chatMessages[0])
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.Contains(before, "rendered **bold**"), "markdown render: "+before)
+	testutil.RequireTrue(testingHandle, !strings.Contains(after, "rendered") && strings.Contains(after, "**bold**"), "plain render: "+after)
+}
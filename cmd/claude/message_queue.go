@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tuiQueuedMessage is input submitted while a response was still running.
+type tuiQueuedMessage struct {
+	// Value is the submitted text, with paste placeholders resolved.
+	Value string
+	// Mode is the input mode it was typed in.
+	Mode tuiInputMode
+}
+
+// queueInput queues the current input while a run is in progress. It reports
+// false when there is nothing to queue.
+func (m *tuiModel) queueInput() bool {
+	value := strings.TrimSpace(m.resolvePastedInput(m.input.Value()))
+	if value == "" {
+		return false
+	}
+	m.queuedMessages = append(m.queuedMessages, tuiQueuedMessage{Value: value, Mode: m.inputMode})
+	m.input.SetValue("")
+	m.setInputMode(tuiInputPrompt)
+	m.clearInputHints()
+	m.statusText = "Message queued; it is sent when the response finishes. Press Escape to interrupt and send it now."
+	return true
+}
+
+// interruptForQueued cancels the running response so the queued messages are
+// sent right away.
+func (m *tuiModel) interruptForQueued() {
+	m.interruptRun("Interrupted; sending queued message.")
+}
+
+// sendQueued submits queued messages once the run has ended, in order, until
+// one of them starts a new run; the rest wait for that run. The draft in the
+// input box is kept.
+func (m *tuiModel) sendQueued() tea.Cmd {
+	var cmds []tea.Cmd
+	for !m.running && len(m.queuedMessages) > 0 {
+		next := m.queuedMessages[0]
+		m.queuedMessages = m.queuedMessages[1:]
+		draft, draftMode := m.input.Value(), m.inputMode
+		m.input.SetValue(next.Value)
+		m.setInputMode(next.Mode)
+		// Queued messages were already submitted once, so size checks are skipped.
+		m.largeMessageApproved = true
+		_, cmd := m.submitInput()
+		cmds = append(cmds, cmd)
+		m.input.SetValue(draft)
+		m.input.CursorEnd()
+		m.setInputMode(draftMode)
+	}
+	return tea.Batch(cmds...)
+}
+
+// restoreQueued moves queued messages back into the input box, ahead of the
+// draft, so a cancelled run does not send them.
+func (m *tuiModel) restoreQueued() {
+	if len(m.queuedMessages) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(m.queuedMessages)+1)
+	for _, queued := range m.queuedMessages {
+		value := queued.Value
+		if queued.Mode == tuiInputBash {
+			value = "!" + value
+		}
+		parts = append(parts, value)
+	}
+	if draft := m.input.Value(); draft != "" {
+		parts = append(parts, draft)
+	}
+	m.queuedMessages = nil
+	m.input.SetValue(strings.Join(parts, "\n"))
+	m.input.CursorEnd()
+}
+
+// renderQueuedMessages lists queued messages above the input box.
+func (m *tuiModel) renderQueuedMessages() string {
+	if len(m.queuedMessages) == 0 {
+		return ""
+	}
+	style := lipgloss.NewStyle().Foreground(m.theme.Secondary)
+	lines := make([]string, 0, len(m.queuedMessages))
+	for _, queued := range m.queuedMessages {
+		preview, _, _ := strings.Cut(queued.Value, "\n")
+		if queued.Mode == tuiInputBash {
+			preview = "!" + preview
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("  queued: %s", truncateForDisplay(preview, maxInt(10, m.width-12)))))
+	}
+	return lipgloss.NewStyle().MarginTop(1).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestQueuedMessagesSendAfterRun verifies input submitted during a run is
+// queued, sent as the next turn when the run ends, and keeps the draft.
+func TestQueuedMessagesSendAfterRun(testingHandle *testing.T) {
+	// Arrange a model with a run in progress.
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	model.running = true
+	model.input.SetValue("also check the tests")
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// Act: queue a message, start a draft, then end the run.
+	model.Update(enter)
+	queued := len(model.queuedMessages)
+	model.input.SetValue("draft")
+	model.Update(streamErrorMsg{Err: context.Canceled})
+
+	// Assert.
+	testutil.RequireEqual(testingHandle, queued, 1, "queued while running")
+	testutil.RequireEqual(testingHandle, len(model.queuedMessages), 0, "queue drained")
+	testutil.RequireTrue(testingHandle, model.running, "queued message started a run")
+	last := model.history[len(model.history)-1]
+	testutil.RequireEqual(testingHandle, last, llm.Message{Role: "user", Content: "also check the tests"}, "next turn")
+	testutil.RequireEqual(testingHandle, model.input.Value(), "draft", "draft kept")
+}
+
+// TestQueuedMessagesInterruptOrRestore verifies Escape interrupts the run for
+// queued messages and Ctrl+C returns them to the input instead.
+func TestQueuedMessagesInterruptOrRestore(testingHandle *testing.T) {
+	// Arrange.
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+	cancelled := 0
+	newRunning := func() *tuiModel {
+		model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+		model.running = true
+		model.cancel = func() { cancelled++ }
+		model.queuedMessages = []tuiQueuedMessage{{Value: "stop, use the v2 API", Mode: tuiInputPrompt}, {Value: "git status", Mode: tuiInputBash}}
+		return model
+	}
+	interrupted := newRunning()
+	restored := newRunning()
+	restored.input.SetValue("draft")
+
+	// Act.
+	interrupted.Update(esc)
+	afterOne := cancelled
+	interrupted.Update(esc)
+	restored.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	// Assert.
+	testutil.RequireEqual(testingHandle, afterOne, 1, "Escape interrupts")
+	testutil.RequireEqual(testingHandle, cancelled, 2, "Escape and Ctrl+C cancel once each")
+	testutil.RequireEqual(testingHandle, len(interrupted.queuedMessages), 2, "queue kept for sending")
+	testutil.RequireEqual(testingHandle, len(restored.queuedMessages), 0, "queue restored")
+	testutil.RequireEqual(testingHandle, restored.input.Value(), "stop, use the v2 API\n!git status\ndraft", "restored input")
+}
@@ -0,0 +1,68 @@
+package main
+
+import "fmt"
+
+// tuiResend is a previous user message being edited in the input box after
+// it was chosen in the message selector. Submitting rewinds the conversation
+// to just before it and sends the edited text in its place.
+type tuiResend struct {
+	// Drop counts the history messages from the chosen one to the end. It is
+	// counted from the end so paging in older history does not move it.
+	Drop int
+	// Draft and DraftMode are the input box contents before editing began,
+	// restored when the edit is cancelled.
+	Draft     string
+	DraftMode tuiInputMode
+}
+
+// beginResend puts the selected message in the input box for editing. The
+// conversation is left as it is until the edit is submitted.
+func (m *tuiModel) beginResend(selected tuiSelectorItem) {
+	m.resend = &tuiResend{Drop: len(m.history) - selected.Index, Draft: m.input.Value(), DraftMode: m.inputMode}
+	m.input.SetValue(selected.Input)
+	m.input.CursorEnd()
+	m.setInputMode(selected.Mode)
+	m.syncInputState()
+}
+
+// cancelResend abandons the edit and restores the previous input.
+func (m *tuiModel) cancelResend() {
+	resend := m.resend
+	m.resend = nil
+	m.input.SetValue(resend.Draft)
+	m.input.CursorEnd()
+	m.setInputMode(resend.DraftMode)
+	m.syncInputState()
+	m.statusText = "Edit cancelled."
+}
+
+// applyResend rewinds the conversation to just before the edited message so
+// the submitted text replaces it. With session persistence the rewound
+// conversation continues in a new session and the original keeps its thread;
+// without it only the history in memory is rewound.
+func (m *tuiModel) applyResend() error {
+	drop := m.resend.Drop
+	notice := ""
+	if m.store != nil && m.sessionID != "" {
+		parent := m.sessionID
+		forkID, err := m.forkSession(drop)
+		if err != nil {
+			return fmt.Errorf("fork failed: %w", err)
+		}
+		notice = formatForkNotice(parent, forkID)
+	}
+	m.resend = nil
+	m.history = m.history[:len(m.history)-drop]
+	m.persisted = min(m.persisted, len(m.history))
+	m.pendingPermission = nil
+	m.toolLines = nil
+	m.toolView.SetContent("No tool activity yet.")
+	m.bootstrapHistory()
+	if notice != "" {
+		m.appendSystemMessage(notice)
+	}
+	return nil
+}
+
+// resendHint is the footer shown while a previous message is being edited.
+const resendHint = "Editing a previous message · Enter to re-run from there · Esc to cancel"
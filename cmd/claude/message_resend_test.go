@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSelectorEditAndResend verifies Enter in the message selector edits the
+// chosen message in place, Esc cancels the edit, and sending it re-runs from
+// that point in a new session that keeps the original untouched.
+func TestSelectorEditAndResend(testingHandle *testing.T) {
+	tests := []struct {
+		name    string
+		persist bool
+	}{
+		{name: "with session persistence", persist: true},
+		{name: "without session persistence"},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Arrange two exchanges and a half-typed draft.
+			testingHandle.Chdir(testingHandle.TempDir())
+			stored := []llm.Message{
+				{Role: "user", Content: "first"}, {Role: "assistant", Content: "one"},
+				{Role: "user", Content: "second"}, {Role: "assistant", Content: "two"},
+			}
+			var store *session.Store
+			if tt.persist {
+				store = &session.Store{BaseDir: testingHandle.TempDir()}
+				testutil.RequireNoError(testingHandle, persistSession(store, "parent", stored, nil), "persist parent")
+			}
+			model := newTUIModel(&options{}, nil, stored, sessionHistoryCursor{}, "sys", "model", "parent", store)
+			model.input.SetValue("draft")
+			selectSecond := func() {
+				model.openMessageSelector()
+				model.selectorIndex = 1
+				model.handleSelectorKey(tea.KeyMsg{Type: tea.KeyEnter})
+			}
+
+			// Act: start an edit and cancel it, then edit again and send.
+			selectSecond()
+			editing := model.input.Value()
+			model.handleKey(tea.KeyMsg{Type: tea.KeyEsc})
+			cancelled := model.input.Value()
+			selectSecond()
+			model.input.SetValue("second, but shorter")
+			model.submitInput()
+
+			// Assert
+			testutil.RequireEqual(testingHandle, editing, "second", "message to edit")
+			testutil.RequireEqual(testingHandle, cancelled, "draft", "draft restored on cancel")
+			testutil.RequireTrue(testingHandle, model.resend == nil, "edit applied")
+			testutil.RequireTrue(testingHandle, model.running, "re-run started")
+			want := []llm.Message{{Role: "system", Content: "sys"}, stored[0], stored[1], {Role: "user", Content: "second, but shorter"}}
+			testutil.RequireEqual(testingHandle, model.history, want, "history rewound and resent")
+			if !tt.persist {
+				testutil.RequireEqual(testingHandle, model.sessionID, "parent", "no session without persistence")
+				return
+			}
+			testutil.RequireTrue(testingHandle, model.sessionID != "parent", "re-run continues in a new session")
+			forked, _, err := loadSessionMessages(store, model.sessionID, 0)
+			testutil.RequireNoError(testingHandle, err, "load fork")
+			testutil.RequireEqual(testingHandle, forked, stored[:2], "fork holds the messages before the edited one")
+			parent, _, err := loadSessionMessages(store, "parent", 0)
+			testutil.RequireNoError(testingHandle, err, "load parent")
+			testutil.RequireEqual(testingHandle, parent, stored, "original thread kept")
+		})
+	}
+}
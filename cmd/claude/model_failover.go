@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// failoverThreshold is how many runs in a row must fail with the provider
+// overloaded, unavailable, or rate limiting before the TUI offers a fallback.
+const failoverThreshold = 2
+
+// tuiFailover is a pending offer to switch to the fallback model.
+type tuiFailover struct {
+	// From is the failing model.
+	From string
+	// To is the fallback model offered.
+	To string
+}
+
+// offerFailover counts a failed run and, after failoverThreshold provider
+// failures in a row, asks whether to switch to the fallback model. It reports
+// whether the question is now waiting for an answer.
+func (m *tuiModel) offerFailover(err error) bool {
+	if !isRetryableError(err) {
+		m.providerFailures = 0
+		return false
+	}
+	m.providerFailures++
+	if m.providerFailures < failoverThreshold {
+		return false
+	}
+	fallback := m.fallbackModel()
+	if fallback == "" {
+		m.statusText += fmt.Sprintf(" (%s failed %d times in a row; /model switches models)", m.model, m.providerFailures)
+		return false
+	}
+	m.appendSystemMessage(fmt.Sprintf("%s failed %d times in a row: %s", m.model, m.providerFailures, formatInteractiveError(err)))
+	m.refreshChat()
+	m.closeTranscript()
+	m.failover = &tuiFailover{From: m.model, To: fallback}
+	m.statusText = fmt.Sprintf("Switch to %s for the rest of the session and retry? [y/N]", fallback)
+	return true
+}
+
+// fallbackModel returns the model to offer: --fallback-model, else the
+// provider's default model, as long as it is not the failing one.
+func (m *tuiModel) fallbackModel() string {
+	var providerCfg *config.ProviderConfig
+	var candidates []string
+	if m.opts != nil {
+		providerCfg = m.opts.ProviderConfig
+		candidates = append(candidates, m.opts.FallbackModel)
+	}
+	if providerCfg != nil {
+		candidates = append(candidates, providerCfg.DefaultModel)
+	}
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if resolved := config.ResolveModel(providerCfg, candidate, ""); resolved != "" && resolved != m.model {
+			return resolved
+		}
+	}
+	return ""
+}
+
+// handleFailoverKey answers the failover offer. Accepting switches models
+// and retries the failed prompt. Other keys dismiss the offer and move queued
+// prompts back into the input box, since nothing would send them.
+func (m *tuiModel) handleFailoverKey(key string) (bool, tea.Cmd) {
+	failover := m.failover
+	switch strings.ToLower(key) {
+	case "y":
+		m.failover = nil
+		m.appendSystemMessage(m.switchModel(failover.To))
+		m.refreshChat()
+		if m.model != failover.To || !endsWithUserPrompt(m.history) {
+			m.statusText = ""
+			return true, m.sendQueued()
+		}
+		return true, m.startRun(m.runPrompt)
+	case "n", "esc", "enter":
+		m.failover = nil
+		m.statusText = "Staying on " + failover.From + "."
+		return true, m.sendQueued()
+	}
+	m.failover = nil
+	m.statusText = ""
+	m.restoreQueued()
+	return false, nil
+}
+
+// endsWithUserPrompt reports whether history ends with a prompt the model has
+// not answered, as it does after a failed run.
+func endsWithUserPrompt(history []llm.Message) bool {
+	return len(history) > 0 && history[len(history)-1].Role == "user"
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestFailoverOfferedAfterRepeatedProviderErrors verifies the TUI asks to
+// switch to the fallback model after two overloaded runs, then retries on it.
+func TestFailoverOfferedAfterRepeatedProviderErrors(testingHandle *testing.T) {
+	// Arrange a session whose prompt failed on an overloaded primary model.
+	opts := &options{FallbackModel: "backup", ProviderConfig: &config.ProviderConfig{DefaultModel: "primary"}}
+	newModel := func() *tuiModel {
+		model := newTUIModel(opts, nil, nil, sessionHistoryCursor{}, "", "primary", "session", nil)
+		model.history = []llm.Message{{Role: "user", Content: "hi"}}
+		model.runPrompt = "hi"
+		return model
+	}
+	overloaded := streamErrorMsg{Err: &openai.APIError{StatusCode: 529, Body: "overloaded"}}
+	key := func(model *tuiModel, value string) {
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(value)})
+	}
+
+	// Act.
+	accepted := newModel()
+	accepted.Update(overloaded)
+	offeredAfterOne := accepted.failover != nil
+	accepted.Update(overloaded)
+	offered := accepted.failover != nil
+	status := accepted.statusText
+	key(accepted, "y")
+
+	declined := newModel()
+	declined.Update(overloaded)
+	declined.Update(overloaded)
+	key(declined, "n")
+
+	denied := newModel()
+	denied.Update(streamErrorMsg{Err: &openai.APIError{StatusCode: 401}})
+	denied.Update(streamErrorMsg{Err: &openai.APIError{StatusCode: 401}})
+
+	// Assert.
+	testutil.RequireTrue(testingHandle, !offeredAfterOne, "one failure is not enough")
+	testutil.RequireTrue(testingHandle, offered, "offered after two failures")
+	testutil.RequireEqual(testingHandle, status, "Switch to backup for the rest of the session and retry? [y/N]", "prompt")
+	testutil.RequireEqual(testingHandle, accepted.model, "backup", "switched")
+	testutil.RequireTrue(testingHandle, accepted.running && accepted.runPrompt == "hi", "failed prompt retried")
+	testutil.RequireEqual(testingHandle, len(accepted.history), 1, "prompt not duplicated")
+	testutil.RequireEqual(testingHandle, declined.model, "primary", "kept model")
+	testutil.RequireTrue(testingHandle, !declined.running && declined.failover == nil, "declined")
+	testutil.RequireEqual(testingHandle, declined.statusText, "Staying on primary.", "declined status")
+	testutil.RequireTrue(testingHandle, denied.failover == nil && !strings.Contains(denied.statusText, "/model"), "auth errors are not failover candidates")
+}
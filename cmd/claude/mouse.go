@@ -0,0 +1,80 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tuiWheelLines is how many lines one mouse wheel step scrolls.
+const tuiWheelLines = 3
+
+// handleMouse scrolls the chat (or the open transcript) with the wheel. A
+// left click on the chat focuses it and toggles a long tool result under the
+// pointer; a click below the chat focuses the input.
+func (m *tuiModel) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		m.scrollWheel(msg.Button == tea.MouseButtonWheelDown)
+	case tea.MouseButtonLeft:
+		if m.transcript != nil || m.showMessageSelector {
+			return nil
+		}
+		if msg.Y >= m.chatView.Height {
+			if m.shouldShowInput() {
+				m.setActivePane("input")
+			}
+			return nil
+		}
+		m.setActivePane("chat")
+		if index := m.chatMessageAt(m.chatView.YOffset + msg.Y); index >= 0 && isExpandableMessage(m.chatMessages[index]) {
+			m.chatMessages[index].Expanded = !m.chatMessages[index].Expanded
+			// Keep the clicked block in place instead of jumping to the bottom.
+			m.chatAutoScroll = false
+			m.refreshChat()
+		}
+	}
+	return nil
+}
+
+// scrollWheel scrolls the transcript when it is open, otherwise the chat.
+// Scrolling the chat back to the bottom pins it there again.
+func (m *tuiModel) scrollWheel(down bool) {
+	if m.transcript != nil {
+		if down {
+			m.transcript.view.ScrollDown(tuiWheelLines)
+		} else {
+			m.transcript.view.ScrollUp(tuiWheelLines)
+		}
+		return
+	}
+	if down {
+		m.chatView.LineDown(tuiWheelLines)
+		m.chatAutoScroll = m.chatView.AtBottom()
+		return
+	}
+	m.chatView.LineUp(tuiWheelLines)
+	m.chatAutoScroll = false
+}
+
+// chatMessageAt returns the chat message rendered on a chat viewport line,
+// or -1 when the line shows the welcome banner or the streaming tail.
+func (m *tuiModel) chatMessageAt(line int) int {
+	starts := m.chatMessageLines
+	index := sort.Search(len(starts), func(i int) bool { return starts[i] > line }) - 1
+	if index < 0 || index >= len(m.chatMessages) {
+		return -1
+	}
+	if index == len(starts)-1 && m.running {
+		// The streaming tail follows the last message; its lines are not the message.
+		last := m.renderCachedMessage(index, m.chatMessages[index])
+		if line > starts[index]+strings.Count(last, "\n") {
+			return -1
+		}
+	}
+	return index
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// newMouseTestModel returns a sized TUI with a long chat ending in a tool
+// result longer than tuiCollapsedLines.
+func newMouseTestModel(testingHandle *testing.T) *tuiModel {
+	testingHandle.Helper()
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	model.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	for index := 0; index < 20; index++ {
+		model.appendAssistantText(fmt.Sprintf("answer %d", index))
+	}
+	var output []string
+	for index := 0; index < tuiCollapsedLines+10; index++ {
+		output = append(output, fmt.Sprintf("line %d", index))
+	}
+	model.appendToolResultMessage(agent.ToolEvent{Type: "tool_result", ToolName: "Bash", ToolID: "call_1", Result: strings.Join(output, "\n")})
+	model.refreshChat()
+	return model
+}
+
+// TestMouseWheelScrollsChat verifies the wheel scrolls the chat and that
+// scrolling back down pins it to the bottom again.
+func TestMouseWheelScrollsChat(testingHandle *testing.T) {
+	// Arrange
+	model := newMouseTestModel(testingHandle)
+	bottom := model.chatView.YOffset
+
+	// Act
+	model.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	scrolled := model.chatView.YOffset
+	pinnedAfterUp := model.chatAutoScroll
+	model.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+
+	// Assert
+	testutil.RequireEqual(testingHandle, scrolled, bottom-tuiWheelLines, "scrolled up")
+	testutil.RequireTrue(testingHandle, !pinnedAfterUp, "auto-scroll off while reading back")
+	testutil.RequireEqual(testingHandle, model.chatView.YOffset, bottom, "back at the bottom")
+	testutil.RequireTrue(testingHandle, model.chatAutoScroll, "auto-scroll back on")
+}
+
+// TestMouseClickTogglesToolResult verifies a click focuses the chat and
+// expands the long tool result under the pointer, and a second click
+// collapses it.
+func TestMouseClickTogglesToolResult(testingHandle *testing.T) {
+	// Arrange
+	model := newMouseTestModel(testingHandle)
+	last := len(model.chatMessages) - 1
+	before := model.chatView.YOffset
+	click := tea.MouseMsg{X: 4, Y: model.chatMessageLines[last] - before + 3, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+
+	// Act
+	model.Update(click)
+	expanded := model.chatMessages[last].Expanded
+	offset := model.chatView.YOffset
+	model.Update(click)
+
+	// Assert
+	testutil.RequireEqual(testingHandle, model.activePane, "chat", "chat focused")
+	testutil.RequireTrue(testingHandle, expanded, "expanded on click")
+	testutil.RequireEqual(testingHandle, offset, before, "expanding keeps the scroll position")
+	testutil.RequireTrue(testingHandle, !model.chatMessages[last].Expanded, "collapsed on the second click")
+
+	model.Update(tea.MouseMsg{X: 4, Y: model.chatView.Height + 1, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	testutil.RequireEqual(testingHandle, model.activePane, "input", "click below the chat focuses the input")
+}
+
+// TestChatMessageAt verifies clicks map to the message rendered on a line.
+func TestChatMessageAt(testingHandle *testing.T) {
+	model := newMouseTestModel(testingHandle)
+	testutil.RequireEqual(testingHandle, model.chatMessageAt(model.chatMessageLines[3]), 3, "first line of a message")
+	testutil.RequireEqual(testingHandle, model.chatMessageAt(model.chatMessageLines[4]-1), 3, "separator after a message")
+	testutil.RequireEqual(testingHandle, model.chatMessageAt(model.chatMessageLines[0]-1), -1, "welcome banner")
+	testutil.RequireTrue(testingHandle, !isExpandableMessage(model.chatMessages[0]), "assistant text is not expandable")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/streamjson"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// needsUserInputSubtype is the result subtype for runs paused on AskUserQuestion.
+const needsUserInputSubtype = "needs_user_input"
+
+// skippedToolCallResult answers calls that were queued behind a paused question.
+const skippedToolCallResult = "Not executed: the run paused for user input before this call ran. Call the tool again if it is still needed."
+
+// answerPendingQuestion turns the first prompt after a needs_user_input pause
+// into the AskUserQuestion tool result. Other calls from the same assistant
+// message that never ran are answered as skipped, so the history stays valid
+// for the upstream API. Input is returned unchanged when nothing is pending.
+func answerPendingQuestion(history []llm.Message, input []llm.Message) []llm.Message {
+	if len(input) == 0 || input[0].Role != "user" {
+		return input
+	}
+	assistantIndex := -1
+	for index := len(history) - 1; index >= 0; index-- {
+		if history[index].Role == "assistant" {
+			assistantIndex = index
+			break
+		}
+		if history[index].Role != "tool" {
+			return input
+		}
+	}
+	if assistantIndex < 0 {
+		return input
+	}
+	answered := map[string]bool{}
+	for _, message := range history[assistantIndex+1:] {
+		answered[message.ToolCallID] = true
+	}
+	var pending []llm.ToolCall
+	questionPending := false
+	for _, call := range history[assistantIndex].ToolCalls {
+		if answered[call.ID] {
+			continue
+		}
+		pending = append(pending, call)
+		if call.Function.Name == tools.AskUserQuestionToolName {
+			questionPending = true
+		}
+	}
+	if !questionPending {
+		return input
+	}
+
+	answer := strings.TrimSpace(llm.ContentText(input[0].Content))
+	answeredQuestion := false
+	converted := make([]llm.Message, 0, len(pending)+len(input)-1)
+	for _, call := range pending {
+		content := skippedToolCallResult
+		// Only the first pending question receives the answer.
+		if call.Function.Name == tools.AskUserQuestionToolName && !answeredQuestion {
+			content = answer
+			answeredQuestion = true
+		}
+		converted = append(converted, llm.Message{Role: "tool", ToolCallID: call.ID, Content: content})
+	}
+	return append(converted, input[1:]...)
+}
+
+// writeNeedsUserInput reports a paused run for text and json output. Text mode
+// prints the question on stdout and the resume command on stderr.
+func writeNeedsUserInput(stdout io.Writer, stderr io.Writer, format string, result *agent.RunResult, request tools.UserInputRequest, sessionID string, model string) error {
+	switch format {
+	case "json":
+		payload := map[string]any{
+			"session_id":   sessionID,
+			"model":        model,
+			"subtype":      needsUserInputSubtype,
+			"final":        result.Final.Content,
+			"usage":        result.TotalUsage,
+			"cost_usd":     result.CostUSD,
+			"question":     request,
+			"resume_token": sessionID,
+		}
+		if artifacts := resultArtifacts(sessionID); len(artifacts) > 0 {
+			payload["artifacts"] = artifacts
+		}
+		return json.NewEncoder(stdout).Encode(payload)
+	default:
+		fmt.Fprintln(stdout, request.Question)
+		for index, option := range request.Options {
+			fmt.Fprintf(stdout, "  %d) %s\n", index+1, option)
+		}
+		if request.Default != "" {
+			fmt.Fprintf(stdout, "[default: %s]\n", request.Default)
+		}
+		_, err := fmt.Fprintf(stderr, "Waiting for input. Answer with: claude -p --resume %s \"<answer>\"\n", sessionID)
+		return err
+	}
+}
+
+// writeStreamJSONNeedsUserInput emits the needs_user_input result event.
+func writeStreamJSONNeedsUserInput(writer *streamjson.Writer, result *agent.RunResult, request tools.UserInputRequest, sessionID string, model string) error {
+	if writer == nil {
+		return fmt.Errorf("stream-json writer is required")
+	}
+	resultEvent := buildStreamJSONResultEvent(result, sessionID, model)
+	resultEvent.Subtype = needsUserInputSubtype
+	resultEvent.Result = request.Question
+	resultEvent.Question = request
+	resultEvent.ResumeToken = sessionID
+	return writer.Write(resultEvent)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestAnswerPendingQuestion verifies a resumed prompt answers the paused question.
+func TestAnswerPendingQuestion(testingHandle *testing.T) {
+	history := []llm.Message{
+		{Role: "user", Content: "set up storage"},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{
+			{ID: "call-read", Function: llm.ToolCallFunction{Name: "Read"}},
+			{ID: "call-ask", Function: llm.ToolCallFunction{Name: tools.AskUserQuestionToolName}},
+			{ID: "call-bash", Function: llm.ToolCallFunction{Name: "Bash"}},
+		}},
+		{Role: "tool", ToolCallID: "call-read", Content: "file contents"},
+	}
+	input := []llm.Message{{Role: "user", Content: " Postgres \n"}}
+
+	converted := answerPendingQuestion(history, input)
+	testutil.RequireEqual(testingHandle, len(converted), 2, "tool messages for unanswered calls")
+	testutil.RequireEqual(testingHandle, converted[0].ToolCallID, "call-ask", "question call id")
+	testutil.RequireEqual(testingHandle, converted[0].Content, "Postgres", "answer")
+	testutil.RequireEqual(testingHandle, converted[1].ToolCallID, "call-bash", "skipped call id")
+	testutil.RequireEqual(testingHandle, converted[1].Content, skippedToolCallResult, "skipped call result")
+
+	finished := append(history, converted...)
+	next := answerPendingQuestion(finished, []llm.Message{{Role: "user", Content: "thanks"}})
+	testutil.RequireEqual(testingHandle, next[0].Role, "user", "answered history leaves prompts alone")
+}
+
+// TestWriteNeedsUserInput verifies the json and text reports carry the question and resume token.
+func TestWriteNeedsUserInput(testingHandle *testing.T) {
+	result := &agent.RunResult{Final: llm.Message{Role: "assistant"}}
+	request := tools.UserInputRequest{ToolUseID: "call-ask", Question: "Which database?", Options: []string{"Postgres", "SQLite"}}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	testutil.RequireNoError(testingHandle, writeNeedsUserInput(&stdout, &stderr, "json", result, request, "session-1", "model"), "write json")
+	var payload map[string]any
+	testutil.RequireNoError(testingHandle, json.Unmarshal(stdout.Bytes(), &payload), "parse json")
+	testutil.RequireEqual(testingHandle, payload["subtype"], needsUserInputSubtype, "subtype")
+	testutil.RequireEqual(testingHandle, payload["resume_token"], "session-1", "resume token")
+	question, _ := payload["question"].(map[string]any)
+	testutil.RequireEqual(testingHandle, question["tool_use_id"], "call-ask", "question tool use id")
+
+	stdout.Reset()
+	testutil.RequireNoError(testingHandle, writeNeedsUserInput(&stdout, &stderr, "text", result, request, "session-1", "model"), "write text")
+	testutil.RequireTrue(testingHandle, strings.Contains(stdout.String(), "Which database?\n  1) Postgres\n  2) SQLite"), "text question: "+stdout.String())
+	testutil.RequireTrue(testingHandle, strings.Contains(stderr.String(), "--resume session-1"), "resume hint: "+stderr.String())
+}
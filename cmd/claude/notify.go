@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/config"
+)
+
+// Notification channels for the preferredNotifChannel setting. command is an
+// OpenClaude extension; the others match Claude Code.
+const (
+	notifChannelAuto      = "auto"
+	notifChannelBell      = "terminal_bell"
+	notifChannelITerm2    = "iterm2"
+	notifChannelITermBell = "iterm2_with_bell"
+	notifChannelCommand   = "command"
+	notifChannelDisabled  = "notifications_disabled"
+)
+
+const (
+	// notifyRunThreshold is how long a run must take before its end notifies.
+	notifyRunThreshold = 30 * time.Second
+	// notifyCommandTimeout bounds the notifyCommand.
+	notifyCommandTimeout = 10 * time.Second
+	// maxNotificationRunes caps the text sent in an OSC 9 notification.
+	maxNotificationRunes = 200
+)
+
+// osc9Terminals are TERM_PROGRAM values of terminals that show OSC 9
+// notifications on the desktop.
+var osc9Terminals = map[string]bool{"iTerm.app": true, "WezTerm": true, "ghostty": true}
+
+// notifChannel resolves the configured channel, choosing one for auto: the
+// notify command when set, OSC 9 on terminals known to show it, else the bell.
+// Only a notify command from user settings or --settings is run; one from
+// project or local settings would let a cloned repository run commands.
+// Unknown names count as auto.
+func notifChannel(opts *options, getenv func(string) string) (string, string) {
+	channel, command := notifChannelAuto, ""
+	if opts != nil && opts.ClaudeSettings != nil {
+		if !config.IsWorkspaceSource(opts.ClaudeSettings.NotifyCommandSource) {
+			command = strings.TrimSpace(opts.ClaudeSettings.NotifyCommand)
+		}
+		if configured := strings.TrimSpace(opts.ClaudeSettings.PreferredNotifChannel); configured != "" {
+			channel = configured
+		}
+	}
+	switch channel {
+	case notifChannelBell, notifChannelITerm2, notifChannelITermBell, notifChannelDisabled:
+		return channel, command
+	case notifChannelCommand:
+		if command == "" {
+			return notifChannelBell, command
+		}
+		return channel, command
+	}
+	switch {
+	case command != "":
+		return notifChannelCommand, command
+	case osc9Terminals[getenv("TERM_PROGRAM")]:
+		return notifChannelITerm2, command
+	}
+	return notifChannelBell, command
+}
+
+// notificationSequence returns the escape sequence for a terminal channel.
+func notificationSequence(channel string, message string) string {
+	switch channel {
+	case notifChannelBell:
+		return "\a"
+	case notifChannelITerm2:
+		return "\x1b]9;" + sanitizeNotification(message) + "\a"
+	case notifChannelITermBell:
+		return "\x1b]9;" + sanitizeNotification(message) + "\a\a"
+	}
+	return ""
+}
+
+// sanitizeNotification drops control characters, which could end the escape
+// sequence early, and caps the length.
+func sanitizeNotification(message string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return ' '
+		}
+		return r
+	}, message)
+	if runes := []rune(cleaned); len(runes) > maxNotificationRunes {
+		cleaned = string(runes[:maxNotificationRunes-1]) + "…"
+	}
+	return cleaned
+}
+
+// notify alerts the user through the configured channel while the terminal
+// is unfocused. Terminals that never report focus changes count as focused,
+// so they are not notified.
+func (m *tuiModel) notify(message string) tea.Cmd {
+	if !m.blurred {
+		return nil
+	}
+	channel, command := notifChannel(m.opts, os.Getenv)
+	switch channel {
+	case notifChannelDisabled:
+		return nil
+	case notifChannelCommand:
+		return func() tea.Msg {
+			runNotifyCommand(command, message)
+			return nil
+		}
+	}
+	out := m.notifyOut
+	sequence := notificationSequence(channel, message)
+	return func() tea.Msg {
+		if out != nil {
+			_, _ = io.WriteString(out, sequence)
+		}
+		return nil
+	}
+}
+
+// notifyRunEnd notifies that a run ended when it took long enough for the
+// user to have switched away.
+func (m *tuiModel) notifyRunEnd(started time.Time, outcome string) tea.Cmd {
+	if started.IsZero() {
+		return nil
+	}
+	elapsed := time.Since(started)
+	if elapsed < notifyRunThreshold {
+		return nil
+	}
+	return m.notify(fmt.Sprintf("OpenClaude %s after %s", outcome, elapsed.Round(time.Second)))
+}
+
+// runNotifyCommand runs the notifyCommand with the message in
+// OPENCLAUDE_NOTIFY_MESSAGE. Failures are ignored; a notification is never
+// worth interrupting the session for.
+func runNotifyCommand(command string, message string) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyCommandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), "OPENCLAUDE_NOTIFY_MESSAGE="+message)
+	_ = cmd.Run()
+}
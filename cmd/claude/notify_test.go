@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestNotifChannel verifies configured channels and the auto choice.
+func TestNotifChannel(testingHandle *testing.T) {
+	cases := []struct {
+		name     string
+		settings *config.Settings
+		terminal string
+		expected string
+	}{
+		{name: "no settings", expected: notifChannelBell},
+		{name: "auto on iTerm2", settings: &config.Settings{PreferredNotifChannel: "auto"}, terminal: "iTerm.app", expected: notifChannelITerm2},
+		{name: "auto with a command", settings: &config.Settings{NotifyCommand: "notify-send hi"}, terminal: "iTerm.app", expected: notifChannelCommand},
+		{name: "explicit bell", settings: &config.Settings{PreferredNotifChannel: "terminal_bell"}, terminal: "ghostty", expected: notifChannelBell},
+		{name: "command without one", settings: &config.Settings{PreferredNotifChannel: "command"}, expected: notifChannelBell},
+		{name: "disabled", settings: &config.Settings{PreferredNotifChannel: "notifications_disabled", NotifyCommand: "x"}, expected: notifChannelDisabled},
+		{name: "project command in auto", settings: &config.Settings{NotifyCommand: "x", NotifyCommandSource: config.SettingsSourceProject}, terminal: "ghostty", expected: notifChannelITerm2},
+		{name: "local command on the command channel", settings: &config.Settings{PreferredNotifChannel: "command", NotifyCommand: "x", NotifyCommandSource: config.SettingsSourceLocal}, expected: notifChannelBell},
+		{name: "user command", settings: &config.Settings{NotifyCommand: "x", NotifyCommandSource: config.SettingsSourceUser}, expected: notifChannelCommand},
+		{name: "unknown is auto", settings: &config.Settings{PreferredNotifChannel: "pager"}, terminal: "WezTerm", expected: notifChannelITerm2},
+	}
+	for _, testCase := range cases {
+		testingHandle.Run(testCase.name, func(testingHandle *testing.T) {
+			opts := &options{ClaudeSettings: testCase.settings}
+			channel, _ := notifChannel(opts, func(string) string { return testCase.terminal })
+			testutil.RequireEqual(testingHandle, channel, testCase.expected, "channel")
+		})
+	}
+}
+
+// TestNotificationSequence verifies the escape sequences and that message
+// text cannot end them early.
+func TestNotificationSequence(testingHandle *testing.T) {
+	testutil.RequireEqual(testingHandle, notificationSequence(notifChannelBell, "done"), "\a", "bell")
+	testutil.RequireEqual(testingHandle, notificationSequence(notifChannelITerm2, "done\a\x1b]9;x"), "\x1b]9;done  ]9;x\a", "osc 9")
+	testutil.RequireEqual(testingHandle, notificationSequence(notifChannelITermBell, "done"), "\x1b]9;done\a\a", "osc 9 with bell")
+	testutil.RequireEqual(testingHandle, notificationSequence(notifChannelDisabled, "done"), "", "disabled")
+}
+
+// TestTUINotifiesWhileBlurred verifies notifications fire only while the
+// terminal is unfocused, and run ends only after long runs.
+func TestTUINotifiesWhileBlurred(testingHandle *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	opts := &options{ClaudeSettings: &config.Settings{PreferredNotifChannel: "iterm2"}}
+	model := newTUIModel(opts, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	model.notifyOut = &out
+
+	// Act
+	focused := model.notify("ignored")
+	model.Update(tea.BlurMsg{})
+	short := model.notifyRunEnd(time.Now(), "finished")
+	long := model.notifyRunEnd(time.Now().Add(-time.Minute), "finished")
+	long()
+	model.Update(tea.FocusMsg{})
+
+	// Assert
+	testutil.RequireTrue(testingHandle, focused == nil, "no notification while focused")
+	testutil.RequireTrue(testingHandle, short == nil, "no notification for short runs")
+	testutil.RequireEqual(testingHandle, out.String(), "\x1b]9;OpenClaude finished after 1m0s\a", "notification written")
+	testutil.RequireTrue(testingHandle, model.notify("ignored") == nil, "focus restored")
+}
+
+// TestNotifyCommand verifies the command channel passes the message in the environment.
+func TestNotifyCommand(testingHandle *testing.T) {
+	if runtime.GOOS == "windows" {
+		testingHandle.Skip("uses a POSIX shell")
+	}
+	// Arrange
+	target := filepath.Join(testingHandle.TempDir(), "message")
+	opts := &options{ClaudeSettings: &config.Settings{NotifyCommand: `printf %s "$OPENCLAUDE_NOTIFY_MESSAGE" > ` + target}}
+	model := newTUIModel(opts, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	model.blurred = true
+
+	// Act
+	model.notify("OpenClaude needs your permission to use Bash")()
+
+	// Assert
+	written, err := os.ReadFile(target)
+	testutil.RequireNoError(testingHandle, err, "read command output")
+	testutil.RequireEqual(testingHandle, string(written), "OpenClaude needs your permission to use Bash", "message")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// tuiPathPaste records a paste of file paths that was converted to @-mentions.
+type tuiPathPaste struct {
+	// Mentions is the @-mention text inserted in place of the paste.
+	Mentions string
+	// Raw is the original pasted text, restored when the user declines.
+	Raw string
+}
+
+// pastedFileMentions converts a paste made up solely of existing file paths
+// into @-mentions. Terminals and file managers paste dragged files as quoted
+// or backslash-escaped paths, file:// URIs, or one path per line; any token
+// that does not name an existing file or directory leaves the paste as text.
+func pastedFileMentions(pasted string, cwd string) (string, int, bool) {
+	paths, ok := pastedPaths(pasted, cwd)
+	if !ok {
+		return "", 0, false
+	}
+	mentions := make([]string, 0, len(paths))
+	for _, path := range paths {
+		label := mentionLabel(path, cwd)
+		if strings.ContainsAny(label, " \t") {
+			label = `"` + label + `"`
+		}
+		mentions = append(mentions, "@"+label)
+	}
+	return strings.Join(mentions, " ") + " ", len(mentions), true
+}
+
+// pastedPaths resolves every entry in a paste to an existing path.
+// Line-per-path pastes are tried first so Windows paths keep their backslashes.
+func pastedPaths(pasted string, cwd string) ([]string, bool) {
+	trimmed := strings.TrimSpace(pasted)
+	if trimmed == "" {
+		return nil, false
+	}
+	var lines []string
+	for _, line := range strings.Split(trimmed, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, strings.Trim(line, `"'`))
+		}
+	}
+	if paths, ok := resolvePastedPaths(lines, cwd); ok {
+		return paths, true
+	}
+	return resolvePastedPaths(splitPromptTokens(trimmed), cwd)
+}
+
+// resolvePastedPaths stats each token, failing when any token is not a path.
+func resolvePastedPaths(tokens []string, cwd string) ([]string, bool) {
+	if len(tokens) == 0 {
+		return nil, false
+	}
+	paths := make([]string, 0, len(tokens))
+	seen := map[string]bool{}
+	for _, token := range tokens {
+		token = strings.TrimSuffix(token, "\r")
+		if strings.HasPrefix(token, "file://") {
+			parsed, err := url.Parse(token)
+			if err != nil || parsed.Path == "" {
+				return nil, false
+			}
+			token = parsed.Path
+		}
+		// Bare words such as "main.go" stay text even when a matching file exists.
+		if !strings.ContainsAny(token, `/\`) && !strings.HasPrefix(token, "~") {
+			return nil, false
+		}
+		path := resolvePromptPath(token, cwd)
+		if _, err := os.Stat(path); err != nil {
+			return nil, false
+		}
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths, true
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestPastedFileMentions verifies only pastes made entirely of existing paths become mentions.
+func TestPastedFileMentions(testingHandle *testing.T) {
+	dir := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(dir, "my docs"), 0o755), "create dir")
+	notes := filepath.Join(dir, "my docs", "notes.md")
+	testutil.RequireNoError(testingHandle, os.WriteFile(notes, []byte("hi\n"), 0o600), "write notes")
+	main := filepath.Join(dir, "main.go")
+	testutil.RequireNoError(testingHandle, os.WriteFile(main, []byte("package main\n"), 0o600), "write main")
+
+	cases := []struct {
+		name     string
+		pasted   string
+		expected string
+		ok       bool
+	}{
+		{name: "escaped spaces", pasted: dir + "/my\\ docs/notes.md " + main, expected: `@"my docs/notes.md" @main.go `, ok: true},
+		{name: "file uri lines", pasted: "file://" + main + "\nfile://" + filepath.Join(dir, "my%20docs"), expected: `@main.go @"my docs" `, ok: true},
+		{name: "mixed text", pasted: main + " please", ok: false},
+		{name: "missing path", pasted: filepath.Join(dir, "absent.txt"), ok: false},
+		{name: "bare word", pasted: "main.go", ok: false},
+	}
+	for _, testCase := range cases {
+		mentions, _, ok := pastedFileMentions(testCase.pasted, dir)
+		testutil.RequireEqual(testingHandle, ok, testCase.ok, testCase.name)
+		testutil.RequireEqual(testingHandle, mentions, testCase.expected, testCase.name)
+	}
+}
+
+// TestTUIPastePathsAttachAndRevert verifies pasted paths become mentions and Esc restores the raw text.
+func TestTUIPastePathsAttachAndRevert(testingHandle *testing.T) {
+	dir := testingHandle.TempDir()
+	main := filepath.Join(dir, "main.go")
+	testutil.RequireNoError(testingHandle, os.WriteFile(main, []byte("package main\n"), 0o600), "write main")
+	testingHandle.Chdir(dir)
+
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	model.input.SetValue("explain")
+	model.input.CursorEnd()
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(main), Paste: true})
+
+	testutil.RequireEqual(testingHandle, model.input.Value(), "explain @main.go ", "paste attached as mention")
+	testutil.RequireTrue(testingHandle, model.pathPaste != nil, "attachment can be reverted")
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	testutil.RequireEqual(testingHandle, model.input.Value(), "explain"+main, "esc restores raw paste")
+	testutil.RequireTrue(testingHandle, model.pathPaste == nil, "offer cleared")
+}
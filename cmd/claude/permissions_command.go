@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// runPermissionsCommand lists approval rules or exports session approvals.
+// "/permissions export [file]" merges the session's always-allow answers into a
+// policy file (default .openclaude/permission-policy.json) for --permission-policy.
+func runPermissionsCommand(opts *options, args string) string {
+	subcommand, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	switch strings.ToLower(subcommand) {
+	case "":
+		return formatPermissionRules(opts)
+	case "export":
+		return exportSessionApprovals(opts, strings.TrimSpace(rest))
+	default:
+		return "Usage: /permissions [export [file]]"
+	}
+}
+
+// formatPermissionRules renders allow rules grouped by where they came from,
+// followed by the settings deny rules.
+func formatPermissionRules(opts *options) string {
+	if opts == nil {
+		return "No permission rules."
+	}
+	var builder strings.Builder
+	builder.WriteString("Permission allow rules:")
+	fmt.Fprintf(&builder, "\n  settings: %s", formatRuleList(settingsAllowedTools(opts)))
+	policyLabel := "policy"
+	if opts.PermissionPolicy != "" {
+		policyLabel = fmt.Sprintf("policy (%s)", opts.PermissionPolicy)
+	}
+	fmt.Fprintf(&builder, "\n  %s: %s", policyLabel, formatRuleList(opts.PermissionPolicyRules))
+	if opts.PermissionPolicyLimits != nil {
+		fmt.Fprintf(&builder, "\n  policy limits: %s", formatPolicyLimits(opts.PermissionPolicyLimits))
+	}
+	fmt.Fprintf(&builder, "\n  this session: %s", formatRuleList(opts.SessionApprovals))
+	if denied := normalizePermissionRules(settingsDenyList(opts)); len(denied) > 0 {
+		fmt.Fprintf(&builder, "\nPermission deny rules (settings): %s", formatRuleList(denied))
+	}
+	builder.WriteString("\nAnswer \"a\" at a tool prompt to always allow it; /permissions export [file] saves session approvals.")
+	return builder.String()
+}
+
+// exportSessionApprovals writes session approvals into a permission policy file.
+func exportSessionApprovals(opts *options, path string) string {
+	if opts == nil || len(opts.SessionApprovals) == 0 {
+		return "No approvals to export; answer \"a\" at a tool prompt to always allow it."
+	}
+	if path == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Sprintf("Export failed: get cwd: %v", err)
+		}
+		path = config.DefaultPermissionPolicyPath(cwd)
+	}
+	policy, err := config.SavePermissionPolicy(path, opts.SessionApprovals)
+	if err != nil {
+		return fmt.Sprintf("Export failed: %v", err)
+	}
+	return fmt.Sprintf("Exported %d approval(s) to %s (%d rule(s) total). Commit it and run CI with --permission-policy %s.",
+		len(opts.SessionApprovals), path, len(policy.Allow), path)
+}
+
+// formatPolicyLimits summarizes the declarative part of a permission policy.
+func formatPolicyLimits(limits *tools.PolicyLimits) string {
+	if limits == nil {
+		return "(none)"
+	}
+	var parts []string
+	if len(limits.Tools) > 0 {
+		parts = append(parts, "tools "+strings.Join(limits.Tools, ", "))
+	}
+	if len(limits.BashPrefixes) > 0 {
+		parts = append(parts, "bash prefixes "+strings.Join(limits.BashPrefixes, ", "))
+	}
+	if len(limits.Paths) > 0 {
+		parts = append(parts, "paths "+strings.Join(limits.Paths, ", "))
+	}
+	if limits.MaxFileSizeBytes > 0 {
+		parts = append(parts, fmt.Sprintf("max file size %d bytes", limits.MaxFileSizeBytes))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// settingsDenyList returns every settings deny rule, whole-tool or specifier.
+func settingsDenyList(opts *options) []string {
+	if opts.ClaudeSettings == nil {
+		return nil
+	}
+	return opts.ClaudeSettings.Permissions.Deny
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestAlwaysAllowExportsPolicy verifies "a" remembers the exact call and /permissions export writes it.
+func TestAlwaysAllowExportsPolicy(testingHandle *testing.T) {
+	projectDir := testingHandle.TempDir()
+	testingHandle.Chdir(projectDir)
+	opts := &options{}
+	runner := &agent.Runner{Permissions: tools.Permissions{Mode: tools.PermissionDefault}}
+	model := newTUIModel(opts, runner, nil, sessionHistoryCursor{}, "", "m", "session", nil)
+	args := json.RawMessage(`{"command":"go test ./..."}`)
+	request := &permissionRequest{ToolName: "Bash", Args: args, Response: make(chan bool, 1)}
+	model.pendingPermission = request
+
+	model.resolvePermissionAlways()
+
+	testutil.RequireTrue(testingHandle, <-request.Response, "call allowed")
+	testutil.RequireEqual(testingHandle, opts.SessionApprovals, []string{"Bash(go test ./...)"}, "session approval recorded")
+	testutil.RequireTrue(testingHandle, !runner.Permissions.ShouldPromptCall("Bash", args), "same command no longer prompts")
+	testutil.RequireTrue(testingHandle, runner.Permissions.ShouldPromptCall("Bash", json.RawMessage(`{"command":"rm -rf ."}`)), "other commands still prompt")
+
+	output := runPermissionsCommand(opts, "export")
+	policyPath := config.DefaultPermissionPolicyPath(projectDir)
+	testutil.RequireTrue(testingHandle, strings.Contains(output, policyPath), "export reports the path")
+	policy, err := config.LoadPermissionPolicy(policyPath)
+	testutil.RequireNoError(testingHandle, err, "load exported policy")
+	testutil.RequireEqual(testingHandle, policy.Allow, []string{"Bash(go test ./...)"}, "exported rules")
+
+	// A CI run loading the policy gets the same approval without prompting.
+	ciOpts := &options{PermissionPolicy: filepath.Join(projectDir, ".openclaude", "permission-policy.json")}
+	loaded, err := config.LoadPermissionPolicy(ciOpts.PermissionPolicy)
+	testutil.RequireNoError(testingHandle, err, "load policy for CI")
+	ciOpts.PermissionPolicyRules = loaded.Allow
+	ciPermissions := tools.Permissions{Mode: tools.PermissionDefault, AlwaysAllow: permissionAllowRules(ciOpts)}
+	testutil.RequireTrue(testingHandle, !ciPermissions.ShouldPromptCall("Bash", args), "policy replays the approval")
+}
@@ -0,0 +1,13 @@
+package main
+
+import "github.com/openclaude/openclaude/internal/llm"
+
+// withPrefill ends the input with an assistant message holding the --prefill
+// text, so the model's reply continues it. Input that already ends with an
+// assistant message, such as a stream-json prefill, is left as is.
+func withPrefill(input []llm.Message, prefill string) []llm.Message {
+	if prefill == "" || (len(input) > 0 && input[len(input)-1].Role == "assistant") {
+		return input
+	}
+	return append(input, llm.Message{Role: "assistant", Content: prefill})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// followConversation carries the conversation between --follow turns.
+type followConversation struct {
+	// messages is the full history, starting with the system prompt.
+	messages []llm.Message
+	// persisted counts the leading messages already in the session log.
+	persisted int
+}
+
+// runPrintModeFollow runs a turn for the prompt argument, if any, and then for
+// each non-empty stdin line, printing every reply before reading the next line.
+// A failed turn is reported on errOut and leaves the conversation unchanged;
+// the last failure becomes the exit error once stdin closes.
+func runPrintModeFollow(
+	ctx context.Context,
+	in io.Reader,
+	out io.Writer,
+	errOut io.Writer,
+	opts *options,
+	runner *agent.Runner,
+	history []llm.Message,
+	systemPrompt string,
+	prompt string,
+	model string,
+	sessionID string,
+	store *session.Store,
+) error {
+	conversation := &followConversation{messages: ensureSystem(history, systemPrompt), persisted: len(history)}
+	reader := bufio.NewReader(in)
+	var lastErr error
+	for eof := false; ; {
+		if prompt == "" {
+			if eof {
+				return lastErr
+			}
+			line, err := reader.ReadString('\n')
+			if errors.Is(err, io.EOF) {
+				eof = true
+			} else if err != nil {
+				return fmt.Errorf("read stdin: %w", err)
+			}
+			prompt = strings.TrimSpace(line)
+			continue
+		}
+		if err := runFollowTurn(ctx, out, errOut, opts, runner, conversation, prompt, model, sessionID, store); err != nil {
+			fmt.Fprintf(errOut, "Error: %v\n", err)
+			lastErr = err
+		}
+		prompt = ""
+	}
+}
+
+// runFollowTurn sends one prompt, persists the new messages, and prints the reply.
+func runFollowTurn(
+	ctx context.Context,
+	out io.Writer,
+	errOut io.Writer,
+	opts *options,
+	runner *agent.Runner,
+	conversation *followConversation,
+	prompt string,
+	model string,
+	sessionID string,
+	store *session.Store,
+) error {
+	prompt = expandPrintMentions(prompt, opts, runner)
+	input := answerPendingQuestion(conversation.messages, []llm.Message{{Role: "user", Content: prompt}})
+	input = withPrefill(input, opts.Prefill)
+	messages := append(append([]llm.Message(nil), conversation.messages...), input...)
+
+	modelUsed := model
+	result, err := runner.Run(ctx, messages, "", model, runner.ToolRunner != nil)
+	if err != nil && opts.FallbackModel != "" && isRetryableError(err) {
+		modelUsed = opts.FallbackModel
+		result, err = runner.Run(ctx, messages, "", opts.FallbackModel, runner.ToolRunner != nil)
+	}
+	var inputErr *tools.UserInputRequiredError
+	if err != nil && !(errors.As(err, &inputErr) && result != nil) {
+		return err
+	}
+
+	if !opts.NoSessionPersistence && store != nil {
+		if err := persistSession(store, sessionID, result.Messages[conversation.persisted:], result.Events); err != nil {
+			return err
+		}
+		_ = store.AppendCost(sessionID, result.CostUSD)
+		_ = store.SaveLastSession(session.ProjectHash(mustCwd()), sessionID)
+		_ = store.CloseSession(sessionID)
+	}
+	conversation.messages = result.Messages
+	conversation.persisted = len(result.Messages)
+	if len(result.DroppedToolResults) > 0 {
+		fmt.Fprintln(errOut, contextTrimmedNotice(result.DroppedToolResults))
+	}
+
+	if inputErr != nil {
+		if err := writeNeedsUserInput(out, io.Discard, "text", result, inputErr.Request, sessionID, modelUsed); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(errOut, "Waiting for input. Answer on the next line.")
+		return err
+	}
+	_, err = fmt.Fprintln(out, formatContent(result.Final.Content))
+	return err
+}
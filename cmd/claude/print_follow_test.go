@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestRunPrintModeFollowRunsTurnPerLine verifies each stdin line runs a turn that
+// sees the earlier ones, replies are written before the next line is read, and a
+// failed turn is reported without ending the loop.
+func TestRunPrintModeFollowRunsTurnPerLine(testingHandle *testing.T) {
+	// Arrange: the gateway replies with the user prompts it received and rejects "fail".
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		var payload struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(request.Body).Decode(&payload)
+		var prompts []string
+		for _, message := range payload.Messages {
+			if message.Role == "user" {
+				prompts = append(prompts, message.Content)
+			}
+		}
+		if prompts[len(prompts)-1] == "fail" {
+			http.Error(responseWriter, "bad prompt", http.StatusBadRequest)
+			return
+		}
+		reply, _ := json.Marshal(strings.Join(prompts, "+"))
+		_, _ = fmt.Fprintf(responseWriter, `{"choices":[{"message":{"role":"assistant","content":%s},"finish_reason":"stop"}]}`, reply)
+	}))
+	testingHandle.Cleanup(server.Close)
+	runner := &agent.Runner{Client: openai.NewClient(server.URL, "", 5*time.Second)}
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	var stderr bytes.Buffer
+	done := make(chan error, 1)
+
+	// Act
+	go func() {
+		done <- runPrintModeFollow(context.Background(), stdinReader, stdoutWriter, &stderr, &options{NoSessionPersistence: true}, runner, nil, "system", "zero", "model", "session", nil)
+		stdoutWriter.Close()
+	}()
+	replies := bufio.NewReader(stdoutReader)
+	readReply := func() string {
+		line, err := replies.ReadString('\n')
+		testutil.RequireNoError(testingHandle, err, "read reply")
+		return line
+	}
+	first := readReply()
+	_, _ = io.WriteString(stdinWriter, "one\n\n")
+	second := readReply()
+	_, _ = io.WriteString(stdinWriter, "fail\ntwo")
+	stdinWriter.Close()
+	third := readReply()
+	rest, _ := io.ReadAll(replies)
+	err := <-done
+
+	// Assert
+	testutil.RequireEqual(testingHandle, []string{first, second, third}, []string{"zero\n", "zero+one\n", "zero+one+two\n"}, "replies")
+	testutil.RequireEqual(testingHandle, string(rest), "", "no extra output")
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "400"), "last failure is the exit error")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(stderr.String(), "Error: ") && strings.Contains(stderr.String(), "bad prompt"), "failure reported on stderr")
+}
+
+// TestValidateFormatOptionsFollow verifies --follow needs text print mode.
+func TestValidateFormatOptionsFollow(testingHandle *testing.T) {
+	testutil.RequireNoError(testingHandle, validateFormatOptions(&options{Print: true, Follow: true, InputFormat: "text", OutputFormat: "text"}), "text print mode")
+	for _, opts := range []*options{
+		{Follow: true, InputFormat: "text", OutputFormat: "text"},
+		{Print: true, Follow: true, InputFormat: "text", OutputFormat: "json"},
+		{Print: true, Follow: true, Verbose: true, InputFormat: "stream-json", OutputFormat: "stream-json"},
+	} {
+		err := validateFormatOptions(opts)
+		testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "--follow"), "rejects follow with other formats")
+	}
+}
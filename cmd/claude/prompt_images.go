@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// promptImageContent attaches images referenced in an interactive prompt.
+// Tokens naming existing image files (relative to cwd, "~" expanded, quoted or
+// with backslash-escaped spaces as terminals paste them) and http(s) image URLs
+// become image parts after the prompt text. The prompt string is returned
+// unchanged when nothing is attached; labels name each attached image.
+// Images over tools.MaxImageBytes or that cannot be read are not attached:
+// their path stays in the prompt as plain text and a warning says why.
+func promptImageContent(prompt string, cwd string) (any, []string, []string) {
+	var images []llm.ContentPart
+	var labels []string
+	var warnings []string
+	seen := map[string]bool{}
+	for _, token := range splitPromptTokens(prompt) {
+		// "@shot.png" mentions attach the image like a bare path.
+		token = strings.TrimPrefix(token, "@")
+		if seen[token] {
+			continue
+		}
+		if isImageURL(token) {
+			seen[token] = true
+			images = append(images, llm.ImagePart(token))
+			labels = append(labels, token)
+			continue
+		}
+		if _, ok := tools.ImageMediaType(token); !ok {
+			continue
+		}
+		path := resolvePromptPath(token, cwd)
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		seen[token] = true
+		if info.Size() > tools.MaxImageBytes {
+			warnings = append(warnings, fmt.Sprintf("Image %s not attached: %s is over the %s limit; its path is sent as text.",
+				filepath.Base(path), formatByteSize(info.Size()), formatByteSize(tools.MaxImageBytes)))
+			continue
+		}
+		image, err := tools.LoadImagePart(path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Image %s not attached: %v; its path is sent as text.", filepath.Base(path), err))
+			continue
+		}
+		images = append(images, image)
+		labels = append(labels, filepath.Base(path))
+	}
+	if len(images) == 0 {
+		return prompt, nil, warnings
+	}
+	return append([]llm.ContentPart{llm.TextPart(prompt)}, images...), labels, warnings
+}
+
+// isImageURL reports whether token is an http(s) URL with an image extension.
+func isImageURL(token string) bool {
+	if !strings.HasPrefix(token, "http://") && !strings.HasPrefix(token, "https://") {
+		return false
+	}
+	parsed, err := url.Parse(token)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	_, ok := tools.ImageMediaType(parsed.Path)
+	return ok
+}
+
+// resolvePromptPath expands "~" and resolves relative paths against cwd.
+func resolvePromptPath(token string, cwd string) string {
+	if token == "~" || strings.HasPrefix(token, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			token = filepath.Join(home, strings.TrimPrefix(token, "~"))
+		}
+	}
+	if filepath.IsAbs(token) {
+		return token
+	}
+	return filepath.Join(cwd, token)
+}
+
+// splitPromptTokens splits a prompt on whitespace, honoring quotes and backslash escapes.
+func splitPromptTokens(prompt string) []string {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+	escaped := false
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range prompt {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestPromptImageContent verifies pasted image paths and URLs become image parts.
+func TestPromptImageContent(testingHandle *testing.T) {
+	dir := testingHandle.TempDir()
+	imagePath := filepath.Join(dir, "My Shot.png")
+	testutil.RequireNoError(testingHandle, os.WriteFile(imagePath, []byte("png"), 0o600), "write image")
+
+	content, labels, warnings := promptImageContent(`compare My\ Shot.png with https://example.com/ref.jpg?x=1 and notes.txt`, dir)
+
+	testutil.RequireEqual(testingHandle, len(warnings), 0, "no warnings")
+	testutil.RequireEqual(testingHandle, labels, []string{"My Shot.png", "https://example.com/ref.jpg?x=1"}, "labels")
+	parts, ok := content.([]llm.ContentPart)
+	testutil.RequireTrue(testingHandle, ok && len(parts) == 3, "text plus two image parts")
+	testutil.RequireEqual(testingHandle, parts[1].ImageURL.URL, "data:image/png;base64,cG5n", "file image data url")
+
+	// Prompts without images are sent unchanged.
+	plain, labels, warnings := promptImageContent(`explain "missing.png" please`, dir)
+	testutil.RequireEqual(testingHandle, len(warnings), 0, "missing files are not warned about")
+	testutil.RequireEqual(testingHandle, plain, any(`explain "missing.png" please`), "plain content")
+	testutil.RequireEqual(testingHandle, len(labels), 0, "no labels")
+}
+
+// TestPromptImageContentSkipsOversizedImages verifies an image over the size
+// limit is left in the prompt as text with a warning instead of failing it.
+func TestPromptImageContentSkipsOversizedImages(testingHandle *testing.T) {
+	// Arrange an oversized image next to a small one.
+	dir := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "small.png"), []byte("png"), 0o600), "write small image")
+	large, err := os.Create(filepath.Join(dir, "large.png"))
+	testutil.RequireNoError(testingHandle, err, "create large image")
+	testutil.RequireNoError(testingHandle, large.Truncate(tools.MaxImageBytes+1), "grow large image")
+	testutil.RequireNoError(testingHandle, large.Close(), "close large image")
+
+	// Act.
+	content, labels, warnings := promptImageContent("compare large.png and small.png", dir)
+
+	// Assert.
+	testutil.RequireEqual(testingHandle, labels, []string{"small.png"}, "only the small image attached")
+	parts, ok := content.([]llm.ContentPart)
+	testutil.RequireTrue(testingHandle, ok && len(parts) == 2, "text plus one image part")
+	testutil.RequireEqual(testingHandle, parts[0].Text, "compare large.png and small.png", "path kept as text")
+	testutil.RequireEqual(testingHandle, len(warnings), 1, "one warning")
+	testutil.RequireTrue(testingHandle, strings.Contains(warnings[0], "large.png") && strings.Contains(warnings[0], "5.0 MB limit"), "warning names the image and the limit")
+}
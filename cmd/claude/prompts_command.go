@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// promptsCommand groups the prompt preset library subcommands.
+func promptsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompts",
+		Short: "List and inspect system prompt presets in ~/.openclaude/prompts",
+	}
+	cmd.AddCommand(promptsListCommand())
+	cmd.AddCommand(promptsShowCommand())
+	return cmd
+}
+
+// promptsListCommand prints the presets --system-prompt-preset accepts.
+func promptsListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List prompt presets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := config.PromptPresetDir()
+			if err != nil {
+				return err
+			}
+			presets, err := config.ListPromptPresets(dir)
+			if err != nil {
+				return err
+			}
+			return writePromptPresetTable(cmd.OutOrStdout(), dir, presets)
+		},
+	}
+}
+
+// promptsShowCommand prints a preset with its variables filled in: the system
+// prompt a run with the same --system-prompt-preset and --var flags would use.
+func promptsShowCommand() *cobra.Command {
+	var vars []string
+	cmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Print a prompt preset with its variables resolved",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("resolve cwd: %w", err)
+			}
+			prompt, err := resolvePromptPreset(cwd, args[0], vars, time.Now())
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), prompt)
+			return err
+		},
+	}
+	cmd.Flags().StringArrayVar(&vars, "var", nil, "Set a template variable as key=value (repeatable)")
+	return cmd
+}
+
+// writePromptPresetTable prints presets as an aligned table.
+func writePromptPresetTable(out io.Writer, dir string, presets []config.PromptPreset) error {
+	if len(presets) == 0 {
+		_, err := fmt.Fprintf(out, "No prompt presets found. Add .md or .txt files to %s.\n", dir)
+		return err
+	}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tDESCRIPTION")
+	for _, preset := range presets {
+		fmt.Fprintf(writer, "%s\t%s\n", preset.Name, preset.Description)
+	}
+	return writer.Flush()
+}
+
+// resolvePromptPreset loads the named preset and fills in its variables:
+// {{cwd}} and {{date}}, then the --var pairs, which may override them.
+func resolvePromptPreset(cwd string, name string, pairs []string, now time.Time) (string, error) {
+	vars, err := config.ParsePromptVars(pairs)
+	if err != nil {
+		return "", fmt.Errorf("Error: %w", err)
+	}
+	for key, value := range map[string]string{"cwd": cwd, "date": now.Format("2006-01-02")} {
+		if _, ok := vars[key]; !ok {
+			vars[key] = value
+		}
+	}
+	dir, err := config.PromptPresetDir()
+	if err != nil {
+		return "", err
+	}
+	preset, err := config.LoadPromptPreset(dir, name)
+	if err != nil {
+		return "", fmt.Errorf("Error: %w", err)
+	}
+	prompt, err := config.RenderPromptTemplate(preset.Template, vars)
+	if err != nil {
+		return "", fmt.Errorf("Error: prompt preset %s: %w", name, err)
+	}
+	return prompt, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSystemPromptPreset verifies --system-prompt-preset fills in built-in and
+// --var variables and conflicts with the other system prompt flags.
+func TestSystemPromptPreset(testingHandle *testing.T) {
+	// Arrange a preset library in a temporary home.
+	home := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", home)
+	dir := filepath.Join(home, ".openclaude", "prompts")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(dir, 0o755), "create prompts dir")
+	template := "You work in {{cwd}} on {{date}} for {{team}}."
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "team.md"), []byte(template), 0o644), "write preset")
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+
+	// Act
+	prompt, err := resolvePromptPreset("/work/app", "team", []string{"team=core"}, now)
+	overridden, overrideErr := resolvePromptPreset("/work/app", "team", []string{"team=core", "date=release day"}, now)
+	_, missingErr := resolvePromptPreset("/work/app", "team", nil, now)
+	opts := &options{SystemPromptPreset: "team", PromptVars: []string{"team=web"}}
+	flagErr := applyPromptFileOverrides(opts, "/work/app")
+	conflictErr := applyPromptFileOverrides(&options{SystemPrompt: "x", SystemPromptPreset: "team"}, "/work/app")
+	strayErr := applyPromptFileOverrides(&options{PromptVars: []string{"team=web"}}, "/work/app")
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "resolve preset")
+	testutil.RequireEqual(testingHandle, prompt, "You work in /work/app on 2026-03-04 for core.", "rendered preset")
+	testutil.RequireNoError(testingHandle, overrideErr, "override built-in")
+	testutil.RequireTrue(testingHandle, strings.Contains(overridden, "on release day for"), "--var overrides date")
+	testutil.RequireTrue(testingHandle, missingErr != nil && strings.Contains(missingErr.Error(), "--var team="), "missing variable reported")
+	testutil.RequireNoError(testingHandle, flagErr, "preset flag")
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(opts.SystemPrompt, "for web."), "preset becomes the system prompt")
+	testutil.RequireTrue(testingHandle, conflictErr != nil && strings.Contains(conflictErr.Error(), "Cannot use --system-prompt-preset"), "conflicting flags")
+	testutil.RequireTrue(testingHandle, strayErr != nil && strings.Contains(strayErr.Error(), "--var requires --system-prompt-preset"), "--var without preset")
+}
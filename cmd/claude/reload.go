@@ -0,0 +1,356 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// runtimeConfig bundles the on-disk configuration that /reload and SIGHUP refresh.
+type runtimeConfig struct {
+	// Provider is the gateway config from ~/.openclaude/config.json.
+	Provider *config.ProviderConfig
+	// Settings are the merged Claude-style settings files.
+	Settings *config.Settings
+	// Agents are the named subagents from .claude/agents.
+	Agents []config.AgentDefinition
+	// PolicyRules are the allow rules from the --permission-policy file.
+	PolicyRules []string
+	// PolicyLimits are the declarative approvals and limits from the policy file.
+	PolicyLimits *tools.PolicyLimits
+	// Warnings describe files that were skipped while loading, such as a
+	// malformed agent definition.
+	Warnings []string
+}
+
+// loadRuntimeConfig reads the provider config, layered settings, and agent definitions.
+func loadRuntimeConfig(cwd string, opts *options) (*runtimeConfig, error) {
+	providerCfg, err := config.LoadProviderConfig("")
+	if err != nil {
+		if errors.Is(err, config.ErrProviderConfigMissing) {
+			return nil, fmt.Errorf("provider config missing; create %s", mustProviderPath())
+		}
+		return nil, fmt.Errorf("load provider config: %w", err)
+	}
+
+	settingSources := splitListArgs(opts.SettingSources)
+	settings, err := config.LoadClaudeSettings(cwd, settingSources, opts.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("load settings: %w", err)
+	}
+	if err := validateSettingsPermissions(settings); err != nil {
+		return nil, err
+	}
+	agentDefinitions, warnings, err := config.LoadAgentDefinitions(cwd)
+	if err != nil {
+		return nil, fmt.Errorf("load agents: %w", err)
+	}
+	var policyRules []string
+	var policyLimits *tools.PolicyLimits
+	if opts.PermissionPolicy != "" {
+		policy, err := config.LoadPermissionPolicy(opts.PermissionPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("load permission policy: %w", err)
+		}
+		policyRules = policy.Allow
+		policyLimits = permissionPolicyLimits(policy)
+	}
+	return &runtimeConfig{Provider: providerCfg, Settings: settings, Agents: agentDefinitions, PolicyRules: policyRules, PolicyLimits: policyLimits, Warnings: warnings}, nil
+}
+
+// currentRuntimeConfig returns the configuration the session is running with.
+func currentRuntimeConfig(opts *options) *runtimeConfig {
+	return &runtimeConfig{
+		Provider:     opts.ProviderConfig,
+		Settings:     opts.ClaudeSettings,
+		Agents:       opts.AgentDefinitions,
+		PolicyRules:  opts.PermissionPolicyRules,
+		PolicyLimits: opts.PermissionPolicyLimits,
+	}
+}
+
+// permissionPolicyLimits returns the declarative part of a policy, or nil when it has none.
+func permissionPolicyLimits(policy *config.PermissionPolicy) *tools.PolicyLimits {
+	if len(policy.Tools) == 0 && len(policy.BashPrefixes) == 0 && len(policy.Paths) == 0 && policy.MaxFileSizeBytes == 0 {
+		return nil
+	}
+	return &tools.PolicyLimits{
+		Tools:            policy.Tools,
+		BashPrefixes:     policy.BashPrefixes,
+		Paths:            policy.Paths,
+		MaxFileSizeBytes: policy.MaxFileSizeBytes,
+	}
+}
+
+// applyRuntimeConfig installs a freshly loaded config on the options and the runner.
+// It rebuilds the client, pricing, model catalog, permission rules, tool set, and
+// Task executor in place; the session model and permission mode are left unchanged.
+func applyRuntimeConfig(runner *agent.Runner, opts *options, cfg *runtimeConfig, model string) error {
+	previous := currentRuntimeConfig(opts)
+	opts.ProviderConfig = cfg.Provider
+	opts.ClaudeSettings = cfg.Settings
+	opts.AgentDefinitions = cfg.Agents
+	opts.PermissionPolicyRules = cfg.PolicyRules
+	opts.PermissionPolicyLimits = cfg.PolicyLimits
+	if runner == nil {
+		return nil
+	}
+
+	toolContext := runner.ToolContext
+	toolRunner, _, err := buildTools(opts, toolContext.Sandbox, toolContext.CWD, toolContext.Store, toolContext.SessionID, runner.Permissions.Mode)
+	if err != nil {
+		// Keep the previous config so a bad deny list does not strand the session.
+		opts.ProviderConfig = previous.Provider
+		opts.ClaudeSettings = previous.Settings
+		opts.AgentDefinitions = previous.Agents
+		opts.PermissionPolicyRules = previous.PolicyRules
+		opts.PermissionPolicyLimits = previous.PolicyLimits
+		return err
+	}
+
+	providerCfg := cfg.Provider
+	// The config was validated on load, so its redaction patterns compile.
+	runner.Redactor, _ = secretRedactor(providerCfg)
+	runner.Client = newAPIClient(opts, providerCfg)
+	runner.ToolRunner = toolRunner
+	runner.Pricing = providerCfg.Pricing
+	runner.Models = providerCfg.Models
+	runner.RequestParams = resolveRequestParams(opts, providerCfg)
+	runner.MaxParallelTasks = providerCfg.MaxParallelTasks
+	runner.ThinkingFormat = providerCfg.ThinkingFormat
+	runner.ServerTools = providerCfg.ServerTools
+	runner.TurnTimeout = resolveTurnTimeout(opts, providerCfg)
+	runner.Permissions.AlwaysAllow = permissionAllowRules(opts)
+	runner.Permissions.Deny = settingsDenyRules(opts)
+	runner.Permissions.Policy = opts.PermissionPolicyLimits
+	if toolContext.Sandbox != nil && cfg.Settings != nil {
+		toolContext.Sandbox.HidePatterns = cfg.Settings.HidePatterns
+	}
+	runner.ToolContext.TaskExecutor = buildTaskExecutor(runner, opts, providerCfg, model)
+	return nil
+}
+
+// permissionAllowRules combines settings allow rules, the permission policy, and session approvals.
+func permissionAllowRules(opts *options) []string {
+	rules := settingsAllowedTools(opts)
+	if opts != nil {
+		rules = append(rules, opts.PermissionPolicyRules...)
+		rules = append(rules, opts.SessionApprovals...)
+	}
+	return rules
+}
+
+// settingsAllowedTools returns the settings allow rules with canonical tool
+// names; specifier rules such as "Bash(npm test:*)" are matched per call.
+func settingsAllowedTools(opts *options) []string {
+	if opts == nil || opts.ClaudeSettings == nil {
+		return nil
+	}
+	return normalizePermissionRules(opts.ClaudeSettings.Permissions.Allow)
+}
+
+// settingsDeniedTools returns tools removed by whole-tool settings deny rules.
+func settingsDeniedTools(opts *options) []string {
+	if opts == nil || opts.ClaudeSettings == nil {
+		return nil
+	}
+	return normalizeToolList(wholeToolRules(opts.ClaudeSettings.Permissions.Deny))
+}
+
+// settingsDenyRules returns the settings deny rules with specifiers, which
+// tools.Permissions.CheckDeny enforces at call time.
+func settingsDenyRules(opts *options) []string {
+	if opts == nil || opts.ClaudeSettings == nil {
+		return nil
+	}
+	var rules []string
+	for _, rule := range normalizePermissionRules(opts.ClaudeSettings.Permissions.Deny) {
+		if _, _, hasSpecifier := tools.ParsePermissionRule(rule); hasSpecifier {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// wholeToolRules keeps rules that name an entire tool (e.g., "Bash").
+// Rules with specifiers such as "Bash(npm test:*)" are enforced per call instead.
+func wholeToolRules(rules []string) []string {
+	var names []string
+	for _, rule := range rules {
+		if strings.Contains(rule, "(") {
+			continue
+		}
+		names = append(names, rule)
+	}
+	return names
+}
+
+// normalizePermissionRules canonicalizes the tool name of each rule, keeping
+// any specifier, so "bash(rm:*)" matches Bash calls.
+func normalizePermissionRules(rules []string) []string {
+	normalized := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		name, specifier, hasSpecifier := tools.ParsePermissionRule(rule)
+		name = normalizeToolList([]string{name})[0]
+		if hasSpecifier {
+			name += "(" + specifier + ")"
+		}
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// validateSettingsPermissions rejects malformed settings permission rules, so
+// a typo in a deny list fails loudly instead of leaving a tool unguarded.
+func validateSettingsPermissions(settings *config.Settings) error {
+	if settings == nil {
+		return nil
+	}
+	for _, rule := range settings.Permissions.Allow {
+		if err := config.ValidatePermissionRule(rule); err != nil {
+			return fmt.Errorf("settings permissions.allow: %w", err)
+		}
+	}
+	for _, rule := range settings.Permissions.Deny {
+		if err := config.ValidatePermissionRule(rule); err != nil {
+			return fmt.Errorf("settings permissions.deny: %w", err)
+		}
+	}
+	return nil
+}
+
+// describeConfigChanges summarizes what differs between two runtime configs.
+// Secrets are never included; a changed API key is reported as rotated.
+func describeConfigChanges(previous *runtimeConfig, next *runtimeConfig) []string {
+	var changes []string
+	oldProvider := previous.Provider
+	if oldProvider == nil {
+		oldProvider = &config.ProviderConfig{}
+	}
+	newProvider := next.Provider
+	if newProvider == nil {
+		newProvider = &config.ProviderConfig{}
+	}
+	if oldProvider.APIBaseURL != newProvider.APIBaseURL {
+		changes = append(changes, fmt.Sprintf("API base URL: %s -> %s", oldProvider.APIBaseURL, newProvider.APIBaseURL))
+	}
+	if oldProvider.APIKey != newProvider.APIKey {
+		changes = append(changes, "API key rotated")
+	}
+	if !reflect.DeepEqual(oldProvider.Auth, newProvider.Auth) {
+		changes = append(changes, "auth settings updated")
+	}
+	if oldProvider.APIFlavor != newProvider.APIFlavor {
+		changes = append(changes, fmt.Sprintf("API flavor: %s -> %s", oldProvider.APIFlavor, newProvider.APIFlavor))
+	}
+	if !reflect.DeepEqual(oldProvider.Azure, newProvider.Azure) {
+		changes = append(changes, "Azure settings updated")
+	}
+	if oldProvider.TimeoutMS != newProvider.TimeoutMS {
+		changes = append(changes, fmt.Sprintf("timeout: %dms -> %dms", oldProvider.TimeoutMS, newProvider.TimeoutMS))
+	}
+	if oldProvider.APITimeoutMS != newProvider.APITimeoutMS || oldProvider.StreamIdleTimeoutMS != newProvider.StreamIdleTimeoutMS || oldProvider.TurnTimeoutMS != newProvider.TurnTimeoutMS {
+		changes = append(changes, "API and turn timeouts updated")
+	}
+	if oldProvider.DefaultModel != newProvider.DefaultModel {
+		changes = append(changes, fmt.Sprintf("default model: %s -> %s (use /model to switch this session)", oldProvider.DefaultModel, newProvider.DefaultModel))
+	}
+	if !reflect.DeepEqual(oldProvider.ModelAliases, newProvider.ModelAliases) {
+		changes = append(changes, "model aliases updated")
+	}
+	if !reflect.DeepEqual(oldProvider.Pricing, newProvider.Pricing) {
+		changes = append(changes, "pricing updated")
+	}
+	if !reflect.DeepEqual(oldProvider.Models, newProvider.Models) {
+		changes = append(changes, "model catalog updated")
+	}
+	if oldProvider.ThinkingFormat != newProvider.ThinkingFormat {
+		changes = append(changes, fmt.Sprintf("thinking format: %s -> %s", oldProvider.ThinkingFormat, newProvider.ThinkingFormat))
+	}
+	if !reflect.DeepEqual(oldProvider.ServerTools, newProvider.ServerTools) {
+		changes = append(changes, "server tools updated")
+	}
+	if oldProvider.MaxParallelTasks != newProvider.MaxParallelTasks {
+		changes = append(changes, fmt.Sprintf("max parallel tasks: %d -> %d", oldProvider.MaxParallelTasks, newProvider.MaxParallelTasks))
+	}
+
+	oldSettings := previous.Settings
+	if oldSettings == nil {
+		oldSettings = &config.Settings{}
+	}
+	newSettings := next.Settings
+	if newSettings == nil {
+		newSettings = &config.Settings{}
+	}
+	if !slices.Equal(oldSettings.Permissions.Allow, newSettings.Permissions.Allow) {
+		changes = append(changes, "permission allow rules: "+formatRuleList(newSettings.Permissions.Allow))
+	}
+	if !slices.Equal(oldSettings.Permissions.Deny, newSettings.Permissions.Deny) {
+		changes = append(changes, "permission deny rules: "+formatRuleList(newSettings.Permissions.Deny))
+	}
+	if oldSettings.Permissions.DefaultMode != newSettings.Permissions.DefaultMode {
+		changes = append(changes, "permission defaultMode changed (applies to new sessions)")
+	}
+	if oldSettings.Model != newSettings.Model {
+		changes = append(changes, "settings model changed (use /model to switch this session)")
+	}
+	if !reflect.DeepEqual(oldSettings.Raw["hooks"], newSettings.Raw["hooks"]) {
+		changes = append(changes, "hooks changed (Stop, SubagentStop, and PreCompact hooks apply now; other settings hooks are not run yet)")
+	}
+
+	if !slices.Equal(previous.PolicyRules, next.PolicyRules) {
+		changes = append(changes, "permission policy rules: "+formatRuleList(next.PolicyRules))
+	}
+	if !reflect.DeepEqual(previous.PolicyLimits, next.PolicyLimits) {
+		changes = append(changes, "permission policy limits: "+formatPolicyLimits(next.PolicyLimits))
+	}
+
+	oldAgents := agentDefinitionNames(previous.Agents)
+	newAgents := agentDefinitionNames(next.Agents)
+	if !slices.Equal(oldAgents, newAgents) {
+		changes = append(changes, "agents: "+formatRuleList(newAgents))
+	} else if !reflect.DeepEqual(previous.Agents, next.Agents) {
+		changes = append(changes, "agent definitions updated")
+	}
+	return changes
+}
+
+// agentDefinitionNames lists agent names in load order.
+func agentDefinitionNames(definitions []config.AgentDefinition) []string {
+	names := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		names = append(names, definition.Name)
+	}
+	return names
+}
+
+// formatRuleList renders a rule list for change reports.
+func formatRuleList(rules []string) string {
+	if len(rules) == 0 {
+		return "(none)"
+	}
+	return strings.Join(rules, ", ")
+}
+
+// formatReloadReport renders the /reload result shown in the TUI.
+func formatReloadReport(source string, changes []string, warnings []string) string {
+	var builder strings.Builder
+	if len(changes) == 0 {
+		builder.WriteString("Reloaded configuration (" + source + "): no changes.")
+	} else {
+		builder.WriteString("Reloaded configuration (" + source + "):")
+		for _, change := range changes {
+			builder.WriteString("\n  - " + change)
+		}
+	}
+	for _, warning := range warnings {
+		builder.WriteString("\nWarning: " + warning)
+	}
+	return builder.String()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestDescribeConfigChanges verifies reload reports list changes without leaking secrets.
+func TestDescribeConfigChanges(testingHandle *testing.T) {
+	previous := &runtimeConfig{
+		Provider: &config.ProviderConfig{APIBaseURL: "https://a.example", APIKey: "old-secret", DefaultModel: "m"},
+		Settings: &config.Settings{},
+	}
+	next := &runtimeConfig{
+		Provider: &config.ProviderConfig{APIBaseURL: "https://a.example", APIKey: "new-secret", DefaultModel: "m"},
+		Settings: &config.Settings{Permissions: config.PermissionSettings{Deny: []string{"WebFetch"}}},
+		Agents:   []config.AgentDefinition{{Name: "reviewer"}},
+	}
+
+	changes := describeConfigChanges(previous, next)
+
+	testutil.RequireEqual(testingHandle, changes, []string{
+		"API key rotated",
+		"permission deny rules: WebFetch",
+		"agents: reviewer",
+	}, "reported changes")
+	report := formatReloadReport("/reload", changes, nil)
+	testutil.RequireTrue(testingHandle, !strings.Contains(report, "secret"), "report must not include API keys")
+	testutil.RequireEqual(testingHandle, len(describeConfigChanges(next, next)), 0, "identical configs report no changes")
+}
+
+// TestReloadDeferredUntilRunFinishes verifies SIGHUP reloads wait for the in-flight run and rebuild the runner.
+func TestReloadDeferredUntilRunFinishes(testingHandle *testing.T) {
+	homeDir := testingHandle.TempDir()
+	projectDir := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", homeDir)
+	testingHandle.Chdir(projectDir)
+	writeReloadFixture(testingHandle, filepath.Join(homeDir, ".openclaude", "config.json"),
+		`{"api_base_url":"http://127.0.0.1:1","api_key":"rotated","default_model":"m","pricing":{"m":{"input_per_1m":1}}}`)
+	writeReloadFixture(testingHandle, filepath.Join(homeDir, ".claude", "settings.json"),
+		`{"permissions":{"allow":["bash"],"deny":["WebFetch","Bash(rm:*)"]}}`)
+
+	opts := &options{ProviderConfig: &config.ProviderConfig{APIKey: "original", DefaultModel: "m"}}
+	runner := &agent.Runner{Permissions: tools.Permissions{Mode: tools.PermissionDefault}}
+	model := &tuiModel{opts: opts, runner: runner, model: "m", width: 80, theme: defaultTUITheme(), running: true}
+
+	model.Update(reloadRequestMsg{Source: "SIGHUP"})
+	testutil.RequireEqual(testingHandle, model.pendingReload, "SIGHUP", "reload deferred while running")
+	testutil.RequireTrue(testingHandle, runner.Client == nil, "runner untouched while running")
+
+	model.finishError(nil)
+
+	testutil.RequireTrue(testingHandle, runner.Client != nil, "client rebuilt")
+	testutil.RequireEqual(testingHandle, opts.ProviderConfig.APIKey, "rotated", "provider config replaced")
+	testutil.RequireEqual(testingHandle, runner.Permissions.AlwaysAllow, []string{"Bash"}, "allow rules applied")
+	testutil.RequireTrue(testingHandle, !runner.Permissions.ShouldPrompt("Bash"), "allowed tool skips prompt")
+	_, hasWebFetch := runner.ToolRunner.Tools["WebFetch"]
+	testutil.RequireTrue(testingHandle, !hasWebFetch, "denied tool removed")
+	_, priced := runner.Pricing["m"]
+	testutil.RequireTrue(testingHandle, priced, "pricing reloaded")
+
+	last := model.chatMessages[len(model.chatMessages)-1].Content
+	testutil.RequireTrue(testingHandle, strings.Contains(last, "API key rotated"), "report mentions key rotation")
+	testutil.RequireEqual(testingHandle, runner.Permissions.Deny, []string{"Bash(rm:*)"}, "specifier deny rules applied")
+	testutil.RequireTrue(testingHandle, runner.Permissions.CheckDeny("Bash", json.RawMessage(`{"command":"ls && rm -rf build"}`)) != nil, "denied command refused")
+}
+
+// writeReloadFixture writes a config fixture, creating parent directories.
+func writeReloadFixture(testingHandle *testing.T, path string, contents string) {
+	testingHandle.Helper()
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Dir(path), 0o755), "create fixture dir")
+	testutil.RequireNoError(testingHandle, os.WriteFile(path, []byte(contents), 0o600), "write fixture")
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/spf13/pflag"
+)
+
+// requestParamFlagOverrides returns the request parameters set on the command
+// line. Flags left unset stay unset, so the provider config still applies.
+func requestParamFlagOverrides(flags *pflag.FlagSet, opts *options) (config.RequestParams, error) {
+	var overrides config.RequestParams
+	if flags.Changed("max-tokens") {
+		if opts.MaxTokens <= 0 {
+			return overrides, fmt.Errorf("--max-tokens must be positive, got %d", opts.MaxTokens)
+		}
+		overrides.MaxTokens = opts.MaxTokens
+	}
+	if flags.Changed("temperature") {
+		temperature := opts.Temperature
+		overrides.Temperature = &temperature
+	}
+	if flags.Changed("top-p") {
+		topP := opts.TopP
+		overrides.TopP = &topP
+	}
+	if flags.Changed("stop") {
+		overrides.Stop = append([]string(nil), opts.Stop...)
+	}
+	if err := overrides.Validate(); err != nil {
+		return overrides, fmt.Errorf("invalid request parameters: %w", err)
+	}
+	return overrides, nil
+}
+
+// resolveRequestParams applies the session's overrides to the provider config.
+func resolveRequestParams(opts *options, providerCfg *config.ProviderConfig) config.RequestParams {
+	var params config.RequestParams
+	if providerCfg != nil {
+		params = providerCfg.RequestParams
+	}
+	if opts != nil {
+		params = params.Merge(opts.RequestParams)
+	}
+	return params
+}
+
+// setRequestParam handles /set: with no arguments it lists the parameters,
+// otherwise it overrides one for the rest of the session. "default" drops the
+// override so the provider config applies again.
+func (m *tuiModel) setRequestParam(args string) string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return formatRequestParams(resolveRequestParams(m.opts, m.opts.ProviderConfig))
+	}
+	if len(fields) < 2 {
+		return setUsage
+	}
+	name := strings.ReplaceAll(strings.ToLower(fields[0]), "-", "_")
+	overrides := m.opts.RequestParams
+	reset := len(fields) == 2 && strings.EqualFold(fields[1], "default")
+	switch name {
+	case "max_tokens":
+		overrides.MaxTokens = 0
+		if !reset {
+			value, err := strconv.Atoi(fields[1])
+			if err != nil || value <= 0 || len(fields) > 2 {
+				return "Usage: /set max_tokens <positive integer|default>"
+			}
+			overrides.MaxTokens = value
+		}
+	case "temperature", "top_p":
+		var value *float64
+		if !reset {
+			parsed, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil || len(fields) > 2 {
+				return fmt.Sprintf("Usage: /set %s <number|default>", name)
+			}
+			value = &parsed
+		}
+		if name == "temperature" {
+			overrides.Temperature = value
+		} else {
+			overrides.TopP = value
+		}
+	case "stop":
+		overrides.Stop = nil
+		if !reset {
+			for _, field := range fields[1:] {
+				sequence, err := strconv.Unquote(`"` + strings.ReplaceAll(field, `"`, `\"`) + `"`)
+				if err != nil {
+					return fmt.Sprintf("Invalid stop sequence %q: %v", field, err)
+				}
+				overrides.Stop = append(overrides.Stop, sequence)
+			}
+		}
+	default:
+		return setUsage
+	}
+	if err := overrides.Validate(); err != nil {
+		return fmt.Sprintf("Invalid %s: %v", name, err)
+	}
+	m.opts.RequestParams = overrides
+	params := resolveRequestParams(m.opts, m.opts.ProviderConfig)
+	if m.runner != nil {
+		m.runner.RequestParams = params
+	}
+	return formatRequestParams(params)
+}
+
+// setUsage explains /set's arguments.
+const setUsage = "Usage: /set <max_tokens|temperature|top_p|stop> <value|default>. Stop sequences are space-separated; write \\n for a newline."
+
+// formatRequestParams lists the parameters sent with each request.
+func formatRequestParams(params config.RequestParams) string {
+	maxTokens := "model default"
+	if params.MaxTokens > 0 {
+		maxTokens = strconv.Itoa(params.MaxTokens)
+	}
+	stop := "none"
+	if len(params.Stop) > 0 {
+		quoted := make([]string, len(params.Stop))
+		for index, sequence := range params.Stop {
+			quoted[index] = strconv.Quote(sequence)
+		}
+		stop = strings.Join(quoted, " ")
+	}
+	return fmt.Sprintf("Request parameters:\n  max_tokens: %s\n  temperature: %s\n  top_p: %s\n  stop: %s", maxTokens, formatOptionalFloat(params.Temperature), formatOptionalFloat(params.TopP), stop)
+}
+
+// formatOptionalFloat renders an unset parameter as the gateway's default.
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return "gateway default"
+	}
+	return strconv.FormatFloat(*value, 'g', -1, 64)
+}
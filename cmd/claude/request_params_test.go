@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/spf13/pflag"
+)
+
+// TestRequestParamFlagOverrides verifies only the flags given override the provider config.
+func TestRequestParamFlagOverrides(testingHandle *testing.T) {
+	// Arrange.
+	opts := &options{}
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.IntVar(&opts.MaxTokens, "max-tokens", 0, "")
+	flags.Float64Var(&opts.Temperature, "temperature", 0, "")
+	flags.Float64Var(&opts.TopP, "top-p", 0, "")
+	flags.StringArrayVar(&opts.Stop, "stop", nil, "")
+	testutil.RequireNoError(testingHandle, flags.Parse([]string{"--temperature", "0", "--stop", "END"}), "parse flags")
+	topP := 0.5
+	providerCfg := &config.ProviderConfig{RequestParams: config.RequestParams{MaxTokens: 1024, TopP: &topP}}
+
+	// Act.
+	overrides, err := requestParamFlagOverrides(flags, opts)
+	opts.RequestParams = overrides
+	params := resolveRequestParams(opts, providerCfg)
+
+	// Assert.
+	testutil.RequireNoError(testingHandle, err, "overrides")
+	testutil.RequireEqual(testingHandle, params.MaxTokens, 1024, "max_tokens from config")
+	testutil.RequireEqual(testingHandle, *params.Temperature, 0.0, "explicit zero temperature")
+	testutil.RequireEqual(testingHandle, *params.TopP, 0.5, "top_p from config")
+	testutil.RequireEqual(testingHandle, strings.Join(params.Stop, ","), "END", "stop from flag")
+
+	testutil.RequireNoError(testingHandle, flags.Parse([]string{"--top-p", "1.5"}), "parse flags")
+	_, err = requestParamFlagOverrides(flags, opts)
+	testutil.RequireTrue(testingHandle, err != nil, "out-of-range top_p rejected")
+}
+
+// TestSetRequestParam verifies /set overrides and resets parameters on the runner.
+func TestSetRequestParam(testingHandle *testing.T) {
+	// Arrange.
+	providerCfg := &config.ProviderConfig{RequestParams: config.RequestParams{MaxTokens: 1024}}
+	model := &tuiModel{opts: &options{ProviderConfig: providerCfg}, runner: &agent.Runner{}}
+
+	// Act and assert each step.
+	testutil.RequireTrue(testingHandle, strings.Contains(model.setRequestParam(""), "max_tokens: 1024"), "lists config values")
+
+	model.setRequestParam("max_tokens 8192")
+	testutil.RequireEqual(testingHandle, model.runner.RequestParams.MaxTokens, 8192, "override max_tokens")
+
+	output := model.setRequestParam(`stop ### \n\n`)
+	testutil.RequireEqual(testingHandle, strings.Join(model.runner.RequestParams.Stop, "|"), "###|\n\n", "stop with escapes")
+	testutil.RequireTrue(testingHandle, strings.Contains(output, `stop: "###" "\n\n"`), "stop listed quoted")
+
+	model.setRequestParam("temperature 0.3")
+	testutil.RequireEqual(testingHandle, *model.runner.RequestParams.Temperature, 0.3, "temperature")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(model.setRequestParam("temperature 3"), "Invalid temperature"), "out of range")
+	testutil.RequireEqual(testingHandle, *model.runner.RequestParams.Temperature, 0.3, "rejected value not applied")
+
+	model.setRequestParam("max_tokens default")
+	testutil.RequireEqual(testingHandle, model.runner.RequestParams.MaxTokens, 1024, "reset to config")
+	testutil.RequireEqual(testingHandle, model.setRequestParam("seed 1"), setUsage, "unknown parameter")
+}
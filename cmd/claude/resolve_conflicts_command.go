@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxConflictHunkBytes caps a conflict quoted in the /resolve-conflicts
+	// prompt; larger ones are left for the model to read.
+	maxConflictHunkBytes = 8 << 10
+	// maxConflictHunks caps the conflicts quoted across all files.
+	maxConflictHunks = 50
+)
+
+// Conflict marker prefixes as git writes them.
+const (
+	conflictOursMarker   = "<<<<<<<"
+	conflictBaseMarker   = "|||||||"
+	conflictSplitMarker  = "======="
+	conflictTheirsMarker = ">>>>>>>"
+)
+
+// conflictHunk is one conflict region in a file.
+type conflictHunk struct {
+	// StartLine and EndLine are the 1-based lines of the opening and closing markers.
+	StartLine int
+	EndLine   int
+	// OursLabel and TheirsLabel follow the opening and closing markers, e.g. "HEAD".
+	OursLabel   string
+	TheirsLabel string
+	// Ours, Base, and Theirs are each side's lines; Base is set only for diff3-style markers.
+	Ours    string
+	Base    string
+	Theirs  string
+	HasBase bool
+	// Block is the whole region, markers included: the text an Edit replaces.
+	Block string
+}
+
+// conflictFile is a file with conflicts for /resolve-conflicts.
+type conflictFile struct {
+	// Path is relative to the repository working directory.
+	Path string
+	// Hunks are the conflicts in file order.
+	Hunks []conflictHunk
+}
+
+// parseConflictHunks finds complete conflict regions in content. Markers that
+// do not form a complete region are left alone.
+func parseConflictHunks(content string) []conflictHunk {
+	var hunks []conflictHunk
+	var current *conflictHunk
+	var ours, base, theirs, block strings.Builder
+	section := ""
+	lines := strings.SplitAfter(content, "\n")
+	for index, line := range lines {
+		text := strings.TrimRight(line, "\r\n")
+		if current == nil {
+			if label, ok := conflictMarker(text, conflictOursMarker); ok {
+				current = &conflictHunk{StartLine: index + 1, OursLabel: label}
+				ours.Reset()
+				base.Reset()
+				theirs.Reset()
+				block.Reset()
+				block.WriteString(line)
+				section = "ours"
+			}
+			continue
+		}
+		block.WriteString(line)
+		switch {
+		case section == "ours" && isConflictMarker(text, conflictBaseMarker):
+			current.HasBase = true
+			section = "base"
+		case (section == "ours" || section == "base") && text == conflictSplitMarker:
+			section = "theirs"
+		case section == "theirs" && isConflictMarker(text, conflictTheirsMarker):
+			current.TheirsLabel, _ = conflictMarker(text, conflictTheirsMarker)
+			current.EndLine = index + 1
+			current.Ours, current.Base, current.Theirs = ours.String(), base.String(), theirs.String()
+			current.Block = block.String()
+			hunks = append(hunks, *current)
+			current = nil
+		case section == "ours":
+			ours.WriteString(line)
+		case section == "base":
+			base.WriteString(line)
+		default:
+			theirs.WriteString(line)
+		}
+	}
+	return hunks
+}
+
+// conflictMarker reports whether text is the marker followed by an optional label.
+func conflictMarker(text string, marker string) (string, bool) {
+	if !isConflictMarker(text, marker) {
+		return "", false
+	}
+	return strings.TrimSpace(text[len(marker):]), true
+}
+
+// isConflictMarker reports whether text starts with marker as a whole word.
+func isConflictMarker(text string, marker string) bool {
+	return text == marker || strings.HasPrefix(text, marker+" ")
+}
+
+// findConflictFiles returns the files to resolve: the given paths, else the
+// unmerged paths in the index, else tracked files that still hold markers.
+func findConflictFiles(ctx context.Context, dir string, paths []string) ([]conflictFile, error) {
+	if len(paths) == 0 {
+		unmerged, err := runReviewCommand(ctx, dir, "git", "diff", "--name-only", "--diff-filter=U")
+		if err != nil {
+			return nil, err
+		}
+		// Unmerged paths are relative to the top of the work tree.
+		top, err := runReviewCommand(ctx, dir, "git", "rev-parse", "--show-toplevel")
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range nonEmptyFields(unmerged) {
+			absolute := filepath.Join(strings.TrimSpace(top), filepath.FromSlash(path))
+			if relative, err := filepath.Rel(dir, absolute); err == nil {
+				absolute = relative
+			}
+			paths = append(paths, absolute)
+		}
+	}
+	if len(paths) == 0 {
+		// git grep exits non-zero when nothing matches.
+		marked, _ := runReviewCommand(ctx, dir, "git", "grep", "-l", "-E", "^<{7}( |$)")
+		paths = nonEmptyFields(marked)
+	}
+	var files []conflictFile
+	for _, path := range paths {
+		full := path
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(full)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		if hunks := parseConflictHunks(string(data)); len(hunks) > 0 {
+			files = append(files, conflictFile{Path: path, Hunks: hunks})
+		}
+	}
+	return files, nil
+}
+
+// nonEmptyFields splits command output into unique non-blank lines.
+func nonEmptyFields(output string) []string {
+	var fields []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !seen[line] {
+			seen[line] = true
+			fields = append(fields, line)
+		}
+	}
+	return fields
+}
+
+// gitOperation names the merge, rebase, cherry-pick, or revert in progress in dir.
+func gitOperation(ctx context.Context, dir string) string {
+	gitDir, err := runReviewCommand(ctx, dir, "git", "rev-parse", "--git-dir")
+	if err != nil {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	for _, candidate := range []struct{ path, name string }{
+		{"rebase-merge", "rebase"},
+		{"rebase-apply", "rebase"},
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+		{"REVERT_HEAD", "revert"},
+	} {
+		if _, err := os.Stat(filepath.Join(gitDir, candidate.path)); err == nil {
+			return candidate.name
+		}
+	}
+	return ""
+}
+
+// buildResolveConflictsPrompt asks the model to resolve each conflict with its
+// own Edit call, quoting ours, base, and theirs, so every hunk goes through the
+// usual edit approval on its own. args optionally limits the files.
+func buildResolveConflictsPrompt(ctx context.Context, dir string, args string) (string, error) {
+	files, err := findConflictFiles(ctx, dir, strings.Fields(args))
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("No merge conflicts found.")
+	}
+	total := 0
+	for _, file := range files {
+		total += len(file.Hunks)
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Resolve the %d git merge conflict(s) below in %d file(s), one conflict at a time.\n", total, len(files))
+	switch operation := gitOperation(ctx, dir); operation {
+	case "rebase":
+		builder.WriteString("A rebase is in progress: \"ours\" is the branch being rebased onto and \"theirs\" is the commit being replayed.\n")
+	case "":
+	default:
+		fmt.Fprintf(&builder, "A %s is in progress: \"ours\" is the current branch and \"theirs\" is the change being applied.\n", operation)
+	}
+	builder.WriteString("\nFor each conflict:\n" +
+		"1. Read the code around it so you understand what each side changed.\n" +
+		"2. Say in a sentence or two what ours and theirs each do and how you will combine them. Keep both intents unless they contradict; if they do, explain the choice.\n" +
+		"3. Apply the resolution with one Edit call whose old_string is the whole conflict, from the <<<<<<< line through the >>>>>>> line, and whose new_string is the resolved code without markers.\n" +
+		"Use one Edit per conflict and never Write, Bash, or replace_all, so I can approve or reject each hunk on its own.\n" +
+		"After the last conflict, Grep the files for `^(<<<<<<<|>>>>>>>)( |$)` to confirm no markers remain, then list the resolved files. Do not stage, commit, or continue the operation; tell me the git commands to finish it.\n")
+
+	quoted := 0
+	for _, file := range files {
+		fmt.Fprintf(&builder, "\n## %s (%d conflict(s))\n", file.Path, len(file.Hunks))
+		hasBase := true
+		for index, hunk := range file.Hunks {
+			hasBase = hasBase && hunk.HasBase
+			fmt.Fprintf(&builder, "\n### Conflict %d, lines %d-%d\n", index+1, hunk.StartLine, hunk.EndLine)
+			if quoted >= maxConflictHunks || len(hunk.Block) > maxConflictHunkBytes {
+				builder.WriteString("Too large to quote here; Read these lines.\n")
+				continue
+			}
+			quoted++
+			writeConflictSide(&builder, "Ours", hunk.OursLabel, hunk.Ours)
+			if hunk.HasBase {
+				writeConflictSide(&builder, "Base", "", hunk.Base)
+			}
+			writeConflictSide(&builder, "Theirs", hunk.TheirsLabel, hunk.Theirs)
+		}
+		if !hasBase {
+			fmt.Fprintf(&builder, "\nThe markers in %s have no base section; run `git show :1:%s` if you need the common ancestor.\n", file.Path, file.Path)
+		}
+	}
+	return builder.String(), nil
+}
+
+// writeConflictSide quotes one side of a conflict in a fenced block.
+func writeConflictSide(builder *strings.Builder, name string, label string, text string) {
+	if label != "" {
+		name += " (" + label + ")"
+	}
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	builder.WriteString(name + ":\n```\n" + text + "```\n")
+}
+
+// warnUnapprovedEdits tells the user when edits would skip the approval
+// prompt that /resolve-conflicts relies on for per-hunk review.
+func (m *tuiModel) warnUnapprovedEdits() {
+	if m.runner == nil || m.runner.Permissions.ShouldPromptCall("Edit", nil) {
+		return
+	}
+	m.appendSystemMessage(fmt.Sprintf("Edits are approved without asking (permission mode %s or an allow rule), so each conflict resolution will be applied without per-hunk review.", m.runner.Permissions.Mode))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestParseConflictHunks verifies plain and diff3 markers are split into
+// sides and incomplete regions are ignored.
+func TestParseConflictHunks(testingHandle *testing.T) {
+	// Arrange
+	content := strings.Join([]string{
+		"package main",
+		"<<<<<<< HEAD",
+		"const name = \"ours\"",
+		"=======",
+		"const name = \"theirs\"",
+		">>>>>>> feature",
+		"func main() {}",
+		"<<<<<<< HEAD",
+		"a := 1",
+		"||||||| base",
+		"a := 0",
+		"=======",
+		"a := 2",
+		">>>>>>> feature",
+		"<<<<<<< HEAD",
+		"never closed",
+		"",
+	}, "\n")
+
+	// Act
+	hunks := parseConflictHunks(content)
+
+	// Assert
+	testutil.RequireEqual(testingHandle, len(hunks), 2, "complete hunks")
+	testutil.RequireEqual(testingHandle, hunks[0].StartLine, 2, "first start")
+	testutil.RequireEqual(testingHandle, hunks[0].EndLine, 6, "first end")
+	testutil.RequireEqual(testingHandle, hunks[0].OursLabel, "HEAD", "ours label")
+	testutil.RequireEqual(testingHandle, hunks[0].TheirsLabel, "feature", "theirs label")
+	testutil.RequireEqual(testingHandle, hunks[0].Theirs, "const name = \"theirs\"\n", "theirs side")
+	testutil.RequireTrue(testingHandle, !hunks[0].HasBase, "plain markers have no base")
+	testutil.RequireTrue(testingHandle, strings.Contains(content, hunks[0].Block) && strings.HasPrefix(hunks[0].Block, "<<<<<<< HEAD\n") && strings.HasSuffix(hunks[0].Block, ">>>>>>> feature\n"), "block spans the markers")
+	testutil.RequireTrue(testingHandle, hunks[1].HasBase, "diff3 base")
+	testutil.RequireEqual(testingHandle, hunks[1].Ours, "a := 1\n", "diff3 ours")
+	testutil.RequireEqual(testingHandle, hunks[1].Base, "a := 0\n", "diff3 base side")
+}
+
+// TestBuildResolveConflictsPrompt verifies a merge conflict is found from the
+// index and quoted with per-hunk Edit instructions.
+func TestBuildResolveConflictsPrompt(testingHandle *testing.T) {
+	// Arrange a merge that conflicts on base.txt.
+	root := reviewGitRepo(testingHandle)
+	ctx := context.Background()
+	_, cleanErr := buildResolveConflictsPrompt(ctx, root, "")
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		_ = cmd.Run()
+	}
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "base.txt"), []byte("feature base\n"), 0o644), "edit on feature")
+	git("commit", "--quiet", "-am", "feature base")
+	git("checkout", "--quiet", "main")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "base.txt"), []byte("main base\n"), 0o644), "edit on main")
+	git("commit", "--quiet", "-am", "main base")
+	git("merge", "--quiet", "feature")
+
+	// Act
+	prompt, err := buildResolveConflictsPrompt(ctx, root, "")
+
+	// Assert
+	testutil.RequireTrue(testingHandle, cleanErr != nil && cleanErr.Error() == "No merge conflicts found.", "clean tree")
+	testutil.RequireNoError(testingHandle, err, "conflict prompt")
+	for _, want := range []string{"1 git merge conflict(s) below in 1 file(s)", "A merge is in progress", "one Edit call whose old_string is the whole conflict", "## base.txt (1 conflict(s))", "### Conflict 1, lines 1-5", "Ours (HEAD):\n```\nmain base\n```", "Theirs (feature):\n```\nfeature base\n```", "git show :1:base.txt"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(prompt, want), "prompt contains "+want+"\n"+prompt)
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/project"
+)
+
+const (
+	// reviewCommandTimeout bounds each git or gh call made by /review and /pr-comments.
+	reviewCommandTimeout = 30 * time.Second
+	// maxReviewDiffBytes caps the diff embedded in the review prompt.
+	maxReviewDiffBytes = 200 << 10
+)
+
+// reviewTarget selects what /review examines.
+type reviewTarget struct {
+	// PR is a pull request number, URL, or branch; empty reviews local changes.
+	PR string
+	// Staged reviews only the index instead of the branch diff.
+	Staged bool
+	// Post asks the model to publish the review on the pull request with gh.
+	Post bool
+}
+
+// parseReviewArgs reads "/review [staged | <pr>] [--post]".
+func parseReviewArgs(args string) (reviewTarget, error) {
+	var target reviewTarget
+	for _, field := range strings.Fields(args) {
+		switch field {
+		case "--post":
+			target.Post = true
+		case "staged", "--staged":
+			target.Staged = true
+		default:
+			if target.PR != "" {
+				return reviewTarget{}, fmt.Errorf("usage: /review [staged | <pr>] [--post]")
+			}
+			target.PR = field
+		}
+	}
+	if target.Staged && target.PR != "" {
+		return reviewTarget{}, fmt.Errorf("/review takes either staged or a pull request, not both")
+	}
+	if target.Post && target.PR == "" {
+		return reviewTarget{}, fmt.Errorf("/review --post needs a pull request number or URL")
+	}
+	return target, nil
+}
+
+// buildReviewPrompt gathers the diff for /review and wraps it in review instructions.
+// Without arguments it reviews the working tree against the merge base with the
+// default branch, which covers both committed and uncommitted branch changes.
+func buildReviewPrompt(ctx context.Context, dir string, args string) (string, error) {
+	target, err := parseReviewArgs(args)
+	if err != nil {
+		return "", err
+	}
+	var description, details, diff string
+	switch {
+	case target.PR != "":
+		output, err := runReviewCommand(ctx, dir, "gh", "pr", "view", target.PR, "--json", "number,title,body,url,baseRefName,headRefName")
+		if err != nil {
+			return "", err
+		}
+		var pr struct {
+			Number      int    `json:"number"`
+			Title       string `json:"title"`
+			Body        string `json:"body"`
+			URL         string `json:"url"`
+			BaseRefName string `json:"baseRefName"`
+			HeadRefName string `json:"headRefName"`
+		}
+		if err := json.Unmarshal([]byte(output), &pr); err != nil {
+			return "", fmt.Errorf("parse gh pr view output: %w", err)
+		}
+		description = fmt.Sprintf("pull request #%d (%s into %s)", pr.Number, pr.HeadRefName, pr.BaseRefName)
+		details = fmt.Sprintf("Title: %s\nURL: %s\n", pr.Title, pr.URL)
+		if body := strings.TrimSpace(pr.Body); body != "" {
+			details += "Description:\n" + body + "\n"
+		}
+		target.PR = fmt.Sprint(pr.Number)
+		if diff, err = runReviewCommand(ctx, dir, "gh", "pr", "diff", target.PR); err != nil {
+			return "", err
+		}
+	case target.Staged:
+		description = "staged changes"
+		if diff, err = runReviewCommand(ctx, dir, "git", "diff", "--cached"); err != nil {
+			return "", err
+		}
+	default:
+		description = "uncommitted changes"
+		base := "HEAD"
+		if mainBranch := project.GitMainBranch(ctx, dir); mainBranch != "" {
+			if mergeBase, err := runReviewCommand(ctx, dir, "git", "merge-base", "HEAD", mainBranch); err == nil {
+				base = strings.TrimSpace(mergeBase)
+				description = "changes on this branch against " + mainBranch + ", including uncommitted work"
+			}
+		}
+		if diff, err = runReviewCommand(ctx, dir, "git", "diff", base); err != nil {
+			return "", err
+		}
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", fmt.Errorf("No %s to review.", description)
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Review the following %s. Focus on correctness bugs, security issues, missing tests, and maintainability; skip style nits a formatter would catch. Read surrounding code with the tools when the diff lacks context.\n\n", description)
+	builder.WriteString("Reply in this structure:\n## Summary\nOne paragraph on what the change does and its overall quality.\n## Comments\nOne bullet per finding: `path:line` — **blocker**, **suggestion**, or **nit** — the problem and a concrete fix. Write \"None.\" when there are no findings.\n## Verdict\napprove, request changes, or comment.\n")
+	if target.Post {
+		fmt.Fprintf(&builder, "\nThen post the review on pull request #%s: write it to a temporary file and run `gh pr review %s --comment --body-file <file>`.\n", target.PR, target.PR)
+	}
+	if details != "" {
+		builder.WriteString("\n" + details)
+	}
+	builder.WriteString("\n```diff\n" + truncateReviewDiff(diff) + "```\n")
+	return builder.String(), nil
+}
+
+// buildPRCommentsPrompt fetches a pull request's conversation, reviews, and inline
+// comments with gh and asks the model to triage them. Without arguments gh picks
+// the pull request for the current branch.
+func buildPRCommentsPrompt(ctx context.Context, dir string, args string) (string, error) {
+	fields := strings.Fields(args)
+	if len(fields) > 1 {
+		return "", fmt.Errorf("usage: /pr-comments [<pr>]")
+	}
+	viewArgs := []string{"pr", "view"}
+	viewArgs = append(viewArgs, fields...)
+	output, err := runReviewCommand(ctx, dir, "gh", append(viewArgs, "--json", "number,title,url,comments,reviews")...)
+	if err != nil {
+		return "", err
+	}
+	type author struct {
+		Login string `json:"login"`
+	}
+	var pr struct {
+		Number   int    `json:"number"`
+		Title    string `json:"title"`
+		URL      string `json:"url"`
+		Comments []struct {
+			Author author `json:"author"`
+			Body   string `json:"body"`
+		} `json:"comments"`
+		Reviews []struct {
+			Author author `json:"author"`
+			Body   string `json:"body"`
+			State  string `json:"state"`
+		} `json:"reviews"`
+	}
+	if err := json.Unmarshal([]byte(output), &pr); err != nil {
+		return "", fmt.Errorf("parse gh pr view output: %w", err)
+	}
+	inlineOutput, err := runReviewCommand(ctx, dir, "gh", "api", "--paginate", fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/comments", pr.Number))
+	if err != nil {
+		return "", err
+	}
+	type inlineComment struct {
+		Path string `json:"path"`
+		Line int    `json:"line"`
+		User author `json:"user"`
+		Body string `json:"body"`
+	}
+	// --paginate prints one JSON array per page.
+	var inline []inlineComment
+	decoder := json.NewDecoder(strings.NewReader(inlineOutput))
+	for {
+		var page []inlineComment
+		if err := decoder.Decode(&page); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", fmt.Errorf("parse review comments: %w", err)
+		}
+		inline = append(inline, page...)
+	}
+
+	var comments strings.Builder
+	for _, comment := range pr.Comments {
+		if body := strings.TrimSpace(comment.Body); body != "" {
+			fmt.Fprintf(&comments, "- @%s: %s\n", comment.Author.Login, body)
+		}
+	}
+	for _, review := range pr.Reviews {
+		if body := strings.TrimSpace(review.Body); body != "" {
+			fmt.Fprintf(&comments, "- @%s (review, %s): %s\n", review.Author.Login, strings.ToLower(strings.ReplaceAll(review.State, "_", " ")), body)
+		}
+	}
+	for _, comment := range inline {
+		location := comment.Path
+		if comment.Line > 0 {
+			location = fmt.Sprintf("%s:%d", comment.Path, comment.Line)
+		}
+		fmt.Fprintf(&comments, "- `%s` @%s: %s\n", location, comment.User.Login, strings.TrimSpace(comment.Body))
+	}
+	if comments.Len() == 0 {
+		return "", fmt.Errorf("Pull request #%d has no comments.", pr.Number)
+	}
+	return fmt.Sprintf("Here are the comments on pull request #%d, %q (%s).\n\n%s\n"+
+		"List them grouped by file, with general comments first. For each, say whether the current code already addresses it and, if not, propose a concrete change. Do not edit files until I ask.",
+		pr.Number, pr.Title, pr.URL, comments.String()), nil
+}
+
+// truncateReviewDiff caps the diff at maxReviewDiffBytes on a line boundary.
+func truncateReviewDiff(diff string) string {
+	if !strings.HasSuffix(diff, "\n") {
+		diff += "\n"
+	}
+	if len(diff) <= maxReviewDiffBytes {
+		return diff
+	}
+	cut := strings.LastIndex(diff[:maxReviewDiffBytes], "\n") + 1
+	return diff[:cut] + fmt.Sprintf("... diff truncated (%d more bytes); read the remaining files directly.\n", len(diff)-cut)
+}
+
+// runReviewCommand runs git or gh in dir and returns stdout, folding stderr into errors.
+func runReviewCommand(ctx context.Context, dir string, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, reviewCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) && name == "gh" {
+			return "", errors.New("the GitHub CLI (gh) is required for pull requests; install it from https://cli.github.com and run gh auth login")
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%s %s: %s", name, args[0], message)
+		}
+		return "", fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return string(output), nil
+}
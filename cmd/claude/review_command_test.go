@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestParseReviewArgs verifies /review argument combinations.
+func TestParseReviewArgs(testingHandle *testing.T) {
+	target, err := parseReviewArgs("")
+	testutil.RequireNoError(testingHandle, err, "no args")
+	testutil.RequireEqual(testingHandle, target, reviewTarget{}, "branch review")
+
+	target, err = parseReviewArgs("42 --post")
+	testutil.RequireNoError(testingHandle, err, "pr args")
+	testutil.RequireEqual(testingHandle, target, reviewTarget{PR: "42", Post: true}, "pr review")
+
+	target, err = parseReviewArgs("staged")
+	testutil.RequireNoError(testingHandle, err, "staged args")
+	testutil.RequireTrue(testingHandle, target.Staged, "staged review")
+
+	for _, args := range []string{"1 2", "staged 3", "--post", "staged --post"} {
+		_, err := parseReviewArgs(args)
+		testutil.RequireTrue(testingHandle, err != nil, "rejects "+args)
+	}
+}
+
+// reviewGitRepo creates a repository with a main commit and a feature branch commit.
+func reviewGitRepo(testingHandle *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		testingHandle.Skip("git not installed")
+	}
+	root := testingHandle.TempDir()
+	testingHandle.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(root, ".gitconfig-none"))
+	testingHandle.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		output, err := cmd.CombinedOutput()
+		testutil.RequireNoError(testingHandle, err, "git "+strings.Join(args, " ")+": "+string(output))
+	}
+	write := func(name string, content string) {
+		testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, name), []byte(content), 0o644), "write "+name)
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	git("config", "user.email", "dev@example.com")
+	git("config", "user.name", "Dev")
+	write("base.txt", "base\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "base")
+	git("checkout", "--quiet", "-b", "feature")
+	write("feature.txt", "committed feature line\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "feature")
+	return root
+}
+
+// TestBuildReviewPromptLocalDiffs verifies branch and staged diffs feed the review prompt.
+func TestBuildReviewPromptLocalDiffs(testingHandle *testing.T) {
+	root := reviewGitRepo(testingHandle)
+	ctx := context.Background()
+
+	_, err := buildReviewPrompt(ctx, root, "staged")
+	testutil.RequireTrue(testingHandle, err != nil && err.Error() == "No staged changes to review.", "empty index")
+
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "base.txt"), []byte("base\nuncommitted line\n"), 0o644), "edit base")
+	prompt, err := buildReviewPrompt(ctx, root, "")
+	testutil.RequireNoError(testingHandle, err, "branch review")
+	for _, want := range []string{"changes on this branch against main", "+committed feature line", "+uncommitted line", "## Comments", "```diff\n"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(prompt, want), "branch prompt contains "+want)
+	}
+
+	cmd := exec.Command("git", "add", "base.txt")
+	cmd.Dir = root
+	testutil.RequireNoError(testingHandle, cmd.Run(), "stage base")
+	prompt, err = buildReviewPrompt(ctx, root, "staged")
+	testutil.RequireNoError(testingHandle, err, "staged review")
+	testutil.RequireTrue(testingHandle, strings.Contains(prompt, "+uncommitted line") && !strings.Contains(prompt, "committed feature line"), "staged prompt holds only the index")
+}
+
+// fakeGH installs a gh stub on PATH that answers pr view, pr diff, and api calls.
+func fakeGH(testingHandle *testing.T) {
+	if runtime.GOOS == "windows" {
+		testingHandle.Skip("shell stub requires a POSIX shell")
+	}
+	dir := testingHandle.TempDir()
+	script := `#!/bin/sh
+case "$1 $2" in
+"pr view") echo '{"number":7,"title":"Add parser","body":"Parses things.","url":"https://github.com/o/r/pull/7","baseRefName":"main","headRefName":"parser","comments":[{"author":{"login":"alice"},"body":"Looks close."}],"reviews":[{"author":{"login":"bob"},"body":"Needs tests.","state":"CHANGES_REQUESTED"},{"author":{"login":"carol"},"body":"","state":"APPROVED"}]}' ;;
+"pr diff") printf 'diff --git a/parser.go b/parser.go\n+func Parse() {}\n' ;;
+"api --paginate") echo '[{"path":"parser.go","line":3,"user":{"login":"bob"},"body":"Handle empty input."}][]' ;;
+*) echo "unexpected: $*" >&2; exit 1 ;;
+esac
+`
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0o755), "write gh stub")
+	testingHandle.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// TestBuildReviewPromptPullRequest verifies PR metadata and diff come from gh.
+func TestBuildReviewPromptPullRequest(testingHandle *testing.T) {
+	fakeGH(testingHandle)
+	prompt, err := buildReviewPrompt(context.Background(), testingHandle.TempDir(), "https://github.com/o/r/pull/7 --post")
+	testutil.RequireNoError(testingHandle, err, "pr review")
+	for _, want := range []string{"pull request #7 (parser into main)", "Title: Add parser", "Parses things.", "+func Parse() {}", "gh pr review 7 --comment --body-file"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(prompt, want), "pr prompt contains "+want+"\n"+prompt)
+	}
+}
+
+// TestBuildPRCommentsPrompt verifies conversation, review, and inline comments are collected.
+func TestBuildPRCommentsPrompt(testingHandle *testing.T) {
+	fakeGH(testingHandle)
+	prompt, err := buildPRCommentsPrompt(context.Background(), testingHandle.TempDir(), "")
+	testutil.RequireNoError(testingHandle, err, "pr comments")
+	for _, want := range []string{"pull request #7, \"Add parser\"", "- @alice: Looks close.", "- @bob (review, changes requested): Needs tests.", "- `parser.go:3` @bob: Handle empty input."} {
+		testutil.RequireTrue(testingHandle, strings.Contains(prompt, want), "comments prompt contains "+want+"\n"+prompt)
+	}
+	testutil.RequireTrue(testingHandle, !strings.Contains(prompt, "carol"), "empty review bodies skipped")
+}
+
+// TestTruncateReviewDiff verifies oversized diffs are cut on a line boundary.
+func TestTruncateReviewDiff(testingHandle *testing.T) {
+	testutil.RequireEqual(testingHandle, truncateReviewDiff("+a"), "+a\n", "short diff")
+	long := strings.Repeat("+0123456789\n", maxReviewDiffBytes/12+10)
+	truncated := truncateReviewDiff(long)
+	testutil.RequireTrue(testingHandle, len(truncated) < len(long), "diff truncated")
+	testutil.RequireTrue(testingHandle, strings.Contains(truncated, "+0123456789\n... diff truncated ("), "cut on a line boundary")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// tuiRunState tracks what the running agent turn has finished, so an
+// interrupted turn keeps its partial output in the history.
+type tuiRunState struct {
+	// Messages are the assistant and tool messages the runner completed.
+	Messages []llm.Message
+	// TextOffset is where the reply still being streamed starts in the stream buffer.
+	TextOffset int
+}
+
+// runMessageMsg delivers a message the runner appended to the conversation.
+type runMessageMsg struct {
+	// Message is the completed assistant reply or tool result.
+	Message llm.Message
+}
+
+// recordRunMessage keeps a completed message of the running turn.
+func (m *tuiModel) recordRunMessage(message llm.Message) {
+	if m.agentRun == nil {
+		return
+	}
+	m.agentRun.Messages = append(m.agentRun.Messages, message)
+	if message.Role == "assistant" {
+		m.agentRun.TextOffset = m.streamBuffer.Len()
+	}
+}
+
+// interruptRun stops the running turn the way Claude Code does: the output
+// streamed so far stays in the chat and in the history, followed by the
+// "[Request interrupted by user]" placeholder, so the next prompt can follow
+// up on it. The kept turn is stored like a finished one, so resuming the
+// session shows what the model saw. Run output still in flight is dropped.
+func (m *tuiModel) interruptRun(reason string) {
+	if m.interrupted {
+		return
+	}
+	m.cancelRun(reason)
+	m.interrupted = true
+	for _, tool := range m.activeTools {
+		m.updateToolUseStatus(tool.ID, tuiToolFailed)
+	}
+	m.activeTools = nil
+	m.flushThinking()
+	if m.agentRun != nil {
+		streamed := m.streamBuffer.String()
+		partial := ""
+		if m.agentRun.TextOffset <= len(streamed) {
+			partial = streamed[m.agentRun.TextOffset:]
+		}
+		if strings.TrimSpace(streamed) != "" {
+			m.appendAssistantText(streamed)
+		}
+		m.streamBuffer.Reset()
+		m.history = append(m.history, interruptedHistory(m.agentRun.Messages, partial)...)
+		m.agentRun = nil
+		if m.store != nil {
+			// The run's cost is not known until it returns, so none is recorded.
+			m.persistTurn(nil, 0)
+		}
+	}
+	m.appendInterruptMessage()
+	m.refreshChat()
+}
+
+// staleRunMsg reports whether msg is run output that arrived after an interrupt.
+func (m *tuiModel) staleRunMsg(msg tea.Msg) bool {
+	if !m.interrupted {
+		return false
+	}
+	switch msg.(type) {
+	case streamThinkingMsg, streamDeltaMsg, toolEventMsg, toolProgressMsg, toolOutputMsg, runProgressMsg, runMessageMsg, contextTrimmedMsg:
+		return true
+	}
+	return false
+}
+
+// interruptedHistory returns what an interrupted turn leaves in the
+// conversation: the completed messages, a placeholder result for every tool
+// call that never returned, the partial reply, and the interrupt marker.
+func interruptedHistory(completed []llm.Message, partial string) []llm.Message {
+	messages := append([]llm.Message(nil), completed...)
+	answered := map[string]bool{}
+	for _, message := range completed {
+		if message.Role == "tool" {
+			answered[message.ToolCallID] = true
+		}
+	}
+	for _, message := range completed {
+		for _, call := range message.ToolCalls {
+			if !answered[call.ID] {
+				messages = append(messages, llm.Message{Role: "tool", ToolCallID: call.ID, Content: tuiInterruptForToolMessage})
+			}
+		}
+	}
+	if strings.TrimSpace(partial) != "" {
+		messages = append(messages, llm.Message{Role: "assistant", Content: partial})
+	}
+	return append(messages, llm.Message{Role: "user", Content: tuiInterruptMessage})
+}
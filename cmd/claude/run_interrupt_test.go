@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestEscapeInterruptKeepsPartialTurn verifies Escape interrupts a running
+// turn, keeps the finished messages and partial reply in the history with the
+// interrupt placeholder, and drops output that arrives afterwards.
+func TestEscapeInterruptKeepsPartialTurn(testingHandle *testing.T) {
+	// Arrange a turn that ran one tool and started a second reply.
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	cancelled := 0
+	model.running = true
+	model.agentRun = &tuiRunState{}
+	model.cancel = func() { cancelled++ }
+	model.input.SetValue("draft")
+	toolCall := llm.Message{Role: "assistant", Content: "Let me look. ", ToolCalls: []llm.ToolCall{{ID: "t1", Type: "function", Function: llm.ToolCallFunction{Name: "Read"}}}}
+	toolResult := llm.Message{Role: "tool", ToolCallID: "t1", Content: "file text"}
+	model.Update(streamDeltaMsg{Text: "Let me look. "})
+	model.Update(runMessageMsg{Message: toolCall})
+	model.Update(runMessageMsg{Message: toolResult})
+	model.Update(streamDeltaMsg{Text: "The file says"})
+
+	// Act.
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model.Update(streamDeltaMsg{Text: " more"})
+	model.Update(streamErrorMsg{Err: context.Canceled})
+
+	// Assert.
+	testutil.RequireEqual(testingHandle, cancelled, 1, "cancelled once")
+	testutil.RequireTrue(testingHandle, !model.running, "run ended")
+	testutil.RequireEqual(testingHandle, model.input.Value(), "draft", "draft kept")
+	testutil.RequireEqual(testingHandle, model.history, []llm.Message{
+		toolCall,
+		toolResult,
+		{Role: "assistant", Content: "The file says"},
+		{Role: "user", Content: tuiInterruptMessage},
+	}, "history")
+	count := len(model.chatMessages)
+	testutil.RequireEqual(testingHandle, model.chatMessages[count-2].Content, "Let me look. The file says", "partial output shown")
+	testutil.RequireEqual(testingHandle, model.chatMessages[count-1].Content, tuiInterruptMessage, "placeholder shown")
+	testutil.RequireEqual(testingHandle, len(model.buildSelectorItems()), 1, "placeholder is not a rewind target")
+}
+
+// TestInterruptPersistsPartialTurn verifies the interrupted turn and its
+// placeholder tool results are stored, so resuming shows what the model saw.
+func TestInterruptPersistsPartialTurn(testingHandle *testing.T) {
+	// Arrange a stored prompt whose turn is waiting on a tool call.
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	prompt := llm.Message{Role: "user", Content: "read it"}
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", store)
+	model.history = append(model.history, prompt)
+	model.persistHistory(nil)
+	model.running = true
+	model.agentRun = &tuiRunState{}
+	model.cancel = func() {}
+	toolCall := llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "t1", Type: "function", Function: llm.ToolCallFunction{Name: "Read"}}}}
+	model.Update(runMessageMsg{Message: toolCall})
+
+	// Act.
+	model.interruptRun("Interrupted")
+	stored, _, err := loadSessionMessages(store, "session", 0)
+
+	// Assert.
+	testutil.RequireNoError(testingHandle, err, "load stored history")
+	testutil.RequireEqual(testingHandle, stored, []llm.Message{
+		prompt,
+		toolCall,
+		{Role: "tool", ToolCallID: "t1", Content: tuiInterruptForToolMessage},
+		{Role: "user", Content: tuiInterruptMessage},
+	}, "stored history")
+	testutil.RequireEqual(testingHandle, model.persisted, len(model.history), "nothing left to store")
+}
+
+// TestInterruptedHistoryAnswersPendingToolCalls verifies tool calls without a
+// result get a placeholder so the next request is well formed.
+func TestInterruptedHistoryAnswersPendingToolCalls(testingHandle *testing.T) {
+	// Arrange.
+	calls := llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "a"}, {ID: "b"}}}
+	done := llm.Message{Role: "tool", ToolCallID: "a", Content: "ok"}
+
+	// Act.
+	got := interruptedHistory([]llm.Message{calls, done}, "  ")
+
+	// Assert.
+	testutil.RequireEqual(testingHandle, got, []llm.Message{
+		calls,
+		done,
+		{Role: "tool", ToolCallID: "b", Content: tuiInterruptForToolMessage},
+		{Role: "user", Content: tuiInterruptMessage},
+	}, "history")
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/project"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/spf13/pflag"
+)
+
+// runMetadataOmittedFlags hold inline JSON, prompts, or URLs that can carry
+// credentials or pages of text; run metadata notes that they were set, not
+// their values.
+var runMetadataOmittedFlags = map[string]bool{
+	"agents":               true,
+	"append-system-prompt": true,
+	"json-schema":          true,
+	"mcp-config":           true,
+	"sdk-url":              true,
+	"settings":             true,
+	"system-prompt":        true,
+	"var":                  true,
+}
+
+// recordRunMetadata appends a run_started event describing this invocation
+// to the session's metadata log. It is best effort: a failure never blocks
+// the run.
+func recordRunMetadata(flags *pflag.FlagSet, opts *options, store *session.Store, sessionID string, model string, baseURL string, cwd string) {
+	if opts.NoSessionPersistence || store == nil {
+		return
+	}
+	run := buildRunInfo(flags, opts, model, baseURL, cwd)
+	_, _ = store.AppendMetadata(sessionID, session.MetadataEvent{Type: session.MetadataRunStarted, Run: &run})
+}
+
+// buildRunInfo collects what a later reader needs to reproduce the run.
+func buildRunInfo(flags *pflag.FlagSet, opts *options, model string, baseURL string, cwd string) session.RunInfo {
+	mode := "interactive"
+	if opts.Print {
+		mode = "print"
+	}
+	run := session.RunInfo{
+		Version:     version,
+		Flags:       runFlags(flags),
+		Mode:        mode,
+		Model:       model,
+		BaseURLHash: baseURLHash(baseURL),
+		CWD:         cwd,
+		GitCommit:   project.GitHead(context.Background(), cwd),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+	}
+	if opts.GitStatus != nil {
+		run.GitDirty = opts.GitStatus.Dirty
+	}
+	return run
+}
+
+// runFlags lists the flags set on the command line as --name=value.
+func runFlags(flags *pflag.FlagSet) []string {
+	if flags == nil {
+		return nil
+	}
+	var set []string
+	flags.Visit(func(flag *pflag.Flag) {
+		value := flag.Value.String()
+		if runMetadataOmittedFlags[flag.Name] {
+			value = "<omitted>"
+		}
+		set = append(set, fmt.Sprintf("--%s=%s", flag.Name, value))
+	})
+	return set
+}
+
+// baseURLHash fingerprints the provider base URL, so runs against different
+// gateways can be told apart without storing the URL itself.
+func baseURLHash(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(baseURL))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestLoadSessionMessagesKeepsToolCallPairs verifies windows never start on a tool result.
+func TestLoadSessionMessagesKeepsToolCallPairs(testingHandle *testing.T) {
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	messages := []llm.Message{
+		{Role: "user", Content: "first"},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "call_1", Type: "function", Function: llm.ToolCallFunction{Name: "Read"}}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "file"},
+		{Role: "assistant", Content: "done"},
+	}
+	testutil.RequireNoError(testingHandle, persistSession(store, "s1", messages, nil), "persist")
+
+	// A two-message window would start on the tool result, so it must grow by one.
+	loaded, cursor, err := loadSessionMessages(store, "s1", 2)
+	testutil.RequireNoError(testingHandle, err, "load recent")
+	testutil.RequireEqual(testingHandle, len(loaded), 3, "window size")
+	testutil.RequireEqual(testingHandle, loaded[0].Role, "assistant", "window start role")
+	testutil.RequireEqual(testingHandle, cursor.HasMore, true, "has more")
+	testutil.RequireTrue(testingHandle, strings.Contains(resumedHistoryNotice(len(loaded), cursor), "most recent 3 messages"), "dropped history reported")
+
+	older, cursor, err := loadOlderSessionMessages(store, cursor, 2)
+	testutil.RequireNoError(testingHandle, err, "load older")
+	testutil.RequireEqual(testingHandle, len(older), 1, "older page size")
+	testutil.RequireEqual(testingHandle, older[0].Content, "first", "older content")
+	testutil.RequireEqual(testingHandle, cursor.HasMore, false, "exhausted")
+	testutil.RequireEqual(testingHandle, resumedHistoryNotice(len(older), cursor), "", "nothing dropped")
+}
+
+// TestLoadSessionMessagesSkipsRetracted verifies tombstones hide retracted
+// messages even when the retraction reaches into an older page.
+func TestLoadSessionMessagesSkipsRetracted(testingHandle *testing.T) {
+	// Arrange three exchanges, the last two retracted by separate tombstones.
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	messages := []llm.Message{
+		{Role: "user", Content: "first"}, {Role: "assistant", Content: "one"},
+		{Role: "user", Content: "second"}, {Role: "assistant", Content: "two"},
+		{Role: "user", Content: "third"}, {Role: "assistant", Content: "three"},
+	}
+	testutil.RequireNoError(testingHandle, persistSession(store, "s1", messages[:4], nil), "persist")
+	testutil.RequireNoError(testingHandle, store.AppendTombstone("s1", messages[2:4]), "retract second")
+	testutil.RequireNoError(testingHandle, persistSession(store, "s1", messages[4:], nil), "persist third")
+	testutil.RequireNoError(testingHandle, store.AppendTombstone("s1", messages[4:]), "retract third")
+	kept := []llm.Message{{Role: "user", Content: "fourth"}, {Role: "assistant", Content: "four"}}
+	testutil.RequireNoError(testingHandle, persistSession(store, "s1", kept, nil), "persist fourth")
+
+	// Act
+	all, _, allErr := loadSessionMessages(store, "s1", 0)
+	paged, cursor, pageErr := loadSessionMessages(store, "s1", 4)
+	for pageErr == nil && cursor.HasMore {
+		var older []llm.Message
+		older, cursor, pageErr = loadOlderSessionMessages(store, cursor, 4)
+		paged = append(older, paged...)
+	}
+
+	// Assert
+	testutil.RequireNoError(testingHandle, allErr, "load all")
+	testutil.RequireEqual(testingHandle, all, append(messages[:2:2], kept...), "retracted messages skipped")
+	testutil.RequireNoError(testingHandle, pageErr, "load pages")
+	testutil.RequireEqual(testingHandle, paged, all, "pages skip the same messages")
+}
+
+// TestLoadResumedSessionMessagesFitsTokenBudget verifies resuming loads whole
+// pages until the model's context budget is full.
+func TestLoadResumedSessionMessagesFitsTokenBudget(testingHandle *testing.T) {
+	// Arrange 250 messages of about 100 tokens each.
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	messages := make([]llm.Message, 250)
+	for index := range messages {
+		role := "user"
+		if index%2 == 1 {
+			role = "assistant"
+		}
+		messages[index] = llm.Message{Role: role, Content: strings.Repeat("word ", 80)}
+	}
+	testutil.RequireNoError(testingHandle, persistSession(store, "s1", messages, nil), "persist")
+	providerCfg := &config.ProviderConfig{Models: map[string]config.ModelInfo{"small": {ContextWindow: 16_000}, "large": {ContextWindow: 40_000}}}
+
+	// Act
+	small, smallCursor, smallErr := loadResumedSessionMessages(store, "s1", sessionHistoryTokenBudget(providerCfg, "small"))
+	large, largeCursor, largeErr := loadResumedSessionMessages(store, "s1", sessionHistoryTokenBudget(providerCfg, "large"))
+
+	// Assert
+	testutil.RequireNoError(testingHandle, errors.Join(smallErr, largeErr), "load resumed history")
+	testutil.RequireEqual(testingHandle, sessionHistoryTokenBudget(nil, "unknown"), defaultHistoryContextWindow*3/4, "unknown window falls back")
+	testutil.RequireEqual(testingHandle, len(small), sessionHistoryPageSize, "a second page would overflow the small window")
+	testutil.RequireTrue(testingHandle, smallCursor.HasMore, "older messages left on disk")
+	testutil.RequireEqual(testingHandle, large, messages, "the large window holds the whole session")
+	testutil.RequireTrue(testingHandle, !largeCursor.HasMore, "nothing left on disk")
+}
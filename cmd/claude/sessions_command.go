@@ -0,0 +1,538 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// sessionsCommand groups OpenClaude session management subcommands.
+func sessionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Inspect stored OpenClaude sessions",
+	}
+	cmd.AddCommand(sessionsListCommand())
+	cmd.AddCommand(sessionsShowCommand())
+	cmd.AddCommand(sessionsPruneCommand())
+	cmd.AddCommand(sessionsRenameCommand())
+	cmd.AddCommand(sessionsMetadataCommand())
+	cmd.AddCommand(sessionsUndoCommand())
+	cmd.AddCommand(sessionsRedactCommand())
+	return cmd
+}
+
+// sessionsListCommand prints recent sessions with titles, timestamps, and message counts.
+func sessionsListCommand() *cobra.Command {
+	var (
+		limit    int
+		jsonMode bool
+		forksOf  string
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent sessions (newest first)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := session.NewStore()
+			if err != nil {
+				return err
+			}
+			// Forks can be older than the newest sessions, so filter before limiting.
+			listLimit := limit
+			if forksOf != "" {
+				listLimit = 0
+			}
+			metas, err := store.ListSessionMeta(listLimit)
+			if err != nil {
+				if os.IsNotExist(err) {
+					metas = nil
+				} else {
+					return fmt.Errorf("list sessions: %w", err)
+				}
+			}
+			if forksOf != "" {
+				metas = sessionForks(metas, forksOf, limit)
+			}
+			if jsonMode {
+				return writeSessionMetaJSON(cmd.OutOrStdout(), metas)
+			}
+			return writeSessionMetaTable(cmd.OutOrStdout(), metas)
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of sessions to list (0 lists all)")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print sessions as a JSON array")
+	cmd.Flags().StringVar(&forksOf, "forks-of", "", "List only sessions forked from this session with /fork")
+	return cmd
+}
+
+// sessionForks keeps the sessions forked from parent, at most limit of them
+// (0 keeps all).
+func sessionForks(metas []session.SessionMeta, parent string, limit int) []session.SessionMeta {
+	var forks []session.SessionMeta
+	for _, meta := range metas {
+		if meta.ForkedFrom != parent {
+			continue
+		}
+		if limit > 0 && len(forks) == limit {
+			break
+		}
+		forks = append(forks, meta)
+	}
+	return forks
+}
+
+// sessionsShowCommand summarizes a session and explains how each of its runs
+// was produced.
+func sessionsShowCommand() *cobra.Command {
+	var jsonMode bool
+	cmd := &cobra.Command{
+		Use:   "show <session-id>",
+		Short: "Show a session's summary and the flags, model, gateway, version, and commit of each run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := existingSessionStore(args[0])
+			if err != nil {
+				return err
+			}
+			metas, err := store.ListSessionMeta(0)
+			if err != nil {
+				return fmt.Errorf("list sessions: %w", err)
+			}
+			meta := session.SessionMeta{ID: args[0]}
+			for _, candidate := range metas {
+				if candidate.ID == args[0] {
+					meta = candidate
+					break
+				}
+			}
+			state, err := store.LoadMetadata(args[0])
+			if err != nil {
+				return err
+			}
+			if jsonMode {
+				runs := state.Runs
+				if runs == nil {
+					runs = []session.RunInfo{}
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(map[string]any{"session": meta, "path": store.SessionPath(args[0]), "runs": runs})
+			}
+			return writeSessionShow(cmd.OutOrStdout(), meta, store.SessionPath(args[0]), state.Runs)
+		},
+	}
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print the session and its runs as JSON")
+	return cmd
+}
+
+// writeSessionShow prints the session summary followed by one block per run.
+func writeSessionShow(out io.Writer, meta session.SessionMeta, path string, runs []session.RunInfo) error {
+	fmt.Fprintf(out, "Session: %s\nTitle: %s\nMessages: %d\nUpdated: %s\nLog: %s\n", meta.ID, valueOrDash(meta.Title), meta.MessageCount, formatSessionTime(meta.UpdatedAt), path)
+	if meta.ForkedFrom != "" {
+		fmt.Fprintf(out, "Forked from: %s\n", meta.ForkedFrom)
+	}
+	if len(runs) == 0 {
+		_, err := fmt.Fprintln(out, "\nNo runs recorded.")
+		return err
+	}
+	for index, run := range runs {
+		commit := valueOrDash(run.GitCommit)
+		if run.GitCommit != "" && run.GitDirty > 0 {
+			commit += fmt.Sprintf(" (+%d uncommitted)", run.GitDirty)
+		}
+		fmt.Fprintf(out, "\nRun %d (%s)\n", index+1, run.Mode)
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "  Version:\t%s\n", run.Version)
+		fmt.Fprintf(writer, "  Model:\t%s\n", run.Model)
+		fmt.Fprintf(writer, "  Gateway:\t%s\n", valueOrDash(run.BaseURLHash))
+		fmt.Fprintf(writer, "  Directory:\t%s\n", run.CWD)
+		fmt.Fprintf(writer, "  Commit:\t%s\n", commit)
+		fmt.Fprintf(writer, "  Platform:\t%s/%s\n", run.OS, run.Arch)
+		fmt.Fprintf(writer, "  Flags:\t%s\n", valueOrDash(strings.Join(run.Flags, " ")))
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionsPruneCommand deletes sessions older than the retention period.
+func sessionsPruneCommand() *cobra.Command {
+	var (
+		olderThan int
+		dryRun    bool
+		jsonMode  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete sessions older than cleanupPeriodDays",
+		Long: "Delete sessions whose last update is older than --older-than days, or the cleanupPeriodDays setting (default 30).\n" +
+			"The session log, its saved state (todos, checkpoints, artifacts, edit backups), and debug dumps are removed.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			days := olderThan
+			if !cmd.Flags().Changed("older-than") {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return fmt.Errorf("resolve cwd: %w", err)
+				}
+				settings, err := config.LoadClaudeSettings(cwd, nil, "")
+				if err != nil {
+					return err
+				}
+				days = settings.CleanupPeriodDays
+			}
+			if days <= 0 {
+				return fmt.Errorf("session cleanup is disabled (cleanupPeriodDays is %d); pass --older-than to prune anyway", days)
+			}
+			store, err := session.NewStore()
+			if err != nil {
+				return err
+			}
+			report, err := store.PruneSessions(time.Now().AddDate(0, 0, -days), nil, dryRun)
+			if err != nil {
+				return err
+			}
+			if jsonMode {
+				if report.Pruned == nil {
+					report.Pruned = []session.PrunedSession{}
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			return writePruneReport(cmd.OutOrStdout(), report, days)
+		},
+	}
+	cmd.Flags().IntVar(&olderThan, "older-than", 0, "Prune sessions not updated for this many days (default: cleanupPeriodDays)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the sessions that would be deleted without deleting them")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print the prune report as JSON")
+	return cmd
+}
+
+// writePruneReport lists pruned sessions and summarizes the space freed and kept.
+func writePruneReport(out io.Writer, report session.PruneReport, days int) error {
+	verb := "Removed"
+	if report.DryRun {
+		verb = "Would remove"
+	}
+	if len(report.Pruned) > 0 {
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tUPDATED\tSIZE")
+		for _, pruned := range report.Pruned {
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", pruned.ID, formatSessionTime(pruned.UpdatedAt), formatByteSize(pruned.Bytes))
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(out, "%s %d sessions older than %d days (%s); %d sessions (%s) kept.\n",
+		verb, len(report.Pruned), days, formatByteSize(report.PrunedBytes), report.Kept, formatByteSize(report.KeptBytes))
+	return err
+}
+
+// sessionsRenameCommand records a title_changed event for a session.
+func sessionsRenameCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <session-id> <title>",
+		Short: "Set a session's title",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := existingSessionStore(args[0])
+			if err != nil {
+				return err
+			}
+			title := strings.Join(strings.Fields(strings.Join(args[1:], " ")), " ")
+			if title == "" {
+				return errors.New("title must not be empty")
+			}
+			if _, err := store.AppendMetadata(args[0], session.MetadataEvent{Type: session.MetadataTitleChanged, Title: title}); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Renamed %s to %q\n", args[0], title)
+			return nil
+		},
+	}
+}
+
+// sessionsMetadataCommand prints a session's current metadata and the events it was built from.
+func sessionsMetadataCommand() *cobra.Command {
+	var jsonMode bool
+	cmd := &cobra.Command{
+		Use:   "metadata <session-id>",
+		Short: "Show a session's metadata and its change history",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := existingSessionStore(args[0])
+			if err != nil {
+				return err
+			}
+			events, err := store.LoadMetadataEvents(args[0])
+			if err != nil {
+				return err
+			}
+			state := session.ReduceMetadata(events)
+			if jsonMode {
+				if events == nil {
+					events = []session.MetadataEvent{}
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(map[string]any{"state": state, "events": events})
+			}
+			return writeSessionMetadata(cmd.OutOrStdout(), state, events)
+		},
+	}
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print the state and events as JSON")
+	return cmd
+}
+
+// sessionsUndoCommand reverts a session's latest metadata change.
+func sessionsUndoCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "undo <session-id>",
+		Short: "Undo a session's latest title, mode, todo, or checkpoint change",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := existingSessionStore(args[0])
+			if err != nil {
+				return err
+			}
+			undone, err := store.UndoMetadata(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Undid #%d %s\n", undone.Seq, describeMetadataEvent(undone))
+			return nil
+		},
+	}
+}
+
+// sessionsRedactCommand purges text, such as an accidentally pasted secret,
+// from a stored session.
+func sessionsRedactCommand() *cobra.Command {
+	var (
+		pattern     string
+		replacement string
+		dryRun      bool
+		jsonMode    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "redact <session-id> --pattern <regex>",
+		Short: "Replace text matching a pattern everywhere a session is stored",
+		Long: "Rewrite a stored session, replacing every match of --pattern (Go regular expression syntax) with --replacement.\n" +
+			"The session log, its saved state (metadata, checkpoints, UI state, artifacts), debug dumps, and the shared input history are redacted; edit backups are not.\n" +
+			"Forked sessions keep their own copies and must be redacted separately. Do not redact a session that is still running.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pattern == "" {
+				return errors.New("--pattern is required")
+			}
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid --pattern: %w", err)
+			}
+			store, err := existingSessionStore(args[0])
+			if err != nil {
+				return err
+			}
+			report, err := store.RedactSession(args[0], compiled, replacement, dryRun)
+			if err != nil {
+				return err
+			}
+			if jsonMode {
+				if report.Files == nil {
+					report.Files = []session.RedactedFile{}
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			return writeRedactReport(cmd.OutOrStdout(), report, args[0])
+		},
+	}
+	cmd.Flags().StringVar(&pattern, "pattern", "", "Regular expression to redact")
+	cmd.Flags().StringVar(&replacement, "replacement", session.DefaultRedaction, "Text that replaces each match")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Count matches without rewriting anything")
+	cmd.Flags().BoolVar(&jsonMode, "json", false, "Print the redaction report as JSON")
+	return cmd
+}
+
+// writeRedactReport lists the redacted files and the total match count.
+func writeRedactReport(out io.Writer, report session.RedactReport, sessionID string) error {
+	verb := "Redacted"
+	if report.DryRun {
+		verb = "Would redact"
+	}
+	if len(report.Files) > 0 {
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "FILE\tMATCHES")
+		for _, file := range report.Files {
+			fmt.Fprintf(writer, "%s\t%d\n", file.Path, file.Matches)
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(out, "%s %d matches in %d files for session %s.\n", verb, report.Matches, len(report.Files), sessionID)
+	return err
+}
+
+// existingSessionStore opens the store and checks that sessionID has a log.
+func existingSessionStore(sessionID string) (*session.Store, error) {
+	store, err := session.NewStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(store.SessionPath(sessionID)); err != nil {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+	return store, nil
+}
+
+// writeSessionMetadata prints the folded state followed by the event history.
+func writeSessionMetadata(out io.Writer, state session.SessionMetadata, events []session.MetadataEvent) error {
+	mode := state.Mode
+	if mode == "" {
+		mode = session.MetadataModeDefault
+	}
+	fmt.Fprintf(out, "Title: %s\nMode: %s\nCheckpoints: %s\nTasks: %d\n", valueOrDash(state.Title), mode, valueOrDash(strings.Join(state.Checkpoints, ", ")), len(state.Tasks))
+	if len(events) == 0 {
+		_, err := fmt.Fprintln(out, "\nNo metadata changes recorded.")
+		return err
+	}
+	fmt.Fprintln(out)
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SEQ\tTIME\tEVENT")
+	for _, event := range events {
+		fmt.Fprintf(writer, "%d\t%s\t%s\n", event.Seq, formatSessionTime(event.At), describeMetadataEvent(event))
+	}
+	return writer.Flush()
+}
+
+// describeMetadataEvent summarizes an event in one line.
+func describeMetadataEvent(event session.MetadataEvent) string {
+	switch event.Type {
+	case session.MetadataTitleChanged:
+		return fmt.Sprintf("title_changed %q", event.Title)
+	case session.MetadataModeChanged:
+		return "mode_changed " + event.Mode
+	case session.MetadataTodoUpdated:
+		var todos []any
+		_ = json.Unmarshal(event.Todos, &todos)
+		return fmt.Sprintf("todo_updated (%d items)", len(todos))
+	case session.MetadataCheckpointCreated:
+		return "checkpoint_created " + event.Checkpoint
+	case session.MetadataTaskUpdated:
+		return strings.TrimSpace(fmt.Sprintf("task_updated %s %s", event.TaskID, event.TaskStatus))
+	case session.MetadataUndone:
+		return fmt.Sprintf("undone #%d", event.Undoes)
+	case session.MetadataRunStarted:
+		if event.Run == nil {
+			return event.Type
+		}
+		return fmt.Sprintf("run_started %s %s (v%s)", event.Run.Mode, event.Run.Model, event.Run.Version)
+	default:
+		return event.Type
+	}
+}
+
+// valueOrDash renders empty values as "-".
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// pruneExpiredSessions applies the retention period at startup, sparing the
+// current session even when it was resumed from an old log. Cleanup is best
+// effort: a failure never blocks the session.
+func pruneExpiredSessions(store *session.Store, days int, currentSessionID string) {
+	if days <= 0 {
+		return
+	}
+	_, _ = store.PruneSessions(time.Now().AddDate(0, 0, -days), map[string]bool{currentSessionID: true}, false)
+}
+
+// formatByteSize renders a byte count with a binary unit (e.g., 1.5 MB).
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
+
+// writeSessionMetaJSON prints session metadata as an indented JSON array.
+func writeSessionMetaJSON(out io.Writer, metas []session.SessionMeta) error {
+	if metas == nil {
+		metas = []session.SessionMeta{}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(metas)
+}
+
+// writeSessionMetaTable prints session metadata as an aligned table.
+func writeSessionMetaTable(out io.Writer, metas []session.SessionMeta) error {
+	if len(metas) == 0 {
+		_, err := fmt.Fprintln(out, "No sessions found.")
+		return err
+	}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tUPDATED\tMESSAGES\tFORKED FROM\tTITLE")
+	for _, meta := range metas {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%s\n", meta.ID, formatSessionTime(meta.UpdatedAt), meta.MessageCount, valueOrDash(meta.ForkedFrom), meta.Title)
+	}
+	return writer.Flush()
+}
+
+// formatSessionStats summarizes a session's turns, cost, edited files, and
+// last tool on one line, so similar sessions can be told apart in the picker.
+func formatSessionStats(meta session.SessionMeta) string {
+	parts := []string{fmt.Sprintf("%d turns", meta.Turns)}
+	if meta.CostUSD > 0 {
+		parts = append(parts, formatUSD(meta.CostUSD))
+	}
+	switch len(meta.FilesModified) {
+	case 0:
+	case 1:
+		parts = append(parts, "modified "+meta.FilesModified[0])
+	default:
+		parts = append(parts, fmt.Sprintf("%d files modified", len(meta.FilesModified)))
+	}
+	if meta.LastTool != "" {
+		parts = append(parts, "last tool "+meta.LastTool)
+	}
+	if meta.ForkedFrom != "" {
+		parts = append(parts, "fork of "+meta.ForkedFrom)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatSessionTime renders a timestamp in local time for session listings.
+func formatSessionTime(value time.Time) string {
+	if value.IsZero() {
+		return "-"
+	}
+	return value.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/spf13/pflag"
+)
+
+// TestWriteSessionMetaTable verifies session listings include titles and counts.
+func TestWriteSessionMetaTable(testingHandle *testing.T) {
+	tests := []struct {
+		name  string
+		metas []session.SessionMeta
+		want  []string
+	}{
+		{name: "empty", metas: nil, want: []string{"No sessions found."}},
+		{
+			name: "rows",
+			metas: []session.SessionMeta{
+				{ID: "abc", Title: "fix tests", MessageCount: 4, UpdatedAt: time.Date(2025, 1, 2, 3, 4, 0, 0, time.Local)},
+			},
+			want: []string{"ID", "MESSAGES", "abc", "2025-01-02 03:04", "fix tests"},
+		},
+		{
+			name:  "fork",
+			metas: []session.SessionMeta{{ID: "def", Title: "try sqlite", MessageCount: 2, ForkedFrom: "abc"}},
+			want:  []string{"FORKED FROM", "def", "abc", "try sqlite"},
+		},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			var out bytes.Buffer
+			testutil.RequireNoError(testingHandle, writeSessionMetaTable(&out, tt.metas), "write table")
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					testingHandle.Fatalf("expected %q in output: %s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+// TestWriteSessionChoices verifies the resume picker previews each session's stats.
+func TestWriteSessionChoices(testingHandle *testing.T) {
+	// Arrange
+	metas := []session.SessionMeta{
+		{ID: "abc", Title: "fix tests", MessageCount: 6, Turns: 2, CostUSD: 0.125, FilesModified: []string{"a.go", "b.go"}, LastTool: "Bash"},
+		{ID: "def", MessageCount: 1, Turns: 1, FilesModified: []string{"notes.md"}},
+	}
+	var out bytes.Buffer
+
+	// Act
+	writeSessionChoices(&out, metas)
+
+	// Assert
+	for _, want := range []string{"1) -  fix tests  6 msgs  abc\n", "   2 turns · $0.1250 · 2 files modified · last tool Bash\n", "2) -  (untitled)  1 msgs  def\n", "   1 turns · modified notes.md\n"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(out.String(), want), "output contains "+want+"\n"+out.String())
+	}
+}
+
+// TestSessionsPruneCommand verifies prune honors cleanupPeriodDays and --dry-run.
+func TestSessionsPruneCommand(testingHandle *testing.T) {
+	// Arrange: a 10-day-old session under a 7-day retention setting.
+	home := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", home)
+	testingHandle.Chdir(testingHandle.TempDir())
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(home, ".claude"), 0o755), "create settings dir")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(home, ".claude", "settings.json"), []byte(`{"cleanupPeriodDays":7}`), 0o644), "write settings")
+	store := &session.Store{BaseDir: filepath.Join(home, ".openclaude")}
+	testutil.RequireNoError(testingHandle, store.AppendEvent("old", map[string]any{"type": "message"}), "write session")
+	stale := time.Now().AddDate(0, 0, -10)
+	testutil.RequireNoError(testingHandle, os.Chtimes(store.SessionPath("old"), stale, stale), "age session")
+	run := func(args ...string) string {
+		var out bytes.Buffer
+		cmd := sessionsCommand()
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		testutil.RequireNoError(testingHandle, cmd.Execute(), strings.Join(args, " "))
+		return out.String()
+	}
+
+	// Act
+	preview := run("prune", "--dry-run")
+	_, previewErr := os.Stat(store.SessionPath("old"))
+	pruned := run("prune")
+	_, prunedErr := os.Stat(store.SessionPath("old"))
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.Contains(preview, "Would remove 1 sessions older than 7 days"), preview)
+	testutil.RequireNoError(testingHandle, previewErr, "dry run keeps the session")
+	testutil.RequireTrue(testingHandle, strings.Contains(pruned, "Removed 1 sessions older than 7 days") && strings.Contains(pruned, "old"), pruned)
+	testutil.RequireTrue(testingHandle, os.IsNotExist(prunedErr), "session removed")
+}
+
+// TestFormatByteSize verifies sizes switch units at 1024.
+func TestFormatByteSize(testingHandle *testing.T) {
+	testutil.RequireEqual(testingHandle, formatByteSize(512), "512 B", "bytes")
+	testutil.RequireEqual(testingHandle, formatByteSize(1536), "1.5 KB", "kilobytes")
+	testutil.RequireEqual(testingHandle, formatByteSize(3*1024*1024), "3.0 MB", "megabytes")
+}
+
+// TestSessionsMetadataCommands verifies rename, metadata, and undo work through the event log.
+func TestSessionsMetadataCommands(testingHandle *testing.T) {
+	// Arrange
+	home := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", home)
+	store := &session.Store{BaseDir: filepath.Join(home, ".openclaude")}
+	testutil.RequireNoError(testingHandle, store.AppendEvent("s1", map[string]any{"type": "message"}), "write session")
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := sessionsCommand()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	// Act
+	renamed, renameErr := run("rename", "s1", "Parser", "rewrite")
+	inspected, inspectErr := run("metadata", "s1")
+	undone, undoErr := run("undo", "s1")
+	_, emptyErr := run("undo", "s1")
+	_, missingErr := run("metadata", "nope")
+
+	// Assert
+	testutil.RequireNoError(testingHandle, renameErr, "rename")
+	testutil.RequireTrue(testingHandle, strings.Contains(renamed, `Renamed s1 to "Parser rewrite"`), renamed)
+	testutil.RequireNoError(testingHandle, inspectErr, "metadata")
+	testutil.RequireTrue(testingHandle, strings.Contains(inspected, "Title: Parser rewrite") && strings.Contains(inspected, `title_changed "Parser rewrite"`), inspected)
+	testutil.RequireNoError(testingHandle, undoErr, "undo")
+	testutil.RequireTrue(testingHandle, strings.Contains(undone, `Undid #1 title_changed "Parser rewrite"`), undone)
+	testutil.RequireTrue(testingHandle, emptyErr != nil && strings.Contains(emptyErr.Error(), "no metadata changes to undo"), "nothing left to undo")
+	testutil.RequireTrue(testingHandle, missingErr != nil && strings.Contains(missingErr.Error(), "session nope not found"), "unknown session")
+}
+
+// TestSessionsShowCommand verifies each run's flags, model, gateway hash, and
+// version are recorded and shown, without inline settings or the gateway URL.
+func TestSessionsShowCommand(testingHandle *testing.T) {
+	// Arrange a print-mode run with a model override and inline settings.
+	home := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", home)
+	store := &session.Store{BaseDir: filepath.Join(home, ".openclaude")}
+	testutil.RequireNoError(testingHandle, store.AppendEvent("s1", map[string]any{"type": "message"}), "write session")
+	opts := &options{}
+	flags := pflag.NewFlagSet("claude", pflag.ContinueOnError)
+	applyFlags(flags, opts)
+	testutil.RequireNoError(testingHandle, flags.Parse([]string{"-p", "--model", "gpt-test", "--settings", `{"env":{"API_KEY":"sk-secret"}}`}), "parse flags")
+	recordRunMetadata(flags, opts, store, "s1", "gpt-test", "https://gateway.example.com/v1/", testingHandle.TempDir())
+	var out bytes.Buffer
+	cmd := sessionsCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"show", "s1"})
+
+	// Act
+	err := cmd.Execute()
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "show")
+	shown := out.String()
+	for _, want := range []string{"Run 1 (print)", "Model:", "gpt-test", "Version:", version, "--print=true", "--model=gpt-test", "--settings=<omitted>", baseURLHash("https://gateway.example.com/v1")} {
+		testutil.RequireTrue(testingHandle, strings.Contains(shown, want), "expected "+want+" in: "+shown)
+	}
+	testutil.RequireTrue(testingHandle, !strings.Contains(shown, "sk-secret") && !strings.Contains(shown, "gateway.example.com"), "no secrets or URL: "+shown)
+	events, loadErr := store.LoadMetadataEvents("s1")
+	testutil.RequireNoError(testingHandle, loadErr, "load metadata")
+	testutil.RequireEqual(testingHandle, describeMetadataEvent(events[0]), "run_started print gpt-test (v"+version+")", "metadata history")
+}
+
+// TestSessionsRedactCommand verifies redact rewrites the log and validates its pattern.
+func TestSessionsRedactCommand(testingHandle *testing.T) {
+	// Arrange
+	home := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", home)
+	store := &session.Store{BaseDir: filepath.Join(home, ".openclaude")}
+	testutil.RequireNoError(testingHandle, store.AppendEvent("s1", map[string]any{"type": "message", "message": map[string]any{"role": "user", "content": "token ghp_abc123"}}), "write session")
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := sessionsCommand()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	// Act
+	output, err := run("redact", "s1", "--pattern", `ghp_\w+`, "--replacement", "***")
+	_, missingErr := run("redact", "s1")
+	_, invalidErr := run("redact", "s1", "--pattern", "(")
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "redact")
+	testutil.RequireTrue(testingHandle, strings.Contains(output, "sessions/s1.jsonl") && strings.Contains(output, "Redacted 1 matches in 1 files for session s1."), output)
+	data, readErr := os.ReadFile(store.SessionPath("s1"))
+	testutil.RequireNoError(testingHandle, readErr, "read log")
+	testutil.RequireTrue(testingHandle, strings.Contains(string(data), "token ***") && !strings.Contains(string(data), "ghp_"), string(data))
+	testutil.RequireTrue(testingHandle, missingErr != nil && strings.Contains(missingErr.Error(), "--pattern is required"), "pattern required")
+	testutil.RequireTrue(testingHandle, invalidErr != nil && strings.Contains(invalidErr.Error(), "invalid --pattern"), "pattern validated")
+}
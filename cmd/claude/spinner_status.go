@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/agent"
+)
+
+// spinnerStatusMaxSubject bounds the file name, pattern, or command quoted in
+// a spinner status.
+const spinnerStatusMaxSubject = 40
+
+// tuiActiveTool is a running tool call and the spinner status it shows.
+type tuiActiveTool struct {
+	// ID matches the call to its result.
+	ID string
+	// Status is the spinner text for the call.
+	Status string
+}
+
+// trackToolStatus updates the running tools from a tool event. The spinner
+// shows the most recently started tool that is still running.
+func (m *tuiModel) trackToolStatus(event agent.ToolEvent) {
+	switch event.Type {
+	case "tool_call":
+		m.activeTools = append(m.activeTools, tuiActiveTool{ID: event.ToolID, Status: toolStatusVerb(event.ToolName, event.Arguments)})
+	case "tool_result":
+		for index, tool := range m.activeTools {
+			if tool.ID == event.ToolID {
+				m.activeTools = append(m.activeTools[:index], m.activeTools[index+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// spinnerStatus returns the spinner text: the running tool's status, or the
+// playful verb picked when the run started.
+func (m *tuiModel) spinnerStatus() string {
+	if len(m.activeTools) > 0 {
+		return m.activeTools[len(m.activeTools)-1].Status
+	}
+	if m.spinnerMessage == "" {
+		return "Thinking"
+	}
+	return m.spinnerMessage
+}
+
+// toolStatusVerb describes a tool call in the present tense, e.g.
+// "Editing main.go" or "Running tests".
+func toolStatusVerb(toolName string, arguments json.RawMessage) string {
+	var args struct {
+		Command      string `json:"command"`
+		Path         string `json:"path"`
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+		Pattern      string `json:"pattern"`
+		Query        string `json:"query"`
+		URL          string `json:"url"`
+		Description  string `json:"description"`
+		Name         string `json:"name"`
+	}
+	_ = json.Unmarshal(arguments, &args)
+	path := firstNonEmpty(args.FilePath, args.NotebookPath, args.Path)
+	switch toolName {
+	case "Bash":
+		return bashStatusVerb(args.Command)
+	case "Read":
+		return withSubject("Reading", fileSubject(path))
+	case "Write":
+		return withSubject("Writing", fileSubject(path))
+	case "Edit", "MultiEdit", "NotebookEdit":
+		return withSubject("Editing", fileSubject(path))
+	case "Grep":
+		return withSubject("Searching for", quotedSubject(firstNonEmpty(args.Query, args.Pattern)))
+	case "Glob":
+		return withSubject("Finding", truncateSubject(args.Pattern))
+	case "ListDir", "LS":
+		return withSubject("Listing", fileSubject(path))
+	case "WebSearch":
+		return "Searching web"
+	case "WebFetch":
+		if parsed, err := url.Parse(args.URL); err == nil && parsed.Host != "" {
+			return "Fetching " + parsed.Host
+		}
+		return "Fetching"
+	case "Task":
+		return withSubject("Delegating", truncateSubject(args.Description))
+	case "TodoWrite":
+		return "Updating todos"
+	case "Skill":
+		return withSubject("Loading skill", truncateSubject(args.Name))
+	case "RunPython":
+		return "Running Python"
+	}
+	return "Running " + toolName
+}
+
+// bashStatusVerb classifies a shell command by its first program.
+func bashStatusVerb(command string) string {
+	fields := strings.Fields(command)
+	// Skip leading environment assignments such as "CGO_ENABLED=0 go build".
+	for len(fields) > 0 && strings.Contains(fields[0], "=") && !strings.HasPrefix(fields[0], "=") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return "Running command"
+	}
+	program := filepath.Base(fields[0])
+	sub := ""
+	if len(fields) > 1 {
+		sub = fields[1]
+	}
+	switch program {
+	case "pytest", "jest", "vitest", "rspec", "phpunit":
+		return "Running tests"
+	case "go", "cargo", "npm", "pnpm", "yarn", "bun", "dotnet", "mix", "make":
+		switch sub {
+		case "test", "t":
+			return "Running tests"
+		case "build", "b", "compile":
+			return "Building"
+		case "install", "i", "add", "get", "ci", "mod", "fetch":
+			return "Installing dependencies"
+		case "run":
+			if len(fields) > 2 && strings.Contains(fields[2], "test") {
+				return "Running tests"
+			}
+		}
+		if program == "make" {
+			if strings.Contains(sub, "test") {
+				return "Running tests"
+			}
+			return "Building"
+		}
+	case "pip", "pip3", "apt", "apt-get", "brew", "poetry", "uv":
+		if sub == "install" || sub == "add" || sub == "sync" {
+			return "Installing dependencies"
+		}
+	case "git":
+		if sub != "" {
+			return "Running git " + sub
+		}
+		return "Running git"
+	case "grep", "rg", "ag", "find", "fd":
+		return "Searching files"
+	case "ls", "tree":
+		return "Listing files"
+	case "cat", "head", "tail", "less":
+		return "Reading files"
+	}
+	return "Running " + truncateSubject(program)
+}
+
+// withSubject joins a verb and an optional subject.
+func withSubject(verb string, subject string) string {
+	if subject == "" {
+		return verb
+	}
+	return verb + " " + subject
+}
+
+// firstNonEmpty returns the first non-blank value.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// fileSubject shortens a path to its base name.
+func fileSubject(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return ""
+	}
+	return truncateSubject(filepath.Base(path))
+}
+
+// quotedSubject quotes a search pattern.
+func quotedSubject(pattern string) string {
+	pattern = truncateSubject(pattern)
+	if pattern == "" {
+		return ""
+	}
+	return "\"" + pattern + "\""
+}
+
+// truncateSubject collapses text to one line of at most spinnerStatusMaxSubject runes.
+func truncateSubject(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= spinnerStatusMaxSubject {
+		return text
+	}
+	return string(runes[:spinnerStatusMaxSubject-1]) + "…"
+}
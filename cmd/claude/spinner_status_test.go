@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestToolStatusVerb verifies tool calls are described by their activity.
+func TestToolStatusVerb(testingHandle *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args string
+		want string
+	}{
+		{name: "go test", tool: "Bash", args: `{"command":"go test ./..."}`, want: "Running tests"},
+		{name: "env prefix", tool: "Bash", args: `{"command":"CGO_ENABLED=0 go build ./cmd/x"}`, want: "Building"},
+		{name: "npm run test", tool: "Bash", args: `{"command":"npm run test:unit"}`, want: "Running tests"},
+		{name: "pip install", tool: "Bash", args: `{"command":"pip install requests"}`, want: "Installing dependencies"},
+		{name: "git", tool: "Bash", args: `{"command":"git status --short"}`, want: "Running git status"},
+		{name: "other command", tool: "Bash", args: `{"command":"/usr/bin/python3 x.py"}`, want: "Running python3"},
+		{name: "edit", tool: "Edit", args: `{"file_path":"/repo/cmd/main.go"}`, want: "Editing main.go"},
+		{name: "read", tool: "Read", args: `{"path":"README.md"}`, want: "Reading README.md"},
+		{name: "grep", tool: "Grep", args: `{"query":"func main"}`, want: `Searching for "func main"`},
+		{name: "web search", tool: "WebSearch", args: `{"query":"go"}`, want: "Searching web"},
+		{name: "web fetch", tool: "WebFetch", args: `{"url":"https://example.com/a"}`, want: "Fetching example.com"},
+		{name: "task", tool: "Task", args: `{"description":"Audit auth"}`, want: "Delegating Audit auth"},
+		{name: "unknown", tool: "mcp__db__query", args: `{}`, want: "Running mcp__db__query"},
+		{name: "bad arguments", tool: "Write", args: `not json`, want: "Writing"},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Act.
+			got := toolStatusVerb(tt.tool, json.RawMessage(tt.args))
+
+			// Assert.
+			testutil.RequireEqual(testingHandle, got, tt.want, "status")
+		})
+	}
+}
+
+// TestSpinnerStatusFollowsRunningTools verifies the spinner names the newest
+// running tool and falls back to the playful verb when none is running.
+func TestSpinnerStatusFollowsRunningTools(testingHandle *testing.T) {
+	// Arrange.
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	model.startSpinner()
+	model.spinnerMessage = "Pondering"
+	send := func(event agent.ToolEvent) {
+		model.Update(toolEventMsg{Event: event})
+	}
+
+	// Act and assert.
+	testutil.RequireEqual(testingHandle, model.spinnerStatus(), "Pondering", "before tools")
+	send(agent.ToolEvent{Type: "tool_call", ToolName: "Bash", ToolID: "a", Arguments: json.RawMessage(`{"command":"go test ./..."}`)})
+	send(agent.ToolEvent{Type: "tool_call", ToolName: "Read", ToolID: "b", Arguments: json.RawMessage(`{"file_path":"go.mod"}`)})
+	testutil.RequireEqual(testingHandle, model.spinnerStatus(), "Reading go.mod", "newest tool")
+	send(agent.ToolEvent{Type: "tool_result", ToolName: "Read", ToolID: "b"})
+	testutil.RequireEqual(testingHandle, model.spinnerStatus(), "Running tests", "remaining tool")
+	send(agent.ToolEvent{Type: "tool_result", ToolName: "Bash", ToolID: "a"})
+	testutil.RequireEqual(testingHandle, model.spinnerStatus(), "Pondering", "after tools")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/streamjson"
+)
+
+// statsRecentTurns caps the per-turn lines /stats lists.
+const statsRecentTurns = 10
+
+// showStats renders /stats for the current TUI session.
+func (m *tuiModel) showStats(_ string) string {
+	return formatStatsReport(m.costTurns)
+}
+
+// formatStatsReport summarizes API latency, time to first token, and wall
+// time across the session's turns, then lists the most recent ones.
+func formatStatsReport(prompts []tuiCostTurn) string {
+	var turns []agent.TurnMetrics
+	var wall, api time.Duration
+	for _, prompt := range prompts {
+		turns = append(turns, prompt.Turns...)
+		wall += prompt.Duration
+		for _, turn := range prompt.Turns {
+			api += turn.APILatency
+		}
+	}
+	if len(turns) == 0 {
+		return "No API requests yet in this session."
+	}
+
+	var latencies, firstTokens []time.Duration
+	var outputTokens int
+	var generating time.Duration
+	for _, turn := range turns {
+		latencies = append(latencies, turn.APILatency)
+		if turn.TimeToFirstToken > 0 {
+			firstTokens = append(firstTokens, turn.TimeToFirstToken)
+			if turn.OutputTokens > 0 {
+				outputTokens += turn.OutputTokens
+				generating += turn.APILatency - turn.TimeToFirstToken
+			}
+		}
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Requests:          %d over %d prompt(s)\n", len(turns), len(prompts))
+	fmt.Fprintf(&builder, "Wall time:         %s", roundStat(wall))
+	if wall > 0 {
+		fmt.Fprintf(&builder, " (API %s, %.0f%%)", roundStat(api), 100*api.Seconds()/wall.Seconds())
+	}
+	fmt.Fprintf(&builder, "\nAPI latency:       %s", summarizeDurations(latencies))
+	if len(firstTokens) > 0 {
+		fmt.Fprintf(&builder, "\nFirst token:       %s", summarizeDurations(firstTokens))
+	}
+	if generating > 0 {
+		fmt.Fprintf(&builder, "\nThroughput:        %.1f tok/s after the first token", float64(outputTokens)/generating.Seconds())
+	}
+
+	builder.WriteString("\n\nRecent turns:")
+	start := max(len(turns)-statsRecentTurns, 0)
+	for index, turn := range turns[start:] {
+		request := agent.RequestMetrics{Model: turn.Model, TimeToFirstToken: turn.TimeToFirstToken, Latency: turn.APILatency, OutputTokens: turn.OutputTokens}
+		fmt.Fprintf(&builder, "\n  %d. %s · turn %s", start+index+1, request, roundStat(turn.Duration))
+	}
+	return builder.String()
+}
+
+// summarizeDurations renders the average, median, and slowest of values.
+func summarizeDurations(values []time.Duration) string {
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, value := range sorted {
+		total += value
+	}
+	average := total / time.Duration(len(sorted))
+	return fmt.Sprintf("avg %s · median %s · max %s", roundStat(average), roundStat(sorted[len(sorted)/2]), roundStat(sorted[len(sorted)-1]))
+}
+
+// roundStat rounds a duration for display.
+func roundStat(duration time.Duration) time.Duration {
+	if duration < time.Second {
+		return duration.Round(time.Millisecond)
+	}
+	return duration.Round(100 * time.Millisecond)
+}
+
+// turnTimings converts a run's turn metrics for json and stream-json results.
+func turnTimings(result *agent.RunResult) []streamjson.TurnTiming {
+	if result == nil {
+		return []streamjson.TurnTiming{}
+	}
+	timings := make([]streamjson.TurnTiming, 0, len(result.Turns))
+	for _, turn := range result.Turns {
+		timings = append(timings, streamjson.TurnTiming{
+			Model:              turn.Model,
+			DurationMS:         turn.Duration.Milliseconds(),
+			DurationAPIMS:      turn.APILatency.Milliseconds(),
+			TimeToFirstTokenMS: turn.TimeToFirstToken.Milliseconds(),
+			OutputTokens:       turn.OutputTokens,
+		})
+	}
+	return timings
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestFormatStatsReport verifies /stats summarizes latency, first token, and wall time.
+func TestFormatStatsReport(testingHandle *testing.T) {
+	testutil.RequireEqual(testingHandle, formatStatsReport(nil), "No API requests yet in this session.", "empty session")
+
+	// Arrange two prompts: one streamed turn, then two turns with a tool call between them.
+	prompts := []tuiCostTurn{
+		{Duration: 2 * time.Second, Turns: []agent.TurnMetrics{
+			{Model: "big", Duration: 2 * time.Second, APILatency: 1500 * time.Millisecond, TimeToFirstToken: 500 * time.Millisecond, OutputTokens: 100},
+		}},
+		{Duration: 4 * time.Second, Turns: []agent.TurnMetrics{
+			{Model: "big", Duration: 3 * time.Second, APILatency: time.Second, TimeToFirstToken: 200 * time.Millisecond, OutputTokens: 20},
+			{Model: "small", Duration: time.Second, APILatency: 500 * time.Millisecond},
+		}},
+	}
+
+	// Act.
+	report := formatStatsReport(prompts)
+
+	// Assert.
+	for _, want := range []string{
+		"Requests:          3 over 2 prompt(s)",
+		"Wall time:         6s (API 3s, 50%)",
+		"API latency:       avg 1s · median 1s · max 1.5s",
+		"First token:       avg 350ms · median 500ms · max 500ms",
+		"Throughput:        66.7 tok/s after the first token",
+		"  1. big 1.5s, first token 500ms, 100.0 tok/s (100 tokens) · turn 2s",
+		"  3. small 500ms (0 tokens) · turn 1s",
+	} {
+		testutil.RequireTrue(testingHandle, strings.Contains(report, want), "report contains "+want+"\n"+report)
+	}
+
+	// The json and stream-json results carry the same turns in milliseconds.
+	timings := turnTimings(&agent.RunResult{Turns: prompts[1].Turns})
+	testutil.RequireEqual(testingHandle, len(timings), 2, "timings")
+	testutil.RequireEqual(testingHandle, timings[0].DurationMS, int64(3000), "wall ms")
+	testutil.RequireEqual(testingHandle, timings[0].TimeToFirstTokenMS, int64(200), "ttft ms")
+	testutil.RequireEqual(testingHandle, timings[1].DurationAPIMS, int64(500), "api ms")
+}
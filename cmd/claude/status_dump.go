@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/session"
+)
+
+// maxGoroutineGroups caps the goroutine summary in a status dump.
+const maxGoroutineGroups = 15
+
+// startStatusDump attaches a status tracker to runner and writes a diagnostic
+// snapshot whenever the process receives SIGUSR1, without interrupting the run.
+// The returned function stops listening.
+func startStatusDump(opts *options, runner *agent.Runner, sessionID string, store *session.Store) func() {
+	runner.Status = agent.NewStatus()
+	signals := make(chan os.Signal, 1)
+	if !notifyStatusSignal(signals) {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-signals:
+				_ = writeStatusDump(opts, runner.Status, sessionID, store)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// writeStatusDump appends a snapshot to --debug-file, to stderr in print mode,
+// or to ~/.openclaude/debug/<session>-status.log in the TUI, where stderr
+// would corrupt the screen.
+func writeStatusDump(opts *options, status *agent.Status, sessionID string, store *session.Store) error {
+	dump := formatStatusDump(status.Snapshot(), sessionID, time.Now())
+	path := opts.DebugFile
+	if path == "" && opts.Print {
+		_, err := io.WriteString(os.Stderr, dump)
+		return err
+	}
+	if path == "" {
+		if store == nil {
+			return nil
+		}
+		path = filepath.Join(store.BaseDir, "debug", sessionID+"-status.log")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.WriteString(file, dump)
+	return err
+}
+
+// formatStatusDump renders the run snapshot followed by a goroutine summary.
+func formatStatusDump(snapshot agent.StatusSnapshot, sessionID string, now time.Time) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "=== OpenClaude status %s (pid %d, session %s) ===\n", now.UTC().Format(time.RFC3339), os.Getpid(), sessionID)
+	builder.WriteString(snapshot.Format(now))
+	builder.WriteString(summarizeGoroutines(allGoroutineStacks(), maxGoroutineGroups))
+	return builder.String()
+}
+
+// allGoroutineStacks returns the stacks of every goroutine.
+func allGoroutineStacks() []byte {
+	buffer := make([]byte, 64<<10)
+	for {
+		size := runtime.Stack(buffer, true)
+		if size < len(buffer) {
+			return buffer[:size]
+		}
+		buffer = make([]byte, len(buffer)*2)
+	}
+}
+
+// summarizeGoroutines groups goroutines by state and top function, largest groups first.
+func summarizeGoroutines(stacks []byte, limit int) string {
+	counts := map[string]int{}
+	total := 0
+	for _, block := range bytes.Split(bytes.TrimSpace(stacks), []byte("\n\n")) {
+		lines := strings.Split(string(block), "\n")
+		if len(lines) == 0 || !strings.HasPrefix(lines[0], "goroutine ") {
+			continue
+		}
+		total++
+		state := ""
+		if start, end := strings.Index(lines[0], "["), strings.LastIndex(lines[0], "]"); start >= 0 && end > start {
+			// Drop durations such as ", 5 minutes" so waits group together.
+			state, _, _ = strings.Cut(lines[0][start+1:end], ",")
+		}
+		function := "?"
+		if len(lines) > 1 {
+			function = lines[1]
+			if paren := strings.LastIndex(function, "("); paren > 0 {
+				function = function[:paren]
+			}
+		}
+		counts["["+state+"] "+function]++
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "  goroutines: %d\n", total)
+	for index, key := range keys {
+		if index == limit {
+			fmt.Fprintf(&builder, "    ... %d more group(s)\n", len(keys)-limit)
+			break
+		}
+		fmt.Fprintf(&builder, "    %d × %s\n", counts[key], key)
+	}
+	return builder.String()
+}
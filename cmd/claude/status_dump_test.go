@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSummarizeGoroutines verifies goroutines group by state and top function.
+func TestSummarizeGoroutines(testingHandle *testing.T) {
+	stacks := `goroutine 1 [running]:
+main.main()
+	/src/main.go:10 +0x1
+
+goroutine 7 [select, 5 minutes]:
+net/http.(*persistConn).readLoop(0xc000)
+	/go/src/net/http/transport.go:2 +0x2
+
+goroutine 8 [select]:
+net/http.(*persistConn).readLoop(0xc001)
+	/go/src/net/http/transport.go:2 +0x2
+`
+	summary := summarizeGoroutines([]byte(stacks), 1)
+	testutil.RequireTrue(testingHandle, strings.Contains(summary, "goroutines: 3\n"), "total: "+summary)
+	testutil.RequireTrue(testingHandle, strings.Contains(summary, "2 × [select] net/http.(*persistConn).readLoop\n"), "grouped: "+summary)
+	testutil.RequireTrue(testingHandle, strings.Contains(summary, "... 1 more group(s)"), "limited: "+summary)
+}
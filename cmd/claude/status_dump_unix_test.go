@@ -0,0 +1,37 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestStatusDumpOnSIGUSR1 verifies the signal appends a snapshot to the debug file.
+func TestStatusDumpOnSIGUSR1(testingHandle *testing.T) {
+	debugFile := filepath.Join(testingHandle.TempDir(), "debug.log")
+	runner := &agent.Runner{}
+	stop := startStatusDump(&options{DebugFile: debugFile}, runner, "session-1", nil)
+	defer stop()
+	testutil.RequireTrue(testingHandle, runner.Status != nil, "status attached")
+
+	testutil.RequireNoError(testingHandle, syscall.Kill(os.Getpid(), syscall.SIGUSR1), "send SIGUSR1")
+
+	var dump string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if data, err := os.ReadFile(debugFile); err == nil && strings.Contains(string(data), "goroutines:") {
+			dump = string(data)
+			break
+		}
+	}
+	for _, want := range []string{"session session-1", "run: idle", "tool: none in flight", "tokens so far: 0 in, 0 out", "goroutines:"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(dump, want), "dump contains "+want+"\n"+dump)
+	}
+}
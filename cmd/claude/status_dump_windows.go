@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// notifyStatusSignal reports false: Windows has no SIGUSR1.
+func notifyStatusSignal(chan<- os.Signal) bool {
+	return false
+}
@@ -10,7 +10,31 @@ import (
 	"github.com/openclaude/openclaude/internal/tools"
 )
 
+// controlState carries stream-json control handling state across requests.
+type controlState struct {
+	// writer receives control responses and status events.
+	writer *streamjson.Writer
+	// opts is updated in place by control requests.
+	opts *options
+	// runner receives permission and thinking changes.
+	runner *agent.Runner
+	// settings supplies output style for the initialize response.
+	settings *config.Settings
+	// sessionID tags emitted events.
+	sessionID string
+	// defaultModel is the model "default" resolves to.
+	defaultModel string
+	// model is the model resolved after applying requests.
+	model string
+	// initialized rejects repeated initialize requests.
+	initialized bool
+	// authStatusEmitted records whether an auth_status event was written.
+	authStatusEmitted bool
+}
+
 // applyStreamJSONControlRequests processes control requests and emits responses.
+// Each subtype is dispatched through the command registry; unknown subtypes get
+// an error response rather than aborting the run.
 func applyStreamJSONControlRequests(
 	parsed *streamJSONInput,
 	writer *streamjson.Writer,
@@ -30,90 +54,96 @@ func applyStreamJSONControlRequests(
 		return model, false, fmt.Errorf("runner is required")
 	}
 
-	initialized := false
-	authStatusEmitted := false
-	resolvedModel := model
-
+	state := &controlState{
+		writer:       writer,
+		opts:         opts,
+		runner:       runner,
+		settings:     settings,
+		sessionID:    sessionID,
+		defaultModel: model,
+		model:        model,
+	}
 	for _, request := range parsed.ControlRequests {
 		subtype := stringField(request.Request, "subtype")
-		switch subtype {
-		case "initialize":
-			if initialized {
-				if err := writeControlResponseError(writer, request.RequestID, "Already initialized"); err != nil {
-					return resolvedModel, authStatusEmitted, err
-				}
-				continue
-			}
-			initialized = true
-			applyInitializeRequest(request.Request, opts, &resolvedModel, model)
-			response := buildInitializeControlResponse(opts, settings, resolvedModel)
-			if err := writeControlResponseSuccess(writer, request.RequestID, response); err != nil {
-				return resolvedModel, authStatusEmitted, err
-			}
-			if opts.EnableAuthStatus && !authStatusEmitted {
-				if err := emitAuthStatus(writer, sessionID, false, "", ""); err != nil {
-					return resolvedModel, authStatusEmitted, err
-				}
-				authStatusEmitted = true
-			}
-		case "set_permission_mode":
-			mode := stringField(request.Request, "mode", "permissionMode", "permission_mode")
-			permissionMode, err := parsePermissionModeStrict(mode)
-			if err != nil {
-				if writeErr := writeControlResponseError(writer, request.RequestID, err.Error()); writeErr != nil {
-					return resolvedModel, authStatusEmitted, writeErr
-				}
-				continue
-			}
-			opts.PermissionMode = string(permissionMode)
-			runner.Permissions.Mode = permissionMode
-			if err := writeControlResponseSuccess(writer, request.RequestID, map[string]any{"mode": opts.PermissionMode}); err != nil {
-				return resolvedModel, authStatusEmitted, err
-			}
-			if err := emitSystemStatus(writer, sessionID, opts.PermissionMode); err != nil {
-				return resolvedModel, authStatusEmitted, err
-			}
-		case "set_model":
-			requestedModel := stringField(request.Request, "model")
-			if requestedModel == "" {
-				if err := writeControlResponseError(writer, request.RequestID, "Missing model"); err != nil {
-					return resolvedModel, authStatusEmitted, err
-				}
-				continue
-			}
-			if requestedModel == "default" {
-				resolvedModel = model
-			} else {
-				resolvedModel = requestedModel
-			}
-			opts.Model = requestedModel
-			if err := writeControlResponseSuccess(writer, request.RequestID, map[string]any{"model": resolvedModel}); err != nil {
-				return resolvedModel, authStatusEmitted, err
-			}
-		case "set_max_thinking_tokens":
-			value, ok := numberField(request.Request, "max_thinking_tokens", "maxThinkingTokens")
-			if !ok {
-				if err := writeControlResponseError(writer, request.RequestID, "Missing max_thinking_tokens"); err != nil {
-					return resolvedModel, authStatusEmitted, err
-				}
-				continue
-			}
-			opts.MaxThinkingTokens = int(value)
-			if err := writeControlResponseSuccess(writer, request.RequestID, map[string]any{"max_thinking_tokens": opts.MaxThinkingTokens}); err != nil {
-				return resolvedModel, authStatusEmitted, err
-			}
-		case "interrupt":
-			if err := writeControlResponseSuccess(writer, request.RequestID, map[string]any{}); err != nil {
-				return resolvedModel, authStatusEmitted, err
-			}
-		default:
-			if err := writeControlResponseError(writer, request.RequestID, fmt.Sprintf("Unsupported control request subtype: %s", subtype)); err != nil {
-				return resolvedModel, authStatusEmitted, err
-			}
+		spec, ok := lookupCommand(subtype, commandModeControl)
+		var err error
+		if ok {
+			err = spec.Control(state, request)
+		} else {
+			err = writeControlResponseError(writer, request.RequestID, fmt.Sprintf("Unsupported control request subtype: %s", subtype))
+		}
+		if err != nil {
+			return state.model, state.authStatusEmitted, err
 		}
 	}
 
-	return resolvedModel, authStatusEmitted, nil
+	return state.model, state.authStatusEmitted, nil
+}
+
+// controlInitialize applies initialize overrides and reports session capabilities.
+func controlInitialize(state *controlState, request streamJSONControlRequest) error {
+	if state.initialized {
+		return writeControlResponseError(state.writer, request.RequestID, "Already initialized")
+	}
+	state.initialized = true
+	applyInitializeRequest(request.Request, state.opts, &state.model, state.defaultModel)
+	response := buildInitializeControlResponse(state.opts, state.settings, state.model)
+	if err := writeControlResponseSuccess(state.writer, request.RequestID, response); err != nil {
+		return err
+	}
+	if state.opts.EnableAuthStatus && !state.authStatusEmitted {
+		if err := emitAuthStatus(state.writer, state.sessionID, false, "", ""); err != nil {
+			return err
+		}
+		state.authStatusEmitted = true
+	}
+	return nil
+}
+
+// controlSetPermissionMode switches the permission mode and emits a status event.
+func controlSetPermissionMode(state *controlState, request streamJSONControlRequest) error {
+	mode := stringField(request.Request, "mode", "permissionMode", "permission_mode")
+	permissionMode, err := parsePermissionModeStrict(mode)
+	if err != nil {
+		return writeControlResponseError(state.writer, request.RequestID, err.Error())
+	}
+	state.opts.PermissionMode = string(permissionMode)
+	state.runner.Permissions.Mode = permissionMode
+	if err := writeControlResponseSuccess(state.writer, request.RequestID, map[string]any{"mode": state.opts.PermissionMode}); err != nil {
+		return err
+	}
+	return emitSystemStatus(state.writer, state.sessionID, state.opts.PermissionMode)
+}
+
+// controlSetModel changes the model used for subsequent turns.
+func controlSetModel(state *controlState, request streamJSONControlRequest) error {
+	requestedModel := stringField(request.Request, "model")
+	if requestedModel == "" {
+		return writeControlResponseError(state.writer, request.RequestID, "Missing model")
+	}
+	if requestedModel == "default" {
+		state.model = state.defaultModel
+	} else {
+		state.model = requestedModel
+	}
+	state.opts.Model = requestedModel
+	return writeControlResponseSuccess(state.writer, request.RequestID, map[string]any{"model": state.model})
+}
+
+// controlSetMaxThinkingTokens updates the thinking budget on options and the runner.
+func controlSetMaxThinkingTokens(state *controlState, request streamJSONControlRequest) error {
+	value, ok := numberField(request.Request, "max_thinking_tokens", "maxThinkingTokens")
+	if !ok {
+		return writeControlResponseError(state.writer, request.RequestID, "Missing max_thinking_tokens")
+	}
+	state.opts.MaxThinkingTokens = int(value)
+	state.runner.MaxThinkingTokens = state.opts.MaxThinkingTokens
+	return writeControlResponseSuccess(state.writer, request.RequestID, map[string]any{"max_thinking_tokens": state.opts.MaxThinkingTokens})
+}
+
+// controlInterrupt acknowledges interrupts; queued input has no turn in flight yet.
+func controlInterrupt(state *controlState, request streamJSONControlRequest) error {
+	return writeControlResponseSuccess(state.writer, request.RequestID, map[string]any{})
 }
 
 // applyInitializeRequest updates option values based on an initialize control request.
@@ -158,7 +188,7 @@ func applyInitializeRequest(request map[string]any, opts *options, resolvedModel
 // buildInitializeControlResponse assembles the initialize response payload.
 func buildInitializeControlResponse(opts *options, settings *config.Settings, model string) map[string]any {
 	return map[string]any{
-		"commands":                []map[string]string{},
+		"commands":                initializeCommandList(opts),
 		"output_style":            resolveOutputStyle(settings),
 		"available_output_styles": []string{"default"},
 		"models":                  buildModelOptions(model, opts.FallbackModel),
@@ -172,6 +202,22 @@ func buildInitializeControlResponse(opts *options, settings *config.Settings, mo
 	}
 }
 
+// initializeCommandList describes init-mode slash commands for the initialize response.
+func initializeCommandList(opts *options) []map[string]string {
+	commands := []map[string]string{}
+	if opts != nil && opts.DisableSlashCommands {
+		return commands
+	}
+	for _, spec := range commandsForMode(commandModeInit) {
+		commands = append(commands, map[string]string{
+			"name":         spec.Name,
+			"description":  spec.Description,
+			"argumentHint": spec.argumentHint(),
+		})
+	}
+	return commands
+}
+
 // buildModelOptions produces a Claude Code compatible model list.
 func buildModelOptions(model string, fallback string) []map[string]string {
 	seen := map[string]bool{}
@@ -56,4 +56,12 @@ func TestApplyStreamJSONControlRequestsInitialize(testingHandle *testing.T) {
 	if response["request_id"] != "req-1" {
 		testingHandle.Fatalf("expected request_id req-1, got %v", response["request_id"])
 	}
+	commands, ok := response["response"].(map[string]any)["commands"].([]any)
+	if !ok || len(commands) != len(commandNames(commandModeInit)) {
+		testingHandle.Fatalf("expected init commands in initialize response, got %v", response["response"])
+	}
+	first, _ := commands[0].(map[string]any)
+	if first["name"] != "keybindings-help" || first["description"] == "" {
+		testingHandle.Fatalf("unexpected first command %v", first)
+	}
 }
@@ -77,7 +77,9 @@ func TestSystemInitEventFieldsAndOrder(testingHandle *testing.T) {
 
 	// Validate key lists to ensure compatibility-sensitive ordering is preserved.
 	testutil.RequireEqual(testingHandle, extractStringSlice(payload["tools"]), expectedToolNames(), "tool list mismatch")
-	testutil.RequireEqual(testingHandle, extractStringSlice(payload["slash_commands"]), defaultSlashCommandList(), "slash command list mismatch")
+	testutil.RequireEqual(testingHandle, extractStringSlice(payload["slash_commands"]), []string{
+		"keybindings-help", "compact", "context", "cost", "init", "pr-comments", "release-notes", "review", "security-review",
+	}, "slash command list mismatch")
 	testutil.RequireEqual(testingHandle, extractStringSlice(payload["agents"]), defaultAgentList(), "agent list mismatch")
 	testutil.RequireEqual(testingHandle, extractStringSlice(payload["skills"]), defaultSkillList(), "skill list mismatch")
 
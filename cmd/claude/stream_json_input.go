@@ -8,13 +8,14 @@ import (
 	"os"
 	"strings"
 
-	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/streamjson"
 )
 
 // streamJSONInput captures parsed stream-json input for print mode.
 type streamJSONInput struct {
-	// Messages holds user messages extracted from the input stream.
-	Messages []openai.Message
+	// Messages holds the user and assistant messages extracted from the input stream.
+	Messages []llm.Message
 	// UserMessages preserves user message metadata for replay output.
 	UserMessages []streamJSONUserMessage
 	// ControlRequests stores control requests that must be handled before execution.
@@ -26,7 +27,7 @@ type streamJSONInput struct {
 // streamJSONUserMessage keeps the user message plus stream-json metadata.
 type streamJSONUserMessage struct {
 	// Message is the parsed OpenAI-compatible user message.
-	Message openai.Message
+	Message llm.Message
 	// UUID is the stream-json UUID, if provided by the input line.
 	UUID string
 	// IsReplay reports whether the input explicitly marked the message as a replay.
@@ -67,7 +68,7 @@ func readStreamInputWithControl(reader io.Reader) (*streamJSONInput, error) {
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("read stream input: %w", err)
 	}
-	if len(parsed.Messages) == 0 {
+	if len(parsed.UserMessages) == 0 {
 		return nil, fmt.Errorf("no user messages found in stream input")
 	}
 	return parsed, nil
@@ -104,6 +105,14 @@ func handleStreamJSONPayload(payload map[string]any, parsed *streamJSONInput) er
 		}
 	}
 
+	// Assistant messages seed the conversation; a trailing one is a prefill.
+	if message, ok := parseStreamAssistantMessage(payload); ok {
+		if llm.ContentText(message.Content) != "" {
+			parsed.Messages = append(parsed.Messages, message)
+		}
+		return nil
+	}
+
 	// Fall back to user message parsing for other payloads.
 	userMessage, ok := parseStreamMessageWithMetadata(payload)
 	if !ok {
@@ -114,6 +123,19 @@ func handleStreamJSONPayload(payload map[string]any, parsed *streamJSONInput) er
 	return nil
 }
 
+// parseStreamAssistantMessage extracts the text of an assistant message, given
+// directly or in a stream-json envelope.
+func parseStreamAssistantMessage(payload map[string]any) (llm.Message, bool) {
+	message := payload
+	if inner, ok := payload["message"].(map[string]any); ok {
+		message = inner
+	}
+	if role, _ := message["role"].(string); role != "assistant" {
+		return llm.Message{}, false
+	}
+	return llm.Message{Role: "assistant", Content: streamjson.ExtractText(message["content"])}, true
+}
+
 // parseStreamMessageWithMetadata extracts a user message along with stream-json metadata.
 func parseStreamMessageWithMetadata(payload map[string]any) (streamJSONUserMessage, bool) {
 	message, ok := parseStreamMessage(payload)
@@ -55,7 +55,7 @@ func TestReadStreamInputWithControlParsesUserAndControl(testingHandle *testing.T
 // TestReadStreamInputWithControlRejectsUnknownType ensures strict input validation.
 func TestReadStreamInputWithControlRejectsUnknownType(testingHandle *testing.T) {
 	// Arrange a payload with an unsupported top-level type.
-	payload := `{"type":"assistant","message":{"role":"assistant","content":"hi"}}`
+	payload := `{"type":"result","subtype":"success","result":"hi"}`
 
 	// Act.
 	_, err := readStreamInputWithControl(strings.NewReader(payload))
@@ -65,3 +65,33 @@ func TestReadStreamInputWithControlRejectsUnknownType(testingHandle *testing.T)
 		testingHandle.Fatalf("expected error for unsupported payload type")
 	}
 }
+
+// TestReadStreamInputKeepsAssistantPrefill verifies assistant lines join the
+// conversation in order, so a trailing one reaches the model as a prefill.
+func TestReadStreamInputKeepsAssistantPrefill(testingHandle *testing.T) {
+	// Arrange a user prompt followed by an assistant prefill.
+	payload := strings.Join([]string{
+		`{"type":"user","message":{"role":"user","content":"status as JSON"}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"{"}]}}`,
+	}, "\n")
+
+	// Act.
+	parsed, err := readStreamInputWithControl(strings.NewReader(payload))
+
+	// Assert.
+	if err != nil {
+		testingHandle.Fatalf("readStreamInputWithControl error: %v", err)
+	}
+	if len(parsed.Messages) != 2 || parsed.Messages[1].Role != "assistant" || parsed.Messages[1].Content != "{" {
+		testingHandle.Fatalf("expected user then assistant prefill, got %+v", parsed.Messages)
+	}
+	if len(parsed.UserMessages) != 1 {
+		testingHandle.Fatalf("expected only the user line in replay metadata, got %+v", parsed.UserMessages)
+	}
+	if flagged := withPrefill(parsed.Messages, "["); len(flagged) != 2 {
+		testingHandle.Fatalf("expected --prefill to leave a stream-json prefill alone, got %+v", flagged)
+	}
+	if prefilled := withPrefill(parsed.Messages[:1], "["); len(prefilled) != 2 || prefilled[1].Content != "[" {
+		testingHandle.Fatalf("expected --prefill to append an assistant message, got %+v", prefilled)
+	}
+}
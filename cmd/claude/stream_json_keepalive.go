@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/openclaude/openclaude/internal/agent"
 	"github.com/openclaude/openclaude/internal/streamjson"
 )
 
@@ -93,3 +94,22 @@ func (e *keepAliveEmitter) setErr(err error) {
 		e.err = err
 	}
 }
+
+// writeRunProgress reports a run heartbeat or retry as a "run_progress"
+// progress event whose status is the phase.
+func writeRunProgress(writer *streamjson.Writer, sessionID string, event agent.ProgressEvent) error {
+	return writer.Write(streamjson.ProgressEvent{
+		Type: "progress",
+		Data: streamjson.ProgressData{
+			Type:      "run_progress",
+			ToolName:  event.Tool,
+			Status:    string(event.Phase),
+			Message:   event.String(),
+			Model:     event.Model,
+			ElapsedMS: event.Elapsed.Milliseconds(),
+			Attempt:   event.Attempt,
+		},
+		SessionID: sessionID,
+		UUID:      streamjson.NewUUID(),
+	})
+}
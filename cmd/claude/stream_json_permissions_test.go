@@ -8,12 +8,13 @@ import (
 	"time"
 
 	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/errcode"
 	"github.com/openclaude/openclaude/internal/streamjson"
 )
 
 // TestExtractPermissionDenialsToolDenied verifies denied tool errors are surfaced.
 func TestExtractPermissionDenialsToolDenied(testingHandle *testing.T) {
-	err := fmt.Errorf("%w: %s", agent.ErrToolDenied, "Bash")
+	err := fmt.Errorf("run: %w", &errcode.ToolDeniedError{ToolName: "Bash", Detail: "uncommitted changes in main.go"})
 	denials := extractPermissionDenials(err)
 	if len(denials) != 1 {
 		testingHandle.Fatalf("expected 1 denial, got %d", len(denials))
@@ -58,7 +59,7 @@ func TestExtractPermissionDenialsDefault(testingHandle *testing.T) {
 
 // TestWriteStreamJSONErrorResultIncludesDenials ensures result payload includes denials.
 func TestWriteStreamJSONErrorResultIncludesDenials(testingHandle *testing.T) {
-	err := fmt.Errorf("%w: %s", agent.ErrToolDenied, "Write")
+	err := error(&errcode.ToolDeniedError{ToolName: "Write"})
 
 	var buffer bytes.Buffer
 	writer := streamjson.NewWriter(&buffer)
@@ -88,4 +89,7 @@ func TestWriteStreamJSONErrorResultIncludesDenials(testingHandle *testing.T) {
 	if entry["tool_name"] != "Write" || entry["reason"] != "user_denied" {
 		testingHandle.Fatalf("unexpected denial payload: %v", entry)
 	}
+	if payload["error_code"] != "tool_denied" {
+		testingHandle.Fatalf("expected error_code tool_denied, got %v", payload["error_code"])
+	}
 }
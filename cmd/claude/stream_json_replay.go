@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/streamjson"
 )
 
 // streamJSONRecorder captures emitted stream-json lines while forwarding output.
@@ -202,6 +203,9 @@ func replayStoredStreamJSON(store *session.Store, sessionID string, writer io.Wr
 	}
 	replayed := false
 	writeLines := func(target io.Writer) error {
+		// Stored lines are already encoded, so they go through the writer's
+		// raw path instead of being decoded and marshaled again.
+		emitter := streamjson.NewWriter(target)
 		for _, line := range lines {
 			trimmed := strings.TrimSpace(line)
 			if trimmed == "" {
@@ -214,7 +218,7 @@ func replayStoredStreamJSON(store *session.Store, sessionID string, writer io.Wr
 			if !shouldReplayStreamJSONEnvelope(envelope) {
 				continue
 			}
-			if _, err := io.WriteString(target, trimmed+"\n"); err != nil {
+			if err := emitter.Write(json.RawMessage(trimmed)); err != nil {
 				return fmt.Errorf("write stream-json replay: %w", err)
 			}
 			replayed = true
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/openclaude/openclaude/internal/agent"
 	"github.com/openclaude/openclaude/internal/streamjson"
 	"github.com/openclaude/openclaude/internal/testutil"
 )
@@ -62,6 +63,33 @@ func TestResultEventFieldOrder(testingHandle *testing.T) {
 }
 
 // assertJSONKeyOrderResult ensures keys appear in the expected order within the JSON line.
+// TestToolUseResultCarriesStreams verifies command tool results carry stdout,
+// stderr, exit code, and duration in tool_use_result, and other tools omit it.
+func TestToolUseResultCarriesStreams(testingHandle *testing.T) {
+	// Arrange a failed command result and a plain tool result.
+	exitCode := 2
+	command := agent.ToolEvent{Type: "tool_result", ToolID: "call-1", Result: "out\nerr", IsError: true, Stdout: "out\n", Stderr: "err\n", ExitCode: &exitCode, DurationMS: 42}
+	plain := agent.ToolEvent{Type: "tool_result", ToolID: "call-2", Result: "file contents"}
+
+	// Act
+	var buffer bytes.Buffer
+	writer := streamjson.NewWriter(&buffer)
+	testutil.RequireNoError(testingHandle, writer.Write(streamjson.UserEvent{Type: "user", ToolUseResult: toolUseResult(command)}), "write command result")
+	testutil.RequireNoError(testingHandle, writer.Write(streamjson.UserEvent{Type: "user", ToolUseResult: toolUseResult(plain)}), "write plain result")
+
+	// Assert
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	var payload struct {
+		ToolUseResult map[string]any `json:"tool_use_result"`
+	}
+	testutil.RequireNoError(testingHandle, json.Unmarshal([]byte(lines[0]), &payload), "parse command result")
+	testutil.RequireEqual(testingHandle, payload.ToolUseResult["stdout"], any("out\n"), "stdout")
+	testutil.RequireEqual(testingHandle, payload.ToolUseResult["stderr"], any("err\n"), "stderr")
+	testutil.RequireEqual(testingHandle, payload.ToolUseResult["exitCode"], any(float64(2)), "exit code")
+	testutil.RequireEqual(testingHandle, payload.ToolUseResult["durationMs"], any(float64(42)), "duration")
+	testutil.RequireTrue(testingHandle, !strings.Contains(lines[1], "tool_use_result"), "plain tools omit tool_use_result: "+lines[1])
+}
+
 func assertJSONKeyOrderResult(testingHandle *testing.T, line string, keys []string) {
 	testingHandle.Helper()
 	lastIndex := -1
@@ -15,9 +15,19 @@ func resolveSystemPrompt(opts *options, runner *agent.Runner) string {
 	toolNames := listToolNames(runner)
 	prompt := agent.DefaultSystemPrompt(toolNames)
 
-	// Apply the explicit system prompt override when provided.
+	// Apply the explicit system prompt override when provided; the detected
+	// toolchain and git status only extend the default prompt.
 	if opts.SystemPrompt != "" {
 		prompt = opts.SystemPrompt
+	} else {
+		if opts.Toolchain != nil {
+			if summary := opts.Toolchain.Summary(); summary != "" {
+				prompt = prompt + "\n\n" + summary
+			}
+		}
+		if summary := opts.GitStatus.Summary(); summary != "" {
+			prompt = prompt + "\n\n" + summary
+		}
 	}
 
 	// Append extra instructions after any base prompt.
@@ -42,20 +52,33 @@ func resolveOutputStyle(settings *config.Settings) string {
 	return "default"
 }
 
-// listAgentNames returns the sorted list of agent identifiers from the JSON payload.
+// listAgentNames returns built-in (or --agents JSON) agent identifiers plus .claude/agents definitions.
 func listAgentNames(opts *options) []any {
-	if opts.AgentsJSON == "" {
-		return stringsToAny(defaultAgentList())
+	names := defaultAgentList()
+	if opts == nil {
+		return stringsToAny(names)
+	}
+	if opts.AgentsJSON != "" {
+		var payload map[string]any
+		if err := json.Unmarshal([]byte(opts.AgentsJSON), &payload); err == nil {
+			names = make([]string, 0, len(payload))
+			for name := range payload {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
 	}
-	var payload map[string]any
-	if err := json.Unmarshal([]byte(opts.AgentsJSON), &payload); err != nil {
-		return stringsToAny(defaultAgentList())
+	// Append .claude/agents definitions after the built-ins, skipping duplicates.
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
 	}
-	names := make([]string, 0, len(payload))
-	for name := range payload {
-		names = append(names, name)
+	for _, definition := range opts.AgentDefinitions {
+		if !seen[definition.Name] {
+			seen[definition.Name] = true
+			names = append(names, definition.Name)
+		}
 	}
-	sort.Strings(names)
 	return stringsToAny(names)
 }
 
@@ -72,23 +95,7 @@ func listSlashCommands(opts *options) []string {
 	if opts != nil && opts.DisableSlashCommands {
 		return []string{}
 	}
-	return defaultSlashCommandList()
-}
-
-// defaultSlashCommandList returns the built-in slash command identifiers.
-// The ordering matches Claude Code output so stream-json consumers stay compatible.
-func defaultSlashCommandList() []string {
-	return []string{
-		"keybindings-help",
-		"compact",
-		"context",
-		"cost",
-		"init",
-		"pr-comments",
-		"release-notes",
-		"review",
-		"security-review",
-	}
+	return commandNames(commandModeInit)
 }
 
 // defaultAgentList returns the built-in agent profile identifiers.
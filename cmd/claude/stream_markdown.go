@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tuiStreamRenderInterval is the shortest gap between markdown renders of the
+// streaming reply's active block.
+const tuiStreamRenderInterval = 100 * time.Millisecond
+
+// streamRenderMsg re-renders the streaming reply once a damped render is due.
+type streamRenderMsg struct{}
+
+// tuiStreamRender holds the streaming reply's markdown between deltas. Blocks
+// that are done (everything before the last blank line outside a code fence)
+// are rendered once; the active block is re-rendered at most once per
+// tuiStreamRenderInterval and shown as last rendered in between.
+type tuiStreamRender struct {
+	// Stable is the finished blocks' markdown.
+	Stable string
+	// StableRendered is Stable rendered.
+	StableRendered string
+	// Text is the streamed text Rendered shows.
+	Text string
+	// Rendered is the full streaming reply as last rendered.
+	Rendered string
+	// At is when Rendered was produced.
+	At time.Time
+	// Stale marks text newer than Rendered.
+	Stale bool
+	// Scheduled marks a pending streamRenderMsg.
+	Scheduled bool
+}
+
+// renderStreamingMarkdown renders the streaming reply, falling back to the
+// raw text when markdown is off or the reply is too large to render live.
+func (m *tuiModel) renderStreamingMarkdown(text string) string {
+	renderer := m.markdownRenderer
+	if renderer == nil || renderer.plain || renderer.render == nil || markdownTooComplex(text) != "" {
+		return text
+	}
+	state := &m.streamRender
+	if text == state.Text {
+		state.Stale = false
+		return state.Rendered
+	}
+	// A reply that only grew keeps its last render until the next one is due.
+	if strings.HasPrefix(text, state.Text) && state.Text != "" && time.Since(state.At) < tuiStreamRenderInterval {
+		state.Stale = true
+		return state.Rendered
+	}
+	stable, active := splitStreamingMarkdown(text)
+	if stable != state.Stable {
+		state.Stable = stable
+		state.StableRendered = ""
+		if stable != "" {
+			state.StableRendered = renderer.renderWithFallback(stable)
+		}
+	}
+	rendered := renderer.renderWithFallback(active)
+	if state.StableRendered != "" {
+		rendered = trimRenderedMargin(state.StableRendered) + "\n\n" + strings.TrimLeft(rendered, "\n")
+	}
+	state.Text, state.Rendered, state.At, state.Stale = text, rendered, time.Now(), false
+	return rendered
+}
+
+// scheduleStreamRender returns a tick for a damped render that is waiting.
+func (m *tuiModel) scheduleStreamRender() tea.Cmd {
+	if !m.streamRender.Stale || m.streamRender.Scheduled {
+		return nil
+	}
+	m.streamRender.Scheduled = true
+	wait := tuiStreamRenderInterval - time.Since(m.streamRender.At)
+	return tea.Tick(max(wait, time.Millisecond), func(time.Time) tea.Msg {
+		return streamRenderMsg{}
+	})
+}
+
+// handleStreamRender renders the text that arrived while a render was damped.
+func (m *tuiModel) handleStreamRender() tea.Cmd {
+	m.streamRender.Scheduled = false
+	if !m.running || !m.streamRender.Stale {
+		return nil
+	}
+	m.refreshChat()
+	return m.scheduleStreamRender()
+}
+
+// splitStreamingMarkdown splits streamed markdown at the last blank line
+// outside a code fence into finished blocks and the block still being written.
+func splitStreamingMarkdown(text string) (string, string) {
+	lines := strings.Split(text, "\n")
+	split := -1
+	inFence := false
+	for index, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t>")
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && strings.TrimSpace(line) == "" {
+			split = index
+		}
+	}
+	if split < 0 {
+		return "", text
+	}
+	stable := strings.TrimSpace(strings.Join(lines[:split], "\n"))
+	active := strings.TrimSpace(strings.Join(lines[split+1:], "\n"))
+	if active == "" {
+		// Nothing after the break yet: the last block is done but may still grow a list.
+		return "", text
+	}
+	return stable, active
+}
+
+// trimRenderedMargin drops the blank lines glamour adds after a document,
+// keeping a reset so no style leaks into the text joined after it.
+func trimRenderedMargin(rendered string) string {
+	for {
+		trimmed := strings.TrimSuffix(strings.TrimRight(rendered, " \n"), "\x1b[0m")
+		if trimmed == rendered {
+			return rendered + "\x1b[0m"
+		}
+		rendered = trimmed
+	}
+}
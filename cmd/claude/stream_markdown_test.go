@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSplitStreamingMarkdown verifies streamed text splits at the last blank
+// line outside a code fence.
+func TestSplitStreamingMarkdown(testingHandle *testing.T) {
+	cases := []struct {
+		name   string
+		text   string
+		stable string
+		active string
+	}{
+		{name: "one block", text: "Some text", active: "Some text"},
+		{name: "two blocks", text: "# Title\n\nfirst\n\nsecond", stable: "# Title\n\nfirst", active: "second"},
+		{name: "blank inside a fence", text: "intro\n\n```go\na := 1\n\nb := 2", stable: "intro", active: "```go\na := 1\n\nb := 2"},
+		{name: "nothing after the break", text: "- one\n\n", active: "- one\n\n"},
+	}
+	for _, testCase := range cases {
+		testingHandle.Run(testCase.name, func(testingHandle *testing.T) {
+			stable, active := splitStreamingMarkdown(testCase.text)
+			testutil.RequireEqual(testingHandle, stable, testCase.stable, "stable")
+			testutil.RequireEqual(testingHandle, active, testCase.active, "active")
+		})
+	}
+}
+
+// TestStreamingMarkdownIsDamped verifies streamed text renders as markdown,
+// keeps its last render until the next is due, and renders finished blocks once.
+func TestStreamingMarkdownIsDamped(testingHandle *testing.T) {
+	// Arrange
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	var rendered []string
+	model.markdownRenderer = &markdownRenderer{timeout: time.Second, render: func(content string) (string, error) {
+		rendered = append(rendered, content)
+		return "<" + content + ">\n\n", nil
+	}}
+	first := "# Title\n\nSome **bold**"
+
+	// Act
+	initial := model.renderStreamingMarkdown(first)
+	damped := model.renderStreamingMarkdown(first + " text")
+	tick := model.scheduleStreamRender()
+	again := model.scheduleStreamRender()
+	model.streamRender.At = time.Now().Add(-time.Second)
+	caughtUp := model.renderStreamingMarkdown(first + " text")
+
+	// Assert
+	testutil.RequireEqual(testingHandle, initial, "<# Title>\x1b[0m\n\n<Some **bold**>\n\n", "blocks rendered and joined")
+	testutil.RequireEqual(testingHandle, damped, initial, "last render shown until the next is due")
+	testutil.RequireTrue(testingHandle, tick != nil && again == nil, "one render scheduled")
+	testutil.RequireEqual(testingHandle, caughtUp, "<# Title>\x1b[0m\n\n<Some **bold** text>\n\n", "new text rendered once due")
+	testutil.RequireEqual(testingHandle, strings.Join(rendered, "|"), "# Title|Some **bold**|Some **bold** text", "finished block rendered once")
+	testutil.RequireTrue(testingHandle, !model.streamRender.Stale, "caught up")
+}
+
+// TestStreamingMarkdownPlain verifies /plain and oversized replies stream as raw text.
+func TestStreamingMarkdownPlain(testingHandle *testing.T) {
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	huge := strings.Repeat("line\n", maxMarkdownLines+1)
+	testutil.RequireEqual(testingHandle, model.renderStreamingMarkdown(huge), huge, "too large to render live")
+	model.togglePlainMarkdown("on")
+	testutil.RequireEqual(testingHandle, model.renderStreamingMarkdown("Some **bold**"), "Some **bold**", "plain")
+}
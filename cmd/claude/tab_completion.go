@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// tabCompletionCandidates lists completions for a partial path or tool name.
+// Paths complete one directory level at a time relative to cwd ("~" and
+// absolute paths included) and are limited to the sandbox roots; bare words
+// also match tool names so prompts like "use Gr<tab>" complete to Grep.
+func tabCompletionCandidates(token string, cwd string, sandbox *tools.Sandbox, toolNames []string) []tuiSlashSuggestion {
+	if token == "" {
+		return nil
+	}
+	var candidates []tuiSlashSuggestion
+	dirPart, prefix := "", token
+	if index := strings.LastIndex(token, "/"); index >= 0 {
+		dirPart, prefix = token[:index+1], token[index+1:]
+	}
+	dir := cwd
+	if dirPart != "" {
+		dir = resolvePromptPath(dirPart, cwd)
+	}
+	if entries, err := os.ReadDir(dir); err == nil {
+		lowerPrefix := strings.ToLower(prefix)
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+				continue
+			}
+			if !strings.HasPrefix(strings.ToLower(name), lowerPrefix) {
+				continue
+			}
+			if !completionAllowed(sandbox, filepath.Join(dir, name)) {
+				continue
+			}
+			completion := dirPart + name
+			description := "file"
+			if entry.IsDir() {
+				completion += "/"
+				description = "directory"
+			}
+			candidates = append(candidates, tuiSlashSuggestion{Name: completion, Description: description, Completion: true})
+		}
+	}
+	if dirPart == "" {
+		sortedTools := append([]string(nil), toolNames...)
+		sort.Strings(sortedTools)
+		lowerToken := strings.ToLower(token)
+		for _, name := range sortedTools {
+			if strings.HasPrefix(strings.ToLower(name), lowerToken) {
+				candidates = append(candidates, tuiSlashSuggestion{Name: name, Description: "tool", Completion: true})
+			}
+		}
+	}
+	return candidates
+}
+
+// completionAllowed reports whether a path is inside the sandbox roots or on the way to one.
+// Ancestors of a root stay completable so absolute paths can be typed from "/".
+func completionAllowed(sandbox *tools.Sandbox, path string) bool {
+	if sandbox == nil {
+		return true
+	}
+	if _, err := sandbox.ResolvePath(path, false); err == nil {
+		return true
+	}
+	for _, root := range sandbox.Roots {
+		rootAbs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if relative, err := filepath.Rel(path, rootAbs); err == nil && !strings.HasPrefix(relative, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// commonCompletionPrefix returns the longest prefix shared by every candidate name.
+func commonCompletionPrefix(candidates []tuiSlashSuggestion) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	prefix := candidates[0].Name
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate.Name, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// trailingInputToken returns the text after the last whitespace in the input.
+func trailingInputToken(inputValue string) string {
+	return inputValue[strings.LastIndexAny(inputValue, " \t\n")+1:]
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestTabCompletionCandidatesRespectSandbox verifies completions stay within sandbox roots.
+func TestTabCompletionCandidatesRespectSandbox(testingHandle *testing.T) {
+	parent := testingHandle.TempDir()
+	project := filepath.Join(parent, "project")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(project, "internal"), 0o755), "create project")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(parent, "private"), 0o755), "create sibling")
+	sandbox := tools.NewSandbox([]string{project})
+
+	candidates := tabCompletionCandidates("in", project, sandbox, []string{"Grep", "Read"})
+	testutil.RequireEqual(testingHandle, len(candidates), 1, "one path candidate")
+	testutil.RequireEqual(testingHandle, candidates[0].Name, "internal/", "directory completion")
+
+	testutil.RequireEqual(testingHandle, len(tabCompletionCandidates("../pr", project, sandbox, nil)), 1, "ancestor listing keeps the root")
+	testutil.RequireEqual(testingHandle, tabCompletionCandidates("../pr", project, sandbox, nil)[0].Name, "../project/", "sibling outside sandbox hidden")
+
+	toolCandidates := tabCompletionCandidates("gr", project, sandbox, []string{"Read", "Grep"})
+	testutil.RequireEqual(testingHandle, len(toolCandidates), 1, "tool candidate")
+	testutil.RequireEqual(testingHandle, toolCandidates[0].Name, "Grep", "tool name completion")
+}
+
+// TestTUITabCompletesPaths verifies Tab completes a unique match and lists ambiguous ones.
+func TestTUITabCompletesPaths(testingHandle *testing.T) {
+	dir := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(dir, "cmd"), 0o755), "create cmd")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "cmd", "main.go"), nil, 0o600), "write main")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(dir, "cmd", "main_test.go"), nil, 0o600), "write main_test")
+
+	runner := &agent.Runner{ToolContext: tools.ToolContext{CWD: dir, Sandbox: tools.NewSandbox([]string{dir})}}
+	model := newTUIModel(&options{}, runner, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	model.input.SetValue("look at cm")
+	model.input.CursorEnd()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	testutil.RequireEqual(testingHandle, model.input.Value(), "look at cmd/", "unique directory completed")
+
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	testutil.RequireEqual(testingHandle, model.input.Value(), "look at cmd/main", "common prefix inserted")
+	testutil.RequireEqual(testingHandle, len(model.slashSuggestions), 2, "ambiguous matches listed")
+
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	testutil.RequireEqual(testingHandle, model.input.Value(), "look at cmd/main.go ", "selected completion applied")
+	testutil.RequireEqual(testingHandle, model.activePane, "input", "tab did not cycle panes")
+}
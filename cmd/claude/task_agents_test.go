@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestResolveTaskAgent verifies subagent_type lookup against custom and built-in agents.
+func TestResolveTaskAgent(testingHandle *testing.T) {
+	opts := &options{AgentDefinitions: []config.AgentDefinition{{Name: "reviewer", Prompt: "Review."}}}
+
+	definition, ok, err := resolveTaskAgent("reviewer", opts)
+	testutil.RequireNoError(testingHandle, err, "resolve custom agent")
+	testutil.RequireEqual(testingHandle, ok, true, "custom agent found")
+	testutil.RequireEqual(testingHandle, definition.Prompt, "Review.", "custom agent prompt")
+
+	_, ok, err = resolveTaskAgent("general-purpose", opts)
+	testutil.RequireNoError(testingHandle, err, "resolve built-in agent")
+	testutil.RequireEqual(testingHandle, ok, false, "built-in agent has no definition")
+
+	if _, _, err := resolveTaskAgent("missing", opts); err == nil {
+		testingHandle.Fatalf("expected error for unknown subagent_type")
+	}
+}
+
+// TestRestrictToolRunner verifies agent tool lists filter the parent runner.
+func TestRestrictToolRunner(testingHandle *testing.T) {
+	base := tools.NewRunner([]tools.Tool{&tools.ReadTool{}, &tools.GlobTool{}, &tools.BashTool{}})
+
+	restricted := restrictToolRunner(base, []string{"read", "Glob", "Unknown"})
+
+	testutil.RequireEqual(testingHandle, restricted.Order, []string{"Read", "Glob"}, "restricted tool order")
+}
+
+// TestListAgentNamesIncludesDefinitions verifies custom agents are appended to the init list.
+func TestListAgentNamesIncludesDefinitions(testingHandle *testing.T) {
+	opts := &options{AgentDefinitions: []config.AgentDefinition{{Name: "reviewer"}, {Name: "Plan"}}}
+
+	names := listAgentNames(opts)
+
+	want := stringsToAny(append(defaultAgentList(), "reviewer"))
+	testutil.RequireEqual(testingHandle, names, want, "agent names")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/telemetry"
+)
+
+// telemetryShutdownTimeout bounds the final export on exit.
+const telemetryShutdownTimeout = 5 * time.Second
+
+// settingsEnvLookup resolves variables from the settings env block first,
+// then the process environment.
+func settingsEnvLookup(settings *config.Settings) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		if settings != nil {
+			if value, ok := settings.Env[name]; ok {
+				return value, true
+			}
+		}
+		return os.LookupEnv(name)
+	}
+}
+
+// startTelemetry starts the OTLP exporter when CLAUDE_CODE_ENABLE_TELEMETRY is
+// set. The returned stop function flushes pending data; it is a no-op when
+// telemetry is off. Misconfiguration only warns so it never blocks a session.
+func startTelemetry(settings *config.Settings, sessionID string) (*telemetry.Exporter, func()) {
+	cfg, enabled, err := telemetry.ConfigFromEnv(settingsEnvLookup(settings))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: telemetry disabled: %v\n", err)
+		return nil, func() {}
+	}
+	if !enabled {
+		return nil, func() {}
+	}
+	cfg.ServiceVersion = version
+	// Console payloads go to stderr so they never mix with print-mode output.
+	exporter := telemetry.NewExporter(cfg, sessionID, nil, os.Stderr)
+	exporter.RecordSession()
+	exporter.Start()
+	return exporter, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryShutdownTimeout)
+		defer cancel()
+		if err := exporter.Shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: telemetry export failed: %v\n", err)
+		}
+	}
+}
+
+// startLedger returns the local cost ledger recorder, or nil when the provider
+// config disables it.
+func startLedger(providerCfg *config.ProviderConfig, store *session.Store, sessionID string) *telemetry.Ledger {
+	if providerCfg.DisableCostLedger || store == nil {
+		return nil
+	}
+	return telemetry.NewLedger(filepath.Join(store.BaseDir, telemetry.LedgerFileName), sessionID, os.Stderr)
+}
+
+// usageRecorders fans usage out to several recorders, such as the cost ledger
+// and the OTLP exporter.
+type usageRecorders []agent.UsageRecorder
+
+// RecordUserPrompt forwards the prompt to every recorder.
+func (r usageRecorders) RecordUserPrompt(prompt string) {
+	for _, recorder := range r {
+		recorder.RecordUserPrompt(prompt)
+	}
+}
+
+// RecordAPIRequest forwards the request to every recorder.
+func (r usageRecorders) RecordAPIRequest(model string, usage llm.Usage, costUSD float64, duration time.Duration) {
+	for _, recorder := range r {
+		recorder.RecordAPIRequest(model, usage, costUSD, duration)
+	}
+}
+
+// RecordAPIError forwards the failure to every recorder.
+func (r usageRecorders) RecordAPIError(model string, err error, duration time.Duration) {
+	for _, recorder := range r {
+		recorder.RecordAPIError(model, err, duration)
+	}
+}
+
+// RecordToolResult forwards the tool call to every recorder.
+func (r usageRecorders) RecordToolResult(toolName string, success bool, duration time.Duration) {
+	for _, recorder := range r {
+		recorder.RecordToolResult(toolName, success, duration)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/openclaude/openclaude/internal/config"
+)
+
+// tuiThemeNames lists the themes in /theme order. auto follows the terminal
+// background; custom is auto with the settings' themeColors on top.
+var tuiThemeNames = []string{"auto", "dark", "light", "ansi", "custom"}
+
+// tuiThemeColor names one theme color for settings and previews.
+type tuiThemeColor struct {
+	// Name is the themeColors key.
+	Name string
+	// Color points into the theme.
+	Color *lipgloss.AdaptiveColor
+}
+
+// colors returns the theme's colors by themeColors key.
+func (t *tuiTheme) colors() []tuiThemeColor {
+	return []tuiThemeColor{
+		{"text", &t.Text},
+		{"secondary", &t.Secondary},
+		{"secondaryBorder", &t.SecondaryBorder},
+		{"bash", &t.Bash},
+		{"claude", &t.Claude},
+		{"permission", &t.Permission},
+		{"error", &t.Error},
+		{"success", &t.Success},
+		{"warning", &t.Warning},
+		{"suggestion", &t.Suggestion},
+	}
+}
+
+// resolveTUITheme builds a theme by name; "" is auto. colors applies only to
+// the custom theme and must use known color names.
+func resolveTUITheme(name string, colors map[string]string) (tuiTheme, error) {
+	theme := defaultTUITheme()
+	switch strings.ToLower(name) {
+	case "", "auto":
+	case "dark", "light":
+		dark := strings.EqualFold(name, "dark")
+		for _, color := range theme.colors() {
+			value := color.Color.Light
+			if dark {
+				value = color.Color.Dark
+			}
+			*color.Color = fixedColor(value)
+		}
+	case "ansi":
+		// The terminal's own palette: default text plus the basic ANSI colors.
+		theme = tuiTheme{
+			Secondary:       fixedColor("8"),
+			SecondaryBorder: fixedColor("8"),
+			Bash:            fixedColor("5"),
+			Claude:          fixedColor("3"),
+			Permission:      fixedColor("4"),
+			Error:           fixedColor("1"),
+			Success:         fixedColor("2"),
+			Warning:         fixedColor("3"),
+			Suggestion:      fixedColor("4"),
+		}
+	case "custom":
+		known := theme.colors()
+		var unknown []string
+		for key, value := range colors {
+			index := slices.IndexFunc(known, func(color tuiThemeColor) bool { return color.Name == key })
+			if index < 0 {
+				unknown = append(unknown, key)
+				continue
+			}
+			*known[index].Color = fixedColor(value)
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return defaultTUITheme(), fmt.Errorf("unknown themeColors %s (use %s)", strings.Join(unknown, ", "), strings.Join(themeColorNames(), ", "))
+		}
+	default:
+		return defaultTUITheme(), fmt.Errorf("unknown theme %q (use %s)", name, strings.Join(tuiThemeNames, ", "))
+	}
+	return theme, nil
+}
+
+// fixedColor is a color that ignores the terminal background.
+func fixedColor(value string) lipgloss.AdaptiveColor {
+	return lipgloss.AdaptiveColor{Light: value, Dark: value}
+}
+
+// themeColorNames lists the themeColors keys.
+func themeColorNames() []string {
+	var theme tuiTheme
+	var names []string
+	for _, color := range theme.colors() {
+		names = append(names, color.Name)
+	}
+	return names
+}
+
+// themeCodeStyle returns the code style matching a theme: dark and light
+// force the background, and ansi limits code to the basic colors.
+func themeCodeStyle(name string) codeStyle {
+	profile := lipgloss.ColorProfile()
+	switch strings.ToLower(name) {
+	case "dark":
+		return newCodeStyle(profile, true)
+	case "light":
+		return newCodeStyle(profile, false)
+	case "ansi":
+		return newCodeStyle(max(profile, termenv.ANSI), lipgloss.HasDarkBackground())
+	}
+	return detectCodeStyle()
+}
+
+// settingsTheme returns the theme and palette from the merged settings.
+func settingsTheme(opts *options) (string, map[string]string) {
+	if opts == nil || opts.ClaudeSettings == nil {
+		return "", nil
+	}
+	return opts.ClaudeSettings.Theme, opts.ClaudeSettings.ThemeColors
+}
+
+// applyTheme switches the chat colors, code highlighting, and markdown
+// styles to a theme and re-renders the transcript. An invalid theme leaves
+// the current one in place.
+func (m *tuiModel) applyTheme(name string, colors map[string]string) error {
+	theme, err := resolveTUITheme(name, colors)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = "auto"
+	}
+	m.theme = theme
+	m.themeName = strings.ToLower(name)
+	m.codeStyle = themeCodeStyle(m.themeName)
+	plain := m.markdownRenderer != nil && m.markdownRenderer.plain
+	m.markdownRenderer = newMarkdownRenderer(m.codeStyle)
+	m.markdownRenderer.SetPlain(plain)
+	m.invalidateRenderCache()
+	return nil
+}
+
+// switchTheme handles /theme: with no arguments it previews every theme,
+// otherwise it switches to one and saves it to the user settings.
+func (m *tuiModel) switchTheme(args string) string {
+	_, colors := settingsTheme(m.opts)
+	name := strings.ToLower(strings.TrimSpace(args))
+	if name == "" {
+		return m.formatThemePreview(colors)
+	}
+	if err := m.applyTheme(name, colors); err != nil {
+		return fmt.Sprintf("Theme unchanged: %v.", err)
+	}
+	m.refreshChat()
+	path, err := saveUserTheme(m.themeName)
+	if err != nil {
+		return fmt.Sprintf("Switched to the %s theme for this session; saving it failed: %v", m.themeName, err)
+	}
+	return fmt.Sprintf("Switched to the %s theme and saved it to %s.", m.themeName, path)
+}
+
+// formatThemePreview shows each theme's colors as sample words, marking the
+// current theme.
+func (m *tuiModel) formatThemePreview(colors map[string]string) string {
+	var builder strings.Builder
+	builder.WriteString("Themes:")
+	for _, name := range tuiThemeNames {
+		marker := " "
+		if name == m.themeName {
+			marker = "›"
+		}
+		fmt.Fprintf(&builder, "\n%s %-7s", marker, name)
+		theme, err := resolveTUITheme(name, colors)
+		if err != nil {
+			fmt.Fprintf(&builder, " %v", err)
+			continue
+		}
+		for _, color := range theme.colors() {
+			if color.Name == "secondaryBorder" || color.Name == "suggestion" {
+				// They share their look with secondary and permission.
+				continue
+			}
+			builder.WriteString(" " + lipgloss.NewStyle().Foreground(*color.Color).Render(color.Name))
+		}
+		if name == "custom" && len(colors) == 0 {
+			builder.WriteString(" (set themeColors in settings)")
+		}
+	}
+	builder.WriteString("\n/theme <name> switches themes and saves the choice to your user settings.")
+	return builder.String()
+}
+
+// saveUserTheme stores the theme in the user settings file and returns its path.
+func saveUserTheme(name string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("resolve cwd: %w", err)
+	}
+	path, err := config.SettingsPath(cwd, "user")
+	if err != nil {
+		return "", err
+	}
+	data, err := config.ReadSettingsMap(path)
+	if err != nil {
+		return "", err
+	}
+	if err := config.SetSetting(data, "theme", name); err != nil {
+		return "", err
+	}
+	return path, config.WriteSettingsMap(path, data)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestResolveTUITheme verifies the built-in themes and the custom palette.
+func TestResolveTUITheme(testingHandle *testing.T) {
+	auto, err := resolveTUITheme("", nil)
+	testutil.RequireNoError(testingHandle, err, "auto theme")
+	testutil.RequireEqual(testingHandle, auto, defaultTUITheme(), "auto is the adaptive theme")
+
+	dark, err := resolveTUITheme("dark", nil)
+	testutil.RequireNoError(testingHandle, err, "dark theme")
+	testutil.RequireEqual(testingHandle, dark.Text, lipgloss.AdaptiveColor{Light: "#ffffff", Dark: "#ffffff"}, "dark ignores the background")
+	light, err := resolveTUITheme("Light", nil)
+	testutil.RequireNoError(testingHandle, err, "light theme")
+	testutil.RequireEqual(testingHandle, light.Error, fixedColor(defaultTUITheme().Error.Light), "light colors")
+	ansi, err := resolveTUITheme("ansi", nil)
+	testutil.RequireNoError(testingHandle, err, "ansi theme")
+	testutil.RequireEqual(testingHandle, ansi.Text, lipgloss.AdaptiveColor{}, "ansi keeps the terminal's text color")
+	testutil.RequireEqual(testingHandle, ansi.Error, fixedColor("1"), "ansi red")
+
+	custom, err := resolveTUITheme("custom", map[string]string{"claude": "#ff8800"})
+	testutil.RequireNoError(testingHandle, err, "custom theme")
+	testutil.RequireEqual(testingHandle, custom.Claude, fixedColor("#ff8800"), "custom color")
+	testutil.RequireEqual(testingHandle, custom.Text, defaultTUITheme().Text, "unset colors stay adaptive")
+
+	_, err = resolveTUITheme("solarized", nil)
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "auto, dark, light, ansi, custom"), "unknown theme")
+	_, err = resolveTUITheme("custom", map[string]string{"accent": "#fff"})
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "unknown themeColors accent"), "unknown color")
+}
+
+// TestSwitchTheme verifies /theme previews themes, switches, and saves the
+// choice to the user settings without touching other settings.
+func TestSwitchTheme(testingHandle *testing.T) {
+	// Arrange
+	home := testingHandle.TempDir()
+	testingHandle.Setenv("HOME", home)
+	testingHandle.Chdir(testingHandle.TempDir())
+	settingsPath := filepath.Join(home, ".claude", "settings.json")
+	testutil.RequireNoError(testingHandle, config.WriteSettingsMap(settingsPath, map[string]any{"model": "sonnet"}), "seed user settings")
+	opts := &options{ClaudeSettings: &config.Settings{Theme: "light", ThemeColors: map[string]string{"text": "#123456"}}}
+	model := newTUIModel(opts, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	testutil.RequireEqual(testingHandle, model.themeName, "light", "theme from settings")
+
+	// Act
+	preview := model.switchTheme("")
+	switched := model.switchTheme("custom")
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.Contains(preview, "› light "), preview)
+	testutil.RequireTrue(testingHandle, strings.Contains(preview, "  ansi    text secondary bash claude"), preview)
+	testutil.RequireEqual(testingHandle, switched, "Switched to the custom theme and saved it to "+settingsPath+".", "switch message")
+	testutil.RequireEqual(testingHandle, model.theme.Text, fixedColor("#123456"), "custom palette applied")
+	saved, err := config.ReadSettingsMap(settingsPath)
+	testutil.RequireNoError(testingHandle, err, "read user settings")
+	testutil.RequireEqual(testingHandle, saved, map[string]any{"model": "sonnet", "theme": "custom"}, "saved theme")
+
+	unknown := model.switchTheme("neon")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(unknown, `Theme unchanged: unknown theme "neon"`), unknown)
+	testutil.RequireEqual(testingHandle, model.themeName, "custom", "theme kept after an unknown name")
+}
+
+// TestInvalidThemeSettingsFallBack verifies a bad theme setting falls back
+// to auto with a notice instead of failing startup.
+func TestInvalidThemeSettingsFallBack(testingHandle *testing.T) {
+	opts := &options{ClaudeSettings: &config.Settings{Theme: "neon"}}
+	model := newTUIModel(opts, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	testutil.RequireEqual(testingHandle, model.themeName, "auto", "fallback theme")
+	last := model.chatMessages[len(model.chatMessages)-1]
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(last.Content, "Theme settings ignored: unknown theme"), last.Content)
+}
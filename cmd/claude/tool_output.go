@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/openclaude/openclaude/internal/session"
+)
+
+// tuiCollapsedLines is how many lines of each tool output stream a collapsed
+// tool result shows.
+const tuiCollapsedLines = 10
+
+// splitToolResult returns a tool result's output and, when the output still
+// ends with it, the stderr shown after it.
+func splitToolResult(message tuiMessage) (string, string) {
+	content := strings.TrimSpace(message.Content)
+	stderr := strings.TrimSpace(message.Stderr)
+	if stderr != "" && strings.HasSuffix(content, stderr) {
+		return strings.TrimSpace(strings.TrimSuffix(content, stderr)), stderr
+	}
+	// Truncated output no longer ends with stderr; show it as it is.
+	return content, ""
+}
+
+// collapseOutputLines keeps the first maxLines lines of output and returns
+// how many it hid.
+func collapseOutputLines(content string, maxLines int) (string, int) {
+	if content == "" {
+		return "", 0
+	}
+	lines := strings.Split(content, "\n")
+	if len(lines) <= maxLines {
+		return content, 0
+	}
+	return strings.Join(lines[:maxLines], "\n"), len(lines) - maxLines
+}
+
+// collapsedToolLines returns how many lines a tool result hides while collapsed.
+func collapsedToolLines(message tuiMessage) int {
+	if message.Kind != tuiMessageToolResult {
+		return 0
+	}
+	content, stderr := splitToolResult(message)
+	_, hiddenContent := collapseOutputLines(content, tuiCollapsedLines)
+	_, hiddenStderr := collapseOutputLines(stderr, tuiCollapsedLines)
+	return hiddenContent + hiddenStderr
+}
+
+// isExpandableMessage reports whether ctrl+x or a click toggles a message:
+// tool results too long to show in full while collapsed.
+func isExpandableMessage(message tuiMessage) bool {
+	return collapsedToolLines(message) > 0
+}
+
+// renderCollapsedHint renders the line that ends a collapsed tool result.
+func (m *tuiModel) renderCollapsedHint(hidden int) string {
+	hint := fmt.Sprintf("    … +%d lines (ctrl+x to expand)", hidden)
+	return lipgloss.NewStyle().Foreground(m.theme.Secondary).Render(hint)
+}
+
+// toggleToolOutput handles ctrl+x: it expands or collapses the newest long
+// tool result that starts above the bottom of the chat, so scrolling up
+// first picks an earlier one.
+func (m *tuiModel) toggleToolOutput() {
+	index := m.toolOutputTarget()
+	if index < 0 {
+		m.inputHint = "No long tool output to expand"
+		return
+	}
+	m.chatMessages[index].Expanded = !m.chatMessages[index].Expanded
+	if m.chatMessages[index].Expanded {
+		m.inputHint = "Tool output expanded · ctrl+x to collapse"
+	} else {
+		m.inputHint = "Tool output collapsed · ctrl+x to expand"
+	}
+	m.refreshChat()
+}
+
+// toolOutputTarget returns the chat message ctrl+x toggles, or -1 when no
+// tool result is long enough to collapse.
+func (m *tuiModel) toolOutputTarget() int {
+	bottom := m.chatView.YOffset + m.chatView.Height
+	fallback := -1
+	for index := len(m.chatMessages) - 1; index >= 0; index-- {
+		if !isExpandableMessage(m.chatMessages[index]) {
+			continue
+		}
+		if index >= len(m.chatMessageLines) || m.chatMessageLines[index] < bottom {
+			return index
+		}
+		if fallback < 0 {
+			fallback = index
+		}
+	}
+	return fallback
+}
+
+// setVerbose handles /verbose [on|off], which like ctrl+o switches between
+// condensed and full tool inputs and outputs for subsequent tool messages and
+// saves the choice with the session.
+func (m *tuiModel) setVerbose(args string) string {
+	verbose := !m.verbose
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+	case "on":
+		verbose = true
+	case "off":
+		verbose = false
+	default:
+		return "Usage: /verbose [on|off]"
+	}
+	m.verbose = verbose
+	if m.store != nil {
+		if err := m.store.SaveUIState(m.sessionID, session.UIState{Verbose: m.verbose}); err != nil {
+			return fmt.Sprintf("Verbose tool output %s for this session; saving it failed: %v", onOff(m.verbose), err)
+		}
+	}
+	if m.verbose {
+		return "Verbose tool output on: new tool messages show full inputs and outputs."
+	}
+	return "Verbose tool output off: new tool results start collapsed."
+}
+
+// onOff names a switch state.
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestToolResultStartsCollapsed verifies long tool results show their first
+// lines and a hint, and stderr is collapsed with them.
+func TestToolResultStartsCollapsed(testingHandle *testing.T) {
+	// Arrange
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", nil)
+	model.width = 80
+	var stdout, stderr []string
+	for index := 0; index < tuiCollapsedLines+4; index++ {
+		stdout = append(stdout, fmt.Sprintf("out %d", index))
+		stderr = append(stderr, fmt.Sprintf("err %d", index))
+	}
+	content := strings.Join(stdout, "\n") + "\n" + strings.Join(stderr, "\n")
+	message := tuiMessage{Kind: tuiMessageToolResult, Content: content, Stderr: strings.Join(stderr, "\n")}
+
+	// Act
+	collapsed := model.renderToolResultMessage(message)
+	message.Expanded = true
+	expanded := model.renderToolResultMessage(message)
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.Contains(collapsed, fmt.Sprintf("out %d", tuiCollapsedLines-1)), "first lines shown")
+	testutil.RequireTrue(testingHandle, !strings.Contains(collapsed, fmt.Sprintf("out %d", tuiCollapsedLines)), "later lines hidden")
+	testutil.RequireTrue(testingHandle, !strings.Contains(collapsed, fmt.Sprintf("err %d", tuiCollapsedLines)), "later stderr hidden")
+	testutil.RequireTrue(testingHandle, strings.Contains(collapsed, "… +8 lines (ctrl+x to expand)"), "hint counts both streams")
+	testutil.RequireTrue(testingHandle, strings.Contains(expanded, fmt.Sprintf("err %d", tuiCollapsedLines+3)), "expanded in full")
+	testutil.RequireTrue(testingHandle, !strings.Contains(expanded, "ctrl+x"), "no hint when expanded")
+	testutil.RequireTrue(testingHandle, !isExpandableMessage(tuiMessage{Kind: tuiMessageToolResult, Content: "short"}), "short results do not collapse")
+}
+
+// TestCtrlXTogglesToolOutputInView verifies ctrl+x toggles the newest long
+// tool result above the bottom of the chat.
+func TestCtrlXTogglesToolOutputInView(testingHandle *testing.T) {
+	// Arrange
+	model := newMouseTestModel(testingHandle)
+	last := len(model.chatMessages) - 1
+	model.appendToolResultMessage(agent.ToolEvent{Type: "tool_result", ToolName: "Read", ToolID: "call_2", Result: model.chatMessages[last].Content})
+	model.refreshChat()
+	ctrlX := tea.KeyMsg{Type: tea.KeyCtrlX}
+
+	// Act
+	model.Update(ctrlX)
+	newestExpanded := model.chatMessages[last+1].Expanded
+	model.Update(ctrlX)
+	model.chatAutoScroll = false
+	model.chatView.SetYOffset(model.chatMessageLines[last+1] - model.chatView.Height)
+	model.Update(ctrlX)
+
+	// Assert
+	testutil.RequireTrue(testingHandle, newestExpanded, "newest result expanded")
+	testutil.RequireTrue(testingHandle, !model.chatMessages[last+1].Expanded, "newest result collapsed again")
+	testutil.RequireTrue(testingHandle, model.chatMessages[last].Expanded, "scrolling up targets the earlier result")
+}
+
+// TestSetVerbose verifies /verbose switches and saves verbose mode.
+func TestSetVerbose(testingHandle *testing.T) {
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	model := newTUIModel(&options{}, nil, nil, sessionHistoryCursor{}, "sys", "model", "sid", store)
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(model.setVerbose(""), "Verbose tool output on"), "toggle on")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(model.setVerbose("on"), "Verbose tool output on"), "explicit on")
+	testutil.RequireEqual(testingHandle, model.setVerbose("loud"), "Usage: /verbose [on|off]", "usage")
+	testutil.RequireTrue(testingHandle, model.verbose, "still verbose after bad usage")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(model.setVerbose("off"), "Verbose tool output off"), "explicit off")
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// tuiTranscript is the Ctrl+R overlay showing the raw conversation.
+// It renders a snapshot of the history taken when the overlay opens.
+type tuiTranscript struct {
+	// text is the unwrapped transcript.
+	text string
+	// lines holds text wrapped to the current width.
+	lines []string
+	// view scrolls the wrapped lines.
+	view viewport.Model
+	// query is the active search string.
+	query string
+	// searching reports whether keys edit the query instead of scrolling.
+	searching bool
+	// matches lists wrapped line indices containing the query.
+	matches []int
+	// matchIndex indexes the focused entry in matches.
+	matchIndex int
+}
+
+// formatTranscript renders messages without truncation: the system prompt,
+// user and assistant text, thinking, full tool arguments, and tool results.
+// Pending streamed thinking and text are appended for in-flight responses.
+func formatTranscript(history []llm.Message, pendingThinking string, pendingText string) string {
+	var blocks []string
+	for _, message := range history {
+		var block strings.Builder
+		switch message.Role {
+		case "tool":
+			fmt.Fprintf(&block, "── tool result (%s) ──", message.ToolCallID)
+		default:
+			fmt.Fprintf(&block, "── %s ──", message.Role)
+		}
+		if message.Reasoning != "" {
+			block.WriteString("\n[thinking]\n" + strings.TrimRight(message.Reasoning, "\n") + "\n[/thinking]")
+		}
+		if text := llm.ContentText(message.Content); text != "" {
+			block.WriteString("\n" + strings.TrimRight(text, "\n"))
+		}
+		for _, call := range message.ToolCalls {
+			fmt.Fprintf(&block, "\n[tool call %s (%s)]\n%s", call.Function.Name, call.ID, indentToolArguments(call.Function.Arguments))
+		}
+		blocks = append(blocks, block.String())
+	}
+	if pendingThinking != "" || pendingText != "" {
+		block := "── assistant (streaming) ──"
+		if pendingThinking != "" {
+			block += "\n[thinking]\n" + strings.TrimRight(pendingThinking, "\n") + "\n[/thinking]"
+		}
+		if pendingText != "" {
+			block += "\n" + strings.TrimRight(pendingText, "\n")
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return "(empty conversation)"
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// indentToolArguments pretty-prints JSON tool arguments, leaving other text as is.
+func indentToolArguments(arguments string) string {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(arguments), "", "  "); err != nil {
+		return arguments
+	}
+	return indented.String()
+}
+
+// openTranscript shows the transcript overlay scrolled to the newest message.
+func (m *tuiModel) openTranscript() {
+	history, notice := m.transcriptHistory()
+	text := formatTranscript(history, m.thinkingBuffer.String(), m.streamBuffer.String())
+	if notice != "" {
+		text = notice + "\n\n" + text
+	}
+	m.transcript = &tuiTranscript{
+		text: text,
+		view: viewport.New(maxInt(20, m.width), 10),
+	}
+	m.layoutTranscript()
+	m.transcript.view.GotoBottom()
+}
+
+// transcriptHistory returns the whole conversation for the transcript: the
+// loaded history preceded by the older pages of a resumed session still on
+// disk. Those pages are read for display only and stay out of the model's
+// context. When they cannot be read, the notice says older messages are missing.
+func (m *tuiModel) transcriptHistory() ([]llm.Message, string) {
+	history := m.history
+	cursor := m.historyCursor
+	if !cursor.HasMore {
+		return history, ""
+	}
+	if m.store == nil {
+		return history, "(older messages not loaded: session persistence is off)"
+	}
+	for cursor.HasMore {
+		older, next, err := loadOlderSessionMessages(m.store, cursor, sessionHistoryPageSize)
+		if err != nil {
+			return history, fmt.Sprintf("(older messages not loaded: %v)", err)
+		}
+		history = mergeOlderHistory(history, older)
+		cursor = next
+	}
+	return history, ""
+}
+
+// closeTranscript hides the transcript overlay.
+func (m *tuiModel) closeTranscript() {
+	m.transcript = nil
+}
+
+// layoutTranscript wraps the transcript to the window and re-renders the viewport.
+func (m *tuiModel) layoutTranscript() {
+	transcript := m.transcript
+	if transcript == nil {
+		return
+	}
+	width := maxInt(20, m.width)
+	transcript.view.Width = width
+	// Reserve one row each for the header and footer.
+	transcript.view.Height = maxInt(4, m.height-2)
+	wrapper := lipgloss.NewStyle().Width(width)
+	transcript.lines = transcript.lines[:0]
+	for _, line := range strings.Split(transcript.text, "\n") {
+		transcript.lines = append(transcript.lines, strings.Split(wrapper.Render(line), "\n")...)
+	}
+	m.searchTranscript()
+}
+
+// searchTranscript recomputes matches for the query and highlights them.
+func (m *tuiModel) searchTranscript() {
+	transcript := m.transcript
+	transcript.matches = nil
+	query := strings.ToLower(transcript.query)
+	if query != "" {
+		for index, line := range transcript.lines {
+			if strings.Contains(strings.ToLower(line), query) {
+				transcript.matches = append(transcript.matches, index)
+			}
+		}
+	}
+	if transcript.matchIndex >= len(transcript.matches) {
+		transcript.matchIndex = 0
+	}
+	m.renderTranscriptLines()
+}
+
+// renderTranscriptLines sets the viewport content, highlighting matched lines.
+func (m *tuiModel) renderTranscriptLines() {
+	transcript := m.transcript
+	matchStyle := lipgloss.NewStyle().Foreground(m.theme.Suggestion)
+	currentStyle := matchStyle.Reverse(true)
+	rendered := append([]string(nil), transcript.lines...)
+	for position, index := range transcript.matches {
+		if position == transcript.matchIndex {
+			rendered[index] = currentStyle.Render(rendered[index])
+		} else {
+			rendered[index] = matchStyle.Render(rendered[index])
+		}
+	}
+	transcript.view.SetContent(strings.Join(rendered, "\n"))
+}
+
+// jumpTranscriptMatch moves to the next (delta 1) or previous (delta -1) match.
+func (m *tuiModel) jumpTranscriptMatch(delta int) {
+	transcript := m.transcript
+	if len(transcript.matches) == 0 {
+		return
+	}
+	transcript.matchIndex = (transcript.matchIndex + delta + len(transcript.matches)) % len(transcript.matches)
+	m.renderTranscriptLines()
+	// Keep the match a few lines below the top for context.
+	transcript.view.SetYOffset(transcript.matches[transcript.matchIndex] - 3)
+}
+
+// handleTranscriptKey scrolls, searches, or closes the transcript overlay.
+func (m *tuiModel) handleTranscriptKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	transcript := m.transcript
+	if transcript.searching {
+		switch key.Type {
+		case tea.KeyEsc:
+			transcript.searching = false
+			transcript.query = ""
+			m.searchTranscript()
+		case tea.KeyEnter:
+			transcript.searching = false
+			transcript.matchIndex = 0
+			m.jumpTranscriptMatch(0)
+		case tea.KeyBackspace:
+			if runes := []rune(transcript.query); len(runes) > 0 {
+				transcript.query = string(runes[:len(runes)-1])
+				m.searchTranscript()
+			}
+		case tea.KeySpace:
+			transcript.query += " "
+			m.searchTranscript()
+		case tea.KeyRunes:
+			transcript.query += string(key.Runes)
+			m.searchTranscript()
+		}
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+r", "esc", "q", "ctrl+c":
+		m.closeTranscript()
+	case "/":
+		transcript.searching = true
+		transcript.query = ""
+		m.searchTranscript()
+	case "n":
+		m.jumpTranscriptMatch(1)
+	case "N":
+		m.jumpTranscriptMatch(-1)
+	case "up", "k":
+		transcript.view.ScrollUp(1)
+	case "down", "j":
+		transcript.view.ScrollDown(1)
+	case "pgup", "b":
+		transcript.view.PageUp()
+	case "pgdown", " ", "f":
+		transcript.view.PageDown()
+	case "home", "g":
+		transcript.view.GotoTop()
+	case "end", "G":
+		transcript.view.GotoBottom()
+	}
+	return m, nil
+}
+
+// renderTranscript draws the full-screen transcript with a header and footer.
+func (m *tuiModel) renderTranscript() string {
+	transcript := m.transcript
+	titleStyle := lipgloss.NewStyle().Foreground(m.theme.Text).Bold(true)
+	secondaryStyle := lipgloss.NewStyle().Foreground(m.theme.Secondary)
+
+	header := titleStyle.Render("Transcript") + secondaryStyle.Render(fmt.Sprintf(" · %d messages · %d%%", len(m.history), int(transcript.view.ScrollPercent()*100)))
+	var footer string
+	switch {
+	case transcript.searching:
+		footer = "/" + transcript.query + "█" + secondaryStyle.Render("  enter to search · esc to cancel")
+	case transcript.query != "" && len(transcript.matches) == 0:
+		footer = secondaryStyle.Render(fmt.Sprintf("No matches for %q · / to search · ctrl+r or esc to close", transcript.query))
+	case transcript.query != "":
+		footer = secondaryStyle.Render(fmt.Sprintf("Match %d/%d for %q · n/N next/previous · / to search · ctrl+r or esc to close", transcript.matchIndex+1, len(transcript.matches), transcript.query))
+	default:
+		footer = secondaryStyle.Render("↑/↓ pgup/pgdown home/end to scroll · / to search · ctrl+r or esc to close")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, transcript.view.View(), footer)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestTranscriptOverlayShowsRawConversation verifies Ctrl+R shows untruncated history and searches it.
+func TestTranscriptOverlayShowsRawConversation(testingHandle *testing.T) {
+	longOutput := strings.Repeat("x", 5000) + " tail-marker"
+	history := []llm.Message{
+		{Role: "user", Content: "list files"},
+		{Role: "assistant", Reasoning: "need ls", ToolCalls: []llm.ToolCall{{ID: "call_1", Type: "function", Function: llm.ToolCallFunction{Name: "Bash", Arguments: `{"command":"ls"}`}}}},
+		{Role: "tool", ToolCallID: "call_1", Content: longOutput},
+		{Role: "assistant", Content: "done"},
+	}
+	model := newTUIModel(&options{}, nil, history, sessionHistoryCursor{}, "system prompt text", "model", "session", nil)
+	model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	testutil.RequireTrue(testingHandle, model.transcript != nil, "ctrl+r opens the transcript")
+	text := model.transcript.text
+	for _, want := range []string{"system prompt text", "[thinking]\nneed ls", `"command": "ls"`, "tail-marker", "── tool result (call_1) ──"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(text, want), "transcript contains "+want)
+	}
+	testutil.RequireTrue(testingHandle, strings.Contains(model.View(), "Transcript"), "overlay replaces the chat view")
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("TAIL-")})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	testutil.RequireEqual(testingHandle, len(model.transcript.matches), 1, "search is case-insensitive")
+	testutil.RequireTrue(testingHandle, strings.Contains(model.View(), "Match 1/1"), "footer reports the match")
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	testutil.RequireTrue(testingHandle, model.transcript == nil, "esc closes the transcript")
+}
+
+// TestTranscriptIncludesUnloadedHistory verifies the transcript of a resumed
+// session shows the messages still on disk without adding them to the
+// model's context, and says so when they cannot be read.
+func TestTranscriptIncludesUnloadedHistory(testingHandle *testing.T) {
+	// Arrange a resumed session whose first exchange is still on disk only.
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	stored := []llm.Message{
+		{Role: "user", Content: "first"}, {Role: "assistant", Content: "one"},
+		{Role: "user", Content: "second"}, {Role: "assistant", Content: "two"},
+	}
+	testutil.RequireNoError(testingHandle, persistSession(store, "resumed", stored, nil), "persist session")
+	history, cursor, err := loadSessionMessages(store, "resumed", 2)
+	testutil.RequireNoError(testingHandle, err, "load session")
+	model := newTUIModel(&options{}, nil, history, cursor, "sys", "model", "resumed", store)
+	model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	loaded := len(model.history)
+
+	// Act
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	full := model.transcript.text
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model.store = nil
+	model.openTranscript()
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.Index(full, "first") < strings.Index(full, "second"), "older messages shown first: "+full)
+	testutil.RequireTrue(testingHandle, strings.Index(full, "sys") < strings.Index(full, "first"), "system prompt stays on top")
+	testutil.RequireEqual(testingHandle, len(model.history), loaded, "model context unchanged")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(model.transcript.text, "(older messages not loaded"), "notice when older messages are unavailable")
+}
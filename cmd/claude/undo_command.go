@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// undoLastExchange handles /undo: the last prompt and everything after it
+// (the reply, tool calls, and tool results) leave the history, and a tombstone
+// in the session log retracts the stored copies, so a resumed session does
+// not bring them back.
+func (m *tuiModel) undoLastExchange(_ string) string {
+	if m.running {
+		return "Wait for the response to finish, or press Esc to interrupt it, before undoing."
+	}
+	start := lastExchangeStart(m.history)
+	if start < 0 {
+		return "Nothing to undo."
+	}
+	if m.store != nil && start < m.persisted {
+		var stored []llm.Message
+		for _, message := range m.history[start:min(m.persisted, len(m.history))] {
+			if message.Role != "system" {
+				stored = append(stored, message)
+			}
+		}
+		if err := m.store.AppendTombstone(m.sessionID, stored); err != nil {
+			return fmt.Sprintf("Undo failed: %v", err)
+		}
+		m.persisted = start
+	}
+	removed := len(m.history) - start
+	preview, _, _ := selectorTextForMessage(m.history[start])
+	m.history = m.history[:start]
+	m.toolLines = nil
+	m.toolView.SetContent("No tool activity yet.")
+	m.bootstrapHistory()
+	return fmt.Sprintf("Removed the last exchange (%d message(s)): %s", removed, truncateForDisplay(compactWhitespace(strings.TrimSpace(preview)), 80))
+}
+
+// lastExchangeStart returns the index of the last prompt in history, skipping
+// interrupt placeholders, or -1 when there is none.
+func lastExchangeStart(history []llm.Message) int {
+	for index := len(history) - 1; index >= 0; index-- {
+		if history[index].Role == "user" && history[index].Content != tuiInterruptMessage {
+			return index
+		}
+	}
+	return -1
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestUndoLastExchange verifies /undo drops the last prompt with its tool
+// calls and reply, and that a resumed session leaves them out too.
+func TestUndoLastExchange(testingHandle *testing.T) {
+	// Arrange a resumed exchange followed by a finished run that used a tool.
+	testingHandle.Chdir(testingHandle.TempDir())
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	first := []llm.Message{{Role: "user", Content: "list files"}, {Role: "assistant", Content: "a.go"}}
+	testutil.RequireNoError(testingHandle, persistSession(store, "s1", first, nil), "persist first exchange")
+	model := newTUIModel(&options{}, nil, first, sessionHistoryCursor{}, "sys", "model", "s1", store)
+	model.history = append(model.history, llm.Message{Role: "user", Content: "delete a.go"})
+	run := append(append([]llm.Message(nil), model.history...),
+		llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "call_1", Type: "function", Function: llm.ToolCallFunction{Name: "Bash"}}}},
+		llm.Message{Role: "tool", ToolCallID: "call_1", Content: "removed"},
+		llm.Message{Role: "assistant", Content: "Deleted."},
+	)
+	model.finishRun(&agent.RunResult{Messages: run})
+	model.history = append(model.history, llm.Message{Role: "user", Content: tuiInterruptMessage})
+	stored, _, err := loadSessionMessages(store, "s1", 0)
+	testutil.RequireNoError(testingHandle, err, "load stored run")
+	testutil.RequireEqual(testingHandle, len(stored), 6, "run stored with its prompt")
+
+	// Act
+	undone := model.undoLastExchange("")
+
+	// Assert
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(undone, "Removed the last exchange (5 message(s)): delete a.go"), undone)
+	testutil.RequireEqual(testingHandle, model.history, append([]llm.Message{{Role: "system", Content: "sys"}}, first...), "history rewound")
+	resumed, _, err := loadSessionMessages(store, "s1", 0)
+	testutil.RequireNoError(testingHandle, err, "load resumed")
+	testutil.RequireEqual(testingHandle, resumed, first, "stored exchange retracted")
+	metas, err := store.ListSessionMeta(0)
+	testutil.RequireNoError(testingHandle, err, "list sessions")
+	testutil.RequireEqual(testingHandle, metas[0].MessageCount, 2, "index counts")
+
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(model.undoLastExchange(""), "list files"), "first exchange undone")
+	testutil.RequireEqual(testingHandle, model.undoLastExchange(""), "Nothing to undo.", "empty history")
+	metas, err = store.ListSessionMeta(0)
+	testutil.RequireNoError(testingHandle, err, "list sessions")
+	testutil.RequireEqual(testingHandle, metas[0].Title, "", "title of the retracted prompt dropped")
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+)
+
+const (
+	// viewPollInterval is how often the viewer checks the session log for new records.
+	viewPollInterval = 500 * time.Millisecond
+	// viewMaxToolResult caps tool output sent to the browser per message.
+	viewMaxToolResult = 200 << 10
+)
+
+// viewMessage is one conversation entry sent to the browser.
+type viewMessage struct {
+	// Role is user, assistant, or tool.
+	Role string `json:"role"`
+	// Text is the message text; tool results are capped at viewMaxToolResult.
+	Text string `json:"text,omitempty"`
+	// Thinking is the assistant's reasoning text.
+	Thinking string `json:"thinking,omitempty"`
+	// ToolCalls lists tool invocations with pretty-printed arguments.
+	ToolCalls []viewToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID links a tool result to its call.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Images counts attached images, which the viewer does not render.
+	Images int `json:"images,omitempty"`
+}
+
+// viewToolCall is a tool invocation shown in the viewer.
+type viewToolCall struct {
+	// ID is the tool call id.
+	ID string `json:"id"`
+	// Name is the tool name.
+	Name string `json:"name"`
+	// Arguments holds indented JSON arguments.
+	Arguments string `json:"arguments"`
+}
+
+// viewCommand serves a read-only browser view of a session that follows new turns.
+func viewCommand() *cobra.Command {
+	var port int
+	cmd := &cobra.Command{
+		Use:   "view [session-id]",
+		Short: "Mirror a session in a local read-only web page",
+		Long: "Serve a read-only web page on 127.0.0.1 that shows a session's conversation and follows new turns as they are saved.\n" +
+			"Defaults to the most recent session for the current directory.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := session.NewStore()
+			if err != nil {
+				return err
+			}
+			sessionID, err := resolveViewSession(store, args)
+			if err != nil {
+				return err
+			}
+			// Bind loopback only; the page exposes the full conversation.
+			listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+			if err != nil {
+				return fmt.Errorf("listen: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Viewing session %s at http://%s/ (Ctrl+C to stop)\n", sessionID, listener.Addr())
+			server := &http.Server{
+				Handler:           newSessionViewHandler(store, sessionID, viewPollInterval),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				<-cmd.Context().Done()
+				_ = server.Close()
+			}()
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&port, "port", 0, "Port to listen on (0 picks a free port)")
+	return cmd
+}
+
+// resolveViewSession returns the requested session id or the project's last session.
+func resolveViewSession(store *session.Store, args []string) (string, error) {
+	if len(args) == 1 {
+		sessionID := strings.TrimSpace(args[0])
+		if sessionID == "" || filepath.Base(sessionID) != sessionID || strings.HasPrefix(sessionID, ".") {
+			return "", fmt.Errorf("invalid session id %q", args[0])
+		}
+		return sessionID, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get cwd: %w", err)
+	}
+	sessionID, err := store.LoadLastSession(session.ProjectHash(cwd))
+	if err != nil || sessionID == "" {
+		return "", errors.New("no session found for this directory; pass a session id (see claude sessions list)")
+	}
+	return sessionID, nil
+}
+
+// newSessionViewHandler serves the viewer page at / and the event stream at /events.
+func newSessionViewHandler(store *session.Store, sessionID string, pollInterval time.Duration) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/" {
+			http.NotFound(writer, request)
+			return
+		}
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		writer.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'")
+		_, _ = io.WriteString(writer, strings.ReplaceAll(viewPageHTML, "{{SESSION}}", html.EscapeString(sessionID)))
+	})
+	mux.HandleFunc("/events", func(writer http.ResponseWriter, request *http.Request) {
+		streamSessionEvents(writer, request, store.SessionPath(sessionID), pollInterval)
+	})
+	return requireLoopbackHost(mux)
+}
+
+// requireLoopbackHost rejects requests whose Host is not a loopback name, so
+// other sites cannot read the session through DNS rebinding.
+func requireLoopbackHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		host, _, err := net.SplitHostPort(request.Host)
+		if err != nil {
+			host = request.Host
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			http.Error(writer, "forbidden host", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// streamSessionEvents sends stored messages as server-sent events, then polls
+// the session log for appended records until the client disconnects. Event ids
+// are byte offsets, so a reconnecting browser resumes via Last-Event-ID.
+func streamSessionEvents(writer http.ResponseWriter, request *http.Request, path string, pollInterval time.Duration) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		http.Error(writer, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+
+	var offset int64
+	if lastID, err := strconv.ParseInt(request.Header.Get("Last-Event-ID"), 10, 64); err == nil && lastID > 0 {
+		offset = lastID
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if info, err := os.Stat(path); err == nil && info.Size() < offset {
+			// The log was rewritten (e.g., compacted); tell the page to start over.
+			fmt.Fprint(writer, "event: reset\ndata: {}\n\n")
+			offset = 0
+		}
+		messages, next, err := readViewMessages(path, offset)
+		if errors.Is(err, errViewRetracted) {
+			// /undo retracted messages the page already shows; start over.
+			fmt.Fprint(writer, "event: reset\ndata: {}\n\n")
+			offset = 0
+			messages, next, err = readViewMessages(path, offset)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(writer, "event: failure\ndata: %s\n\n", strconv.Quote(err.Error()))
+			flusher.Flush()
+			return
+		}
+		if next != offset {
+			for _, message := range messages {
+				data, _ := json.Marshal(message)
+				fmt.Fprintf(writer, "data: %s\n\n", data)
+			}
+			// Record the offset even when only non-message records were read.
+			fmt.Fprintf(writer, "id: %d\nevent: sync\ndata: {}\n\n", next)
+			flusher.Flush()
+			offset = next
+		}
+		select {
+		case <-request.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// errViewRetracted reports a tombstone retracting messages read before the
+// requested offset, which only a fresh read from the start can leave out.
+var errViewRetracted = errors.New("messages retracted")
+
+// readViewMessages parses complete JSONL records after offset and returns the
+// conversation messages among them plus the offset past the last complete line.
+// Messages retracted by tombstones are left out.
+func readViewMessages(path string, offset int64) ([]viewMessage, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	var messages []viewMessage
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// A partial trailing line is re-read once the writer finishes it.
+			if errors.Is(err, io.EOF) {
+				return messages, offset, nil
+			}
+			return messages, offset, err
+		}
+		offset += int64(len(line))
+		if tombstone, ok := session.DecodeTombstone(bytes.TrimSpace(line)); ok {
+			if tombstone.Messages > len(messages) {
+				return nil, offset, errViewRetracted
+			}
+			messages = messages[:len(messages)-tombstone.Messages]
+			continue
+		}
+		var record struct {
+			Type    string       `json:"type"`
+			Message *llm.Message `json:"message"`
+		}
+		if json.Unmarshal(bytes.TrimSpace(line), &record) != nil || record.Type != "message" || record.Message == nil {
+			continue
+		}
+		if message, ok := newViewMessage(*record.Message); ok {
+			messages = append(messages, message)
+		}
+	}
+}
+
+// newViewMessage converts a stored message; system messages are skipped.
+func newViewMessage(message llm.Message) (viewMessage, bool) {
+	if message.Role == "system" {
+		return viewMessage{}, false
+	}
+	view := viewMessage{
+		Role:       message.Role,
+		Text:       llm.ContentText(message.Content),
+		Thinking:   message.Reasoning,
+		ToolCallID: message.ToolCallID,
+	}
+	for _, part := range llm.ContentParts(message.Content) {
+		if part.Type == "image_url" {
+			view.Images++
+		}
+	}
+	if message.Role == "tool" && len(view.Text) > viewMaxToolResult {
+		view.Text = view.Text[:viewMaxToolResult] + "\n...[truncated]"
+	}
+	for _, call := range message.ToolCalls {
+		view.ToolCalls = append(view.ToolCalls, viewToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: indentToolArguments(call.Function.Arguments),
+		})
+	}
+	return view, true
+}
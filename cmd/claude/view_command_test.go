@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSessionViewStreamsMessages verifies the viewer replays stored messages and follows new ones.
+func TestSessionViewStreamsMessages(testingHandle *testing.T) {
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	sessionID := "view-session"
+	testutil.RequireNoError(testingHandle, persistSession(store, sessionID, []llm.Message{
+		{Role: "system", Content: "hidden system prompt"},
+		{Role: "user", Content: "list files"},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "call-1", Type: "function", Function: llm.ToolCallFunction{Name: "Bash", Arguments: `{"command":"ls"}`}}}},
+		{Role: "tool", ToolCallID: "call-1", Content: "main.go"},
+	}, nil), "persist session")
+
+	server := httptest.NewServer(newSessionViewHandler(store, sessionID, 10*time.Millisecond))
+	defer server.Close()
+
+	page, err := http.Get(server.URL + "/")
+	testutil.RequireNoError(testingHandle, err, "get page")
+	body, _ := io.ReadAll(page.Body)
+	page.Body.Close()
+	testutil.RequireTrue(testingHandle, strings.Contains(string(body), "OpenClaude session view-session"), "page names the session")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	testutil.RequireNoError(testingHandle, err, "build events request")
+	response, err := http.DefaultClient.Do(request)
+	testutil.RequireNoError(testingHandle, err, "get events")
+	defer response.Body.Close()
+	testutil.RequireEqual(testingHandle, response.Header.Get("Content-Type"), "text/event-stream", "content type")
+
+	reader := bufio.NewReader(response.Body)
+	readUntil := func(marker string) string {
+		var seen strings.Builder
+		for !strings.Contains(seen.String(), marker) {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				testingHandle.Fatalf("read events before %q: %v\n%s", marker, err, seen.String())
+			}
+			seen.WriteString(line)
+		}
+		return seen.String()
+	}
+
+	initial := readUntil("event: sync")
+	for _, want := range []string{`"text":"list files"`, `"name":"Bash"`, `"tool_call_id":"call-1"`, `"text":"main.go"`} {
+		testutil.RequireTrue(testingHandle, strings.Contains(initial, want), "initial events contain "+want+": "+initial)
+	}
+	testutil.RequireTrue(testingHandle, !strings.Contains(initial, "hidden system prompt"), "system prompt is not streamed")
+
+	testutil.RequireNoError(testingHandle, persistSession(store, sessionID, []llm.Message{{Role: "assistant", Content: "done"}}, nil), "append message")
+	testutil.RequireTrue(testingHandle, strings.Contains(readUntil("event: sync"), `"text":"done"`), "new message is streamed")
+
+	testutil.RequireNoError(testingHandle, store.AppendTombstone(sessionID, []llm.Message{{Role: "assistant", Content: "done"}}), "retract message")
+	replayed := readUntil("event: sync")
+	testutil.RequireTrue(testingHandle, strings.Contains(replayed, "event: reset") && strings.Contains(replayed, `"text":"main.go"`), "page starts over: "+replayed)
+	testutil.RequireTrue(testingHandle, !strings.Contains(replayed, `"text":"done"`), "retracted message is left out: "+replayed)
+}
+
+// TestSessionViewRejectsForeignHost verifies DNS-rebinding requests are refused.
+func TestSessionViewRejectsForeignHost(testingHandle *testing.T) {
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	handler := newSessionViewHandler(store, "view-session", time.Second)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "http://attacker.example:8080/", nil)
+	handler.ServeHTTP(recorder, request)
+	testutil.RequireEqual(testingHandle, recorder.Code, http.StatusForbidden, "foreign host status")
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "http://localhost:8080/", nil)
+	handler.ServeHTTP(recorder, request)
+	testutil.RequireEqual(testingHandle, recorder.Code, http.StatusOK, "localhost status")
+}
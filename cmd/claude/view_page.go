@@ -0,0 +1,115 @@
+package main
+
+// viewPageHTML is the self-contained viewer page served by claude view.
+// It renders text with a small markdown subset and collapses tool output;
+// all content is HTML-escaped before formatting.
+const viewPageHTML = `<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>OpenClaude · {{SESSION}}</title>
+<style>
+  :root { color-scheme: light dark; --muted: #888; --border: #8884; --user: #d9770633; --tool: #8882; --error: #dc2626; }
+  body { font: 15px/1.55 system-ui, sans-serif; max-width: 920px; margin: 0 auto; padding: 1rem 1.25rem 4rem; }
+  header { position: sticky; top: 0; padding: .5rem 0; backdrop-filter: blur(6px); border-bottom: 1px solid var(--border); display: flex; justify-content: space-between; gap: 1rem; }
+  header .status { color: var(--muted); font-size: 13px; }
+  .msg { margin: 1rem 0; }
+  .role { font-size: 12px; text-transform: uppercase; letter-spacing: .05em; color: var(--muted); margin-bottom: .25rem; }
+  .user .body { background: var(--user); border-radius: 8px; padding: .5rem .75rem; }
+  pre { background: var(--tool); border-radius: 6px; padding: .6rem .75rem; overflow-x: auto; font-size: 13px; }
+  code { font: 13px ui-monospace, SFMono-Regular, Menlo, monospace; }
+  :not(pre) > code { background: var(--tool); border-radius: 4px; padding: 0 .25rem; }
+  details { border: 1px solid var(--border); border-radius: 6px; margin: .4rem 0; padding: .3rem .6rem; }
+  details > summary { cursor: pointer; font: 13px ui-monospace, monospace; }
+  details.thinking > summary { font-style: italic; color: var(--muted); }
+  details.error > summary { color: var(--error); }
+  .note { color: var(--muted); font-size: 13px; }
+</style>
+</head>
+<body>
+<header><strong>OpenClaude session {{SESSION}}</strong><span class="status" id="status">connecting…</span></header>
+<main id="log"></main>
+<script>
+(() => {
+  const log = document.getElementById("log");
+  const status = document.getElementById("status");
+  const calls = {};
+
+  const escape = (text) => text.replace(/[&<>"']/g, (c) => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", "\"": "&quot;", "'": "&#39;"}[c]));
+
+  // inline formats escaped text: code spans, bold, and italics.
+  const inline = (text) => text
+    .replace(/` + "`" + `([^` + "`" + `]+)` + "`" + `/g, "<code>$1</code>")
+    .replace(/\*\*([^*]+)\*\*/g, "<strong>$1</strong>")
+    .replace(/(^|\W)\*([^*\s][^*]*)\*/g, "$1<em>$2</em>");
+
+  // markdown renders fenced code, headings, lists, and paragraphs.
+  const markdown = (source) => {
+    const out = [];
+    const parts = source.split(/^` + "```" + `.*$/m);
+    parts.forEach((part, index) => {
+      if (index % 2 === 1) {
+        out.push("<pre><code>" + escape(part.replace(/^\n|\n$/g, "")) + "</code></pre>");
+        return;
+      }
+      let list = null;
+      for (const raw of escape(part).split("\n")) {
+        const item = raw.match(/^\s*(?:[-*]|\d+\.)\s+(.*)$/);
+        if (item) {
+          if (!list) { list = []; }
+          list.push("<li>" + inline(item[1]) + "</li>");
+          continue;
+        }
+        if (list) { out.push("<ul>" + list.join("") + "</ul>"); list = null; }
+        const heading = raw.match(/^(#{1,6})\s+(.*)$/);
+        if (heading) {
+          const level = Math.min(6, heading[1].length + 2);
+          out.push("<h" + level + ">" + inline(heading[2]) + "</h" + level + ">");
+        } else if (raw.trim() !== "") {
+          out.push("<p>" + inline(raw) + "</p>");
+        }
+      }
+      if (list) { out.push("<ul>" + list.join("") + "</ul>"); }
+    });
+    return out.join("");
+  };
+
+  const details = (summary, body, className) =>
+    "<details class=\"" + (className || "") + "\"><summary>" + escape(summary) + "</summary><pre><code>" + escape(body) + "</code></pre></details>";
+
+  const render = (message) => {
+    const node = document.createElement("div");
+    node.className = "msg " + message.role;
+    let html = "";
+    if (message.role === "tool") {
+      const call = calls[message.tool_call_id] || {};
+      const lines = (message.text || "").split("\n").length;
+      html += details("↳ " + (call.name || "tool") + " result · " + lines + " line(s)", message.text || "(no output)", /^(error|.*failed)/i.test(message.text || "") ? "error" : "");
+    } else {
+      html += "<div class=\"role\">" + escape(message.role) + "</div>";
+      if (message.thinking) { html += details("thinking", message.thinking, "thinking"); }
+      if (message.text) { html += "<div class=\"body\">" + markdown(message.text) + "</div>"; }
+      if (message.images) { html += "<div class=\"note\">[" + message.images + " image(s) attached]</div>"; }
+      for (const call of message.tool_calls || []) {
+        calls[call.id] = call;
+        html += details("⏺ " + call.name, call.arguments, "call");
+      }
+    }
+    node.innerHTML = html;
+    const atBottom = window.innerHeight + window.scrollY >= document.body.scrollHeight - 40;
+    log.appendChild(node);
+    if (atBottom) { window.scrollTo(0, document.body.scrollHeight); }
+  };
+
+  const events = new EventSource("events");
+  events.onopen = () => { status.textContent = "live · read-only"; };
+  events.onmessage = (event) => render(JSON.parse(event.data));
+  events.addEventListener("reset", () => { log.replaceChildren(); });
+  events.addEventListener("failure", (event) => { status.textContent = "error: " + JSON.parse(event.data); events.close(); });
+  events.onerror = () => { status.textContent = "disconnected · retrying…"; };
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// Suffixes that set an --add-dir root's access.
+const (
+	addDirReadOnlySuffix  = ":ro"
+	addDirReadWriteSuffix = ":rw"
+)
+
+// buildSandbox allows cwd and each --add-dir root. Roots given as path:ro are
+// read-only; path:rw and bare paths are read-write.
+func buildSandbox(cwd string, addDirs []string) *tools.Sandbox {
+	roots := []string{cwd}
+	var readOnly []string
+	for _, value := range addDirs {
+		if path, ok := strings.CutSuffix(value, addDirReadOnlySuffix); ok {
+			roots = append(roots, path)
+			readOnly = append(readOnly, path)
+			continue
+		}
+		roots = append(roots, strings.TrimSuffix(value, addDirReadWriteSuffix))
+	}
+	sandbox := tools.NewSandbox(roots)
+	sandbox.ReadOnly = readOnly
+	return sandbox
+}
+
+// formatWorkspaceRoots lists the sandbox roots with their access for /context.
+func formatWorkspaceRoots(sandbox *tools.Sandbox) string {
+	if sandbox == nil || len(sandbox.Roots) == 0 {
+		return "No workspace roots."
+	}
+	var builder strings.Builder
+	builder.WriteString("Workspace roots:")
+	for _, root := range sandbox.Roots {
+		access := "read-write"
+		if absolute, err := filepath.Abs(root); err == nil && sandbox.IsReadOnly(absolute) {
+			access = "read-only"
+		}
+		fmt.Fprintf(&builder, "\n  %s  (%s)", root, access)
+	}
+	return builder.String()
+}
+
+// showContext renders /context for the current TUI session.
+func (m *tuiModel) showContext(_ string) string {
+	if m.runner == nil {
+		return formatWorkspaceRoots(nil)
+	}
+	return formatWorkspaceRoots(m.runner.ToolContext.Sandbox)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestBuildSandboxAddDirAccess verifies :ro and :rw suffixes set each root's
+// access and /context lists it.
+func TestBuildSandboxAddDirAccess(testingHandle *testing.T) {
+	// Arrange
+	cwd := testingHandle.TempDir()
+	docs := filepath.Join(cwd, "..", "docs")
+	vendor := testingHandle.TempDir()
+
+	// Act
+	sandbox := buildSandbox(cwd, []string{docs + ":ro", vendor + ":rw"})
+	report := formatWorkspaceRoots(sandbox)
+
+	// Assert
+	testutil.RequireEqual(testingHandle, sandbox.Roots, []string{cwd, docs, vendor}, "roots")
+	testutil.RequireEqual(testingHandle, sandbox.ReadOnly, []string{docs}, "read-only roots")
+	for _, want := range []string{"Workspace roots:", cwd + "  (read-write)", docs + "  (read-only)", vendor + "  (read-write)"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(report, want), "report contains "+want+"\n"+report)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/session"
+)
+
+// untrustedWorkspaceNotice tells the user why Bash and edits are refused.
+const untrustedWorkspaceNotice = "This folder is not trusted: files can be read, but Bash and file edits are disabled. Run /trust to enable them."
+
+// skippedHooksNotice warns that the hooks from project and local settings do
+// not run while the folder is untrusted. It is empty when there are none.
+func skippedHooksNotice(opts *options) string {
+	if opts == nil || opts.ClaudeSettings == nil {
+		return ""
+	}
+	count := 0
+	for _, matchers := range opts.ClaudeSettings.Hooks {
+		for _, matcher := range matchers {
+			if config.IsWorkspaceSource(matcher.Source) {
+				count += len(matcher.Hooks)
+			}
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Skipping %d hook command(s) from this folder's .claude settings until you run /trust.", count)
+}
+
+// confirmWorkspaceTrust returns whether cwd is trusted, asking on the first
+// interactive run in it and saving the answer. Without an answer (for
+// example, stdin is closed) the folder is untrusted for this run only.
+func confirmWorkspaceTrust(store *session.Store, cwd string, in io.Reader, out io.Writer) (bool, error) {
+	if store == nil {
+		return true, nil
+	}
+	trust, decided, err := store.LoadWorkspaceTrust(cwd)
+	if err != nil {
+		return false, err
+	}
+	if decided {
+		return trust.Trusted, nil
+	}
+	fmt.Fprintf(out, "Do you trust the files in this folder?\n\n  %s\n\n", cwd)
+	fmt.Fprintln(out, "OpenClaude can read files here. Trusting the folder also lets it run commands and edit files; files from someone else can contain instructions that steer the model.")
+	fmt.Fprint(out, "Trust this folder? [y/N]: ")
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(out)
+		return false, nil
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	trusted := answer == "y" || answer == "yes"
+	if err := store.SaveWorkspaceTrust(cwd, trusted); err != nil {
+		return false, err
+	}
+	return trusted, nil
+}
+
+// trustWorkspace handles /trust: it saves trust for the working directory and
+// enables Bash and edits for the rest of the session.
+func (m *tuiModel) trustWorkspace(_ string) string {
+	if m.runner == nil {
+		return "No workspace to trust."
+	}
+	if !m.runner.Permissions.Untrusted {
+		return "This folder is already trusted."
+	}
+	cwd := m.promptCWD()
+	if m.store != nil {
+		if err := m.store.SaveWorkspaceTrust(cwd, true); err != nil {
+			return fmt.Sprintf("Failed to save trust: %v", err)
+		}
+	}
+	m.runner.Permissions.Untrusted = false
+	return fmt.Sprintf("Trusted %s. Bash, file edits, and the folder's settings hooks are enabled.", cwd)
+}
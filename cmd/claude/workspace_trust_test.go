@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestConfirmWorkspaceTrust verifies the dialog asks once per folder, saves
+// the answer, and treats a closed stdin as untrusted without saving.
+func TestConfirmWorkspaceTrust(testingHandle *testing.T) {
+	// Arrange
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	trustedDir := testingHandle.TempDir()
+	closedDir := testingHandle.TempDir()
+	var out bytes.Buffer
+
+	// Act
+	first, firstErr := confirmWorkspaceTrust(store, trustedDir, strings.NewReader("y\n"), &out)
+	prompted := out.String()
+	out.Reset()
+	second, secondErr := confirmWorkspaceTrust(store, trustedDir, strings.NewReader(""), &out)
+	repeated := out.String()
+	closed, closedErr := confirmWorkspaceTrust(store, closedDir, strings.NewReader(""), &out)
+	_, closedDecided, _ := store.LoadWorkspaceTrust(closedDir)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, firstErr, "first run")
+	testutil.RequireNoError(testingHandle, secondErr, "second run")
+	testutil.RequireNoError(testingHandle, closedErr, "closed stdin")
+	testutil.RequireTrue(testingHandle, first && second, "answer saved")
+	testutil.RequireTrue(testingHandle, strings.Contains(prompted, trustedDir) && strings.Contains(prompted, "Trust this folder? [y/N]"), "dialog shown: "+prompted)
+	testutil.RequireEqual(testingHandle, repeated, "", "no dialog once decided")
+	testutil.RequireTrue(testingHandle, !closed && !closedDecided, "closed stdin is untrusted and unsaved")
+}
+
+// TestTrustCommandEnablesTools verifies /trust lifts the untrusted block and saves trust.
+func TestTrustCommandEnablesTools(testingHandle *testing.T) {
+	// Arrange
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	runner := &agent.Runner{}
+	runner.ToolContext.CWD = testingHandle.TempDir()
+	runner.Permissions.Untrusted = true
+	model := newTUIModel(&options{}, runner, nil, sessionHistoryCursor{}, "", "model", "session", store)
+	notice := model.chatMessages[len(model.chatMessages)-1].Content
+
+	// Act
+	reply := model.trustWorkspace("")
+	trust, decided, err := store.LoadWorkspaceTrust(runner.ToolContext.CWD)
+
+	// Assert
+	testutil.RequireEqual(testingHandle, notice, untrustedWorkspaceNotice, "startup notice")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(reply, "Trusted "), reply)
+	testutil.RequireTrue(testingHandle, !runner.Permissions.Untrusted, "tools enabled")
+	testutil.RequireNoError(testingHandle, err, "load trust")
+	testutil.RequireTrue(testingHandle, decided && trust.Trusted, "trust saved")
+}
+
+// TestUntrustedStartupWarnsAboutWorkspaceHooks verifies the TUI says which
+// settings hooks it skips in an untrusted folder.
+func TestUntrustedStartupWarnsAboutWorkspaceHooks(testingHandle *testing.T) {
+	// Arrange
+	runner := &agent.Runner{}
+	runner.Permissions.Untrusted = true
+	opts := &options{ClaudeSettings: &config.Settings{Hooks: map[string][]config.HookMatcher{
+		"Stop":       {{Source: config.SettingsSourceUser, Hooks: []config.HookCommand{{Command: "user"}}}},
+		"PreCompact": {{Source: config.SettingsSourceProject, Hooks: []config.HookCommand{{Command: "a"}, {Command: "b"}}}},
+	}}}
+
+	// Act
+	model := newTUIModel(opts, runner, nil, sessionHistoryCursor{}, "", "model", "session", nil)
+	notice := model.chatMessages[len(model.chatMessages)-1].Content
+
+	// Assert
+	testutil.RequireEqual(testingHandle, notice, "Skipping 2 hook command(s) from this folder's .claude settings until you run /trust.", "hooks notice")
+}
@@ -5,22 +5,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/errcode"
+	"github.com/openclaude/openclaude/internal/llm"
 	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/redact"
 	"github.com/openclaude/openclaude/internal/tools"
 )
 
 var (
 	// ErrMaxTurns signals that the agent exceeded the allowed turn count.
-	ErrMaxTurns = errors.New("max turns exceeded")
+	ErrMaxTurns = errcode.ErrMaxTurns
 	// ErrMaxBudget signals that the cost limit was exceeded.
-	ErrMaxBudget = errors.New("max budget exceeded")
-	// ErrToolDenied signals a user denied a tool call.
-	ErrToolDenied = errors.New("tool denied")
+	ErrMaxBudget = errcode.ErrBudget
+	// ErrToolDenied signals a user denied a tool call; the error is an
+	// *errcode.ToolDeniedError naming the tool.
+	ErrToolDenied = errcode.ErrToolDenied
 	// ErrPlanMode signals that tools are disabled in plan mode.
-	ErrPlanMode = errors.New("tools are disabled in plan mode")
+	ErrPlanMode = errcode.New(errcode.ToolDenied, "tools are disabled in plan mode")
+	// ErrTurnTimeout signals that a turn ran past Runner.TurnTimeout.
+	ErrTurnTimeout = errors.New("turn deadline exceeded")
 )
 
 // ToolEvent captures tool call/result events for streaming output.
@@ -37,22 +44,55 @@ type ToolEvent struct {
 	Result string `json:"result,omitempty"`
 	// IsError indicates whether the tool result represents a failure.
 	IsError bool `json:"is_error,omitempty"`
+	// Stdout and Stderr are a command tool's output streams, kept apart.
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	// ExitCode is a command tool's exit status; nil for tools that run no process.
+	ExitCode *int `json:"exit_code,omitempty"`
+	// Interrupted reports a command stopped by cancellation or a timeout.
+	Interrupted bool `json:"interrupted,omitempty"`
+	// DurationMS is how long the tool ran, on tool_result events.
+	DurationMS int64 `json:"duration_ms,omitempty"`
+}
+
+// toolResultEvent builds the tool_result event for a finished call. Calls
+// that never reached the tool are timed from started.
+func toolResultEvent(call llm.ToolCall, result tools.ToolResult, started time.Time) ToolEvent {
+	duration := result.Duration
+	if duration == 0 {
+		duration = time.Since(started)
+	}
+	return ToolEvent{
+		Type:        "tool_result",
+		ToolName:    call.Function.Name,
+		ToolID:      call.ID,
+		Result:      result.Content,
+		IsError:     result.IsError,
+		Stdout:      result.Stdout,
+		Stderr:      result.Stderr,
+		ExitCode:    result.ExitCode,
+		Interrupted: result.Interrupted,
+		DurationMS:  duration.Milliseconds(),
+	}
 }
 
 // RunResult captures the outcome of a single user turn.
 type RunResult struct {
 	// Messages is the full conversation history.
-	Messages []openai.Message
+	Messages []llm.Message
 	// Final is the last assistant message in the turn.
-	Final openai.Message
+	Final llm.Message
 	// Usage reports token counts for the last call.
-	Usage openai.Usage
+	Usage llm.Usage
 	// TotalUsage accumulates usage across all calls.
-	TotalUsage openai.Usage
+	TotalUsage llm.Usage
 	// ModelUsage aggregates usage by model identifier.
-	ModelUsage map[string]openai.Usage
+	ModelUsage map[string]llm.Usage
 	// Events contains tool call and result events.
 	Events []ToolEvent
+	// DroppedToolResults lists old tool results removed from Messages so the
+	// turn could continue after the prompt outgrew the context window.
+	DroppedToolResults []DroppedToolResult
 	// CostUSD is the accumulated cost for the run.
 	CostUSD float64
 	// NumTurns counts the number of assistant turns executed.
@@ -61,11 +101,66 @@ type RunResult struct {
 	Duration time.Duration
 	// APIDuration is the cumulative time spent in API calls.
 	APIDuration time.Duration
+	// Turns times each assistant turn, in order.
+	Turns []TurnMetrics
+	// turnStarted is when the latest turn's API request was sent.
+	turnStarted time.Time
+}
+
+// TurnMetrics times one assistant turn, from sending its API request to
+// finishing the tool calls it asked for.
+type TurnMetrics struct {
+	// Model is the model the turn's request went to.
+	Model string
+	// Duration is the turn's wall time, tool calls included.
+	Duration time.Duration
+	// APILatency is the duration of the turn's API request.
+	APILatency time.Duration
+	// TimeToFirstToken is the wait for the first generated output; zero for
+	// non-streaming requests.
+	TimeToFirstToken time.Duration
+	// OutputTokens counts the tokens the request generated.
+	OutputTokens int
+}
+
+// recordTurn appends a turn whose API request was sent at start, closing the
+// previous turn there.
+func (r *RunResult) recordTurn(turn TurnMetrics, start time.Time) {
+	r.closeTurn(start)
+	r.Turns = append(r.Turns, turn)
+	r.turnStarted = start
+}
+
+// finish sets the run's duration and closes its last turn.
+func (r *RunResult) finish(startTime time.Time) {
+	now := time.Now()
+	r.Duration = now.Sub(startTime)
+	r.closeTurn(now)
+}
+
+// closeTurn ends the latest turn at end unless it already ended.
+func (r *RunResult) closeTurn(end time.Time) {
+	if last := len(r.Turns) - 1; last >= 0 && r.Turns[last].Duration == 0 {
+		r.Turns[last].Duration = end.Sub(r.turnStarted)
+	}
 }
 
 // ToolAuthorizer controls interactive permission prompts.
 type ToolAuthorizer func(toolName string, args json.RawMessage) (bool, error)
 
+// UsageRecorder receives per-request usage for telemetry export.
+// Subagent runners share their parent's recorder.
+type UsageRecorder interface {
+	// RecordUserPrompt records a prompt submitted by the user.
+	RecordUserPrompt(prompt string)
+	// RecordAPIRequest records a completed model request.
+	RecordAPIRequest(model string, usage llm.Usage, costUSD float64, duration time.Duration)
+	// RecordAPIError records a failed model request.
+	RecordAPIError(model string, err error, duration time.Duration)
+	// RecordToolResult records a completed tool call.
+	RecordToolResult(toolName string, success bool, duration time.Duration)
+}
+
 // Runner executes the agent loop.
 type Runner struct {
 	// Client executes OpenAI-compatible requests.
@@ -78,18 +173,71 @@ type Runner struct {
 	Permissions tools.Permissions
 	// AuthorizeTool prompts user approval when required.
 	AuthorizeTool ToolAuthorizer
+	// BeforeTool runs after authorization, right before a tool call executes;
+	// an error stops the run. The git pre-flight check hooks in here.
+	BeforeTool func(name string, args json.RawMessage) error
+	// StopHook runs when the model ends a turn without tool calls. A non-empty
+	// reason is sent back as a user message and the run continues; active
+	// reports that the run is already continuing because of the hook.
+	StopHook func(ctx context.Context, active bool) string
+	// PreCompact runs before old tool results are dropped to recover from a
+	// context overflow.
+	PreCompact func(ctx context.Context)
 	// MaxTurns limits the number of tool-assisted turns.
 	MaxTurns int
 	// Pricing provides per-model costs for budget tracking.
 	Pricing map[string]config.ModelPricing
 	// MaxBudgetUSD enforces a ceiling on estimated cost.
 	MaxBudgetUSD float64
+	// Models carries per-model limits; MaxOutputTokens is sent as max_tokens when set.
+	Models map[string]config.ModelInfo
+	// RequestParams are the provider's max_tokens, temperature, top_p, and
+	// stop with the session's overrides applied.
+	RequestParams config.RequestParams
+	// MaxThinkingTokens enables extended thinking with this budget (0 disables it).
+	MaxThinkingTokens int
+	// ThinkingFormat selects how the budget is sent when the model has no override.
+	ThinkingFormat string
+	// ServerTools are provider-side tools advertised beside the function tools.
+	ServerTools []map[string]any
+	// MaxArgumentRetries caps consecutive turns whose tool calls carry unparseable
+	// arguments before the run fails with ErrInvalidToolArguments (<= 0 uses 3).
+	MaxArgumentRetries int
+	// MaxParallelTasks caps concurrent Task subagents within one turn (<= 1 runs them serially).
+	MaxParallelTasks int
+	// Recorder receives usage telemetry when set.
+	Recorder UsageRecorder
+	// Status tracks progress for diagnostic dumps and stream heartbeats when set.
+	Status *Status
+	// ProgressInterval sets how often streaming runs send heartbeats (<= 0 uses 5s).
+	ProgressInterval time.Duration
+	// TurnTimeout bounds a whole turn, including every API call and tool run (0 disables it).
+	TurnTimeout time.Duration
+	// Redactor masks credentials in the messages a run starts with; ToolRunner
+	// masks tool results with its own. Nil leaves messages as they are.
+	Redactor *redact.Redactor
 }
 
-// Run executes a single user turn with tool handling.
+// Run executes a single user turn with tool handling. When a tool pauses for
+// user input, Run returns the partial result (ending with the pending tool
+// call) together with a *tools.UserInputRequiredError.
 func (r *Runner) Run(
 	ctx context.Context,
-	messages []openai.Message,
+	messages []llm.Message,
+	systemPrompt string,
+	model string,
+	toolsEnabled bool,
+) (*RunResult, error) {
+	ctx, cancel := r.turnContext(ctx)
+	defer cancel()
+	result, err := r.run(ctx, messages, systemPrompt, model, toolsEnabled)
+	return result, turnError(ctx, err)
+}
+
+// run is Run without the turn deadline.
+func (r *Runner) run(
+	ctx context.Context,
+	messages []llm.Message,
 	systemPrompt string,
 	model string,
 	toolsEnabled bool,
@@ -102,123 +250,224 @@ func (r *Runner) Run(
 		r.MaxTurns = 8
 	}
 
+	// Cancel any in-flight parallel subagents when the run exits early, then wait
+	// for them so no subagent keeps writing task records after the run returns.
+	var inflight sync.WaitGroup
+	defer inflight.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Prepend a system prompt if provided.
 	if systemPrompt != "" {
 		messages = prependSystem(messages, systemPrompt)
 	}
+	// Mask credentials in prompts before they are sent or saved.
+	messages = r.Redactor.Messages(messages)
 
 	result := &RunResult{
 		Messages:   messages,
-		ModelUsage: map[string]openai.Usage{},
+		ModelUsage: map[string]llm.Usage{},
 	}
 
 	startTime := time.Now()
+	r.recordUserPrompt(messages)
+	r.Status.beginRun()
+	defer r.Status.endRun()
 
+	recovered := false
+	invalidStreak := 0
+	stopHookActive := false
 	for turn := 0; turn < r.MaxTurns; turn++ {
 		req := &openai.ChatRequest{
-			Model:    model,
-			Messages: result.Messages,
+			Model:     model,
+			Messages:  result.Messages,
+			MaxTokens: r.maxOutputTokens(model),
 		}
+		r.applyRequestParams(req)
+		r.applyThinking(req, model)
 		if toolsEnabled && r.ToolRunner != nil {
 			req.Tools = r.ToolRunner.ToolSpecs()
 			req.ToolChoice = "auto"
 		}
+		if toolsEnabled {
+			req.ServerTools = r.ServerTools
+		}
 
 		callStart := time.Now()
+		r.Status.beginRequest(model, turn+1)
 		resp, err := r.Client.ChatCompletions(ctx, req)
-		result.APIDuration += time.Since(callStart)
+		callDuration := time.Since(callStart)
+		result.APIDuration += callDuration
 		if err != nil {
-			return nil, err
+			r.Status.endRequest(err, llm.Usage{})
+			if r.Recorder != nil {
+				r.Recorder.RecordAPIError(model, err, callDuration)
+			}
+			// Retry the call once with old tool results dropped when the prompt overflows.
+			if !recovered && r.recoverContextOverflow(ctx, err, result) {
+				recovered = true
+				turn--
+				continue
+			}
+			return nil, contextOverflowError(err, result)
 		}
 
+		r.Status.endRequest(nil, resp.Usage)
+		result.recordTurn(TurnMetrics{Model: model, APILatency: callDuration, OutputTokens: resp.Usage.CompletionTokens}, callStart)
 		choice := resp.Choices[0]
 		result.Usage = resp.Usage
 		accumulateUsage(&result.TotalUsage, resp.Usage)
 		accumulateUsageMap(result.ModelUsage, model, resp.Usage)
-		result.Messages = append(result.Messages, choice.Message)
-		result.Final = choice.Message
-		result.CostUSD += estimateCost(model, resp.Usage, r.Pricing)
+		invalid := repairToolArguments(&choice.Message)
+		appendAssistantMessage(result, choice.Message)
+		callCost := EstimateCost(model, resp.Usage, r.Pricing)
+		result.CostUSD += callCost
+		if r.Recorder != nil {
+			r.Recorder.RecordAPIRequest(model, resp.Usage, callCost, callDuration)
+		}
 		result.NumTurns++
 		if r.MaxBudgetUSD > 0 && result.CostUSD > r.MaxBudgetUSD {
-			result.Duration = time.Since(startTime)
+			result.finish(startTime)
 			return nil, fmt.Errorf("%w: %.4f > %.4f", ErrMaxBudget, result.CostUSD, r.MaxBudgetUSD)
 		}
 
-		// If no tool calls are requested, return the assistant response.
+		// If no tool calls are requested, return the assistant response unless
+		// a Stop hook sends the model back to work.
 		if len(choice.Message.ToolCalls) == 0 || !toolsEnabled || r.ToolRunner == nil {
-			result.Duration = time.Since(startTime)
+			if r.continueAfterStop(ctx, result, &stopHookActive) {
+				continue
+			}
+			result.finish(startTime)
 			return result, nil
 		}
 
-		for _, call := range choice.Message.ToolCalls {
-			args := json.RawMessage(call.Function.Arguments)
-			event := ToolEvent{
-				Type:      "tool_call",
-				ToolName:  call.Function.Name,
-				ToolID:    call.ID,
-				Arguments: args,
-			}
-			result.Events = append(result.Events, event)
-
-			// Respect session-level plan mode markers before executing tools.
-			if tools.IsPlanMode(r.ToolContext.Store, r.ToolContext.SessionID) && call.Function.Name != "ExitPlanMode" {
-				return nil, ErrPlanMode
-			}
-
-			// Plan mode must not execute any tools.
-			if r.Permissions.Mode == tools.PermissionPlan {
-				return nil, ErrPlanMode
-			}
+		if err := r.checkArgumentRetries(invalid, &invalidStreak); err != nil {
+			result.finish(startTime)
+			return nil, err
+		}
 
-			// If configured, ask for user permission before invoking tools.
-			if r.AuthorizeTool != nil && r.Permissions.ShouldPrompt(call.Function.Name) {
-				allowed, err := r.AuthorizeTool(call.Function.Name, args)
+		// Checks run in call order; a run of Task calls is checked together and
+		// then started concurrently.
+		pending := map[int]*pendingToolCall{}
+		prepared := 0
+		var images []llm.ContentPart
+		for index, call := range choice.Message.ToolCalls {
+			if index >= prepared {
+				next, err := r.prepareToolCalls(ctx, result, choice.Message.ToolCalls, index, invalid, nil, pending, &inflight)
 				if err != nil {
 					return nil, err
 				}
-				if !allowed {
-					return nil, fmt.Errorf("%w: %s", ErrToolDenied, call.Function.Name)
-				}
+				prepared = next
 			}
 
-			toolResult, err := r.ToolRunner.Run(ctx, call.Function.Name, args, r.ToolContext)
+			// Unparseable arguments go back to the model without running anything.
+			problem, badArguments := invalid[index]
+
+			toolStart := time.Now()
+			toolResult := tools.ToolResult{IsError: true, Content: problem}
+			var err error
+			if !badArguments {
+				toolResult, err = r.runToolCall(ctx, result, index, call, pending, nil, nil)
+			}
+			// Pause on questions that need out-of-band answers; the caller persists
+			// the partial result and the answer arrives on resume.
+			var inputErr *tools.UserInputRequiredError
+			if errors.As(err, &inputErr) {
+				inputErr.Request.ToolUseID = call.ID
+				result.finish(startTime)
+				return result, inputErr
+			}
 			if err != nil {
 				toolResult = tools.ToolResult{IsError: true, Content: err.Error()}
 			}
+			r.recordToolResult(call.Function.Name, toolResult, toolStart)
+			applyToolUsage(result, toolResult)
+			images = append(images, toolResult.Images...)
+			if r.MaxBudgetUSD > 0 && result.CostUSD > r.MaxBudgetUSD {
+				result.finish(startTime)
+				return nil, fmt.Errorf("%w: %.4f > %.4f", ErrMaxBudget, result.CostUSD, r.MaxBudgetUSD)
+			}
 
-			result.Events = append(result.Events, ToolEvent{
-				Type:     "tool_result",
-				ToolName: call.Function.Name,
-				ToolID:   call.ID,
-				Result:   toolResult.Content,
-				IsError:  toolResult.IsError,
-			})
+			result.Events = append(result.Events, toolResultEvent(call, toolResult, toolStart))
 
-			toolMessage := openai.Message{
+			toolMessage := llm.Message{
 				Role:       "tool",
 				ToolCallID: call.ID,
 				Content:    toolResult.Content,
 			}
 			result.Messages = append(result.Messages, toolMessage)
 		}
+		if len(images) > 0 {
+			result.Messages = append(result.Messages, toolImageMessage(images))
+		}
 	}
 
-	result.Duration = time.Since(startTime)
+	result.finish(startTime)
 	return result, ErrMaxTurns
 }
 
+// toolImageMessage wraps images returned by tools in a user message.
+// OpenAI-compatible tool messages carry text only, so images follow the tool results.
+func toolImageMessage(images []llm.ContentPart) llm.Message {
+	parts := append([]llm.ContentPart{llm.TextPart("Images returned by tool calls:")}, images...)
+	return llm.Message{Role: "user", Content: parts}
+}
+
 // prependSystem injects a system message at the start of the conversation.
-func prependSystem(messages []openai.Message, prompt string) []openai.Message {
+func prependSystem(messages []llm.Message, prompt string) []llm.Message {
 	if len(messages) > 0 && messages[0].Role == "system" {
 		messages[0].Content = fmt.Sprintf("%v\n\n%v", messages[0].Content, prompt)
 		return messages
 	}
-	system := openai.Message{Role: "system", Content: prompt}
-	return append([]openai.Message{system}, messages...)
+	system := llm.Message{Role: "system", Content: prompt}
+	return append([]llm.Message{system}, messages...)
 }
 
-// estimateCost computes cost using pricing per million tokens.
-func estimateCost(model string, usage openai.Usage, pricing map[string]config.ModelPricing) float64 {
+// maxOutputTokens returns the configured completion cap for a model, or nil when unset.
+// An explicit max_tokens applies to every model and wins over the catalog.
+func (r *Runner) maxOutputTokens(model string) *int {
+	if r.RequestParams.MaxTokens > 0 {
+		limit := r.RequestParams.MaxTokens
+		return &limit
+	}
+	info, ok := r.Models[model]
+	if !ok || info.MaxOutputTokens <= 0 {
+		return nil
+	}
+	limit := info.MaxOutputTokens
+	return &limit
+}
+
+// applyRequestParams adds the configured sampling parameters and stop sequences.
+func (r *Runner) applyRequestParams(req *openai.ChatRequest) {
+	req.Temperature = r.RequestParams.Temperature
+	req.TopP = r.RequestParams.TopP
+	req.Stop = r.RequestParams.Stop
+}
+
+// recordUserPrompt reports the trailing user message of a top-level run.
+// Subagent prompts are written by the model, so they are not recorded.
+func (r *Runner) recordUserPrompt(messages []llm.Message) {
+	if r.Recorder == nil || r.ToolContext.TaskDepth > 0 || len(messages) == 0 {
+		return
+	}
+	if last := messages[len(messages)-1]; last.Role == "user" {
+		r.Recorder.RecordUserPrompt(llm.ContentText(last.Content))
+	}
+}
+
+// recordToolResult reports a finished tool call.
+func (r *Runner) recordToolResult(toolName string, result tools.ToolResult, start time.Time) {
+	if r.Recorder != nil {
+		r.Recorder.RecordToolResult(toolName, !result.IsError, time.Since(start))
+	}
+}
+
+// EstimateCost computes cost using pricing per million tokens.
+// Cache tokens are part of the prompt count and are billed at the cache
+// rates when configured, falling back to the input rate. Server-side tool
+// requests are billed per thousand and are free unless priced.
+func EstimateCost(model string, usage llm.Usage, pricing map[string]config.ModelPricing) float64 {
 	if pricing == nil {
 		return 0
 	}
@@ -226,23 +475,36 @@ func estimateCost(model string, usage openai.Usage, pricing map[string]config.Mo
 	if !ok {
 		return 0
 	}
-	input := float64(usage.PromptTokens) / 1_000_000
+	cacheReadRate := price.CacheReadPer1M
+	if cacheReadRate == 0 {
+		cacheReadRate = price.InputPer1M
+	}
+	cacheWriteRate := price.CacheWritePer1M
+	if cacheWriteRate == 0 {
+		cacheWriteRate = price.InputPer1M
+	}
+	uncached := max(usage.PromptTokens-usage.CacheReadTokens-usage.CacheWriteTokens, 0)
+	input := float64(uncached) / 1_000_000
 	output := float64(usage.CompletionTokens) / 1_000_000
-	return input*price.InputPer1M + output*price.OutputPer1M
+	cacheRead := float64(usage.CacheReadTokens) / 1_000_000
+	cacheWrite := float64(usage.CacheWriteTokens) / 1_000_000
+	tokens := input*price.InputPer1M + output*price.OutputPer1M + cacheRead*cacheReadRate + cacheWrite*cacheWriteRate
+	return tokens + ServerToolCost(usage.ServerToolUse, price)
+}
+
+// ServerToolCost prices server-side tool requests with the per-1K rates.
+func ServerToolCost(use llm.ServerToolUse, price config.ModelPricing) float64 {
+	webSearch := float64(use.WebSearchRequests) / 1_000
+	webFetch := float64(use.WebFetchRequests) / 1_000
+	return webSearch*price.WebSearchPer1K + webFetch*price.WebFetchPer1K
 }
 
 // accumulateUsage adds usage counts into the accumulator.
-func accumulateUsage(acc *openai.Usage, usage openai.Usage) {
-	acc.PromptTokens += usage.PromptTokens
-	acc.CompletionTokens += usage.CompletionTokens
-	acc.TotalTokens += usage.TotalTokens
+func accumulateUsage(acc *llm.Usage, usage llm.Usage) {
+	*acc = acc.Add(usage)
 }
 
 // accumulateUsageMap adds usage counts into a per-model map.
-func accumulateUsageMap(target map[string]openai.Usage, model string, usage openai.Usage) {
-	current := target[model]
-	current.PromptTokens += usage.PromptTokens
-	current.CompletionTokens += usage.CompletionTokens
-	current.TotalTokens += usage.TotalTokens
-	target[model] = current
+func accumulateUsageMap(target map[string]llm.Usage, model string, usage llm.Usage) {
+	target[model] = target[model].Add(usage)
 }
@@ -0,0 +1,594 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/redact"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestRunSendsModelMaxOutputTokens verifies catalog output caps are forwarded as max_tokens.
+func TestRunSendsModelMaxOutputTokens(testingHandle *testing.T) {
+	tests := []struct {
+		name   string
+		models map[string]config.ModelInfo
+		params config.RequestParams
+		want   any
+	}{
+		{name: "configured", models: map[string]config.ModelInfo{"small": {MaxOutputTokens: 512}}, want: float64(512)},
+		{name: "unconfigured", models: nil, want: nil},
+		{name: "explicit max_tokens", models: map[string]config.ModelInfo{"small": {MaxOutputTokens: 512}}, params: config.RequestParams{MaxTokens: 2048}, want: float64(2048)},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Arrange a gateway that records the request body.
+			var payload map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				_ = json.NewDecoder(request.Body).Decode(&payload)
+				responseWriter.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+			}))
+			defer server.Close()
+
+			runner := &Runner{
+				Client:        openai.NewClient(server.URL, "", 5*time.Second),
+				Models:        tt.models,
+				RequestParams: tt.params,
+			}
+
+			// Act.
+			_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "hi"}}, "", "small", false)
+
+			// Assert.
+			testutil.RequireNoError(testingHandle, err, "run")
+			testutil.RequireEqual(testingHandle, payload["max_tokens"], tt.want, "max_tokens")
+		})
+	}
+}
+
+// TestRunSendsRequestParams verifies sampling parameters and stop sequences
+// reach both the streaming and non-streaming request bodies.
+func TestRunSendsRequestParams(testingHandle *testing.T) {
+	for _, stream := range []bool{false, true} {
+		testingHandle.Run(fmt.Sprintf("stream=%v", stream), func(testingHandle *testing.T) {
+			// Arrange a gateway that records the request body.
+			var payload map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				_ = json.NewDecoder(request.Body).Decode(&payload)
+				if stream {
+					responseWriter.Header().Set("Content-Type", "text/event-stream")
+					_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"delta\":{\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+					return
+				}
+				responseWriter.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+			}))
+			defer server.Close()
+
+			temperature, topP := 0.2, 0.9
+			runner := &Runner{
+				Client:        openai.NewClient(server.URL, "", 5*time.Second),
+				RequestParams: config.RequestParams{Temperature: &temperature, TopP: &topP, Stop: []string{"###"}},
+			}
+			messages := []llm.Message{{Role: "user", Content: "hi"}}
+
+			// Act.
+			var err error
+			if stream {
+				_, err = runner.RunStream(context.Background(), messages, "", "small", false, &StreamCallbacks{})
+			} else {
+				_, err = runner.Run(context.Background(), messages, "", "small", false)
+			}
+
+			// Assert.
+			testutil.RequireNoError(testingHandle, err, "run")
+			testutil.RequireEqual(testingHandle, payload["temperature"], 0.2, "temperature")
+			testutil.RequireEqual(testingHandle, payload["top_p"], 0.9, "top_p")
+			testutil.RequireEqual(testingHandle, fmt.Sprint(payload["stop"]), "[###]", "stop")
+		})
+	}
+}
+
+// TestRunSendsThinkingBudget verifies thinking budgets are sent in the model's format.
+func TestRunSendsThinkingBudget(testingHandle *testing.T) {
+	tests := []struct {
+		name         string
+		budget       int
+		format       string
+		models       map[string]config.ModelInfo
+		wantEffort   any
+		wantThinking any
+	}{
+		{name: "disabled", budget: 0, format: config.ThinkingFormatOpenAI, wantEffort: nil, wantThinking: nil},
+		{name: "openai medium", budget: 8000, format: config.ThinkingFormatOpenAI, wantEffort: "medium", wantThinking: nil},
+		{
+			name:         "anthropic override",
+			budget:       2048,
+			format:       config.ThinkingFormatOpenAI,
+			models:       map[string]config.ModelInfo{"small": {ThinkingFormat: config.ThinkingFormatAnthropic}},
+			wantEffort:   nil,
+			wantThinking: map[string]any{"type": "enabled", "budget_tokens": float64(2048)},
+		},
+		{name: "none", budget: 2048, format: config.ThinkingFormatNone, wantEffort: nil, wantThinking: nil},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Arrange a gateway that records the request body.
+			var payload map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				_ = json.NewDecoder(request.Body).Decode(&payload)
+				responseWriter.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"ok","reasoning_content":"hmm"},"finish_reason":"stop"}]}`)
+			}))
+			defer server.Close()
+
+			runner := &Runner{
+				Client:            openai.NewClient(server.URL, "", 5*time.Second),
+				Models:            tt.models,
+				MaxThinkingTokens: tt.budget,
+				ThinkingFormat:    tt.format,
+			}
+
+			// Act.
+			result, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "hi"}}, "", "small", false)
+
+			// Assert.
+			testutil.RequireNoError(testingHandle, err, "run")
+			testutil.RequireEqual(testingHandle, payload["reasoning_effort"], tt.wantEffort, "reasoning_effort")
+			testutil.RequireEqual(testingHandle, payload["thinking"], tt.wantThinking, "thinking")
+			testutil.RequireEqual(testingHandle, result.Final.Reasoning, "hmm", "captured reasoning")
+		})
+	}
+}
+
+// TestRunForwardsToolImages verifies images returned by Read reach the model in a user message.
+func TestRunForwardsToolImages(testingHandle *testing.T) {
+	dir := testingHandle.TempDir()
+	imagePath := filepath.Join(dir, "shot.png")
+	testutil.RequireNoError(testingHandle, os.WriteFile(imagePath, []byte("\x89PNG"), 0o600), "write image")
+
+	// Arrange a gateway that requests a Read of the image, then records the follow-up request.
+	var calls int
+	var followUp map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		calls++
+		responseWriter.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			arguments, _ := json.Marshal(map[string]string{"file_path": imagePath})
+			fmt.Fprintf(responseWriter, `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call-1","type":"function","function":{"name":"Read","arguments":%q}}]},"finish_reason":"tool_calls"}]}`, arguments)
+			return
+		}
+		_ = json.NewDecoder(request.Body).Decode(&followUp)
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"a screenshot"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	runner := &Runner{
+		Client:      openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:  tools.NewRunner([]tools.Tool{&tools.ReadTool{}}),
+		ToolContext: tools.ToolContext{Sandbox: tools.NewSandbox([]string{dir}), CWD: dir},
+		Permissions: tools.Permissions{Mode: tools.PermissionBypass},
+	}
+
+	// Act.
+	_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "look"}}, "", "vision", true)
+
+	// Assert the tool message is text and the image follows as a user image part.
+	testutil.RequireNoError(testingHandle, err, "run")
+	messages, _ := followUp["messages"].([]any)
+	testutil.RequireEqual(testingHandle, len(messages), 4, "message count")
+	toolMessage, _ := messages[2].(map[string]any)
+	testutil.RequireEqual(testingHandle, toolMessage["role"], "tool", "tool message role")
+	imageMessage, _ := messages[3].(map[string]any)
+	parts, _ := imageMessage["content"].([]any)
+	testutil.RequireEqual(testingHandle, len(parts), 2, "image message parts")
+	imagePart, _ := parts[1].(map[string]any)
+	imageURL, _ := imagePart["image_url"].(map[string]any)
+	testutil.RequireEqual(testingHandle, imageURL["url"], "data:image/png;base64,iVBORw==", "image data url")
+}
+
+// TestRunRedactsSecrets verifies credentials in the prompt and in tool
+// results are masked in every request and in the returned conversation.
+func TestRunRedactsSecrets(testingHandle *testing.T) {
+	// Arrange a file holding a fake key and a gateway that reads it.
+	dir := testingHandle.TempDir()
+	secret := "AKIA" + strings.Repeat("T", 16)
+	envPath := filepath.Join(dir, "deploy.env")
+	testutil.RequireNoError(testingHandle, os.WriteFile(envPath, []byte("AWS_ACCESS_KEY_ID="+secret+"\n"), 0o600), "write env")
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		bodies = append(bodies, string(body))
+		responseWriter.Header().Set("Content-Type", "application/json")
+		if len(bodies) == 1 {
+			arguments, _ := json.Marshal(map[string]string{"file_path": envPath})
+			fmt.Fprintf(responseWriter, `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call-1","type":"function","function":{"name":"Read","arguments":%q}}]},"finish_reason":"tool_calls"}]}`, arguments)
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"done"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	redactor, err := redact.New(nil)
+	testutil.RequireNoError(testingHandle, err, "new redactor")
+	toolRunner := tools.NewRunner([]tools.Tool{&tools.ReadTool{}})
+	toolRunner.Redactor = redactor
+	runner := &Runner{
+		Client:      openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:  toolRunner,
+		ToolContext: tools.ToolContext{Sandbox: tools.NewSandbox([]string{dir}), CWD: dir},
+		Permissions: tools.Permissions{Mode: tools.PermissionBypass},
+		Redactor:    redactor,
+	}
+	prompt := []llm.Message{{Role: "user", Content: "is " + secret + " still valid?"}}
+
+	// Act
+	result, err := runner.Run(context.Background(), prompt, "", "model", true)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "run")
+	testutil.RequireEqual(testingHandle, len(bodies), 2, "requests")
+	for index, body := range bodies {
+		testutil.RequireTrue(testingHandle, !strings.Contains(body, secret), fmt.Sprintf("request %d masked: %s", index, body))
+	}
+	testutil.RequireTrue(testingHandle, strings.Contains(bodies[1], "AWS_ACCESS_KEY_ID=[REDACTED:aws-access-key]"), "tool result masked")
+	saved, _ := json.Marshal(result.Messages)
+	testutil.RequireTrue(testingHandle, !strings.Contains(string(saved), secret), "conversation masked")
+	testutil.RequireEqual(testingHandle, prompt[0].Content, "is "+secret+" still valid?", "caller's messages untouched")
+}
+
+// TestEstimateCostPricesCacheTokens verifies cache tokens use their own rates and fall back to input pricing.
+func TestEstimateCostPricesCacheTokens(testingHandle *testing.T) {
+	usage := llm.Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000, CacheReadTokens: 400_000, CacheWriteTokens: 100_000}
+	pricing := map[string]config.ModelPricing{
+		"cached":   {InputPer1M: 10, OutputPer1M: 20, CacheReadPer1M: 1, CacheWritePer1M: 12},
+		"fallback": {InputPer1M: 10, OutputPer1M: 20},
+	}
+	// 500k uncached input at $10 + 1M output at $20 + 400k reads at $1 + 100k writes at $12.
+	testutil.RequireEqual(testingHandle, fmt.Sprintf("%.2f", EstimateCost("cached", usage, pricing)), "26.60", "cache rates")
+	testutil.RequireEqual(testingHandle, fmt.Sprintf("%.2f", EstimateCost("fallback", usage, pricing)), "30.00", "input rate fallback")
+	testutil.RequireEqual(testingHandle, EstimateCost("unknown", usage, pricing), 0.0, "unpriced model")
+}
+
+// TestEstimateCostPricesServerTools verifies gateway-reported server tool requests are priced per thousand.
+func TestEstimateCostPricesServerTools(testingHandle *testing.T) {
+	var usage llm.Usage
+	payload := `{"prompt_tokens":1000000,"completion_tokens":0,"server_tool_use":{"web_search_requests":3,"web_fetch_requests":2}}`
+	testutil.RequireNoError(testingHandle, json.Unmarshal([]byte(payload), &usage), "decode usage")
+	testutil.RequireEqual(testingHandle, usage.ServerToolUse.WebSearchRequests, 3, "web searches")
+
+	var total llm.Usage
+	accumulateUsage(&total, usage)
+	accumulateUsage(&total, usage)
+	testutil.RequireEqual(testingHandle, total.ServerToolUse, llm.ServerToolUse{WebSearchRequests: 6, WebFetchRequests: 4}, "accumulated")
+
+	pricing := map[string]config.ModelPricing{
+		"search":   {InputPer1M: 1, WebSearchPer1K: 10, WebFetchPer1K: 5},
+		"unpriced": {InputPer1M: 1},
+	}
+	// $1 of input + 6 searches at $10/1k + 4 fetches at $5/1k.
+	testutil.RequireEqual(testingHandle, fmt.Sprintf("%.3f", EstimateCost("search", llm.Usage{PromptTokens: 1_000_000, ServerToolUse: total.ServerToolUse}, pricing)), "1.080", "server tool rates")
+	testutil.RequireEqual(testingHandle, fmt.Sprintf("%.3f", EstimateCost("unpriced", llm.Usage{PromptTokens: 1_000_000, ServerToolUse: total.ServerToolUse}, pricing)), "1.000", "server tools free without rates")
+
+	encoded, err := json.Marshal(llm.Usage{PromptTokens: 1})
+	testutil.RequireNoError(testingHandle, err, "encode usage")
+	testutil.RequireTrue(testingHandle, !strings.Contains(string(encoded), "server_tool_use"), "zero server tool use omitted")
+}
+
+// pausingTool stands in for AskUserQuestion without a TTY in print mode.
+type pausingTool struct{}
+
+func (pausingTool) Name() string           { return tools.AskUserQuestionToolName }
+func (pausingTool) Description() string    { return "ask" }
+func (pausingTool) Schema() map[string]any { return map[string]any{"type": "object"} }
+func (pausingTool) Run(context.Context, json.RawMessage, tools.ToolContext) (tools.ToolResult, error) {
+	return tools.ToolResult{}, &tools.UserInputRequiredError{Request: tools.UserInputRequest{Question: "Which database?"}}
+}
+
+// TestRunPausesForUserInput verifies a question stops the loop with the partial result.
+func TestRunPausesForUserInput(testingHandle *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		calls++
+		responseWriter.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call-ask","type":"function","function":{"name":"AskUserQuestion","arguments":"{\"question\":\"Which database?\"}"}}]},"finish_reason":"tool_calls"}]}`)
+	}))
+	defer server.Close()
+
+	runner := &Runner{
+		Client:      openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:  tools.NewRunner([]tools.Tool{pausingTool{}}),
+		Permissions: tools.Permissions{Mode: tools.PermissionBypass},
+	}
+	result, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "set up storage"}}, "", "model", true)
+
+	var inputErr *tools.UserInputRequiredError
+	testutil.RequireTrue(testingHandle, errors.As(err, &inputErr), "run returns a user input error")
+	testutil.RequireEqual(testingHandle, inputErr.Request.ToolUseID, "call-ask", "pending tool use id")
+	testutil.RequireTrue(testingHandle, result != nil, "partial result is returned")
+	testutil.RequireEqual(testingHandle, len(result.Messages), 2, "history ends with the pending call")
+	testutil.RequireEqual(testingHandle, calls, 1, "no follow-up request is sent")
+}
+
+// TestRunEnforcesPolicyLimitsInBypassMode verifies policy path limits turn
+// violating calls into error results even when prompts are bypassed.
+func TestRunEnforcesPolicyLimitsInBypassMode(testingHandle *testing.T) {
+	dir := testingHandle.TempDir()
+
+	// Arrange a gateway that asks to Write outside the allowed paths.
+	var calls int
+	var toolContent string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		calls++
+		responseWriter.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call-1","type":"function","function":{"name":"Write","arguments":"{\"file_path\":\"main.go\",\"content\":\"package main\"}"}}]},"finish_reason":"tool_calls"}]}`)
+			return
+		}
+		var followUp struct {
+			Messages []llm.Message `json:"messages"`
+		}
+		_ = json.NewDecoder(request.Body).Decode(&followUp)
+		toolContent = llm.ContentText(followUp.Messages[len(followUp.Messages)-1].Content)
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	runner := &Runner{
+		Client:      openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:  tools.NewRunner([]tools.Tool{&tools.WriteTool{}}),
+		ToolContext: tools.ToolContext{Sandbox: tools.NewSandbox([]string{dir}), CWD: dir},
+		Permissions: tools.Permissions{Mode: tools.PermissionBypass, Policy: &tools.PolicyLimits{Paths: []string{"src"}}},
+	}
+
+	// Act.
+	_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "write"}}, "", "model", true)
+
+	// Assert the file was not written and the model saw why.
+	testutil.RequireNoError(testingHandle, err, "run")
+	_, statErr := os.Stat(filepath.Join(dir, "main.go"))
+	testutil.RequireTrue(testingHandle, os.IsNotExist(statErr), "write blocked")
+	testutil.RequireTrue(testingHandle, strings.Contains(toolContent, "outside the allowed paths"), "policy error result: "+toolContent)
+}
+
+// TestRunReturnsInvalidToolArgumentsToModel verifies unparseable arguments are
+// answered with an error result the model can correct, up to the retry bound.
+func TestRunReturnsInvalidToolArgumentsToModel(testingHandle *testing.T) {
+	const badCall = `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call-1","type":"function","function":{"name":"Write","arguments":"{\"file_path\": \"main.go\", \"content\": "}}]},"finish_reason":"tool_calls"}]}`
+	tests := []struct {
+		name      string
+		badTurns  int
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "model corrects itself", badTurns: 1, wantCalls: 2},
+		{name: "retries exhausted", badTurns: 10, wantErr: true, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Arrange a gateway that sends truncated arguments before answering.
+			dir := testingHandle.TempDir()
+			var calls int
+			var followUp struct {
+				Messages []llm.Message `json:"messages"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				calls++
+				_ = json.NewDecoder(request.Body).Decode(&followUp)
+				responseWriter.Header().Set("Content-Type", "application/json")
+				if calls <= tt.badTurns {
+					_, _ = fmt.Fprint(responseWriter, badCall)
+					return
+				}
+				_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+			}))
+			defer server.Close()
+			runner := &Runner{
+				Client:      openai.NewClient(server.URL, "", 5*time.Second),
+				ToolRunner:  tools.NewRunner([]tools.Tool{&tools.WriteTool{}}),
+				ToolContext: tools.ToolContext{Sandbox: tools.NewSandbox([]string{dir}), CWD: dir},
+				Permissions: tools.Permissions{Mode: tools.PermissionDefault},
+				AuthorizeTool: func(name string, input json.RawMessage) (bool, error) {
+					return false, fmt.Errorf("unexpected prompt for %s", name)
+				},
+				MaxTurns:           10,
+				MaxArgumentRetries: 2,
+			}
+
+			// Act.
+			_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "write"}}, "", "model", true)
+
+			// Assert.
+			testutil.RequireEqual(testingHandle, errors.Is(err, ErrInvalidToolArguments), tt.wantErr, fmt.Sprintf("error: %v", err))
+			testutil.RequireEqual(testingHandle, calls, tt.wantCalls, "gateway calls")
+			_, statErr := os.Stat(filepath.Join(dir, "main.go"))
+			testutil.RequireTrue(testingHandle, os.IsNotExist(statErr), "write did not run")
+			history := followUp.Messages
+			assistant, toolMessage := history[len(history)-2], history[len(history)-1]
+			testutil.RequireEqual(testingHandle, assistant.ToolCalls[0].Function.Arguments, "{}", "history arguments")
+			testutil.RequireTrue(testingHandle, strings.Contains(llm.ContentText(toolMessage.Content), "arguments for Write could not be parsed"), llm.ContentText(toolMessage.Content))
+		})
+	}
+}
+
+// TestRunContinuesWhenStopHookBlocks verifies a Stop hook reason sends the
+// model back to work once, with stop_hook_active set on the second check.
+func TestRunContinuesWhenStopHookBlocks(testingHandle *testing.T) {
+	// Arrange a gateway that always answers and records the last message it saw.
+	var calls int
+	var lastMessage llm.Message
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		calls++
+		var payload struct {
+			Messages []llm.Message `json:"messages"`
+		}
+		_ = json.NewDecoder(request.Body).Decode(&payload)
+		lastMessage = payload.Messages[len(payload.Messages)-1]
+		responseWriter.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"done %d"},"finish_reason":"stop"}]}`, calls)
+	}))
+	defer server.Close()
+	var actives []bool
+	runner := &Runner{
+		Client: openai.NewClient(server.URL, "", 5*time.Second),
+		StopHook: func(ctx context.Context, active bool) string {
+			actives = append(actives, active)
+			if active {
+				return ""
+			}
+			return "Tests were not run."
+		},
+	}
+
+	// Act.
+	result, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "fix it"}}, "", "model", false)
+
+	// Assert.
+	testutil.RequireNoError(testingHandle, err, "run")
+	testutil.RequireEqual(testingHandle, calls, 2, "gateway calls")
+	testutil.RequireEqual(testingHandle, actives, []bool{false, true}, "stop_hook_active")
+	testutil.RequireEqual(testingHandle, lastMessage.Role, "user", "feedback role")
+	testutil.RequireEqual(testingHandle, llm.ContentText(lastMessage.Content), "Stop hook feedback:\nTests were not run.", "feedback")
+	testutil.RequireEqual(testingHandle, llm.ContentText(result.Final.Content), "done 2", "final")
+}
+
+// TestRunReportsTurnTimeout verifies the turn deadline stops a stuck call and is reported as ErrTurnTimeout.
+func TestRunReportsTurnTimeout(testingHandle *testing.T) {
+	// Arrange a gateway that never answers.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	runner := &Runner{Client: openai.NewClient(server.URL, "", time.Minute), TurnTimeout: 50 * time.Millisecond}
+	messages := []llm.Message{{Role: "user", Content: "hi"}}
+
+	// Act
+	_, runErr := runner.Run(context.Background(), messages, "", "m", false)
+	_, streamErr := runner.RunStream(context.Background(), messages, "", "m", false, &StreamCallbacks{})
+
+	// Assert
+	for _, err := range []error{runErr, streamErr} {
+		testutil.RequireTrue(testingHandle, errors.Is(err, ErrTurnTimeout), fmt.Sprintf("turn timeout: %v", err))
+		testutil.RequireEqual(testingHandle, err.Error(), "turn deadline exceeded after 50ms", "message")
+	}
+}
+
+// TestRunRecoversFromContextOverflow verifies old tool results are dropped and
+// the call retried once, keeping the latest tool round intact.
+func TestRunRecoversFromContextOverflow(testingHandle *testing.T) {
+	// Arrange: the gateway overflows while the old Read result is in the prompt,
+	// and always when "stuck" is set.
+	oldResult := strings.Repeat("old line\n", 100)
+	var stuck bool
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		requests++
+		body, _ := io.ReadAll(request.Body)
+		if stuck || strings.Contains(string(body), "old line") {
+			http.Error(responseWriter, `{"error":{"code":"context_length_exceeded","message":"This model's maximum context length is 8192 tokens."}}`, http.StatusBadRequest)
+			return
+		}
+		if strings.Contains(string(body), `"stream":true`) {
+			_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"done\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"done"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	toolCall := func(id string, name string) llm.Message {
+		return llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: id, Type: "function", Function: llm.ToolCallFunction{Name: name, Arguments: "{}"}}}}
+	}
+	messages := []llm.Message{
+		{Role: "user", Content: "look around"},
+		toolCall("call-1", "Read"),
+		{Role: "tool", ToolCallID: "call-1", Content: oldResult},
+		toolCall("call-2", "Grep"),
+		{Role: "tool", ToolCallID: "call-2", Content: strings.Repeat("new line\n", 100)},
+		{Role: "assistant", Content: "found it"},
+		{Role: "user", Content: "continue"},
+	}
+	runner := &Runner{Client: openai.NewClient(server.URL, "", 5*time.Second)}
+	var trimmed []DroppedToolResult
+
+	// Act
+	result, err := runner.Run(context.Background(), messages, "", "m", false)
+	streamResult, streamErr := runner.RunStream(context.Background(), messages, "", "m", false, &StreamCallbacks{
+		OnContextTrimmed: func(dropped []DroppedToolResult) { trimmed = dropped },
+	})
+	stuck = true
+	requests = 0
+	_, stuckErr := runner.Run(context.Background(), messages, "", "m", false)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "run recovers")
+	testutil.RequireNoError(testingHandle, streamErr, "stream recovers")
+	want := []DroppedToolResult{{ToolID: "call-1", ToolName: "Read", Chars: len(oldResult)}}
+	testutil.RequireEqual(testingHandle, result.DroppedToolResults, want, "dropped index")
+	testutil.RequireEqual(testingHandle, streamResult.DroppedToolResults, want, "stream dropped index")
+	testutil.RequireEqual(testingHandle, trimmed, want, "trim callback")
+	testutil.RequireEqual(testingHandle, result.Messages[2].Content, "[Read result (900 characters) dropped to fit the context window. Run the tool again if you still need it.]", "placeholder")
+	testutil.RequireEqual(testingHandle, result.Messages[4].Content, messages[4].Content, "latest tool round kept")
+	testutil.RequireEqual(testingHandle, messages[2].Content, any(oldResult), "caller history untouched")
+	testutil.RequireTrue(testingHandle, stuckErr != nil && strings.Contains(stuckErr.Error(), "still too long after dropping 1 old tool results"), fmt.Sprintf("recovery failure: %v", stuckErr))
+	testutil.RequireEqual(testingHandle, requests, 2, "retried once")
+}
+
+// TestRunContinuesPrefill verifies a trailing assistant message is sent as a
+// prefill and merged with the reply into one message.
+func TestRunContinuesPrefill(testingHandle *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+	}{
+		{name: "continuation", reply: `\"ok\": true}`},
+		{name: "echoed prefill", reply: `{\"ok\": true}`},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Arrange a gateway that records the last message it was sent.
+			var payload struct {
+				Messages []map[string]any `json:"messages"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				_ = json.NewDecoder(request.Body).Decode(&payload)
+				responseWriter.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"%s"},"finish_reason":"stop"}]}`, tt.reply)
+			}))
+			defer server.Close()
+			runner := &Runner{Client: openai.NewClient(server.URL, "", 5*time.Second)}
+			messages := []llm.Message{{Role: "user", Content: "status as JSON"}, {Role: "assistant", Content: "{"}}
+
+			// Act.
+			result, err := runner.Run(context.Background(), messages, "", "small", false)
+
+			// Assert.
+			testutil.RequireNoError(testingHandle, err, "run")
+			last := payload.Messages[len(payload.Messages)-1]
+			testutil.RequireEqual(testingHandle, last["role"], any("assistant"), "prefill sent last")
+			testutil.RequireEqual(testingHandle, last["content"], any("{"), "prefill content")
+			testutil.RequireEqual(testingHandle, len(result.Messages), 2, "prefill and reply merged")
+			testutil.RequireEqual(testingHandle, result.Final.Content, any(`{"ok": true}`), "final text")
+			testutil.RequireEqual(testingHandle, messages[1].Content, any("{"), "caller messages untouched")
+		})
+	}
+}
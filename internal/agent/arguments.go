@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// ErrInvalidToolArguments signals that the model kept sending tool calls whose
+// arguments are not valid JSON objects.
+var ErrInvalidToolArguments = errors.New("invalid tool arguments")
+
+// defaultArgumentRetries is how many consecutive turns may carry unparseable
+// tool arguments before the run fails.
+const defaultArgumentRetries = 3
+
+// maxEchoedArguments caps how much of the bad arguments is quoted back to the model.
+const maxEchoedArguments = 500
+
+// argumentRetries returns the configured retry bound for unparseable tool arguments.
+func (r *Runner) argumentRetries() int {
+	if r.MaxArgumentRetries > 0 {
+		return r.MaxArgumentRetries
+	}
+	return defaultArgumentRetries
+}
+
+// repairToolArguments checks each tool call's arguments before the assistant
+// message joins the conversation. Empty arguments become "{}". Arguments that
+// are not a JSON object are replaced with "{}" so the history stays valid for
+// the gateway, and the parse problem is returned keyed by tool call index so it
+// can be sent back as that call's tool result instead of running the tool.
+func repairToolArguments(message *llm.Message) map[int]string {
+	var invalid map[int]string
+	for index := range message.ToolCalls {
+		call := &message.ToolCalls[index]
+		raw := strings.TrimSpace(call.Function.Arguments)
+		if raw == "" {
+			call.Function.Arguments = "{}"
+			continue
+		}
+		var object map[string]json.RawMessage
+		err := json.Unmarshal([]byte(raw), &object)
+		if err == nil && object != nil {
+			continue
+		}
+		if err == nil {
+			err = errors.New("arguments must be a JSON object")
+		}
+		if invalid == nil {
+			invalid = map[int]string{}
+		}
+		invalid[index] = invalidArgumentsMessage(call.Function.Name, raw, err)
+		call.Function.Arguments = "{}"
+	}
+	return invalid
+}
+
+// invalidArgumentsMessage describes a parse failure so the model can resend the call.
+func invalidArgumentsMessage(name string, raw string, err error) string {
+	quoted := raw
+	if len(quoted) > maxEchoedArguments {
+		quoted = truncateUTF8(quoted, maxEchoedArguments) + "..."
+	}
+	return fmt.Sprintf("The arguments for %s could not be parsed (%v), so the tool did not run. "+
+		"Send the call again with arguments as a single valid JSON object matching the tool's schema.\nReceived: %s", name, err, quoted)
+}
+
+// truncateUTF8 cuts text to at most limit bytes without splitting a rune.
+func truncateUTF8(text string, limit int) string {
+	for limit > 0 && limit < len(text) && text[limit]&0xC0 == 0x80 {
+		limit--
+	}
+	return text[:limit]
+}
+
+// checkArgumentRetries counts consecutive turns with unparseable tool
+// arguments and fails once they exceed the configured bound.
+func (r *Runner) checkArgumentRetries(invalid map[int]string, streak *int) error {
+	if len(invalid) == 0 {
+		*streak = 0
+		return nil
+	}
+	*streak++
+	if *streak <= r.argumentRetries() {
+		return nil
+	}
+	first := -1
+	for index := range invalid {
+		if first < 0 || index < first {
+			first = index
+		}
+	}
+	return fmt.Errorf("%w after %d attempts: %s", ErrInvalidToolArguments, *streak, strings.SplitN(invalid[first], "\n", 2)[0])
+}
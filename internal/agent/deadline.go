@@ -0,0 +1,27 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// turnContext applies TurnTimeout to ctx; the cancel func must always be called.
+func (r *Runner) turnContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.TurnTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, r.TurnTimeout, fmt.Errorf("%w after %s", ErrTurnTimeout, r.TurnTimeout))
+}
+
+// turnError reports a turn that failed because its deadline passed as
+// ErrTurnTimeout instead of the context error surfaced by the call in flight.
+func turnError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if cause := context.Cause(ctx); errors.Is(cause, ErrTurnTimeout) {
+		return cause
+	}
+	return err
+}
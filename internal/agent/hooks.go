@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// stopHookFeedbackPrefix introduces a Stop hook's reason in the message that
+// sends the model back to work.
+const stopHookFeedbackPrefix = "Stop hook feedback:\n"
+
+// continueAfterStop runs the Stop hook when the model ends its turn. When the
+// hook blocks stopping, its reason is appended as a user message and true is
+// returned so the loop requests another response. active is set while the run
+// continues because of a hook, so hooks can avoid forcing it forever.
+func (r *Runner) continueAfterStop(ctx context.Context, result *RunResult, active *bool) bool {
+	if r.StopHook == nil {
+		return false
+	}
+	reason := r.StopHook(ctx, *active)
+	if reason == "" {
+		return false
+	}
+	*active = true
+	result.Messages = append(result.Messages, llm.Message{Role: "user", Content: stopHookFeedbackPrefix + reason})
+	return true
+}
+
+// preCompact runs the PreCompact hook before old tool results are dropped.
+func (r *Runner) preCompact(ctx context.Context) {
+	if r.PreCompact != nil {
+		r.PreCompact(ctx)
+	}
+}
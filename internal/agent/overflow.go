@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/openclaude/openclaude/internal/errcode"
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// minDroppedToolResultChars skips tool results too small for their placeholder to save space.
+const minDroppedToolResultChars = 256
+
+// DroppedToolResult records a tool result removed from the conversation so a
+// turn could continue after the prompt outgrew the context window.
+type DroppedToolResult struct {
+	// ToolID is the tool call whose result was dropped.
+	ToolID string `json:"tool_id"`
+	// ToolName is the tool that produced it.
+	ToolName string `json:"tool_name"`
+	// Chars is the size of the dropped content.
+	Chars int `json:"chars"`
+}
+
+// IsContextOverflow reports whether err is a gateway rejecting a prompt that
+// does not fit the model's context window.
+func IsContextOverflow(err error) bool {
+	return errors.Is(err, errcode.ErrContextOverflow)
+}
+
+// dropOldToolResults replaces the content of tool results that came before the
+// latest tool round with a placeholder naming what was removed. It returns a
+// copy of messages and the dropped results, oldest first.
+func dropOldToolResults(messages []llm.Message) ([]llm.Message, []DroppedToolResult) {
+	latestRound := -1
+	toolNames := map[string]string{}
+	for index, message := range messages {
+		if message.Role != "assistant" || len(message.ToolCalls) == 0 {
+			continue
+		}
+		latestRound = index
+		for _, call := range message.ToolCalls {
+			toolNames[call.ID] = call.Function.Name
+		}
+	}
+
+	trimmed := append([]llm.Message(nil), messages...)
+	var dropped []DroppedToolResult
+	for index := 0; index < latestRound; index++ {
+		message := trimmed[index]
+		if message.Role != "tool" {
+			continue
+		}
+		chars := len(llm.ContentText(message.Content))
+		if chars < minDroppedToolResultChars && !llm.HasImages(message.Content) {
+			continue
+		}
+		name := toolNames[message.ToolCallID]
+		if name == "" {
+			name = "tool"
+		}
+		message.Content = fmt.Sprintf("[%s result (%d characters) dropped to fit the context window. Run the tool again if you still need it.]", name, chars)
+		trimmed[index] = message
+		dropped = append(dropped, DroppedToolResult{ToolID: message.ToolCallID, ToolName: name, Chars: chars})
+	}
+	return trimmed, dropped
+}
+
+// recoverContextOverflow trims old tool results after a context overflow so the
+// failed call can be retried. It returns false when err is not an overflow or
+// nothing is left to drop. The PreCompact hook runs before anything is dropped.
+func (r *Runner) recoverContextOverflow(ctx context.Context, err error, result *RunResult) bool {
+	if !IsContextOverflow(err) {
+		return false
+	}
+	trimmed, dropped := dropOldToolResults(result.Messages)
+	if len(dropped) == 0 {
+		return false
+	}
+	r.preCompact(ctx)
+	result.Messages = trimmed
+	result.DroppedToolResults = append(result.DroppedToolResults, dropped...)
+	return true
+}
+
+// contextOverflowError explains an overflow that recovery could not fix.
+func contextOverflowError(err error, result *RunResult) error {
+	if !IsContextOverflow(err) {
+		return err
+	}
+	if len(result.DroppedToolResults) == 0 {
+		return fmt.Errorf("prompt is too long and there are no old tool results to drop; start a new session or shorten the request: %w", err)
+	}
+	return fmt.Errorf("prompt is still too long after dropping %d old tool results: %w", len(result.DroppedToolResults), err)
+}
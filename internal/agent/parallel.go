@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/openclaude/openclaude/internal/errcode"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// parallelToolName is the only tool whose calls may run concurrently within a turn.
+// Other tools mutate the workspace and must keep their requested ordering.
+const parallelToolName = "Task"
+
+// pendingToolCall tracks a tool call launched ahead of the sequential tool loop.
+type pendingToolCall struct {
+	// done closes once result and err are populated.
+	done chan struct{}
+	// result holds the tool output.
+	result tools.ToolResult
+	// err reports a tool runner failure.
+	err error
+}
+
+// toolCallEvent describes a requested tool call.
+func toolCallEvent(call llm.ToolCall) ToolEvent {
+	return ToolEvent{
+		Type:      "tool_call",
+		ToolName:  call.Function.Name,
+		ToolID:    call.ID,
+		Arguments: json.RawMessage(call.Function.Arguments),
+	}
+}
+
+// prepareToolCall records a tool call and runs the checks that must pass
+// before it starts: the OnToolCall callback, plan mode, the permission prompt
+// and the BeforeTool hook. Calls with unparseable arguments never run, so
+// they skip the prompt and the hook.
+func (r *Runner) prepareToolCall(result *RunResult, call llm.ToolCall, badArguments bool, onToolCall func(ToolEvent) error) error {
+	event := toolCallEvent(call)
+	result.Events = append(result.Events, event)
+	if onToolCall != nil {
+		if err := onToolCall(event); err != nil {
+			return fmt.Errorf("tool call callback: %w", err)
+		}
+	}
+
+	// Respect session-level plan mode markers before executing tools.
+	if tools.IsPlanMode(r.ToolContext.Store, r.ToolContext.SessionID) && call.Function.Name != "ExitPlanMode" {
+		return ErrPlanMode
+	}
+
+	// Plan mode must not execute any tools.
+	if r.Permissions.Mode == tools.PermissionPlan {
+		return ErrPlanMode
+	}
+
+	// If configured, ask for user permission before invoking tools.
+	if !badArguments && r.AuthorizeTool != nil && r.Permissions.ShouldPromptCall(call.Function.Name, event.Arguments) {
+		allowed, err := r.AuthorizeTool(call.Function.Name, event.Arguments)
+		if err != nil {
+			return fmt.Errorf("authorize tool %s: %w", call.Function.Name, err)
+		}
+		if !allowed {
+			return &errcode.ToolDeniedError{ToolName: call.Function.Name}
+		}
+	}
+	if !badArguments && r.BeforeTool != nil {
+		if err := r.BeforeTool(call.Function.Name, event.Arguments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareToolCalls prepares the call at index and returns the index of the
+// first call it did not prepare. When the call opens a run of consecutive Task
+// calls, the whole run is prepared in call order and its valid calls are then
+// launched concurrently into pending, so no subagent starts before every
+// earlier call in the turn has been authorized.
+// Each launched goroutine is tracked by inflight so the run can wait for it.
+func (r *Runner) prepareToolCalls(
+	ctx context.Context,
+	result *RunResult,
+	calls []llm.ToolCall,
+	index int,
+	invalid map[int]string,
+	onToolCall func(ToolEvent) error,
+	pending map[int]*pendingToolCall,
+	inflight *sync.WaitGroup,
+) (int, error) {
+	end := index + 1
+	if r.MaxParallelTasks > 1 && calls[index].Function.Name == parallelToolName {
+		for end < len(calls) && calls[end].Function.Name == parallelToolName {
+			end++
+		}
+	}
+	var launch []int
+	for member := index; member < end; member++ {
+		_, badArguments := invalid[member]
+		if err := r.prepareToolCall(result, calls[member], badArguments, onToolCall); err != nil {
+			return end, err
+		}
+		if !badArguments {
+			launch = append(launch, member)
+		}
+	}
+	// With the budget spent, the Tasks are left to runToolCall, which refuses them.
+	if len(launch) > 1 {
+		if budget, ok := r.taskBudget(result, len(launch)); ok {
+			r.startParallelTasks(ctx, calls, launch, budget, pending, inflight)
+		}
+	}
+	return end, nil
+}
+
+// taskBudget returns the cap for each of count Tasks started now, so together
+// they stay within what the run may still spend, or zero without a budget.
+// ok is false when the run has a budget and none of it is left: a zero cap
+// would read as no cap and leave the subagent unlimited.
+func (r *Runner) taskBudget(result *RunResult, count int) (float64, bool) {
+	if r.MaxBudgetUSD <= 0 {
+		return 0, true
+	}
+	remaining := r.MaxBudgetUSD - result.CostUSD
+	if remaining <= 0 {
+		return 0, false
+	}
+	return remaining / float64(count), true
+}
+
+// startParallelTasks launches the prepared Task calls at indexes concurrently,
+// keyed by tool call index in pending. budget caps each subagent's spend so
+// the group together stays within the parent's remaining budget.
+func (r *Runner) startParallelTasks(ctx context.Context, calls []llm.ToolCall, indexes []int, budget float64, pending map[int]*pendingToolCall, inflight *sync.WaitGroup) {
+	// The semaphore bounds how many subagents talk to the gateway at once.
+	semaphore := make(chan struct{}, r.MaxParallelTasks)
+	for _, index := range indexes {
+		call := calls[index]
+		entry := &pendingToolCall{done: make(chan struct{})}
+		pending[index] = entry
+		inflight.Add(1)
+		go func() {
+			defer inflight.Done()
+			defer close(entry.done)
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				entry.err = ctx.Err()
+				return
+			}
+			defer func() { <-semaphore }()
+			// Each subagent receives its own copy of the tool context.
+			toolCtx := r.ToolContext.Clone()
+			toolCtx.TaskBudgetUSD = budget
+			entry.result, entry.err = r.runTool(ctx, call, toolCtx)
+		}()
+	}
+}
+
+// runToolCall returns a prefetched result when available or runs the tool inline.
+// Inline runs send their progress reports to progress and their live output to
+// output when those are set. An inline Task gets a copy of the tool context
+// capped at what the run may still spend, like a parallel one, and is refused
+// once the run has spent its budget.
+func (r *Runner) runToolCall(
+	ctx context.Context,
+	result *RunResult,
+	index int,
+	call llm.ToolCall,
+	pending map[int]*pendingToolCall,
+	progress func(tools.Progress),
+	output func(string),
+) (tools.ToolResult, error) {
+	r.Status.beginTool(call.Function.Name)
+	defer r.Status.endTool()
+	if entry, ok := pending[index]; ok {
+		select {
+		case <-entry.done:
+			return entry.result, entry.err
+		case <-ctx.Done():
+			return tools.ToolResult{}, ctx.Err()
+		}
+	}
+	toolCtx := r.ToolContext
+	if call.Function.Name == parallelToolName {
+		budget, ok := r.taskBudget(result, 1)
+		if !ok {
+			return tools.ToolResult{IsError: true, Content: fmt.Sprintf("Task not started: the run has spent its $%.4f budget.", r.MaxBudgetUSD)}, nil
+		}
+		toolCtx = r.ToolContext.Clone()
+		toolCtx.TaskBudgetUSD = budget
+	}
+	if progress != nil {
+		toolCtx.Progress = progress
+	}
+	if output != nil {
+		toolCtx.Output = output
+	}
+	return r.runTool(ctx, call, toolCtx)
+}
+
+// runTool runs a tool call that passed the workspace trust, deny rule, and policy checks.
+func (r *Runner) runTool(ctx context.Context, call llm.ToolCall, toolCtx tools.ToolContext) (tools.ToolResult, error) {
+	args := json.RawMessage(call.Function.Arguments)
+	if err := r.Permissions.CheckTrust(call.Function.Name); err != nil {
+		return tools.ToolResult{IsError: true, Content: err.Error()}, nil
+	}
+	if err := r.Permissions.CheckDeny(call.Function.Name, args); err != nil {
+		return tools.ToolResult{IsError: true, Content: err.Error()}, nil
+	}
+	if err := r.Permissions.Policy.Check(call.Function.Name, args, r.ToolContext.CWD); err != nil {
+		return tools.ToolResult{IsError: true, Content: err.Error()}, nil
+	}
+	return r.ToolRunner.Run(ctx, call.Function.Name, args, toolCtx)
+}
+
+// applyToolUsage rolls nested tool usage (such as Task subagents) into the run totals.
+func applyToolUsage(result *RunResult, toolResult tools.ToolResult) {
+	if result == nil || toolResult.Usage == nil {
+		return
+	}
+	result.CostUSD += toolResult.Usage.CostUSD
+	for model, usage := range toolResult.Usage.ModelUsage {
+		accumulateUsage(&result.TotalUsage, usage)
+		accumulateUsageMap(result.ModelUsage, model, usage)
+	}
+}
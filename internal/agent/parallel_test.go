@@ -0,0 +1,291 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// barrierTaskTool is a fake Task tool that only completes once every call is in flight.
+type barrierTaskTool struct {
+	// expected is the number of concurrent calls required to release the barrier.
+	expected int32
+	// arrived counts calls that reached the barrier.
+	arrived atomic.Int32
+	// release closes when all expected calls have arrived.
+	release chan struct{}
+	// once guards closing release.
+	once sync.Once
+}
+
+func (t *barrierTaskTool) Name() string           { return "Task" }
+func (t *barrierTaskTool) Description() string    { return "fake task" }
+func (t *barrierTaskTool) Schema() map[string]any { return map[string]any{"type": "object"} }
+
+// Run blocks until all expected calls arrive, proving they execute concurrently.
+func (t *barrierTaskTool) Run(ctx context.Context, input json.RawMessage, toolCtx tools.ToolContext) (tools.ToolResult, error) {
+	if t.arrived.Add(1) == t.expected {
+		t.once.Do(func() { close(t.release) })
+	}
+	select {
+	case <-t.release:
+	case <-time.After(2 * time.Second):
+		return tools.ToolResult{IsError: true, Content: "tasks did not run concurrently"}, nil
+	case <-ctx.Done():
+		return tools.ToolResult{}, ctx.Err()
+	}
+	return tools.ToolResult{
+		Content: string(input),
+		Usage: &tools.ToolUsage{
+			CostUSD:    0.25,
+			ModelUsage: map[string]llm.Usage{"sub-model": {PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}},
+		},
+	}, nil
+}
+
+// TestRunExecutesTaskCallsInParallel verifies Task calls overlap and roll usage into the parent.
+func TestRunExecutesTaskCallsInParallel(testingHandle *testing.T) {
+	// Arrange a gateway that requests two Task calls and then finishes.
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) == 1 {
+			_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","tool_calls":[`+
+				`{"id":"call_1","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"a\"}"}},`+
+				`{"id":"call_2","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"b\"}"}}]},`+
+				`"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`)
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"done"},"finish_reason":"stop"}],`+
+			`"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	taskTool := &barrierTaskTool{expected: 2, release: make(chan struct{})}
+	runner := &Runner{
+		Client:           openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:       tools.NewRunner([]tools.Tool{taskTool}),
+		Permissions:      tools.Permissions{Mode: tools.PermissionDefault},
+		MaxParallelTasks: 2,
+	}
+
+	// Act.
+	result, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "go"}}, "", "parent-model", true)
+
+	// Assert both tool results succeeded in call order and usage was rolled up.
+	testutil.RequireNoError(testingHandle, err, "run")
+	var toolResults []ToolEvent
+	for _, event := range result.Events {
+		if event.Type == "tool_result" {
+			toolResults = append(toolResults, event)
+		}
+	}
+	testutil.RequireEqual(testingHandle, len(toolResults), 2, "tool result count")
+	for index, event := range toolResults {
+		if event.IsError {
+			testingHandle.Fatalf("tool result %d failed: %s", index, event.Result)
+		}
+	}
+	testutil.RequireEqual(testingHandle, toolResults[0].ToolID, "call_1", "first tool result id")
+	testutil.RequireEqual(testingHandle, result.CostUSD, 0.5, "rolled-up cost")
+	testutil.RequireEqual(testingHandle, result.ModelUsage["sub-model"].TotalTokens, 10, "subagent usage")
+	testutil.RequireEqual(testingHandle, result.TotalUsage.TotalTokens, 14, "total usage")
+}
+
+// TestRunRollsTaskCostIntoBudget verifies subagent cost counts against the parent budget.
+func TestRunRollsTaskCostIntoBudget(testingHandle *testing.T) {
+	// Arrange a gateway that requests a single Task call.
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","tool_calls":[`+
+			`{"id":"call_1","type":"function","function":{"name":"Task","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`)
+	}))
+	defer server.Close()
+
+	taskTool := &barrierTaskTool{expected: 1, release: make(chan struct{})}
+	runner := &Runner{
+		Client:       openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:   tools.NewRunner([]tools.Tool{taskTool}),
+		MaxBudgetUSD: 0.1,
+	}
+
+	// Act.
+	_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "go"}}, "", "parent-model", true)
+
+	// Assert.
+	if err == nil || !errors.Is(err, ErrMaxBudget) {
+		testingHandle.Fatalf("expected budget error, got %v", err)
+	}
+}
+
+// recordingTool records each run and the Task budget it was given.
+type recordingTool struct {
+	// name is the tool name.
+	name string
+	// mu guards budgets.
+	mu sync.Mutex
+	// budgets holds the TaskBudgetUSD of each run.
+	budgets []float64
+	// cost is the subagent cost each run reports.
+	cost float64
+}
+
+func (t *recordingTool) Name() string           { return t.name }
+func (t *recordingTool) Description() string    { return "fake tool" }
+func (t *recordingTool) Schema() map[string]any { return map[string]any{"type": "object"} }
+
+// Run records the call and scribbles on the context's shared state, which
+// must not reach the parent.
+func (t *recordingTool) Run(ctx context.Context, input json.RawMessage, toolCtx tools.ToolContext) (tools.ToolResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budgets = append(t.budgets, toolCtx.TaskBudgetUSD)
+	for index := range toolCtx.TaskLineage {
+		toolCtx.TaskLineage[index] = 0
+	}
+	if toolCtx.Sandbox != nil {
+		toolCtx.Sandbox.Roots = append(toolCtx.Sandbox.Roots[:0], "/scribbled")
+	}
+	return tools.ToolResult{Content: "ok", Usage: &tools.ToolUsage{CostUSD: t.cost}}, nil
+}
+
+// taskTurnServer requests the given tool calls once and then finishes.
+func taskTurnServer(toolCalls string) *httptest.Server {
+	var requests atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) == 1 {
+			_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","tool_calls":[`+toolCalls+`]},"finish_reason":"tool_calls"}]}`)
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"done"},"finish_reason":"stop"}]}`)
+	}))
+}
+
+// TestRunStartsTasksAfterEarlierCallsAreAuthorized verifies a denied call
+// ends the turn before any Task later in it has started.
+func TestRunStartsTasksAfterEarlierCallsAreAuthorized(testingHandle *testing.T) {
+	// Arrange a turn that runs Bash before two Tasks, with Bash denied.
+	server := taskTurnServer(`{"id":"call_1","type":"function","function":{"name":"Bash","arguments":"{}"}},` +
+		`{"id":"call_2","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"a\"}"}},` +
+		`{"id":"call_3","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"b\"}"}}`)
+	defer server.Close()
+	bash := &recordingTool{name: "Bash"}
+	task := &recordingTool{name: "Task"}
+	runner := &Runner{
+		Client:           openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:       tools.NewRunner([]tools.Tool{bash, task}),
+		Permissions:      tools.Permissions{Mode: tools.PermissionDefault},
+		AuthorizeTool:    func(string, json.RawMessage) (bool, error) { return false, nil },
+		MaxParallelTasks: 2,
+	}
+
+	// Act.
+	_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "go"}}, "", "parent-model", true)
+
+	// Assert.
+	testutil.RequireTrue(testingHandle, err != nil, "denied call ends the run")
+	testutil.RequireEqual(testingHandle, len(task.budgets), 0, "no Task started")
+}
+
+// TestRunSplitsBudgetBetweenParallelTasks verifies parallel Tasks share the
+// remaining budget instead of each receiving all of it.
+func TestRunSplitsBudgetBetweenParallelTasks(testingHandle *testing.T) {
+	// Arrange.
+	server := taskTurnServer(`{"id":"call_1","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"a\"}"}},` +
+		`{"id":"call_2","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"b\"}"}}`)
+	defer server.Close()
+	task := &recordingTool{name: "Task"}
+	runner := &Runner{
+		Client:           openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:       tools.NewRunner([]tools.Tool{task}),
+		MaxBudgetUSD:     1,
+		MaxParallelTasks: 2,
+	}
+
+	// Act.
+	_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "go"}}, "", "parent-model", true)
+
+	// Assert.
+	testutil.RequireNoError(testingHandle, err, "run")
+	testutil.RequireEqual(testingHandle, len(task.budgets), 2, "both Tasks ran")
+	for _, budget := range task.budgets {
+		testutil.RequireEqual(testingHandle, budget, 0.5, "half the budget each")
+	}
+}
+
+// TestRunPassesRemainingBudgetToSerialTasks verifies Tasks run one at a time
+// are capped at what the run may still spend and get their own tool context.
+func TestRunPassesRemainingBudgetToSerialTasks(testingHandle *testing.T) {
+	// Arrange.
+	server := taskTurnServer(`{"id":"call_1","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"a\"}"}},` +
+		`{"id":"call_2","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"b\"}"}}`)
+	defer server.Close()
+	task := &recordingTool{name: "Task", cost: 0.25}
+	runner := &Runner{
+		Client:       openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:   tools.NewRunner([]tools.Tool{task}),
+		MaxBudgetUSD: 1,
+		ToolContext: tools.ToolContext{
+			Sandbox:     &tools.Sandbox{Roots: []string{"/workspace"}},
+			TaskLineage: []uint64{7},
+		},
+	}
+
+	// Act.
+	_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "go"}}, "", "parent-model", true)
+
+	// Assert.
+	testutil.RequireNoError(testingHandle, err, "run")
+	testutil.RequireEqual(testingHandle, task.budgets, []float64{1, 0.75}, "remaining budget per Task")
+	testutil.RequireEqual(testingHandle, runner.ToolContext.TaskLineage, []uint64{7}, "parent lineage untouched")
+	testutil.RequireEqual(testingHandle, runner.ToolContext.Sandbox.Roots, []string{"/workspace"}, "parent sandbox untouched")
+}
+
+// TestRunRefusesTasksOnceBudgetIsSpent verifies a run that has spent exactly
+// its budget starts no more Tasks, serial or parallel, rather than passing
+// them a zero cap that reads as no cap.
+func TestRunRefusesTasksOnceBudgetIsSpent(testingHandle *testing.T) {
+	for _, parallel := range []int{1, 2} {
+		// Arrange.
+		server := taskTurnServer(`{"id":"call_1","type":"function","function":{"name":"Spend","arguments":"{}"}},` +
+			`{"id":"call_2","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"a\"}"}},` +
+			`{"id":"call_3","type":"function","function":{"name":"Task","arguments":"{\"prompt\":\"b\"}"}}`)
+		spend := &recordingTool{name: "Spend", cost: 1}
+		task := &recordingTool{name: "Task"}
+		runner := &Runner{
+			Client:           openai.NewClient(server.URL, "", 5*time.Second),
+			ToolRunner:       tools.NewRunner([]tools.Tool{spend, task}),
+			MaxBudgetUSD:     1,
+			MaxParallelTasks: parallel,
+		}
+
+		// Act.
+		result, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "go"}}, "", "parent-model", true)
+		server.Close()
+
+		// Assert.
+		testutil.RequireNoError(testingHandle, err, "run")
+		testutil.RequireEqual(testingHandle, len(task.budgets), 0, "no Task started")
+		refused := 0
+		for _, message := range result.Messages {
+			if content, ok := message.Content.(string); ok && message.Role == "tool" && strings.HasPrefix(content, "Task not started: the run has spent its $1.0000 budget.") {
+				refused++
+			}
+		}
+		testutil.RequireEqual(testingHandle, refused, 2, "both Tasks refused")
+	}
+}
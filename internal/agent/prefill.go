@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// trailingPrefill reports whether messages end with an assistant message the
+// model should continue: a prefill with text and no tool calls.
+func trailingPrefill(messages []llm.Message) (string, bool) {
+	if len(messages) == 0 {
+		return "", false
+	}
+	last := messages[len(messages)-1]
+	if last.Role != "assistant" || len(last.ToolCalls) > 0 {
+		return "", false
+	}
+	text := llm.ContentText(last.Content)
+	return text, text != ""
+}
+
+// appendAssistantMessage records a model response. A response to a prefill
+// continues it, so the two become one assistant message with the prefill's
+// text in front; gateways that echo the prefill are not doubled.
+func appendAssistantMessage(result *RunResult, message llm.Message) {
+	if prefill, ok := trailingPrefill(result.Messages); ok {
+		text := llm.ContentText(message.Content)
+		if !strings.HasPrefix(text, prefill) {
+			text = prefill + text
+		}
+		message.Content = text
+		// Cap the slice so the caller's copy of the prefill is left alone.
+		last := len(result.Messages) - 1
+		result.Messages = append(result.Messages[:last:last], message)
+		result.Final = message
+		return
+	}
+	result.Messages = append(result.Messages, message)
+	result.Final = message
+}
@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultProgressInterval is how often heartbeats are sent when the runner
+// has no ProgressInterval.
+const DefaultProgressInterval = 5 * time.Second
+
+// ProgressPhase names what a run is waiting on.
+type ProgressPhase string
+
+const (
+	// ProgressWaiting means a request was sent and nothing has been generated
+	// yet, e.g. while the gateway queues it.
+	ProgressWaiting ProgressPhase = "waiting"
+	// ProgressStreaming means the model is generating the response.
+	ProgressStreaming ProgressPhase = "streaming"
+	// ProgressTool means a tool call is still running.
+	ProgressTool ProgressPhase = "tool"
+	// ProgressRetrying means a failed request or turn is being retried.
+	ProgressRetrying ProgressPhase = "retrying"
+)
+
+// ProgressEvent reports what a run is doing. Heartbeats are sent while a
+// request or tool call takes longer than the progress interval; retries are
+// reported as they happen.
+type ProgressEvent struct {
+	// Phase is what the run is waiting on.
+	Phase ProgressPhase
+	// Model is the model of the latest request.
+	Model string
+	// Turn is the 1-based turn of the run.
+	Turn int
+	// Tool names the running tool call in the tool phase.
+	Tool string
+	// Elapsed is how long the current phase has lasted.
+	Elapsed time.Duration
+	// OutputTokens estimates the tokens generated so far in the streaming phase.
+	OutputTokens int
+	// Attempt is the attempt about to start in the retrying phase.
+	Attempt int
+	// Reason says why a retry happens.
+	Reason string
+}
+
+// String renders the event for spinners, e.g. "Bash still running (45s)".
+func (e ProgressEvent) String() string {
+	elapsed := e.Elapsed.Round(time.Second)
+	switch e.Phase {
+	case ProgressWaiting:
+		return fmt.Sprintf("waiting for %s to respond (%s)", e.Model, elapsed)
+	case ProgressStreaming:
+		return fmt.Sprintf("%s is generating (~%d tokens, %s)", e.Model, e.OutputTokens, elapsed)
+	case ProgressTool:
+		return fmt.Sprintf("%s still running (%s)", e.Tool, elapsed)
+	case ProgressRetrying:
+		return fmt.Sprintf("retrying, attempt %d: %s", e.Attempt, e.Reason)
+	}
+	return string(e.Phase)
+}
+
+// Progress derives a heartbeat from the snapshot; ok is false when the run is idle.
+func (snapshot StatusSnapshot) Progress(now time.Time) (event ProgressEvent, ok bool) {
+	if snapshot.RunStarted.IsZero() {
+		return ProgressEvent{}, false
+	}
+	event = ProgressEvent{Model: snapshot.Model, Turn: snapshot.Turn}
+	switch {
+	case snapshot.Tool != "":
+		event.Phase = ProgressTool
+		event.Tool = snapshot.Tool
+		event.Elapsed = now.Sub(snapshot.ToolStarted)
+	case snapshot.RequestStarted.IsZero():
+		return ProgressEvent{}, false
+	case snapshot.FirstTokenAt.IsZero():
+		event.Phase = ProgressWaiting
+		event.Elapsed = now.Sub(snapshot.RequestStarted)
+	default:
+		event.Phase = ProgressStreaming
+		event.Elapsed = now.Sub(snapshot.RequestStarted)
+		event.OutputTokens = snapshot.StreamedChars / charsPerToken
+	}
+	return event, true
+}
+
+// progressInterval returns the configured heartbeat interval.
+func (r *Runner) progressInterval() time.Duration {
+	if r.ProgressInterval > 0 {
+		return r.ProgressInterval
+	}
+	return DefaultProgressInterval
+}
+
+// startHeartbeat sends a progress event to report every interval while the
+// current request or tool call has lasted at least one interval, so quick
+// steps stay quiet. It needs the runner's Status; the returned function stops
+// the heartbeat.
+func (r *Runner) startHeartbeat(ctx context.Context, report func(ProgressEvent)) func() {
+	if report == nil || r.Status == nil {
+		return func() {}
+	}
+	interval := r.progressInterval()
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if event, ok := r.Status.Snapshot().Progress(now); ok && event.Elapsed >= interval {
+					report(event)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
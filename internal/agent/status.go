@@ -0,0 +1,321 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+)
+
+// Status tracks what a runner is doing so a diagnostic snapshot can be taken
+// from another goroutine (e.g., on SIGUSR1). A nil *Status ignores updates.
+type Status struct {
+	// OnRequestDone, when set, receives the metrics of each successful API
+	// request. It is called outside the lock and must be set before the run.
+	OnRequestDone func(metrics RequestMetrics)
+	// mu guards every field below.
+	mu sync.Mutex
+	// snapshot holds the current state.
+	snapshot StatusSnapshot
+}
+
+// StatusSnapshot is a point-in-time copy of a runner's progress.
+type StatusSnapshot struct {
+	// Model is the model of the latest request.
+	Model string
+	// Turn is the 1-based turn of the current run.
+	Turn int
+	// RunStarted is when the current run began; zero when idle.
+	RunStarted time.Time
+	// RequestStarted is when the in-flight API request began; zero when none is in flight.
+	RequestStarted time.Time
+	// StreamChunks counts stream chunks received for the in-flight request.
+	StreamChunks int
+	// LastChunkAt is when the latest stream chunk arrived.
+	LastChunkAt time.Time
+	// FirstTokenAt is when the first generated text, thinking, or tool call
+	// argument arrived for the in-flight request; zero until then.
+	FirstTokenAt time.Time
+	// StreamedChars counts generated characters received for the in-flight request.
+	StreamedChars int
+	// LastRequest holds the metrics of the latest successful API request.
+	LastRequest RequestMetrics
+	// Tool names the in-flight tool call; empty when none.
+	Tool string
+	// ToolStarted is when the in-flight tool call began.
+	ToolStarted time.Time
+	// Usage sums token usage reported so far in the current run.
+	Usage llm.Usage
+	// APIRequests counts completed API requests in the current run.
+	APIRequests int
+	// LastAPIStatus describes the latest API result, e.g. "ok" or "error 429: rate limited".
+	LastAPIStatus string
+	// LastAPIAt is when the latest API request finished.
+	LastAPIAt time.Time
+}
+
+// charsPerToken estimates output tokens from streamed characters before the
+// gateway reports usage.
+const charsPerToken = 4
+
+// RequestMetrics measures the speed of one API request.
+type RequestMetrics struct {
+	// Model is the requested model.
+	Model string
+	// TimeToFirstToken is the wait for the first generated output; zero until
+	// it arrives and for non-streaming requests.
+	TimeToFirstToken time.Duration
+	// Latency is the time since the request was sent, or its total duration
+	// once it finished.
+	Latency time.Duration
+	// OutputTokens counts generated tokens.
+	OutputTokens int
+	// Estimated reports that OutputTokens was estimated from streamed
+	// characters because usage was not (yet) reported.
+	Estimated bool
+}
+
+// TokensPerSecond returns output throughput. Time spent waiting for the first
+// token is excluded when known, so slow queues do not hide fast generation.
+func (m RequestMetrics) TokensPerSecond() float64 {
+	generating := m.Latency - m.TimeToFirstToken
+	if generating <= 0 || m.OutputTokens == 0 {
+		return 0
+	}
+	return float64(m.OutputTokens) / generating.Seconds()
+}
+
+// String renders the metrics, e.g. "gpt-4o 2.4s, first token 610ms, 48.2 tok/s (87 tokens)".
+func (m RequestMetrics) String() string {
+	var builder strings.Builder
+	builder.WriteString(m.Model)
+	if builder.Len() > 0 {
+		builder.WriteString(" ")
+	}
+	builder.WriteString(formatMetricDuration(m.Latency))
+	if m.TimeToFirstToken > 0 {
+		builder.WriteString(", first token " + formatMetricDuration(m.TimeToFirstToken))
+	}
+	approx := ""
+	if m.Estimated {
+		approx = "~"
+	}
+	if rate := m.TokensPerSecond(); rate > 0 {
+		fmt.Fprintf(&builder, ", %s%.1f tok/s", approx, rate)
+	}
+	fmt.Fprintf(&builder, " (%s%d tokens)", approx, m.OutputTokens)
+	return builder.String()
+}
+
+// formatMetricDuration shows sub-second durations in milliseconds and longer
+// ones in tenths of a second.
+func formatMetricDuration(value time.Duration) string {
+	if value < time.Second {
+		return value.Round(time.Millisecond).String()
+	}
+	return fmt.Sprintf("%.1fs", value.Seconds())
+}
+
+// Metrics returns live metrics for the in-flight request, or the latest
+// finished request when none is in flight. ok is false before any request.
+func (snapshot StatusSnapshot) Metrics(now time.Time) (metrics RequestMetrics, ok bool) {
+	if snapshot.RequestStarted.IsZero() {
+		return snapshot.LastRequest, snapshot.LastRequest.Latency > 0
+	}
+	metrics = RequestMetrics{
+		Model:        snapshot.Model,
+		Latency:      now.Sub(snapshot.RequestStarted),
+		OutputTokens: snapshot.StreamedChars / charsPerToken,
+		Estimated:    true,
+	}
+	if !snapshot.FirstTokenAt.IsZero() {
+		metrics.TimeToFirstToken = snapshot.FirstTokenAt.Sub(snapshot.RequestStarted)
+	}
+	return metrics, true
+}
+
+// NewStatus returns an idle status tracker.
+func NewStatus() *Status {
+	return &Status{}
+}
+
+// Snapshot returns a copy of the current state.
+func (s *Status) Snapshot() StatusSnapshot {
+	if s == nil {
+		return StatusSnapshot{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot
+}
+
+// update applies fn under the lock.
+func (s *Status) update(fn func(snapshot *StatusSnapshot)) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.snapshot)
+}
+
+// beginRun resets per-run counters.
+func (s *Status) beginRun() {
+	s.update(func(snapshot *StatusSnapshot) {
+		*snapshot = StatusSnapshot{Model: snapshot.Model, LastAPIStatus: snapshot.LastAPIStatus, LastAPIAt: snapshot.LastAPIAt, LastRequest: snapshot.LastRequest, RunStarted: time.Now()}
+	})
+}
+
+// endRun marks the runner idle.
+func (s *Status) endRun() {
+	s.update(func(snapshot *StatusSnapshot) {
+		snapshot.RunStarted = time.Time{}
+		snapshot.RequestStarted = time.Time{}
+		snapshot.Tool = ""
+	})
+}
+
+// beginRequest records an API request starting for the given turn.
+func (s *Status) beginRequest(model string, turn int) {
+	s.update(func(snapshot *StatusSnapshot) {
+		snapshot.Model = model
+		snapshot.Turn = turn
+		snapshot.RequestStarted = time.Now()
+		snapshot.StreamChunks = 0
+		snapshot.LastChunkAt = time.Time{}
+		snapshot.FirstTokenAt = time.Time{}
+		snapshot.StreamedChars = 0
+	})
+}
+
+// streamChunk records a stream chunk for the in-flight request.
+func (s *Status) streamChunk(event openai.StreamResponse) {
+	chars := streamedChars(event)
+	s.update(func(snapshot *StatusSnapshot) {
+		snapshot.StreamChunks++
+		snapshot.LastChunkAt = time.Now()
+		if chars > 0 && snapshot.FirstTokenAt.IsZero() {
+			snapshot.FirstTokenAt = snapshot.LastChunkAt
+		}
+		snapshot.StreamedChars += chars
+	})
+}
+
+// streamedChars counts the generated characters in a stream chunk.
+func streamedChars(event openai.StreamResponse) int {
+	chars := 0
+	for _, choice := range event.Choices {
+		chars += len(choice.Delta.Content) + len(choice.Delta.ReasoningText())
+		for _, call := range choice.Delta.ToolCalls {
+			chars += len(call.Function.Arguments)
+		}
+	}
+	return chars
+}
+
+// endRequest records the outcome of the in-flight API request. Successful
+// requests become LastRequest and are reported to OnRequestDone.
+func (s *Status) endRequest(err error, usage llm.Usage) {
+	if s == nil {
+		return
+	}
+	var finished RequestMetrics
+	s.update(func(snapshot *StatusSnapshot) {
+		now := time.Now()
+		if err == nil {
+			finished, _ = snapshot.Metrics(now)
+			if usage.CompletionTokens > 0 {
+				finished.OutputTokens = usage.CompletionTokens
+				finished.Estimated = false
+			}
+			snapshot.LastRequest = finished
+		}
+		snapshot.RequestStarted = time.Time{}
+		snapshot.LastAPIAt = now
+		snapshot.APIRequests++
+		accumulateUsage(&snapshot.Usage, usage)
+		snapshot.LastAPIStatus = apiStatusText(err)
+	})
+	if err == nil && s.OnRequestDone != nil {
+		s.OnRequestDone(finished)
+	}
+}
+
+// beginTool records a tool call starting.
+func (s *Status) beginTool(name string) {
+	s.update(func(snapshot *StatusSnapshot) {
+		snapshot.Tool = name
+		snapshot.ToolStarted = time.Now()
+	})
+}
+
+// endTool clears the in-flight tool call.
+func (s *Status) endTool() {
+	s.update(func(snapshot *StatusSnapshot) {
+		snapshot.Tool = ""
+		snapshot.ToolStarted = time.Time{}
+	})
+}
+
+// apiStatusText summarizes an API result.
+func apiStatusText(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Sprintf("error %d: %s", apiErr.StatusCode, truncateStatusText(strings.TrimSpace(apiErr.Body)))
+	}
+	return "error: " + truncateStatusText(err.Error())
+}
+
+// truncateStatusText keeps status lines short when gateways return large error bodies.
+func truncateStatusText(text string) string {
+	const maxStatusText = 200
+	if len(text) <= maxStatusText {
+		return text
+	}
+	return text[:maxStatusText] + "…"
+}
+
+// Format renders the snapshot as indented "key: value" lines relative to now.
+func (snapshot StatusSnapshot) Format(now time.Time) string {
+	var builder strings.Builder
+	since := func(value time.Time) string {
+		return now.Sub(value).Round(time.Millisecond).String()
+	}
+	if snapshot.RunStarted.IsZero() {
+		builder.WriteString("  run: idle\n")
+	} else {
+		fmt.Fprintf(&builder, "  run: turn %d, running for %s\n", snapshot.Turn, since(snapshot.RunStarted))
+	}
+	if snapshot.Model != "" {
+		fmt.Fprintf(&builder, "  model: %s\n", snapshot.Model)
+	}
+	if snapshot.RequestStarted.IsZero() {
+		builder.WriteString("  api request: none in flight\n")
+	} else {
+		fmt.Fprintf(&builder, "  api request: in flight for %s, %d stream chunk(s)", since(snapshot.RequestStarted), snapshot.StreamChunks)
+		if !snapshot.LastChunkAt.IsZero() {
+			fmt.Fprintf(&builder, ", last %s ago", since(snapshot.LastChunkAt))
+		}
+		builder.WriteString("\n")
+	}
+	if metrics, ok := snapshot.Metrics(now); ok {
+		fmt.Fprintf(&builder, "  throughput: %s\n", metrics)
+	}
+	if snapshot.Tool == "" {
+		builder.WriteString("  tool: none in flight\n")
+	} else {
+		fmt.Fprintf(&builder, "  tool: %s, running for %s\n", snapshot.Tool, since(snapshot.ToolStarted))
+	}
+	fmt.Fprintf(&builder, "  tokens so far: %d in, %d out (%d request(s))\n", snapshot.Usage.PromptTokens, snapshot.Usage.CompletionTokens, snapshot.APIRequests)
+	if snapshot.LastAPIStatus != "" {
+		fmt.Fprintf(&builder, "  last api status: %s, %s ago\n", snapshot.LastAPIStatus, since(snapshot.LastAPIAt))
+	}
+	return builder.String()
+}
@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// snapshotTool captures the runner status while it runs.
+type snapshotTool struct {
+	status   *Status
+	snapshot StatusSnapshot
+}
+
+func (t *snapshotTool) Name() string           { return "Probe" }
+func (t *snapshotTool) Description() string    { return "probe" }
+func (t *snapshotTool) Schema() map[string]any { return map[string]any{"type": "object"} }
+func (t *snapshotTool) Run(context.Context, json.RawMessage, tools.ToolContext) (tools.ToolResult, error) {
+	t.snapshot = t.status.Snapshot()
+	return tools.ToolResult{Content: "ok"}, nil
+}
+
+// TestStatusTracksRunProgress verifies the status reflects the in-flight tool, usage, and API results.
+func TestStatusTracksRunProgress(testingHandle *testing.T) {
+	// Arrange a gateway that calls the probe tool, then answers.
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		calls++
+		responseWriter.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call-1","type":"function","function":{"name":"Probe","arguments":"{}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":10,"completion_tokens":2}}`)
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":"done"},"finish_reason":"stop"}],"usage":{"prompt_tokens":15,"completion_tokens":3}}`)
+	}))
+	defer server.Close()
+	status := NewStatus()
+	probe := &snapshotTool{status: status}
+	runner := &Runner{
+		Client:      openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:  tools.NewRunner([]tools.Tool{probe}),
+		Permissions: tools.Permissions{Mode: tools.PermissionBypass},
+		Status:      status,
+	}
+
+	// Act.
+	_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "probe"}}, "", "model", true)
+
+	// Assert the mid-run snapshot and the idle state afterwards.
+	testutil.RequireNoError(testingHandle, err, "run")
+	testutil.RequireEqual(testingHandle, probe.snapshot.Tool, "Probe", "tool in flight")
+	testutil.RequireEqual(testingHandle, probe.snapshot.Turn, 1, "turn")
+	testutil.RequireEqual(testingHandle, probe.snapshot.Usage.PromptTokens, 10, "tokens so far")
+	testutil.RequireEqual(testingHandle, probe.snapshot.LastAPIStatus, "ok", "last api status")
+	testutil.RequireTrue(testingHandle, strings.Contains(probe.snapshot.Format(time.Now()), "tool: Probe, running for"), "formatted tool line")
+	final := status.Snapshot()
+	testutil.RequireTrue(testingHandle, final.RunStarted.IsZero() && final.Tool == "", "idle after run")
+	testutil.RequireEqual(testingHandle, final.Usage.CompletionTokens, 5, "run usage")
+	testutil.RequireEqual(testingHandle, final.APIRequests, 2, "api requests")
+}
+
+// TestStatusRecordsAPIErrors verifies failed requests show their status code.
+func TestStatusRecordsAPIErrors(testingHandle *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		http.Error(responseWriter, `{"error":{"message":"slow down"}}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	runner := &Runner{Client: openai.NewClient(server.URL, "", 5*time.Second), Status: NewStatus()}
+
+	_, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "hi"}}, "", "model", false)
+
+	testutil.RequireTrue(testingHandle, err != nil, "run fails")
+	snapshot := runner.Status.Snapshot()
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(snapshot.LastAPIStatus, "error 429: "), "status: "+snapshot.LastAPIStatus)
+	testutil.RequireTrue(testingHandle, strings.Contains(snapshot.Format(time.Now()), "run: idle"), "idle after failure")
+}
+
+// TestStatusMeasuresStreamingThroughput verifies time to first token and
+// throughput are measured per request and reported when it finishes.
+func TestStatusMeasuresStreamingThroughput(testingHandle *testing.T) {
+	// Arrange a gateway that waits before streaming its answer.
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		responseWriter.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hello there\"},\"finish_reason\":\"stop\"}]}\n\n")
+		_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":4,\"completion_tokens\":20}}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+	var reported []RequestMetrics
+	status := NewStatus()
+	status.OnRequestDone = func(metrics RequestMetrics) {
+		reported = append(reported, metrics)
+	}
+	runner := &Runner{Client: openai.NewClient(server.URL, "", 5*time.Second), Status: status, MaxTurns: 1}
+
+	// Act.
+	_, err := runner.RunStream(context.Background(), []llm.Message{{Role: "user", Content: "hi"}}, "", "model", false, nil)
+
+	// Assert the reported metrics use gateway usage and exclude the wait.
+	testutil.RequireNoError(testingHandle, err, "run")
+	testutil.RequireEqual(testingHandle, len(reported), 1, "reports")
+	metrics := reported[0]
+	testutil.RequireEqual(testingHandle, metrics.Model, "model", "model")
+	testutil.RequireEqual(testingHandle, metrics.OutputTokens, 20, "output tokens")
+	testutil.RequireTrue(testingHandle, !metrics.Estimated, "usage is exact")
+	testutil.RequireTrue(testingHandle, metrics.TimeToFirstToken >= 50*time.Millisecond, fmt.Sprintf("ttft %s", metrics.TimeToFirstToken))
+	testutil.RequireTrue(testingHandle, metrics.Latency >= metrics.TimeToFirstToken, "latency covers ttft")
+	last, ok := status.Snapshot().Metrics(time.Now())
+	testutil.RequireTrue(testingHandle, ok, "last request kept")
+	testutil.RequireEqual(testingHandle, last, metrics, "last request")
+}
+
+// TestRunStreamRecordsTurnMetrics verifies each turn reports its wall time,
+// API latency, and time to first token.
+func TestRunStreamRecordsTurnMetrics(testingHandle *testing.T) {
+	// Arrange a gateway that calls the probe tool, then answers after a wait.
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		calls++
+		responseWriter.Header().Set("Content-Type", "text/event-stream")
+		if calls == 1 {
+			_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call-1\",\"type\":\"function\",\"function\":{\"name\":\"Probe\",\"arguments\":\"{}\"}}]},\"finish_reason\":\"tool_calls\"}]}\n\ndata: [DONE]\n\n")
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"done\"},\"finish_reason\":\"stop\"}]}\n\n")
+		_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":4,\"completion_tokens\":7}}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+	runner := &Runner{
+		Client:      openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:  tools.NewRunner([]tools.Tool{&snapshotTool{}}),
+		Permissions: tools.Permissions{Mode: tools.PermissionBypass},
+	}
+
+	// Act.
+	result, err := runner.RunStream(context.Background(), []llm.Message{{Role: "user", Content: "probe"}}, "", "model", true, nil)
+
+	// Assert.
+	testutil.RequireNoError(testingHandle, err, "run")
+	testutil.RequireEqual(testingHandle, len(result.Turns), 2, "turns")
+	var wall time.Duration
+	for index, turn := range result.Turns {
+		testutil.RequireEqual(testingHandle, turn.Model, "model", "model")
+		testutil.RequireTrue(testingHandle, turn.TimeToFirstToken > 0 && turn.TimeToFirstToken <= turn.APILatency, fmt.Sprintf("turn %d ttft %s within latency %s", index, turn.TimeToFirstToken, turn.APILatency))
+		testutil.RequireTrue(testingHandle, turn.Duration >= turn.APILatency, fmt.Sprintf("turn %d wall time %s covers latency %s", index, turn.Duration, turn.APILatency))
+		wall += turn.Duration
+	}
+	testutil.RequireTrue(testingHandle, result.Turns[1].TimeToFirstToken >= 20*time.Millisecond, "second turn waited for its first token")
+	testutil.RequireEqual(testingHandle, result.Turns[1].OutputTokens, 7, "output tokens")
+	testutil.RequireTrue(testingHandle, wall <= result.Duration, "turns fit in the run")
+}
+
+// TestRequestMetricsString verifies throughput excludes the first-token wait.
+func TestRequestMetricsString(testingHandle *testing.T) {
+	metrics := RequestMetrics{Model: "gpt", TimeToFirstToken: 500 * time.Millisecond, Latency: 2500 * time.Millisecond, OutputTokens: 100}
+
+	testutil.RequireEqual(testingHandle, metrics.TokensPerSecond(), 50.0, "tokens per second")
+	testutil.RequireEqual(testingHandle, metrics.String(), "gpt 2.5s, first token 500ms, 50.0 tok/s (100 tokens)", "exact")
+	metrics.Estimated = true
+	testutil.RequireEqual(testingHandle, metrics.String(), "gpt 2.5s, first token 500ms, ~50.0 tok/s (~100 tokens)", "estimated")
+}
+
+// slowTool sleeps long enough for heartbeats to fire.
+type slowTool struct{}
+
+func (slowTool) Name() string           { return "Slow" }
+func (slowTool) Description() string    { return "slow" }
+func (slowTool) Schema() map[string]any { return map[string]any{"type": "object"} }
+func (slowTool) Run(context.Context, json.RawMessage, tools.ToolContext) (tools.ToolResult, error) {
+	time.Sleep(100 * time.Millisecond)
+	return tools.ToolResult{Content: "ok"}, nil
+}
+
+// TestRunStreamSendsHeartbeats verifies long waits for the gateway and long
+// tool calls are reported as progress events, and quick steps are not.
+func TestRunStreamSendsHeartbeats(testingHandle *testing.T) {
+	// Arrange a gateway that is slow to start the first answer, which calls the slow tool.
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		calls++
+		responseWriter.Header().Set("Content-Type", "text/event-stream")
+		if calls == 1 {
+			time.Sleep(100 * time.Millisecond)
+			_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call-1\",\"type\":\"function\",\"function\":{\"name\":\"Slow\",\"arguments\":\"{}\"}}]},\"finish_reason\":\"tool_calls\"}]}\n\ndata: [DONE]\n\n")
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"done\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+	runner := &Runner{
+		Client:           openai.NewClient(server.URL, "", 5*time.Second),
+		ToolRunner:       tools.NewRunner([]tools.Tool{slowTool{}}),
+		Permissions:      tools.Permissions{Mode: tools.PermissionBypass},
+		Status:           NewStatus(),
+		ProgressInterval: 20 * time.Millisecond,
+	}
+	var mu sync.Mutex
+	phases := map[ProgressPhase]ProgressEvent{}
+	callbacks := &StreamCallbacks{OnProgress: func(event ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		phases[event.Phase] = event
+	}}
+
+	// Act.
+	_, err := runner.RunStream(context.Background(), []llm.Message{{Role: "user", Content: "go"}}, "", "model", true, callbacks)
+
+	// Assert.
+	testutil.RequireNoError(testingHandle, err, "run")
+	mu.Lock()
+	defer mu.Unlock()
+	waiting, ok := phases[ProgressWaiting]
+	testutil.RequireTrue(testingHandle, ok, "waiting heartbeat")
+	testutil.RequireEqual(testingHandle, waiting.Model, "model", "model")
+	testutil.RequireTrue(testingHandle, waiting.Elapsed >= 20*time.Millisecond, fmt.Sprintf("elapsed %s", waiting.Elapsed))
+	tool, ok := phases[ProgressTool]
+	testutil.RequireTrue(testingHandle, ok, "tool heartbeat")
+	testutil.RequireEqual(testingHandle, tool.Tool, "Slow", "tool")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(tool.String(), "Slow still running ("), tool.String())
+	_, ok = phases[ProgressStreaming]
+	testutil.RequireTrue(testingHandle, !ok, "instant answers send no heartbeat")
+}
@@ -2,10 +2,12 @@ package agent
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/openclaude/openclaude/internal/llm"
 	"github.com/openclaude/openclaude/internal/llm/openai"
 	"github.com/openclaude/openclaude/internal/tools"
 )
@@ -18,18 +20,30 @@ type StreamCallbacks struct {
 	OnStreamEvent func(event openai.StreamResponse) error
 	// OnToolCall fires when a tool call is issued by the model.
 	OnToolCall func(event ToolEvent) error
+	// OnToolProgress receives progress reported by a running tool call (rate-limited by the tool).
+	OnToolProgress func(event ToolEvent, progress tools.Progress)
+	// OnToolOutput receives output lines from a running tool call as they are
+	// produced (batched by the tool); the tool result still carries all of it.
+	OnToolOutput func(event ToolEvent, text string)
+	// OnContextTrimmed fires when old tool results were dropped after a context
+	// overflow, before the failed call is retried.
+	OnContextTrimmed func(dropped []DroppedToolResult)
+	// OnProgress receives heartbeats while a request or tool call runs longer
+	// than the runner's progress interval, and retry notices. Heartbeats come
+	// from another goroutine and need the runner's Status.
+	OnProgress func(event ProgressEvent)
 	// OnStreamComplete fires after the assistant message is assembled.
 	OnStreamComplete func(summary StreamSummary) error
 	// OnToolResult fires after a tool result is appended to messages.
-	OnToolResult func(event ToolEvent, message openai.Message) error
+	OnToolResult func(event ToolEvent, message llm.Message) error
 }
 
 // StreamSummary captures metadata for a completed streaming response.
 type StreamSummary struct {
 	// Message is the completed assistant message.
-	Message openai.Message
+	Message llm.Message
 	// Usage reports token usage when available.
-	Usage openai.Usage
+	Usage llm.Usage
 	// HasUsage reports whether Usage was populated.
 	HasUsage bool
 	// FinishReason is the OpenAI finish reason.
@@ -39,9 +53,26 @@ type StreamSummary struct {
 }
 
 // RunStream executes a single user turn using streaming responses.
+// Like Run, it returns the partial result with a *tools.UserInputRequiredError
+// when a tool pauses for user input.
 func (r *Runner) RunStream(
 	ctx context.Context,
-	messages []openai.Message,
+	messages []llm.Message,
+	systemPrompt string,
+	model string,
+	toolsEnabled bool,
+	callbacks *StreamCallbacks,
+) (*RunResult, error) {
+	ctx, cancel := r.turnContext(ctx)
+	defer cancel()
+	result, err := r.runStream(ctx, messages, systemPrompt, model, toolsEnabled, callbacks)
+	return result, turnError(ctx, err)
+}
+
+// runStream is RunStream without the turn deadline.
+func (r *Runner) runStream(
+	ctx context.Context,
+	messages []llm.Message,
 	systemPrompt string,
 	model string,
 	toolsEnabled bool,
@@ -55,30 +86,56 @@ func (r *Runner) RunStream(
 		r.MaxTurns = 8
 	}
 
+	// Cancel any in-flight parallel subagents when the run exits early, then wait
+	// for them so no subagent keeps writing task records after the run returns.
+	var inflight sync.WaitGroup
+	defer inflight.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Prepend a system prompt if provided.
 	if systemPrompt != "" {
 		messages = prependSystem(messages, systemPrompt)
 	}
+	// Mask credentials in prompts before they are sent or saved.
+	messages = r.Redactor.Messages(messages)
 
 	result := &RunResult{
 		Messages:   messages,
-		ModelUsage: map[string]openai.Usage{},
+		ModelUsage: map[string]llm.Usage{},
 	}
 
 	startTime := time.Now()
+	r.recordUserPrompt(messages)
+	r.Status.beginRun()
+	defer r.Status.endRun()
+	var onProgress func(ProgressEvent)
+	if callbacks != nil {
+		onProgress = callbacks.OnProgress
+	}
+	defer r.startHeartbeat(ctx, onProgress)()
 
+	recovered := false
+	invalidStreak := 0
+	stopHookActive := false
 	for turn := 0; turn < r.MaxTurns; turn++ {
 		req := &openai.ChatRequest{
-			Model:    model,
-			Messages: result.Messages,
+			Model:     model,
+			Messages:  result.Messages,
+			MaxTokens: r.maxOutputTokens(model),
 			StreamOptions: &openai.StreamOptions{
 				IncludeUsage: true,
 			},
 		}
+		r.applyRequestParams(req)
+		r.applyThinking(req, model)
 		if toolsEnabled && r.ToolRunner != nil {
 			req.Tools = r.ToolRunner.ToolSpecs()
 			req.ToolChoice = "auto"
 		}
+		if toolsEnabled {
+			req.ServerTools = r.ServerTools
+		}
 
 		if callbacks != nil && callbacks.OnStreamStart != nil {
 			if err := callbacks.OnStreamStart(model); err != nil {
@@ -88,7 +145,13 @@ func (r *Runner) RunStream(
 
 		accumulator := openai.NewStreamAccumulator()
 		callStart := time.Now()
+		var firstToken time.Duration
+		r.Status.beginRequest(model, turn+1)
 		_, err := r.Client.ChatCompletionsStream(ctx, req, func(event openai.StreamResponse) error {
+			r.Status.streamChunk(event)
+			if firstToken == 0 && streamedChars(event) > 0 {
+				firstToken = time.Since(callStart)
+			}
 			if err := accumulator.Apply(event); err != nil {
 				return fmt.Errorf("apply stream delta: %w", err)
 			}
@@ -99,31 +162,54 @@ func (r *Runner) RunStream(
 			}
 			return nil
 		})
-		result.APIDuration += time.Since(callStart)
+		callDuration := time.Since(callStart)
+		result.APIDuration += callDuration
 		if err != nil {
-			return nil, fmt.Errorf("stream request: %w", err)
+			r.Status.endRequest(err, llm.Usage{})
+			if r.Recorder != nil {
+				r.Recorder.RecordAPIError(model, err, callDuration)
+			}
+			// Retry the call once with old tool results dropped when the prompt overflows.
+			if !recovered && r.recoverContextOverflow(ctx, err, result) {
+				recovered = true
+				if callbacks != nil && callbacks.OnContextTrimmed != nil {
+					callbacks.OnContextTrimmed(result.DroppedToolResults)
+				}
+				if onProgress != nil {
+					onProgress(ProgressEvent{Phase: ProgressRetrying, Model: model, Turn: turn + 1, Attempt: 2, Reason: "context window full, old tool results dropped"})
+				}
+				turn--
+				continue
+			}
+			return nil, fmt.Errorf("stream request: %w", contextOverflowError(err, result))
 		}
 
 		message := accumulator.Message()
+		invalid := repairToolArguments(&message)
 		usage, hasUsage := accumulator.Usage()
+		r.Status.endRequest(nil, usage)
+		result.recordTurn(TurnMetrics{Model: model, APILatency: callDuration, TimeToFirstToken: firstToken, OutputTokens: usage.CompletionTokens}, callStart)
 
 		result.Usage = usage
 		if hasUsage {
 			accumulateUsage(&result.TotalUsage, usage)
 			accumulateUsageMap(result.ModelUsage, model, usage)
 		}
-		result.Messages = append(result.Messages, message)
-		result.Final = message
-		result.CostUSD += estimateCost(model, usage, r.Pricing)
+		appendAssistantMessage(result, message)
+		callCost := EstimateCost(model, usage, r.Pricing)
+		result.CostUSD += callCost
+		if r.Recorder != nil {
+			r.Recorder.RecordAPIRequest(model, usage, callCost, callDuration)
+		}
 		result.NumTurns++
 		if r.MaxBudgetUSD > 0 && result.CostUSD > r.MaxBudgetUSD {
-			result.Duration = time.Since(startTime)
+			result.finish(startTime)
 			return nil, fmt.Errorf("%w: %.4f > %.4f", ErrMaxBudget, result.CostUSD, r.MaxBudgetUSD)
 		}
 
 		if callbacks != nil && callbacks.OnStreamComplete != nil {
 			if err := callbacks.OnStreamComplete(StreamSummary{
-				Message:      message,
+				Message:      result.Final,
 				Usage:        usage,
 				HasUsage:     hasUsage,
 				FinishReason: accumulator.FinishReason(),
@@ -133,64 +219,87 @@ func (r *Runner) RunStream(
 			}
 		}
 
-		// If no tool calls are requested, return the assistant response.
+		// If no tool calls are requested, return the assistant response unless
+		// a Stop hook sends the model back to work.
 		if len(message.ToolCalls) == 0 || !toolsEnabled || r.ToolRunner == nil {
-			result.Duration = time.Since(startTime)
+			if r.continueAfterStop(ctx, result, &stopHookActive) {
+				continue
+			}
+			result.finish(startTime)
 			return result, nil
 		}
 
-		for _, call := range message.ToolCalls {
-			args := json.RawMessage(call.Function.Arguments)
-			event := ToolEvent{
-				Type:      "tool_call",
-				ToolName:  call.Function.Name,
-				ToolID:    call.ID,
-				Arguments: args,
-			}
-			result.Events = append(result.Events, event)
+		if err := r.checkArgumentRetries(invalid, &invalidStreak); err != nil {
+			result.finish(startTime)
+			return nil, err
+		}
+		if len(invalid) > 0 && onProgress != nil {
+			onProgress(ProgressEvent{Phase: ProgressRetrying, Model: model, Turn: turn + 1, Attempt: invalidStreak + 1, Reason: "tool arguments could not be parsed"})
+		}
 
-			if callbacks != nil && callbacks.OnToolCall != nil {
-				if err := callbacks.OnToolCall(event); err != nil {
-					return nil, fmt.Errorf("tool call callback: %w", err)
+		// Checks run in call order; a run of Task calls is checked together and
+		// then started concurrently.
+		var onToolCall func(ToolEvent) error
+		if callbacks != nil {
+			onToolCall = callbacks.OnToolCall
+		}
+		pending := map[int]*pendingToolCall{}
+		prepared := 0
+		var images []llm.ContentPart
+		for index, call := range message.ToolCalls {
+			if index >= prepared {
+				next, err := r.prepareToolCalls(ctx, result, message.ToolCalls, index, invalid, onToolCall, pending, &inflight)
+				if err != nil {
+					return nil, err
 				}
+				prepared = next
 			}
+			event := toolCallEvent(call)
 
-			// Respect session-level plan mode markers before executing tools.
-			if tools.IsPlanMode(r.ToolContext.Store, r.ToolContext.SessionID) && call.Function.Name != "ExitPlanMode" {
-				return nil, ErrPlanMode
-			}
-
-			// Plan mode must not execute any tools.
-			if r.Permissions.Mode == tools.PermissionPlan {
-				return nil, ErrPlanMode
-			}
+			// Unparseable arguments go back to the model without running anything.
+			problem, badArguments := invalid[index]
 
-			// If configured, ask for user permission before invoking tools.
-			if r.AuthorizeTool != nil && r.Permissions.ShouldPrompt(call.Function.Name) {
-				allowed, err := r.AuthorizeTool(call.Function.Name, args)
-				if err != nil {
-					return nil, fmt.Errorf("authorize tool %s: %w", call.Function.Name, err)
+			toolStart := time.Now()
+			var progress func(tools.Progress)
+			if callbacks != nil && callbacks.OnToolProgress != nil {
+				progress = func(update tools.Progress) {
+					callbacks.OnToolProgress(event, update)
 				}
-				if !allowed {
-					return nil, fmt.Errorf("%w: %s", ErrToolDenied, call.Function.Name)
+			}
+			var output func(string)
+			if callbacks != nil && callbacks.OnToolOutput != nil {
+				output = func(text string) {
+					callbacks.OnToolOutput(event, text)
 				}
 			}
-
-			toolResult, err := r.ToolRunner.Run(ctx, call.Function.Name, args, r.ToolContext)
+			toolResult := tools.ToolResult{IsError: true, Content: problem}
+			var err error
+			if !badArguments {
+				toolResult, err = r.runToolCall(ctx, result, index, call, pending, progress, output)
+			}
+			// Pause on questions that need out-of-band answers; the caller persists
+			// the partial result and the answer arrives on resume.
+			var inputErr *tools.UserInputRequiredError
+			if errors.As(err, &inputErr) {
+				inputErr.Request.ToolUseID = call.ID
+				result.finish(startTime)
+				return result, inputErr
+			}
 			if err != nil {
 				toolResult = tools.ToolResult{IsError: true, Content: err.Error()}
 			}
-
-			resultEvent := ToolEvent{
-				Type:     "tool_result",
-				ToolName: call.Function.Name,
-				ToolID:   call.ID,
-				Result:   toolResult.Content,
-				IsError:  toolResult.IsError,
+			r.recordToolResult(call.Function.Name, toolResult, toolStart)
+			applyToolUsage(result, toolResult)
+			images = append(images, toolResult.Images...)
+			if r.MaxBudgetUSD > 0 && result.CostUSD > r.MaxBudgetUSD {
+				result.finish(startTime)
+				return nil, fmt.Errorf("%w: %.4f > %.4f", ErrMaxBudget, result.CostUSD, r.MaxBudgetUSD)
 			}
+
+			resultEvent := toolResultEvent(call, toolResult, toolStart)
 			result.Events = append(result.Events, resultEvent)
 
-			toolMessage := openai.Message{
+			toolMessage := llm.Message{
 				Role:       "tool",
 				ToolCallID: call.ID,
 				Content:    toolResult.Content,
@@ -202,8 +311,11 @@ func (r *Runner) RunStream(
 				}
 			}
 		}
+		if len(images) > 0 {
+			result.Messages = append(result.Messages, toolImageMessage(images))
+		}
 	}
 
-	result.Duration = time.Since(startTime)
+	result.finish(startTime)
 	return result, ErrMaxTurns
 }
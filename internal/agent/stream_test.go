@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// cancellationBound is how quickly a canceled run must stop: the call
+// returns, token delivery ends, and the gateway sees the connection close.
+const cancellationBound = time.Second
+
+// TestRunStreamCancellationStopsTokens is the cancellation contract for
+// RunStream: canceling the context aborts the in-flight SSE request, whether
+// tokens are flowing or the gateway has gone quiet, so no token arrives after
+// RunStream returns and the gateway sees the disconnect.
+func TestRunStreamCancellationStopsTokens(testingHandle *testing.T) {
+	tests := []struct {
+		name string
+		// silentAfter stops the gateway sending after this many tokens (0 never stops).
+		silentAfter int
+		// cancelAfter cancels once this many tokens were delivered.
+		cancelAfter int64
+	}{
+		{name: "while tokens flow", cancelAfter: 5},
+		{name: "while the gateway is silent", silentAfter: 2, cancelAfter: 2},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Arrange a gateway that streams a token every 5ms until the client leaves.
+			disconnected := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				responseWriter.Header().Set("Content-Type", "text/event-stream")
+				flusher := responseWriter.(http.Flusher)
+				ticker := time.NewTicker(5 * time.Millisecond)
+				defer ticker.Stop()
+				for sent := 0; ; {
+					select {
+					case <-request.Context().Done():
+						close(disconnected)
+						return
+					case <-ticker.C:
+						if tt.silentAfter > 0 && sent >= tt.silentAfter {
+							continue
+						}
+						_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"tok \"}}]}\n\n")
+						flusher.Flush()
+						sent++
+					}
+				}
+			}))
+			defer server.Close()
+			runner := &Runner{Client: openai.NewClient(server.URL, "", 0)}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var tokens atomic.Int64
+			callbacks := &StreamCallbacks{OnStreamEvent: func(openai.StreamResponse) error {
+				if tokens.Add(1) == tt.cancelAfter {
+					// Cancel from another goroutine, as the TUI does, once the
+					// stream has moved on to waiting for the next event.
+					time.AfterFunc(20*time.Millisecond, cancel)
+				}
+				return nil
+			}}
+
+			// Act.
+			done := make(chan error, 1)
+			go func() {
+				_, err := runner.RunStream(ctx, []llm.Message{{Role: "user", Content: "count forever"}}, "", "model", false, callbacks)
+				done <- err
+			}()
+			<-ctx.Done()
+			canceledAt := time.Now()
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(cancellationBound):
+				testingHandle.Fatalf("RunStream still running %s after cancel", cancellationBound)
+			}
+			returnedAfter := time.Since(canceledAt)
+			delivered := tokens.Load()
+			time.Sleep(50 * time.Millisecond)
+
+			// Assert.
+			testutil.RequireTrue(testingHandle, errors.Is(err, context.Canceled), fmt.Sprintf("canceled error, got %v", err))
+			testutil.RequireTrue(testingHandle, returnedAfter < cancellationBound, fmt.Sprintf("returned %s after cancel", returnedAfter))
+			testutil.RequireEqual(testingHandle, tokens.Load(), delivered, "no token delivered after return")
+			select {
+			case <-disconnected:
+			case <-time.After(cancellationBound):
+				testingHandle.Fatalf("gateway did not see the request aborted within %s", cancellationBound)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"github.com/openclaude/openclaude/internal/config"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+)
+
+const (
+	// reasoningEffortLowMax is the largest thinking budget mapped to "low" effort.
+	reasoningEffortLowMax = 4096
+	// reasoningEffortMediumMax is the largest thinking budget mapped to "medium" effort.
+	reasoningEffortMediumMax = 16384
+)
+
+// applyThinking adds the configured thinking budget to a request in the model's format.
+// A zero budget leaves the request untouched so non-reasoning models are unaffected.
+func (r *Runner) applyThinking(req *openai.ChatRequest, model string) {
+	if r.MaxThinkingTokens <= 0 {
+		return
+	}
+	format := r.ThinkingFormat
+	if info, ok := r.Models[model]; ok && info.ThinkingFormat != "" {
+		format = info.ThinkingFormat
+	}
+	switch format {
+	case config.ThinkingFormatAnthropic:
+		req.Thinking = &openai.ThinkingConfig{Type: "enabled", BudgetTokens: r.MaxThinkingTokens}
+	case config.ThinkingFormatNone:
+	default:
+		req.ReasoningEffort = ReasoningEffortForBudget(r.MaxThinkingTokens)
+	}
+}
+
+// ReasoningEffortForBudget maps a thinking token budget to an OpenAI reasoning_effort level.
+func ReasoningEffortForBudget(budget int) string {
+	switch {
+	case budget <= reasoningEffortLowMax:
+		return "low"
+	case budget <= reasoningEffortMediumMax:
+		return "medium"
+	default:
+		return "high"
+	}
+}
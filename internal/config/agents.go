@@ -0,0 +1,199 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AgentDefinition describes a named subagent loaded from .claude/agents/*.md.
+type AgentDefinition struct {
+	// Name is the identifier used as the Task tool subagent_type.
+	Name string
+	// Description explains when the agent should be used.
+	Description string
+	// Tools restricts the agent to the listed tools (empty inherits all tools).
+	Tools []string
+	// Model overrides the task model ("inherit" or empty uses the parent model).
+	Model string
+	// Prompt is the Markdown body used as the agent's system prompt.
+	Prompt string
+	// Source reports where the definition came from (user or project).
+	Source string
+	// Path is the file the definition was loaded from.
+	Path string
+}
+
+// LoadAgentDefinitions loads subagent definitions from user and project agent directories.
+// Project definitions override user definitions with the same name. Missing
+// directories are ignored. A file that cannot be read or parsed is skipped and
+// described in the returned warnings, naming its path, so one typo neither
+// disables the other agents nor goes unnoticed.
+func LoadAgentDefinitions(cwd string) ([]AgentDefinition, []string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve home dir: %w", err)
+	}
+	sources := []settingsSource{
+		{Source: "user", Path: filepath.Join(home, ".claude", "agents")},
+		{Source: "project", Path: filepath.Join(findProjectRoot(cwd), ".claude", "agents")},
+	}
+
+	byName := map[string]AgentDefinition{}
+	var warnings []string
+	for _, source := range sources {
+		definitions, skipped, err := loadAgentDirectory(source.Path, source.Source)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, skipped...)
+		for _, definition := range definitions {
+			byName[definition.Name] = definition
+		}
+	}
+
+	definitions := make([]AgentDefinition, 0, len(byName))
+	for _, definition := range byName {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions, warnings, nil
+}
+
+// FindAgentDefinition returns the definition with the given name, if present.
+func FindAgentDefinition(definitions []AgentDefinition, name string) (AgentDefinition, bool) {
+	for _, definition := range definitions {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return AgentDefinition{}, false
+}
+
+// loadAgentDirectory parses every Markdown file in an agents directory,
+// returning a warning for each file it had to skip.
+func loadAgentDirectory(dir string, source string) ([]AgentDefinition, []string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("read agents dir: %w", err)
+	}
+
+	var definitions []AgentDefinition
+	var warnings []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".md") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipping agent %s: %v", path, err))
+			continue
+		}
+		definition, err := ParseAgentDefinition(string(raw))
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipping agent %s: %v", path, err))
+			continue
+		}
+		if definition.Name == "" {
+			// Fall back to the file name when the frontmatter omits a name.
+			definition.Name = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		}
+		definition.Source = source
+		definition.Path = path
+		definitions = append(definitions, definition)
+	}
+	return definitions, warnings, nil
+}
+
+// ParseAgentDefinition parses a Markdown agent file with YAML-style frontmatter.
+// Only the flat keys Claude Code uses are supported: name, description, tools, model.
+// Tools may be a comma-separated string or a "- item" list.
+func ParseAgentDefinition(contents string) (AgentDefinition, error) {
+	contents = strings.TrimPrefix(contents, "\ufeff")
+	normalized := strings.ReplaceAll(contents, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return AgentDefinition{}, errors.New("missing frontmatter")
+	}
+	rest := normalized[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return AgentDefinition{}, errors.New("unterminated frontmatter")
+	}
+	frontmatter := rest[:end]
+	body := rest[end+len("\n---"):]
+	// Drop the remainder of the closing delimiter line.
+	if newline := strings.Index(body, "\n"); newline >= 0 {
+		body = body[newline+1:]
+	} else {
+		body = ""
+	}
+
+	definition := AgentDefinition{Prompt: strings.TrimSpace(body)}
+	currentKey := ""
+	for _, line := range strings.Split(frontmatter, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") {
+			// List items continue the most recent key (only tools accepts lists).
+			if currentKey == "tools" {
+				definition.Tools = append(definition.Tools, unquoteFrontmatter(strings.TrimPrefix(trimmed, "- ")))
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return AgentDefinition{}, fmt.Errorf("invalid frontmatter line: %q", trimmed)
+		}
+		currentKey = strings.ToLower(strings.TrimSpace(key))
+		value = unquoteFrontmatter(strings.TrimSpace(value))
+		switch currentKey {
+		case "name":
+			definition.Name = value
+		case "description":
+			definition.Description = value
+		case "model":
+			definition.Model = value
+		case "tools":
+			definition.Tools = append(definition.Tools, splitFrontmatterList(value)...)
+		}
+	}
+	return definition, nil
+}
+
+// splitFrontmatterList splits comma-separated (optionally bracketed) list values.
+func splitFrontmatterList(value string) []string {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	if value == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = unquoteFrontmatter(strings.TrimSpace(item))
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// unquoteFrontmatter strips matching single or double quotes from a value.
+func unquoteFrontmatter(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' && last == '"') || (first == '\'' && last == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
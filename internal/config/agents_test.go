@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAgentDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     AgentDefinition
+		wantErr  bool
+	}{
+		{
+			name:     "comma tools",
+			contents: "---\nname: reviewer\ndescription: \"Reviews diffs\"\ntools: Read, Grep, Glob\nmodel: haiku\n---\nYou review code.\n",
+			want: AgentDefinition{
+				Name:        "reviewer",
+				Description: "Reviews diffs",
+				Tools:       []string{"Read", "Grep", "Glob"},
+				Model:       "haiku",
+				Prompt:      "You review code.",
+			},
+		},
+		{
+			name:     "list tools",
+			contents: "---\nname: writer\ntools:\n  - Write\n  - Edit\n---\n\nWrite docs.",
+			want: AgentDefinition{
+				Name:   "writer",
+				Tools:  []string{"Write", "Edit"},
+				Prompt: "Write docs.",
+			},
+		},
+		{
+			name:     "missing frontmatter",
+			contents: "just text",
+			wantErr:  true,
+		},
+		{
+			name:     "unterminated frontmatter",
+			contents: "---\nname: broken\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAgentDefinition(tt.contents)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse agent: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("unexpected definition: %+v", got)
+			}
+		})
+	}
+}
+
+func TestLoadAgentDefinitionsProjectOverridesUser(t *testing.T) {
+	// Arrange user and project agents that share a name.
+	tempDir := t.TempDir()
+	homeDir := filepath.Join(tempDir, "home")
+	repoDir := filepath.Join(tempDir, "repo")
+	writeAgent := func(dir, file, contents string) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("create agents dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(contents), 0o600); err != nil {
+			t.Fatalf("write agent: %v", err)
+		}
+	}
+	writeAgent(filepath.Join(homeDir, ".claude", "agents"), "shared.md", "---\nname: shared\ndescription: user\n---\nuser prompt")
+	writeAgent(filepath.Join(homeDir, ".claude", "agents"), "solo.md", "---\ndescription: only user\n---\nsolo prompt")
+	writeAgent(filepath.Join(repoDir, ".claude", "agents"), "shared.md", "---\nname: shared\ndescription: project\n---\nproject prompt")
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatalf("create repo dir: %v", err)
+	}
+	t.Setenv("HOME", homeDir)
+
+	// Act.
+	definitions, warnings, err := LoadAgentDefinitions(repoDir)
+	if err != nil {
+		t.Fatalf("load agents: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+
+	// Assert.
+	if len(definitions) != 2 {
+		t.Fatalf("expected 2 agents, got %+v", definitions)
+	}
+	shared, ok := FindAgentDefinition(definitions, "shared")
+	if !ok || shared.Description != "project" || shared.Source != "project" {
+		t.Fatalf("expected project override, got %+v", shared)
+	}
+	solo, ok := FindAgentDefinition(definitions, "solo")
+	if !ok || solo.Prompt != "solo prompt" || solo.Source != "user" {
+		t.Fatalf("expected file-name fallback, got %+v", solo)
+	}
+}
+
+func TestLoadAgentDefinitionsSkipsMalformedFiles(t *testing.T) {
+	// Arrange one bad and one good project agent.
+	tempDir := t.TempDir()
+	repoDir := filepath.Join(tempDir, "repo")
+	agentsDir := filepath.Join(repoDir, ".claude", "agents")
+	if err := os.MkdirAll(agentsDir, 0o755); err != nil {
+		t.Fatalf("create agents dir: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatalf("create repo dir: %v", err)
+	}
+	badPath := filepath.Join(agentsDir, "bad.md")
+	if err := os.WriteFile(badPath, []byte("---\nname: bad\n"), 0o600); err != nil {
+		t.Fatalf("write bad agent: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(agentsDir, "good.md"), []byte("---\nname: good\n---\ngood prompt"), 0o600); err != nil {
+		t.Fatalf("write good agent: %v", err)
+	}
+	t.Setenv("HOME", filepath.Join(tempDir, "home"))
+
+	// Act.
+	definitions, warnings, err := LoadAgentDefinitions(repoDir)
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("load agents: %v", err)
+	}
+	if len(definitions) != 1 || definitions[0].Name != "good" {
+		t.Fatalf("expected only the good agent, got %+v", definitions)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], badPath) || !strings.Contains(warnings[0], "unterminated frontmatter") {
+		t.Fatalf("expected a warning naming the bad file, got %v", warnings)
+	}
+}
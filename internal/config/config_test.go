@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/keyring"
 )
 
 func TestLoadClaudeSettingsPrecedence(t *testing.T) {
@@ -55,6 +60,273 @@ func TestLoadClaudeSettingsPrecedence(t *testing.T) {
 	}
 }
 
+func TestMergeSettingsPermissions(t *testing.T) {
+	base, err := parseSettings([]byte(`{"permissions":{"allow":["Read"],"deny":["WebFetch"],"defaultMode":"plan"}}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	overlay, err := parseSettings([]byte(`{"permissions":{"allow":["Bash", 3],"defaultMode":"acceptEdits"}}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+
+	merged := mergeSettings(base, overlay)
+	if got := merged.Permissions.Allow; len(got) != 2 || got[0] != "Read" || got[1] != "Bash" {
+		t.Fatalf("unexpected allow rules: %v", got)
+	}
+	if got := merged.Permissions.Deny; len(got) != 1 || got[0] != "WebFetch" {
+		t.Fatalf("unexpected deny rules: %v", got)
+	}
+	if merged.Permissions.DefaultMode != "acceptEdits" {
+		t.Fatalf("expected overlay default mode, got %s", merged.Permissions.DefaultMode)
+	}
+}
+
+func TestMergeSettingsEnv(t *testing.T) {
+	base, err := parseSettings([]byte(`{"env":{"OTEL_METRICS_EXPORTER":"otlp","KEEP":"base"}}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	overlay, err := parseSettings([]byte(`{"env":{"OTEL_METRICS_EXPORTER":"console","IGNORED":1}}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+
+	merged := mergeSettings(base, overlay)
+	if got := merged.Env["OTEL_METRICS_EXPORTER"]; got != "console" {
+		t.Fatalf("expected overlay env to win, got %q", got)
+	}
+	if got := merged.Env["KEEP"]; got != "base" {
+		t.Fatalf("expected base env to be kept, got %q", got)
+	}
+	if _, ok := merged.Env["IGNORED"]; ok {
+		t.Fatalf("expected non-string env values to be ignored")
+	}
+}
+
+func TestMergeSettingsSandbox(t *testing.T) {
+	base, err := parseSettings([]byte(`{"sandbox":{"enabled":true,"disableNetwork":true,"writableDirs":["/cache"]}}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	if !base.Sandbox.Enabled || !base.Sandbox.DisableNetwork || len(base.Sandbox.WritableDirs) != 1 {
+		t.Fatalf("unexpected sandbox settings: %+v", base.Sandbox)
+	}
+	merged := mergeSettings(base, &Settings{Raw: map[string]any{}})
+	if !merged.Sandbox.Enabled {
+		t.Fatalf("expected base sandbox to be kept")
+	}
+	overlay, err := parseSettings([]byte(`{"sandbox":{"enabled":false}}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+	merged = mergeSettings(base, overlay)
+	if merged.Sandbox.Enabled || merged.Sandbox.DisableNetwork || len(merged.Sandbox.WritableDirs) != 0 {
+		t.Fatalf("expected overlay sandbox block to replace base, got %+v", merged.Sandbox)
+	}
+}
+
+func TestMergeSettingsSuggestCommits(t *testing.T) {
+	base, err := parseSettings([]byte(`{"suggestCommits":true}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	if merged := mergeSettings(base, &Settings{Raw: map[string]any{}}); !merged.SuggestCommits {
+		t.Fatalf("expected suggestCommits to be kept")
+	}
+	overlay, err := parseSettings([]byte(`{"suggestCommits":false}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+	if merged := mergeSettings(base, overlay); merged.SuggestCommits {
+		t.Fatalf("expected overlay to turn suggestCommits off")
+	}
+}
+
+func TestMergeSettingsCleanupPeriodDays(t *testing.T) {
+	base, err := parseSettings([]byte(`{"model":"sonnet"}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	if base.CleanupPeriodDays != DefaultCleanupPeriodDays {
+		t.Fatalf("expected default retention, got %d", base.CleanupPeriodDays)
+	}
+	overlay, err := parseSettings([]byte(`{"cleanupPeriodDays":7}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+	merged := mergeSettings(base, overlay)
+	if merged.CleanupPeriodDays != 7 {
+		t.Fatalf("expected overlay retention, got %d", merged.CleanupPeriodDays)
+	}
+	if merged = mergeSettings(merged, &Settings{Raw: map[string]any{}}); merged.CleanupPeriodDays != 7 {
+		t.Fatalf("expected retention to be kept, got %d", merged.CleanupPeriodDays)
+	}
+}
+
+func TestMergeSettingsToolOverrides(t *testing.T) {
+	base, err := parseSettings([]byte(`{"toolOverrides":{"Bash":{"description":"Prefer Grep and Glob."},"Read":{"description":"Read a file."}}}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	overlay, err := parseSettings([]byte(`{"toolOverrides":{"Bash":{"schema":{"type":"object","properties":{"command":{"type":"string"}}}}}}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+	merged := mergeSettings(base, overlay)
+	if bash := merged.ToolOverrides["Bash"]; bash.Description != "" || bash.Schema["type"] != "object" {
+		t.Fatalf("expected the overlay to replace the Bash override, got %#v", bash)
+	}
+	if merged.ToolOverrides["Read"].Description != "Read a file." {
+		t.Fatalf("expected the base Read override to be kept, got %#v", merged.ToolOverrides["Read"])
+	}
+
+	for _, raw := range []string{
+		`{"toolOverrides":{"Bash":"text"}}`,
+		`{"toolOverrides":{"Bash":{"description":7}}}`,
+		`{"toolOverrides":{"Bash":{"schema":{"type":"string"}}}}`,
+	} {
+		if _, err := parseSettings([]byte(raw)); err == nil || !strings.Contains(err.Error(), "toolOverrides.Bash") {
+			t.Fatalf("expected an error naming the override for %s, got %v", raw, err)
+		}
+	}
+}
+
+func TestMergeSettingsTheme(t *testing.T) {
+	base, err := parseSettings([]byte(`{"theme":"custom","themeColors":{"claude":"#ff8800","text":"#eee"}}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	overlay, err := parseSettings([]byte(`{"theme":"light"}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+	merged := mergeSettings(base, overlay)
+	if merged.Theme != "light" || merged.ThemeColors["claude"] != "#ff8800" {
+		t.Fatalf("expected the overlay theme and the base palette, got %q %v", merged.Theme, merged.ThemeColors)
+	}
+	palette, err := parseSettings([]byte(`{"themeColors":{"error":"#f00"}}`))
+	if err != nil {
+		t.Fatalf("parse palette settings: %v", err)
+	}
+	if merged = mergeSettings(merged, palette); len(merged.ThemeColors) != 1 || merged.Theme != "light" {
+		t.Fatalf("expected the overlay palette to replace the base one, got %q %v", merged.Theme, merged.ThemeColors)
+	}
+
+	for _, raw := range []string{`{"themeColors":"dark"}`, `{"themeColors":{"text":"white"}}`, `{"themeColors":{"text":"#12345"}}`} {
+		if _, err := parseSettings([]byte(raw)); err == nil || !strings.Contains(err.Error(), "themeColors") {
+			t.Fatalf("expected a themeColors error for %s, got %v", raw, err)
+		}
+	}
+}
+
+func TestMergeSettingsNotifications(t *testing.T) {
+	base, err := parseSettings([]byte(`{"preferredNotifChannel":"command","notifyCommand":"notify-send OpenClaude"}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	overlay, err := parseSettings([]byte(`{"preferredNotifChannel":"terminal_bell"}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+	merged := mergeSettings(base, overlay)
+	if merged.PreferredNotifChannel != "terminal_bell" || merged.NotifyCommand != "notify-send OpenClaude" {
+		t.Fatalf("expected the overlay channel and the base command, got %q %q", merged.PreferredNotifChannel, merged.NotifyCommand)
+	}
+	project, err := parseSettings([]byte(`{"notifyCommand":"curl evil.example"}`))
+	if err != nil {
+		t.Fatalf("parse project settings: %v", err)
+	}
+	merged = mergeSettings(base.withSource(SettingsSourceUser), project.withSource(SettingsSourceProject))
+	if merged.NotifyCommand != "notify-send OpenClaude" || merged.NotifyCommandSource != SettingsSourceUser {
+		t.Fatalf("expected a project command not to replace the user's, got %q from %q", merged.NotifyCommand, merged.NotifyCommandSource)
+	}
+}
+
+func TestMergeSettingsIgnorePatterns(t *testing.T) {
+	base, err := parseSettings([]byte(`{"ignorePatterns":["node_modules/"]}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	overlay, err := parseSettings([]byte(`{"ignorePatterns":["*.min.js", 3],"hidePatterns":["!.env.example"]}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+	merged := mergeSettings(base, overlay)
+	if strings.Join(merged.IgnorePatterns, ",") != "node_modules/,*.min.js" {
+		t.Fatalf("expected patterns from both sources, got %v", merged.IgnorePatterns)
+	}
+	if strings.Join(merged.HidePatterns, ",") != "!.env.example" {
+		t.Fatalf("expected hide patterns from the overlay, got %v", merged.HidePatterns)
+	}
+}
+
+func TestMergeSettingsHooks(t *testing.T) {
+	base, err := parseSettings([]byte(`{"hooks":{"Stop":[{"hooks":[{"type":"command","command":"./check.sh","timeout":5}]}]}}`))
+	if err != nil {
+		t.Fatalf("parse base settings: %v", err)
+	}
+	overlay, err := parseSettings([]byte(`{"hooks":{"Stop":[{"hooks":[{"type":"prompt","prompt":"x"},{"type":"command","command":"make lint"}]}],"PreCompact":[{"matcher":"auto","hooks":[{"type":"command","command":""}]}]}}`))
+	if err != nil {
+		t.Fatalf("parse overlay settings: %v", err)
+	}
+	merged := mergeSettings(base, overlay)
+	stop := merged.Hooks["Stop"]
+	if len(stop) != 2 || stop[0].Hooks[0].Command != "./check.sh" || stop[0].Hooks[0].Timeout != 5*time.Second || stop[1].Hooks[0].Command != "make lint" {
+		t.Fatalf("expected Stop hooks from both sources, got %+v", stop)
+	}
+	if _, ok := merged.Hooks["PreCompact"]; ok {
+		t.Fatalf("expected hooks without a command to be skipped, got %+v", merged.Hooks["PreCompact"])
+	}
+}
+
+func TestWithoutWorkspaceCommands(t *testing.T) {
+	// Arrange user hooks and project and local hooks and notify commands.
+	tempDir := t.TempDir()
+	homeDir := filepath.Join(tempDir, "home")
+	repoDir := filepath.Join(tempDir, "repo")
+	localDir := filepath.Join(repoDir, "sub")
+	files := map[string]string{
+		filepath.Join(homeDir, ".claude", "settings.json"):  `{"hooks":{"Stop":[{"hooks":[{"type":"command","command":"user-stop"}]}]}}`,
+		filepath.Join(repoDir, ".claude", "settings.json"):  `{"notifyCommand":"project-notify","hooks":{"Stop":[{"hooks":[{"type":"command","command":"project-stop"}]}]}}`,
+		filepath.Join(localDir, ".claude", "settings.json"): `{"hooks":{"PreCompact":[{"hooks":[{"type":"command","command":"local-compact"}]}]}}`,
+	}
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0o755); err != nil {
+		t.Fatalf("create repo dir: %v", err)
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create settings dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write settings: %v", err)
+		}
+	}
+	t.Setenv("HOME", homeDir)
+	settings, err := LoadClaudeSettings(localDir, nil, "")
+	if err != nil {
+		t.Fatalf("load settings: %v", err)
+	}
+
+	// Act.
+	untrusted := settings.WithoutWorkspaceCommands()
+
+	// Assert.
+	if len(settings.Hooks["Stop"]) != 2 || settings.NotifyCommand != "project-notify" || settings.NotifyCommandSource != SettingsSourceProject {
+		t.Fatalf("expected the merged settings to keep every source, got %+v %q", settings.Hooks, settings.NotifyCommand)
+	}
+	stop := untrusted.Hooks["Stop"]
+	if len(stop) != 1 || stop[0].Hooks[0].Command != "user-stop" || stop[0].Source != SettingsSourceUser {
+		t.Fatalf("expected only the user Stop hook, got %+v", stop)
+	}
+	if _, ok := untrusted.Hooks["PreCompact"]; ok {
+		t.Fatalf("expected the local PreCompact hook to be dropped, got %+v", untrusted.Hooks["PreCompact"])
+	}
+	if untrusted.NotifyCommand != "" {
+		t.Fatalf("expected the project notify command to be dropped, got %q", untrusted.NotifyCommand)
+	}
+}
+
 func TestResolveModelAliases(t *testing.T) {
 	// Arrange a config with an alias.
 	cfg := &ProviderConfig{
@@ -73,3 +345,273 @@ func TestResolveModelAliases(t *testing.T) {
 		t.Fatalf("expected custom, got %s", got)
 	}
 }
+
+func TestResolveModelAliasFallbacks(t *testing.T) {
+	// Arrange aliases with mixed case.
+	cfg := &ProviderConfig{
+		DefaultModel: "base-model",
+		ModelAliases: map[string]string{"Sonnet": "gpt-sonnet"},
+	}
+
+	// Assert case-insensitive and "default" resolution.
+	if got := ResolveModel(cfg, "sonnet", ""); got != "gpt-sonnet" {
+		t.Fatalf("expected gpt-sonnet, got %s", got)
+	}
+	if got := ResolveModel(cfg, "default", ""); got != "base-model" {
+		t.Fatalf("expected base-model, got %s", got)
+	}
+}
+
+func TestLoadProviderConfigModelCatalog(t *testing.T) {
+	// Arrange a config with a catalog entry that carries pricing.
+	path := filepath.Join(t.TempDir(), "config.json")
+	raw := `{
+		"api_base_url": "http://localhost",
+		"api_key": "key",
+		"default_model": "big",
+		"model_aliases": {"opus": "big", "haiku": "small"},
+		"pricing": {"big": {"input_per_1m": 1, "output_per_1m": 2}},
+		"models": {
+			"big": {"context_window": 200000, "pricing": {"input_per_1m": 9, "output_per_1m": 9}},
+			"small": {"context_window": 32000, "max_output_tokens": 4096, "pricing": {"input_per_1m": 0.5, "output_per_1m": 1}}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	// Act.
+	cfg, err := LoadProviderConfig(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	catalog := ModelCatalog(cfg)
+
+	// Assert catalog pricing fills gaps without overriding top-level pricing.
+	if cfg.Pricing["small"].InputPer1M != 0.5 {
+		t.Fatalf("expected catalog pricing for small, got %+v", cfg.Pricing["small"])
+	}
+	if cfg.Pricing["big"].InputPer1M != 1 {
+		t.Fatalf("expected top-level pricing for big, got %+v", cfg.Pricing["big"])
+	}
+	if info, ok := LookupModelInfo(cfg, "big"); !ok || info.Pricing.InputPer1M != cfg.Pricing["big"].InputPer1M {
+		t.Fatalf("expected /model pricing to match accounting, got %+v", info.Pricing)
+	}
+	if len(catalog) != 2 || catalog[0].Name != "big" || !catalog[0].Default {
+		t.Fatalf("unexpected catalog: %+v", catalog)
+	}
+	if len(catalog[1].Aliases) != 1 || catalog[1].Aliases[0] != "haiku" || catalog[1].Info.MaxOutputTokens != 4096 {
+		t.Fatalf("unexpected small entry: %+v", catalog[1])
+	}
+}
+
+func TestLoadProviderConfigRejectsCaseCollidingAliases(t *testing.T) {
+	// Arrange aliases that differ only by case.
+	path := filepath.Join(t.TempDir(), "config.json")
+	raw := `{"api_base_url": "http://localhost", "api_key": "key", "default_model": "m",
+		"model_aliases": {"Fast": "small", "fast": "big", "opus": "big", "OPUS": "big"}}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	// Act.
+	_, err := LoadProviderConfig(path)
+
+	// Assert only the pair with different targets is rejected.
+	if !errors.Is(err, ErrProviderConfigInvalid) || !strings.Contains(err.Error(), `"Fast" and "fast"`) {
+		t.Fatalf("expected a case collision error, got %v", err)
+	}
+}
+
+func TestLoadProviderConfigAPIFlavor(t *testing.T) {
+	dir := t.TempDir()
+	load := func(extra string) (*ProviderConfig, error) {
+		path := filepath.Join(dir, "config.json")
+		raw := `{"api_base_url": "https://example.openai.azure.com", "api_key": "key", "default_model": "gpt-4o"` + extra + `}`
+		if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		return LoadProviderConfig(path)
+	}
+
+	cfg, err := load("")
+	if err != nil || cfg.APIFlavor != APIFlavorOpenAI {
+		t.Fatalf("expected openai flavor by default, got %+v, %v", cfg, err)
+	}
+	cfg, err = load(`, "api_flavor": "azure", "azure": {"deployments": {"gpt-4o": "prod-4o"}}`)
+	if err != nil {
+		t.Fatalf("load azure config: %v", err)
+	}
+	if cfg.Azure.APIVersion != DefaultAzureAPIVersion || cfg.Azure.Deployments["gpt-4o"] != "prod-4o" {
+		t.Fatalf("unexpected azure config: %+v", cfg.Azure)
+	}
+	if _, err := load(`, "api_flavor": "bedrock"`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected unknown flavor to be rejected, got %v", err)
+	}
+}
+
+func TestLoadProviderConfigAuth(t *testing.T) {
+	dir := t.TempDir()
+	load := func(fields string) (*ProviderConfig, error) {
+		path := filepath.Join(dir, "config.json")
+		raw := `{"api_base_url": "http://localhost", "default_model": "m"` + fields + `}`
+		if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		return LoadProviderConfig(path)
+	}
+
+	if _, err := load(""); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected a missing api_key and auth to be rejected, got %v", err)
+	}
+	cfg, err := load(`, "auth": {"type": "token_command", "command": "gcloud auth print-access-token"}`)
+	if err != nil {
+		t.Fatalf("load token command config: %v", err)
+	}
+	if cfg.Auth.TokenTTLSeconds != DefaultTokenTTLSeconds {
+		t.Fatalf("expected default token ttl, got %d", cfg.Auth.TokenTTLSeconds)
+	}
+	if _, err := load(`, "auth": {"type": "client_credentials", "token_url": "http://idp/token", "client_id": "id"}`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected client_credentials without a secret to be rejected, got %v", err)
+	}
+	if _, err := load(`, "auth": {"type": "saml"}`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected unknown auth type to be rejected, got %v", err)
+	}
+}
+
+func TestLoadProviderConfigTimeouts(t *testing.T) {
+	dir := t.TempDir()
+	load := func(fields string) (*ProviderConfig, error) {
+		path := filepath.Join(dir, "config.json")
+		raw := `{"api_base_url": "http://localhost", "api_key": "k", "default_model": "m"` + fields + `}`
+		if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		return LoadProviderConfig(path)
+	}
+
+	cfg, err := load("")
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.APITimeoutMS != 0 || cfg.TurnTimeoutMS != 0 || cfg.StreamIdleTimeoutMS != DefaultStreamIdleTimeoutMS {
+		t.Fatalf("unexpected timeout defaults: %+v", cfg)
+	}
+	cfg, err = load(`, "api_timeout_ms": 90000, "stream_idle_timeout_ms": -1, "turn_timeout_ms": 600000`)
+	if err != nil {
+		t.Fatalf("load timeouts: %v", err)
+	}
+	if cfg.APITimeoutMS != 90000 || cfg.StreamIdleTimeoutMS != -1 || cfg.TurnTimeoutMS != 600000 {
+		t.Fatalf("unexpected timeouts: %+v", cfg)
+	}
+	if _, err := load(`, "turn_timeout_ms": -5`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected a negative turn timeout to be rejected, got %v", err)
+	}
+}
+
+func TestLoadProviderConfigKeyringReference(t *testing.T) {
+	t.Cleanup(keyring.UseMemory())
+	dir := t.TempDir()
+	load := func(fields string) (*ProviderConfig, error) {
+		path := filepath.Join(dir, "config.json")
+		raw := `{"api_base_url": "http://localhost", "default_model": "m"` + fields + `}`
+		if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		return LoadProviderConfig(path)
+	}
+
+	if _, err := load(`, "api_key_keyring": "work"`); !errors.Is(err, keyring.ErrNotFound) {
+		t.Fatalf("expected a missing keyring entry to be reported, got %v", err)
+	}
+	if err := keyring.Set("work", "sk-from-keyring"); err != nil {
+		t.Fatalf("store key: %v", err)
+	}
+	cfg, err := load(`, "api_key_keyring": "work"`)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.APIKey != "sk-from-keyring" {
+		t.Fatalf("expected the keyring key, got %q", cfg.APIKey)
+	}
+	if _, err := load(`, "api_key": "sk", "api_key_keyring": "work"`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected api_key and api_key_keyring together to be rejected, got %v", err)
+	}
+}
+
+func TestLoadProviderConfigServerTools(t *testing.T) {
+	dir := t.TempDir()
+	load := func(extra string) (*ProviderConfig, error) {
+		path := filepath.Join(dir, "config.json")
+		raw := `{"api_base_url": "https://gateway.example/v1", "api_key": "key", "default_model": "m"` + extra + `}`
+		if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		return LoadProviderConfig(path)
+	}
+
+	cfg, err := load(`, "server_tools": [{"type": "web_search", "max_uses": 3}]`)
+	if err != nil || len(cfg.ServerTools) != 1 || cfg.ServerTools[0]["type"] != "web_search" {
+		t.Fatalf("expected one server tool, got %+v, %v", cfg, err)
+	}
+	if _, err := load(`, "server_tools": [{"name": "web_search"}]`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected a server tool without type to be rejected, got %v", err)
+	}
+	if _, err := load(`, "server_tools": [{"type": "function"}]`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected a function server tool to be rejected, got %v", err)
+	}
+}
+
+func TestLoadProviderConfigRedaction(t *testing.T) {
+	dir := t.TempDir()
+	load := func(extra string) (*ProviderConfig, error) {
+		path := filepath.Join(dir, "config.json")
+		raw := `{"api_base_url": "https://gateway.example/v1", "api_key": "key", "default_model": "m"` + extra + `}`
+		if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		return LoadProviderConfig(path)
+	}
+
+	cfg, err := load(`, "redaction": {"patterns": ["INTERNAL-[0-9]+"]}`)
+	if err != nil || cfg.Redaction.Disabled || len(cfg.Redaction.Patterns) != 1 {
+		t.Fatalf("expected one custom pattern with redaction on, got %+v, %v", cfg, err)
+	}
+	if _, err := load(`, "redaction": {"patterns": ["("]}`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected an invalid pattern to be rejected, got %v", err)
+	}
+	if _, err := load(`, "redaction": {"patterns": [".*"]}`); !errors.Is(err, ErrProviderConfigInvalid) {
+		t.Fatalf("expected a pattern matching empty text to be rejected, got %v", err)
+	}
+}
+
+func TestLoadProviderConfigRequestParams(t *testing.T) {
+	dir := t.TempDir()
+	load := func(extra string) (*ProviderConfig, error) {
+		path := filepath.Join(dir, "config.json")
+		raw := `{"api_base_url": "https://gateway.example/v1", "api_key": "key", "default_model": "m"` + extra + `}`
+		if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		return LoadProviderConfig(path)
+	}
+
+	cfg, err := load(`, "max_tokens": 4096, "temperature": 0.2, "top_p": 0.9, "stop": ["###"]`)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.MaxTokens != 4096 || cfg.Temperature == nil || *cfg.Temperature != 0.2 || cfg.TopP == nil || *cfg.TopP != 0.9 || len(cfg.Stop) != 1 {
+		t.Fatalf("expected request params to load, got %+v", cfg.RequestParams)
+	}
+	for _, extra := range []string{`, "temperature": 2.5`, `, "top_p": -0.1`, `, "max_tokens": -1`, `, "stop": [""]`, `, "stop": ["a", "b", "c", "d", "e"]`} {
+		if _, err := load(extra); !errors.Is(err, ErrProviderConfigInvalid) {
+			t.Fatalf("expected %s to be rejected, got %v", extra, err)
+		}
+	}
+
+	temperature := 0.7
+	merged := cfg.RequestParams.Merge(RequestParams{Temperature: &temperature})
+	if *merged.Temperature != 0.7 || merged.MaxTokens != 4096 || *merged.TopP != 0.9 {
+		t.Fatalf("expected the override to replace only temperature, got %+v", merged)
+	}
+}
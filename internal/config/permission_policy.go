@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PermissionPolicyVersion is the current permission policy file format version.
+const PermissionPolicyVersion = 1
+
+// permissionRulePattern accepts "Tool" or "Tool(specifier)" rules.
+var permissionRulePattern = regexp.MustCompile(`(?s)^[A-Za-z][A-Za-z0-9_]*(\(.+\))?$`)
+
+// PermissionPolicy is a committed list of tool approvals replayed in CI.
+// Rules use the settings syntax: "Write" approves a whole tool and
+// "Bash(go test ./...)" approves one exact command. The optional declarative
+// fields approve whole tools and Bash command prefixes, and limit the paths and
+// file sizes file tools may touch.
+type PermissionPolicy struct {
+	// Version identifies the file format.
+	Version int `json:"version"`
+	// Allow lists approved permission rules.
+	Allow []string `json:"allow"`
+	// Tools lists tools approved for every call.
+	Tools []string `json:"tools,omitempty"`
+	// BashPrefixes approves Bash commands whose every segment starts with a prefix.
+	BashPrefixes []string `json:"bash_prefixes,omitempty"`
+	// Paths restricts file tools to these files, directories, or glob patterns,
+	// relative to the working directory.
+	Paths []string `json:"paths,omitempty"`
+	// MaxFileSizeBytes caps the size of files file tools read, edit, or write.
+	MaxFileSizeBytes int64 `json:"max_file_size_bytes,omitempty"`
+}
+
+// DefaultPermissionPolicyPath returns the project policy path used by /permissions export.
+func DefaultPermissionPolicyPath(cwd string) string {
+	return filepath.Join(cwd, ".openclaude", "permission-policy.json")
+}
+
+// LoadPermissionPolicy reads and validates a permission policy file.
+func LoadPermissionPolicy(path string) (*PermissionPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// JSON files start with an object; anything else is read as YAML.
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if data, err = permissionPolicyYAMLToJSON(string(data)); err != nil {
+			return nil, fmt.Errorf("parse permission policy %s: %w", path, err)
+		}
+	}
+	var policy PermissionPolicy
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("parse permission policy %s: %w", path, err)
+	}
+	if policy.Version != PermissionPolicyVersion {
+		return nil, fmt.Errorf("permission policy %s: unsupported version %d (want %d)", path, policy.Version, PermissionPolicyVersion)
+	}
+	for _, rule := range policy.Allow {
+		if err := ValidatePermissionRule(rule); err != nil {
+			return nil, fmt.Errorf("permission policy %s: %w", path, err)
+		}
+	}
+	for _, tool := range policy.Tools {
+		if err := ValidatePermissionRule(tool); err != nil || strings.Contains(tool, "(") {
+			return nil, fmt.Errorf("permission policy %s: invalid tool name %q", path, tool)
+		}
+	}
+	for _, entry := range append(append([]string{}, policy.BashPrefixes...), policy.Paths...) {
+		if strings.TrimSpace(entry) == "" {
+			return nil, fmt.Errorf("permission policy %s: bash_prefixes and paths entries must not be empty", path)
+		}
+	}
+	if policy.MaxFileSizeBytes < 0 {
+		return nil, fmt.Errorf("permission policy %s: max_file_size_bytes must not be negative", path)
+	}
+	return &policy, nil
+}
+
+// permissionPolicyYAMLToJSON converts the YAML subset policy files use to JSON:
+// top-level "key: value" scalars, "key: [a, b]" lists, and "key:" followed by
+// "- item" lines. "#" starts a comment at the beginning of a line or after whitespace.
+func permissionPolicyYAMLToJSON(contents string) ([]byte, error) {
+	document := map[string]any{}
+	currentKey := ""
+	for number, line := range strings.Split(strings.ReplaceAll(contents, "\r\n", "\n"), "\n") {
+		line = stripYAMLComment(line)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok {
+			list, isList := document[currentKey].([]any)
+			if currentKey == "" || !isList {
+				return nil, fmt.Errorf("line %d: list item without a list key", number+1)
+			}
+			document[currentKey] = append(list, unquoteFrontmatter(strings.TrimSpace(item)))
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok || line != strings.TrimLeft(line, " \t") {
+			return nil, fmt.Errorf("line %d: expected a top-level key: value", number+1)
+		}
+		currentKey = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch {
+		case value == "":
+			document[currentKey] = []any{}
+		case strings.HasPrefix(value, "["):
+			items := []any{}
+			for _, item := range splitFrontmatterList(value) {
+				items = append(items, item)
+			}
+			document[currentKey] = items
+		default:
+			if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+				document[currentKey] = integer
+			} else {
+				document[currentKey] = unquoteFrontmatter(value)
+			}
+		}
+	}
+	return json.Marshal(document)
+}
+
+// SavePermissionPolicy merges rules into the policy at path, creating it if needed.
+// Existing rules are kept so exports from several sessions accumulate; the
+// result is sorted and de-duplicated for stable diffs.
+func SavePermissionPolicy(path string, rules []string) (*PermissionPolicy, error) {
+	policy := &PermissionPolicy{Version: PermissionPolicyVersion}
+	if existing, err := LoadPermissionPolicy(path); err == nil {
+		policy = existing
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if err := ValidatePermissionRule(rule); err != nil {
+			return nil, err
+		}
+	}
+	seen := map[string]bool{}
+	var merged []string
+	for _, rule := range append(policy.Allow, rules...) {
+		if !seen[rule] {
+			seen[rule] = true
+			merged = append(merged, rule)
+		}
+	}
+	sort.Strings(merged)
+	policy.Allow = merged
+
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	// The policy is meant to be committed, so it is world-readable like source files.
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ValidatePermissionRule checks a rule uses the "Tool" or "Tool(specifier)" syntax.
+func ValidatePermissionRule(rule string) error {
+	if !permissionRulePattern.MatchString(rule) {
+		return fmt.Errorf("invalid permission rule %q", rule)
+	}
+	return nil
+}
+
+// stripYAMLComment removes a "#" comment that is outside quotes and starts the
+// line or follows whitespace.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for index := 0; index < len(line); index++ {
+		switch char := line[index]; {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '#' && (index == 0 || line[index-1] == ' ' || line[index-1] == '\t'):
+			return line[:index]
+		}
+	}
+	return line
+}
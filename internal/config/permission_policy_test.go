@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSavePermissionPolicyMergesAndValidates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".openclaude", "permission-policy.json")
+
+	if _, err := SavePermissionPolicy(path, []string{"Write", "Bash(go test ./...)"}); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	if _, err := SavePermissionPolicy(path, []string{"Write", "Edit"}); err != nil {
+		t.Fatalf("second save: %v", err)
+	}
+	policy, err := LoadPermissionPolicy(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := []string{"Bash(go test ./...)", "Edit", "Write"}
+	if !reflect.DeepEqual(policy.Allow, want) {
+		t.Fatalf("allow = %v, want %v", policy.Allow, want)
+	}
+
+	if _, err := SavePermissionPolicy(path, []string{"not a rule"}); err == nil {
+		t.Fatalf("expected invalid rule error")
+	}
+}
+
+func TestLoadPermissionPolicyRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"version":2,"allow":["Write"]}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := LoadPermissionPolicy(path); err == nil {
+		t.Fatalf("expected version error")
+	}
+}
+
+func TestLoadPermissionPolicyYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	contents := `# CI policy
+version: 1
+allow: [Bash(make lint)]
+tools: # approved for every call
+  - Read
+  - "Edit" # quoted
+bash_prefixes:
+  - "go test"
+  - go vet
+paths: [src, "docs/*.md"]  # relative to cwd
+max_file_size_bytes: 1048576
+`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	policy, err := LoadPermissionPolicy(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := &PermissionPolicy{
+		Version:          1,
+		Allow:            []string{"Bash(make lint)"},
+		Tools:            []string{"Read", "Edit"},
+		BashPrefixes:     []string{"go test", "go vet"},
+		Paths:            []string{"src", "docs/*.md"},
+		MaxFileSizeBytes: 1 << 20,
+	}
+	if !reflect.DeepEqual(policy, want) {
+		t.Fatalf("policy = %+v, want %+v", policy, want)
+	}
+
+	for name, invalid := range map[string]string{
+		"unknown key":   "version: 1\nallowed_tools: [Read]\n",
+		"tool rule":     "version: 1\ntools: [Bash(ls)]\n",
+		"negative size": "version: 1\nmax_file_size_bytes: -1\n",
+		"stray item":    "- Read\n",
+		"indented key":  "version: 1\n  tools: [Read]\n",
+	} {
+		if err := os.WriteFile(path, []byte(invalid), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if _, err := LoadPermissionPolicy(path); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PromptPreset is a reusable system prompt template from ~/.openclaude/prompts.
+type PromptPreset struct {
+	// Name is the file name without its extension, used with --system-prompt-preset.
+	Name string
+	// Description comes from optional frontmatter and is shown by `claude prompts list`.
+	Description string
+	// Template is the prompt body, with {{name}} placeholders.
+	Template string
+	// Path is the file the preset was loaded from.
+	Path string
+}
+
+// promptPresetExtensions are the file types read as presets.
+var promptPresetExtensions = map[string]bool{".md": true, ".txt": true}
+
+// promptPlaceholder matches {{name}}, allowing spaces inside the braces.
+var promptPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// promptVarName matches a variable name a placeholder can refer to.
+var promptVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// PromptPresetDir returns ~/.openclaude/prompts.
+func PromptPresetDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home dir: %w", err)
+	}
+	return filepath.Join(home, ".openclaude", "prompts"), nil
+}
+
+// ListPromptPresets loads every preset in dir, sorted by name. A missing
+// directory has no presets.
+func ListPromptPresets(dir string) ([]PromptPreset, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read prompts dir: %w", err)
+	}
+	var presets []PromptPreset
+	for _, entry := range entries {
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || !promptPresetExtensions[extension] {
+			continue
+		}
+		preset, err := readPromptPreset(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+	return presets, nil
+}
+
+// LoadPromptPreset returns the preset with the given name from dir. The
+// error for an unknown name lists the presets that exist.
+func LoadPromptPreset(dir string, name string) (PromptPreset, error) {
+	presets, err := ListPromptPresets(dir)
+	if err != nil {
+		return PromptPreset{}, err
+	}
+	names := make([]string, 0, len(presets))
+	for _, preset := range presets {
+		if preset.Name == name {
+			return preset, nil
+		}
+		names = append(names, preset.Name)
+	}
+	if len(names) == 0 {
+		return PromptPreset{}, fmt.Errorf("prompt preset %q not found: %s has no .md or .txt presets", name, dir)
+	}
+	return PromptPreset{}, fmt.Errorf("prompt preset %q not found in %s (available: %s)", name, dir, strings.Join(names, ", "))
+}
+
+// readPromptPreset reads one preset file, splitting off optional frontmatter.
+func readPromptPreset(path string) (PromptPreset, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return PromptPreset{}, fmt.Errorf("read prompt preset %s: %w", path, err)
+	}
+	base := filepath.Base(path)
+	preset := PromptPreset{Name: strings.TrimSuffix(base, filepath.Ext(base)), Path: path}
+	contents := strings.ReplaceAll(strings.TrimPrefix(string(raw), "\ufeff"), "\r\n", "\n")
+	if rest, ok := strings.CutPrefix(contents, "---\n"); ok {
+		frontmatter, body, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			return PromptPreset{}, fmt.Errorf("parse prompt preset %s: unterminated frontmatter", path)
+		}
+		for _, line := range strings.Split(frontmatter, "\n") {
+			key, value, _ := strings.Cut(line, ":")
+			if strings.EqualFold(strings.TrimSpace(key), "description") {
+				preset.Description = unquoteFrontmatter(strings.TrimSpace(value))
+			}
+		}
+		contents = body
+	}
+	preset.Template = strings.TrimSpace(contents)
+	return preset, nil
+}
+
+// RenderPromptTemplate replaces each {{name}} in template with vars[name].
+// Placeholders without a value are an error, so a typo never reaches the model.
+func RenderPromptTemplate(template string, vars map[string]string) (string, error) {
+	var missing []string
+	seen := map[string]bool{}
+	rendered := promptPlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		name := promptPlaceholder.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return match
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("prompt template has no value for %s (pass --var %s=...)", strings.Join(missing, ", "), missing[0])
+	}
+	return rendered, nil
+}
+
+// ParsePromptVars parses --var key=value pairs; later pairs win.
+func ParsePromptVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !promptVarName.MatchString(key) {
+			return nil, fmt.Errorf("invalid --var %q: use key=value, where key starts with a letter or _", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
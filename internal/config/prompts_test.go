@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListPromptPresets(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"review.md":  "---\ndescription: \"Strict reviewer\"\n---\nReview code in {{cwd}}.\n",
+		"plain.txt":  "Answer briefly.\n",
+		"notes.json": "{}",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	presets, err := ListPromptPresets(dir)
+	if err != nil {
+		t.Fatalf("list presets: %v", err)
+	}
+	if len(presets) != 2 || presets[0].Name != "plain" || presets[1].Name != "review" {
+		t.Fatalf("expected plain and review presets, got %+v", presets)
+	}
+	if presets[1].Description != "Strict reviewer" || presets[1].Template != "Review code in {{cwd}}." {
+		t.Fatalf("expected frontmatter split from the template, got %+v", presets[1])
+	}
+	if _, err := LoadPromptPreset(dir, "missing"); err == nil || !strings.Contains(err.Error(), "available: plain, review") {
+		t.Fatalf("expected unknown preset error to list presets, got %v", err)
+	}
+	if presets, err := ListPromptPresets(filepath.Join(dir, "absent")); err != nil || presets != nil {
+		t.Fatalf("expected a missing dir to have no presets, got %+v, %v", presets, err)
+	}
+}
+
+func TestRenderPromptTemplate(t *testing.T) {
+	vars, err := ParsePromptVars([]string{"team=core", "tone=terse", "tone=friendly"})
+	if err != nil {
+		t.Fatalf("parse vars: %v", err)
+	}
+
+	rendered, err := RenderPromptTemplate("You help {{team}} in a {{ tone }} voice.", vars)
+	if err != nil || rendered != "You help core in a friendly voice." {
+		t.Fatalf("unexpected render %q, %v", rendered, err)
+	}
+	if _, err := RenderPromptTemplate("{{team}} {{owner}} {{owner}}", vars); err == nil || !strings.Contains(err.Error(), "no value for owner (pass --var owner=...)") {
+		t.Fatalf("expected missing variable error, got %v", err)
+	}
+	for _, bad := range []string{"novalue", "=x", "1st=x"} {
+		if _, err := ParsePromptVars([]string{bad}); err == nil {
+			t.Fatalf("expected --var %q to be rejected", bad)
+		}
+	}
+}
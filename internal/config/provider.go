@@ -6,32 +6,211 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/keyring"
+	"github.com/openclaude/openclaude/internal/redact"
 )
 
 // ProviderConfig defines how OpenClaude connects to an OpenAI-compatible gateway.
 type ProviderConfig struct {
 	// APIBaseURL is the base URL for OpenAI-compatible chat completions.
 	APIBaseURL string `json:"api_base_url"`
-	// APIKey is the bearer token used for Authorization.
+	// APIKey is the bearer token used for Authorization (the api-key header for Azure).
 	APIKey string `json:"api_key"`
+	// APIKeyKeyring names the OS keyring entry holding the API key (see `claude auth set`).
+	APIKeyKeyring string `json:"api_key_keyring,omitempty"`
+	// APIFlavor selects the gateway API: openai (default) or azure.
+	APIFlavor string `json:"api_flavor"`
+	// Azure configures deployments and the API version for the azure flavor.
+	Azure AzureConfig `json:"azure"`
+	// Auth obtains refreshed bearer tokens instead of using the static api_key.
+	Auth *AuthConfig `json:"auth,omitempty"`
 	// TimeoutMS configures request timeout in milliseconds.
 	TimeoutMS int `json:"timeout_ms"`
+	// APITimeoutMS caps each API call, streaming ones included (default: timeout_ms for
+	// non-streaming calls and no cap for streams, which are bounded by StreamIdleTimeoutMS).
+	APITimeoutMS int `json:"api_timeout_ms"`
+	// StreamIdleTimeoutMS caps the silence before and between stream events (negative disables it).
+	StreamIdleTimeoutMS int `json:"stream_idle_timeout_ms"`
+	// TurnTimeoutMS caps a whole turn, API calls and tool runs included (0 disables it).
+	TurnTimeoutMS int `json:"turn_timeout_ms"`
 	// DefaultModel is used when no CLI or settings override is provided.
 	DefaultModel string `json:"default_model"`
 	// ModelAliases maps friendly names (e.g., opus) to provider model ids.
 	ModelAliases map[string]string `json:"model_aliases"`
 	// Pricing holds per-model pricing metadata for budget enforcement.
 	Pricing map[string]ModelPricing `json:"pricing"`
+	// Models describes provider models (context window, output cap, pricing) keyed by id.
+	Models map[string]ModelInfo `json:"models"`
 	// Telemetry controls optional telemetry behavior.
 	Telemetry TelemetryConfig `json:"telemetry"`
+	// MaxParallelTasks caps concurrent Task subagents per turn (1 disables parallelism).
+	MaxParallelTasks int `json:"max_parallel_tasks"`
+	// ThinkingFormat selects how thinking budgets are sent: openai, anthropic, or none.
+	ThinkingFormat string `json:"thinking_format"`
+	// ServerTools are provider-side tools, such as {"type": "web_search"},
+	// sent verbatim in each request's tools array; the provider runs them.
+	ServerTools []map[string]any `json:"server_tools"`
+	// InputHistorySize caps persisted TUI input history per project (negative disables it).
+	InputHistorySize int `json:"input_history_size"`
+	// LargeMessageTokens is the estimated size at which the TUI holds a prompt
+	// and offers to attach it as a file instead (negative disables the check).
+	LargeMessageTokens int `json:"large_message_tokens"`
+	// Python configures the RunPython tool.
+	Python PythonConfig `json:"python"`
+	// DisableToolchainDetection skips the project toolchain summary in the system prompt.
+	DisableToolchainDetection bool `json:"disable_toolchain_detection"`
+	// DisableGitStatus skips git branch and status detection for the prompt, init event, and status line.
+	DisableGitStatus bool `json:"disable_git_status"`
+	// DisableCostLedger stops appending per-request usage and cost to ~/.openclaude/ledger.jsonl.
+	DisableCostLedger bool `json:"disable_cost_ledger"`
+	// ToolResultMaxBytes caps tool results added to the conversation, keyed by
+	// tool name with "default" for the rest (default DefaultToolResultMaxBytes;
+	// zero or less disables a cap).
+	ToolResultMaxBytes map[string]int `json:"tool_result_max_bytes"`
+	// Redaction configures masking of credentials in tool output and prompts.
+	Redaction RedactionConfig `json:"redaction"`
+	// RequestParams are max_tokens, temperature, top_p, and stop, sent with
+	// every request unless the session overrides them.
+	RequestParams
+}
+
+// RedactionConfig configures secret redaction, an OpenClaude extension.
+type RedactionConfig struct {
+	// Disabled stops masking credentials, including the built-in formats.
+	Disabled bool `json:"disabled"`
+	// Patterns are extra regular expressions to mask; with a capture group,
+	// only the first group is masked.
+	Patterns []string `json:"patterns"`
+}
+
+// PythonConfig configures the RunPython tool, an OpenClaude extension.
+type PythonConfig struct {
+	// Enabled offers RunPython alongside the default tools.
+	Enabled bool `json:"enabled"`
+	// Interpreter is the Python executable (default python3).
+	Interpreter string `json:"interpreter"`
+	// Venv runs snippets in a per-session virtualenv so they can install packages.
+	Venv bool `json:"venv"`
+	// TimeoutMS bounds each snippet's wall-clock time (default 60000).
+	TimeoutMS int `json:"timeout_ms"`
+	// MemoryMB caps the interpreter address space (default 2048; negative disables).
+	MemoryMB int `json:"memory_mb"`
 }
 
+// AzureConfig configures the Azure OpenAI API flavor.
+type AzureConfig struct {
+	// APIVersion is sent as the api-version query parameter (default DefaultAzureAPIVersion).
+	APIVersion string `json:"api_version"`
+	// Deployments maps model ids to Azure deployment names; unmapped models are used as deployment names.
+	Deployments map[string]string `json:"deployments"`
+}
+
+// AuthConfig configures short-lived bearer tokens, an OpenClaude extension.
+type AuthConfig struct {
+	// Type selects the token source: token_command or client_credentials.
+	Type string `json:"type"`
+	// Command prints a token (or an OAuth token response) for token_command.
+	Command string `json:"command,omitempty"`
+	// TokenURL is the OAuth token endpoint for client_credentials.
+	TokenURL string `json:"token_url,omitempty"`
+	// ClientID identifies the OAuth client for client_credentials.
+	ClientID string `json:"client_id,omitempty"`
+	// ClientSecret authenticates the OAuth client for client_credentials.
+	ClientSecret string `json:"client_secret,omitempty"`
+	// Scopes are requested with client_credentials.
+	Scopes []string `json:"scopes,omitempty"`
+	// Audience is sent with client_credentials for servers that require it.
+	Audience string `json:"audience,omitempty"`
+	// TokenTTLSeconds is how long a token without a reported expiry is reused (default DefaultTokenTTLSeconds).
+	TokenTTLSeconds int `json:"token_ttl_seconds,omitempty"`
+}
+
+const (
+	// AuthTypeTokenCommand runs a command such as `gcloud auth print-access-token`.
+	AuthTypeTokenCommand = "token_command"
+	// AuthTypeClientCredentials uses the OAuth 2.0 client credentials grant.
+	AuthTypeClientCredentials = "client_credentials"
+	// DefaultTokenTTLSeconds is the assumed lifetime of tokens that do not report one.
+	DefaultTokenTTLSeconds = 300
+)
+
+const (
+	// APIFlavorOpenAI sends OpenAI chat/completions requests with a bearer token.
+	APIFlavorOpenAI = "openai"
+	// APIFlavorAzure sends Azure OpenAI deployment requests with an api-key header.
+	APIFlavorAzure = "azure"
+	// DefaultAzureAPIVersion is the Azure OpenAI API version used when none is configured.
+	DefaultAzureAPIVersion = "2024-10-21"
+)
+
+// DefaultStreamIdleTimeoutMS is used when stream_idle_timeout_ms is not configured.
+const DefaultStreamIdleTimeoutMS = 120000
+
+// DefaultMaxParallelTasks is used when max_parallel_tasks is not configured.
+const DefaultMaxParallelTasks = 4
+
+// DefaultInputHistorySize is used when input_history_size is not configured.
+const DefaultInputHistorySize = 200
+
+// DefaultLargeMessageTokens is used when large_message_tokens is not configured.
+const DefaultLargeMessageTokens = 20000
+
+// DefaultToolResultMaxBytes is the tool result cap when tool_result_max_bytes has no "default" entry.
+const DefaultToolResultMaxBytes = 100000
+
+const (
+	// ThinkingFormatOpenAI maps thinking budgets to reasoning_effort.
+	ThinkingFormatOpenAI = "openai"
+	// ThinkingFormatAnthropic sends thinking budgets as {"thinking": {"budget_tokens": N}}.
+	ThinkingFormatAnthropic = "anthropic"
+	// ThinkingFormatNone never sends thinking parameters.
+	ThinkingFormatNone = "none"
+)
+
 // ModelPricing defines per-model pricing for budget enforcement.
 type ModelPricing struct {
 	// InputPer1M is the cost per 1M prompt tokens.
 	InputPer1M float64 `json:"input_per_1m"`
 	// OutputPer1M is the cost per 1M completion tokens.
 	OutputPer1M float64 `json:"output_per_1m"`
+	// CacheReadPer1M is the cost per 1M cache read tokens (defaults to InputPer1M).
+	CacheReadPer1M float64 `json:"cache_read_per_1m,omitempty"`
+	// CacheWritePer1M is the cost per 1M cache write tokens (defaults to InputPer1M).
+	CacheWritePer1M float64 `json:"cache_write_per_1m,omitempty"`
+	// WebSearchPer1K is the cost per 1K server-side web search requests.
+	WebSearchPer1K float64 `json:"web_search_per_1k,omitempty"`
+	// WebFetchPer1K is the cost per 1K server-side web fetch requests.
+	WebFetchPer1K float64 `json:"web_fetch_per_1k,omitempty"`
+}
+
+// ModelInfo describes limits and pricing for a single provider model.
+type ModelInfo struct {
+	// Description is a short human-readable summary shown by /model.
+	Description string `json:"description,omitempty"`
+	// ContextWindow is the maximum prompt size in tokens (0 when unknown).
+	ContextWindow int `json:"context_window,omitempty"`
+	// MaxOutputTokens caps completion tokens and is sent as max_tokens when set.
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+	// Pricing prices this model when the top-level pricing map has no entry
+	// for it; see ModelPricingFor.
+	Pricing *ModelPricing `json:"pricing,omitempty"`
+	// ThinkingFormat overrides the provider thinking_format for this model.
+	ThinkingFormat string `json:"thinking_format,omitempty"`
+}
+
+// ModelCatalogEntry is a model known to the provider config with the aliases pointing at it.
+type ModelCatalogEntry struct {
+	// Name is the provider model id.
+	Name string
+	// Aliases lists friendly names that resolve to this model.
+	Aliases []string
+	// Info holds configured limits and pricing (zero when not configured).
+	Info ModelInfo
+	// Default reports whether this is the provider default model.
+	Default bool
 }
 
 // TelemetryConfig controls optional telemetry.
@@ -81,15 +260,92 @@ func LoadProviderConfig(path string) (*ProviderConfig, error) {
 		return nil, fmt.Errorf("parse provider config: %w", err)
 	}
 
-	// Validate required fields.
-	if cfg.APIBaseURL == "" || cfg.APIKey == "" || cfg.DefaultModel == "" {
+	// A keyring reference keeps the key itself out of the file.
+	if cfg.APIKeyKeyring != "" {
+		if cfg.APIKey != "" {
+			return nil, fmt.Errorf("%w: set either api_key or api_key_keyring, not both", ErrProviderConfigInvalid)
+		}
+		key, err := keyring.Get(cfg.APIKeyKeyring)
+		if err != nil {
+			return nil, fmt.Errorf("read api key %q from the keyring: %w", cfg.APIKeyKeyring, err)
+		}
+		cfg.APIKey = key
+	}
+
+	// Validate required fields; a token source replaces the API key.
+	if cfg.APIBaseURL == "" || (cfg.APIKey == "" && cfg.Auth == nil) || cfg.DefaultModel == "" {
 		return nil, ErrProviderConfigInvalid
 	}
+	if cfg.Auth != nil {
+		if err := validateAuth(cfg.Auth); err != nil {
+			return nil, err
+		}
+	}
 
 	// Apply defaults for optional fields.
 	if cfg.TimeoutMS <= 0 {
 		cfg.TimeoutMS = 600000
 	}
+	if cfg.APITimeoutMS < 0 || cfg.TurnTimeoutMS < 0 {
+		return nil, fmt.Errorf("%w: api_timeout_ms and turn_timeout_ms must not be negative", ErrProviderConfigInvalid)
+	}
+	if cfg.StreamIdleTimeoutMS == 0 {
+		cfg.StreamIdleTimeoutMS = DefaultStreamIdleTimeoutMS
+	}
+
+	if cfg.MaxParallelTasks <= 0 {
+		cfg.MaxParallelTasks = DefaultMaxParallelTasks
+	}
+
+	if cfg.InputHistorySize == 0 {
+		cfg.InputHistorySize = DefaultInputHistorySize
+	}
+
+	if cfg.LargeMessageTokens == 0 {
+		cfg.LargeMessageTokens = DefaultLargeMessageTokens
+	}
+
+	if _, ok := cfg.ToolResultMaxBytes["default"]; !ok {
+		if cfg.ToolResultMaxBytes == nil {
+			cfg.ToolResultMaxBytes = map[string]int{}
+		}
+		cfg.ToolResultMaxBytes["default"] = DefaultToolResultMaxBytes
+	}
+
+	switch cfg.APIFlavor {
+	case "":
+		cfg.APIFlavor = APIFlavorOpenAI
+	case APIFlavorOpenAI:
+	case APIFlavorAzure:
+		if cfg.Azure.APIVersion == "" {
+			cfg.Azure.APIVersion = DefaultAzureAPIVersion
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown api_flavor %q (use openai or azure)", ErrProviderConfigInvalid, cfg.APIFlavor)
+	}
+
+	if err := validateThinkingFormat(cfg.ThinkingFormat); err != nil {
+		return nil, err
+	}
+	if cfg.ThinkingFormat == "" {
+		cfg.ThinkingFormat = ThinkingFormatOpenAI
+	}
+
+	if err := cfg.RequestParams.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderConfigInvalid, err)
+	}
+
+	for _, expression := range cfg.Redaction.Patterns {
+		if _, err := redact.Compile(expression); err != nil {
+			return nil, fmt.Errorf("%w: redaction.patterns: %v", ErrProviderConfigInvalid, err)
+		}
+	}
+
+	for index, tool := range cfg.ServerTools {
+		if err := validateServerTool(tool); err != nil {
+			return nil, fmt.Errorf("%w: server_tools[%d] %v", ErrProviderConfigInvalid, index, err)
+		}
+	}
 
 	if cfg.ModelAliases == nil {
 		cfg.ModelAliases = make(map[string]string)
@@ -99,9 +355,94 @@ func LoadProviderConfig(path string) (*ProviderConfig, error) {
 		cfg.Pricing = make(map[string]ModelPricing)
 	}
 
+	if cfg.Models == nil {
+		cfg.Models = make(map[string]ModelInfo)
+	}
+
+	if err := validateModelAliases(cfg.ModelAliases); err != nil {
+		return nil, err
+	}
+
+	// Catalog pricing feeds budget enforcement with the same precedence /model shows.
+	for name, info := range cfg.Models {
+		if err := validateThinkingFormat(info.ThinkingFormat); err != nil {
+			return nil, fmt.Errorf("model %s: %w", name, err)
+		}
+		if pricing, ok := ModelPricingFor(&cfg, name); ok {
+			cfg.Pricing[name] = pricing
+		}
+	}
+
 	return &cfg, nil
 }
 
+// validateModelAliases rejects aliases that differ only by case but point at
+// different models, since case-insensitive lookup could not tell them apart.
+func validateModelAliases(aliases map[string]string) error {
+	seen := map[string]string{}
+	for _, alias := range sortedAliases(aliases) {
+		key := strings.ToLower(alias)
+		if previous, ok := seen[key]; ok && aliases[previous] != aliases[alias] {
+			return fmt.Errorf("%w: model_aliases %q and %q differ only by case but point at different models", ErrProviderConfigInvalid, previous, alias)
+		}
+		seen[key] = alias
+	}
+	return nil
+}
+
+// sortedAliases returns the alias names in a stable order.
+func sortedAliases(aliases map[string]string) []string {
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateAuth checks the fields the auth type needs and applies the token TTL default.
+func validateAuth(auth *AuthConfig) error {
+	switch auth.Type {
+	case AuthTypeTokenCommand:
+		if strings.TrimSpace(auth.Command) == "" {
+			return fmt.Errorf("%w: auth type token_command needs a command", ErrProviderConfigInvalid)
+		}
+	case AuthTypeClientCredentials:
+		if auth.TokenURL == "" || auth.ClientID == "" || auth.ClientSecret == "" {
+			return fmt.Errorf("%w: auth type client_credentials needs token_url, client_id, and client_secret", ErrProviderConfigInvalid)
+		}
+	default:
+		return fmt.Errorf("%w: unknown auth type %q (use token_command or client_credentials)", ErrProviderConfigInvalid, auth.Type)
+	}
+	if auth.TokenTTLSeconds <= 0 {
+		auth.TokenTTLSeconds = DefaultTokenTTLSeconds
+	}
+	return nil
+}
+
+// validateServerTool requires a type, and rejects function tools, which
+// would reach the model without a local implementation.
+func validateServerTool(tool map[string]any) error {
+	kind, _ := tool["type"].(string)
+	switch kind {
+	case "":
+		return errors.New("needs a string \"type\"")
+	case "function":
+		return errors.New("cannot be a function tool")
+	}
+	return nil
+}
+
+// validateThinkingFormat rejects unknown thinking_format values so typos fail loudly.
+func validateThinkingFormat(format string) error {
+	switch format {
+	case "", ThinkingFormatOpenAI, ThinkingFormatAnthropic, ThinkingFormatNone:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown thinking_format %q (use openai, anthropic, or none)", ErrProviderConfigInvalid, format)
+	}
+}
+
 // ResolveModel returns the resolved model for the session.
 func ResolveModel(cfg *ProviderConfig, cliModel string, settingsModel string) string {
 	// CLI input takes precedence over settings.
@@ -115,6 +456,7 @@ func ResolveModel(cfg *ProviderConfig, cliModel string, settingsModel string) st
 }
 
 // aliasModel resolves an alias to a provider model name.
+// Aliases match case-insensitively, and "default" maps to the provider default model.
 func aliasModel(cfg *ProviderConfig, name string) string {
 	if cfg == nil {
 		return name
@@ -122,5 +464,86 @@ func aliasModel(cfg *ProviderConfig, name string) string {
 	if aliased, ok := cfg.ModelAliases[name]; ok {
 		return aliased
 	}
+	// Sorted order keeps the fallback deterministic for configs built in code,
+	// which skip the load-time collision check.
+	for _, alias := range sortedAliases(cfg.ModelAliases) {
+		if strings.EqualFold(alias, name) {
+			return cfg.ModelAliases[alias]
+		}
+	}
+	if strings.EqualFold(name, "default") && cfg.DefaultModel != "" {
+		return cfg.DefaultModel
+	}
 	return name
 }
+
+// ModelPricingFor returns the price of a resolved model id. The top-level
+// pricing map wins over a models catalog entry's pricing, so /model and budget
+// accounting always agree.
+func ModelPricingFor(cfg *ProviderConfig, model string) (ModelPricing, bool) {
+	if cfg == nil {
+		return ModelPricing{}, false
+	}
+	if pricing, ok := cfg.Pricing[model]; ok {
+		return pricing, true
+	}
+	if info, ok := cfg.Models[model]; ok && info.Pricing != nil {
+		return *info.Pricing, true
+	}
+	return ModelPricing{}, false
+}
+
+// LookupModelInfo returns catalog metadata for a resolved model id, with the
+// pricing ModelPricingFor resolves.
+func LookupModelInfo(cfg *ProviderConfig, model string) (ModelInfo, bool) {
+	if cfg == nil {
+		return ModelInfo{}, false
+	}
+	info, ok := cfg.Models[model]
+	if pricing, priced := ModelPricingFor(cfg, model); priced {
+		info.Pricing = &pricing
+		ok = true
+	}
+	return info, ok
+}
+
+// ModelCatalog lists every model referenced by the provider config, sorted by name.
+// It merges the default model, alias targets, catalog entries, and priced models.
+func ModelCatalog(cfg *ProviderConfig) []ModelCatalogEntry {
+	if cfg == nil {
+		return nil
+	}
+	entries := map[string]*ModelCatalogEntry{}
+	add := func(name string) *ModelCatalogEntry {
+		entry, ok := entries[name]
+		if !ok {
+			info, _ := LookupModelInfo(cfg, name)
+			entry = &ModelCatalogEntry{Name: name, Info: info, Default: name == cfg.DefaultModel}
+			entries[name] = entry
+		}
+		return entry
+	}
+	if cfg.DefaultModel != "" {
+		add(cfg.DefaultModel)
+	}
+	for name := range cfg.Models {
+		add(name)
+	}
+	for name := range cfg.Pricing {
+		add(name)
+	}
+	for alias, target := range cfg.ModelAliases {
+		entry := add(target)
+		entry.Aliases = append(entry.Aliases, alias)
+	}
+
+	catalog := make([]ModelCatalogEntry, 0, len(entries))
+	for _, entry := range entries {
+		sort.Strings(entry.Aliases)
+		catalog = append(catalog, *entry)
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Name < catalog[j].Name
+	})
+	return catalog
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RequestParams are optional chat completion parameters. Unset fields are
+// left out of requests, so the gateway's defaults apply.
+type RequestParams struct {
+	// MaxTokens caps output tokens for every model, replacing the catalog's
+	// max_output_tokens (0 leaves the cap to the catalog or the gateway).
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// Temperature is the sampling temperature, from 0 to 2.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP is the nucleus sampling probability mass, from 0 to 1.
+	TopP *float64 `json:"top_p,omitempty"`
+	// Stop lists sequences that end the response when generated.
+	Stop []string `json:"stop,omitempty"`
+}
+
+// MaxStopSequences is the most stop sequences OpenAI-compatible APIs accept.
+const MaxStopSequences = 4
+
+// Merge returns p with every field set in override replacing its own.
+func (p RequestParams) Merge(override RequestParams) RequestParams {
+	if override.MaxTokens > 0 {
+		p.MaxTokens = override.MaxTokens
+	}
+	if override.Temperature != nil {
+		p.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		p.TopP = override.TopP
+	}
+	if len(override.Stop) > 0 {
+		p.Stop = override.Stop
+	}
+	return p
+}
+
+// Validate rejects values gateways would refuse, so a typo fails before the
+// first request instead of on it.
+func (p RequestParams) Validate() error {
+	if p.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must be positive, got %d", p.MaxTokens)
+	}
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *p.Temperature)
+	}
+	if p.TopP != nil && (*p.TopP < 0 || *p.TopP > 1) {
+		return fmt.Errorf("top_p must be between 0 and 1, got %g", *p.TopP)
+	}
+	if len(p.Stop) > MaxStopSequences {
+		return fmt.Errorf("stop accepts at most %d sequences, got %d", MaxStopSequences, len(p.Stop))
+	}
+	for _, sequence := range p.Stop {
+		if sequence == "" {
+			return errors.New("stop sequences must not be empty")
+		}
+	}
+	return nil
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // Settings represent a subset of Claude-style settings relevant to OpenClaude.
@@ -15,10 +17,50 @@ type Settings struct {
 	Model string
 	// EnabledPlugins mirrors Claude Code settings for compatibility.
 	EnabledPlugins map[string]bool
+	// Permissions holds the allow/deny rules and default permission mode.
+	Permissions PermissionSettings
+	// Env holds the settings "env" block; variables apply to the session (e.g., OTEL_* telemetry).
+	Env map[string]string
+	// Sandbox holds the "sandbox" block that confines Bash with the OS sandbox.
+	Sandbox SandboxSettings
+	// SuggestCommits offers /commit after turns that edit files (OpenClaude extension).
+	SuggestCommits bool
+	// CleanupPeriodDays is how long sessions are kept before startup prunes them.
+	// It is DefaultCleanupPeriodDays when unset; zero or less disables pruning.
+	CleanupPeriodDays int
+	// ToolOverrides replaces the descriptions and parameter schemas of built-in
+	// tools, keyed by tool name (OpenClaude extension).
+	ToolOverrides map[string]ToolOverride
+	// IgnorePatterns lists gitignore-style patterns that Glob and Grep skip on top of
+	// .gitignore, .claudeignore, and .openclaudeignore files (OpenClaude extension).
+	IgnorePatterns []string
+	// HidePatterns lists gitignore-style patterns applied after .claudeignore
+	// and .openclaudeignore files, hiding paths from every file tool and
+	// @-mentions; "!pattern" re-includes a path (OpenClaude extension).
+	HidePatterns []string
+	// Hooks holds the "hooks" block keyed by hook event name (e.g., "Stop").
+	Hooks map[string][]HookMatcher
+	// Theme names the TUI color theme: auto, dark, light, ansi, or custom.
+	Theme string
+	// ThemeColors maps TUI color names to hex colors for the custom theme
+	// (OpenClaude extension).
+	ThemeColors map[string]string
+	// PreferredNotifChannel picks how the TUI notifies while unfocused:
+	// auto, terminal_bell, iterm2, iterm2_with_bell, command, or
+	// notifications_disabled.
+	PreferredNotifChannel string
+	// NotifyCommand is run for notifications on the command channel
+	// (OpenClaude extension).
+	NotifyCommand string
+	// NotifyCommandSource is the settings source NotifyCommand came from.
+	NotifyCommandSource string
 	// Raw retains the full JSON map for future compatibility.
 	Raw map[string]any
 }
 
+// DefaultCleanupPeriodDays matches Claude Code's default transcript retention.
+const DefaultCleanupPeriodDays = 30
+
 // LoadClaudeSettings loads settings from user/project/local sources and merges them.
 func LoadClaudeSettings(cwd string, sources []string, extraSettings string) (*Settings, error) {
 	sourceSet := normalizeSources(sources)
@@ -40,7 +82,7 @@ func LoadClaudeSettings(cwd string, sources []string, extraSettings string) (*Se
 			}
 			return nil, err
 		}
-		merged = mergeSettings(merged, settings)
+		merged = mergeSettings(merged, settings.withSource(item.Source))
 	}
 
 	if extraSettings != "" {
@@ -48,16 +90,123 @@ func LoadClaudeSettings(cwd string, sources []string, extraSettings string) (*Se
 		if err != nil {
 			return nil, err
 		}
-		merged = mergeSettings(merged, override)
+		merged = mergeSettings(merged, override.withSource(SettingsSourceFlag))
 	}
 
 	if merged == nil {
-		return &Settings{Raw: map[string]any{}}, nil
+		return &Settings{Raw: map[string]any{}, CleanupPeriodDays: DefaultCleanupPeriodDays}, nil
 	}
 
 	return merged, nil
 }
 
+// Settings sources, in increasing precedence. Project and local settings live
+// in the workspace, so a cloned repository controls them.
+const (
+	SettingsSourceUser    = "user"
+	SettingsSourceProject = "project"
+	SettingsSourceLocal   = "local"
+	// SettingsSourceFlag is the --settings flag.
+	SettingsSourceFlag = "flag"
+)
+
+// IsWorkspaceSource reports whether source is read from the workspace.
+func IsWorkspaceSource(source string) bool {
+	return source == SettingsSourceProject || source == SettingsSourceLocal
+}
+
+// withSource records source on the hooks and notify command that run shell
+// commands, so they can be dropped in an untrusted workspace.
+func (s *Settings) withSource(source string) *Settings {
+	if s == nil {
+		return nil
+	}
+	for _, matchers := range s.Hooks {
+		for index := range matchers {
+			matchers[index].Source = source
+		}
+	}
+	if s.NotifyCommand != "" {
+		s.NotifyCommandSource = source
+	}
+	return s
+}
+
+// WithoutWorkspaceCommands returns a copy of the settings without the hooks
+// and notify command from project or local settings, for a workspace the
+// user has not trusted.
+func (s *Settings) WithoutWorkspaceCommands() *Settings {
+	if s == nil {
+		return nil
+	}
+	filtered := *s
+	filtered.Hooks = nil
+	for event, matchers := range s.Hooks {
+		for _, matcher := range matchers {
+			if IsWorkspaceSource(matcher.Source) {
+				continue
+			}
+			if filtered.Hooks == nil {
+				filtered.Hooks = map[string][]HookMatcher{}
+			}
+			filtered.Hooks[event] = append(filtered.Hooks[event], matcher)
+		}
+	}
+	if IsWorkspaceSource(s.NotifyCommandSource) {
+		filtered.NotifyCommand, filtered.NotifyCommandSource = "", ""
+	}
+	return &filtered
+}
+
+// PermissionSettings mirrors the Claude Code "permissions" settings block.
+type PermissionSettings struct {
+	// Allow lists rules approved without prompting (e.g., "Bash", "Read").
+	Allow []string
+	// Deny lists rules whose tools are removed from the session.
+	Deny []string
+	// DefaultMode is the permission mode used when --permission-mode is not set.
+	DefaultMode string
+}
+
+// SandboxSettings mirrors the "sandbox" settings block. Claude Code defines
+// enabled; writableDirs and disableNetwork are OpenClaude extensions.
+type SandboxSettings struct {
+	// Enabled runs Bash commands under sandbox-exec (macOS) or Landlock (Linux).
+	Enabled bool
+	// WritableDirs lists extra directories commands may write to.
+	WritableDirs []string
+	// DisableNetwork cuts sandboxed commands off from the network.
+	DisableNetwork bool
+}
+
+// ToolOverride is one "toolOverrides" entry. Empty fields keep the built-in value.
+type ToolOverride struct {
+	// Description replaces the tool description sent to the model.
+	Description string
+	// Schema replaces the tool's JSON parameter schema.
+	Schema map[string]any
+}
+
+// HookMatcher is one entry of a hook event list: the commands to run when the
+// matcher accepts the event's subject (a tool name, or "auto"/"manual" for
+// PreCompact). An empty matcher accepts everything.
+type HookMatcher struct {
+	// Matcher is a name, "a|b" list, or regular expression.
+	Matcher string
+	// Hooks lists the commands to run.
+	Hooks []HookCommand
+	// Source is the settings source the entry came from.
+	Source string
+}
+
+// HookCommand is one "type": "command" hook.
+type HookCommand struct {
+	// Command is run with sh -c (cmd /C on Windows) and receives the event as JSON on stdin.
+	Command string
+	// Timeout bounds the command; zero uses the default of 60 seconds.
+	Timeout time.Duration
+}
+
 type settingsSource struct {
 	Source string
 	Path   string
@@ -72,9 +221,9 @@ func settingsPaths(cwd string) ([]settingsSource, error) {
 	projectRoot := findProjectRoot(cwd)
 
 	return []settingsSource{
-		{Source: "user", Path: filepath.Join(home, ".claude", "settings.json")},
-		{Source: "project", Path: filepath.Join(projectRoot, ".claude", "settings.json")},
-		{Source: "local", Path: filepath.Join(cwd, ".claude", "settings.json")},
+		{Source: SettingsSourceUser, Path: filepath.Join(home, ".claude", "settings.json")},
+		{Source: SettingsSourceProject, Path: filepath.Join(projectRoot, ".claude", "settings.json")},
+		{Source: SettingsSourceLocal, Path: filepath.Join(cwd, ".claude", "settings.json")},
 	}, nil
 }
 
@@ -123,8 +272,11 @@ func parseSettings(raw []byte) (*Settings, error) {
 	}
 
 	settings := &Settings{
-		Raw:            data,
-		EnabledPlugins: map[string]bool{},
+		Raw:               data,
+		EnabledPlugins:    map[string]bool{},
+		Env:               map[string]string{},
+		CleanupPeriodDays: DefaultCleanupPeriodDays,
+		ToolOverrides:     map[string]ToolOverride{},
 	}
 
 	if model, ok := data["model"].(string); ok {
@@ -140,9 +292,161 @@ func parseSettings(raw []byte) (*Settings, error) {
 		}
 	}
 
+	if permissions, ok := data["permissions"].(map[string]any); ok {
+		settings.Permissions.Allow = settingsStringList(permissions["allow"])
+		settings.Permissions.Deny = settingsStringList(permissions["deny"])
+		if mode, ok := permissions["defaultMode"].(string); ok {
+			settings.Permissions.DefaultMode = mode
+		}
+	}
+
+	if sandbox, ok := data["sandbox"].(map[string]any); ok {
+		settings.Sandbox.Enabled, _ = sandbox["enabled"].(bool)
+		settings.Sandbox.DisableNetwork, _ = sandbox["disableNetwork"].(bool)
+		settings.Sandbox.WritableDirs = settingsStringList(sandbox["writableDirs"])
+	}
+
+	settings.SuggestCommits, _ = data["suggestCommits"].(bool)
+	settings.Theme, _ = data["theme"].(string)
+	settings.PreferredNotifChannel, _ = data["preferredNotifChannel"].(string)
+	settings.NotifyCommand, _ = data["notifyCommand"].(string)
+	if colors, ok := data["themeColors"]; ok {
+		parsed, err := parseThemeColors(colors)
+		if err != nil {
+			return nil, err
+		}
+		settings.ThemeColors = parsed
+	}
+	settings.IgnorePatterns = settingsStringList(data["ignorePatterns"])
+	settings.HidePatterns = settingsStringList(data["hidePatterns"])
+
+	// JSON numbers decode as float64; fractional days are truncated.
+	if days, ok := data["cleanupPeriodDays"].(float64); ok {
+		settings.CleanupPeriodDays = int(days)
+	}
+
+	if overrides, ok := data["toolOverrides"].(map[string]any); ok {
+		for name, value := range overrides {
+			override, err := parseToolOverride(name, value)
+			if err != nil {
+				return nil, err
+			}
+			settings.ToolOverrides[name] = override
+		}
+	}
+
+	if hooks, ok := data["hooks"].(map[string]any); ok {
+		settings.Hooks = parseHooks(hooks)
+	}
+
+	// Claude Code only accepts string values in env; others are ignored.
+	if env, ok := data["env"].(map[string]any); ok {
+		for key, value := range env {
+			if text, ok := value.(string); ok {
+				settings.Env[key] = text
+			}
+		}
+	}
+
 	return settings, nil
 }
 
+// parseToolOverride validates one toolOverrides entry. A replacement schema
+// must describe an object, since tool arguments are always a JSON object.
+func parseToolOverride(name string, value any) (ToolOverride, error) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return ToolOverride{}, fmt.Errorf("parse settings: toolOverrides.%s must be an object", name)
+	}
+	var override ToolOverride
+	if description, ok := fields["description"]; ok {
+		text, isText := description.(string)
+		if !isText {
+			return ToolOverride{}, fmt.Errorf("parse settings: toolOverrides.%s.description must be a string", name)
+		}
+		override.Description = strings.TrimSpace(text)
+	}
+	if schema, ok := fields["schema"]; ok {
+		object, isObject := schema.(map[string]any)
+		if !isObject || object["type"] != "object" {
+			return ToolOverride{}, fmt.Errorf("parse settings: toolOverrides.%s.schema must be a JSON schema with \"type\": \"object\"", name)
+		}
+		override.Schema = object
+	}
+	return override, nil
+}
+
+// hexColorPattern matches #rgb and #rrggbb colors.
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseThemeColors validates the "themeColors" object. Color names are
+// checked by the TUI, which owns the palette.
+func parseThemeColors(value any) (map[string]string, error) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("parse settings: themeColors must be an object")
+	}
+	colors := make(map[string]string, len(fields))
+	for name, field := range fields {
+		text, _ := field.(string)
+		if !hexColorPattern.MatchString(text) {
+			return nil, fmt.Errorf("parse settings: themeColors.%s must be a hex color like #d77757", name)
+		}
+		colors[name] = text
+	}
+	return colors, nil
+}
+
+// parseHooks reads the "hooks" block. Entries that are not command hooks
+// with a command are skipped, as Claude Code does for unknown hook types.
+func parseHooks(raw map[string]any) map[string][]HookMatcher {
+	hooks := map[string][]HookMatcher{}
+	for event, value := range raw {
+		entries, _ := value.([]any)
+		for _, item := range entries {
+			entry, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			matcher := HookMatcher{}
+			matcher.Matcher, _ = entry["matcher"].(string)
+			commands, _ := entry["hooks"].([]any)
+			for _, command := range commands {
+				fields, ok := command.(map[string]any)
+				if !ok || fields["type"] != "command" {
+					continue
+				}
+				text, _ := fields["command"].(string)
+				if strings.TrimSpace(text) == "" {
+					continue
+				}
+				// JSON numbers decode as float64; the timeout is in seconds.
+				seconds, _ := fields["timeout"].(float64)
+				matcher.Hooks = append(matcher.Hooks, HookCommand{Command: text, Timeout: time.Duration(seconds * float64(time.Second))})
+			}
+			if len(matcher.Hooks) > 0 {
+				hooks[event] = append(hooks[event], matcher)
+			}
+		}
+	}
+	return hooks
+}
+
+// settingsStringList extracts string entries from a JSON array, skipping other types.
+func settingsStringList(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if text, ok := item.(string); ok && strings.TrimSpace(text) != "" {
+			list = append(list, strings.TrimSpace(text))
+		}
+	}
+	return list
+}
+
 // mergeSettings applies overlay values on top of the base settings.
 func mergeSettings(base *Settings, overlay *Settings) *Settings {
 	if base == nil {
@@ -155,6 +459,8 @@ func mergeSettings(base *Settings, overlay *Settings) *Settings {
 	merged := &Settings{
 		Model:          base.Model,
 		EnabledPlugins: map[string]bool{},
+		Env:            map[string]string{},
+		ToolOverrides:  map[string]ToolOverride{},
 		Raw:            map[string]any{},
 	}
 
@@ -176,6 +482,81 @@ func mergeSettings(base *Settings, overlay *Settings) *Settings {
 		merged.EnabledPlugins[key] = value
 	}
 
+	for key, value := range base.Env {
+		merged.Env[key] = value
+	}
+	for key, value := range overlay.Env {
+		merged.Env[key] = value
+	}
+
+	// A later source replaces a tool's override as a whole.
+	for name, override := range base.ToolOverrides {
+		merged.ToolOverrides[name] = override
+	}
+	for name, override := range overlay.ToolOverrides {
+		merged.ToolOverrides[name] = override
+	}
+
+	// A sandbox block replaces the one from lower-precedence sources as a whole.
+	merged.Sandbox = base.Sandbox
+	if _, ok := overlay.Raw["sandbox"]; ok {
+		merged.Sandbox = overlay.Sandbox
+	}
+
+	merged.CleanupPeriodDays = base.CleanupPeriodDays
+	if _, ok := overlay.Raw["cleanupPeriodDays"]; ok {
+		merged.CleanupPeriodDays = overlay.CleanupPeriodDays
+	}
+
+	merged.SuggestCommits = base.SuggestCommits
+	if _, ok := overlay.Raw["suggestCommits"]; ok {
+		merged.SuggestCommits = overlay.SuggestCommits
+	}
+
+	merged.Theme = base.Theme
+	if overlay.Theme != "" {
+		merged.Theme = overlay.Theme
+	}
+	merged.PreferredNotifChannel = base.PreferredNotifChannel
+	if overlay.PreferredNotifChannel != "" {
+		merged.PreferredNotifChannel = overlay.PreferredNotifChannel
+	}
+	// The TUI ignores a notify command from the workspace, so one there does
+	// not replace the user's.
+	merged.NotifyCommand, merged.NotifyCommandSource = base.NotifyCommand, base.NotifyCommandSource
+	userCommand := base.NotifyCommand != "" && !IsWorkspaceSource(base.NotifyCommandSource)
+	if overlay.NotifyCommand != "" && !(userCommand && IsWorkspaceSource(overlay.NotifyCommandSource)) {
+		merged.NotifyCommand, merged.NotifyCommandSource = overlay.NotifyCommand, overlay.NotifyCommandSource
+	}
+	// A palette replaces the one from lower-precedence sources as a whole.
+	merged.ThemeColors = base.ThemeColors
+	if _, ok := overlay.Raw["themeColors"]; ok {
+		merged.ThemeColors = overlay.ThemeColors
+	}
+
+	// Hooks accumulate across sources like Claude Code; every matching hook runs.
+	if len(base.Hooks) > 0 || len(overlay.Hooks) > 0 {
+		merged.Hooks = map[string][]HookMatcher{}
+		for event, matchers := range base.Hooks {
+			merged.Hooks[event] = append(merged.Hooks[event], matchers...)
+		}
+		for event, matchers := range overlay.Hooks {
+			merged.Hooks[event] = append(merged.Hooks[event], matchers...)
+		}
+	}
+
+	// Ignore patterns accumulate across sources like permission rules.
+	merged.IgnorePatterns = append(append([]string(nil), base.IgnorePatterns...), overlay.IgnorePatterns...)
+	merged.HidePatterns = append(append([]string(nil), base.HidePatterns...), overlay.HidePatterns...)
+
+	// Permission rules accumulate across sources like Claude Code; the mode is overridden.
+	merged.Permissions.Allow = append(append([]string(nil), base.Permissions.Allow...), overlay.Permissions.Allow...)
+	merged.Permissions.Deny = append(append([]string(nil), base.Permissions.Deny...), overlay.Permissions.Deny...)
+	merged.Permissions.DefaultMode = base.Permissions.DefaultMode
+	if overlay.Permissions.DefaultMode != "" {
+		merged.Permissions.DefaultMode = overlay.Permissions.DefaultMode
+	}
+
 	return merged
 }
 
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// SettingsScopes lists the writable settings scopes from lowest to highest precedence.
+var SettingsScopes = []string{"user", "project", "local"}
+
+// SettingsPath returns the settings file for a scope: user (~/.claude),
+// project (the repository root's .claude), or local (cwd's .claude).
+func SettingsPath(cwd string, scope string) (string, error) {
+	paths, err := settingsPaths(cwd)
+	if err != nil {
+		return "", err
+	}
+	for _, item := range paths {
+		if item.Source == scope {
+			return item.Path, nil
+		}
+	}
+	return "", fmt.Errorf("unknown settings scope %q (use %s)", scope, strings.Join(SettingsScopes, ", "))
+}
+
+// ReadSettingsMap reads a settings file as raw JSON; a missing file is empty.
+func ReadSettingsMap(path string) (map[string]any, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read settings: %w", err)
+	}
+	data := map[string]any{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("parse settings %s: %w", path, err)
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	return data, nil
+}
+
+// WriteSettingsMap atomically replaces a settings file, keeping the mode of an
+// existing file (new files are 0644 so project settings can be committed).
+func WriteSettingsMap(path string, data map[string]any) error {
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode settings: %w", err)
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create settings dir: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), ".settings-*.json")
+	if err != nil {
+		return fmt.Errorf("write settings: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(append(encoded, '\n')); err != nil {
+		temp.Close()
+		return fmt.Errorf("write settings: %w", err)
+	}
+	if err := temp.Chmod(mode); err != nil {
+		temp.Close()
+		return fmt.Errorf("write settings: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("write settings: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("write settings: %w", err)
+	}
+	return nil
+}
+
+// ParseSettingValue reads a command-line value as JSON when it is valid JSON
+// (true, 3, ["Bash"], {"k":"v"}) and as a plain string otherwise.
+func ParseSettingValue(text string) any {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value
+	}
+	return text
+}
+
+// LookupSetting returns the value at a dotted key such as "permissions.defaultMode".
+func LookupSetting(data map[string]any, key string) (any, bool) {
+	var current any = data
+	for _, part := range strings.Split(key, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// SetSetting stores value at a dotted key, creating intermediate objects.
+func SetSetting(data map[string]any, key string, value any) error {
+	parts := strings.Split(key, ".")
+	object := data
+	for index, part := range parts[:len(parts)-1] {
+		next, exists := object[part]
+		if !exists {
+			created := map[string]any{}
+			object[part] = created
+			object = created
+			continue
+		}
+		nested, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is not an object", strings.Join(parts[:index+1], "."))
+		}
+		object = nested
+	}
+	object[parts[len(parts)-1]] = value
+	return nil
+}
+
+// RemoveSetting deletes a dotted key and reports whether it existed.
+func RemoveSetting(data map[string]any, key string) bool {
+	parts := strings.Split(key, ".")
+	object := data
+	if len(parts) > 1 {
+		parent, _ := LookupSetting(data, strings.Join(parts[:len(parts)-1], "."))
+		nested, ok := parent.(map[string]any)
+		if !ok {
+			return false
+		}
+		object = nested
+	}
+	if _, exists := object[parts[len(parts)-1]]; !exists {
+		return false
+	}
+	delete(object, parts[len(parts)-1])
+	return true
+}
+
+// AddSettingValues appends values missing from the array at key, creating it when absent.
+func AddSettingValues(data map[string]any, key string, values []any) error {
+	list, err := settingArray(data, key)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if !containsSettingValue(list, value) {
+			list = append(list, value)
+		}
+	}
+	return SetSetting(data, key, list)
+}
+
+// RemoveSettingValues removes values from the array at key and reports how many were removed.
+func RemoveSettingValues(data map[string]any, key string, values []any) (int, error) {
+	list, err := settingArray(data, key)
+	if err != nil {
+		return 0, err
+	}
+	kept := make([]any, 0, len(list))
+	for _, item := range list {
+		if !containsSettingValue(values, item) {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(list) {
+		return 0, nil
+	}
+	return len(list) - len(kept), SetSetting(data, key, kept)
+}
+
+// settingArray returns the array at key, or nil when the key is absent.
+func settingArray(data map[string]any, key string) ([]any, error) {
+	value, ok := LookupSetting(data, key)
+	if !ok {
+		return nil, nil
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a list", key)
+	}
+	return list, nil
+}
+
+// containsSettingValue reports whether list holds a value equal to target.
+func containsSettingValue(list []any, target any) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Package errcode defines the error taxonomy shared by the agent, the tools,
+// and the provider client: a small set of categories with stable codes that
+// the CLI reports as exit statuses and in json and stream-json results.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is the machine-readable name of an error category. Codes are part of
+// the public output format and never change once released.
+type Code string
+
+const (
+	// Auth is a provider rejecting the credentials (HTTP 401 or 403).
+	Auth Code = "auth_failed"
+	// Budget is a run stopped by --max-budget-usd.
+	Budget Code = "max_budget_exceeded"
+	// MaxTurns is a run stopped by --max-turns.
+	MaxTurns Code = "max_turns_exceeded"
+	// ToolDenied is a tool call refused by the user, a hook, plan mode, or
+	// print mode's lack of a permission prompt.
+	ToolDenied Code = "tool_denied"
+	// Sandbox is a path outside the allowed roots, denied, read-only, or hidden.
+	Sandbox Code = "sandbox_violation"
+	// RateLimited is a provider answering HTTP 429.
+	RateLimited Code = "rate_limited"
+	// ProviderUnavailable is a provider answering HTTP 5xx or timing out.
+	ProviderUnavailable Code = "provider_unavailable"
+	// ContextOverflow is a prompt that does not fit the model's context window.
+	ContextOverflow Code = "context_overflow"
+	// Internal is any error outside the taxonomy.
+	Internal Code = "internal_error"
+)
+
+// Error is an error with a Code. A category's sentinel matches every Error
+// of that code under errors.Is, so tools.ErrPathHidden is also ErrSandbox.
+type Error struct {
+	code     Code
+	message  string
+	category bool
+}
+
+var (
+	// ErrAuth is the Auth category.
+	ErrAuth = newCategory(Auth, "authentication failed")
+	// ErrBudget is the Budget category.
+	ErrBudget = newCategory(Budget, "max budget exceeded")
+	// ErrMaxTurns is the MaxTurns category.
+	ErrMaxTurns = newCategory(MaxTurns, "max turns exceeded")
+	// ErrToolDenied is the ToolDenied category.
+	ErrToolDenied = newCategory(ToolDenied, "tool denied")
+	// ErrSandbox is the Sandbox category.
+	ErrSandbox = newCategory(Sandbox, "sandbox violation")
+	// ErrRateLimited is the RateLimited category.
+	ErrRateLimited = newCategory(RateLimited, "rate limited by the provider")
+	// ErrProviderUnavailable is the ProviderUnavailable category.
+	ErrProviderUnavailable = newCategory(ProviderUnavailable, "provider unavailable")
+	// ErrContextOverflow is the ContextOverflow category.
+	ErrContextOverflow = newCategory(ContextOverflow, "context window exceeded")
+)
+
+// categories lists the sentinels in the order Of checks them, with the exit
+// status the CLI uses for each. Status 2 is left to usage errors and hooks.
+var categories = []struct {
+	err  *Error
+	exit int
+}{
+	{ErrAuth, 3},
+	{ErrBudget, 4},
+	{ErrMaxTurns, 5},
+	{ErrToolDenied, 6},
+	{ErrSandbox, 7},
+	{ErrRateLimited, 8},
+	{ErrProviderUnavailable, 9},
+	{ErrContextOverflow, 10},
+}
+
+// newCategory builds a category sentinel.
+func newCategory(code Code, message string) *Error {
+	return &Error{code: code, message: message, category: true}
+}
+
+// New returns a specific error in the code's category.
+func New(code Code, message string) *Error {
+	return &Error{code: code, message: message}
+}
+
+// Error returns the message.
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Code returns the error's category code.
+func (e *Error) Code() Code {
+	return e.code
+}
+
+// Is matches the sentinel of the error's category.
+func (e *Error) Is(target error) bool {
+	sentinel, ok := target.(*Error)
+	return ok && sentinel.category && sentinel.code == e.code
+}
+
+// Of returns the code of the first category err belongs to: "" for nil and
+// Internal for errors outside the taxonomy.
+func Of(err error) Code {
+	if err == nil {
+		return ""
+	}
+	for _, category := range categories {
+		if errors.Is(err, category.err) {
+			return category.err.code
+		}
+	}
+	return Internal
+}
+
+// ExitCode returns the process exit status for err: 0 for nil, 1 for errors
+// outside the taxonomy, and a distinct status for each category.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	for _, category := range categories {
+		if errors.Is(err, category.err) {
+			return category.exit
+		}
+	}
+	return 1
+}
+
+// ToolDeniedError reports a refused tool call and names the tool, so callers
+// never parse it out of the message.
+type ToolDeniedError struct {
+	// ToolName is the tool whose call was refused.
+	ToolName string
+	// Detail optionally says why, e.g. "uncommitted changes in main.go".
+	Detail string
+}
+
+// Error keeps the "tool denied: <name>" message denials have always used.
+func (e *ToolDeniedError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%s: %s (%s)", ErrToolDenied.message, e.ToolName, e.Detail)
+	}
+	return fmt.Sprintf("%s: %s", ErrToolDenied.message, e.ToolName)
+}
+
+// Unwrap places the denial in the ToolDenied category.
+func (e *ToolDeniedError) Unwrap() error {
+	return ErrToolDenied
+}
@@ -0,0 +1,36 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestErrorCategories verifies specific errors match their category sentinel
+// only, and that codes and exit statuses follow the category.
+func TestErrorCategories(testingHandle *testing.T) {
+	// Arrange
+	hidden := New(Sandbox, "path is hidden")
+	denied := fmt.Errorf("run: %w", &ToolDeniedError{ToolName: "Bash", Detail: "uncommitted changes in main.go"})
+
+	// Act
+	var deniedErr *ToolDeniedError
+	found := errors.As(denied, &deniedErr)
+
+	// Assert
+	testutil.RequireTrue(testingHandle, errors.Is(fmt.Errorf("read: %w", hidden), ErrSandbox), "specific error matches its category")
+	testutil.RequireTrue(testingHandle, !errors.Is(hidden, ErrToolDenied), "other categories do not match")
+	testutil.RequireTrue(testingHandle, !errors.Is(hidden, New(Sandbox, "path is hidden")), "specific errors match only themselves")
+	testutil.RequireEqual(testingHandle, Of(hidden), Sandbox, "sandbox code")
+	testutil.RequireEqual(testingHandle, ExitCode(hidden), 7, "sandbox exit status")
+	testutil.RequireTrue(testingHandle, found && deniedErr.ToolName == "Bash", "denied tool name")
+	testutil.RequireEqual(testingHandle, denied.Error(), "run: tool denied: Bash (uncommitted changes in main.go)", "denial message")
+	testutil.RequireEqual(testingHandle, Of(denied), ToolDenied, "denial code")
+	testutil.RequireEqual(testingHandle, Of(ErrMaxTurns), MaxTurns, "sentinel code")
+	testutil.RequireEqual(testingHandle, Of(errors.New("boom")), Internal, "unclassified code")
+	testutil.RequireEqual(testingHandle, ExitCode(errors.New("boom")), 1, "unclassified exit status")
+	testutil.RequireEqual(testingHandle, Of(nil), Code(""), "nil code")
+	testutil.RequireEqual(testingHandle, ExitCode(nil), 0, "nil exit status")
+}
@@ -0,0 +1,309 @@
+// Package faultinject injects API and tool failures at a configured rate so the
+// retry, fallback, and error-result paths can be exercised end to end.
+package faultinject
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// Kind names one class of injected fault.
+type Kind string
+
+const (
+	// KindRateLimit answers an API request with HTTP 429.
+	KindRateLimit Kind = "429"
+	// KindServerError answers an API request with HTTP 500.
+	KindServerError Kind = "500"
+	// KindSlowStream delays every read of an API response body.
+	KindSlowStream Kind = "slow"
+	// KindToolJSON hands a tool truncated JSON arguments.
+	KindToolJSON Kind = "tool-json"
+	// KindToolTimeout fails a tool call with a deadline error without running it.
+	KindToolTimeout Kind = "tool-timeout"
+)
+
+// allKinds lists every fault in the order they are rolled.
+var allKinds = []Kind{KindRateLimit, KindServerError, KindSlowStream, KindToolJSON, KindToolTimeout}
+
+// defaultSlowDelay is the pause before each read of a slowed response body.
+const defaultSlowDelay = 200 * time.Millisecond
+
+// Config controls which faults are injected and how often.
+type Config struct {
+	// Rate is the probability in (0, 1] that an eligible call gets a fault.
+	Rate float64
+	// Kinds lists enabled faults; empty enables all of them.
+	Kinds []Kind
+	// Seed makes injection reproducible; zero picks a time-based seed.
+	Seed int64
+	// Limit caps the total number of injected faults; zero means unlimited.
+	Limit int
+	// SlowDelay is the pause before each body read for slow streams.
+	SlowDelay time.Duration
+}
+
+// ParseSpec reads a --fault-inject value such as
+// "rate=0.2,kinds=429+slow+tool-json,seed=7,limit=3,delay=500ms".
+func ParseSpec(spec string) (Config, error) {
+	cfg := Config{SlowDelay: defaultSlowDelay}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("fault-inject: expected key=value, got %q", field)
+		}
+		var err error
+		switch strings.TrimSpace(key) {
+		case "rate":
+			cfg.Rate, err = strconv.ParseFloat(value, 64)
+		case "kinds":
+			for _, name := range strings.Split(value, "+") {
+				kind := Kind(strings.TrimSpace(name))
+				if !knownKind(kind) {
+					return Config{}, fmt.Errorf("fault-inject: unknown kind %q (want %s)", name, joinKinds(allKinds))
+				}
+				cfg.Kinds = append(cfg.Kinds, kind)
+			}
+		case "seed":
+			cfg.Seed, err = strconv.ParseInt(value, 10, 64)
+		case "limit":
+			cfg.Limit, err = strconv.Atoi(value)
+		case "delay":
+			cfg.SlowDelay, err = time.ParseDuration(value)
+		default:
+			return Config{}, fmt.Errorf("fault-inject: unknown key %q", key)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("fault-inject: invalid %s: %w", key, err)
+		}
+	}
+	if cfg.Rate <= 0 || cfg.Rate > 1 {
+		return Config{}, errors.New("fault-inject: rate must be in (0, 1]")
+	}
+	if cfg.Limit < 0 || cfg.SlowDelay < 0 {
+		return Config{}, errors.New("fault-inject: limit and delay must not be negative")
+	}
+	return cfg, nil
+}
+
+// Injector decides when to inject faults and counts what it injected.
+// It is safe for concurrent use.
+type Injector struct {
+	// config holds the parsed settings.
+	config Config
+	// enabled is the set of active kinds.
+	enabled map[Kind]bool
+	// mu guards rng and counts.
+	mu sync.Mutex
+	// rng drives injection decisions.
+	rng *rand.Rand
+	// counts records injected faults by kind.
+	counts map[Kind]int
+	// total is the sum of counts, checked against the limit.
+	total int
+}
+
+// New constructs an injector for cfg.
+func New(cfg Config) *Injector {
+	enabled := map[Kind]bool{}
+	kinds := cfg.Kinds
+	if len(kinds) == 0 {
+		kinds = allKinds
+	}
+	for _, kind := range kinds {
+		enabled[kind] = true
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Injector{
+		config:  cfg,
+		enabled: enabled,
+		rng:     rand.New(rand.NewSource(seed)),
+		counts:  map[Kind]int{},
+	}
+}
+
+// roll reports whether to inject kind now, recording it when it does.
+func (i *Injector) roll(kind Kind) bool {
+	if i == nil || !i.enabled[kind] {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.config.Limit > 0 && i.total >= i.config.Limit {
+		return false
+	}
+	if i.rng.Float64() >= i.config.Rate {
+		return false
+	}
+	i.counts[kind]++
+	i.total++
+	return true
+}
+
+// Counts returns a copy of the injected fault counts by kind.
+func (i *Injector) Counts() map[Kind]int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	counts := make(map[Kind]int, len(i.counts))
+	for kind, count := range i.counts {
+		counts[kind] = count
+	}
+	return counts
+}
+
+// Summary renders injected counts, e.g. "injected 429×2, tool-json×1".
+func (i *Injector) Summary() string {
+	counts := i.Counts()
+	if len(counts) == 0 {
+		return "injected no faults"
+	}
+	parts := make([]string, 0, len(counts))
+	for _, kind := range allKinds {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%s×%d", kind, counts[kind]))
+		}
+	}
+	return "injected " + strings.Join(parts, ", ")
+}
+
+// Transport wraps base so API requests may fail with 429 or 500 before
+// reaching the gateway, or have their response bodies slowed down.
+func (i *Injector) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		switch {
+		case i.roll(KindRateLimit):
+			return faultResponse(request, http.StatusTooManyRequests, "rate_limit_error", "rate limited"), nil
+		case i.roll(KindServerError):
+			return faultResponse(request, http.StatusInternalServerError, "api_error", "internal server error"), nil
+		}
+		response, err := base.RoundTrip(request)
+		if err != nil || !i.roll(KindSlowStream) {
+			return response, err
+		}
+		response.Body = &slowBody{ReadCloser: response.Body, ctx: request.Context(), delay: i.config.SlowDelay}
+		return response, nil
+	})
+}
+
+// WrapTools replaces each tool in runner with one that may receive malformed
+// arguments or time out. Tool names, schemas, and ordering are unchanged; a
+// nil injector leaves the runner as is.
+func (i *Injector) WrapTools(runner *tools.Runner) {
+	if i == nil || runner == nil {
+		return
+	}
+	for name, tool := range runner.Tools {
+		runner.Tools[name] = &faultTool{Tool: tool, injector: i}
+	}
+}
+
+// faultTool injects tool faults in front of a real tool.
+type faultTool struct {
+	tools.Tool
+	// injector decides when to inject.
+	injector *Injector
+}
+
+// Run corrupts the arguments or fails with a deadline error when a fault is rolled.
+func (t *faultTool) Run(ctx context.Context, input json.RawMessage, toolCtx tools.ToolContext) (tools.ToolResult, error) {
+	if t.injector.roll(KindToolTimeout) {
+		return tools.ToolResult{}, fmt.Errorf("fault injection: %s timed out: %w", t.Name(), context.DeadlineExceeded)
+	}
+	if t.injector.roll(KindToolJSON) {
+		// Cut the arguments mid-value, as a model hitting max_tokens would.
+		input = json.RawMessage(strings.TrimSuffix(string(input), "}") + `, "truncated": "`)
+	}
+	return t.Tool.Run(ctx, input, toolCtx)
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// faultResponse builds an OpenAI-style error response without contacting the gateway.
+func faultResponse(request *http.Request, status int, errorType string, message string) *http.Response {
+	if request.Body != nil {
+		_ = request.Body.Close()
+	}
+	body := fmt.Sprintf(`{"error":{"message":"fault injection: %s","type":%q}}`, message, errorType)
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	if status == http.StatusTooManyRequests {
+		header.Set("Retry-After", "1")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}
+}
+
+// slowBody delays each read to simulate a slow stream.
+type slowBody struct {
+	io.ReadCloser
+	// ctx aborts the delay when the request is canceled.
+	ctx context.Context
+	// delay is the pause before each read.
+	delay time.Duration
+}
+
+func (b *slowBody) Read(buffer []byte) (int, error) {
+	timer := time.NewTimer(b.delay)
+	defer timer.Stop()
+	select {
+	case <-b.ctx.Done():
+		return 0, b.ctx.Err()
+	case <-timer.C:
+	}
+	return b.ReadCloser.Read(buffer)
+}
+
+// knownKind reports whether kind is a supported fault.
+func knownKind(kind Kind) bool {
+	for _, candidate := range allKinds {
+		if candidate == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// joinKinds renders kinds for error messages.
+func joinKinds(kinds []Kind) string {
+	names := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		names = append(names, string(kind))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,155 @@
+package faultinject
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/agent"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/testutil"
+	"github.com/openclaude/openclaude/internal/tools"
+)
+
+// TestParseSpec verifies spec parsing, defaults, and validation.
+func TestParseSpec(testingHandle *testing.T) {
+	cfg, err := ParseSpec("rate=0.5,kinds=429+tool-json,seed=7,limit=2,delay=10ms")
+	testutil.RequireNoError(testingHandle, err, "parse spec")
+	testutil.RequireEqual(testingHandle, cfg, Config{Rate: 0.5, Kinds: []Kind{KindRateLimit, KindToolJSON}, Seed: 7, Limit: 2, SlowDelay: 10 * time.Millisecond}, "config")
+
+	cfg, err = ParseSpec("rate=1")
+	testutil.RequireNoError(testingHandle, err, "rate only")
+	testutil.RequireEqual(testingHandle, cfg.SlowDelay, defaultSlowDelay, "default delay")
+
+	for _, spec := range []string{"", "rate=0", "rate=1.5", "rate=1,kinds=404", "rate=1,bogus=1", "0.5", "rate=1,limit=-1"} {
+		_, err := ParseSpec(spec)
+		testutil.RequireTrue(testingHandle, err != nil, "rejects "+spec)
+	}
+}
+
+// chatServer answers chat/completions with the scripted bodies in order and counts requests.
+func chatServer(testingHandle *testing.T, bodies ...string) (*httptest.Server, *atomic.Int32, *[]string) {
+	var calls atomic.Int32
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		payload, _ := io.ReadAll(request.Body)
+		requests = append(requests, string(payload))
+		index := int(calls.Add(1)) - 1
+		if index >= len(bodies) {
+			index = len(bodies) - 1
+		}
+		if strings.HasPrefix(bodies[index], "data:") {
+			responseWriter.Header().Set("Content-Type", "text/event-stream")
+		} else {
+			responseWriter.Header().Set("Content-Type", "application/json")
+		}
+		_, _ = fmt.Fprint(responseWriter, bodies[index])
+	}))
+	testingHandle.Cleanup(server.Close)
+	return server, &calls, &requests
+}
+
+const finalAnswer = `{"choices":[{"message":{"role":"assistant","content":"done"},"finish_reason":"stop"}]}`
+
+// TestTransportInjectsAPIErrors verifies 429 and 500 faults surface as retryable API errors.
+func TestTransportInjectsAPIErrors(testingHandle *testing.T) {
+	server, calls, _ := chatServer(testingHandle, finalAnswer)
+	for _, test := range []struct {
+		kind   Kind
+		status int
+	}{{KindRateLimit, http.StatusTooManyRequests}, {KindServerError, http.StatusInternalServerError}} {
+		injector := New(Config{Rate: 1, Kinds: []Kind{test.kind}, Seed: 1, Limit: 1})
+		client := openai.NewClient(server.URL, "", 5*time.Second)
+		client.SetTransport(injector.Transport(nil))
+		request := &openai.ChatRequest{Model: "model", Messages: []llm.Message{{Role: "user", Content: "hi"}}}
+
+		_, err := client.ChatCompletions(context.Background(), request)
+		var apiErr *openai.APIError
+		testutil.RequireTrue(testingHandle, errors.As(err, &apiErr), "api error for "+string(test.kind))
+		testutil.RequireEqual(testingHandle, apiErr.StatusCode, test.status, "status for "+string(test.kind))
+
+		// The limit is spent, so the retry reaches the gateway.
+		response, err := client.ChatCompletions(context.Background(), request)
+		testutil.RequireNoError(testingHandle, err, "retry after "+string(test.kind))
+		testutil.RequireEqual(testingHandle, llm.ContentText(response.Choices[0].Message.Content), "done", "retry answer")
+		testutil.RequireEqual(testingHandle, injector.Summary(), "injected "+string(test.kind)+"×1", "summary")
+	}
+	testutil.RequireEqual(testingHandle, calls.Load(), int32(2), "injected errors never reach the gateway")
+}
+
+// TestTransportSlowsStreams verifies slowed streams still complete.
+func TestTransportSlowsStreams(testingHandle *testing.T) {
+	stream := "data: {\"id\":\"s\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hel\"}}]}\n\n" +
+		"data: {\"id\":\"s\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"}}]}\n\n" +
+		"data: [DONE]\n\n"
+	server, _, _ := chatServer(testingHandle, stream)
+	injector := New(Config{Rate: 1, Kinds: []Kind{KindSlowStream}, Seed: 1, SlowDelay: 20 * time.Millisecond})
+	client := openai.NewClient(server.URL, "", 5*time.Second)
+	client.SetTransport(injector.Transport(nil))
+
+	var text strings.Builder
+	start := time.Now()
+	_, err := client.ChatCompletionsStream(context.Background(), &openai.ChatRequest{Model: "model"}, func(event openai.StreamResponse) error {
+		for _, choice := range event.Choices {
+			text.WriteString(choice.Delta.Content)
+		}
+		return nil
+	})
+	testutil.RequireNoError(testingHandle, err, "slow stream")
+	testutil.RequireEqual(testingHandle, text.String(), "hello", "streamed text")
+	testutil.RequireTrue(testingHandle, time.Since(start) >= 20*time.Millisecond, "stream was delayed")
+}
+
+// echoTool decodes its arguments like the built-in tools do.
+type echoTool struct{}
+
+func (echoTool) Name() string           { return "Echo" }
+func (echoTool) Description() string    { return "echo" }
+func (echoTool) Schema() map[string]any { return map[string]any{"type": "object"} }
+func (echoTool) Run(_ context.Context, input json.RawMessage, _ tools.ToolContext) (tools.ToolResult, error) {
+	var payload struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(input, &payload); err != nil {
+		return tools.ToolResult{IsError: true, Content: fmt.Sprintf("invalid input: %v", err)}, nil
+	}
+	return tools.ToolResult{Content: payload.Text}, nil
+}
+
+// TestToolFaultsBecomeErrorResults verifies tool faults reach the model as error
+// results and the agent loop carries on to a final answer.
+func TestToolFaultsBecomeErrorResults(testingHandle *testing.T) {
+	toolCall := `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call-1","type":"function","function":{"name":"Echo","arguments":"{\"text\":\"hi\"}"}}]},"finish_reason":"tool_calls"}]}`
+	for _, test := range []struct {
+		kind Kind
+		want string
+	}{{KindToolJSON, "invalid input"}, {KindToolTimeout, "fault injection: Echo timed out: context deadline exceeded"}} {
+		server, _, requests := chatServer(testingHandle, toolCall, finalAnswer)
+		injector := New(Config{Rate: 1, Kinds: []Kind{test.kind}, Seed: 1})
+		toolRunner := tools.NewRunner([]tools.Tool{echoTool{}})
+		injector.WrapTools(toolRunner)
+		runner := &agent.Runner{
+			Client:      openai.NewClient(server.URL, "", 5*time.Second),
+			ToolRunner:  toolRunner,
+			Permissions: tools.Permissions{Mode: tools.PermissionBypass},
+			MaxTurns:    4,
+		}
+
+		result, err := runner.Run(context.Background(), []llm.Message{{Role: "user", Content: "echo hi"}}, "", "model", true)
+		testutil.RequireNoError(testingHandle, err, "run with "+string(test.kind))
+		testutil.RequireEqual(testingHandle, llm.ContentText(result.Final.Content), "done", "final answer")
+		toolMessage := result.Messages[len(result.Messages)-2]
+		testutil.RequireEqual(testingHandle, toolMessage.Role, "tool", "tool result message")
+		testutil.RequireTrue(testingHandle, strings.Contains(llm.ContentText(toolMessage.Content), test.want), "tool error for "+string(test.kind)+": "+llm.ContentText(toolMessage.Content))
+		testutil.RequireEqual(testingHandle, len(*requests), 2, "error result sent back to the model")
+	}
+}
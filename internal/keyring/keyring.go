@@ -0,0 +1,133 @@
+// Package keyring stores credentials in the operating system's secret store:
+// the macOS Keychain, the Secret Service (libsecret) on Linux and BSD, and the
+// Windows Credential Manager.
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Service is the service name OpenClaude credentials are stored under.
+const Service = "openclaude"
+
+// ErrNotFound is returned when no credential is stored under a name.
+var ErrNotFound = errors.New("credential not found in keyring")
+
+// Backend reads and writes secrets in a credential store.
+type Backend interface {
+	// Set stores secret under name, replacing any existing value.
+	Set(name string, secret string) error
+	// Get returns the secret stored under name or ErrNotFound.
+	Get(name string) (string, error)
+	// Delete removes the secret stored under name or returns ErrNotFound.
+	Delete(name string) error
+}
+
+var (
+	// mu guards current.
+	mu sync.Mutex
+	// current is the backend used by the package functions.
+	current Backend = systemBackend{}
+)
+
+// namePattern limits names to characters that are safe in every backend.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidateName reports whether name can be used as a credential name.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid credential name %q: use up to 64 letters, digits, '.', '_', or '-'", name)
+	}
+	return nil
+}
+
+// Set stores secret under name in the system keyring.
+func Set(name string, secret string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if secret == "" {
+		return errors.New("refusing to store an empty secret")
+	}
+	if strings.ContainsAny(secret, "\r\n") {
+		return errors.New("secret must be a single line")
+	}
+	return backend().Set(name, secret)
+}
+
+// Get returns the secret stored under name in the system keyring.
+func Get(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	return backend().Get(name)
+}
+
+// Delete removes the secret stored under name from the system keyring.
+func Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	return backend().Delete(name)
+}
+
+// UseMemory replaces the system keyring with an empty in-memory store, for
+// tests, and returns a function that restores the previous backend.
+func UseMemory() (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	previous := current
+	current = &memoryBackend{secrets: map[string]string{}}
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		current = previous
+	}
+}
+
+// backend returns the active backend.
+func backend() Backend {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// memoryBackend keeps secrets in a map.
+type memoryBackend struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// Set stores the secret.
+func (b *memoryBackend) Set(name string, secret string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.secrets[name] = secret
+	return nil
+}
+
+// Get returns the stored secret.
+func (b *memoryBackend) Get(name string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	secret, ok := b.secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Delete removes the stored secret.
+func (b *memoryBackend) Delete(name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(b.secrets, name)
+	return nil
+}
@@ -0,0 +1,63 @@
+//go:build darwin
+
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityNotFoundExit is the exit code security(1) uses for a missing item.
+const securityNotFoundExit = 44
+
+// systemBackend stores generic passwords in the login Keychain with security(1).
+type systemBackend struct{}
+
+// Set adds or updates the Keychain item. The command goes through `security -i`
+// on stdin so the secret never appears in the process list.
+func (systemBackend) Set(name string, secret string) error {
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", securityQuote(Service), securityQuote(name), securityQuote(secret))
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(command)
+	return runSecurity(cmd)
+}
+
+// Get reads the Keychain item's password.
+func (systemBackend) Get(name string) (string, error) {
+	cmd := exec.Command("security", "find-generic-password", "-s", Service, "-a", name, "-w")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := runSecurity(cmd); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// Delete removes the Keychain item.
+func (systemBackend) Delete(name string) error {
+	return runSecurity(exec.Command("security", "delete-generic-password", "-s", Service, "-a", name))
+}
+
+// runSecurity runs security(1) and maps its missing-item exit code to ErrNotFound.
+func runSecurity(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == securityNotFoundExit {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("keychain: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// securityQuote quotes an argument for `security -i`.
+func securityQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
@@ -0,0 +1,73 @@
+//go:build !darwin && !windows
+
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// systemBackend stores secrets in the Secret Service (GNOME Keyring, KWallet)
+// with secret-tool from libsecret.
+type systemBackend struct{}
+
+// Set stores the secret; secret-tool reads it from stdin so it never appears
+// in the process list.
+func (systemBackend) Set(name string, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label=OpenClaude "+name, "service", Service, "account", name)
+	cmd.Stdin = strings.NewReader(secret)
+	_, err := runSecretTool(cmd)
+	return err
+}
+
+// Get looks the secret up; secret-tool exits non-zero with no output when it is missing.
+func (systemBackend) Get(name string) (string, error) {
+	output, err := runSecretTool(exec.Command("secret-tool", "lookup", "service", Service, "account", name))
+	if err != nil {
+		if errors.Is(err, errSecretToolExit) && output == "" {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if output == "" {
+		return "", ErrNotFound
+	}
+	return output, nil
+}
+
+// Delete clears the secret. secret-tool does not report missing items, so a
+// lookup runs first.
+func (b systemBackend) Delete(name string) error {
+	if _, err := b.Get(name); err != nil {
+		return err
+	}
+	_, err := runSecretTool(exec.Command("secret-tool", "clear", "service", Service, "account", name))
+	return err
+}
+
+// errSecretToolExit marks a non-zero exit without an error message, which is
+// how lookup reports a missing secret.
+var errSecretToolExit = errors.New("secret-tool failed")
+
+// runSecretTool runs secret-tool and returns its trimmed stdout.
+func runSecretTool(cmd *exec.Cmd) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", errors.New("secret-tool not found; install libsecret-tools (or your distribution's libsecret package) to use the keyring")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	output := strings.TrimRight(stdout.String(), "\n")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && strings.TrimSpace(stderr.String()) == "" {
+		return output, fmt.Errorf("%w: %v", errSecretToolExit, err)
+	}
+	if err != nil {
+		return output, fmt.Errorf("secret service: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
@@ -0,0 +1,33 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestMemoryKeyringRoundTrip verifies set, get, and delete against the in-memory backend.
+func TestMemoryKeyringRoundTrip(testingHandle *testing.T) {
+	testingHandle.Cleanup(UseMemory())
+
+	testutil.RequireNoError(testingHandle, Set("work", "sk-secret"), "set")
+	secret, err := Get("work")
+	testutil.RequireNoError(testingHandle, err, "get")
+	testutil.RequireEqual(testingHandle, secret, "sk-secret", "secret")
+	testutil.RequireNoError(testingHandle, Delete("work"), "delete")
+	_, err = Get("work")
+	testutil.RequireTrue(testingHandle, errors.Is(err, ErrNotFound), "deleted secret is gone")
+	testutil.RequireTrue(testingHandle, errors.Is(Delete("work"), ErrNotFound), "second delete reports not found")
+}
+
+// TestKeyringRejectsBadInput verifies names and secrets are checked before reaching a backend.
+func TestKeyringRejectsBadInput(testingHandle *testing.T) {
+	testingHandle.Cleanup(UseMemory())
+
+	testutil.RequireTrue(testingHandle, Set("../work", "sk") != nil, "path-like name")
+	testutil.RequireTrue(testingHandle, Set("work", "") != nil, "empty secret")
+	testutil.RequireTrue(testingHandle, Set("work", "sk\nsecond") != nil, "multi-line secret")
+	_, err := Get("")
+	testutil.RequireTrue(testingHandle, err != nil, "empty name")
+}
@@ -0,0 +1,112 @@
+//go:build windows
+
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// credTypeGeneric is CRED_TYPE_GENERIC.
+	credTypeGeneric = 1
+	// credPersistLocalMachine is CRED_PERSIST_LOCAL_MACHINE.
+	credPersistLocalMachine = 2
+)
+
+var (
+	advapi32        = windows.NewLazySystemDLL("advapi32.dll")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+// credential mirrors the Win32 CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// systemBackend stores generic credentials in the Windows Credential Manager.
+type systemBackend struct{}
+
+// Set writes the credential, replacing an existing one.
+func (systemBackend) Set(name string, secret string) error {
+	target, err := windows.UTF16PtrFromString(credentialTarget(name))
+	if err != nil {
+		return err
+	}
+	user, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if ok, _, callErr := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); ok == 0 {
+		return fmt.Errorf("credential manager: %w", callErr)
+	}
+	return nil
+}
+
+// Get reads the credential blob.
+func (systemBackend) Get(name string) (string, error) {
+	target, err := windows.UTF16PtrFromString(credentialTarget(name))
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	if ok, _, callErr := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred))); ok == 0 {
+		return "", credentialError(callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	if cred.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+// Delete removes the credential.
+func (systemBackend) Delete(name string) error {
+	target, err := windows.UTF16PtrFromString(credentialTarget(name))
+	if err != nil {
+		return err
+	}
+	if ok, _, callErr := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); ok == 0 {
+		return credentialError(callErr)
+	}
+	return nil
+}
+
+// credentialTarget is the Credential Manager target name for a credential.
+func credentialTarget(name string) string {
+	return Service + ":" + name
+}
+
+// credentialError maps ERROR_NOT_FOUND to ErrNotFound.
+func credentialError(err error) error {
+	if errors.Is(err, windows.ERROR_NOT_FOUND) {
+		return ErrNotFound
+	}
+	return fmt.Errorf("credential manager: %w", err)
+}
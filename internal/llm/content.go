@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+const (
+	// ContentPartText marks a text content part.
+	ContentPartText = "text"
+	// ContentPartImageURL marks an image content part (URL or data URL).
+	ContentPartImageURL = "image_url"
+)
+
+// ContentPart is one element of multimodal message content.
+// Message.Content holds []ContentPart when a message carries images; the JSON
+// shape matches OpenAI content arrays so it can be sent upstream unchanged.
+type ContentPart struct {
+	// Type is text or image_url.
+	Type string `json:"type"`
+	// Text carries the text for text parts.
+	Text string `json:"text,omitempty"`
+	// ImageURL references the image for image_url parts.
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL points at an image by http(s) URL or base64 data URL.
+type ImageURL struct {
+	// URL is the image location, e.g. "data:image/png;base64,...".
+	URL string `json:"url"`
+}
+
+// TextPart builds a text content part.
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: ContentPartText, Text: text}
+}
+
+// ImagePart builds an image content part from a URL or data URL.
+func ImagePart(url string) ContentPart {
+	return ContentPart{Type: ContentPartImageURL, ImageURL: &ImageURL{URL: url}}
+}
+
+// ImageDataURL encodes image bytes as a base64 data URL.
+func ImageDataURL(mediaType string, data []byte) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// ParseImageDataURL splits a base64 data URL into its media type and payload.
+func ParseImageDataURL(url string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return "", "", false
+	}
+	header, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return "", "", false
+	}
+	mediaType, ok := strings.CutSuffix(header, ";base64")
+	if !ok {
+		return "", "", false
+	}
+	return mediaType, data, true
+}
+
+// ContentParts returns content as parts, or nil when it is plain text.
+// It accepts in-memory []ContentPart and the []any shape decoded from stored JSON.
+func ContentParts(content any) []ContentPart {
+	switch typed := content.(type) {
+	case []ContentPart:
+		return typed
+	case []any:
+		parts := make([]ContentPart, 0, len(typed))
+		for _, item := range typed {
+			entry, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch entry["type"] {
+			case ContentPartText:
+				text, _ := entry["text"].(string)
+				parts = append(parts, TextPart(text))
+			case ContentPartImageURL:
+				image, _ := entry["image_url"].(map[string]any)
+				url, _ := image["url"].(string)
+				parts = append(parts, ImagePart(url))
+			}
+		}
+		return parts
+	default:
+		return nil
+	}
+}
+
+// HasImages reports whether content includes at least one image part.
+func HasImages(content any) bool {
+	for _, part := range ContentParts(content) {
+		if part.Type == ContentPartImageURL {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentText flattens content for display, using "[Image]" for image parts.
+func ContentText(content any) string {
+	if text, ok := content.(string); ok {
+		return text
+	}
+	var builder strings.Builder
+	for _, part := range ContentParts(content) {
+		switch part.Type {
+		case ContentPartText:
+			builder.WriteString(part.Text)
+		case ContentPartImageURL:
+			if builder.Len() > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString("[Image]")
+		}
+	}
+	return builder.String()
+}
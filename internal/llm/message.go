@@ -0,0 +1,140 @@
+// Package llm defines provider-agnostic chat types shared by the agent loop,
+// tool runner, and stream-json event builders. Provider adapters (such as
+// internal/llm/openai) translate their wire formats to and from these types so
+// new backends do not require touching every consumer.
+package llm
+
+import "encoding/json"
+
+// Message represents a chat message.
+// The JSON shape matches the OpenAI chat format so persisted sessions stay readable.
+type Message struct {
+	// Role is one of system, user, assistant, or tool.
+	Role string `json:"role"`
+	// Content carries message text or structured payloads.
+	Content any `json:"content,omitempty"`
+	// ToolCalls lists tool invocations requested by the assistant.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID associates a tool response to a prior call.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Name optionally identifies a function or assistant.
+	Name string `json:"name,omitempty"`
+	// Reasoning holds thinking text returned by reasoning models.
+	// It is persisted with the session but never sent back upstream.
+	Reasoning string `json:"reasoning_content,omitempty"`
+	// ServerToolCalls lists tools the provider ran while producing the reply.
+	// Like Reasoning, they are persisted but never sent back upstream.
+	ServerToolCalls []ServerToolCall `json:"server_tool_calls,omitempty"`
+}
+
+// Tool describes a callable function for the model.
+type Tool struct {
+	// Type must be "function" for function tools.
+	Type string `json:"type"`
+	// Function describes the callable function contract.
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction defines a function for tool calling.
+type ToolFunction struct {
+	// Name is the unique identifier for the function.
+	Name string `json:"name"`
+	// Description provides a natural language summary.
+	Description string `json:"description,omitempty"`
+	// Parameters is a JSON Schema object describing inputs.
+	Parameters map[string]any `json:"parameters,omitempty"`
+}
+
+// ToolCall represents a tool invocation requested by the model.
+type ToolCall struct {
+	// ID is the unique tool call id.
+	ID string `json:"id"`
+	// Type is the tool type, typically "function".
+	Type string `json:"type"`
+	// Function includes the name and serialized arguments.
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction is the function call payload.
+type ToolCallFunction struct {
+	// Name identifies which tool to invoke.
+	Name string `json:"name"`
+	// Arguments contains a JSON string to be parsed by the tool.
+	Arguments string `json:"arguments"`
+}
+
+// Usage represents token usage info.
+type Usage struct {
+	// PromptTokens counts input tokens.
+	PromptTokens int `json:"prompt_tokens"`
+	// CompletionTokens counts output tokens.
+	CompletionTokens int `json:"completion_tokens"`
+	// TotalTokens is the sum of prompt and completion tokens.
+	TotalTokens int `json:"total_tokens"`
+	// CacheReadTokens counts prompt tokens served from the provider's prompt cache.
+	// Gateways fronting Anthropic models report it; it is included in PromptTokens.
+	CacheReadTokens int `json:"cache_read_input_tokens,omitempty"`
+	// CacheWriteTokens counts prompt tokens written to the prompt cache (included in PromptTokens).
+	CacheWriteTokens int `json:"cache_creation_input_tokens,omitempty"`
+	// ReasoningTokens counts completion tokens spent on reasoning (included in CompletionTokens).
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
+	// ServerToolUse counts tools the provider ran server-side, as reported by
+	// gateways that pass through Anthropic's usage.server_tool_use.
+	ServerToolUse ServerToolUse `json:"server_tool_use,omitzero"`
+}
+
+// UnmarshalJSON also reads OpenAI's prompt_tokens_details.cached_tokens and
+// completion_tokens_details.reasoning_tokens. Anthropic-style
+// cache_read_input_tokens wins when a gateway reports both.
+func (u *Usage) UnmarshalJSON(data []byte) error {
+	type plainUsage Usage
+	var decoded struct {
+		plainUsage
+		PromptTokensDetails *struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+		CompletionTokensDetails *struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"completion_tokens_details"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*u = Usage(decoded.plainUsage)
+	if u.CacheReadTokens == 0 && decoded.PromptTokensDetails != nil {
+		u.CacheReadTokens = decoded.PromptTokensDetails.CachedTokens
+	}
+	if u.ReasoningTokens == 0 && decoded.CompletionTokensDetails != nil {
+		u.ReasoningTokens = decoded.CompletionTokensDetails.ReasoningTokens
+	}
+	return nil
+}
+
+// Add returns the sum of both usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		CacheReadTokens:  u.CacheReadTokens + other.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + other.CacheWriteTokens,
+		ReasoningTokens:  u.ReasoningTokens + other.ReasoningTokens,
+		ServerToolUse:    u.ServerToolUse.Add(other.ServerToolUse),
+	}
+}
+
+// ServerToolUse counts billable server-side tool requests.
+type ServerToolUse struct {
+	// WebSearchRequests counts web searches run by the provider.
+	WebSearchRequests int `json:"web_search_requests,omitempty"`
+	// WebFetchRequests counts web fetches run by the provider.
+	WebFetchRequests int `json:"web_fetch_requests,omitempty"`
+}
+
+// Add returns the sum of both request counts.
+func (s ServerToolUse) Add(other ServerToolUse) ServerToolUse {
+	return ServerToolUse{
+		WebSearchRequests: s.WebSearchRequests + other.WebSearchRequests,
+		WebFetchRequests:  s.WebFetchRequests + other.WebFetchRequests,
+	}
+}
@@ -8,10 +8,26 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
+
+	"github.com/openclaude/openclaude/internal/errcode"
+	"github.com/openclaude/openclaude/internal/llm"
 )
 
+// contextOverflowMarkers are the error fragments gateways use for prompts that
+// exceed the model's context window.
+var contextOverflowMarkers = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"context window",
+	"prompt is too long",
+	"input is too long",
+	"too many tokens",
+	"reduce the length",
+}
+
 // APIError represents an HTTP error from the OpenAI-compatible gateway.
 type APIError struct {
 	StatusCode int
@@ -22,6 +38,41 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("openai api error: status %d: %s", e.StatusCode, e.Body)
 }
 
+// Is places the error in the errcode taxonomy by status: 401 and 403 are
+// ErrAuth, 429 ErrRateLimited, 5xx ErrProviderUnavailable, and 413 or a 400
+// naming the context window ErrContextOverflow.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case errcode.ErrAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case errcode.ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case errcode.ErrProviderUnavailable:
+		return e.StatusCode >= http.StatusInternalServerError
+	case errcode.ErrContextOverflow:
+		return e.isContextOverflow()
+	}
+	return false
+}
+
+// isContextOverflow reports whether the gateway rejected a prompt that does
+// not fit the model's context window.
+func (e *APIError) isContextOverflow() bool {
+	if e.StatusCode == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	if e.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	body := strings.ToLower(e.Body)
+	for _, marker := range contextOverflowMarkers {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 // Client talks to an OpenAI-compatible chat/completions endpoint.
 type Client struct {
 	// baseURL points to the OpenAI-compatible gateway.
@@ -30,6 +81,20 @@ type Client struct {
 	apiKey string
 	// httpClient executes requests with timeouts.
 	httpClient *http.Client
+	// azure switches to Azure OpenAI deployment URLs and api-key auth when set.
+	azure *AzureOptions
+	// tokens supplies refreshed bearer tokens in place of apiKey when set.
+	tokens TokenSource
+	// timeouts replaces the single HTTP client timeout when set.
+	timeouts *Timeouts
+}
+
+// AzureOptions configures the Azure OpenAI flavor of the API.
+type AzureOptions struct {
+	// APIVersion is sent as the api-version query parameter.
+	APIVersion string
+	// Deployments maps model names to deployment names; unmapped models are used as deployment names.
+	Deployments map[string]string
 }
 
 // NewClient constructs a new client with timeout settings.
@@ -43,6 +108,24 @@ func NewClient(baseURL string, apiKey string, timeout time.Duration) *Client {
 	}
 }
 
+// SetTransport replaces the HTTP transport, keeping the configured timeout.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+// SetAzure switches the client to Azure OpenAI: requests go to
+// {base}/openai/deployments/{deployment}/chat/completions?api-version=... and the
+// key is sent in the api-key header instead of as a bearer token.
+func (c *Client) SetAzure(options AzureOptions) {
+	c.azure = &options
+}
+
+// SetTokenSource sends tokens from source as bearer tokens instead of the
+// static API key. A 401 response drops the cached token and retries once.
+func (c *Client) SetTokenSource(source TokenSource) {
+	c.tokens = source
+}
+
 // ChatCompletions executes a non-streaming chat/completions request.
 func (c *Client) ChatCompletions(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
 	// Marshal request payload once for consistent retries.
@@ -51,29 +134,17 @@ func (c *Client) ChatCompletions(ctx context.Context, req *ChatRequest) (*ChatRe
 		return nil, fmt.Errorf("marshal chat request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		c.completionsURL(),
-		bytes.NewReader(payload),
-	)
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+	resp, err := c.post(ctx, req.Model, payload)
 	if err != nil {
-		return nil, fmt.Errorf("create chat request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("send chat request: %w", err)
+		return nil, timeoutError(ctx, err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read chat response: %w", err)
+		return nil, timeoutError(ctx, fmt.Errorf("read chat response: %w", err))
 	}
 
 	// Non-2xx responses return a structured API error for fallback logic.
@@ -88,13 +159,91 @@ func (c *Client) ChatCompletions(ctx context.Context, req *ChatRequest) (*ChatRe
 	if len(parsed.Choices) == 0 {
 		return nil, errors.New("empty response choices")
 	}
+	for index := range parsed.Choices {
+		llm.SplitServerTools(&parsed.Choices[index].Message)
+	}
+	if parsed.Usage.ServerToolUse == (llm.ServerToolUse{}) {
+		parsed.Usage.ServerToolUse = llm.CountServerTools(parsed.Choices[0].Message.ServerToolCalls)
+	}
 	return &parsed, nil
 }
 
 // completionsURL normalizes the base URL to a chat/completions endpoint.
-func (c *Client) completionsURL() string {
-	if strings.HasSuffix(c.baseURL, "/chat/completions") {
-		return c.baseURL
+// Azure base URLs may be the resource endpoint, with or without /openai, or
+// a single deployment's URL.
+func (c *Client) completionsURL(model string) string {
+	base := c.baseURL
+	if c.azure != nil && !strings.Contains(base, "/deployments/") {
+		deployment := model
+		if mapped := c.azure.Deployments[model]; mapped != "" {
+			deployment = mapped
+		}
+		base = strings.TrimSuffix(base, "/openai") + "/openai/deployments/" + url.PathEscape(deployment)
+	}
+	if !strings.HasSuffix(base, "/chat/completions") {
+		base += "/chat/completions"
+	}
+	if c.azure != nil && c.azure.APIVersion != "" {
+		base += "?api-version=" + url.QueryEscape(c.azure.APIVersion)
+	}
+	return base
+}
+
+// post sends a chat/completions payload.
+func (c *Client) post(ctx context.Context, model string, payload []byte) (*http.Response, error) {
+	return c.send(ctx, http.MethodPost, c.completionsURL(model), payload, "chat")
+}
+
+// send issues an authenticated request; kind names it in errors. With a token
+// source, a 401 response is retried once with a freshly fetched token.
+func (c *Client) send(ctx context.Context, method string, target string, payload []byte, kind string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, method, target, body)
+		if err != nil {
+			return nil, fmt.Errorf("create %s request: %w", kind, err)
+		}
+		if payload != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		if err := c.setAuth(httpReq); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("send %s request: %w", kind, err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized || c.tokens == nil || attempt > 0 {
+			return resp, nil
+		}
+		resp.Body.Close()
+		c.tokens.Invalidate()
+	}
+}
+
+// setAuth adds the credentials: a token from the token source as a bearer token
+// (Azure accepts Entra ID tokens this way too), or the API key as a bearer token
+// or, for Azure, in the api-key header.
+func (c *Client) setAuth(httpReq *http.Request) error {
+	if c.tokens != nil {
+		token, err := c.tokens.Token(httpReq.Context())
+		if err != nil {
+			return fmt.Errorf("get auth token: %w", err)
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+	if c.apiKey == "" {
+		return nil
+	}
+	if c.azure != nil {
+		httpReq.Header.Set("api-key", c.apiKey)
+		return nil
 	}
-	return c.baseURL + "/chat/completions"
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	return nil
 }
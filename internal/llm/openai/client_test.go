@@ -0,0 +1,186 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/errcode"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestAzureFlavorRequests verifies deployment URLs, api-version, and api-key auth.
+func TestAzureFlavorRequests(testingHandle *testing.T) {
+	// Arrange a server that records the request line and auth headers.
+	var paths, queries, apiKeys, bearers []string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		paths = append(paths, request.URL.Path)
+		queries = append(queries, request.URL.RawQuery)
+		apiKeys = append(apiKeys, request.Header.Get("api-key"))
+		bearers = append(bearers, request.Header.Get("Authorization"))
+		// Streams end at EOF, so one JSON body serves both request kinds.
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	request := func() *ChatRequest {
+		return &ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "hi"}}}
+	}
+
+	// Act
+	azure := NewClient(server.URL+"/openai/", "secret", 5*time.Second)
+	azure.SetAzure(AzureOptions{APIVersion: "2024-10-21", Deployments: map[string]string{"gpt-4o": "prod 4o"}})
+	_, err := azure.ChatCompletions(context.Background(), request())
+	testutil.RequireNoError(testingHandle, err, "azure completion")
+	unmapped := NewClient(server.URL, "secret", 5*time.Second)
+	unmapped.SetAzure(AzureOptions{APIVersion: "2024-10-21"})
+	_, err = unmapped.ChatCompletionsStream(context.Background(), request(), func(StreamResponse) error { return nil })
+	testutil.RequireNoError(testingHandle, err, "azure stream")
+	plain := NewClient(server.URL, "secret", 5*time.Second)
+	_, err = plain.ChatCompletions(context.Background(), request())
+	testutil.RequireNoError(testingHandle, err, "openai completion")
+
+	// Assert
+	testutil.RequireEqual(testingHandle, paths, []string{"/openai/deployments/prod 4o/chat/completions", "/openai/deployments/gpt-4o/chat/completions", "/chat/completions"}, "paths")
+	testutil.RequireEqual(testingHandle, queries, []string{"api-version=2024-10-21", "api-version=2024-10-21", ""}, "api-version")
+	testutil.RequireEqual(testingHandle, apiKeys, []string{"secret", "secret", ""}, "api-key header")
+	testutil.RequireEqual(testingHandle, bearers, []string{"", "", "Bearer secret"}, "bearer token")
+}
+
+// TestListModels verifies the models endpoint for both API flavors and API errors.
+func TestListModels(testingHandle *testing.T) {
+	// Arrange
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		paths = append(paths, request.Method+" "+request.URL.RequestURI())
+		if request.URL.Path == "/missing/models" {
+			http.NotFound(responseWriter, request)
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, `{"object":"list","data":[{"id":"gpt-4o"},{"id":"gpt-4o-mini"}]}`)
+	}))
+	defer server.Close()
+
+	// Act
+	models, err := NewClient(server.URL+"/v1/chat/completions", "secret", 5*time.Second).ListModels(context.Background())
+	azure := NewClient(server.URL+"/openai/deployments/prod", "secret", 5*time.Second)
+	azure.SetAzure(AzureOptions{APIVersion: "2024-10-21"})
+	_, azureErr := azure.ListModels(context.Background())
+	_, missingErr := NewClient(server.URL+"/missing", "secret", 5*time.Second).ListModels(context.Background())
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "list models")
+	testutil.RequireNoError(testingHandle, azureErr, "azure models")
+	testutil.RequireEqual(testingHandle, models, []string{"gpt-4o", "gpt-4o-mini"}, "model ids")
+	testutil.RequireEqual(testingHandle, paths[:2], []string{"GET /v1/models", "GET /openai/models?api-version=2024-10-21"}, "models urls")
+	apiErr, ok := missingErr.(*APIError)
+	testutil.RequireTrue(testingHandle, ok && apiErr.StatusCode == http.StatusNotFound, "missing endpoint is an API error")
+}
+
+// TestServerToolsPassthrough verifies configured server tools are sent beside
+// the function tools and that the server tool calls in a reply are split out
+// and counted rather than left for the agent to run.
+func TestServerToolsPassthrough(testingHandle *testing.T) {
+	// Arrange a gateway that reports one search as a typed tool call and one
+	// as Anthropic content blocks, without usage.server_tool_use.
+	var sent struct {
+		Tools []map[string]any `json:"tools"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		_ = json.NewDecoder(request.Body).Decode(&sent)
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"message":{"role":"assistant","content":[
+			{"type":"server_tool_use","id":"srv-2","name":"web_search","input":{"query":"go 1.24"}},
+			{"type":"web_search_tool_result","tool_use_id":"srv-2","content":[{"type":"web_search_result","url":"https://go.dev"}]},
+			{"type":"text","text":"Go 1.24 "},{"type":"text","text":"is out."}],
+			"tool_calls":[{"id":"srv-1","type":"web_search_call","function":{"arguments":"{\"query\":\"go\"}"}},
+			{"id":"call-1","type":"function","function":{"name":"Read","arguments":"{}"}}]},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	request := &ChatRequest{
+		Model:       "m",
+		Messages:    []Message{{Role: "user", Content: "news"}},
+		Tools:       []Tool{{Type: "function", Function: ToolFunction{Name: "Read"}}},
+		ServerTools: []map[string]any{{"type": "web_search", "max_uses": 2}},
+	}
+
+	// Act
+	response, err := NewClient(server.URL, "", 5*time.Second).ChatCompletions(context.Background(), request)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "chat completions")
+	testutil.RequireEqual(testingHandle, len(sent.Tools), 2, "function and server tools sent")
+	testutil.RequireEqual(testingHandle, sent.Tools[1], map[string]any{"type": "web_search", "max_uses": float64(2)}, "server tool sent verbatim")
+	message := response.Choices[0].Message
+	testutil.RequireEqual(testingHandle, message.Content, any("Go 1.24 is out."), "text kept")
+	testutil.RequireEqual(testingHandle, len(message.ToolCalls), 1, "only the function call is left to run")
+	testutil.RequireEqual(testingHandle, len(message.ServerToolCalls), 2, "server calls")
+	testutil.RequireEqual(testingHandle, message.ServerToolCalls[0].Name, "web_search", "name from call type")
+	testutil.RequireEqual(testingHandle, message.ServerToolCalls[1].Input, `{"query":"go 1.24"}`, "block input")
+	testutil.RequireTrue(testingHandle, strings.Contains(string(message.ServerToolCalls[1].Result), "https://go.dev"), "result attached to its call")
+	testutil.RequireEqual(testingHandle, response.Usage.ServerToolUse.WebSearchRequests, 2, "searches counted")
+
+	// The calls stay in history but are not sent back upstream.
+	payload, err := json.Marshal(&ChatRequest{Model: "m", Messages: []Message{message}})
+	testutil.RequireNoError(testingHandle, err, "marshal history")
+	testutil.RequireTrue(testingHandle, !strings.Contains(string(payload), "server_tool_calls"), "server calls stripped: "+string(payload))
+}
+
+// TestUsageTokenDetails verifies OpenAI token details fill the cache and
+// reasoning counters without overriding Anthropic-style cache fields.
+func TestUsageTokenDetails(testingHandle *testing.T) {
+	tests := []struct {
+		name          string
+		payload       string
+		wantCached    int
+		wantReasoning int
+	}{
+		{name: "openai details", payload: `{"prompt_tokens":100,"prompt_tokens_details":{"cached_tokens":80},"completion_tokens_details":{"reasoning_tokens":30}}`, wantCached: 80, wantReasoning: 30},
+		{name: "anthropic fields win", payload: `{"prompt_tokens":100,"cache_read_input_tokens":60,"prompt_tokens_details":{"cached_tokens":80}}`, wantCached: 60},
+		{name: "null details", payload: `{"prompt_tokens":100,"prompt_tokens_details":null,"completion_tokens_details":null}`},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Act
+			var usage Usage
+			err := json.Unmarshal([]byte(tt.payload), &usage)
+
+			// Assert
+			testutil.RequireNoError(testingHandle, err, "decode usage")
+			testutil.RequireEqual(testingHandle, usage.PromptTokens, 100, "prompt tokens")
+			testutil.RequireEqual(testingHandle, usage.CacheReadTokens, tt.wantCached, "cached tokens")
+			testutil.RequireEqual(testingHandle, usage.ReasoningTokens, tt.wantReasoning, "reasoning tokens")
+		})
+	}
+}
+
+// TestAPIErrorCategories verifies gateway errors are classified by status and
+// body into the errcode taxonomy.
+func TestAPIErrorCategories(testingHandle *testing.T) {
+	// Arrange
+	cases := []struct {
+		err  error
+		want errcode.Code
+	}{
+		{&APIError{StatusCode: http.StatusUnauthorized}, errcode.Auth},
+		{&APIError{StatusCode: http.StatusForbidden}, errcode.Auth},
+		{&APIError{StatusCode: http.StatusTooManyRequests}, errcode.RateLimited},
+		{&APIError{StatusCode: http.StatusBadGateway}, errcode.ProviderUnavailable},
+		{&TimeoutError{Kind: "stream idle", Limit: time.Second}, errcode.ProviderUnavailable},
+		{&APIError{StatusCode: http.StatusRequestEntityTooLarge}, errcode.ContextOverflow},
+		{&APIError{StatusCode: http.StatusBadRequest, Body: `{"error":{"code":"context_length_exceeded"}}`}, errcode.ContextOverflow},
+		{&APIError{StatusCode: http.StatusBadRequest, Body: `{"error":"bad tool schema"}`}, errcode.Internal},
+	}
+
+	for _, tc := range cases {
+		// Act
+		code := errcode.Of(fmt.Errorf("chat: %w", tc.err))
+
+		// Assert
+		testutil.RequireEqual(testingHandle, code, tc.want, tc.err.Error())
+	}
+}
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// modelList is the body of a GET /models response.
+type modelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// ListModels returns the model ids the gateway advertises at GET /models.
+// Gateways without the endpoint answer with an *APIError (usually 404).
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := c.send(ctx, http.MethodGet, c.modelsURL(), nil, "models")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read models response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	var parsed modelList
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("parse models response: %w", err)
+	}
+	ids := make([]string, 0, len(parsed.Data))
+	for _, model := range parsed.Data {
+		ids = append(ids, model.ID)
+	}
+	return ids, nil
+}
+
+// modelsURL derives the models endpoint from the base URL: {base}/models, or
+// {resource}/openai/models?api-version=... for Azure.
+// Query parameters on the base URL (some gateways take keys there) are kept.
+func (c *Client) modelsURL() string {
+	base, query, _ := strings.Cut(c.baseURL, "?")
+	base = strings.TrimSuffix(strings.TrimRight(base, "/"), "/chat/completions")
+	if c.azure == nil {
+		base += "/models"
+	} else {
+		if index := strings.Index(base, "/openai"); index >= 0 {
+			base = base[:index]
+		}
+		base += "/openai/models"
+		if c.azure.APIVersion != "" {
+			query = strings.TrimPrefix(query+"&api-version="+url.QueryEscape(c.azure.APIVersion), "&")
+		}
+	}
+	if query != "" {
+		base += "?" + query
+	}
+	return base
+}
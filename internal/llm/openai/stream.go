@@ -2,14 +2,21 @@ package openai
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"strings"
+	"time"
+)
+
+const (
+	// streamDrainLimit bounds what is read past [DONE] to reuse the connection.
+	streamDrainLimit = 4 << 10
+	// streamDrainTimeout bounds how long the gateway gets to end the response
+	// after [DONE] before the connection is dropped instead.
+	streamDrainTimeout = 100 * time.Millisecond
 )
 
 // ChatCompletionsStream executes a streaming chat/completions request.
@@ -31,23 +38,11 @@ func (c *Client) ChatCompletionsStream(ctx context.Context, req *ChatRequest, ha
 		return nil, fmt.Errorf("marshal chat request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		c.completionsURL(),
-		bytes.NewReader(payload),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("create chat request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
+	ctx, watchdog := c.streamContext(ctx)
+	defer watchdog.stop()
+	resp, err := c.post(ctx, req.Model, payload)
 	if err != nil {
-		return nil, fmt.Errorf("send chat request: %w", err)
+		return nil, timeoutError(ctx, err)
 	}
 	defer resp.Body.Close()
 
@@ -64,19 +59,21 @@ func (c *Client) ChatCompletionsStream(ctx context.Context, req *ChatRequest, ha
 
 	for {
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			return nil, timeoutError(ctx, ctx.Err())
 		}
 		data, err := readSSEEvent(reader)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				return summary, nil
 			}
-			return nil, fmt.Errorf("read stream event: %w", err)
+			return nil, timeoutError(ctx, fmt.Errorf("read stream event: %w", err))
 		}
+		watchdog.reset()
 		if data == "" {
 			continue
 		}
 		if data == "[DONE]" {
+			drainStream(resp.Body)
 			return summary, nil
 		}
 		var event StreamResponse
@@ -99,6 +96,15 @@ func (c *Client) ChatCompletionsStream(ctx context.Context, req *ChatRequest, ha
 	}
 }
 
+// drainStream reads what the gateway sends after [DONE] so the connection
+// goes back to the pool. Any other early return closes the body unread, which
+// aborts the transfer, as a canceled stream must.
+func drainStream(body io.ReadCloser) {
+	timer := time.AfterFunc(streamDrainTimeout, func() { _ = body.Close() })
+	defer timer.Stop()
+	_, _ = io.CopyN(io.Discard, body, streamDrainLimit)
+}
+
 // readSSEEvent reads a single SSE event payload.
 func readSSEEvent(reader *bufio.Reader) (string, error) {
 	var builder strings.Builder
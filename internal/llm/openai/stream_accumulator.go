@@ -2,12 +2,16 @@ package openai
 
 import (
 	"strings"
+
+	"github.com/openclaude/openclaude/internal/llm"
 )
 
 // StreamAccumulator builds a full assistant message from streaming deltas.
 type StreamAccumulator struct {
 	// contentBuilder accumulates streamed text content.
 	contentBuilder strings.Builder
+	// reasoningBuilder accumulates streamed thinking text.
+	reasoningBuilder strings.Builder
 	// toolStates stores tool call data keyed by streaming index.
 	toolStates map[int]*toolCallState
 	// toolOrder preserves the order tool calls first appeared.
@@ -63,6 +67,9 @@ func (acc *StreamAccumulator) Apply(event StreamResponse) error {
 		if delta.Content != "" {
 			acc.contentBuilder.WriteString(delta.Content)
 		}
+		if reasoning := delta.ReasoningText(); reasoning != "" {
+			acc.reasoningBuilder.WriteString(reasoning)
+		}
 		for _, toolDelta := range delta.ToolCalls {
 			state := acc.toolStates[toolDelta.Index]
 			if state == nil {
@@ -98,7 +105,9 @@ func (acc *StreamAccumulator) Message() Message {
 	if content := acc.contentBuilder.String(); content != "" {
 		message.Content = content
 	}
+	message.Reasoning = acc.reasoningBuilder.String()
 	message.ToolCalls = acc.ToolCalls()
+	llm.SplitServerTools(&message)
 	return message
 }
 
@@ -131,9 +140,14 @@ func (acc *StreamAccumulator) FinishReason() string {
 	return acc.finishReason
 }
 
-// Usage returns the final usage and whether it was provided.
+// Usage returns the final usage and whether it was provided. Server tool
+// counters the gateway left out are derived from the server tool calls.
 func (acc *StreamAccumulator) Usage() (Usage, bool) {
-	return acc.usage, acc.hasUsage
+	usage := acc.usage
+	if usage.ServerToolUse == (llm.ServerToolUse{}) {
+		usage.ServerToolUse = llm.CountServerTools(acc.Message().ServerToolCalls)
+	}
+	return usage, acc.hasUsage
 }
 
 // Model returns the model identifier, if present.
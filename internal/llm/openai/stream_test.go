@@ -2,10 +2,13 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,7 +35,7 @@ func TestChatCompletionsStreamParsesEvents(testingHandle *testing.T) {
 			`{"id":"req-1","model":"model-x","choices":[{"index":0,"delta":{"role":"assistant"}}]}`,
 			`{"choices":[{"index":0,"delta":{"content":"Hello "}}]}`,
 			`{"choices":[{"index":0,"delta":{"content":"world"}}]}`,
-			`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":2,"completion_tokens":2,"total_tokens":4}}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":2,"completion_tokens":2,"total_tokens":4,"prompt_tokens_details":{"cached_tokens":1},"completion_tokens_details":{"reasoning_tokens":1}}}`,
 		}
 
 		for _, payload := range events {
@@ -65,6 +68,8 @@ func TestChatCompletionsStreamParsesEvents(testingHandle *testing.T) {
 	testutil.RequireEqual(testingHandle, summary.Model, "model-x", "stream model mismatch")
 	testutil.RequireTrue(testingHandle, summary.HasUsage, "expected usage in summary")
 	testutil.RequireEqual(testingHandle, summary.Usage.TotalTokens, 4, "usage mismatch")
+	testutil.RequireEqual(testingHandle, summary.Usage.CacheReadTokens, 1, "cached tokens from prompt_tokens_details")
+	testutil.RequireEqual(testingHandle, summary.Usage.ReasoningTokens, 1, "reasoning tokens from completion_tokens_details")
 
 	collectedPayloads := make([]string, 0, len(collected))
 	for _, event := range collected {
@@ -75,3 +80,111 @@ func TestChatCompletionsStreamParsesEvents(testingHandle *testing.T) {
 	joined := strings.Join(collectedPayloads, ",")
 	testutil.RequireStringContains(testingHandle, joined, "req-1", "expected event id in stream")
 }
+
+// TestChatRequestOmitsHistoryReasoning verifies reasoning text is never sent upstream.
+func TestChatRequestOmitsHistoryReasoning(testingHandle *testing.T) {
+	history := []Message{
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "hello", Reasoning: "private"},
+	}
+	request := &ChatRequest{Model: "m", Messages: history}
+
+	payload, err := json.Marshal(request)
+	testutil.RequireNoError(testingHandle, err, "marshal request")
+
+	if strings.Contains(string(payload), "reasoning_content") {
+		testingHandle.Fatalf("expected reasoning to be stripped: %s", payload)
+	}
+	testutil.RequireEqual(testingHandle, history[1].Reasoning, "private", "caller history untouched")
+}
+
+// TestClientTimeouts verifies request caps, stream idle limits, and that streams
+// that keep producing events outlive the non-streaming cap.
+func TestClientTimeouts(testingHandle *testing.T) {
+	// Arrange: "/slow" never answers, "/stall" stops after one event, and
+	// "/steady" streams an event every 20ms for 200ms.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		wait := func(duration time.Duration) {
+			select {
+			case <-release:
+			case <-time.After(duration):
+			}
+		}
+		flusher := responseWriter.(http.Flusher)
+		switch {
+		case strings.HasPrefix(request.URL.Path, "/slow"):
+			wait(time.Second)
+		case strings.HasPrefix(request.URL.Path, "/stall"):
+			_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n")
+			flusher.Flush()
+			wait(time.Second)
+		default:
+			for index := 0; index < 10; index++ {
+				_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n")
+				flusher.Flush()
+				wait(20 * time.Millisecond)
+			}
+			_, _ = fmt.Fprint(responseWriter, "data: [DONE]\n\n")
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	timeouts := Timeouts{Request: 100 * time.Millisecond, StreamIdle: 100 * time.Millisecond}
+	client := func(path string) *Client {
+		client := NewClient(server.URL+path, "", time.Minute)
+		client.SetTimeouts(timeouts)
+		return client
+	}
+	request := func() *ChatRequest {
+		return &ChatRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}}
+	}
+	var events int
+	count := func(StreamResponse) error { events++; return nil }
+
+	// Act
+	_, slowErr := client("/slow").ChatCompletions(context.Background(), request())
+	_, stallErr := client("/stall").ChatCompletionsStream(context.Background(), request(), count)
+	events = 0
+	_, steadyErr := client("/steady").ChatCompletionsStream(context.Background(), request(), count)
+
+	// Assert
+	testutil.RequireEqual(testingHandle, slowErr, error(&TimeoutError{Kind: "request", Limit: 100 * time.Millisecond}), "request cap")
+	testutil.RequireEqual(testingHandle, stallErr, error(&TimeoutError{Kind: "stream idle", Limit: 100 * time.Millisecond}), "idle stream")
+	testutil.RequireNoError(testingHandle, steadyErr, "steady stream outlives the request cap")
+	testutil.RequireEqual(testingHandle, events, 10, "steady events")
+}
+
+// TestChatCompletionsStreamReusesConnection verifies a stream that ends with
+// [DONE] is drained, so the next request reuses its connection.
+func TestChatCompletionsStreamReusesConnection(testingHandle *testing.T) {
+	// Arrange a gateway that lingers briefly after [DONE] before ending the response.
+	var connections atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(responseWriter, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\ndata: [DONE]\n\n")
+		responseWriter.(http.Flusher).Flush()
+		time.Sleep(10 * time.Millisecond)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+	client := NewClient(server.URL, "", time.Minute)
+	request := func() *ChatRequest {
+		return &ChatRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}}
+	}
+	ignore := func(StreamResponse) error { return nil }
+
+	// Act
+	_, firstErr := client.ChatCompletionsStream(context.Background(), request(), ignore)
+	_, secondErr := client.ChatCompletionsStream(context.Background(), request(), ignore)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, firstErr, "first stream")
+	testutil.RequireNoError(testingHandle, secondErr, "second stream")
+	testutil.RequireEqual(testingHandle, connections.Load(), int64(1), "connections opened")
+}
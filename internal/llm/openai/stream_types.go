@@ -34,10 +34,22 @@ type StreamDelta struct {
 	Role string `json:"role,omitempty"`
 	// Content holds streamed text.
 	Content string `json:"content,omitempty"`
+	// ReasoningContent holds streamed thinking text (DeepSeek, vLLM, LiteLLM).
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+	// Reasoning holds streamed thinking text (OpenRouter-style gateways).
+	Reasoning string `json:"reasoning,omitempty"`
 	// ToolCalls streams tool call metadata and arguments.
 	ToolCalls []StreamToolCallDelta `json:"tool_calls,omitempty"`
 }
 
+// ReasoningText returns the thinking text carried by the delta, whichever field the gateway uses.
+func (delta StreamDelta) ReasoningText() string {
+	if delta.ReasoningContent != "" {
+		return delta.ReasoningContent
+	}
+	return delta.Reasoning
+}
+
 // StreamToolCallDelta represents incremental tool call data.
 type StreamToolCallDelta struct {
 	// Index identifies the tool call position.
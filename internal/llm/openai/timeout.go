@@ -0,0 +1,124 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/errcode"
+)
+
+// Timeouts bounds API calls by kind, replacing the single HTTP client timeout
+// that otherwise cuts long streams off mid-generation. Zero disables a limit.
+type Timeouts struct {
+	// Request caps a whole non-streaming call.
+	Request time.Duration
+	// Stream caps a whole streaming call, however steadily it is progressing.
+	Stream time.Duration
+	// StreamIdle caps the wait for response headers and between stream events.
+	StreamIdle time.Duration
+}
+
+// TimeoutError reports an API call stopped by one of the client's Timeouts.
+type TimeoutError struct {
+	// Kind is "request", "stream", or "stream idle".
+	Kind string
+	// Limit is the timeout that expired.
+	Limit time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Kind == "stream idle" {
+		return fmt.Sprintf("api stream idle for %s; no data from the gateway", e.Limit)
+	}
+	return fmt.Sprintf("api %s timed out after %s", e.Kind, e.Limit)
+}
+
+// Is places timeouts under errcode.ErrProviderUnavailable.
+func (e *TimeoutError) Is(target error) bool {
+	return target == errcode.ErrProviderUnavailable
+}
+
+// SetTimeouts applies per-kind timeouts in place of the client-wide timeout
+// passed to NewClient.
+func (c *Client) SetTimeouts(timeouts Timeouts) {
+	c.timeouts = &timeouts
+	c.httpClient.Timeout = 0
+}
+
+// requestContext bounds a non-streaming call by Timeouts.Request.
+func (c *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeouts == nil || c.timeouts.Request <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, c.timeouts.Request, &TimeoutError{Kind: "request", Limit: c.timeouts.Request})
+}
+
+// streamWatchdog cancels a stream that stays silent for longer than its idle limit.
+type streamWatchdog struct {
+	// mu guards timer against a reset racing stop.
+	mu sync.Mutex
+	// timer fires the idle cancellation; nil without an idle limit.
+	timer *time.Timer
+	// idle is the allowed silence.
+	idle time.Duration
+	// cancel releases the stream context.
+	cancel context.CancelFunc
+}
+
+// reset restarts the idle countdown after the stream made progress.
+func (w *streamWatchdog) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Reset(w.idle)
+	}
+}
+
+// stop disarms the watchdog and releases the stream context.
+func (w *streamWatchdog) stop() {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.mu.Unlock()
+	w.cancel()
+}
+
+// streamContext bounds a streaming call by Timeouts.Stream and Timeouts.StreamIdle.
+func (c *Client) streamContext(ctx context.Context) (context.Context, *streamWatchdog) {
+	watchdog := &streamWatchdog{cancel: func() {}}
+	if c.timeouts == nil {
+		return ctx, watchdog
+	}
+	if c.timeouts.Stream > 0 {
+		ctx, watchdog.cancel = context.WithTimeoutCause(ctx, c.timeouts.Stream, &TimeoutError{Kind: "stream", Limit: c.timeouts.Stream})
+	}
+	if c.timeouts.StreamIdle > 0 {
+		var cancelIdle context.CancelCauseFunc
+		ctx, cancelIdle = context.WithCancelCause(ctx)
+		cancelTotal := watchdog.cancel
+		watchdog.cancel = func() {
+			cancelIdle(nil)
+			cancelTotal()
+		}
+		watchdog.idle = c.timeouts.StreamIdle
+		watchdog.timer = time.AfterFunc(watchdog.idle, func() {
+			cancelIdle(&TimeoutError{Kind: "stream idle", Limit: watchdog.idle})
+		})
+	}
+	return ctx, watchdog
+}
+
+// timeoutError returns the TimeoutError that canceled ctx in place of the
+// context or transport error it caused, and err otherwise.
+func timeoutError(ctx context.Context, err error) error {
+	var timeout *TimeoutError
+	if err != nil && errors.As(context.Cause(ctx), &timeout) {
+		return timeout
+	}
+	return err
+}
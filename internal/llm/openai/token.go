@@ -0,0 +1,207 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies short-lived bearer tokens in place of a static API key.
+type TokenSource interface {
+	// Token returns a valid token, fetching a new one when the cached token is about to expire.
+	Token(ctx context.Context) (string, error)
+	// Invalidate drops the cached token so the next call fetches a fresh one.
+	Invalidate()
+}
+
+// tokenRefreshMargin refreshes tokens this long before they expire so a request
+// never starts with a token that lapses mid-flight.
+const tokenRefreshMargin = time.Minute
+
+// tokenCommandTimeout bounds a token command run.
+const tokenCommandTimeout = 30 * time.Second
+
+// fetchedToken is a token with the lifetime reported by its source (zero when unknown).
+type fetchedToken struct {
+	value     string
+	expiresIn time.Duration
+}
+
+// cachedTokenSource caches a fetched token until shortly before it expires.
+type cachedTokenSource struct {
+	// fetch obtains a new token.
+	fetch func(ctx context.Context) (fetchedToken, error)
+	// defaultTTL is the lifetime assumed when fetch does not report one.
+	defaultTTL time.Duration
+	// now returns the current time; tests replace it.
+	now func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	refresh time.Time
+}
+
+// Token returns the cached token or fetches a new one.
+func (s *cachedTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.now().Before(s.refresh) {
+		return s.token, nil
+	}
+	fetched, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	if fetched.value == "" {
+		return "", errors.New("token source returned an empty token")
+	}
+	lifetime := fetched.expiresIn
+	if lifetime <= 0 {
+		lifetime = s.defaultTTL
+	}
+	// Short-lived tokens are refreshed halfway through instead of never being cached.
+	margin := min(tokenRefreshMargin, lifetime/2)
+	s.token = fetched.value
+	s.refresh = s.now().Add(lifetime - margin)
+	return s.token, nil
+}
+
+// Invalidate drops the cached token.
+func (s *cachedTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+// NewCommandTokenSource runs command through the shell for each token, like
+// `gcloud auth print-access-token`. The command prints either the token or an
+// OAuth token response ({"access_token": ..., "expires_in": ...}); plain tokens
+// are reused for ttl.
+func NewCommandTokenSource(command string, ttl time.Duration) TokenSource {
+	return &cachedTokenSource{
+		fetch: func(ctx context.Context) (fetchedToken, error) {
+			return runTokenCommand(ctx, command)
+		},
+		defaultTTL: ttl,
+		now:        time.Now,
+	}
+}
+
+// runTokenCommand runs the token command and parses its output.
+func runTokenCommand(ctx context.Context, command string) (fetchedToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Stdout may hold a partial token, so only stderr is reported.
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return fetchedToken{}, fmt.Errorf("token command failed: %w: %s", err, detail)
+		}
+		return fetchedToken{}, fmt.Errorf("token command failed: %w", err)
+	}
+	output := strings.TrimSpace(stdout.String())
+	if strings.HasPrefix(output, "{") {
+		return parseTokenResponse([]byte(output))
+	}
+	return fetchedToken{value: output}, nil
+}
+
+// ClientCredentials configures the OAuth 2.0 client credentials grant.
+type ClientCredentials struct {
+	// TokenURL is the authorization server's token endpoint.
+	TokenURL string
+	// ClientID and ClientSecret identify the client.
+	ClientID     string
+	ClientSecret string
+	// Scopes are requested as a space-separated scope parameter.
+	Scopes []string
+	// Audience is sent for servers that require it (e.g., Auth0).
+	Audience string
+}
+
+// NewClientCredentialsTokenSource requests tokens from an OAuth token endpoint
+// with the client credentials grant. Tokens without expires_in are reused for ttl.
+func NewClientCredentialsTokenSource(credentials ClientCredentials, httpClient *http.Client, ttl time.Duration) TokenSource {
+	return &cachedTokenSource{
+		fetch: func(ctx context.Context) (fetchedToken, error) {
+			return requestClientCredentialsToken(ctx, credentials, httpClient)
+		},
+		defaultTTL: ttl,
+		now:        time.Now,
+	}
+}
+
+// requestClientCredentialsToken exchanges the client credentials for an access token.
+func requestClientCredentialsToken(ctx context.Context, credentials ClientCredentials, httpClient *http.Client) (fetchedToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", credentials.ClientID)
+	form.Set("client_secret", credentials.ClientSecret)
+	if len(credentials.Scopes) > 0 {
+		form.Set("scope", strings.Join(credentials.Scopes, " "))
+	}
+	if credentials.Audience != "" {
+		form.Set("audience", credentials.Audience)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, credentials.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fetchedToken{}, fmt.Errorf("create token request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return fetchedToken{}, fmt.Errorf("send token request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fetchedToken{}, fmt.Errorf("read token response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fetchedToken{}, fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return parseTokenResponse(body)
+}
+
+// parseTokenResponse reads an OAuth token response body.
+func parseTokenResponse(body []byte) (fetchedToken, error) {
+	var parsed struct {
+		AccessToken string          `json:"access_token"`
+		ExpiresIn   json.RawMessage `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return fetchedToken{}, fmt.Errorf("parse token response: %w", err)
+	}
+	if parsed.AccessToken == "" {
+		return fetchedToken{}, errors.New("token response has no access_token")
+	}
+	// Some servers send expires_in as a string.
+	var seconds float64
+	if err := json.Unmarshal(parsed.ExpiresIn, &seconds); err != nil {
+		var text string
+		if json.Unmarshal(parsed.ExpiresIn, &text) == nil {
+			seconds, _ = strconv.ParseFloat(text, 64)
+		}
+	}
+	return fetchedToken{value: parsed.AccessToken, expiresIn: time.Duration(seconds * float64(time.Second))}, nil
+}
@@ -0,0 +1,93 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestClientCredentialsTokenRefresh verifies token caching, refresh before expiry, and the 401 retry.
+func TestClientCredentialsTokenRefresh(testingHandle *testing.T) {
+	// Arrange a token endpoint that numbers its tokens and a gateway that rejects the first one.
+	issued := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		if request.ParseForm() != nil || request.PostForm.Get("grant_type") != "client_credentials" ||
+			request.PostForm.Get("client_secret") != "shh" || request.PostForm.Get("scope") != "a b" {
+			http.Error(responseWriter, "bad request", http.StatusBadRequest)
+			return
+		}
+		issued++
+		_, _ = fmt.Fprintf(responseWriter, `{"access_token":"tok-%d","token_type":"Bearer","expires_in":3600}`, issued)
+	}))
+	defer tokenServer.Close()
+	var bearers []string
+	gateway := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		bearers = append(bearers, request.Header.Get("Authorization"))
+		if request.Header.Get("Authorization") == "Bearer tok-1" {
+			http.Error(responseWriter, "expired", http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprint(responseWriter, `{"choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer gateway.Close()
+	now := time.Unix(1_700_000_000, 0)
+	source := NewClientCredentialsTokenSource(ClientCredentials{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "id",
+		ClientSecret: "shh",
+		Scopes:       []string{"a", "b"},
+	}, tokenServer.Client(), 5*time.Minute)
+	source.(*cachedTokenSource).now = func() time.Time { return now }
+	client := NewClient(gateway.URL, "", 5*time.Second)
+	client.SetTokenSource(source)
+	request := func() *ChatRequest {
+		return &ChatRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}}
+	}
+
+	// Act: the first call retries after a 401, the second reuses the token,
+	// and the third runs inside the refresh margin.
+	_, err := client.ChatCompletions(context.Background(), request())
+	testutil.RequireNoError(testingHandle, err, "first completion")
+	_, err = client.ChatCompletions(context.Background(), request())
+	testutil.RequireNoError(testingHandle, err, "cached completion")
+	now = now.Add(59*time.Minute + time.Second)
+	_, err = client.ChatCompletionsStream(context.Background(), request(), func(StreamResponse) error { return nil })
+	testutil.RequireNoError(testingHandle, err, "refreshed stream")
+
+	// Assert
+	testutil.RequireEqual(testingHandle, bearers, []string{"Bearer tok-1", "Bearer tok-2", "Bearer tok-2", "Bearer tok-3"}, "bearer tokens")
+	testutil.RequireEqual(testingHandle, issued, 3, "tokens issued")
+}
+
+// TestCommandTokenSource verifies plain and JSON command output and failure reporting.
+func TestCommandTokenSource(testingHandle *testing.T) {
+	if runtime.GOOS == "windows" {
+		testingHandle.Skip("uses sh quoting")
+	}
+	ctx := context.Background()
+
+	token, err := NewCommandTokenSource("printf 'plain-token\\n'", time.Minute).Token(ctx)
+	testutil.RequireNoError(testingHandle, err, "plain token")
+	testutil.RequireEqual(testingHandle, token, "plain-token", "plain token")
+
+	source := NewCommandTokenSource(`echo '{"access_token":"json-token","expires_in":"120"}'`, time.Hour)
+	now := time.Unix(1_700_000_000, 0)
+	source.(*cachedTokenSource).now = func() time.Time { return now }
+	token, err = source.Token(ctx)
+	testutil.RequireNoError(testingHandle, err, "json token")
+	testutil.RequireEqual(testingHandle, token, "json-token", "json token")
+	refresh := source.(*cachedTokenSource).refresh
+	testutil.RequireEqual(testingHandle, refresh, now.Add(time.Minute), "expires_in overrides the ttl")
+
+	_, err = NewCommandTokenSource("echo 'not logged in' >&2; exit 3", time.Minute).Token(ctx)
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "not logged in"), fmt.Sprint(err))
+	_, err = NewCommandTokenSource("true", time.Minute).Token(ctx)
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "empty token"), fmt.Sprint(err))
+}
@@ -1,5 +1,11 @@
 package openai
 
+import (
+	"encoding/json"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
 // ChatRequest matches the OpenAI-compatible chat/completions request.
 type ChatRequest struct {
 	// Model is the provider model identifier.
@@ -8,6 +14,9 @@ type ChatRequest struct {
 	Messages []Message `json:"messages"`
 	// Tools advertises available tool functions.
 	Tools []Tool `json:"tools,omitempty"`
+	// ServerTools are provider-defined tools, such as {"type": "web_search"},
+	// sent verbatim after the function tools.
+	ServerTools []map[string]any `json:"-"`
 	// ToolChoice directs tool usage (e.g., "auto").
 	ToolChoice any `json:"tool_choice,omitempty"`
 	// Stream toggles server-sent events in the response.
@@ -18,57 +27,76 @@ type ChatRequest struct {
 	Temperature *float64 `json:"temperature,omitempty"`
 	// MaxTokens limits the model output, if supported by the backend.
 	MaxTokens *int `json:"max_tokens,omitempty"`
+	// TopP limits sampling to the most probable tokens, if supported by the backend.
+	TopP *float64 `json:"top_p,omitempty"`
+	// Stop lists sequences that end the completion when generated.
+	Stop []string `json:"stop,omitempty"`
+	// ReasoningEffort requests OpenAI-style reasoning (low, medium, high).
+	ReasoningEffort string `json:"reasoning_effort,omitempty"`
+	// Thinking requests Anthropic-style extended thinking with a token budget.
+	Thinking *ThinkingConfig `json:"thinking,omitempty"`
 }
 
-// Message represents a chat message.
-type Message struct {
-	// Role is one of system, user, assistant, or tool.
-	Role string `json:"role"`
-	// Content carries message text or structured payloads.
-	Content any `json:"content,omitempty"`
-	// ToolCalls lists tool invocations requested by the assistant.
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
-	// ToolCallID associates a tool response to a prior call.
-	ToolCallID string `json:"tool_call_id,omitempty"`
-	// Name optionally identifies a function or assistant.
-	Name string `json:"name,omitempty"`
-}
-
-// Tool describes a callable function for the model.
-type Tool struct {
-	// Type must be "function" for OpenAI-compatible tools.
+// ThinkingConfig matches the Anthropic-compatible extended thinking parameter.
+type ThinkingConfig struct {
+	// Type is "enabled" to turn thinking on.
 	Type string `json:"type"`
-	// Function describes the callable function contract.
-	Function ToolFunction `json:"function"`
+	// BudgetTokens caps tokens spent on thinking.
+	BudgetTokens int `json:"budget_tokens"`
 }
 
-// ToolFunction defines a function for tool calling.
-type ToolFunction struct {
-	// Name is the unique identifier for the function.
-	Name string `json:"name"`
-	// Description provides a natural language summary.
-	Description string `json:"description,omitempty"`
-	// Parameters is a JSON Schema object describing inputs.
-	Parameters map[string]any `json:"parameters,omitempty"`
+// MarshalJSON encodes the request, dropping reasoning text and server tool
+// calls from history and appending the server tools to tools. Several
+// gateways reject reasoning_content on input messages, and prior reasoning
+// is not needed for the next turn.
+func (req ChatRequest) MarshalJSON() ([]byte, error) {
+	type wireRequest ChatRequest
+	wire := wireRequest(req)
+	copied := false
+	for index, message := range req.Messages {
+		if message.Reasoning == "" && len(message.ServerToolCalls) == 0 {
+			continue
+		}
+		// Copy on first write so the caller's history keeps its reasoning.
+		if !copied {
+			wire.Messages = append([]Message(nil), req.Messages...)
+			copied = true
+		}
+		wire.Messages[index].Reasoning = ""
+		wire.Messages[index].ServerToolCalls = nil
+	}
+	if len(req.ServerTools) == 0 {
+		return json.Marshal(wire)
+	}
+	tools := make([]any, 0, len(req.Tools)+len(req.ServerTools))
+	for _, tool := range req.Tools {
+		tools = append(tools, tool)
+	}
+	for _, tool := range req.ServerTools {
+		tools = append(tools, tool)
+	}
+	// The outer tools field shadows the embedded one.
+	return json.Marshal(struct {
+		wireRequest
+		Tools []any `json:"tools,omitempty"`
+	}{wire, tools})
 }
 
-// ToolCall represents a tool invocation requested by the model.
-type ToolCall struct {
-	// ID is the unique tool call id.
-	ID string `json:"id"`
-	// Type is the tool type, typically "function".
-	Type string `json:"type"`
-	// Function includes the name and serialized arguments.
-	Function ToolCallFunction `json:"function"`
-}
+// Message aliases the provider-agnostic chat message.
+// The OpenAI wire format matches llm.Message, so no translation is required.
+type Message = llm.Message
 
-// ToolCallFunction is the function call payload.
-type ToolCallFunction struct {
-	// Name identifies which tool to invoke.
-	Name string `json:"name"`
-	// Arguments contains a JSON string to be parsed by the tool.
-	Arguments string `json:"arguments"`
-}
+// Tool aliases the provider-agnostic tool definition.
+type Tool = llm.Tool
+
+// ToolFunction aliases the provider-agnostic function definition.
+type ToolFunction = llm.ToolFunction
+
+// ToolCall aliases the provider-agnostic tool invocation.
+type ToolCall = llm.ToolCall
+
+// ToolCallFunction aliases the provider-agnostic function call payload.
+type ToolCallFunction = llm.ToolCallFunction
 
 // ChatResponse matches the OpenAI-compatible chat/completions response.
 type ChatResponse struct {
@@ -90,12 +118,5 @@ type ChatChoice struct {
 	FinishReason string `json:"finish_reason"`
 }
 
-// Usage represents token usage info.
-type Usage struct {
-	// PromptTokens counts input tokens.
-	PromptTokens int `json:"prompt_tokens"`
-	// CompletionTokens counts output tokens.
-	CompletionTokens int `json:"completion_tokens"`
-	// TotalTokens is the sum of prompt and completion tokens.
-	TotalTokens int `json:"total_tokens"`
-}
+// Usage aliases the provider-agnostic token usage counters.
+type Usage = llm.Usage
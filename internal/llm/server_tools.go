@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ServerToolCall records a tool the provider ran itself, such as web search
+// or a code interpreter. It is shown and counted but never run locally.
+type ServerToolCall struct {
+	// ID is the provider's id for the call, when it sends one.
+	ID string `json:"id,omitempty"`
+	// Name identifies the server tool, e.g. "web_search".
+	Name string `json:"name"`
+	// Input is the call's arguments as JSON, when the provider reports them.
+	Input string `json:"input,omitempty"`
+	// Result is the provider's raw result block, when it reports one.
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// SplitServerTools moves the server tool calls a gateway returned out of
+// message, leaving the text and the function calls the agent should run.
+// Gateways report them as tool calls whose type is not "function", or as
+// Anthropic server_tool_use and *_tool_result content blocks.
+func SplitServerTools(message *Message) {
+	if len(message.ToolCalls) > 0 {
+		var functions []ToolCall
+		for _, call := range message.ToolCalls {
+			if call.Type == "" || call.Type == "function" {
+				functions = append(functions, call)
+				continue
+			}
+			name := call.Function.Name
+			if name == "" {
+				name = strings.TrimSuffix(call.Type, "_call")
+			}
+			message.ServerToolCalls = append(message.ServerToolCalls, ServerToolCall{ID: call.ID, Name: name, Input: call.Function.Arguments})
+		}
+		message.ToolCalls = functions
+	}
+
+	blocks, ok := message.Content.([]any)
+	if !ok {
+		return
+	}
+	var text strings.Builder
+	found := false
+	for _, item := range blocks {
+		block, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		kind, _ := block["type"].(string)
+		switch {
+		case kind == ContentPartText:
+			value, _ := block["text"].(string)
+			text.WriteString(value)
+		case kind == "server_tool_use":
+			found = true
+			call := ServerToolCall{}
+			call.ID, _ = block["id"].(string)
+			call.Name, _ = block["name"].(string)
+			if input, err := json.Marshal(block["input"]); err == nil && block["input"] != nil {
+				call.Input = string(input)
+			}
+			message.ServerToolCalls = append(message.ServerToolCalls, call)
+		case strings.HasSuffix(kind, "_tool_result"):
+			found = true
+			raw, _ := json.Marshal(block)
+			id, _ := block["tool_use_id"].(string)
+			attachServerToolResult(message, id, strings.TrimSuffix(kind, "_tool_result"), raw)
+		}
+	}
+	if found {
+		message.Content = nil
+		if text.Len() > 0 {
+			message.Content = text.String()
+		}
+	}
+}
+
+// attachServerToolResult stores a result block on its call, or records it
+// as a call of its own when the call was not reported.
+func attachServerToolResult(message *Message, id string, name string, raw json.RawMessage) {
+	for index := range message.ServerToolCalls {
+		if id != "" && message.ServerToolCalls[index].ID == id {
+			message.ServerToolCalls[index].Result = raw
+			return
+		}
+	}
+	message.ServerToolCalls = append(message.ServerToolCalls, ServerToolCall{ID: id, Name: name, Result: raw})
+}
+
+// CountServerTools derives the usage counters from server tool calls, for
+// gateways that return the calls but not usage.server_tool_use.
+func CountServerTools(calls []ServerToolCall) ServerToolUse {
+	var use ServerToolUse
+	for _, call := range calls {
+		switch {
+		case strings.Contains(call.Name, "web_search"):
+			use.WebSearchRequests++
+		case strings.Contains(call.Name, "web_fetch"):
+			use.WebFetchRequests++
+		}
+	}
+	return use
+}
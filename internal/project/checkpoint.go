@@ -0,0 +1,166 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gitSnapshotTimeout bounds each git command that snapshots or restores a work
+// tree, which may hash every file in a large repository.
+const gitSnapshotTimeout = time.Minute
+
+// WorkTreeSnapshot identifies a saved copy of a git work tree's files.
+type WorkTreeSnapshot struct {
+	// Root is the work tree's top-level directory.
+	Root string
+	// Tree is the git tree object holding every tracked and untracked, non-ignored file.
+	Tree string
+}
+
+// SnapshotWorkTree stores the current files of the work tree containing dir,
+// including untracked files but not ignored ones, in the repository's object
+// database. HEAD, the index, and the files themselves are left untouched. Like
+// other unreachable objects, snapshots are eventually pruned by git gc.
+func SnapshotWorkTree(ctx context.Context, dir string) (WorkTreeSnapshot, error) {
+	root, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return WorkTreeSnapshot{}, fmt.Errorf("%s is not inside a git work tree", dir)
+	}
+	snapshot := WorkTreeSnapshot{Root: strings.TrimSpace(root)}
+	if snapshot.Tree, err = writeWorkTree(ctx, snapshot.Root); err != nil {
+		return WorkTreeSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// RestoreWorkTree puts the work tree's files back as they were in snapshot:
+// changed and deleted files are rewritten and files created since are removed.
+// Ignored files and the index are left alone. It returns the restored paths,
+// relative to the work tree root.
+func RestoreWorkTree(ctx context.Context, snapshot WorkTreeSnapshot) ([]string, error) {
+	current, err := writeWorkTree(ctx, snapshot.Root)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := runGitSnapshot(ctx, snapshot.Root, nil, "", "diff-tree", "-r", "-z", "--no-renames", "--name-status", snapshot.Tree, current)
+	if err != nil {
+		return nil, fmt.Errorf("compare with snapshot %s: %w", snapshot.Tree, err)
+	}
+
+	var restored, checkout []string
+	fields := strings.Split(strings.TrimSuffix(diff, "\x00"), "\x00")
+	for index := 0; index+1 < len(fields); index += 2 {
+		status, path := fields[index], fields[index+1]
+		restored = append(restored, path)
+		if status == "A" {
+			// Created after the snapshot.
+			if err := os.Remove(filepath.Join(snapshot.Root, filepath.FromSlash(path))); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("remove %s: %w", path, err)
+			}
+			continue
+		}
+		checkout = append(checkout, path)
+	}
+	if len(checkout) > 0 {
+		index, cleanup, err := scratchIndex(ctx, snapshot.Root, false)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		if _, err := runGitSnapshot(ctx, snapshot.Root, index, "", "read-tree", snapshot.Tree); err != nil {
+			return nil, fmt.Errorf("read snapshot %s: %w", snapshot.Tree, err)
+		}
+		stdin := strings.Join(checkout, "\x00") + "\x00"
+		if _, err := runGitSnapshot(ctx, snapshot.Root, index, stdin, "checkout-index", "--force", "-z", "--stdin"); err != nil {
+			return nil, fmt.Errorf("restore files: %w", err)
+		}
+	}
+	sort.Strings(restored)
+	return restored, nil
+}
+
+// writeWorkTree stages the whole work tree into a scratch index and writes it as a tree object.
+func writeWorkTree(ctx context.Context, root string) (string, error) {
+	index, cleanup, err := scratchIndex(ctx, root, true)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	if _, err := runGitSnapshot(ctx, root, index, "", "add", "--all"); err != nil {
+		return "", fmt.Errorf("snapshot files: %w", err)
+	}
+	tree, err := runGitSnapshot(ctx, root, index, "", "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("write snapshot tree: %w", err)
+	}
+	return strings.TrimSpace(tree), nil
+}
+
+// scratchIndex returns the environment selecting a temporary index file, and a
+// cleanup func. Seeding it from the real index lets git skip rehashing unchanged files.
+func scratchIndex(ctx context.Context, root string, seed bool) ([]string, func(), error) {
+	dir, err := os.MkdirTemp("", "openclaude-index-")
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("create scratch index: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	path := filepath.Join(dir, "index")
+	if seed {
+		if real, err := runGit(ctx, root, "rev-parse", "--git-path", "index"); err == nil {
+			real = strings.TrimSpace(real)
+			if !filepath.IsAbs(real) {
+				real = filepath.Join(root, real)
+			}
+			if err := copyFile(real, path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				cleanup()
+				return nil, func() {}, fmt.Errorf("seed scratch index: %w", err)
+			}
+		}
+	}
+	return []string{"GIT_INDEX_FILE=" + path}, cleanup, nil
+}
+
+// copyFile copies the file at source to target.
+func copyFile(source string, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	_, copyErr := io.Copy(out, in)
+	return errors.Join(copyErr, out.Close())
+}
+
+// runGitSnapshot runs a git command that may write objects or files, with extra
+// environment and stdin, and reports git's stderr on failure.
+func runGitSnapshot(ctx context.Context, dir string, env []string, stdin string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitSnapshotTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,64 @@
+package project
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSnapshotAndRestoreWorkTree verifies edits, deletions, and new files are undone
+// while ignored files and the index are left alone.
+func TestSnapshotAndRestoreWorkTree(testingHandle *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		testingHandle.Skip("git not installed")
+	}
+	// Arrange: a repository with a committed, a staged, an untracked, and an ignored file.
+	root := testingHandle.TempDir()
+	_, err := SnapshotWorkTree(context.Background(), root)
+	testutil.RequireTrue(testingHandle, err != nil, "non-repository rejected")
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL="+filepath.Join(root, ".gitconfig-none"), "GIT_CONFIG_NOSYSTEM=1")
+		output, err := cmd.CombinedOutput()
+		testutil.RequireNoError(testingHandle, err, "git "+strings.Join(args, " ")+": "+string(output))
+		return string(output)
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	git("config", "user.email", "dev@example.com")
+	git("config", "user.name", "Dev")
+	writeFiles(testingHandle, root, map[string]string{".gitignore": "*.log\n", "main.go": "package main\n", "old.go": "package old\n"})
+	git("add", ".")
+	git("commit", "--quiet", "-m", "Initial commit")
+	writeFiles(testingHandle, root, map[string]string{"staged.go": "package staged\n", "notes.txt": "draft\n", "build.log": "v1\n"})
+	git("add", "staged.go")
+	snapshot, err := SnapshotWorkTree(context.Background(), root)
+	testutil.RequireNoError(testingHandle, err, "snapshot")
+
+	// Act: edit, delete, and create files, then restore.
+	writeFiles(testingHandle, root, map[string]string{"main.go": "package broken\n", "new.go": "package new\n", "build.log": "v2\n"})
+	testutil.RequireNoError(testingHandle, os.Remove(filepath.Join(root, "old.go")), "delete old.go")
+	testutil.RequireNoError(testingHandle, os.Remove(filepath.Join(root, "notes.txt")), "delete notes.txt")
+	restored, err := RestoreWorkTree(context.Background(), snapshot)
+	testutil.RequireNoError(testingHandle, err, "restore")
+
+	// Assert
+	testutil.RequireEqual(testingHandle, restored, []string{"main.go", "new.go", "notes.txt", "old.go"}, "restored paths")
+	for name, want := range map[string]string{"main.go": "package main\n", "old.go": "package old\n", "notes.txt": "draft\n", "build.log": "v2\n"} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		testutil.RequireNoError(testingHandle, err, "read "+name)
+		testutil.RequireEqual(testingHandle, string(data), want, name)
+	}
+	_, err = os.Stat(filepath.Join(root, "new.go"))
+	testutil.RequireTrue(testingHandle, os.IsNotExist(err), "new file removed")
+	testutil.RequireEqual(testingHandle, git("diff", "--cached", "--name-only"), "staged.go\n", "index untouched")
+
+	restored, err = RestoreWorkTree(context.Background(), snapshot)
+	testutil.RequireNoError(testingHandle, err, "restore again")
+	testutil.RequireEqual(testingHandle, len(restored), 0, "nothing left to restore")
+}
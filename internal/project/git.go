@@ -0,0 +1,245 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// gitCommandTimeout bounds each git invocation so slow repositories never block startup.
+	gitCommandTimeout = 3 * time.Second
+	// maxGitStatusLines caps changed files listed in the system prompt.
+	maxGitStatusLines = 40
+	// gitRecentCommits is how many commits the system prompt lists.
+	gitRecentCommits = 5
+)
+
+// GitStatus summarizes the repository state of the working directory.
+type GitStatus struct {
+	// Branch is the current branch, or "HEAD (detached)".
+	Branch string `json:"branch"`
+	// MainBranch is the repository's default branch when known.
+	MainBranch string `json:"main_branch,omitempty"`
+	// Upstream is the tracked remote branch, if any.
+	Upstream string `json:"upstream,omitempty"`
+	// Ahead counts local commits not on the upstream.
+	Ahead int `json:"ahead"`
+	// Behind counts upstream commits not in the local branch.
+	Behind int `json:"behind"`
+	// Dirty counts changed, staged, conflicted, and untracked files.
+	Dirty int `json:"dirty"`
+	// Changes lists files in `git status --short` form.
+	Changes []string `json:"-"`
+	// RecentCommits lists `git log --oneline` entries, newest first.
+	RecentCommits []string `json:"-"`
+}
+
+// LoadGitStatus inspects the repository containing dir. It returns nil when
+// dir is not inside a git work tree or git is unavailable.
+func LoadGitStatus(ctx context.Context, dir string) *GitStatus {
+	output, err := runGit(ctx, dir, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return nil
+	}
+	status := parseGitStatus(output)
+	if log, err := runGit(ctx, dir, "log", "--oneline", "--no-decorate", "-n", strconv.Itoa(gitRecentCommits)); err == nil {
+		status.RecentCommits = nonEmptyLines(log)
+	}
+	status.MainBranch = GitMainBranch(ctx, dir)
+	return status
+}
+
+// parseGitStatus reads `git status --porcelain=v2 --branch` output.
+func parseGitStatus(output string) *GitStatus {
+	status := &GitStatus{}
+	for _, line := range nonEmptyLines(output) {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			status.Branch = strings.TrimPrefix(line, "# branch.head ")
+			if status.Branch == "(detached)" {
+				status.Branch = "HEAD (detached)"
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			fmt.Sscanf(strings.TrimPrefix(line, "# branch.ab "), "+%d -%d", &status.Ahead, &status.Behind)
+		case strings.HasPrefix(line, "# "):
+			continue
+		default:
+			if change := shortStatusLine(line); change != "" {
+				status.Dirty++
+				status.Changes = append(status.Changes, change)
+			}
+		}
+	}
+	return status
+}
+
+// shortStatusLine converts a porcelain v2 entry to `git status --short` form.
+func shortStatusLine(line string) string {
+	// Field counts before the path: ordinary 8, renamed 9, unmerged 10.
+	var fields []string
+	switch {
+	case strings.HasPrefix(line, "? "):
+		return "?? " + strings.TrimPrefix(line, "? ")
+	case strings.HasPrefix(line, "1 "):
+		fields = strings.SplitN(line, " ", 9)
+	case strings.HasPrefix(line, "2 "):
+		fields = strings.SplitN(line, " ", 10)
+	case strings.HasPrefix(line, "u "):
+		fields = strings.SplitN(line, " ", 11)
+	default:
+		return ""
+	}
+	if len(fields) < 3 {
+		return ""
+	}
+	code := strings.ReplaceAll(fields[1], ".", " ")
+	path := fields[len(fields)-1]
+	if target, source, renamed := strings.Cut(path, "\t"); renamed {
+		path = source + " -> " + target
+	}
+	return code + " " + path
+}
+
+// GitMainBranch guesses the default branch from origin/HEAD, then main or master.
+func GitMainBranch(ctx context.Context, dir string) string {
+	if ref, err := runGit(ctx, dir, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if _, branch, ok := strings.Cut(strings.TrimSpace(ref), "/"); ok {
+			return branch
+		}
+	}
+	for _, candidate := range []string{"main", "master"} {
+		if _, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// GitDirtyFiles returns the absolute paths of files with uncommitted changes,
+// including untracked files, in the repository containing dir. Renamed files
+// are listed under both names.
+func GitDirtyFiles(ctx context.Context, dir string) ([]string, error) {
+	top, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("not a git work tree: %w", err)
+	}
+	output, err := runGit(ctx, dir, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSpace(top)
+	var files []string
+	entries := strings.Split(output, "\x00")
+	for index := 0; index < len(entries); index++ {
+		entry := entries[index]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, filepath.Join(root, filepath.FromSlash(entry[3:])))
+		// A rename or copy is followed by its source path.
+		if (entry[0] == 'R' || entry[0] == 'C') && index+1 < len(entries) {
+			index++
+			files = append(files, filepath.Join(root, filepath.FromSlash(entries[index])))
+		}
+	}
+	return files, nil
+}
+
+// GitStash stashes every uncommitted change, including untracked files, under message.
+func GitStash(ctx context.Context, dir string, message string) error {
+	if _, err := runGit(ctx, dir, "stash", "push", "--include-untracked", "--message", message); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return fmt.Errorf("git stash: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("git stash: %w", err)
+	}
+	return nil
+}
+
+// GitHead returns the commit checked out in dir, or "" outside a git work tree.
+func GitHead(ctx context.Context, dir string) string {
+	output, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// runGit runs a git command in dir without taking optional locks.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--no-optional-locks"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	return string(output), err
+}
+
+// nonEmptyLines splits output into trimmed, non-empty lines.
+func nonEmptyLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Summary renders the system prompt block, mirroring Claude Code's gitStatus section.
+func (s *GitStatus) Summary() string {
+	if s == nil {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("gitStatus: This is the git status at the start of the conversation. Note that this status is a snapshot in time, and will not update during the conversation.\n")
+	fmt.Fprintf(&builder, "Current branch: %s\n", s.Branch)
+	if s.MainBranch != "" {
+		fmt.Fprintf(&builder, "\nMain branch (you will usually use this for PRs): %s\n", s.MainBranch)
+	}
+	if s.Upstream != "" {
+		fmt.Fprintf(&builder, "\nUpstream: %s (ahead %d, behind %d)\n", s.Upstream, s.Ahead, s.Behind)
+	}
+	builder.WriteString("\nStatus:\n")
+	if len(s.Changes) == 0 {
+		builder.WriteString("(clean)\n")
+	}
+	for index, change := range s.Changes {
+		if index == maxGitStatusLines {
+			fmt.Fprintf(&builder, "... and %d more\n", len(s.Changes)-maxGitStatusLines)
+			break
+		}
+		builder.WriteString(change + "\n")
+	}
+	if len(s.RecentCommits) > 0 {
+		builder.WriteString("\nRecent commits:\n" + strings.Join(s.RecentCommits, "\n"))
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// Brief renders a compact status line entry such as "main ↑1 ↓2 ●3".
+func (s *GitStatus) Brief() string {
+	if s == nil {
+		return ""
+	}
+	brief := s.Branch
+	if s.Ahead > 0 {
+		brief += fmt.Sprintf(" ↑%d", s.Ahead)
+	}
+	if s.Behind > 0 {
+		brief += fmt.Sprintf(" ↓%d", s.Behind)
+	}
+	if s.Dirty > 0 {
+		brief += fmt.Sprintf(" ●%d", s.Dirty)
+	}
+	return brief
+}
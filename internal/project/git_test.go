@@ -0,0 +1,94 @@
+package project
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestParseGitStatus verifies porcelain v2 headers and entries are summarized.
+func TestParseGitStatus(testingHandle *testing.T) {
+	output := strings.Join([]string{
+		"# branch.oid 0123456789abcdef",
+		"# branch.head feature",
+		"# branch.upstream origin/feature",
+		"# branch.ab +2 -1",
+		"1 .M N... 100644 100644 100644 abc abc cmd/main.go",
+		"1 A. N... 000000 100644 100644 000 abc docs/new file.md",
+		"2 R. N... 100644 100644 100644 abc abc R100 new.go\told.go",
+		"u UU N... 100644 100644 100644 100644 a b c conflict.go",
+		"? scratch.txt",
+	}, "\n")
+	status := parseGitStatus(output)
+
+	testutil.RequireEqual(testingHandle, status.Branch, "feature", "branch")
+	testutil.RequireEqual(testingHandle, status.Upstream, "origin/feature", "upstream")
+	testutil.RequireEqual(testingHandle, status.Ahead, 2, "ahead")
+	testutil.RequireEqual(testingHandle, status.Behind, 1, "behind")
+	testutil.RequireEqual(testingHandle, status.Dirty, 5, "dirty")
+	testutil.RequireEqual(testingHandle, strings.Join(status.Changes, "|"), " M cmd/main.go|A  docs/new file.md|R  old.go -> new.go|UU conflict.go|?? scratch.txt", "changes")
+	testutil.RequireEqual(testingHandle, status.Brief(), "feature ↑2 ↓1 ●5", "brief")
+}
+
+// TestLoadGitStatus verifies a real repository reports branch, changes, and commits.
+func TestLoadGitStatus(testingHandle *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		testingHandle.Skip("git not installed")
+	}
+	root := testingHandle.TempDir()
+	testutil.RequireTrue(testingHandle, LoadGitStatus(context.Background(), root) == nil, "non-repository returns nil")
+	testutil.RequireEqual(testingHandle, GitHead(context.Background(), root), "", "non-repository has no head")
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL="+filepath.Join(root, ".gitconfig-none"), "GIT_CONFIG_NOSYSTEM=1")
+		output, err := cmd.CombinedOutput()
+		testutil.RequireNoError(testingHandle, err, "git "+strings.Join(args, " ")+": "+string(output))
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	git("config", "user.email", "dev@example.com")
+	git("config", "user.name", "Dev")
+	writeFiles(testingHandle, root, map[string]string{"README.md": "hello\n"})
+	git("add", "README.md")
+	git("commit", "--quiet", "-m", "Initial commit")
+
+	status := LoadGitStatus(context.Background(), root)
+	testutil.RequireTrue(testingHandle, status != nil, "repository detected")
+	testutil.RequireEqual(testingHandle, status.Branch, "main", "branch")
+	testutil.RequireEqual(testingHandle, status.MainBranch, "main", "main branch")
+	testutil.RequireEqual(testingHandle, status.Dirty, 0, "clean")
+	testutil.RequireEqual(testingHandle, len(GitHead(context.Background(), root)), 40, "head commit")
+	testutil.RequireTrue(testingHandle, strings.Contains(status.Summary(), "Status:\n(clean)"), "clean summary")
+
+	writeFiles(testingHandle, root, map[string]string{"README.md": "changed\n", "notes.txt": "draft\n"})
+	status = LoadGitStatus(context.Background(), root)
+	testutil.RequireEqual(testingHandle, status.Dirty, 2, "dirty")
+	summary := status.Summary()
+	for _, want := range []string{"Current branch: main", " M README.md", "?? notes.txt", "Recent commits:", "Initial commit"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(summary, want), "summary contains "+want)
+	}
+	testutil.RequireEqual(testingHandle, status.Brief(), "main ●2", "brief")
+
+	dirty, err := GitDirtyFiles(context.Background(), root)
+	testutil.RequireNoError(testingHandle, err, "dirty files")
+	testutil.RequireEqual(testingHandle, dirty, []string{filepath.Join(root, "README.md"), filepath.Join(root, "notes.txt")}, "dirty files")
+	testutil.RequireNoError(testingHandle, GitStash(context.Background(), root, "pre-flight"), "stash")
+	testutil.RequireEqual(testingHandle, LoadGitStatus(context.Background(), root).Dirty, 0, "clean after stash")
+}
+
+// TestGitStatusSummaryCapsChanges verifies long change lists are truncated.
+func TestGitStatusSummaryCapsChanges(testingHandle *testing.T) {
+	status := &GitStatus{Branch: "main"}
+	for index := 0; index < maxGitStatusLines+3; index++ {
+		status.Changes = append(status.Changes, "?? file")
+	}
+	testutil.RequireTrue(testingHandle, strings.Contains(status.Summary(), "... and 3 more"), "summary truncated")
+	var missing *GitStatus
+	testutil.RequireEqual(testingHandle, missing.Summary(), "", "nil summary")
+}
@@ -0,0 +1,288 @@
+// Package project detects facts about the workspace OpenClaude runs in.
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// manifestFiles lists the files detection reads; their stat results form the cache fingerprint.
+var manifestFiles = []string{
+	"go.mod",
+	"package.json", "tsconfig.json", "package-lock.json", "pnpm-lock.yaml", "yarn.lock", "bun.lockb", "bun.lock",
+	"Cargo.toml",
+	"pyproject.toml", "requirements.txt", "setup.py", "poetry.lock", "uv.lock", "Pipfile",
+	"Gemfile",
+	"pom.xml", "build.gradle", "build.gradle.kts", "gradlew",
+	"composer.json",
+	"mix.exs",
+	"CMakeLists.txt",
+	"Makefile",
+}
+
+// Toolchain summarizes the languages and commands detected in a project root.
+type Toolchain struct {
+	// Languages lists detected languages in detection order.
+	Languages []string `json:"languages,omitempty"`
+	// PackageManagers lists detected package managers and build tools.
+	PackageManagers []string `json:"package_managers,omitempty"`
+	// Build lists likely build commands.
+	Build []string `json:"build,omitempty"`
+	// Test lists likely test commands.
+	Test []string `json:"test,omitempty"`
+	// Lint lists likely lint commands.
+	Lint []string `json:"lint,omitempty"`
+	// Manifests lists the files the result was derived from.
+	Manifests []string `json:"manifests,omitempty"`
+}
+
+// toolchainCache is the on-disk cache record.
+type toolchainCache struct {
+	// Fingerprint identifies the manifest state the toolchain was detected from.
+	Fingerprint string `json:"fingerprint"`
+	// Toolchain is the cached detection result.
+	Toolchain Toolchain `json:"toolchain"`
+}
+
+// Empty reports whether nothing was detected.
+func (t Toolchain) Empty() bool {
+	return len(t.Languages) == 0 && len(t.Build) == 0 && len(t.Test) == 0 && len(t.Lint) == 0
+}
+
+// Summary renders a compact block for the system prompt.
+func (t Toolchain) Summary() string {
+	if t.Empty() {
+		return ""
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Project toolchain (auto-detected from %s; verify before relying on it):", strings.Join(t.Manifests, ", "))
+	for _, field := range []struct {
+		label  string
+		values []string
+	}{
+		{"Languages", t.Languages},
+		{"Package managers", t.PackageManagers},
+		{"Build", t.Build},
+		{"Test", t.Test},
+		{"Lint", t.Lint},
+	} {
+		if len(field.values) > 0 {
+			fmt.Fprintf(&builder, "\n- %s: %s", field.label, strings.Join(field.values, "; "))
+		}
+	}
+	return builder.String()
+}
+
+// Brief renders a one-line summary for the welcome banner.
+func (t Toolchain) Brief() string {
+	if t.Empty() {
+		return ""
+	}
+	var parts []string
+	if len(t.Languages) > 0 {
+		languages := strings.Join(t.Languages, ", ")
+		if len(t.PackageManagers) > 0 {
+			languages += " (" + strings.Join(t.PackageManagers, ", ") + ")"
+		}
+		parts = append(parts, languages)
+	}
+	if len(t.Test) > 0 {
+		parts = append(parts, "test: "+t.Test[0])
+	}
+	return strings.Join(parts, " · ")
+}
+
+// LoadToolchain returns the toolchain for root, reusing cachePath while the
+// manifests are unchanged. Entries never expire by time; cache failures fall
+// back to fresh detection.
+func LoadToolchain(root string, cachePath string) Toolchain {
+	fingerprint := manifestFingerprint(root)
+	if cachePath != "" {
+		if raw, err := os.ReadFile(cachePath); err == nil {
+			var cached toolchainCache
+			if json.Unmarshal(raw, &cached) == nil && cached.Fingerprint == fingerprint {
+				return cached.Toolchain
+			}
+		}
+	}
+	toolchain := DetectToolchain(root)
+	if cachePath != "" {
+		if raw, err := json.Marshal(toolchainCache{Fingerprint: fingerprint, Toolchain: toolchain}); err == nil {
+			if os.MkdirAll(filepath.Dir(cachePath), 0o700) == nil {
+				_ = os.WriteFile(cachePath, raw, 0o600)
+			}
+		}
+	}
+	return toolchain
+}
+
+// manifestFingerprint summarizes manifest names, sizes, and modification times.
+func manifestFingerprint(root string) string {
+	var builder strings.Builder
+	for _, name := range manifestFiles {
+		if info, err := os.Stat(filepath.Join(root, name)); err == nil {
+			fmt.Fprintf(&builder, "%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return builder.String()
+}
+
+// DetectToolchain scans manifest files in root (not subdirectories).
+func DetectToolchain(root string) Toolchain {
+	var t Toolchain
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(root, name))
+		return err == nil
+	}
+	read := func(name string) string {
+		raw, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			return ""
+		}
+		return string(raw)
+	}
+	for _, name := range manifestFiles {
+		if exists(name) {
+			t.Manifests = append(t.Manifests, name)
+		}
+	}
+
+	if exists("go.mod") {
+		t.add("Go", "go modules")
+		t.Build = append(t.Build, "go build ./...")
+		t.Test = append(t.Test, "go test ./...")
+		t.Lint = append(t.Lint, "go vet ./...")
+	}
+	if exists("package.json") {
+		language := "JavaScript"
+		if exists("tsconfig.json") {
+			language = "TypeScript"
+		}
+		manager := "npm"
+		switch {
+		case exists("pnpm-lock.yaml"):
+			manager = "pnpm"
+		case exists("yarn.lock"):
+			manager = "yarn"
+		case exists("bun.lockb"), exists("bun.lock"):
+			manager = "bun"
+		}
+		t.add(language, manager)
+		var pkg struct {
+			Scripts map[string]string `json:"scripts"`
+		}
+		_ = json.Unmarshal([]byte(read("package.json")), &pkg)
+		for _, script := range []struct {
+			name    string
+			command *[]string
+		}{{"build", &t.Build}, {"test", &t.Test}, {"lint", &t.Lint}, {"typecheck", &t.Lint}} {
+			if _, ok := pkg.Scripts[script.name]; ok {
+				*script.command = append(*script.command, manager+" run "+script.name)
+			}
+		}
+	}
+	if exists("Cargo.toml") {
+		t.add("Rust", "cargo")
+		t.Build = append(t.Build, "cargo build")
+		t.Test = append(t.Test, "cargo test")
+		t.Lint = append(t.Lint, "cargo clippy")
+	}
+	if exists("pyproject.toml") || exists("requirements.txt") || exists("setup.py") || exists("Pipfile") {
+		pyproject := read("pyproject.toml")
+		manager := "pip"
+		switch {
+		case exists("uv.lock"):
+			manager = "uv"
+		case exists("poetry.lock"), strings.Contains(pyproject, "[tool.poetry"):
+			manager = "poetry"
+		case exists("Pipfile"):
+			manager = "pipenv"
+		}
+		t.add("Python", manager)
+		runner := ""
+		if manager != "pip" {
+			runner = manager + " run "
+		}
+		if strings.Contains(pyproject, "pytest") || exists("tests") || exists("pytest.ini") {
+			t.Test = append(t.Test, runner+"pytest")
+		}
+		if strings.Contains(pyproject, "[tool.ruff") {
+			t.Lint = append(t.Lint, runner+"ruff check .")
+		}
+	}
+	if exists("Gemfile") {
+		t.add("Ruby", "bundler")
+		if exists("Rakefile") {
+			t.Test = append(t.Test, "bundle exec rake test")
+		}
+	}
+	if exists("pom.xml") {
+		t.add("Java", "maven")
+		t.Build = append(t.Build, "mvn package")
+		t.Test = append(t.Test, "mvn test")
+	}
+	if exists("build.gradle") || exists("build.gradle.kts") {
+		language := "Java"
+		if exists("build.gradle.kts") {
+			language = "Kotlin"
+		}
+		gradle := "gradle"
+		if exists("gradlew") {
+			gradle = "./gradlew"
+		}
+		t.add(language, "gradle")
+		t.Build = append(t.Build, gradle+" build")
+		t.Test = append(t.Test, gradle+" test")
+	}
+	if exists("composer.json") {
+		t.add("PHP", "composer")
+	}
+	if exists("mix.exs") {
+		t.add("Elixir", "mix")
+		t.Build = append(t.Build, "mix compile")
+		t.Test = append(t.Test, "mix test")
+	}
+	if exists("CMakeLists.txt") {
+		t.add("C/C++", "cmake")
+		t.Build = append(t.Build, "cmake -B build && cmake --build build")
+	}
+	if makefile := read("Makefile"); makefile != "" {
+		targets := makeTargets(makefile)
+		for _, target := range []struct {
+			name    string
+			command *[]string
+		}{{"build", &t.Build}, {"test", &t.Test}, {"lint", &t.Lint}, {"check", &t.Lint}} {
+			if slices.Contains(targets, target.name) {
+				*target.command = append(*target.command, "make "+target.name)
+			}
+		}
+	}
+	return t
+}
+
+// add records a language and package manager once each.
+func (t *Toolchain) add(language string, manager string) {
+	if !slices.Contains(t.Languages, language) {
+		t.Languages = append(t.Languages, language)
+	}
+	if !slices.Contains(t.PackageManagers, manager) {
+		t.PackageManagers = append(t.PackageManagers, manager)
+	}
+}
+
+// makeTargetPattern matches explicit Makefile rule names.
+var makeTargetPattern = regexp.MustCompile(`(?m)^([A-Za-z][A-Za-z0-9_-]*)\s*:([^=]|$)`)
+
+// makeTargets lists the explicit targets defined in a Makefile.
+func makeTargets(makefile string) []string {
+	var targets []string
+	for _, match := range makeTargetPattern.FindAllStringSubmatch(makefile, -1) {
+		targets = append(targets, match[1])
+	}
+	return targets
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// writeFiles creates files with contents under root.
+func writeFiles(testingHandle *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, name), []byte(content), 0o644), "write "+name)
+	}
+}
+
+// TestDetectToolchain verifies manifests map to languages, package managers, and commands.
+func TestDetectToolchain(testingHandle *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Toolchain
+	}{
+		{
+			name:  "go with makefile",
+			files: map[string]string{"go.mod": "module example\n", "Makefile": "VERSION := 1\nbuild:\n\tgo build\nlint: fmt\n\tgolangci-lint run\n"},
+			want: Toolchain{
+				Languages: []string{"Go"}, PackageManagers: []string{"go modules"},
+				Build: []string{"go build ./...", "make build"}, Test: []string{"go test ./..."}, Lint: []string{"go vet ./...", "make lint"},
+				Manifests: []string{"go.mod", "Makefile"},
+			},
+		},
+		{
+			name:  "typescript with pnpm scripts",
+			files: map[string]string{"package.json": `{"scripts":{"test":"vitest","lint":"eslint ."}}`, "tsconfig.json": "{}", "pnpm-lock.yaml": ""},
+			want: Toolchain{
+				Languages: []string{"TypeScript"}, PackageManagers: []string{"pnpm"},
+				Test: []string{"pnpm run test"}, Lint: []string{"pnpm run lint"},
+				Manifests: []string{"package.json", "tsconfig.json", "pnpm-lock.yaml"},
+			},
+		},
+		{
+			name:  "poetry project",
+			files: map[string]string{"pyproject.toml": "[tool.poetry]\n[tool.pytest.ini_options]\n[tool.ruff]\n"},
+			want: Toolchain{
+				Languages: []string{"Python"}, PackageManagers: []string{"poetry"},
+				Test: []string{"poetry run pytest"}, Lint: []string{"poetry run ruff check ."},
+				Manifests: []string{"pyproject.toml"},
+			},
+		},
+		{name: "empty directory", files: map[string]string{}, want: Toolchain{}},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			root := testingHandle.TempDir()
+			writeFiles(testingHandle, root, tt.files)
+			testutil.RequireEqual(testingHandle, DetectToolchain(root), tt.want, "toolchain")
+		})
+	}
+}
+
+// TestLoadToolchainCache verifies cached results are reused until a manifest changes.
+func TestLoadToolchainCache(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	cachePath := filepath.Join(testingHandle.TempDir(), "toolchain", "project.json")
+	writeFiles(testingHandle, root, map[string]string{"Cargo.toml": "[package]\n"})
+
+	first := LoadToolchain(root, cachePath)
+	testutil.RequireEqual(testingHandle, first.Languages, []string{"Rust"}, "detected")
+	testutil.RequireTrue(testingHandle, strings.Contains(first.Summary(), "- Test: cargo test"), "summary lists commands")
+
+	// Tamper with the cache: an unchanged fingerprint must return the cached value.
+	raw, err := os.ReadFile(cachePath)
+	testutil.RequireNoError(testingHandle, err, "read cache")
+	testutil.RequireNoError(testingHandle, os.WriteFile(cachePath, []byte(strings.Replace(string(raw), "Rust", "Cached", 1)), 0o600), "rewrite cache")
+	testutil.RequireEqual(testingHandle, LoadToolchain(root, cachePath).Languages, []string{"Cached"}, "cache reused")
+
+	later := time.Now().Add(time.Minute)
+	testutil.RequireNoError(testingHandle, os.Chtimes(filepath.Join(root, "Cargo.toml"), later, later), "touch manifest")
+	testutil.RequireEqual(testingHandle, LoadToolchain(root, cachePath).Languages, []string{"Rust"}, "changed manifest re-detects")
+}
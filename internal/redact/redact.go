@@ -0,0 +1,178 @@
+// Package redact masks credentials in tool output and prompts before they are
+// sent upstream or saved with the session.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// rule is one credential pattern. When the pattern has a capture group, only
+// the first group is masked, so "Authorization: Bearer" stays readable.
+type rule struct {
+	// name labels the mask, e.g. [REDACTED:aws-access-key].
+	name string
+	// pattern matches the credential.
+	pattern *regexp.Regexp
+}
+
+// builtinRules are the credential formats masked by default. They match
+// formats with a recognizable prefix or structure, not any long random
+// string, so ordinary code and hashes come through untouched.
+var builtinRules = []rule{
+	{"private-key", regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-key", regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`)},
+	{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"api-key", regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"stripe-key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"google-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"authorization", regexp.MustCompile(`(?i)\bauthorization["']?\s*[:=]\s*["']?(?:bearer|basic|token)\s+([A-Za-z0-9._~+/=-]{8,})`)},
+	{"url-password", regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:([^/\s@]+)@`)},
+}
+
+// Redactor masks credentials in text. A nil Redactor masks nothing.
+type Redactor struct {
+	rules []rule
+}
+
+// New returns a Redactor for the built-in credential formats plus custom
+// regular expressions (RE2 syntax). A custom pattern with a capture group
+// masks only the first group.
+func New(custom []string) (*Redactor, error) {
+	rules := append([]rule(nil), builtinRules...)
+	for _, expression := range custom {
+		pattern, err := Compile(expression)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule{name: "custom", pattern: pattern})
+	}
+	return &Redactor{rules: rules}, nil
+}
+
+// Compile parses a custom pattern, rejecting ones that match empty text.
+func Compile(expression string) (*regexp.Regexp, error) {
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("redaction pattern %q: %w", expression, err)
+	}
+	if pattern.MatchString("") {
+		return nil, fmt.Errorf("redaction pattern %q matches empty text", expression)
+	}
+	return pattern, nil
+}
+
+// MaskPrefix starts every mask String writes. File tools look for it so a
+// masked value read back from a tool result is never written over the real one.
+const MaskPrefix = "[REDACTED:"
+
+// CountMasks returns how many masks text contains.
+func CountMasks(text string) int {
+	return strings.Count(text, MaskPrefix)
+}
+
+// String returns text with every credential replaced by [REDACTED:<kind>].
+func (r *Redactor) String(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	for _, rule := range r.rules {
+		mask := MaskPrefix + rule.name + "]"
+		if rule.pattern.NumSubexp() == 0 {
+			text = rule.pattern.ReplaceAllLiteralString(text, mask)
+			continue
+		}
+		text = rule.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			groups := rule.pattern.FindStringSubmatchIndex(match)
+			if len(groups) < 4 || groups[2] < 0 {
+				return mask
+			}
+			return match[:groups[2]] + mask + match[groups[3]:]
+		})
+	}
+	return text
+}
+
+// Messages returns messages with the text of each one redacted: string
+// content, text parts, and text blocks decoded from JSON. Messages that need
+// no change are shared with the input; the input itself is not modified.
+func (r *Redactor) Messages(messages []llm.Message) []llm.Message {
+	if r == nil {
+		return messages
+	}
+	redacted := messages
+	copied := false
+	for index, message := range messages {
+		content, changed := r.content(message.Content)
+		if !changed {
+			continue
+		}
+		if !copied {
+			redacted = append([]llm.Message(nil), messages...)
+			copied = true
+		}
+		redacted[index].Content = content
+	}
+	return redacted
+}
+
+// content redacts one message's content, reporting whether it changed.
+func (r *Redactor) content(content any) (any, bool) {
+	switch value := content.(type) {
+	case string:
+		masked := r.String(value)
+		return masked, masked != value
+	case []llm.ContentPart:
+		var parts []llm.ContentPart
+		for index, part := range value {
+			masked := r.String(part.Text)
+			if masked == part.Text {
+				continue
+			}
+			if parts == nil {
+				parts = append([]llm.ContentPart(nil), value...)
+			}
+			parts[index].Text = masked
+		}
+		if parts == nil {
+			return content, false
+		}
+		return parts, true
+	case []any:
+		var blocks []any
+		for index, item := range value {
+			block, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			text, ok := block["text"].(string)
+			if !ok {
+				continue
+			}
+			masked := r.String(text)
+			if masked == text {
+				continue
+			}
+			if blocks == nil {
+				blocks = append([]any(nil), value...)
+			}
+			copyBlock := make(map[string]any, len(block))
+			for key, field := range block {
+				copyBlock[key] = field
+			}
+			copyBlock["text"] = masked
+			blocks[index] = copyBlock
+		}
+		if blocks == nil {
+			return content, false
+		}
+		return blocks, true
+	}
+	return content, false
+}
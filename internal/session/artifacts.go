@@ -0,0 +1,99 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Artifact describes a file kept in a session's artifacts directory.
+type Artifact struct {
+	// Name is the path relative to the artifacts directory, with forward slashes.
+	Name string `json:"name"`
+	// Path is the absolute path on disk.
+	Path string `json:"path"`
+	// SizeBytes is the file size.
+	SizeBytes int64 `json:"size_bytes"`
+	// ModifiedAt is the last modification time.
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ArtifactsDir returns the directory holding a session's artifacts: tool output
+// spill files, saved figures, and files commands write to $OPENCLAUDE_ARTIFACTS_DIR.
+func (s *Store) ArtifactsDir(sessionID string) string {
+	return filepath.Join(s.BaseDir, "session-env", sessionID, "artifacts")
+}
+
+// WriteArtifact saves data under name in the session's artifacts directory.
+// An existing artifact is never overwritten; a numeric suffix is added instead.
+func (s *Store) WriteArtifact(sessionID string, name string, data []byte) (Artifact, error) {
+	if sessionID == "" {
+		return Artifact{}, errors.New("session id required")
+	}
+	name = filepath.Base(filepath.Clean(name))
+	if name == "." || name == string(filepath.Separator) {
+		return Artifact{}, errors.New("artifact name required")
+	}
+	dir := s.ArtifactsDir(sessionID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return Artifact{}, fmt.Errorf("create artifacts dir: %w", err)
+	}
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := name
+	for attempt := 2; ; attempt++ {
+		path := filepath.Join(dir, candidate)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			candidate = fmt.Sprintf("%s-%d%s", stem, attempt, ext)
+			continue
+		}
+		if err != nil {
+			return Artifact{}, fmt.Errorf("create artifact: %w", err)
+		}
+		_, writeErr := file.Write(data)
+		closeErr := file.Close()
+		if err := errors.Join(writeErr, closeErr); err != nil {
+			return Artifact{}, fmt.Errorf("write artifact: %w", err)
+		}
+		return Artifact{Name: candidate, Path: path, SizeBytes: int64(len(data)), ModifiedAt: time.Now().UTC()}, nil
+	}
+}
+
+// ListArtifacts returns a session's artifacts sorted by name, including files in
+// subdirectories. A session without artifacts yields nil.
+func (s *Store) ListArtifacts(sessionID string) ([]Artifact, error) {
+	dir := s.ArtifactsDir(sessionID)
+	var artifacts []Artifact
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, Artifact{Name: filepath.ToSlash(rel), Path: path, SizeBytes: info.Size(), ModifiedAt: info.ModTime().UTC()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list artifacts: %w", err)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Name < artifacts[j].Name })
+	return artifacts, nil
+}
@@ -0,0 +1,41 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestWriteAndListArtifacts verifies artifacts are never overwritten and list with sizes.
+func TestWriteAndListArtifacts(testingHandle *testing.T) {
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	artifacts, err := store.ListArtifacts("s1")
+	testutil.RequireNoError(testingHandle, err, "list missing dir")
+	testutil.RequireEqual(testingHandle, len(artifacts), 0, "no artifacts yet")
+
+	first, err := store.WriteArtifact("s1", "report.txt", []byte("one"))
+	testutil.RequireNoError(testingHandle, err, "write first")
+	second, err := store.WriteArtifact("s1", "../report.txt", []byte("second"))
+	testutil.RequireNoError(testingHandle, err, "write second")
+	testutil.RequireEqual(testingHandle, first.Name, "report.txt", "first name")
+	testutil.RequireEqual(testingHandle, second.Name, "report-2.txt", "suffixed name")
+	testutil.RequireEqual(testingHandle, filepath.Dir(second.Path), store.ArtifactsDir("s1"), "name cannot escape the directory")
+	info, err := os.Stat(first.Path)
+	testutil.RequireNoError(testingHandle, err, "stat artifact")
+	testutil.RequireEqual(testingHandle, info.Mode().Perm(), os.FileMode(0o600), "artifact mode")
+
+	nested := filepath.Join(store.ArtifactsDir("s1"), "reports", "summary.md")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Dir(nested), 0o700), "mkdir nested")
+	testutil.RequireNoError(testingHandle, os.WriteFile(nested, []byte("# ok\n"), 0o600), "write nested")
+
+	artifacts, err = store.ListArtifacts("s1")
+	testutil.RequireNoError(testingHandle, err, "list artifacts")
+	names := make([]string, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		names = append(names, artifact.Name)
+	}
+	testutil.RequireEqual(testingHandle, names, []string{"report-2.txt", "report.txt", "reports/summary.md"}, "artifact names")
+	testutil.RequireEqual(testingHandle, artifacts[0].SizeBytes, int64(6), "artifact size")
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// checkpointNamePattern limits names to characters that are safe as file names.
+var checkpointNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Checkpoint is a named snapshot of a session's conversation and, when the
+// workspace is a git work tree, of its files.
+type Checkpoint struct {
+	// Name identifies the checkpoint within its session.
+	Name string `json:"name"`
+	// CreatedAt is when the checkpoint was taken.
+	CreatedAt time.Time `json:"created_at"`
+	// Messages is the conversation history, including the system prompt.
+	Messages []llm.Message `json:"messages"`
+	// WorkTree is the git work tree root the file snapshot was taken from.
+	WorkTree string `json:"work_tree,omitempty"`
+	// Tree is the git tree object holding the files; empty when files were not captured.
+	Tree string `json:"tree,omitempty"`
+}
+
+// CheckpointsDir returns the directory holding a session's checkpoints.
+func (s *Store) CheckpointsDir(sessionID string) string {
+	return filepath.Join(s.BaseDir, "session-env", sessionID, "checkpoints")
+}
+
+// ValidateCheckpointName reports whether name can be used for a checkpoint.
+func ValidateCheckpointName(name string) error {
+	if !checkpointNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid checkpoint name %q: use up to 64 letters, digits, '.', '-', or '_'", name)
+	}
+	return nil
+}
+
+// SaveCheckpoint stores checkpoint for the session, replacing any checkpoint with the same name.
+func (s *Store) SaveCheckpoint(sessionID string, checkpoint Checkpoint) error {
+	if sessionID == "" {
+		return errors.New("session id required")
+	}
+	if err := ValidateCheckpointName(checkpoint.Name); err != nil {
+		return err
+	}
+	dir := s.CheckpointsDir(sessionID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create checkpoints dir: %w", err)
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	file, err := os.CreateTemp(dir, ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("create checkpoint: %w", err)
+	}
+	_, writeErr := file.Write(data)
+	closeErr := file.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		_ = os.Remove(file.Name())
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(file.Name(), filepath.Join(dir, checkpoint.Name+".json")); err != nil {
+		_ = os.Remove(file.Name())
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	_, err = s.AppendMetadata(sessionID, MetadataEvent{Type: MetadataCheckpointCreated, Checkpoint: checkpoint.Name})
+	return err
+}
+
+// LoadCheckpoint reads a session's checkpoint by name. A missing checkpoint,
+// or one whose creation was undone, returns an error matching fs.ErrNotExist.
+func (s *Store) LoadCheckpoint(sessionID string, name string) (Checkpoint, error) {
+	if err := ValidateCheckpointName(name); err != nil {
+		return Checkpoint{}, err
+	}
+	if metadata, err := s.LoadMetadata(sessionID); err == nil && slices.Contains(metadata.WithdrawnCheckpoints, name) {
+		return Checkpoint{}, fmt.Errorf("checkpoint %s: %w", name, fs.ErrNotExist)
+	}
+	return readCheckpoint(filepath.Join(s.CheckpointsDir(sessionID), name+".json"))
+}
+
+// ListCheckpoints returns a session's checkpoints, oldest first, leaving out
+// checkpoints whose creation was undone.
+func (s *Store) ListCheckpoints(sessionID string) ([]Checkpoint, error) {
+	entries, err := os.ReadDir(s.CheckpointsDir(sessionID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list checkpoints: %w", err)
+	}
+	metadata, err := s.LoadMetadata(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var checkpoints []Checkpoint
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		if slices.Contains(metadata.WithdrawnCheckpoints, strings.TrimSuffix(name, ".json")) {
+			continue
+		}
+		checkpoint, err := readCheckpoint(filepath.Join(s.CheckpointsDir(sessionID), name))
+		if err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	sort.SliceStable(checkpoints, func(i, j int) bool {
+		return checkpoints[i].CreatedAt.Before(checkpoints[j].CreatedAt)
+	})
+	return checkpoints, nil
+}
+
+// readCheckpoint decodes one checkpoint file.
+func readCheckpoint(path string) (Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return Checkpoint{}, fmt.Errorf("read checkpoint %s: %w", filepath.Base(path), err)
+	}
+	return checkpoint, nil
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSaveAndListCheckpoints verifies checkpoints round-trip, replace by name, and list oldest first.
+func TestSaveAndListCheckpoints(testingHandle *testing.T) {
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	checkpoints, err := store.ListCheckpoints("s1")
+	testutil.RequireNoError(testingHandle, err, "list missing dir")
+	testutil.RequireEqual(testingHandle, len(checkpoints), 0, "no checkpoints yet")
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []llm.Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "hi"}}
+	testutil.RequireNoError(testingHandle, store.SaveCheckpoint("s1", Checkpoint{Name: "later", CreatedAt: start.Add(time.Hour)}), "save later")
+	testutil.RequireNoError(testingHandle, store.SaveCheckpoint("s1", Checkpoint{Name: "before-refactor", CreatedAt: start, Tree: "old"}), "save first")
+	testutil.RequireNoError(testingHandle, store.SaveCheckpoint("s1", Checkpoint{Name: "before-refactor", CreatedAt: start, Messages: messages, WorkTree: "/repo", Tree: "abc"}), "replace first")
+
+	loaded, err := store.LoadCheckpoint("s1", "before-refactor")
+	testutil.RequireNoError(testingHandle, err, "load")
+	testutil.RequireEqual(testingHandle, loaded.Tree, "abc", "replaced by name")
+	testutil.RequireEqual(testingHandle, loaded.Messages, messages, "messages")
+	info, err := os.Stat(filepath.Join(store.CheckpointsDir("s1"), "before-refactor.json"))
+	testutil.RequireNoError(testingHandle, err, "stat checkpoint")
+	testutil.RequireEqual(testingHandle, info.Mode().Perm(), os.FileMode(0o600), "checkpoint mode")
+
+	checkpoints, err = store.ListCheckpoints("s1")
+	testutil.RequireNoError(testingHandle, err, "list")
+	testutil.RequireEqual(testingHandle, len(checkpoints), 2, "two checkpoints")
+	testutil.RequireEqual(testingHandle, checkpoints[0].Name, "before-refactor", "oldest first")
+
+	_, err = store.LoadCheckpoint("s1", "missing")
+	testutil.RequireTrue(testingHandle, errors.Is(err, fs.ErrNotExist), "missing checkpoint")
+	for _, name := range []string{"", "../escape", ".hidden", "has space"} {
+		testutil.RequireTrue(testingHandle, store.SaveCheckpoint("s1", Checkpoint{Name: name}) != nil, "invalid name "+name)
+	}
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// forkEventType marks the record that opens a forked session's log.
+const forkEventType = "fork"
+
+// forkEventMarker identifies fork records without decoding JSON.
+var forkEventMarker = []byte(`"type":"fork"`)
+
+// forkEvent records where a forked session branched off its parent.
+type forkEvent struct {
+	// Type is always forkEventType.
+	Type string `json:"type"`
+	// From is the parent session id.
+	From string `json:"from"`
+	// Messages counts the messages the fork started with.
+	Messages int `json:"messages"`
+	// At is when the fork was made.
+	At time.Time `json:"at"`
+}
+
+// messageEvent is a conversation message as stored in a session log.
+type messageEvent struct {
+	Type    string      `json:"type"`
+	Message llm.Message `json:"message"`
+}
+
+// ForkSession starts toSessionID as a branch of fromSessionID holding messages,
+// typically the parent's history up to the fork point. The log opens with a
+// fork record naming the parent, and the parent's session state (metadata log,
+// checkpoints, UI state) is copied as CloneSession does. The parent is left
+// untouched, and a system prompt in messages is not stored.
+func (s *Store) ForkSession(fromSessionID string, toSessionID string, messages []llm.Message) error {
+	if fromSessionID == "" || toSessionID == "" {
+		return errors.New("session id required")
+	}
+	if fromSessionID == toSessionID {
+		return errors.New("a fork needs a new session id")
+	}
+	targetPath := s.SessionPath(toSessionID)
+	if _, err := os.Stat(targetPath); err == nil {
+		return fmt.Errorf("session %s already exists", toSessionID)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read session file: %w", err)
+	}
+
+	fork := forkEvent{Type: forkEventType, From: fromSessionID, At: time.Now().UTC()}
+	var records []any
+	for _, message := range messages {
+		if message.Role != "system" {
+			records = append(records, messageEvent{Type: "message", Message: message})
+		}
+	}
+	fork.Messages = len(records)
+	var log bytes.Buffer
+	for _, record := range append([]any{fork}, records...) {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("marshal session event: %w", err)
+		}
+		log.Write(append(data, '\n'))
+	}
+
+	if err := s.cloneSessionEnv(fromSessionID, toSessionID); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(targetPath, log.Bytes()); err != nil {
+		return err
+	}
+	// The index is advisory; the next listing rescans the log if this fails.
+	_ = s.updateIndexEntry(toSessionID)
+	return nil
+}
+
+// applyForkEvent records the parent of a forked session.
+func applyForkEvent(meta *SessionMeta, line []byte) {
+	var record forkEvent
+	if json.Unmarshal(line, &record) != nil || record.Type != forkEventType {
+		return
+	}
+	meta.ForkedFrom = record.From
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestForkSessionBranchesHistory verifies a fork holds the given messages,
+// carries the parent's state, names its parent, and leaves the parent alone.
+func TestForkSessionBranchesHistory(testingHandle *testing.T) {
+	// Arrange a parent session with two exchanges and a todo list.
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	appendMessage(testingHandle, store, "parent", "user", "rename the flag")
+	appendMessage(testingHandle, store, "parent", "assistant", "done")
+	appendMessage(testingHandle, store, "parent", "user", "now the docs")
+	todo := filepath.Join(store.BaseDir, "session-env", "parent", "todo.json")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Dir(todo), 0o755), "create state dir")
+	testutil.RequireNoError(testingHandle, os.WriteFile(todo, []byte(`[]`), 0o600), "write todo")
+	messages := []llm.Message{
+		{Role: "system", Content: "prompt"},
+		{Role: "user", Content: "rename the flag"},
+		{Role: "assistant", Content: "done"},
+	}
+
+	// Act
+	err := store.ForkSession("parent", "child", messages)
+	again := store.ForkSession("parent", "child", messages)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "fork")
+	testutil.RequireTrue(testingHandle, again != nil, "existing fork rejected")
+	events, err := store.LoadEvents("child")
+	testutil.RequireNoError(testingHandle, err, "load fork")
+	testutil.RequireEqual(testingHandle, len(events), 3, "fork record and two messages")
+	_, err = os.Stat(filepath.Join(store.BaseDir, "session-env", "child", "todo.json"))
+	testutil.RequireNoError(testingHandle, err, "state copied")
+	metas, err := store.ListSessionMeta(0)
+	testutil.RequireNoError(testingHandle, err, "list sessions")
+	byID := map[string]SessionMeta{}
+	for _, meta := range metas {
+		byID[meta.ID] = meta
+	}
+	testutil.RequireEqual(testingHandle, byID["child"].ForkedFrom, "parent", "parent recorded")
+	testutil.RequireEqual(testingHandle, byID["child"].MessageCount, 2, "fork messages")
+	testutil.RequireEqual(testingHandle, byID["child"].Title, "rename the flag", "fork title")
+	testutil.RequireEqual(testingHandle, byID["parent"].MessageCount, 3, "parent untouched")
+	testutil.RequireEqual(testingHandle, byID["parent"].ForkedFrom, "", "parent is no fork")
+}
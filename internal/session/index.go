@@ -0,0 +1,392 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionMeta summarizes a stored session for listings and the resume picker.
+type SessionMeta struct {
+	// ID is the session identifier (the JSONL file name without extension).
+	ID string `json:"id"`
+	// Title is the first user message with whitespace collapsed, truncated for display.
+	Title string `json:"title,omitempty"`
+	// CreatedAt is when the session was first indexed.
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is the modification time of the session log.
+	UpdatedAt time.Time `json:"updated_at"`
+	// MessageCount counts persisted conversation messages.
+	MessageCount int `json:"message_count"`
+	// Turns counts user prompts.
+	Turns int `json:"turns"`
+	// CostUSD totals the estimated cost of the session's runs.
+	CostUSD float64 `json:"cost_usd,omitempty"`
+	// FilesModified lists the files edit tools targeted, in first-edit order.
+	FilesModified []string `json:"files_modified,omitempty"`
+	// LastTool is the most recently called tool.
+	LastTool string `json:"last_tool,omitempty"`
+	// RenamedTitle is the title set with a title_changed metadata event, kept
+	// here so listings need not read every metadata log. Listings show it in
+	// place of Title.
+	RenamedTitle string `json:"renamed_title,omitempty"`
+	// ForkedFrom is the parent session of a fork made with /fork.
+	ForkedFrom string `json:"forked_from,omitempty"`
+	// Size is the byte offset of the log covered by this entry.
+	// A log larger than Size is scanned incrementally from this offset.
+	Size int64 `json:"size"`
+}
+
+// sessionIndex is the on-disk metadata index keyed by session id.
+type sessionIndex struct {
+	// Version allows the index layout to evolve; mismatches trigger a rebuild.
+	Version int `json:"version"`
+	// Sessions holds metadata for each indexed session.
+	Sessions map[string]SessionMeta `json:"sessions"`
+}
+
+const (
+	// sessionIndexVersion is bumped whenever SessionMeta derivation changes.
+	sessionIndexVersion = 5
+	// sessionTitleLimit caps stored titles in runes.
+	sessionTitleLimit = 80
+	// maxIndexWorkers caps concurrent session scans during listing.
+	maxIndexWorkers = 8
+)
+
+// sessionIndexMu serializes index read-modify-write cycles within the process.
+// Other processes may race; entries are validated against log sizes, so a lost
+// update only costs an incremental rescan.
+var sessionIndexMu sync.Mutex
+
+// messageEventMarker identifies conversation message records without decoding JSON.
+var messageEventMarker = []byte(`"type":"message"`)
+
+// userMessageMarker identifies user messages without decoding JSON. Quotes
+// inside message text are escaped, so the marker only matches the role field.
+var userMessageMarker = []byte(`"role":"user"`)
+
+// titledSessions records, by log path, the sessions whose index entry already
+// has a title, so later prompts do not rewrite the index. Guarded by sessionIndexMu.
+var titledSessions = map[string]bool{}
+
+// indexPath returns the metadata index location (outside sessions/ so it is never listed).
+func (s *Store) indexPath() string {
+	return filepath.Join(s.BaseDir, "session_index.json")
+}
+
+// ListSessionMeta returns metadata for recent sessions sorted by modification time desc.
+// Titles set with a title_changed metadata event replace derived ones. Entries are served from the index when the log has not changed; stale entries are
+// refreshed concurrently with a bounded worker pool and written back to the index.
+// Only sessions missing from the index have their metadata log read.
+func (s *Store) ListSessionMeta(limit int) ([]SessionMeta, error) {
+	entries, err := os.ReadDir(filepath.Join(s.BaseDir, "sessions"))
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, item := range entries {
+		if item.IsDir() || filepath.Ext(item.Name()) != ".jsonl" {
+			continue
+		}
+		info, err := item.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().After(infos[j].ModTime())
+	})
+	if limit > 0 && len(infos) > limit {
+		infos = infos[:limit]
+	}
+
+	sessionIndexMu.Lock()
+	defer sessionIndexMu.Unlock()
+
+	index := s.readIndex()
+	metas := make([]SessionMeta, len(infos))
+	var stale []int
+	for i, info := range infos {
+		id := strings.TrimSuffix(info.Name(), ".jsonl")
+		meta, ok := index.Sessions[id]
+		if ok && meta.Size == info.Size() {
+			meta.UpdatedAt = info.ModTime()
+			metas[i] = meta
+			continue
+		}
+		if !ok {
+			meta = SessionMeta{ID: id, RenamedTitle: s.renamedTitle(id)}
+		}
+		metas[i] = meta
+		stale = append(stale, i)
+	}
+	if len(stale) > 0 {
+		s.refreshStale(metas, infos, stale)
+		for _, i := range stale {
+			index.Sessions[metas[i].ID] = metas[i]
+		}
+		if err := s.writeIndex(index); err != nil {
+			return nil, err
+		}
+	}
+	for i := range metas {
+		if metas[i].RenamedTitle != "" {
+			metas[i].Title = metas[i].RenamedTitle
+		}
+	}
+	return metas, nil
+}
+
+// renamedTitle reads the title_changed title from a session's metadata log,
+// or returns "" when the session was never renamed.
+func (s *Store) renamedTitle(sessionID string) string {
+	if _, err := os.Stat(s.MetadataPath(sessionID)); err != nil {
+		return ""
+	}
+	metadata, err := s.LoadMetadata(sessionID)
+	if err != nil {
+		return ""
+	}
+	return metadata.Title
+}
+
+// indexRenamedTitle records a session's current renamed title in its index entry.
+func (s *Store) indexRenamedTitle(sessionID string, title string) error {
+	sessionIndexMu.Lock()
+	defer sessionIndexMu.Unlock()
+
+	index := s.readIndex()
+	meta, ok := index.Sessions[sessionID]
+	if !ok {
+		meta = SessionMeta{ID: sessionID}
+	}
+	meta.RenamedTitle = title
+	meta, err := scanSessionMeta(s.SessionPath(sessionID), meta)
+	if err != nil {
+		return err
+	}
+	index.Sessions[sessionID] = meta
+	return s.writeIndex(index)
+}
+
+// refreshStale rescans stale entries in place using a bounded worker pool.
+// Scan failures leave the previous metadata so one unreadable log does not fail listing.
+func (s *Store) refreshStale(metas []SessionMeta, infos []os.FileInfo, stale []int) {
+	workers := runtime.NumCPU()
+	if workers > maxIndexWorkers {
+		workers = maxIndexWorkers
+	}
+	if workers > len(stale) {
+		workers = len(stale)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				meta, err := scanSessionMeta(s.SessionPath(metas[i].ID), metas[i])
+				if err != nil {
+					continue
+				}
+				meta.UpdatedAt = infos[i].ModTime()
+				metas[i] = meta
+			}
+		}()
+	}
+	for _, i := range stale {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// updateIndexEntry brings a single session's index entry up to date.
+// Only the bytes appended since the last indexed offset are scanned.
+func (s *Store) updateIndexEntry(sessionID string) error {
+	sessionIndexMu.Lock()
+	defer sessionIndexMu.Unlock()
+
+	index := s.readIndex()
+	meta, ok := index.Sessions[sessionID]
+	if !ok {
+		meta = SessionMeta{ID: sessionID, RenamedTitle: s.renamedTitle(sessionID)}
+	}
+	meta, err := scanSessionMeta(s.SessionPath(sessionID), meta)
+	if err != nil {
+		return err
+	}
+	index.Sessions[sessionID] = meta
+	titledSessions[s.SessionPath(sessionID)] = meta.Title != ""
+	return s.writeIndex(index)
+}
+
+// awaitsIndexedTitle reports whether data is a user message for a session
+// whose index entry has no title yet, so the prompt should be indexed now.
+func (s *Store) awaitsIndexedTitle(sessionID string, data []byte) bool {
+	if !bytes.Contains(data, userMessageMarker) {
+		return false
+	}
+	sessionIndexMu.Lock()
+	defer sessionIndexMu.Unlock()
+	return !titledSessions[s.SessionPath(sessionID)]
+}
+
+// readIndex loads the index, returning an empty one when missing, corrupt, or outdated.
+func (s *Store) readIndex() sessionIndex {
+	empty := sessionIndex{Version: sessionIndexVersion, Sessions: map[string]SessionMeta{}}
+	raw, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return empty
+	}
+	var index sessionIndex
+	if err := json.Unmarshal(raw, &index); err != nil || index.Version != sessionIndexVersion || index.Sessions == nil {
+		return empty
+	}
+	return index
+}
+
+// writeIndex atomically replaces the index file.
+func (s *Store) writeIndex(index sessionIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("marshal session index: %w", err)
+	}
+	path := s.indexPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create index dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".session_index-*.json")
+	if err != nil {
+		return fmt.Errorf("create session index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write session index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write session index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace session index: %w", err)
+	}
+	return nil
+}
+
+// scanSessionMeta updates meta with events appended after meta.Size.
+// A log that shrank (rewritten or truncated) is rescanned from the start. A trailing
+// partial line is left for the next scan so in-flight writes are never half-counted.
+func scanSessionMeta(path string, meta SessionMeta) (SessionMeta, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return meta, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return meta, err
+	}
+	if info.Size() < meta.Size {
+		meta = SessionMeta{ID: meta.ID, CreatedAt: meta.CreatedAt, RenamedTitle: meta.RenamedTitle}
+	}
+	if meta.CreatedAt.IsZero() {
+		meta.CreatedAt = info.ModTime()
+	}
+	meta.UpdatedAt = info.ModTime()
+	if _, err := file.Seek(meta.Size, io.SeekStart); err != nil {
+		return meta, err
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	offset := meta.Size
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return meta, err
+		}
+		offset += int64(len(line))
+		applyMetaEvent(&meta, line)
+	}
+	meta.Size = offset
+	return meta, nil
+}
+
+// applyMetaEvent folds a single JSONL event into session metadata.
+func applyMetaEvent(meta *SessionMeta, line []byte) {
+	if !bytes.Contains(line, messageEventMarker) {
+		if bytes.Contains(line, forkEventMarker) {
+			applyForkEvent(meta, line)
+			return
+		}
+		if bytes.Contains(line, tombstoneEventMarker) {
+			applyTombstoneEvent(meta, line)
+			return
+		}
+		applyStatsEvent(meta, line)
+		return
+	}
+	var record struct {
+		Type    string `json:"type"`
+		Message struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(line, &record); err != nil || record.Type != "message" {
+		return
+	}
+	meta.MessageCount++
+	if record.Message.Role != "user" {
+		return
+	}
+	meta.Turns++
+	if meta.Title == "" {
+		meta.Title = sessionTitle(record.Message.Content)
+	}
+}
+
+// sessionTitle derives a one-line title from message content (string or text blocks).
+func sessionTitle(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err != nil {
+		var blocks []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(content, &blocks); err != nil {
+			return ""
+		}
+		for _, block := range blocks {
+			if block.Type == "text" && strings.TrimSpace(block.Text) != "" {
+				text = block.Text
+				break
+			}
+		}
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) > sessionTitleLimit {
+		return string(runes[:sessionTitleLimit-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,122 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// appendMessage writes a conversation message event like persistSession does.
+func appendMessage(testingHandle *testing.T, store *Store, sessionID string, role string, content string) {
+	event := map[string]any{"type": "message", "message": map[string]any{"role": role, "content": content}}
+	testutil.RequireNoError(testingHandle, store.AppendEvent(sessionID, event), "append message")
+}
+
+// TestAppendEventUpdatesIndex verifies message writes only rewrite the index
+// when a session is created or titled, and CloseSession catches it up.
+func TestAppendEventUpdatesIndex(testingHandle *testing.T) {
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	appendMessage(testingHandle, store, "s1", "system", "be helpful")
+	appendMessage(testingHandle, store, "s1", "user", "  fix the\nflaky   test ")
+	testutil.RequireNoError(testingHandle, store.AppendEvent("s1", map[string]any{"type": "tool_call"}), "append tool event")
+	appendMessage(testingHandle, store, "s1", "assistant", "done")
+	appendMessage(testingHandle, store, "s1", "user", "and the docs")
+
+	titled := store.readIndex().Sessions["s1"]
+	testutil.RequireEqual(testingHandle, titled.Title, "fix the flaky test", "title")
+	testutil.RequireEqual(testingHandle, titled.MessageCount, 2, "later messages do not rewrite the index")
+
+	testutil.RequireNoError(testingHandle, store.CloseSession("s1"), "close session")
+	closed := store.readIndex().Sessions["s1"]
+	testutil.RequireEqual(testingHandle, closed.MessageCount, 4, "message count")
+	info, err := os.Stat(store.SessionPath("s1"))
+	testutil.RequireNoError(testingHandle, err, "stat session")
+	testutil.RequireEqual(testingHandle, closed.Size, info.Size(), "indexed size")
+}
+
+// TestListSessionMetaUsesIndexAndRefreshesStale verifies fresh entries are reused and stale ones rescanned.
+func TestListSessionMetaUsesIndexAndRefreshesStale(testingHandle *testing.T) {
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	for index := 0; index < 20; index++ {
+		sessionID := fmt.Sprintf("s%02d", index)
+		appendMessage(testingHandle, store, sessionID, "user", "prompt "+sessionID)
+		// Spread modification times so ordering is deterministic.
+		modTime := time.Unix(1_700_000_000+int64(index), 0)
+		testutil.RequireNoError(testingHandle, os.Chtimes(store.SessionPath(sessionID), modTime, modTime), "set mtime")
+	}
+
+	// Tamper with a fresh entry to prove it is served from the index.
+	index := store.readIndex()
+	fresh := index.Sessions["s19"]
+	fresh.Title = "from index"
+	index.Sessions["s19"] = fresh
+	// Drop another entry entirely so it must be rebuilt by a worker.
+	delete(index.Sessions, "s18")
+	testutil.RequireNoError(testingHandle, store.writeIndex(index), "write index")
+
+	// Append a message without going through AppendEvent so the entry goes stale.
+	file, err := os.OpenFile(store.SessionPath("s17"), os.O_APPEND|os.O_WRONLY, 0o600)
+	testutil.RequireNoError(testingHandle, err, "open session")
+	_, err = file.WriteString(`{"message":{"content":"more","role":"assistant"},"type":"message"}` + "\n")
+	testutil.RequireNoError(testingHandle, err, "write raw event")
+	testutil.RequireNoError(testingHandle, file.Close(), "close session")
+	modTime := time.Unix(1_700_000_017, 0)
+	testutil.RequireNoError(testingHandle, os.Chtimes(store.SessionPath("s17"), modTime, modTime), "set mtime")
+
+	metas, err := store.ListSessionMeta(3)
+	testutil.RequireNoError(testingHandle, err, "list sessions")
+
+	testutil.RequireEqual(testingHandle, len(metas), 3, "limit")
+	testutil.RequireEqual(testingHandle, metas[0].Title, "from index", "fresh entry reused")
+	testutil.RequireEqual(testingHandle, metas[1].Title, "prompt s18", "missing entry rebuilt")
+	testutil.RequireEqual(testingHandle, metas[2].MessageCount, 2, "stale entry rescanned")
+
+	// The refreshed entries are persisted for the next listing.
+	persisted := store.readIndex()
+	testutil.RequireEqual(testingHandle, persisted.Sessions["s18"].Title, "prompt s18", "rebuilt entry persisted")
+	testutil.RequireEqual(testingHandle, persisted.Sessions["s17"].MessageCount, 2, "rescanned entry persisted")
+}
+
+// TestScanSessionMetaSkipsPartialLine verifies in-flight writes are not counted.
+func TestScanSessionMetaSkipsPartialLine(testingHandle *testing.T) {
+	path := filepath.Join(testingHandle.TempDir(), "s.jsonl")
+	complete := `{"message":{"content":"hi","role":"user"},"type":"message"}` + "\n"
+	testutil.RequireNoError(testingHandle, os.WriteFile(path, []byte(complete+`{"type":"mess`), 0o600), "write log")
+
+	meta, err := scanSessionMeta(path, SessionMeta{ID: "s"})
+	testutil.RequireNoError(testingHandle, err, "scan")
+
+	testutil.RequireEqual(testingHandle, meta.MessageCount, 1, "message count")
+	testutil.RequireEqual(testingHandle, meta.Size, int64(len(complete)), "size stops at last newline")
+}
+
+// TestListSessionMetaStats verifies turns, cost, edited files, and the last
+// tool are derived from the log, including events appended after indexing.
+func TestListSessionMetaStats(testingHandle *testing.T) {
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	appendMessage(testingHandle, store, "s1", "user", "rename the flag")
+	edit := map[string]any{"type": "tool_call", "tool_name": "Edit", "arguments": map[string]any{"file_path": "main.go"}}
+	testutil.RequireNoError(testingHandle, store.AppendEvent("s1", edit), "append edit")
+	testutil.RequireNoError(testingHandle, store.AppendEvent("s1", edit), "append repeated edit")
+	appendMessage(testingHandle, store, "s1", "assistant", "done")
+	testutil.RequireNoError(testingHandle, store.AppendCost("s1", 0.25), "append cost")
+	testutil.RequireNoError(testingHandle, store.AppendCost("s1", 0), "skip unpriced cost")
+	appendMessage(testingHandle, store, "s1", "user", "now the docs")
+	write := map[string]any{"type": "tool_call", "tool_name": "Write", "arguments": map[string]any{"file_path": "README.md"}}
+	testutil.RequireNoError(testingHandle, store.AppendEvent("s1", write), "append write")
+	testutil.RequireNoError(testingHandle, store.AppendEvent("s1", map[string]any{"type": "tool_call", "tool_name": "Grep"}), "append grep")
+	testutil.RequireNoError(testingHandle, store.AppendCost("s1", 0.5), "append second cost")
+
+	metas, err := store.ListSessionMeta(0)
+	testutil.RequireNoError(testingHandle, err, "list sessions")
+
+	testutil.RequireEqual(testingHandle, len(metas), 1, "sessions")
+	testutil.RequireEqual(testingHandle, metas[0].Turns, 2, "turns")
+	testutil.RequireEqual(testingHandle, metas[0].CostUSD, 0.75, "cost")
+	testutil.RequireEqual(testingHandle, fmt.Sprint(metas[0].FilesModified), "[main.go README.md]", "files modified")
+	testutil.RequireEqual(testingHandle, metas[0].LastTool, "Grep", "last tool")
+}
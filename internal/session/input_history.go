@@ -0,0 +1,126 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// InputHistoryEntry is one persisted TUI input line.
+type InputHistoryEntry struct {
+	// Input is the submitted text; bash-mode entries keep their "!" prefix.
+	Input string `json:"input"`
+	// Time records when the input was submitted.
+	Time time.Time `json:"time"`
+}
+
+// InputHistoryPath returns the per-project input history file.
+func (s *Store) InputHistoryPath(projectHash string) string {
+	return filepath.Join(s.BaseDir, "history", projectHash+".jsonl")
+}
+
+// LoadInputHistory returns up to limit of the most recent inputs, oldest first.
+// A missing file yields an empty history; malformed lines are skipped.
+func (s *Store) LoadInputHistory(projectHash string, limit int) ([]string, error) {
+	entries, err := s.readInputHistory(projectHash)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	inputs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		inputs = append(inputs, entry.Input)
+	}
+	return inputs, nil
+}
+
+// AppendInputHistory records an input and trims the file to the limit.
+// The file is rewritten only once it holds twice the limit so appends stay cheap.
+func (s *Store) AppendInputHistory(projectHash string, input string, limit int) error {
+	if strings.TrimSpace(input) == "" {
+		return nil
+	}
+	path := s.InputHistoryPath(projectHash)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create history dir: %w", err)
+	}
+	line, err := json.Marshal(InputHistoryEntry{Input: input, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	// Prompts can contain secrets, so history is private like session files.
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if limit <= 0 {
+		return nil
+	}
+	entries, err := s.readInputHistory(projectHash)
+	if err != nil || len(entries) <= 2*limit {
+		return err
+	}
+	return s.rewriteInputHistory(path, entries[len(entries)-limit:])
+}
+
+// readInputHistory parses the history file for a project.
+func (s *Store) readInputHistory(projectHash string) ([]InputHistoryEntry, error) {
+	file, err := os.Open(s.InputHistoryPath(projectHash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	var entries []InputHistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry InputHistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || entry.Input == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// rewriteInputHistory replaces the history file atomically with the given entries.
+func (s *Store) rewriteInputHistory(path string, entries []InputHistoryEntry) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), ".history-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	writer := bufio.NewWriter(temp)
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			temp.Close()
+			return err
+		}
+		writer.Write(append(line, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
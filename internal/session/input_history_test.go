@@ -0,0 +1,34 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestInputHistoryAppendLoadAndTrim verifies history persists per project and stays bounded.
+func TestInputHistoryAppendLoadAndTrim(testingHandle *testing.T) {
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	for index := 0; index < 7; index++ {
+		testutil.RequireNoError(testingHandle, store.AppendInputHistory("project", fmt.Sprintf("prompt %d", index), 3), "append history")
+	}
+	testutil.RequireNoError(testingHandle, store.AppendInputHistory("project", "   ", 3), "append blank history")
+
+	inputs, err := store.LoadInputHistory("project", 3)
+	testutil.RequireNoError(testingHandle, err, "load history")
+	testutil.RequireEqual(testingHandle, fmt.Sprint(inputs), "[prompt 4 prompt 5 prompt 6]", "recent inputs")
+
+	entries, err := store.readInputHistory("project")
+	testutil.RequireNoError(testingHandle, err, "read history")
+	testutil.RequireTrue(testingHandle, len(entries) <= 6, "file trimmed to twice the limit")
+
+	info, err := os.Stat(store.InputHistoryPath("project"))
+	testutil.RequireNoError(testingHandle, err, "stat history")
+	testutil.RequireEqual(testingHandle, info.Mode().Perm(), os.FileMode(0o600), "history mode")
+
+	other, err := store.LoadInputHistory("other", 3)
+	testutil.RequireNoError(testingHandle, err, "load other project")
+	testutil.RequireEqual(testingHandle, len(other), 0, "projects do not share history")
+}
@@ -0,0 +1,414 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Metadata event types. Every change to a session's mutable metadata is
+// appended to its metadata log as one of these and never rewritten in place.
+const (
+	// MetadataTitleChanged renames the session.
+	MetadataTitleChanged = "title_changed"
+	// MetadataModeChanged switches the session mode (MetadataModePlan or MetadataModeDefault).
+	MetadataModeChanged = "mode_changed"
+	// MetadataTodoUpdated replaces the todo list.
+	MetadataTodoUpdated = "todo_updated"
+	// MetadataCheckpointCreated records a saved checkpoint.
+	MetadataCheckpointCreated = "checkpoint_created"
+	// MetadataTaskUpdated records a subagent task lifecycle change.
+	MetadataTaskUpdated = "task_updated"
+	// MetadataUndone reverts the earlier event numbered Undoes.
+	MetadataUndone = "undone"
+	// MetadataRunStarted records how a CLI invocation ran the session.
+	MetadataRunStarted = "run_started"
+)
+
+const (
+	// MetadataModePlan restricts the session to read-only planning.
+	MetadataModePlan = "plan"
+	// MetadataModeDefault is the normal mode.
+	MetadataModeDefault = "default"
+)
+
+// Pre-event-log side files, read when a session has no metadata log yet and
+// folded into it on the first append.
+const (
+	legacyPlanModeFile = "plan_mode"
+	legacyTodoFile     = "todo.json"
+	legacyTaskLogFile  = "tasks.jsonl"
+)
+
+// undoableMetadata lists the event types UndoMetadata may revert. Task updates
+// record work that already happened, so they are history only.
+var undoableMetadata = map[string]bool{
+	MetadataTitleChanged:      true,
+	MetadataModeChanged:       true,
+	MetadataTodoUpdated:       true,
+	MetadataCheckpointCreated: true,
+}
+
+// metadataMu serializes metadata appends within the process so sequence numbers
+// stay unique; concurrent processes may repeat a number, which only affects undo.
+var metadataMu sync.Mutex
+
+// metadataLog is the parsed prefix of a metadata log: the events in its first
+// size bytes. Logs are append-only, so later reads only parse what was added.
+type metadataLog struct {
+	// file identifies the log the events came from.
+	file os.FileInfo
+	// size is the byte offset the cached events cover.
+	size int64
+	// events holds the parsed events in append order.
+	events []MetadataEvent
+}
+
+// metadataCache keeps parsed metadata logs by path so appends and reads do not
+// re-parse the whole log. A log that was replaced (as redaction does) or shrank
+// is reread from the start.
+var metadataCache = struct {
+	mu   sync.Mutex
+	logs map[string]*metadataLog
+}{logs: map[string]*metadataLog{}}
+
+// MetadataEvent is one entry of a session's metadata log.
+type MetadataEvent struct {
+	// Seq numbers events from 1 in append order.
+	Seq int `json:"seq"`
+	// Type is one of the Metadata* event types.
+	Type string `json:"type"`
+	// At is when the event was appended.
+	At time.Time `json:"at"`
+	// Title is the new title for title_changed.
+	Title string `json:"title,omitempty"`
+	// Mode is the new mode for mode_changed.
+	Mode string `json:"mode,omitempty"`
+	// Todos is the new todo list for todo_updated.
+	Todos json.RawMessage `json:"todos,omitempty"`
+	// Checkpoint names the checkpoint for checkpoint_created.
+	Checkpoint string `json:"checkpoint,omitempty"`
+	// TaskID and TaskStatus identify the task and its new status for task_updated.
+	TaskID     string `json:"task_id,omitempty"`
+	TaskStatus string `json:"task_status,omitempty"`
+	// Task is the tool's full task record for task_updated.
+	Task json.RawMessage `json:"task,omitempty"`
+	// Undoes is the Seq reverted by an undone event.
+	Undoes int `json:"undoes,omitempty"`
+	// Run describes the invocation for run_started.
+	Run *RunInfo `json:"run,omitempty"`
+}
+
+// RunInfo is what a past result depended on: how the CLI was invoked, which
+// model and gateway it talked to, and the state of the workspace.
+type RunInfo struct {
+	// Version is the OpenClaude version.
+	Version string `json:"version"`
+	// Flags are the command-line flags that were set, as --name=value.
+	Flags []string `json:"flags,omitempty"`
+	// Mode is "print" or "interactive".
+	Mode string `json:"mode"`
+	// Model is the resolved model id.
+	Model string `json:"model"`
+	// BaseURLHash identifies the provider base URL without revealing it.
+	BaseURLHash string `json:"base_url_hash,omitempty"`
+	// CWD is the working directory.
+	CWD string `json:"cwd"`
+	// GitCommit is the workspace HEAD commit, when it is a git work tree.
+	GitCommit string `json:"git_commit,omitempty"`
+	// GitDirty counts uncommitted files at the start of the run.
+	GitDirty int `json:"git_dirty,omitempty"`
+	// OS and Arch are the platform the binary ran on.
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+// SessionMetadata is the current metadata, rebuilt by folding the event log.
+type SessionMetadata struct {
+	// Title overrides the title derived from the first prompt.
+	Title string `json:"title,omitempty"`
+	// Mode is the session mode; empty means MetadataModeDefault.
+	Mode string `json:"mode,omitempty"`
+	// Todos is the latest todo list.
+	Todos json.RawMessage `json:"todos,omitempty"`
+	// Checkpoints names the live checkpoints in creation order.
+	Checkpoints []string `json:"checkpoints,omitempty"`
+	// WithdrawnCheckpoints names checkpoints whose creation was undone; their
+	// snapshots stay on disk but are no longer listed or restorable.
+	WithdrawnCheckpoints []string `json:"withdrawn_checkpoints,omitempty"`
+	// Tasks maps task ids to their latest status.
+	Tasks map[string]string `json:"tasks,omitempty"`
+	// Runs lists every recorded invocation, oldest first.
+	Runs []RunInfo `json:"runs,omitempty"`
+	// LastSeq is the highest event number seen.
+	LastSeq int `json:"last_seq"`
+}
+
+// PlanMode reports whether the session is in plan mode.
+func (m SessionMetadata) PlanMode() bool {
+	return m.Mode == MetadataModePlan
+}
+
+// MetadataPath returns a session's metadata event log.
+func (s *Store) MetadataPath(sessionID string) string {
+	return filepath.Join(s.sessionEnvDir(sessionID), "metadata.jsonl")
+}
+
+// AppendMetadata numbers, timestamps, and appends a metadata event, returning
+// it as stored. A session still using legacy side files is migrated first.
+func (s *Store) AppendMetadata(sessionID string, event MetadataEvent) (MetadataEvent, error) {
+	if sessionID == "" {
+		return event, errors.New("session id required")
+	}
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+	return s.appendMetadataLocked(sessionID, event)
+}
+
+// appendMetadataLocked implements AppendMetadata; the caller holds metadataMu.
+func (s *Store) appendMetadataLocked(sessionID string, event MetadataEvent) (MetadataEvent, error) {
+	events, err := s.LoadMetadataEvents(sessionID)
+	if err != nil {
+		return event, err
+	}
+	var pending []MetadataEvent
+	if _, err := os.Stat(s.MetadataPath(sessionID)); errors.Is(err, fs.ErrNotExist) {
+		pending = events
+	}
+	last := 0
+	if len(events) > 0 {
+		last = events[len(events)-1].Seq
+	}
+	event.Seq = last + 1
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+	if err := s.writeMetadataEvents(sessionID, append(pending, event)); err != nil {
+		return event, err
+	}
+	if pending != nil {
+		s.removeLegacyMetadata(sessionID)
+	}
+	if event.Type == MetadataTitleChanged || event.Type == MetadataUndone {
+		// The index is advisory, so a failed update only leaves the old title listed.
+		_ = s.indexRenamedTitle(sessionID, ReduceMetadata(append(events, event)).Title)
+	}
+	return event, nil
+}
+
+// LoadMetadataEvents reads a session's metadata log in order. A session without
+// a log yields events synthesized from its legacy side files, if any. Only the
+// bytes appended since the previous read are parsed.
+func (s *Store) LoadMetadataEvents(sessionID string) ([]MetadataEvent, error) {
+	path := s.MetadataPath(sessionID)
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		metadataCache.mu.Lock()
+		delete(metadataCache.logs, path)
+		metadataCache.mu.Unlock()
+		return s.legacyMetadataEvents(sessionID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read session metadata: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("read session metadata: %w", err)
+	}
+
+	metadataCache.mu.Lock()
+	defer metadataCache.mu.Unlock()
+	cached := metadataCache.logs[path]
+	if cached == nil || !os.SameFile(cached.file, info) || info.Size() < cached.size {
+		cached = &metadataLog{file: info}
+	}
+	if info.Size() > cached.size {
+		if _, err := file.Seek(cached.size, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("read session metadata: %w", err)
+		}
+		next := &metadataLog{file: info, size: cached.size, events: slices.Clip(cached.events)}
+		reader := bufio.NewReaderSize(file, 64*1024)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if errors.Is(err, io.EOF) {
+				// A torn final line is left for the next read; if another
+				// append lands on it the joined line fails to decode and is skipped.
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("read session metadata: %w", err)
+			}
+			next.size += int64(len(line))
+			var event MetadataEvent
+			if err := json.Unmarshal(line, &event); err != nil || event.Type == "" {
+				continue
+			}
+			next.events = append(next.events, event)
+		}
+		cached = next
+	}
+	metadataCache.logs[path] = cached
+	return slices.Clone(cached.events), nil
+}
+
+// LoadMetadata rebuilds a session's current metadata from its event log.
+func (s *Store) LoadMetadata(sessionID string) (SessionMetadata, error) {
+	events, err := s.LoadMetadataEvents(sessionID)
+	if err != nil {
+		return SessionMetadata{}, err
+	}
+	return ReduceMetadata(events), nil
+}
+
+// UndoMetadata reverts the most recent undoable event that is still in effect
+// by appending an undone event, and returns the event it reverted.
+func (s *Store) UndoMetadata(sessionID string) (MetadataEvent, error) {
+	if sessionID == "" {
+		return MetadataEvent{}, errors.New("session id required")
+	}
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	events, err := s.LoadMetadataEvents(sessionID)
+	if err != nil {
+		return MetadataEvent{}, err
+	}
+	undone := undoneSeqs(events)
+	for index := len(events) - 1; index >= 0; index-- {
+		target := events[index]
+		if !undoableMetadata[target.Type] || undone[target.Seq] {
+			continue
+		}
+		if _, err := s.appendMetadataLocked(sessionID, MetadataEvent{Type: MetadataUndone, Undoes: target.Seq}); err != nil {
+			return MetadataEvent{}, err
+		}
+		return target, nil
+	}
+	return MetadataEvent{}, errors.New("no metadata changes to undo")
+}
+
+// ReduceMetadata folds events into the current metadata, skipping undone ones.
+func ReduceMetadata(events []MetadataEvent) SessionMetadata {
+	var state SessionMetadata
+	undone := undoneSeqs(events)
+	for _, event := range events {
+		state.LastSeq = max(state.LastSeq, event.Seq)
+		if undone[event.Seq] {
+			if event.Type == MetadataCheckpointCreated && !slices.Contains(state.WithdrawnCheckpoints, event.Checkpoint) {
+				state.WithdrawnCheckpoints = append(state.WithdrawnCheckpoints, event.Checkpoint)
+			}
+			continue
+		}
+		switch event.Type {
+		case MetadataTitleChanged:
+			state.Title = event.Title
+		case MetadataModeChanged:
+			state.Mode = event.Mode
+		case MetadataTodoUpdated:
+			state.Todos = event.Todos
+		case MetadataCheckpointCreated:
+			state.Checkpoints = append(slices.DeleteFunc(state.Checkpoints, func(name string) bool { return name == event.Checkpoint }), event.Checkpoint)
+			state.WithdrawnCheckpoints = slices.DeleteFunc(state.WithdrawnCheckpoints, func(name string) bool { return name == event.Checkpoint })
+		case MetadataTaskUpdated:
+			if state.Tasks == nil {
+				state.Tasks = map[string]string{}
+			}
+			state.Tasks[event.TaskID] = event.TaskStatus
+		case MetadataRunStarted:
+			if event.Run != nil {
+				state.Runs = append(state.Runs, *event.Run)
+			}
+		}
+	}
+	return state
+}
+
+// undoneSeqs returns the event numbers reverted by undone events.
+func undoneSeqs(events []MetadataEvent) map[int]bool {
+	undone := map[int]bool{}
+	for _, event := range events {
+		if event.Type == MetadataUndone {
+			undone[event.Undoes] = true
+		}
+	}
+	return undone
+}
+
+// writeMetadataEvents appends events to the metadata log in one write.
+func (s *Store) writeMetadataEvents(sessionID string, events []MetadataEvent) error {
+	var data []byte
+	for _, event := range events {
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("marshal session metadata: %w", err)
+		}
+		data = append(append(data, encoded...), '\n')
+	}
+	path := s.MetadataPath(sessionID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create session env dir: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open session metadata: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("write session metadata: %w", err)
+	}
+	return nil
+}
+
+// legacyMetadataEvents converts the plan_mode marker, todo.json, and tasks.jsonl
+// written by older versions into equivalent events.
+func (s *Store) legacyMetadataEvents(sessionID string) []MetadataEvent {
+	dir := s.sessionEnvDir(sessionID)
+	var events []MetadataEvent
+	add := func(event MetadataEvent) {
+		event.Seq = len(events) + 1
+		events = append(events, event)
+	}
+	if info, err := os.Stat(filepath.Join(dir, legacyPlanModeFile)); err == nil {
+		add(MetadataEvent{Type: MetadataModeChanged, At: info.ModTime().UTC(), Mode: MetadataModePlan})
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, legacyTodoFile)); err == nil {
+		var payload struct {
+			Todos json.RawMessage `json:"todos"`
+		}
+		if json.Unmarshal(data, &payload) == nil && len(payload.Todos) > 0 {
+			add(MetadataEvent{Type: MetadataTodoUpdated, Todos: payload.Todos})
+		}
+	}
+	if file, err := os.Open(filepath.Join(dir, legacyTaskLogFile)); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			var record struct {
+				ID     string `json:"id"`
+				Status string `json:"status"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &record) != nil || record.ID == "" {
+				continue
+			}
+			add(MetadataEvent{Type: MetadataTaskUpdated, TaskID: record.ID, TaskStatus: record.Status, Task: append(json.RawMessage(nil), scanner.Bytes()...)})
+		}
+	}
+	return events
+}
+
+// removeLegacyMetadata deletes side files once the metadata log holds their contents.
+func (s *Store) removeLegacyMetadata(sessionID string) {
+	for _, name := range []string{legacyPlanModeFile, legacyTodoFile, legacyTaskLogFile} {
+		_ = os.Remove(filepath.Join(s.sessionEnvDir(sessionID), name))
+	}
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestMetadataUndoRebuildsState verifies undone events drop out of the folded
+// state and undo walks back through the history.
+func TestMetadataUndoRebuildsState(testingHandle *testing.T) {
+	// Arrange
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	appendMessage(testingHandle, store, "s1", "user", "fix the parser")
+	for _, event := range []MetadataEvent{
+		{Type: MetadataTitleChanged, Title: "Parser work"},
+		{Type: MetadataModeChanged, Mode: MetadataModePlan},
+		{Type: MetadataTaskUpdated, TaskID: "t1", TaskStatus: "completed", Task: json.RawMessage(`{"id":"t1"}`)},
+		{Type: MetadataTitleChanged, Title: "Parser rewrite"},
+	} {
+		_, err := store.AppendMetadata("s1", event)
+		testutil.RequireNoError(testingHandle, err, "append "+event.Type)
+	}
+
+	// Act
+	renamed, renamedErr := store.LoadMetadata("s1")
+	firstUndo, firstErr := store.UndoMetadata("s1")
+	secondUndo, secondErr := store.UndoMetadata("s1")
+	state, stateErr := store.LoadMetadata("s1")
+	metas, listErr := store.ListSessionMeta(0)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, errors.Join(renamedErr, firstErr, secondErr, stateErr, listErr), "metadata calls")
+	testutil.RequireEqual(testingHandle, renamed.Title, "Parser rewrite", "latest title")
+	testutil.RequireTrue(testingHandle, renamed.PlanMode(), "plan mode on")
+	testutil.RequireEqual(testingHandle, firstUndo.Seq, 4, "first undo reverts the rename")
+	testutil.RequireEqual(testingHandle, secondUndo.Type, MetadataModeChanged, "second undo skips the task update")
+	testutil.RequireEqual(testingHandle, state.Title, "Parser work", "title reverted")
+	testutil.RequireTrue(testingHandle, !state.PlanMode(), "plan mode reverted")
+	testutil.RequireEqual(testingHandle, state.Tasks, map[string]string{"t1": "completed"}, "tasks kept")
+	testutil.RequireEqual(testingHandle, state.LastSeq, 6, "undo events are logged")
+	testutil.RequireEqual(testingHandle, metas[0].Title, "Parser work", "listing uses the renamed title")
+}
+
+// TestMetadataMigratesLegacyFiles verifies sessions written with side files
+// read the same and are folded into the log on the first append.
+func TestMetadataMigratesLegacyFiles(testingHandle *testing.T) {
+	// Arrange
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	env := filepath.Join(store.BaseDir, "session-env", "old")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(env, 0o755), "create state dir")
+	legacy := map[string]string{
+		"plan_mode":   "1",
+		"todo.json":   `{"todos":[{"text":"ship it"}]}`,
+		"tasks.jsonl": `{"type":"task","id":"t1","status":"created"}` + "\n",
+	}
+	for name, content := range legacy {
+		testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(env, name), []byte(content), 0o600), "write "+name)
+	}
+
+	// Act
+	before, beforeErr := store.LoadMetadata("old")
+	_, appendErr := store.AppendMetadata("old", MetadataEvent{Type: MetadataTitleChanged, Title: "Old work"})
+	after, afterErr := store.LoadMetadata("old")
+
+	// Assert
+	testutil.RequireNoError(testingHandle, errors.Join(beforeErr, appendErr, afterErr), "metadata calls")
+	testutil.RequireTrue(testingHandle, before.PlanMode(), "legacy plan marker")
+	testutil.RequireEqual(testingHandle, string(before.Todos), `[{"text":"ship it"}]`, "legacy todos")
+	testutil.RequireEqual(testingHandle, after.Tasks, map[string]string{"t1": "created"}, "legacy tasks migrated")
+	testutil.RequireTrue(testingHandle, after.PlanMode() && after.Title == "Old work", "state after migration")
+	testutil.RequireEqual(testingHandle, after.LastSeq, 4, "legacy events numbered before the new one")
+	for name := range legacy {
+		_, err := os.Stat(filepath.Join(env, name))
+		testutil.RequireTrue(testingHandle, os.IsNotExist(err), name+" removed")
+	}
+}
+
+// TestUndoCheckpointWithdrawsIt verifies an undone checkpoint is hidden until saved again.
+func TestUndoCheckpointWithdrawsIt(testingHandle *testing.T) {
+	// Arrange
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	testutil.RequireNoError(testingHandle, store.SaveCheckpoint("s1", Checkpoint{Name: "start"}), "save checkpoint")
+
+	// Act
+	_, undoErr := store.UndoMetadata("s1")
+	listed, listErr := store.ListCheckpoints("s1")
+	_, loadErr := store.LoadCheckpoint("s1", "start")
+	testutil.RequireNoError(testingHandle, store.SaveCheckpoint("s1", Checkpoint{Name: "start"}), "save again")
+	relisted, relistErr := store.ListCheckpoints("s1")
+
+	// Assert
+	testutil.RequireNoError(testingHandle, errors.Join(undoErr, listErr, relistErr), "checkpoint calls")
+	testutil.RequireEqual(testingHandle, len(listed), 0, "withdrawn checkpoint hidden")
+	testutil.RequireTrue(testingHandle, errors.Is(loadErr, fs.ErrNotExist), "withdrawn checkpoint not restorable")
+	testutil.RequireEqual(testingHandle, len(relisted), 1, "saving again restores it")
+}
+
+// TestListSessionMetaReadsRenamedTitleFromIndex verifies a rename is stored in
+// the index entry, so listing does not read the metadata log.
+func TestListSessionMetaReadsRenamedTitleFromIndex(testingHandle *testing.T) {
+	// Arrange
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	appendMessage(testingHandle, store, "s1", "user", "fix the parser")
+	_, err := store.AppendMetadata("s1", MetadataEvent{Type: MetadataTitleChanged, Title: "Parser work"})
+	testutil.RequireNoError(testingHandle, err, "rename")
+	testutil.RequireNoError(testingHandle, os.Remove(store.MetadataPath("s1")), "remove metadata log")
+
+	// Act
+	metas, err := store.ListSessionMeta(0)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "list sessions")
+	testutil.RequireEqual(testingHandle, metas[0].Title, "Parser work", "renamed title served from the index")
+}
+
+// TestLoadMetadataEventsReadsAppendedAndReplacedLogs verifies the parsed log
+// cache picks up appends made elsewhere and rereads a replaced log.
+func TestLoadMetadataEventsReadsAppendedAndReplacedLogs(testingHandle *testing.T) {
+	// Arrange
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	_, err := store.AppendMetadata("s1", MetadataEvent{Type: MetadataTitleChanged, Title: "First"})
+	testutil.RequireNoError(testingHandle, err, "first rename")
+	file, err := os.OpenFile(store.MetadataPath("s1"), os.O_APPEND|os.O_WRONLY, 0o600)
+	testutil.RequireNoError(testingHandle, err, "open metadata log")
+	_, err = file.WriteString(`{"seq":2,"type":"title_changed","title":"Second"}` + "\n")
+	testutil.RequireNoError(testingHandle, err, "append raw event")
+	testutil.RequireNoError(testingHandle, file.Close(), "close metadata log")
+
+	// Act
+	appended, appendedErr := store.LoadMetadata("s1")
+	replacement := filepath.Join(testingHandle.TempDir(), "metadata.jsonl")
+	testutil.RequireNoError(testingHandle, os.WriteFile(replacement, []byte(`{"seq":1,"type":"title_changed","title":"Replaced title"}`+"\n"), 0o600), "write replacement")
+	testutil.RequireNoError(testingHandle, os.Rename(replacement, store.MetadataPath("s1")), "replace metadata log")
+	replaced, replacedErr := store.LoadMetadata("s1")
+
+	// Assert
+	testutil.RequireNoError(testingHandle, errors.Join(appendedErr, replacedErr), "load metadata")
+	testutil.RequireEqual(testingHandle, appended.Title, "Second", "appended event read")
+	testutil.RequireEqual(testingHandle, replaced.Title, "Replaced title", "replaced log reread")
+	testutil.RequireEqual(testingHandle, replaced.LastSeq, 1, "stale events dropped")
+}
@@ -0,0 +1,144 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PrunedSession describes a session removed (or, in a dry run, selected) by PruneSessions.
+type PrunedSession struct {
+	// ID is the session identifier.
+	ID string `json:"id"`
+	// UpdatedAt is the modification time of the session log.
+	UpdatedAt time.Time `json:"updated_at"`
+	// Bytes is the size of the log plus the session's state directory.
+	Bytes int64 `json:"bytes"`
+}
+
+// PruneReport summarizes a retention pass over the store.
+type PruneReport struct {
+	// Pruned lists the expired sessions, oldest first.
+	Pruned []PrunedSession `json:"pruned"`
+	// PrunedBytes totals Pruned.
+	PrunedBytes int64 `json:"pruned_bytes"`
+	// Kept counts the sessions left in place.
+	Kept int `json:"kept"`
+	// KeptBytes is the size of the kept sessions.
+	KeptBytes int64 `json:"kept_bytes"`
+	// DryRun reports that nothing was deleted.
+	DryRun bool `json:"dry_run"`
+}
+
+// PruneSessions removes sessions whose log was last modified before cutoff:
+// the log, its session-env state, debug dumps, index entry, and any
+// last-session pointer naming it. Sessions listed in keep are never removed.
+// With dryRun the report lists what would be removed without deleting anything.
+// A missing sessions directory yields an empty report.
+func (s *Store) PruneSessions(cutoff time.Time, keep map[string]bool, dryRun bool) (PruneReport, error) {
+	report := PruneReport{DryRun: dryRun}
+	entries, err := os.ReadDir(filepath.Join(s.BaseDir, "sessions"))
+	if errors.Is(err, os.ErrNotExist) {
+		return report, nil
+	}
+	if err != nil {
+		return report, fmt.Errorf("list sessions: %w", err)
+	}
+
+	for _, item := range entries {
+		if item.IsDir() || filepath.Ext(item.Name()) != ".jsonl" {
+			continue
+		}
+		info, err := item.Info()
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(item.Name(), ".jsonl")
+		size := info.Size() + treeSize(s.sessionEnvDir(id))
+		if keep[id] || !info.ModTime().Before(cutoff) {
+			report.Kept++
+			report.KeptBytes += size
+			continue
+		}
+		report.Pruned = append(report.Pruned, PrunedSession{ID: id, UpdatedAt: info.ModTime(), Bytes: size})
+		report.PrunedBytes += size
+	}
+	sort.Slice(report.Pruned, func(i, j int) bool {
+		return report.Pruned[i].UpdatedAt.Before(report.Pruned[j].UpdatedAt)
+	})
+	if dryRun || len(report.Pruned) == 0 {
+		return report, nil
+	}
+
+	removed := map[string]bool{}
+	for _, pruned := range report.Pruned {
+		if err := s.removeSession(pruned.ID); err != nil {
+			return report, err
+		}
+		removed[pruned.ID] = true
+	}
+	s.forgetSessions(removed)
+	return report, nil
+}
+
+// sessionEnvDir returns the per-session state directory.
+func (s *Store) sessionEnvDir(sessionID string) string {
+	return filepath.Join(s.BaseDir, "session-env", sessionID)
+}
+
+// removeSession deletes a session's state and debug dumps, then its log, so an
+// interrupted prune leaves a log that a later pass will pick up again.
+func (s *Store) removeSession(sessionID string) error {
+	if err := os.RemoveAll(s.sessionEnvDir(sessionID)); err != nil {
+		return fmt.Errorf("remove session %s: %w", sessionID, err)
+	}
+	dumps, _ := filepath.Glob(filepath.Join(s.BaseDir, "debug", sessionID+"-*"))
+	for _, dump := range dumps {
+		_ = os.Remove(dump)
+	}
+	if err := os.Remove(s.SessionPath(sessionID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// forgetSessions drops removed sessions from the index and clears last-session
+// pointers to them so --continue starts fresh instead of failing. Both are
+// advisory, so failures are ignored.
+func (s *Store) forgetSessions(removed map[string]bool) {
+	sessionIndexMu.Lock()
+	index := s.readIndex()
+	for id := range removed {
+		delete(index.Sessions, id)
+	}
+	_ = s.writeIndex(index)
+	sessionIndexMu.Unlock()
+
+	pointers, _ := filepath.Glob(filepath.Join(s.BaseDir, "projects", "*", "last_session"))
+	for _, pointer := range pointers {
+		raw, err := os.ReadFile(pointer)
+		if err == nil && removed[strings.TrimSpace(string(raw))] {
+			_ = os.Remove(pointer)
+		}
+	}
+}
+
+// treeSize sums the sizes of regular files under path; a missing path is empty.
+func treeSize(path string) int64 {
+	var total int64
+	_ = filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, infoErr := entry.Info(); infoErr == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
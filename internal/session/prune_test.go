@@ -0,0 +1,55 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestPruneSessions verifies expired sessions and their state are removed while
+// recent and kept sessions survive, and that a dry run deletes nothing.
+func TestPruneSessions(testingHandle *testing.T) {
+	// Arrange: "old" and "resumed" are 40 days stale, "fresh" was just written.
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	stale := time.Now().AddDate(0, 0, -40)
+	for _, sessionID := range []string{"old", "resumed", "fresh"} {
+		appendMessage(testingHandle, store, sessionID, "user", "prompt "+sessionID)
+	}
+	testutil.RequireNoError(testingHandle, store.SaveUIState("old", UIState{Verbose: true}), "save state")
+	testutil.RequireNoError(testingHandle, store.SaveLastSession("project", "old"), "save last session")
+	for _, sessionID := range []string{"old", "resumed"} {
+		testutil.RequireNoError(testingHandle, os.Chtimes(store.SessionPath(sessionID), stale, stale), "age session")
+	}
+	cutoff := time.Now().AddDate(0, 0, -30)
+	keep := map[string]bool{"resumed": true}
+
+	// Act
+	preview, previewErr := store.PruneSessions(cutoff, keep, true)
+	_, previewStatErr := os.Stat(store.SessionPath("old"))
+	report, err := store.PruneSessions(cutoff, keep, false)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, previewErr, "dry run")
+	testutil.RequireNoError(testingHandle, previewStatErr, "dry run keeps the log")
+	testutil.RequireEqual(testingHandle, len(preview.Pruned), 1, "dry run selection")
+	testutil.RequireNoError(testingHandle, err, "prune")
+	testutil.RequireEqual(testingHandle, len(report.Pruned), 1, "pruned count")
+	testutil.RequireEqual(testingHandle, report.Pruned[0].ID, "old", "pruned session")
+	testutil.RequireTrue(testingHandle, report.Pruned[0].Bytes > 0 && report.PrunedBytes == report.Pruned[0].Bytes, "pruned size")
+	testutil.RequireEqual(testingHandle, report.Kept, 2, "kept count")
+	_, statErr := os.Stat(store.SessionPath("old"))
+	testutil.RequireTrue(testingHandle, os.IsNotExist(statErr), "log removed")
+	_, statErr = os.Stat(filepath.Join(store.BaseDir, "session-env", "old"))
+	testutil.RequireTrue(testingHandle, os.IsNotExist(statErr), "state removed")
+	_, statErr = store.LoadLastSession("project")
+	testutil.RequireTrue(testingHandle, os.IsNotExist(statErr), "last session pointer cleared")
+	_, indexed := store.readIndex().Sessions["old"]
+	testutil.RequireTrue(testingHandle, !indexed, "index entry removed")
+	for _, sessionID := range []string{"resumed", "fresh"} {
+		_, statErr = os.Stat(store.SessionPath(sessionID))
+		testutil.RequireNoError(testingHandle, statErr, sessionID+" kept")
+	}
+}
@@ -0,0 +1,224 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedaction replaces redacted text when no replacement is given.
+const DefaultRedaction = "[REDACTED]"
+
+// RedactReport lists the files a redaction rewrote.
+type RedactReport struct {
+	// Files lists each file with matches, relative to the store directory.
+	Files []RedactedFile `json:"files"`
+	// Matches counts every replacement.
+	Matches int `json:"matches"`
+	// DryRun reports that nothing was rewritten.
+	DryRun bool `json:"dry_run"`
+}
+
+// RedactedFile is one file a redaction touched.
+type RedactedFile struct {
+	// Path is relative to the store directory.
+	Path string `json:"path"`
+	// Matches counts the replacements in the file.
+	Matches int `json:"matches"`
+}
+
+// RedactSession replaces every match of pattern with replacement wherever the
+// session is stored on disk: its log, the JSON state under session-env
+// (metadata log, checkpoints, UI state), saved artifacts, and debug dumps.
+// Input history is shared by every session of a project, so all of it is
+// redacted too. Edit backups are copies of workspace files and are left alone.
+//
+// JSON is matched against decoded strings, including stream-json lines stored
+// inside the log, so text is matched as it appeared in the conversation rather
+// than in its escaped form. Lines without matches are kept byte for byte;
+// rewritten files are replaced atomically. The session's index entry is then
+// rebuilt so its title and size match the new log. With dryRun, matches are
+// counted and nothing is written. Redact a session only while it is not in use;
+// concurrent appends could be lost.
+func (s *Store) RedactSession(sessionID string, pattern *regexp.Regexp, replacement string, dryRun bool) (RedactReport, error) {
+	report := RedactReport{DryRun: dryRun}
+	if pattern.MatchString("") {
+		return report, fmt.Errorf("pattern %q matches empty text", pattern)
+	}
+	if _, err := os.Stat(s.SessionPath(sessionID)); err != nil {
+		return report, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	paths := []string{s.SessionPath(sessionID)}
+	envDir := s.sessionEnvDir(sessionID)
+	err := filepath.WalkDir(envDir, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == "backup" {
+			return filepath.SkipDir
+		}
+		if entry.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("list session state: %w", err)
+	}
+	dumps, _ := filepath.Glob(filepath.Join(s.BaseDir, "debug", sessionID+"-*"))
+	histories, _ := filepath.Glob(filepath.Join(s.BaseDir, "history", "*.jsonl"))
+	paths = append(append(paths, dumps...), histories...)
+
+	for _, path := range paths {
+		matches, err := redactFile(path, pattern, replacement, dryRun)
+		if err != nil {
+			return report, err
+		}
+		if matches == 0 {
+			continue
+		}
+		relative, err := filepath.Rel(s.BaseDir, path)
+		if err != nil {
+			relative = path
+		}
+		report.Files = append(report.Files, RedactedFile{Path: filepath.ToSlash(relative), Matches: matches})
+		report.Matches += matches
+	}
+	if !dryRun && report.Matches > 0 {
+		if err := s.reindexSession(sessionID); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// redactFile redacts one file, returning the number of matches. JSON and JSONL
+// files are redacted per line; anything else as plain text.
+func redactFile(path string, pattern *regexp.Regexp, replacement string, dryRun bool) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("read %s: %w", path, err)
+	}
+	var redacted []byte
+	var matches int
+	switch filepath.Ext(path) {
+	case ".json", ".jsonl":
+		redacted, matches = redactJSONLines(data, pattern, replacement)
+	default:
+		matches = len(pattern.FindAllIndex(data, -1))
+		redacted = pattern.ReplaceAllLiteral(data, []byte(replacement))
+	}
+	if matches == 0 || dryRun {
+		return matches, nil
+	}
+	if err := writeFileAtomic(path, redacted); err != nil {
+		return 0, err
+	}
+	return matches, nil
+}
+
+// redactJSONLines redacts each line of a JSON or JSONL document. Lines that
+// are not valid JSON are redacted as plain text.
+func redactJSONLines(data []byte, pattern *regexp.Regexp, replacement string) ([]byte, int) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	total := 0
+	for index, line := range lines {
+		body := bytes.TrimRight(line, "\r\n")
+		if len(bytes.TrimSpace(body)) == 0 {
+			continue
+		}
+		redacted, matches := redactJSON(body, pattern, replacement)
+		if matches == 0 {
+			continue
+		}
+		lines[index] = append(redacted, line[len(body):]...)
+		total += matches
+	}
+	return bytes.Join(lines, nil), total
+}
+
+// redactJSON redacts the strings of one JSON value and re-encodes it when
+// anything matched.
+func redactJSON(data []byte, pattern *regexp.Regexp, replacement string) ([]byte, int) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return pattern.ReplaceAllLiteral(data, []byte(replacement)), len(pattern.FindAllIndex(data, -1))
+	}
+	value, matches := redactValue(value, pattern, replacement)
+	if matches == 0 {
+		return data, 0
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return pattern.ReplaceAllLiteral(data, []byte(replacement)), len(pattern.FindAllIndex(data, -1))
+	}
+	return encoded, matches
+}
+
+// redactValue replaces matches in every string of a decoded JSON value.
+// Strings holding JSON documents, like stored stream-json lines, are redacted
+// as JSON so escaped text still matches.
+func redactValue(value any, pattern *regexp.Regexp, replacement string) (any, int) {
+	switch typed := value.(type) {
+	case string:
+		trimmed := strings.TrimSpace(typed)
+		if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+			redacted, matches := redactJSON([]byte(trimmed), pattern, replacement)
+			if matches > 0 {
+				return string(redacted), matches
+			}
+			return typed, 0
+		}
+		matches := len(pattern.FindAllStringIndex(typed, -1))
+		if matches == 0 {
+			return typed, 0
+		}
+		return pattern.ReplaceAllLiteralString(typed, replacement), matches
+	case map[string]any:
+		total := 0
+		for key, item := range typed {
+			redacted, matches := redactValue(item, pattern, replacement)
+			typed[key] = redacted
+			total += matches
+		}
+		return typed, total
+	case []any:
+		total := 0
+		for index, item := range typed {
+			redacted, matches := redactValue(item, pattern, replacement)
+			typed[index] = redacted
+			total += matches
+		}
+		return typed, total
+	}
+	return value, 0
+}
+
+// reindexSession rebuilds a session's index entry from the start of its log,
+// keeping its creation time. The renamed title is reread since redaction may
+// have changed it.
+func (s *Store) reindexSession(sessionID string) error {
+	sessionIndexMu.Lock()
+	defer sessionIndexMu.Unlock()
+
+	index := s.readIndex()
+	meta := SessionMeta{ID: sessionID, CreatedAt: index.Sessions[sessionID].CreatedAt, RenamedTitle: s.renamedTitle(sessionID)}
+	meta, err := scanSessionMeta(s.SessionPath(sessionID), meta)
+	if err != nil {
+		return err
+	}
+	index.Sessions[sessionID] = meta
+	return s.writeIndex(index)
+}
@@ -0,0 +1,70 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestRedactSession verifies a pasted secret is replaced in the log, stored
+// stream-json lines, checkpoints, and input history, that unmatched lines and
+// edit backups are untouched, and that the index title is rebuilt.
+func TestRedactSession(testingHandle *testing.T) {
+	// Arrange a session whose first prompt pasted an API key.
+	const secret = "sk-live-0123456789abcdef"
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	appendMessage(testingHandle, store, "s1", "user", "my key is "+secret+" <please> fix auth")
+	appendMessage(testingHandle, store, "s1", "assistant", "done")
+	testutil.RequireNoError(testingHandle, store.AppendStreamJSONLine("s1", `{"type":"user","message":{"content":"key=`+secret+`"}}`), "append stream-json")
+	testutil.RequireNoError(testingHandle, store.SaveCheckpoint("s1", Checkpoint{Name: "before", Messages: []llm.Message{{Role: "user", Content: secret}}}), "save checkpoint")
+	testutil.RequireNoError(testingHandle, store.AppendInputHistory("project", "my key is "+secret, 0), "append history")
+	backup := filepath.Join(store.sessionEnvDir("s1"), "backup", "config.env")
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Dir(backup), 0o700), "create backup dir")
+	testutil.RequireNoError(testingHandle, os.WriteFile(backup, []byte("KEY="+secret+"\n"), 0o600), "write backup")
+	before, err := os.ReadFile(store.SessionPath("s1"))
+	testutil.RequireNoError(testingHandle, err, "read log")
+	_, err = store.ListSessionMeta(0)
+	testutil.RequireNoError(testingHandle, err, "index session")
+	pattern := regexp.MustCompile(`sk-live-[0-9a-f]+`)
+
+	// Act
+	preview, previewErr := store.RedactSession("s1", pattern, DefaultRedaction, true)
+	unchanged, _ := os.ReadFile(store.SessionPath("s1"))
+	report, err := store.RedactSession("s1", pattern, DefaultRedaction, false)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, previewErr, "dry run")
+	testutil.RequireEqual(testingHandle, preview.Matches, 4, "dry run matches")
+	testutil.RequireEqual(testingHandle, string(unchanged), string(before), "dry run writes nothing")
+	testutil.RequireNoError(testingHandle, err, "redact")
+	testutil.RequireEqual(testingHandle, report.Matches, 4, "matches")
+	testutil.RequireEqual(testingHandle, len(report.Files), 3, "files")
+	after, err := os.ReadFile(store.SessionPath("s1"))
+	testutil.RequireNoError(testingHandle, err, "read redacted log")
+	testutil.RequireTrue(testingHandle, !strings.Contains(string(after), "sk-live"), string(after))
+	beforeLines, afterLines := strings.Split(string(before), "\n"), strings.Split(string(after), "\n")
+	testutil.RequireEqual(testingHandle, afterLines[1], beforeLines[1], "unmatched line kept byte for byte")
+	lines, err := store.LoadStreamJSONLines("s1")
+	testutil.RequireNoError(testingHandle, err, "load stream-json")
+	testutil.RequireEqual(testingHandle, lines, []string{`{"message":{"content":"key=[REDACTED]"},"type":"user"}`}, "stream-json line")
+	checkpoint, err := store.LoadCheckpoint("s1", "before")
+	testutil.RequireNoError(testingHandle, err, "load checkpoint")
+	testutil.RequireEqual(testingHandle, checkpoint.Messages[0].Content, any(DefaultRedaction), "checkpoint")
+	history, err := store.LoadInputHistory("project", 0)
+	testutil.RequireNoError(testingHandle, err, "load history")
+	testutil.RequireEqual(testingHandle, history, []string{"my key is [REDACTED]"}, "history")
+	backupData, err := os.ReadFile(backup)
+	testutil.RequireNoError(testingHandle, err, "read backup")
+	testutil.RequireTrue(testingHandle, strings.Contains(string(backupData), secret), "backups are not rewritten")
+	metas, err := store.ListSessionMeta(0)
+	testutil.RequireNoError(testingHandle, err, "list")
+	testutil.RequireEqual(testingHandle, metas[0].Title, "my key is [REDACTED] <please> fix auth", "title rebuilt")
+	testutil.RequireEqual(testingHandle, metas[0].MessageCount, 2, "message count")
+	_, err = store.RedactSession("s1", regexp.MustCompile(`x*`), DefaultRedaction, false)
+	testutil.RequireTrue(testingHandle, err != nil, "empty-matching pattern rejected")
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+// costEventType marks the per-run cost records folded into session stats.
+const costEventType = "cost"
+
+// costEvent records the estimated cost of one run in the session log.
+type costEvent struct {
+	// Type is always costEventType.
+	Type string `json:"type"`
+	// CostUSD is the run's estimated cost.
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// Markers for the non-message events the index folds into stats, checked
+// before decoding so other records are skipped cheaply.
+var (
+	toolCallEventMarker = []byte(`"type":"tool_call"`)
+	costEventMarker     = []byte(`"type":"cost"`)
+)
+
+// fileEditTools are the tools whose calls change files.
+var fileEditTools = map[string]bool{"Edit": true, "Write": true, "NotebookEdit": true}
+
+// EditToolPath returns the file an edit tool call changes, or "" for other calls.
+func EditToolPath(toolName string, arguments json.RawMessage) string {
+	if !fileEditTools[toolName] {
+		return ""
+	}
+	var args struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	}
+	if json.Unmarshal(arguments, &args) != nil {
+		return ""
+	}
+	if args.FilePath != "" {
+		return args.FilePath
+	}
+	return args.NotebookPath
+}
+
+// AppendCost records a run's estimated cost so listings can total it.
+// Runs without a priced cost are not recorded.
+func (s *Store) AppendCost(sessionID string, costUSD float64) error {
+	if sessionID == "" {
+		return errors.New("session id required")
+	}
+	if costUSD <= 0 {
+		return nil
+	}
+	return s.AppendEvent(sessionID, costEvent{Type: costEventType, CostUSD: costUSD})
+}
+
+// applyStatsEvent folds tool call and cost records into session stats.
+func applyStatsEvent(meta *SessionMeta, line []byte) {
+	switch {
+	case bytes.Contains(line, toolCallEventMarker):
+		var record struct {
+			Type      string          `json:"type"`
+			ToolName  string          `json:"tool_name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if json.Unmarshal(line, &record) != nil || record.Type != "tool_call" || record.ToolName == "" {
+			return
+		}
+		meta.LastTool = record.ToolName
+		if path := EditToolPath(record.ToolName, record.Arguments); path != "" && !slices.Contains(meta.FilesModified, path) {
+			meta.FilesModified = append(meta.FilesModified, path)
+		}
+	case bytes.Contains(line, costEventMarker):
+		var record costEvent
+		if json.Unmarshal(line, &record) != nil || record.Type != costEventType {
+			return
+		}
+		meta.CostUSD += record.CostUSD
+	}
+}
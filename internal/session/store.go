@@ -2,6 +2,7 @@ package session
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -73,14 +74,34 @@ func (s *Store) AppendEvent(sessionID string, event any) error {
 	if err != nil {
 		return fmt.Errorf("marshal session event: %w", err)
 	}
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat session file: %w", err)
+	}
 
 	if _, err := file.Write(append(data, '\n')); err != nil {
 		return fmt.Errorf("write session event: %w", err)
 	}
 
+	// The index is only rewritten when a new session appears or its first
+	// prompt gives it a title; everything else is picked up incrementally by
+	// the next listing or CloseSession. The index is advisory, so failures are ignored.
+	if bytes.Contains(data, messageEventMarker) && (info.Size() == 0 || s.awaitsIndexedTitle(sessionID, data)) {
+		_ = s.updateIndexEntry(sessionID)
+	}
+
 	return nil
 }
 
+// CloseSession brings the session's index entry up to date once a run has
+// stored its messages, so the next listing does not have to rescan them.
+func (s *Store) CloseSession(sessionID string) error {
+	if sessionID == "" {
+		return errors.New("session id required")
+	}
+	return s.updateIndexEntry(sessionID)
+}
+
 // AppendStreamJSONLine stores a stream-json line for later replay.
 // It trims surrounding whitespace so empty lines do not pollute the session log.
 func (s *Store) AppendStreamJSONLine(sessionID string, line string) error {
@@ -124,6 +145,94 @@ func (s *Store) LoadEvents(sessionID string) ([]json.RawMessage, error) {
 	return events, nil
 }
 
+// EventPage is a window of session events read backwards from the end of a log.
+type EventPage struct {
+	// Events holds the matching events in chronological order.
+	Events []json.RawMessage
+	// Start is the byte offset of the first returned event.
+	// Pass it as before to LoadEventsPage to fetch the preceding page.
+	Start int64
+	// HasMore reports whether older matching events exist before Start.
+	HasMore bool
+}
+
+// eventPageChunkSize is how many bytes are read per backwards seek.
+const eventPageChunkSize = 64 * 1024
+
+// LoadEventsPage reads up to limit matching events that end before the byte offset before.
+// A negative before reads from the end of the file, and a non-positive limit reads every
+// matching event. Events rejected by match are skipped without counting towards limit;
+// a nil match accepts every event. Only the requested window is scanned, so resuming a
+// large session does not require reading the whole log.
+func (s *Store) LoadEventsPage(sessionID string, before int64, limit int, match func(json.RawMessage) bool) (EventPage, error) {
+	file, err := os.Open(s.SessionPath(sessionID))
+	if err != nil {
+		return EventPage{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return EventPage{}, fmt.Errorf("stat session file: %w", err)
+	}
+	end := info.Size()
+	if before >= 0 && before < end {
+		end = before
+	}
+
+	page := EventPage{Start: end}
+	// buf holds the unconsumed bytes in [pos, end of the current line).
+	pos := end
+	var buf []byte
+	for {
+		body := buf
+		for len(body) > 0 && body[len(body)-1] == '\n' {
+			body = body[:len(body)-1]
+		}
+		index := bytes.LastIndexByte(body, '\n')
+		if index < 0 && pos > 0 {
+			// The earliest line in the buffer may be partial; read the preceding chunk.
+			size := int64(eventPageChunkSize)
+			if size > pos {
+				size = pos
+			}
+			pos -= size
+			prefix := make([]byte, size, size+int64(len(buf)))
+			if _, err := file.ReadAt(prefix, pos); err != nil {
+				return EventPage{}, fmt.Errorf("read session file: %w", err)
+			}
+			buf = append(prefix, buf...)
+			continue
+		}
+		if len(body) == 0 {
+			break
+		}
+
+		line := bytes.TrimSpace(body[index+1:])
+		lineStart := pos + int64(index+1)
+		buf = body[:index+1]
+		if len(line) == 0 {
+			continue
+		}
+		raw := json.RawMessage(append([]byte(nil), line...))
+		if match != nil && !match(raw) {
+			continue
+		}
+		if limit > 0 && len(page.Events) == limit {
+			page.HasMore = true
+			break
+		}
+		page.Events = append(page.Events, raw)
+		page.Start = lineStart
+	}
+
+	// Events were collected newest-first; restore chronological order.
+	for left, right := 0, len(page.Events)-1; left < right; left, right = left+1, right-1 {
+		page.Events[left], page.Events[right] = page.Events[right], page.Events[left]
+	}
+	return page, nil
+}
+
 // LoadStreamJSONLines returns stored stream-json lines in session order.
 // It skips malformed entries so replay is resilient to partial writes.
 func (s *Store) LoadStreamJSONLines(sessionID string) ([]string, error) {
@@ -146,7 +255,12 @@ func (s *Store) LoadStreamJSONLines(sessionID string) ([]string, error) {
 	return lines, nil
 }
 
-// CloneSession copies events from one session id to another.
+// CloneSession copies a session to a new id: its event log and the per-session
+// state under session-env (metadata log, checkpoints, UI state),
+// so a fork resumes exactly like the original. The log is written to a temporary
+// file and renamed into place after the state is copied, so an interrupted clone
+// leaves no partial log. Cloning again is a no-op once the target log starts with
+// the source log; a target holding a different conversation is an error.
 func (s *Store) CloneSession(fromSessionID string, toSessionID string) error {
 	if fromSessionID == "" || toSessionID == "" {
 		return errors.New("session id required")
@@ -154,14 +268,141 @@ func (s *Store) CloneSession(fromSessionID string, toSessionID string) error {
 	if fromSessionID == toSessionID {
 		return nil
 	}
-	events, err := s.LoadEvents(fromSessionID)
+	source, err := os.ReadFile(s.SessionPath(fromSessionID))
+	if err != nil {
+		return err
+	}
+	targetPath := s.SessionPath(toSessionID)
+	existing, err := os.ReadFile(targetPath)
+	if err == nil {
+		if bytes.HasPrefix(existing, source) {
+			return nil
+		}
+		return fmt.Errorf("session %s already exists with a different history", toSessionID)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read session file: %w", err)
+	}
+
+	if err := s.cloneSessionEnv(fromSessionID, toSessionID); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(targetPath, source); err != nil {
+		return err
+	}
+	// The index is advisory; the next listing rescans the log if this fails.
+	_ = s.updateIndexEntry(toSessionID)
+	return nil
+}
+
+// uncopiedSessionEnv lists session-env entries a clone leaves behind: edit
+// backups and artifacts can be large, and the conversation refers to them by
+// their original paths, which stay valid.
+var uncopiedSessionEnv = map[string]bool{"backup": true, "artifacts": true}
+
+// cloneSessionEnv copies the source session-env directory through a staging
+// directory. Entries the target already has are kept.
+func (s *Store) cloneSessionEnv(fromSessionID string, toSessionID string) error {
+	root := filepath.Join(s.BaseDir, "session-env")
+	entries, err := os.ReadDir(filepath.Join(root, fromSessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read session state: %w", err)
+	}
+	staging, err := os.MkdirTemp(root, "."+toSessionID+"-clone-")
+	if err != nil {
+		return fmt.Errorf("create session state: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	for _, entry := range entries {
+		if uncopiedSessionEnv[entry.Name()] {
+			continue
+		}
+		if err := copyTree(filepath.Join(root, fromSessionID, entry.Name()), filepath.Join(staging, entry.Name())); err != nil {
+			return fmt.Errorf("copy session state: %w", err)
+		}
+	}
+
+	target := filepath.Join(root, toSessionID)
+	if err := os.Rename(staging, target); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return fmt.Errorf("create session state: %w", err)
+	}
+	staged, err := os.ReadDir(staging)
+	if err != nil {
+		return fmt.Errorf("read session state: %w", err)
+	}
+	for _, entry := range staged {
+		destination := filepath.Join(target, entry.Name())
+		if _, err := os.Lstat(destination); err == nil {
+			continue
+		}
+		if err := os.Rename(filepath.Join(staging, entry.Name()), destination); err != nil {
+			return fmt.Errorf("copy session state: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyTree copies a regular file or a directory of them, keeping permissions.
+// Other file types are skipped.
+func copyTree(source string, destination string) error {
+	info, err := os.Lstat(source)
 	if err != nil {
 		return err
 	}
-	for _, event := range events {
-		if err := s.AppendEvent(toSessionID, event); err != nil {
+	if info.IsDir() {
+		if err := os.Mkdir(destination, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(source)
+		if err != nil {
 			return err
 		}
+		for _, entry := range entries {
+			if err := copyTree(filepath.Join(source, entry.Name()), filepath.Join(destination, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destination, data, info.Mode().Perm())
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create session dir: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("write session file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("write session file: %w", err)
+	}
+	if err := temp.Chmod(0o600); err != nil {
+		temp.Close()
+		return fmt.Errorf("write session file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("write session file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("write session file: %w", err)
 	}
 	return nil
 }
@@ -203,7 +444,7 @@ func (s *Store) ListSessions(limit int) ([]string, error) {
 
 	var list []entry
 	for _, item := range entries {
-		if item.IsDir() {
+		if item.IsDir() || filepath.Ext(item.Name()) != ".jsonl" {
 			continue
 		}
 		info, err := item.Info()
@@ -0,0 +1,132 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestLoadEventsPageWalksBackwards verifies pages cover the log newest-first without gaps.
+func TestLoadEventsPageWalksBackwards(testingHandle *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		limit int
+		pad   int
+	}{
+		{name: "small events", count: 25, limit: 10, pad: 0},
+		{name: "events larger than a chunk", count: 6, limit: 4, pad: eventPageChunkSize + 17},
+		{name: "single page", count: 3, limit: 10, pad: 0},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			store := &Store{BaseDir: testingHandle.TempDir()}
+			for index := 0; index < tt.count; index++ {
+				event := map[string]any{"type": "message", "n": index, "pad": strings.Repeat("x", tt.pad)}
+				testutil.RequireNoError(testingHandle, store.AppendEvent("s1", event), "append event")
+				// Interleave non-matching events to verify filtering does not count them.
+				testutil.RequireNoError(testingHandle, store.AppendEvent("s1", map[string]any{"type": "tool_call"}), "append tool event")
+			}
+
+			var seen []int
+			before := int64(-1)
+			for {
+				page, err := store.LoadEventsPage("s1", before, tt.limit, isMessageRecord)
+				testutil.RequireNoError(testingHandle, err, "load page")
+				var numbers []int
+				for _, raw := range page.Events {
+					var payload struct {
+						N int `json:"n"`
+					}
+					testutil.RequireNoError(testingHandle, json.Unmarshal(raw, &payload), "decode event")
+					numbers = append(numbers, payload.N)
+				}
+				seen = append(numbers, seen...)
+				if !page.HasMore {
+					break
+				}
+				before = page.Start
+			}
+
+			want := make([]int, tt.count)
+			for index := range want {
+				want[index] = index
+			}
+			testutil.RequireEqual(testingHandle, seen, want, "paged events")
+		})
+	}
+}
+
+// TestLoadEventsPageUnlimited verifies a non-positive limit returns every event.
+func TestLoadEventsPageUnlimited(testingHandle *testing.T) {
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	for index := 0; index < 5; index++ {
+		testutil.RequireNoError(testingHandle, store.AppendEvent("s1", map[string]any{"n": index}), "append event")
+	}
+
+	page, err := store.LoadEventsPage("s1", -1, 0, nil)
+	testutil.RequireNoError(testingHandle, err, "load page")
+	testutil.RequireEqual(testingHandle, len(page.Events), 5, "event count")
+	testutil.RequireEqual(testingHandle, page.HasMore, false, "has more")
+	testutil.RequireEqual(testingHandle, page.Start, int64(0), "start offset")
+}
+
+// isMessageRecord matches message events in the test log.
+func isMessageRecord(raw json.RawMessage) bool {
+	return strings.Contains(string(raw), fmt.Sprintf("%q:%q", "type", "message"))
+}
+
+// TestCloneSessionCopiesStateOnce verifies the log and session state are copied and re-cloning is a no-op.
+func TestCloneSessionCopiesStateOnce(testingHandle *testing.T) {
+	// Arrange a session with a message and per-session state.
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	message := map[string]any{"type": "message", "message": map[string]any{"role": "user", "content": "fix the parser"}}
+	testutil.RequireNoError(testingHandle, store.AppendEvent("src", message), "append event")
+	env := filepath.Join(store.BaseDir, "session-env", "src")
+	files := map[string]string{
+		"todo.json":              `[{"content":"fix"}]`,
+		"plan_mode":              "on",
+		"tasks.jsonl":            `{"id":"t1"}` + "\n",
+		"ui.json":                `{"verbose":true}`,
+		"checkpoints/start.json": `{"name":"start"}`,
+		"backup/main.go":         "package main",
+	}
+	for name, content := range files {
+		path := filepath.Join(env, name)
+		testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Dir(path), 0o755), "create state dir")
+		testutil.RequireNoError(testingHandle, os.WriteFile(path, []byte(content), 0o600), "write "+name)
+	}
+
+	// Act
+	testutil.RequireNoError(testingHandle, store.CloneSession("src", "fork"), "clone")
+	testutil.RequireNoError(testingHandle, store.CloneSession("src", "fork"), "clone again")
+
+	// Assert
+	events, err := store.LoadEvents("fork")
+	testutil.RequireNoError(testingHandle, err, "load fork")
+	testutil.RequireEqual(testingHandle, len(events), 1, "events copied once")
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(store.BaseDir, "session-env", "fork", name))
+		if strings.HasPrefix(name, "backup/") {
+			testutil.RequireTrue(testingHandle, os.IsNotExist(err), "backups stay with the source")
+			continue
+		}
+		testutil.RequireNoError(testingHandle, err, "read "+name)
+		testutil.RequireEqual(testingHandle, string(data), content, name)
+	}
+	metas, err := store.ListSessionMeta(0)
+	testutil.RequireNoError(testingHandle, err, "list sessions")
+	titles := map[string]string{}
+	for _, meta := range metas {
+		titles[meta.ID] = meta.Title
+	}
+	testutil.RequireEqual(testingHandle, titles["fork"], "fix the parser", "title")
+
+	testutil.RequireNoError(testingHandle, store.AppendEvent("other", map[string]any{"type": "message"}), "append other")
+	testutil.RequireTrue(testingHandle, store.CloneSession("src", "other") != nil, "divergent target rejected")
+}
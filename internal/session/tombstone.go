@@ -0,0 +1,67 @@
+package session
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// TombstoneEventType marks a record retracting the messages stored before it.
+const TombstoneEventType = "tombstone"
+
+// tombstoneEventMarker identifies tombstones without decoding JSON.
+var tombstoneEventMarker = []byte(`"type":"tombstone"`)
+
+// Tombstone retracts the last Messages message events stored before it, as
+// /undo does. The log itself is append-only, so loaders skip the retracted
+// messages instead.
+type Tombstone struct {
+	// Type is always TombstoneEventType.
+	Type string `json:"type"`
+	// Messages counts the retracted message events.
+	Messages int `json:"messages"`
+	// Turns counts the user prompts among them.
+	Turns int `json:"turns"`
+	// At is when the messages were retracted.
+	At time.Time `json:"at"`
+}
+
+// AppendTombstone retracts retracted, the newest messages in the session log.
+func (s *Store) AppendTombstone(sessionID string, retracted []llm.Message) error {
+	tombstone := Tombstone{Type: TombstoneEventType, Messages: len(retracted), At: time.Now().UTC()}
+	for _, message := range retracted {
+		if message.Role == "user" {
+			tombstone.Turns++
+		}
+	}
+	if err := s.AppendEvent(sessionID, tombstone); err != nil {
+		return err
+	}
+	// The index is advisory; the next listing rescans the log if this fails.
+	_ = s.updateIndexEntry(sessionID)
+	return nil
+}
+
+// DecodeTombstone returns the tombstone a raw session event holds, if any.
+func DecodeTombstone(raw []byte) (Tombstone, bool) {
+	var tombstone Tombstone
+	if json.Unmarshal(raw, &tombstone) != nil || tombstone.Type != TombstoneEventType {
+		return Tombstone{}, false
+	}
+	return tombstone, true
+}
+
+// applyTombstoneEvent removes retracted messages from the session counts.
+// Undoing every prompt also drops the title taken from the first one.
+func applyTombstoneEvent(meta *SessionMeta, line []byte) {
+	tombstone, ok := DecodeTombstone(line)
+	if !ok {
+		return
+	}
+	meta.MessageCount = max(meta.MessageCount-tombstone.Messages, 0)
+	meta.Turns = max(meta.Turns-tombstone.Turns, 0)
+	if meta.Turns == 0 {
+		meta.Title = ""
+	}
+}
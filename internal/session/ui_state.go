@@ -0,0 +1,56 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// UIState holds per-session TUI preferences restored when the session is resumed.
+type UIState struct {
+	// Verbose shows full tool inputs and outputs instead of condensed summaries.
+	Verbose bool `json:"verbose,omitempty"`
+}
+
+// UIStatePath returns the file holding a session's TUI preferences.
+func (s *Store) UIStatePath(sessionID string) string {
+	return filepath.Join(s.BaseDir, "session-env", sessionID, "ui.json")
+}
+
+// LoadUIState reads a session's TUI preferences; a session without any yields the zero state.
+func (s *Store) LoadUIState(sessionID string) (UIState, error) {
+	var state UIState
+	data, err := os.ReadFile(s.UIStatePath(sessionID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("read ui state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return UIState{}, fmt.Errorf("parse ui state: %w", err)
+	}
+	return state, nil
+}
+
+// SaveUIState stores a session's TUI preferences.
+func (s *Store) SaveUIState(sessionID string, state UIState) error {
+	if sessionID == "" {
+		return errors.New("session id required")
+	}
+	path := s.UIStatePath(sessionID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create session env dir: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal ui state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write ui state: %w", err)
+	}
+	return nil
+}
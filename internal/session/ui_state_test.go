@@ -0,0 +1,23 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestUIStateRoundTrip verifies TUI preferences default to zero and persist per session.
+func TestUIStateRoundTrip(testingHandle *testing.T) {
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	state, err := store.LoadUIState("s1")
+	testutil.RequireNoError(testingHandle, err, "load missing state")
+	testutil.RequireEqual(testingHandle, state, UIState{}, "zero state")
+
+	testutil.RequireNoError(testingHandle, store.SaveUIState("s1", UIState{Verbose: true}), "save state")
+	state, err = store.LoadUIState("s1")
+	testutil.RequireNoError(testingHandle, err, "load state")
+	testutil.RequireTrue(testingHandle, state.Verbose, "verbose persisted")
+	other, err := store.LoadUIState("s2")
+	testutil.RequireNoError(testingHandle, err, "load other session")
+	testutil.RequireTrue(testingHandle, !other.Verbose, "state is per session")
+}
@@ -0,0 +1,65 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WorkspaceTrust is the user's answer to the trust dialog for one directory.
+// It is kept under ~/.openclaude/projects rather than in the workspace, so a
+// cloned repository cannot mark itself trusted.
+type WorkspaceTrust struct {
+	// Path is the directory the decision was made for.
+	Path string `json:"path"`
+	// Trusted allows Bash and file edits in the directory and below it.
+	Trusted bool `json:"trusted"`
+	// DecidedAt is when the user answered.
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// workspaceTrustPath returns the trust record for a directory.
+func (s *Store) workspaceTrustPath(dir string) string {
+	return filepath.Join(s.BaseDir, "projects", ProjectHash(dir), "trust.json")
+}
+
+// LoadWorkspaceTrust returns the decision covering dir: its own or that of
+// the nearest ancestor with one. ok is false when no decision was made.
+func (s *Store) LoadWorkspaceTrust(dir string) (WorkspaceTrust, bool, error) {
+	for current := filepath.Clean(dir); ; current = filepath.Dir(current) {
+		raw, err := os.ReadFile(s.workspaceTrustPath(current))
+		if err == nil {
+			var trust WorkspaceTrust
+			if err := json.Unmarshal(raw, &trust); err != nil {
+				return WorkspaceTrust{}, false, fmt.Errorf("parse workspace trust: %w", err)
+			}
+			return trust, true, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return WorkspaceTrust{}, false, fmt.Errorf("read workspace trust: %w", err)
+		}
+		if filepath.Dir(current) == current {
+			return WorkspaceTrust{}, false, nil
+		}
+	}
+}
+
+// SaveWorkspaceTrust records the user's trust decision for dir.
+func (s *Store) SaveWorkspaceTrust(dir string, trusted bool) error {
+	trust := WorkspaceTrust{Path: filepath.Clean(dir), Trusted: trusted, DecidedAt: time.Now().UTC()}
+	data, err := json.MarshalIndent(trust, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode workspace trust: %w", err)
+	}
+	path := s.workspaceTrustPath(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create project dir: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write workspace trust: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestWorkspaceTrustCoversSubdirectories verifies a decision applies to the
+// directory and below it, and the nearest decision wins.
+func TestWorkspaceTrustCoversSubdirectories(testingHandle *testing.T) {
+	// Arrange
+	store := &Store{BaseDir: testingHandle.TempDir()}
+	repo := filepath.Join(testingHandle.TempDir(), "repo")
+	vendored := filepath.Join(repo, "third_party", "lib")
+	_, undecided, err := store.LoadWorkspaceTrust(repo)
+	testutil.RequireNoError(testingHandle, err, "load before deciding")
+
+	// Act
+	testutil.RequireNoError(testingHandle, store.SaveWorkspaceTrust(repo, true), "trust repo")
+	testutil.RequireNoError(testingHandle, store.SaveWorkspaceTrust(vendored, false), "distrust vendored")
+	repoTrust, repoDecided, repoErr := store.LoadWorkspaceTrust(filepath.Join(repo, "src"))
+	vendoredTrust, _, vendoredErr := store.LoadWorkspaceTrust(filepath.Join(vendored, "pkg"))
+
+	// Assert
+	testutil.RequireTrue(testingHandle, !undecided, "no decision at first")
+	testutil.RequireNoError(testingHandle, repoErr, "load repo subdir")
+	testutil.RequireNoError(testingHandle, vendoredErr, "load vendored subdir")
+	testutil.RequireTrue(testingHandle, repoDecided && repoTrust.Trusted && repoTrust.Path == repo, "subdirectory inherits trust")
+	testutil.RequireTrue(testingHandle, !vendoredTrust.Trusted && vendoredTrust.Path == vendored, "nearest decision wins")
+}
@@ -0,0 +1,76 @@
+package streamjson
+
+import "github.com/openclaude/openclaude/internal/llm"
+
+// ImageSource describes a Claude-style image block source.
+type ImageSource struct {
+	// Type is base64 or url.
+	Type string `json:"type"`
+	// MediaType is the image media type for base64 sources.
+	MediaType string `json:"media_type,omitempty"`
+	// Data is the base64 payload for base64 sources.
+	Data string `json:"data,omitempty"`
+	// URL is the image location for url sources.
+	URL string `json:"url,omitempty"`
+}
+
+// ConvertUserContent translates Claude-style user content into provider content.
+// Text-only content stays a string; image blocks become image_url parts.
+func ConvertUserContent(content any) any {
+	blocks, ok := content.([]any)
+	if !ok {
+		return ExtractText(content)
+	}
+	parts := make([]llm.ContentPart, 0, len(blocks))
+	hasImage := false
+	for _, item := range blocks {
+		block, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch block["type"] {
+		case "text":
+			if text, ok := block["text"].(string); ok {
+				parts = append(parts, llm.TextPart(text))
+			}
+		case "image":
+			source, _ := block["source"].(map[string]any)
+			url := imageSourceURL(source)
+			if url == "" {
+				continue
+			}
+			parts = append(parts, llm.ImagePart(url))
+			hasImage = true
+		}
+	}
+	if !hasImage {
+		return ExtractText(content)
+	}
+	return parts
+}
+
+// imageSourceURL converts an image block source into a URL or data URL.
+func imageSourceURL(source map[string]any) string {
+	switch source["type"] {
+	case "base64":
+		mediaType, _ := source["media_type"].(string)
+		data, _ := source["data"].(string)
+		if mediaType == "" || data == "" {
+			return ""
+		}
+		return "data:" + mediaType + ";base64," + data
+	case "url":
+		url, _ := source["url"].(string)
+		return url
+	default:
+		return ""
+	}
+}
+
+// imageBlock converts an image URL back into a Claude-style image block.
+func imageBlock(url string) ContentBlock {
+	if mediaType, data, ok := llm.ParseImageDataURL(url); ok {
+		return ContentBlock{Type: "image", Source: &ImageSource{Type: "base64", MediaType: mediaType, Data: data}}
+	}
+	return ContentBlock{Type: "image", Source: &ImageSource{Type: "url", URL: url}}
+}
@@ -1,7 +1,9 @@
 package streamjson
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,7 +12,7 @@ import (
 
 	"github.com/google/uuid"
 
-	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/llm"
 )
 
 // Message represents the high-level message payload used in stream-json events.
@@ -43,6 +45,8 @@ type ContentBlock struct {
 	Type string `json:"type"`
 	// Text carries plain text content.
 	Text string `json:"text,omitempty"`
+	// Thinking carries reasoning text, when Type == thinking.
+	Thinking string `json:"thinking,omitempty"`
 	// ID identifies a tool call, when Type == tool_use.
 	ID string `json:"id,omitempty"`
 	// Name specifies the tool name for tool_use blocks.
@@ -55,6 +59,8 @@ type ContentBlock struct {
 	Content string `json:"content,omitempty"`
 	// IsError indicates a tool_result error condition.
 	IsError bool `json:"is_error,omitempty"`
+	// Source carries the image data, when Type == image.
+	Source *ImageSource `json:"source,omitempty"`
 }
 
 // AssistantEvent represents a stream-json assistant message event.
@@ -83,6 +89,9 @@ type MessageUsage struct {
 	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
 	// CacheReadInputTokens reports cached read input tokens.
 	CacheReadInputTokens int `json:"cache_read_input_tokens"`
+	// ReasoningOutputTokens counts output tokens spent on reasoning, when the
+	// gateway reports them (an OpenClaude extension).
+	ReasoningOutputTokens int `json:"reasoning_output_tokens,omitempty"`
 	// ServerToolUse reports tool request counts handled by the service.
 	ServerToolUse MessageServerToolUse `json:"server_tool_use"`
 	// ServiceTier reports the service tier when available.
@@ -123,6 +132,25 @@ type UserEvent struct {
 	IsSynthetic bool `json:"isSynthetic,omitempty"`
 	// IsReplay marks user messages replayed into the stream.
 	IsReplay bool `json:"isReplay,omitempty"`
+	// ToolUseResult is the structured output of a command tool on tool_result messages.
+	ToolUseResult *ToolUseResult `json:"tool_use_result,omitempty"`
+}
+
+// ToolUseResult mirrors Claude Code's tool_use_result for Bash: stdout and
+// stderr kept apart. ExitCode and DurationMS are OpenClaude extensions.
+type ToolUseResult struct {
+	// Stdout is the command's standard output.
+	Stdout string `json:"stdout"`
+	// Stderr is the command's standard error.
+	Stderr string `json:"stderr"`
+	// Interrupted reports a command stopped before it finished.
+	Interrupted bool `json:"interrupted"`
+	// IsImage reports image output; command output never is.
+	IsImage bool `json:"isImage"`
+	// ExitCode is the command's exit status (-1 when it did not start).
+	ExitCode int `json:"exitCode"`
+	// DurationMS is how long the command ran.
+	DurationMS int64 `json:"durationMs"`
 }
 
 // SystemEvent represents a stream-json system event.
@@ -141,6 +169,27 @@ type SystemEvent struct {
 	UUID string `json:"uuid"`
 }
 
+// GitPreflightEvent warns that an edit touches a file with uncommitted changes
+// (OpenClaude extension).
+type GitPreflightEvent struct {
+	// Type is always "system".
+	Type string `json:"type"`
+	// Subtype is always "git_preflight".
+	Subtype string `json:"subtype"`
+	// ToolName is the edit tool about to run.
+	ToolName string `json:"tool_name"`
+	// Files lists the uncommitted files the edit touches.
+	Files []string `json:"files"`
+	// DirtyCount counts all uncommitted files in the repository.
+	DirtyCount int `json:"dirty_count"`
+	// Action reports what happens next; print mode always proceeds.
+	Action string `json:"action"`
+	// SessionID scopes the event to a session.
+	SessionID string `json:"session_id"`
+	// UUID uniquely identifies the event.
+	UUID string `json:"uuid"`
+}
+
 // SystemInitEvent represents the stream-json initialization event.
 type SystemInitEvent struct {
 	// Type is always "system".
@@ -175,6 +224,8 @@ type SystemInitEvent struct {
 	Skills []any `json:"skills"`
 	// Plugins lists configured plugins.
 	Plugins []any `json:"plugins"`
+	// Git reports branch, ahead/behind, and dirty counts (OpenClaude extension; omitted outside a repository).
+	Git any `json:"git,omitempty"`
 	// UUID uniquely identifies the event.
 	UUID string `json:"uuid"`
 }
@@ -203,6 +254,21 @@ type ProgressData struct {
 	Status string `json:"status,omitempty"`
 	// Message provides a human-readable description.
 	Message string `json:"message,omitempty"`
+	// Current counts work done for "running" tool progress (OpenClaude extension).
+	Current int64 `json:"current,omitempty"`
+	// Total is the expected amount of work, when known.
+	Total int64 `json:"total,omitempty"`
+	// Unit names what Current and Total count, e.g. "bytes" or "files".
+	Unit string `json:"unit,omitempty"`
+	// Percent is the completion percentage, when the total is known.
+	Percent *int `json:"percent,omitempty"`
+	// Model is the model of the latest request in "run_progress" events (OpenClaude extension).
+	Model string `json:"model,omitempty"`
+	// ElapsedMS is how long the reported phase has lasted in "run_progress"
+	// events, or how long the tool ran in completed "tool_progress" events.
+	ElapsedMS int64 `json:"elapsed_ms,omitempty"`
+	// Attempt is the attempt about to start when Status is "retrying".
+	Attempt int `json:"attempt,omitempty"`
 }
 
 // ToolUseSummaryEvent summarizes completed tool usage.
@@ -367,6 +433,41 @@ type ResultEvent struct {
 	UUID string `json:"uuid"`
 	// Errors holds error messages for error subtypes.
 	Errors []string `json:"errors,omitempty"`
+	// ErrorCode is the errcode category of a failed run, e.g. "rate_limited" (OpenClaude extension).
+	ErrorCode string `json:"error_code,omitempty"`
+	// Question is the pending AskUserQuestion payload for needs_user_input (OpenClaude extension).
+	Question any `json:"question,omitempty"`
+	// ResumeToken is passed to --resume to answer the question (OpenClaude extension).
+	ResumeToken string `json:"resume_token,omitempty"`
+	// Artifacts lists files in the session's artifacts directory (OpenClaude extension).
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// Turns times each assistant turn of the run (OpenClaude extension).
+	Turns []TurnTiming `json:"turns,omitempty"`
+}
+
+// TurnTiming reports the timing of one assistant turn.
+type TurnTiming struct {
+	// Model is the model the turn's request went to.
+	Model string `json:"model"`
+	// DurationMS is the turn's wall time, tool calls included.
+	DurationMS int64 `json:"duration_ms"`
+	// DurationAPIMS is the turn's API request latency.
+	DurationAPIMS int64 `json:"duration_api_ms"`
+	// TimeToFirstTokenMS is the wait for the first streamed output; omitted
+	// for non-streaming requests.
+	TimeToFirstTokenMS int64 `json:"ttft_ms,omitempty"`
+	// OutputTokens counts the tokens the request generated.
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Artifact describes one session artifact in a result event.
+type Artifact struct {
+	// Name is the path relative to the artifacts directory.
+	Name string `json:"name"`
+	// Path is the absolute path on disk.
+	Path string `json:"path"`
+	// SizeBytes is the file size.
+	SizeBytes int64 `json:"size_bytes"`
 }
 
 // StreamEvent wraps a low-level streaming event.
@@ -435,6 +536,8 @@ type StreamDelta struct {
 	Type string `json:"type"`
 	// Text is the streamed text chunk.
 	Text string `json:"text,omitempty"`
+	// Thinking is the streamed reasoning chunk for thinking_delta.
+	Thinking string `json:"thinking,omitempty"`
 	// PartialJSON carries incremental JSON for tool inputs.
 	PartialJSON string `json:"partial_json,omitempty"`
 }
@@ -471,6 +574,8 @@ type MessageStopEvent struct {
 
 // Writer emits stream-json events as JSON Lines.
 // The writer guarantees each call produces exactly one newline-delimited JSON object.
+// The encoder and buffer are reused across writes so high-frequency delta
+// emission does not allocate a fresh encoder per event.
 type Writer struct {
 	// mu serializes writes to prevent JSON line interleaving.
 	mu sync.Mutex
@@ -478,8 +583,17 @@ type Writer struct {
 	writer io.Writer
 	// afterWrite runs after a JSON line is written when set.
 	afterWrite func(event any) error
+	// buffer holds the encoded line between encode and write.
+	buffer bytes.Buffer
+	// encoder writes into buffer and is created lazily on first use.
+	encoder *json.Encoder
 }
 
+// maxRetainedBufferSize caps the buffer capacity kept between writes.
+// Large one-off events (such as big tool results) should not pin memory for
+// the rest of the session.
+const maxRetainedBufferSize = 64 * 1024
+
 // NewWriter constructs a stream-json writer.
 func NewWriter(writer io.Writer) *Writer {
 	return &Writer{writer: writer}
@@ -495,17 +609,32 @@ func (w *Writer) SetAfterWrite(afterWrite func(event any) error) {
 
 // Write emits a single event as a JSON line.
 // If the after-write hook fails, the write is treated as failed for callers.
+// Pre-encoded json.RawMessage events are compacted rather than re-marshaled,
+// so multi-line JSON still fits on one line.
 func (w *Writer) Write(event any) error {
-	var buffer bytes.Buffer
-	encoder := json.NewEncoder(&buffer)
-	// Disable HTML escaping to match Claude Code's JSON.stringify output.
-	encoder.SetEscapeHTML(false)
-	if err := encoder.Encode(event); err != nil {
-		return fmt.Errorf("encode stream-json event: %w", err)
-	}
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	if _, err := w.writer.Write(buffer.Bytes()); err != nil {
+
+	w.buffer.Reset()
+	if raw, ok := event.(json.RawMessage); ok {
+		if err := json.Compact(&w.buffer, raw); err != nil {
+			w.buffer.Reset()
+			return fmt.Errorf("encode stream-json event: %w", err)
+		}
+		w.buffer.WriteByte('\n')
+	} else {
+		if w.encoder == nil {
+			w.encoder = json.NewEncoder(&w.buffer)
+			// Disable HTML escaping to match Claude Code's JSON.stringify output.
+			w.encoder.SetEscapeHTML(false)
+		}
+		if err := w.encoder.Encode(event); err != nil {
+			return fmt.Errorf("encode stream-json event: %w", err)
+		}
+	}
+	_, err := w.writer.Write(w.buffer.Bytes())
+	w.releaseBufferLocked()
+	if err != nil {
 		return fmt.Errorf("write stream-json event: %w", err)
 	}
 	if w.afterWrite != nil {
@@ -516,9 +645,38 @@ func (w *Writer) Write(event any) error {
 	return nil
 }
 
+// releaseBufferLocked drops oversized buffers so their memory can be reclaimed.
+// The encoder points at the buffer field, so it stays valid after the swap.
+func (w *Writer) releaseBufferLocked() {
+	if w.buffer.Cap() <= maxRetainedBufferSize {
+		return
+	}
+	w.buffer = bytes.Buffer{}
+}
+
+// uuidPoolSize is how many random bytes one crypto/rand read prefetches:
+// enough for 256 event UUIDs.
+const uuidPoolSize = 16 * 256
+
+// uuidSource buffers crypto/rand for event UUIDs. Every event carries a UUID,
+// so reading the system source once per event shows up on fast streams. The
+// buffer is private to this package rather than enabling uuid.EnableRandPool,
+// which would change UUID generation for the whole process.
+var uuidSource = struct {
+	mu     sync.Mutex
+	reader *bufio.Reader
+}{reader: bufio.NewReaderSize(rand.Reader, uuidPoolSize)}
+
 // NewUUID returns a new UUID string for stream-json events.
 func NewUUID() string {
-	return uuid.NewString()
+	uuidSource.mu.Lock()
+	id, err := uuid.NewRandomFromReader(uuidSource.reader)
+	uuidSource.mu.Unlock()
+	if err != nil {
+		// Fall back to the unpooled source if the buffered read failed.
+		return uuid.NewString()
+	}
+	return id.String()
 }
 
 // StandardServiceTier is the default service tier label in Claude Code output.
@@ -536,7 +694,7 @@ func BuildTextMessage(role string, text string) Message {
 }
 
 // BuildToolUseMessage constructs an assistant message containing tool_use blocks.
-func BuildToolUseMessage(toolCalls []openai.ToolCall) Message {
+func BuildToolUseMessage(toolCalls []llm.ToolCall) Message {
 	blocks := make([]ContentBlock, 0, len(toolCalls))
 	for _, call := range toolCalls {
 		input := map[string]any{}
@@ -573,9 +731,20 @@ func BuildToolResultMessage(toolCallID string, content string, isError bool) Mes
 	}
 }
 
-// BuildAssistantMessage builds an assistant message from an OpenAI message.
-func BuildAssistantMessage(message openai.Message) Message {
+// BuildAssistantMessage builds an assistant message from a provider-agnostic message.
+func BuildAssistantMessage(message llm.Message) Message {
 	var blocks []ContentBlock
+	if message.Reasoning != "" {
+		blocks = append(blocks, ContentBlock{Type: "thinking", Thinking: message.Reasoning})
+	}
+	// Server tools already ran, so they precede the text as in Claude's replies.
+	for _, call := range message.ServerToolCalls {
+		input := map[string]any{}
+		if call.Input != "" && json.Unmarshal([]byte(call.Input), &input) != nil {
+			input["raw"] = call.Input
+		}
+		blocks = append(blocks, ContentBlock{Type: "server_tool_use", ID: call.ID, Name: call.Name, Input: input})
+	}
 	if text, ok := message.Content.(string); ok && text != "" {
 		blocks = append(blocks, ContentBlock{Type: "text", Text: text})
 	}
@@ -603,11 +772,22 @@ func BuildAssistantMessage(message openai.Message) Message {
 	return BuildTextMessage("assistant", string(raw))
 }
 
-// BuildUserMessage builds a user message from an OpenAI message.
-func BuildUserMessage(message openai.Message) Message {
+// BuildUserMessage builds a user message from a provider-agnostic message.
+func BuildUserMessage(message llm.Message) Message {
 	if text, ok := message.Content.(string); ok {
 		return BuildTextMessage("user", text)
 	}
+	if parts := llm.ContentParts(message.Content); len(parts) > 0 {
+		blocks := make([]ContentBlock, 0, len(parts))
+		for _, part := range parts {
+			if part.Type == llm.ContentPartImageURL && part.ImageURL != nil {
+				blocks = append(blocks, imageBlock(part.ImageURL.URL))
+				continue
+			}
+			blocks = append(blocks, ContentBlock{Type: "text", Text: part.Text})
+		}
+		return Message{Type: "message", Role: "user", Content: blocks}
+	}
 	raw, err := json.Marshal(message.Content)
 	if err != nil {
 		return BuildTextMessage("user", fmt.Sprintf("%v", message.Content))
@@ -615,21 +795,23 @@ func BuildUserMessage(message openai.Message) Message {
 	return BuildTextMessage("user", string(raw))
 }
 
-// NewMessageUsageFromOpenAI converts OpenAI usage data into a Claude-style usage payload.
+// NewMessageUsage converts provider usage data into a Claude-style usage payload.
 // Cache and server tool usage fields are zeroed when the gateway does not provide them.
-func NewMessageUsageFromOpenAI(usage openai.Usage, serviceTier string) *MessageUsage {
+// Claude reports input_tokens excluding cache reads and writes, so those are subtracted.
+func NewMessageUsage(usage llm.Usage, serviceTier string) *MessageUsage {
 	var tier *string
 	if serviceTier != "" {
 		tier = StringPointer(serviceTier)
 	}
 	return &MessageUsage{
-		InputTokens:              usage.PromptTokens,
+		InputTokens:              max(usage.PromptTokens-usage.CacheReadTokens-usage.CacheWriteTokens, 0),
 		OutputTokens:             usage.CompletionTokens,
-		CacheCreationInputTokens: 0,
-		CacheReadInputTokens:     0,
+		CacheCreationInputTokens: usage.CacheWriteTokens,
+		CacheReadInputTokens:     usage.CacheReadTokens,
+		ReasoningOutputTokens:    usage.ReasoningTokens,
 		ServerToolUse: MessageServerToolUse{
-			WebSearchRequests: 0,
-			WebFetchRequests:  0,
+			WebSearchRequests: usage.ServerToolUse.WebSearchRequests,
+			WebFetchRequests:  usage.ServerToolUse.WebFetchRequests,
 		},
 		ServiceTier: tier,
 		CacheCreation: MessageCacheCreation{
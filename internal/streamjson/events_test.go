@@ -3,6 +3,7 @@ package streamjson
 import (
 	"testing"
 
+	"github.com/openclaude/openclaude/internal/llm"
 	"github.com/openclaude/openclaude/internal/llm/openai"
 )
 
@@ -42,6 +43,31 @@ func TestBuildAssistantMessageWithToolUse(t *testing.T) {
 	}
 }
 
+func TestBuildAssistantMessageWithServerToolUse(t *testing.T) {
+	// Arrange a reply the provider produced after running a web search.
+	msg := llm.Message{
+		Role:            "assistant",
+		Content:         "Go 1.24 is out.",
+		ServerToolCalls: []llm.ServerToolCall{{ID: "srv_1", Name: "web_search", Input: `{"query":"go"}`}},
+	}
+
+	// Act.
+	built := BuildAssistantMessage(msg)
+
+	// Assert.
+	blocks, ok := built.Content.([]ContentBlock)
+	if !ok || len(blocks) != 2 {
+		t.Fatalf("expected two content blocks, got %+v", built.Content)
+	}
+	input, _ := blocks[0].Input.(map[string]any)
+	if blocks[0].Type != "server_tool_use" || blocks[0].Name != "web_search" || blocks[0].ID != "srv_1" || input["query"] != "go" {
+		t.Fatalf("expected server_tool_use block first, got %+v", blocks[0])
+	}
+	if blocks[1].Type != "text" {
+		t.Fatalf("expected text block after the server tool, got %+v", blocks[1])
+	}
+}
+
 func TestBuildStreamEventsForText(t *testing.T) {
 	// Arrange a short text payload.
 	events := BuildStreamEventsForText("hello", "model-x", "session-1")
@@ -51,3 +77,53 @@ func TestBuildStreamEventsForText(t *testing.T) {
 		t.Fatalf("expected 6 stream events, got %d", len(events))
 	}
 }
+
+func TestConvertUserContentImages(t *testing.T) {
+	// Arrange Claude-style user content with a base64 image and a URL image.
+	content := []any{
+		map[string]any{"type": "text", "text": "what is this?"},
+		map[string]any{"type": "image", "source": map[string]any{"type": "base64", "media_type": "image/png", "data": "AAAA"}},
+		map[string]any{"type": "image", "source": map[string]any{"type": "url", "url": "https://example.com/a.png"}},
+	}
+
+	// Act.
+	converted := ConvertUserContent(content)
+
+	// Assert the provider parts and the stream-json round trip.
+	parts, ok := converted.([]llm.ContentPart)
+	if !ok || len(parts) != 3 {
+		t.Fatalf("expected three content parts, got %#v", converted)
+	}
+	if parts[1].ImageURL.URL != "data:image/png;base64,AAAA" || parts[2].ImageURL.URL != "https://example.com/a.png" {
+		t.Fatalf("unexpected image urls: %#v", parts)
+	}
+	message := BuildUserMessage(llm.Message{Role: "user", Content: parts})
+	blocks, ok := message.Content.([]ContentBlock)
+	if !ok || len(blocks) != 3 || blocks[1].Source == nil || blocks[1].Source.Data != "AAAA" {
+		t.Fatalf("unexpected user message blocks: %#v", message.Content)
+	}
+	if blocks[2].Source.Type != "url" {
+		t.Fatalf("expected url image source, got %#v", blocks[2].Source)
+	}
+
+	// Text-only content keeps the flattened string form.
+	if text := ConvertUserContent([]any{map[string]any{"type": "text", "text": "hi"}}); text != "hi" {
+		t.Fatalf("expected plain text, got %#v", text)
+	}
+}
+
+func TestNewMessageUsageServerToolUse(t *testing.T) {
+	// Arrange gateway usage with cache reads and server-side searches.
+	usage := llm.Usage{PromptTokens: 100, CompletionTokens: 10, CacheReadTokens: 40, ServerToolUse: llm.ServerToolUse{WebSearchRequests: 2, WebFetchRequests: 1}}
+
+	// Act.
+	converted := NewMessageUsage(usage, "")
+
+	// Assert server tool counts pass through instead of being zeroed.
+	if converted.InputTokens != 60 || converted.CacheReadInputTokens != 40 {
+		t.Fatalf("unexpected token counts: %#v", converted)
+	}
+	if converted.ServerToolUse.WebSearchRequests != 2 || converted.ServerToolUse.WebFetchRequests != 1 {
+		t.Fatalf("unexpected server tool use: %#v", converted.ServerToolUse)
+	}
+}
@@ -45,6 +45,12 @@ type openAIStreamState struct {
 	hasTextBlock bool
 	// textBuilder accumulates streamed text.
 	textBuilder strings.Builder
+	// thinkingBlockIndex is the index of the thinking block in blocks.
+	thinkingBlockIndex int
+	// hasThinkingBlock reports whether the thinking block exists.
+	hasThinkingBlock bool
+	// thinkingBuilder accumulates streamed reasoning text.
+	thinkingBuilder strings.Builder
 	// toolBlockIndex maps tool call index to block index.
 	toolBlockIndex map[int]int
 	// toolBlocks stores tool call state keyed by tool index.
@@ -55,7 +61,7 @@ type openAIStreamState struct {
 
 // streamBlock represents a content block in message order.
 type streamBlock struct {
-	// kind is either "text" or "tool_use".
+	// kind is "thinking", "text", or "tool_use".
 	kind string
 	// toolIndex links tool_use blocks to their tool call index.
 	toolIndex int
@@ -89,14 +95,15 @@ func NewOpenAIStreamEmitter(writer *Writer, includePartials bool, sessionID stri
 // Begin resets state for a new assistant message stream.
 func (emitter *OpenAIStreamEmitter) Begin(model string) {
 	emitter.state = &openAIStreamState{
-		writer:          emitter.writer,
-		includePartials: emitter.includePartials,
-		sessionID:       emitter.sessionID,
-		model:           model,
-		messageID:       NewUUID(),
-		textBlockIndex:  -1,
-		toolBlockIndex:  map[int]int{},
-		toolBlocks:      map[int]*toolBlockState{},
+		writer:             emitter.writer,
+		includePartials:    emitter.includePartials,
+		sessionID:          emitter.sessionID,
+		model:              model,
+		messageID:          NewUUID(),
+		textBlockIndex:     -1,
+		thinkingBlockIndex: -1,
+		toolBlockIndex:     map[int]int{},
+		toolBlocks:         map[int]*toolBlockState{},
 	}
 }
 
@@ -132,6 +139,30 @@ func (state *openAIStreamState) Handle(event openai.StreamResponse) error {
 			continue
 		}
 		delta := choice.Delta
+		if reasoning := delta.ReasoningText(); reasoning != "" {
+			if err := state.ensureMessageStarted(); err != nil {
+				return err
+			}
+			if err := state.ensureThinkingBlock(); err != nil {
+				return err
+			}
+			state.thinkingBuilder.WriteString(reasoning)
+			if state.includePartials {
+				if err := state.write(StreamEvent{
+					Type: "stream_event",
+					Event: ContentBlockDeltaEvent{
+						Type:  "content_block_delta",
+						Index: state.thinkingBlockIndex,
+						Delta: StreamDelta{
+							Type:     "thinking_delta",
+							Thinking: reasoning,
+						},
+					},
+				}); err != nil {
+					return err
+				}
+			}
+		}
 		if delta.Content != "" {
 			if err := state.ensureMessageStarted(); err != nil {
 				return err
@@ -248,6 +279,29 @@ func (state *openAIStreamState) ensureMessageStarted() error {
 	})
 }
 
+// ensureThinkingBlock allocates the thinking block when reasoning deltas appear.
+func (state *openAIStreamState) ensureThinkingBlock() error {
+	if state.hasThinkingBlock {
+		return nil
+	}
+	state.thinkingBlockIndex = len(state.blocks)
+	state.blocks = append(state.blocks, streamBlock{kind: "thinking"})
+	state.hasThinkingBlock = true
+	if !state.includePartials {
+		return nil
+	}
+	return state.write(StreamEvent{
+		Type: "stream_event",
+		Event: ContentBlockStartEvent{
+			Type:  "content_block_start",
+			Index: state.thinkingBlockIndex,
+			ContentBlock: ContentBlock{
+				Type: "thinking",
+			},
+		},
+	})
+}
+
 // ensureTextBlock allocates the text block when streaming text appears.
 func (state *openAIStreamState) ensureTextBlock() error {
 	if state.hasTextBlock {
@@ -320,10 +374,7 @@ func (state *openAIStreamState) ensureToolBlock(
 func (state *openAIStreamState) stopBlocks() error {
 	for blockIndex, block := range state.blocks {
 		switch block.kind {
-		case "text":
-			if !state.hasTextBlock {
-				continue
-			}
+		case "thinking", "text":
 			if err := state.write(StreamEvent{
 				Type: "stream_event",
 				Event: ContentBlockStopEvent{
@@ -358,6 +409,15 @@ func (state *openAIStreamState) buildMessage() (Message, bool, error) {
 	var blocks []ContentBlock
 	for _, block := range state.blocks {
 		switch block.kind {
+		case "thinking":
+			thinking := state.thinkingBuilder.String()
+			if thinking == "" {
+				continue
+			}
+			blocks = append(blocks, ContentBlock{
+				Type:     "thinking",
+				Thinking: thinking,
+			})
 		case "text":
 			text := state.textBuilder.String()
 			if text == "" {
@@ -130,6 +130,40 @@ func TestOpenAIStreamEmitterTool(testingHandle *testing.T) {
 	testutil.RequireEqual(testingHandle, gotLines, wantLines, "stream tool output mismatch")
 }
 
+// TestOpenAIStreamEmitterThinking verifies reasoning deltas become a leading thinking block.
+func TestOpenAIStreamEmitterThinking(testingHandle *testing.T) {
+	// Arrange a stream emitter with a buffered writer.
+	var buffer bytes.Buffer
+	writer := NewWriter(&buffer)
+	emitter := NewOpenAIStreamEmitter(writer, true, "session-1")
+	emitter.Begin("model-x")
+
+	// Mix both reasoning field spellings used by gateways.
+	events := []openai.StreamResponse{
+		{Choices: []openai.StreamChoice{{Index: 0, Delta: openai.StreamDelta{ReasoningContent: "Check the "}}}},
+		{Choices: []openai.StreamChoice{{Index: 0, Delta: openai.StreamDelta{Reasoning: "greeting."}}}},
+		{Choices: []openai.StreamChoice{{Index: 0, Delta: openai.StreamDelta{Content: "Hi"}}}},
+		{Choices: []openai.StreamChoice{{Index: 0, Delta: openai.StreamDelta{}, FinishReason: stringPointer("stop")}}},
+	}
+
+	for _, event := range events {
+		testutil.RequireNoError(testingHandle, emitter.Handle(event), "emit thinking stream event")
+	}
+
+	message, ok, err := emitter.Finalize()
+	testutil.RequireNoError(testingHandle, err, "finalize thinking stream")
+	testutil.RequireTrue(testingHandle, ok, "expected a finalized thinking message")
+	testutil.RequireEqual(testingHandle, message.Content, []ContentBlock{
+		{Type: "thinking", Thinking: "Check the greeting."},
+		{Type: "text", Text: "Hi"},
+	}, "assistant content blocks")
+
+	gotLines := normalizeStreamJSONLines(testingHandle, buffer.Bytes())
+	wantLines := loadFixtureLines(testingHandle, "stream_thinking.jsonl")
+
+	testutil.RequireEqual(testingHandle, gotLines, wantLines, "stream thinking output mismatch")
+}
+
 // normalizeStreamJSONLines replaces unstable fields before comparisons.
 func normalizeStreamJSONLines(testingHandle *testing.T, output []byte) []any {
 	testingHandle.Helper()
@@ -0,0 +1,235 @@
+package streamjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/openclaude/openclaude/internal/llm/openai"
+)
+
+func TestWriterReusesEncoderAcrossEvents(t *testing.T) {
+	// Arrange a writer and two events with HTML-sensitive characters.
+	var out bytes.Buffer
+	writer := NewWriter(&out)
+
+	// Act.
+	if err := writer.Write(map[string]string{"text": "<a>&b"}); err != nil {
+		t.Fatalf("write first event: %v", err)
+	}
+	if err := writer.Write(map[string]string{"text": "second"}); err != nil {
+		t.Fatalf("write second event: %v", err)
+	}
+
+	// Assert each event is its own line and HTML escaping stays disabled.
+	want := "{\"text\":\"<a>&b\"}\n{\"text\":\"second\"}\n"
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
+
+func TestWriterPassesRawMessagesThrough(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "without newline", raw: `{"b":1,"a":2}`, want: "{\"b\":1,\"a\":2}\n"},
+		{name: "with newline", raw: "{\"a\":1}\n", want: "{\"a\":1}\n"},
+		{name: "multi-line", raw: "{\n  \"a\": [1,\n 2],\n  \"s\": \"x y\"\n}", want: "{\"a\":[1,2],\"s\":\"x y\"}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			writer := NewWriter(&out)
+			if err := writer.Write(json.RawMessage(tt.raw)); err != nil {
+				t.Fatalf("write raw event: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, out.String())
+			}
+		})
+	}
+}
+
+func TestWriterRejectsInvalidRawMessages(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewWriter(&out)
+	if err := writer.Write(json.RawMessage(`{"a":`)); err == nil {
+		t.Fatalf("expected an error for invalid JSON")
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing written, got %q", out.String())
+	}
+}
+
+func TestNewUUIDReturnsDistinctVersion4UUIDs(t *testing.T) {
+	// Draw more UUIDs than one pool refill holds.
+	seen := make(map[string]bool)
+	for i := 0; i < uuidPoolSize/16*2+1; i++ {
+		id, err := uuid.Parse(NewUUID())
+		if err != nil {
+			t.Fatalf("parse uuid: %v", err)
+		}
+		if id.Version() != 4 || id.Variant() != uuid.RFC4122 {
+			t.Fatalf("expected a version 4 RFC 4122 uuid, got %s", id)
+		}
+		if seen[id.String()] {
+			t.Fatalf("duplicate uuid %s", id)
+		}
+		seen[id.String()] = true
+	}
+}
+
+func TestWriterReleasesOversizedBuffers(t *testing.T) {
+	// Arrange a payload larger than the retention cap.
+	writer := NewWriter(io.Discard)
+	large := strings.Repeat("x", maxRetainedBufferSize*2)
+
+	// Act.
+	if err := writer.Write(map[string]string{"text": large}); err != nil {
+		t.Fatalf("write large event: %v", err)
+	}
+
+	// Assert the buffer was not retained, and the writer still works.
+	if writer.buffer.Cap() > maxRetainedBufferSize {
+		t.Fatalf("expected buffer to be released, cap=%d", writer.buffer.Cap())
+	}
+	var out bytes.Buffer
+	writer.writer = &out
+	if err := writer.Write(map[string]int{"n": 1}); err != nil {
+		t.Fatalf("write after release: %v", err)
+	}
+	if out.String() != "{\"n\":1}\n" {
+		t.Fatalf("unexpected output after release: %q", out.String())
+	}
+}
+
+// BenchmarkWriterTextDelta measures the hot path for partial text deltas.
+func BenchmarkWriterTextDelta(b *testing.B) {
+	writer := NewWriter(io.Discard)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		event := StreamEvent{
+			Type: "stream_event",
+			Event: ContentBlockDeltaEvent{
+				Type:  "content_block_delta",
+				Index: 0,
+				Delta: StreamDelta{Type: "text_delta", Text: "hello "},
+			},
+			SessionID: "session-1",
+			UUID:      NewUUID(),
+		}
+		if err := writer.Write(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkWriterAssistantMessage measures emitting a full assistant message.
+func BenchmarkWriterAssistantMessage(b *testing.B) {
+	writer := NewWriter(io.Discard)
+	message := BuildAssistantMessage(openai.Message{
+		Role:    "assistant",
+		Content: strings.Repeat("lorem ipsum ", 512),
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		event := AssistantEvent{
+			Type:      "assistant",
+			Message:   message,
+			SessionID: "session-1",
+			UUID:      NewUUID(),
+		}
+		if err := writer.Write(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// storedUserLine builds a persisted user event like the ones replayed by
+// --replay-user-messages, with a large text block.
+func storedUserLine(b *testing.B) string {
+	b.Helper()
+	line, err := json.Marshal(UserEvent{
+		Type:      "user",
+		Message:   BuildTextMessage("user", strings.Repeat("lorem ipsum ", 512)),
+		SessionID: "session-1",
+		UUID:      NewUUID(),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return string(line)
+}
+
+// BenchmarkWriterRawMessage measures the pre-encoded passthrough path used to
+// replay stored lines.
+func BenchmarkWriterRawMessage(b *testing.B) {
+	writer := NewWriter(io.Discard)
+	line := storedUserLine(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := writer.Write(json.RawMessage(line)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkWriterRemarshaledMessage is the baseline for
+// BenchmarkWriterRawMessage: decoding a stored line and encoding it again.
+func BenchmarkWriterRemarshaledMessage(b *testing.B) {
+	writer := NewWriter(io.Discard)
+	line := storedUserLine(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			b.Fatal(err)
+		}
+		if err := writer.Write(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkOpenAIStreamEmitterDeltas measures translating OpenAI chunks to stream events.
+func BenchmarkOpenAIStreamEmitterDeltas(b *testing.B) {
+	emitter := NewOpenAIStreamEmitter(NewWriter(io.Discard), true, "session-1")
+	emitter.Begin("model-x")
+	chunk := openai.StreamResponse{
+		Choices: []openai.StreamChoice{{Delta: openai.StreamDelta{Content: "token "}}},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := emitter.Handle(chunk); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkNewUUID measures event UUID generation.
+func BenchmarkNewUUID(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewUUID()
+	}
+}
+
+// BenchmarkNewUUIDUnpooled is the baseline for BenchmarkNewUUID: one
+// crypto/rand read per UUID.
+func BenchmarkNewUUIDUnpooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = uuid.NewString()
+	}
+}
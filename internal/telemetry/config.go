@@ -0,0 +1,149 @@
+// Package telemetry exports usage metrics and events over OTLP/HTTP JSON.
+// Configuration mirrors Claude Code's OpenTelemetry environment variables.
+package telemetry
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ExporterOTLP sends data to an OTLP/HTTP collector.
+	ExporterOTLP = "otlp"
+	// ExporterConsole writes OTLP JSON payloads to the console writer.
+	ExporterConsole = "console"
+	// defaultOTLPEndpoint is the standard local collector address for OTLP/HTTP.
+	defaultOTLPEndpoint = "http://localhost:4318"
+	// defaultMetricInterval matches the OpenTelemetry SDK default export interval.
+	defaultMetricInterval = 60 * time.Second
+	// defaultLogInterval matches Claude Code's default logs export interval.
+	defaultLogInterval = 5 * time.Second
+)
+
+// Config describes where and how often telemetry is exported.
+type Config struct {
+	// MetricsExporter is otlp, console, or empty when metrics are off.
+	MetricsExporter string
+	// LogsExporter is otlp, console, or empty when events are off.
+	LogsExporter string
+	// MetricsEndpoint is the full OTLP metrics URL (e.g., .../v1/metrics).
+	MetricsEndpoint string
+	// LogsEndpoint is the full OTLP logs URL (e.g., .../v1/logs).
+	LogsEndpoint string
+	// Headers are added to every export request (often credentials; never logged).
+	Headers map[string]string
+	// MetricInterval is the metrics export period.
+	MetricInterval time.Duration
+	// LogInterval is the events export period.
+	LogInterval time.Duration
+	// ResourceAttributes are attached to every exported resource.
+	ResourceAttributes map[string]string
+	// LogUserPrompts includes prompt text in user_prompt events (length only otherwise).
+	LogUserPrompts bool
+	// ServiceVersion is reported as service.version.
+	ServiceVersion string
+}
+
+// ConfigFromEnv reads telemetry settings through lookup, which typically
+// layers the settings "env" block over the process environment.
+// It reports false when telemetry is disabled or no exporter is selected.
+func ConfigFromEnv(lookup func(string) (string, bool)) (Config, bool, error) {
+	get := func(name string) string {
+		value, _ := lookup(name)
+		return strings.TrimSpace(value)
+	}
+	if !envEnabled(get("CLAUDE_CODE_ENABLE_TELEMETRY")) {
+		return Config{}, false, nil
+	}
+
+	cfg := Config{
+		MetricsExporter:    exporterName(get("OTEL_METRICS_EXPORTER")),
+		LogsExporter:       exporterName(get("OTEL_LOGS_EXPORTER")),
+		MetricInterval:     defaultMetricInterval,
+		LogInterval:        defaultLogInterval,
+		Headers:            parseKeyValues(get("OTEL_EXPORTER_OTLP_HEADERS")),
+		ResourceAttributes: parseKeyValues(get("OTEL_RESOURCE_ATTRIBUTES")),
+		LogUserPrompts:     envEnabled(get("OTEL_LOG_USER_PROMPTS")),
+	}
+	if cfg.MetricsExporter == "" && cfg.LogsExporter == "" {
+		return Config{}, false, nil
+	}
+	// Only the JSON encoding of OTLP/HTTP is implemented; collectors accept it on the same port.
+	if protocol := get("OTEL_EXPORTER_OTLP_PROTOCOL"); protocol != "" && protocol != "http/json" {
+		return Config{}, false, fmt.Errorf("OTEL_EXPORTER_OTLP_PROTOCOL %q is not supported; use http/json", protocol)
+	}
+
+	base := get("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if base == "" {
+		base = defaultOTLPEndpoint
+	}
+	base = strings.TrimRight(base, "/")
+	cfg.MetricsEndpoint = get("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT")
+	if cfg.MetricsEndpoint == "" {
+		cfg.MetricsEndpoint = base + "/v1/metrics"
+	}
+	cfg.LogsEndpoint = get("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT")
+	if cfg.LogsEndpoint == "" {
+		cfg.LogsEndpoint = base + "/v1/logs"
+	}
+	for _, endpoint := range []string{cfg.MetricsEndpoint, cfg.LogsEndpoint} {
+		if parsed, err := url.Parse(endpoint); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return Config{}, false, fmt.Errorf("invalid OTLP endpoint %q", endpoint)
+		}
+	}
+
+	for name, target := range map[string]*time.Duration{
+		"OTEL_METRIC_EXPORT_INTERVAL": &cfg.MetricInterval,
+		"OTEL_LOGS_EXPORT_INTERVAL":   &cfg.LogInterval,
+	} {
+		if raw := get(name); raw != "" {
+			millis, err := strconv.Atoi(raw)
+			if err != nil || millis <= 0 {
+				return Config{}, false, fmt.Errorf("%s must be a positive number of milliseconds", name)
+			}
+			*target = time.Duration(millis) * time.Millisecond
+		}
+	}
+	return cfg, true, nil
+}
+
+// envEnabled reports whether a flag variable is set to a truthy value.
+func envEnabled(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
+
+// exporterName normalizes an OTEL_*_EXPORTER value; "none" and unknown values disable the signal.
+func exporterName(value string) string {
+	for _, name := range strings.Split(strings.ToLower(value), ",") {
+		switch name = strings.TrimSpace(name); name {
+		case ExporterOTLP, ExporterConsole:
+			return name
+		}
+	}
+	return ""
+}
+
+// parseKeyValues parses the "key1=value1,key2=value2" format used by OTEL variables.
+func parseKeyValues(value string) map[string]string {
+	pairs := map[string]string{}
+	for _, item := range strings.Split(value, ",") {
+		key, rawValue, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(strings.TrimSpace(rawValue)); err == nil {
+			rawValue = decoded
+		}
+		pairs[key] = strings.TrimSpace(rawValue)
+	}
+	return pairs
+}
@@ -0,0 +1,423 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/llm/openai"
+)
+
+const (
+	// serviceName is reported as service.name.
+	serviceName = "openclaude"
+	// meterName is the instrumentation scope for metrics and events.
+	meterName = "com.anthropic.claude_code"
+	// maxPendingEvents caps buffered events when the collector is unreachable.
+	maxPendingEvents = 1000
+)
+
+// Metric names match Claude Code's so existing dashboards keep working.
+const (
+	metricSessionCount = "claude_code.session.count"
+	metricTokenUsage   = "claude_code.token.usage"
+	metricCostUsage    = "claude_code.cost.usage"
+)
+
+// Exporter aggregates usage counters and buffers events, exporting both
+// periodically and on Shutdown. It is safe for concurrent use, since
+// subagents record from parallel goroutines.
+type Exporter struct {
+	cfg       Config
+	sessionID string
+	client    *http.Client
+	console   io.Writer
+	startTime time.Time
+
+	mu       sync.Mutex
+	counters map[counterKey]float64
+	events   []logRecord
+	stop     chan struct{}
+	done     sync.WaitGroup
+}
+
+// counterKey identifies one cumulative counter series.
+type counterKey struct {
+	name       string
+	model      string
+	tokenType  string
+	unit       string
+	descriptor string
+}
+
+// logRecord is one buffered event.
+type logRecord struct {
+	time       time.Time
+	name       string
+	attributes map[string]any
+}
+
+// NewExporter creates an exporter for one CLI session. A nil client uses a
+// client with a 10s timeout; console receives payloads for the console exporter.
+func NewExporter(cfg Config, sessionID string, client *http.Client, console io.Writer) *Exporter {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if console == nil {
+		console = io.Discard
+	}
+	return &Exporter{
+		cfg:       cfg,
+		sessionID: sessionID,
+		client:    client,
+		console:   console,
+		startTime: time.Now(),
+		counters:  map[counterKey]float64{},
+	}
+}
+
+// Start begins periodic export. Call Shutdown to stop it and flush.
+func (e *Exporter) Start() {
+	e.stop = make(chan struct{})
+	for _, loop := range []struct {
+		interval time.Duration
+		export   func(context.Context) error
+	}{
+		{e.cfg.MetricInterval, e.exportMetrics},
+		{e.cfg.LogInterval, e.exportLogs},
+	} {
+		if loop.interval <= 0 {
+			continue
+		}
+		e.done.Add(1)
+		go func(interval time.Duration, export func(context.Context) error) {
+			defer e.done.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-e.stop:
+					return
+				case <-ticker.C:
+					// Periodic failures are retried on the next tick; Shutdown reports the last one.
+					_ = export(context.Background())
+				}
+			}
+		}(loop.interval, loop.export)
+	}
+}
+
+// Shutdown stops periodic export and flushes remaining metrics and events.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	if e.stop != nil {
+		close(e.stop)
+		e.done.Wait()
+		e.stop = nil
+	}
+	metricsErr := e.exportMetrics(ctx)
+	logsErr := e.exportLogs(ctx)
+	if metricsErr != nil {
+		return metricsErr
+	}
+	return logsErr
+}
+
+// RecordSession counts the start of a CLI session.
+func (e *Exporter) RecordSession() {
+	e.add(counterKey{name: metricSessionCount, unit: "count", descriptor: "Count of CLI sessions started"}, 1)
+}
+
+// RecordUserPrompt records a prompt event; the text is included only when
+// OTEL_LOG_USER_PROMPTS is enabled.
+func (e *Exporter) RecordUserPrompt(prompt string) {
+	attributes := map[string]any{"prompt_length": len(prompt)}
+	if e.cfg.LogUserPrompts {
+		attributes["prompt"] = prompt
+	} else {
+		attributes["prompt"] = "<REDACTED>"
+	}
+	e.event("claude_code.user_prompt", attributes)
+}
+
+// RecordAPIRequest records token and cost counters plus an api_request event.
+func (e *Exporter) RecordAPIRequest(model string, usage llm.Usage, costUSD float64, duration time.Duration) {
+	input := uncachedInputTokens(usage)
+	for _, series := range []struct {
+		tokenType string
+		count     int
+	}{
+		{"input", input},
+		{"output", usage.CompletionTokens},
+		{"cacheRead", usage.CacheReadTokens},
+		{"cacheCreation", usage.CacheWriteTokens},
+	} {
+		if series.count > 0 {
+			e.add(counterKey{name: metricTokenUsage, model: model, tokenType: series.tokenType, unit: "tokens", descriptor: "Number of tokens used"}, float64(series.count))
+		}
+	}
+	if costUSD > 0 {
+		e.add(counterKey{name: metricCostUsage, model: model, unit: "USD", descriptor: "Cost of the session in USD"}, costUSD)
+	}
+	e.event("claude_code.api_request", map[string]any{
+		"model":                 model,
+		"cost_usd":              costUSD,
+		"duration_ms":           duration.Milliseconds(),
+		"input_tokens":          input,
+		"output_tokens":         usage.CompletionTokens,
+		"cache_read_tokens":     usage.CacheReadTokens,
+		"cache_creation_tokens": usage.CacheWriteTokens,
+	})
+}
+
+// uncachedInputTokens returns prompt tokens excluding cache reads and writes,
+// which are reported as their own types.
+func uncachedInputTokens(usage llm.Usage) int {
+	input := usage.PromptTokens - usage.CacheReadTokens - usage.CacheWriteTokens
+	if input < 0 {
+		return usage.PromptTokens
+	}
+	return input
+}
+
+// RecordAPIError records a failed model request.
+func (e *Exporter) RecordAPIError(model string, err error, duration time.Duration) {
+	message := err.Error()
+	if runes := []rune(message); len(runes) > 500 {
+		message = string(runes[:500]) + "…"
+	}
+	attributes := map[string]any{
+		"model":       model,
+		"error":       message,
+		"duration_ms": duration.Milliseconds(),
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		attributes["status_code"] = strconv.Itoa(apiErr.StatusCode)
+	}
+	e.event("claude_code.api_error", attributes)
+}
+
+// RecordToolResult records a completed tool call.
+func (e *Exporter) RecordToolResult(toolName string, success bool, duration time.Duration) {
+	e.event("claude_code.tool_result", map[string]any{
+		"tool_name":   toolName,
+		"success":     strconv.FormatBool(success),
+		"duration_ms": duration.Milliseconds(),
+	})
+}
+
+// add increments a counter when metrics are enabled.
+func (e *Exporter) add(key counterKey, value float64) {
+	if e.cfg.MetricsExporter == "" {
+		return
+	}
+	e.mu.Lock()
+	e.counters[key] += value
+	e.mu.Unlock()
+}
+
+// event buffers an event when logs are enabled, dropping the oldest beyond the cap.
+func (e *Exporter) event(name string, attributes map[string]any) {
+	if e.cfg.LogsExporter == "" {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.events) >= maxPendingEvents {
+		e.events = e.events[1:]
+	}
+	e.events = append(e.events, logRecord{time: time.Now(), name: name, attributes: attributes})
+}
+
+// exportMetrics sends every counter as a cumulative monotonic sum.
+func (e *Exporter) exportMetrics(ctx context.Context) error {
+	if e.cfg.MetricsExporter == "" {
+		return nil
+	}
+	e.mu.Lock()
+	keys := make([]counterKey, 0, len(e.counters))
+	for key := range e.counters {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	now := time.Now()
+	metrics := []map[string]any{}
+	byName := map[string]int{}
+	for _, key := range keys {
+		attributes := e.standardAttributes()
+		if key.model != "" {
+			attributes = append(attributes, attribute("model", key.model))
+		}
+		if key.tokenType != "" {
+			attributes = append(attributes, attribute("type", key.tokenType))
+		}
+		point := map[string]any{
+			"attributes":        attributes,
+			"startTimeUnixNano": unixNano(e.startTime),
+			"timeUnixNano":      unixNano(now),
+			"asDouble":          e.counters[key],
+		}
+		index, ok := byName[key.name]
+		if !ok {
+			index = len(metrics)
+			byName[key.name] = index
+			metrics = append(metrics, map[string]any{
+				"name":        key.name,
+				"description": key.descriptor,
+				"unit":        key.unit,
+				"sum": map[string]any{
+					"aggregationTemporality": 2, // cumulative
+					"isMonotonic":            true,
+					"dataPoints":             []map[string]any{},
+				},
+			})
+		}
+		sum := metrics[index]["sum"].(map[string]any)
+		sum["dataPoints"] = append(sum["dataPoints"].([]map[string]any), point)
+	}
+	e.mu.Unlock()
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	payload := map[string]any{"resourceMetrics": []map[string]any{{
+		"resource":     map[string]any{"attributes": e.resourceAttributes()},
+		"scopeMetrics": []map[string]any{{"scope": map[string]any{"name": meterName}, "metrics": metrics}},
+	}}}
+	return e.send(ctx, e.cfg.MetricsExporter, e.cfg.MetricsEndpoint, payload)
+}
+
+// exportLogs sends and clears buffered events; failed batches are re-queued.
+func (e *Exporter) exportLogs(ctx context.Context) error {
+	if e.cfg.LogsExporter == "" {
+		return nil
+	}
+	e.mu.Lock()
+	events := e.events
+	e.events = nil
+	e.mu.Unlock()
+	if len(events) == 0 {
+		return nil
+	}
+
+	records := make([]map[string]any, 0, len(events))
+	for _, record := range events {
+		attributes := append(e.standardAttributes(),
+			attribute("event.name", record.name),
+			attribute("event.timestamp", record.time.UTC().Format(time.RFC3339Nano)))
+		names := make([]string, 0, len(record.attributes))
+		for name := range record.attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			attributes = append(attributes, attribute(name, record.attributes[name]))
+		}
+		records = append(records, map[string]any{
+			"timeUnixNano": unixNano(record.time),
+			"body":         map[string]any{"stringValue": record.name},
+			"attributes":   attributes,
+		})
+	}
+	payload := map[string]any{"resourceLogs": []map[string]any{{
+		"resource":  map[string]any{"attributes": e.resourceAttributes()},
+		"scopeLogs": []map[string]any{{"scope": map[string]any{"name": meterName}, "logRecords": records}},
+	}}}
+	if err := e.send(ctx, e.cfg.LogsExporter, e.cfg.LogsEndpoint, payload); err != nil {
+		e.mu.Lock()
+		e.events = append(events, e.events...)
+		if len(e.events) > maxPendingEvents {
+			e.events = e.events[len(e.events)-maxPendingEvents:]
+		}
+		e.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// send posts an OTLP JSON payload or writes it to the console writer.
+func (e *Exporter) send(ctx context.Context, exporter string, endpoint string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if exporter == ExporterConsole {
+		_, err := fmt.Fprintf(e.console, "%s\n", body)
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		// Report the endpoint only; the request headers may carry credentials.
+		return fmt.Errorf("otlp export to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp export to %s: %s", endpoint, resp.Status)
+	}
+	return nil
+}
+
+// standardAttributes are attached to every data point and event.
+func (e *Exporter) standardAttributes() []map[string]any {
+	return []map[string]any{attribute("session.id", e.sessionID)}
+}
+
+// resourceAttributes describe the exporting process plus OTEL_RESOURCE_ATTRIBUTES.
+func (e *Exporter) resourceAttributes() []map[string]any {
+	attributes := []map[string]any{
+		attribute("service.name", serviceName),
+		attribute("service.version", e.cfg.ServiceVersion),
+	}
+	names := make([]string, 0, len(e.cfg.ResourceAttributes))
+	for name := range e.cfg.ResourceAttributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attributes = append(attributes, attribute(name, e.cfg.ResourceAttributes[name]))
+	}
+	return attributes
+}
+
+// attribute encodes one OTLP KeyValue.
+func attribute(key string, value any) map[string]any {
+	var encoded map[string]any
+	switch typed := value.(type) {
+	case int:
+		encoded = map[string]any{"intValue": strconv.Itoa(typed)}
+	case int64:
+		encoded = map[string]any{"intValue": strconv.FormatInt(typed, 10)}
+	case float64:
+		encoded = map[string]any{"doubleValue": typed}
+	case bool:
+		encoded = map[string]any{"boolValue": typed}
+	default:
+		encoded = map[string]any{"stringValue": fmt.Sprint(typed)}
+	}
+	return map[string]any{"key": key, "value": encoded}
+}
+
+// unixNano encodes a timestamp the way OTLP JSON expects (a decimal string).
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
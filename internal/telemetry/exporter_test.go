@@ -0,0 +1,171 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestConfigFromEnv verifies defaults, overrides, and validation of OTEL variables.
+func TestConfigFromEnv(testingHandle *testing.T) {
+	lookup := func(values map[string]string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		}
+	}
+
+	_, enabled, err := ConfigFromEnv(lookup(map[string]string{"OTEL_METRICS_EXPORTER": "otlp"}))
+	testutil.RequireNoError(testingHandle, err, "config without opt-in")
+	testutil.RequireTrue(testingHandle, !enabled, "telemetry requires CLAUDE_CODE_ENABLE_TELEMETRY")
+
+	cfg, enabled, err := ConfigFromEnv(lookup(map[string]string{
+		"CLAUDE_CODE_ENABLE_TELEMETRY":     "1",
+		"OTEL_METRICS_EXPORTER":            "otlp",
+		"OTEL_LOGS_EXPORTER":               "none",
+		"OTEL_EXPORTER_OTLP_ENDPOINT":      "https://collector.example.com/",
+		"OTEL_EXPORTER_OTLP_HEADERS":       "Authorization=Bearer%20token,x-team=platform",
+		"OTEL_METRIC_EXPORT_INTERVAL":      "10000",
+		"OTEL_RESOURCE_ATTRIBUTES":         "department=eng,team.id=platform",
+		"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT": "https://logs.example.com/v1/logs",
+		"OTEL_EXPORTER_OTLP_PROTOCOL":      "http/json",
+		"OTEL_LOG_USER_PROMPTS":            "0",
+	}))
+	testutil.RequireNoError(testingHandle, err, "config")
+	testutil.RequireTrue(testingHandle, enabled, "telemetry enabled")
+	testutil.RequireEqual(testingHandle, cfg.MetricsExporter, ExporterOTLP, "metrics exporter")
+	testutil.RequireEqual(testingHandle, cfg.LogsExporter, "", "logs exporter none")
+	testutil.RequireEqual(testingHandle, cfg.MetricsEndpoint, "https://collector.example.com/v1/metrics", "metrics endpoint")
+	testutil.RequireEqual(testingHandle, cfg.LogsEndpoint, "https://logs.example.com/v1/logs", "logs endpoint override")
+	testutil.RequireEqual(testingHandle, cfg.Headers["Authorization"], "Bearer token", "decoded header")
+	testutil.RequireEqual(testingHandle, cfg.MetricInterval, 10*time.Second, "metric interval")
+	testutil.RequireEqual(testingHandle, cfg.LogInterval, defaultLogInterval, "default log interval")
+	testutil.RequireEqual(testingHandle, cfg.ResourceAttributes["team.id"], "platform", "resource attribute")
+
+	_, _, err = ConfigFromEnv(lookup(map[string]string{
+		"CLAUDE_CODE_ENABLE_TELEMETRY": "1",
+		"OTEL_METRICS_EXPORTER":        "otlp",
+		"OTEL_EXPORTER_OTLP_PROTOCOL":  "grpc",
+	}))
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "http/json"), "grpc is rejected")
+}
+
+// otlpCollector captures OTLP JSON requests by path.
+type otlpCollector struct {
+	mu       sync.Mutex
+	payloads map[string][]map[string]any
+	headers  http.Header
+}
+
+func (c *otlpCollector) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+	var payload map[string]any
+	_ = json.Unmarshal(body, &payload)
+	c.mu.Lock()
+	c.payloads[request.URL.Path] = append(c.payloads[request.URL.Path], payload)
+	c.headers = request.Header.Clone()
+	c.mu.Unlock()
+	writer.WriteHeader(http.StatusOK)
+}
+
+// payloadText re-encodes a captured payload without HTML escaping for substring checks.
+func payloadText(payload map[string]any) string {
+	var builder strings.Builder
+	encoder := json.NewEncoder(&builder)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(payload)
+	return builder.String()
+}
+
+// TestExporterSendsMetricsAndLogs verifies counters and events reach the collector on Shutdown.
+func TestExporterSendsMetricsAndLogs(testingHandle *testing.T) {
+	collector := &otlpCollector{payloads: map[string][]map[string]any{}}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	exporter := NewExporter(Config{
+		MetricsExporter:    ExporterOTLP,
+		LogsExporter:       ExporterOTLP,
+		MetricsEndpoint:    server.URL + "/v1/metrics",
+		LogsEndpoint:       server.URL + "/v1/logs",
+		Headers:            map[string]string{"X-Api-Key": "secret"},
+		ResourceAttributes: map[string]string{"team": "platform"},
+		ServiceVersion:     "1.2.3",
+	}, "session-1", server.Client(), nil)
+	exporter.RecordSession()
+	exporter.RecordUserPrompt("fix the bug")
+	exporter.RecordAPIRequest("gpt-4o", llm.Usage{PromptTokens: 120, CompletionTokens: 30, CacheReadTokens: 20}, 0.5, 1500*time.Millisecond)
+	exporter.RecordAPIRequest("gpt-4o", llm.Usage{PromptTokens: 100, CompletionTokens: 10}, 0.25, time.Second)
+	exporter.RecordToolResult("Bash", true, 200*time.Millisecond)
+	exporter.RecordAPIError("gpt-4o", errors.New("boom"), time.Second)
+	testutil.RequireNoError(testingHandle, exporter.Shutdown(context.Background()), "shutdown")
+
+	testutil.RequireEqual(testingHandle, collector.headers.Get("X-Api-Key"), "secret", "export headers")
+	testutil.RequireEqual(testingHandle, len(collector.payloads["/v1/metrics"]), 1, "metrics requests")
+	metrics := payloadText(collector.payloads["/v1/metrics"][0])
+	for _, expected := range []string{
+		`"name":"claude_code.session.count"`,
+		`"name":"claude_code.token.usage"`,
+		`"name":"claude_code.cost.usage"`,
+		`"asDouble":0.75`,
+		// 120 prompt tokens minus 20 cached plus 100.
+		`"asDouble":200`,
+		`{"key":"type","value":{"stringValue":"cacheRead"}}`,
+		`{"key":"service.version","value":{"stringValue":"1.2.3"}}`,
+		`{"key":"team","value":{"stringValue":"platform"}}`,
+		`{"key":"session.id","value":{"stringValue":"session-1"}}`,
+	} {
+		testutil.RequireTrue(testingHandle, strings.Contains(metrics, expected), "metrics payload contains "+expected+": "+metrics)
+	}
+
+	testutil.RequireEqual(testingHandle, len(collector.payloads["/v1/logs"]), 1, "logs requests")
+	logs := payloadText(collector.payloads["/v1/logs"][0])
+	for _, expected := range []string{
+		`{"stringValue":"claude_code.user_prompt"}`,
+		`{"key":"prompt","value":{"stringValue":"<REDACTED>"}}`,
+		`{"key":"prompt_length","value":{"intValue":"11"}}`,
+		`{"stringValue":"claude_code.api_request"}`,
+		`{"key":"duration_ms","value":{"intValue":"1500"}}`,
+		`{"stringValue":"claude_code.tool_result"}`,
+		`{"key":"tool_name","value":{"stringValue":"Bash"}}`,
+		`{"stringValue":"claude_code.api_error"}`,
+	} {
+		testutil.RequireTrue(testingHandle, strings.Contains(logs, expected), "logs payload contains "+expected+": "+logs)
+	}
+	testutil.RequireTrue(testingHandle, !strings.Contains(logs, "fix the bug"), "prompt text is redacted by default")
+}
+
+// TestExporterRequeuesEventsOnFailure verifies events survive a failed export.
+func TestExporterRequeuesEventsOnFailure(testingHandle *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	collector := &otlpCollector{payloads: map[string][]map[string]any{}}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if failing.Load() {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		collector.ServeHTTP(writer, request)
+	}))
+	defer server.Close()
+
+	exporter := NewExporter(Config{LogsExporter: ExporterOTLP, LogsEndpoint: server.URL + "/v1/logs"}, "session-1", server.Client(), nil)
+	exporter.RecordToolResult("Read", false, time.Millisecond)
+	err := exporter.Shutdown(context.Background())
+	testutil.RequireTrue(testingHandle, err != nil && strings.Contains(err.Error(), "503"), "failed export is reported")
+
+	failing.Store(false)
+	testutil.RequireNoError(testingHandle, exporter.Shutdown(context.Background()), "retry export")
+	testutil.RequireEqual(testingHandle, len(collector.payloads["/v1/logs"]), 1, "events delivered on retry")
+}
@@ -0,0 +1,183 @@
+package telemetry
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// LedgerFileName is the cost ledger's file name in the OpenClaude home directory.
+const LedgerFileName = "ledger.jsonl"
+
+// ledgerEventName tags ledger entries with the OTLP event they mirror.
+const ledgerEventName = "claude_code.api_request"
+
+// LedgerEntry is one API request's usage and cost. Field names match the
+// attributes of the claude_code.api_request OTLP event.
+type LedgerEntry struct {
+	// Timestamp is when the request finished.
+	Timestamp time.Time `json:"timestamp"`
+	// Event is always "claude_code.api_request".
+	Event string `json:"event"`
+	// SessionID identifies the session that made the request.
+	SessionID string `json:"session_id"`
+	// Model is the model the request was sent to.
+	Model string `json:"model"`
+	// InputTokens counts uncached prompt tokens.
+	InputTokens int `json:"input_tokens"`
+	// OutputTokens counts completion tokens.
+	OutputTokens int `json:"output_tokens"`
+	// CacheReadTokens counts prompt tokens served from the prompt cache.
+	CacheReadTokens int `json:"cache_read_tokens"`
+	// CacheCreationTokens counts prompt tokens written to the prompt cache.
+	CacheCreationTokens int `json:"cache_creation_tokens"`
+	// CostUSD is the estimated cost from the configured pricing (0 when unpriced).
+	CostUSD float64 `json:"cost_usd"`
+	// DurationMS is the request's wall time.
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// Ledger appends a LedgerEntry per API request to a local JSONL file shared by
+// all sessions. It implements the agent's usage recorder; only API requests
+// are recorded.
+type Ledger struct {
+	// path is the ledger file.
+	path string
+	// sessionID tags every entry.
+	sessionID string
+	// warn receives the first write failure; later ones are dropped.
+	warn io.Writer
+	// mu serializes appends from parallel subagents.
+	mu sync.Mutex
+	// warned records that a failure was already reported.
+	warned bool
+}
+
+// NewLedger returns a ledger appending to path for sessionID. Write failures
+// are reported once to warn and never fail a request.
+func NewLedger(path string, sessionID string, warn io.Writer) *Ledger {
+	return &Ledger{path: path, sessionID: sessionID, warn: warn}
+}
+
+// RecordAPIRequest appends the request's usage and cost to the ledger.
+func (l *Ledger) RecordAPIRequest(model string, usage llm.Usage, costUSD float64, duration time.Duration) {
+	entry := LedgerEntry{
+		Timestamp:           time.Now().UTC(),
+		Event:               ledgerEventName,
+		SessionID:           l.sessionID,
+		Model:               model,
+		InputTokens:         uncachedInputTokens(usage),
+		OutputTokens:        usage.CompletionTokens,
+		CacheReadTokens:     usage.CacheReadTokens,
+		CacheCreationTokens: usage.CacheWriteTokens,
+		CostUSD:             costUSD,
+		DurationMS:          duration.Milliseconds(),
+	}
+	line, err := json.Marshal(entry)
+	if err == nil {
+		err = l.append(append(line, '\n'))
+	}
+	if err != nil {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !l.warned && l.warn != nil {
+			l.warned = true
+			fmt.Fprintf(l.warn, "Warning: cost ledger not written: %v\n", err)
+		}
+	}
+}
+
+// append writes one line with a single O_APPEND write so concurrent sessions
+// never interleave entries.
+func (l *Ledger) append(line []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// RecordUserPrompt is a no-op; the ledger only tracks spend.
+func (l *Ledger) RecordUserPrompt(string) {}
+
+// RecordAPIError is a no-op; failed requests are not billed.
+func (l *Ledger) RecordAPIError(string, error, time.Duration) {}
+
+// RecordToolResult is a no-op; the ledger only tracks spend.
+func (l *Ledger) RecordToolResult(string, bool, time.Duration) {}
+
+// ReadLedger loads every entry from a ledger file. A missing file has no entries.
+func ReadLedger(path string) ([]LedgerEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []LedgerEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry LedgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, number, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// ledgerCSVHeader names the CSV export columns.
+var ledgerCSVHeader = []string{"timestamp", "session_id", "model", "input_tokens", "output_tokens", "cache_read_tokens", "cache_creation_tokens", "cost_usd", "duration_ms"}
+
+// WriteLedgerCSV writes entries as CSV with a header row. Timestamps are
+// RFC 3339 in UTC and costs are plain decimals.
+func WriteLedgerCSV(out io.Writer, entries []LedgerEntry) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write(ledgerCSVHeader); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		record := []string{
+			entry.Timestamp.UTC().Format(time.RFC3339Nano),
+			entry.SessionID,
+			entry.Model,
+			strconv.Itoa(entry.InputTokens),
+			strconv.Itoa(entry.OutputTokens),
+			strconv.Itoa(entry.CacheReadTokens),
+			strconv.Itoa(entry.CacheCreationTokens),
+			strconv.FormatFloat(entry.CostUSD, 'f', -1, 64),
+			strconv.FormatInt(entry.DurationMS, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestLedgerRecordsAndExportsCSV verifies requests append ledger entries and export as CSV.
+func TestLedgerRecordsAndExportsCSV(testingHandle *testing.T) {
+	// Arrange
+	path := filepath.Join(testingHandle.TempDir(), "home", LedgerFileName)
+	first := NewLedger(path, "s1", nil)
+	second := NewLedger(path, "s2", nil)
+
+	// Act
+	first.RecordAPIRequest("gpt-4o", llm.Usage{PromptTokens: 1200, CompletionTokens: 300, CacheReadTokens: 1000}, 0.0125, 1500*time.Millisecond)
+	first.RecordAPIError("gpt-4o", os.ErrDeadlineExceeded, time.Second)
+	second.RecordAPIRequest("claude,custom", llm.Usage{PromptTokens: 50, CompletionTokens: 5}, 0, 200*time.Millisecond)
+	entries, err := ReadLedger(path)
+	var out bytes.Buffer
+	csvErr := WriteLedgerCSV(&out, entries)
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "read ledger")
+	testutil.RequireNoError(testingHandle, csvErr, "write csv")
+	testutil.RequireEqual(testingHandle, len(entries), 2, "only API requests are recorded")
+	testutil.RequireEqual(testingHandle, entries[0].InputTokens, 200, "uncached input tokens")
+	testutil.RequireEqual(testingHandle, entries[0].Event, "claude_code.api_request", "event name")
+	info, statErr := os.Stat(path)
+	testutil.RequireNoError(testingHandle, statErr, "stat ledger")
+	testutil.RequireEqual(testingHandle, info.Mode().Perm(), os.FileMode(0o600), "ledger permissions")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	testutil.RequireEqual(testingHandle, lines[0], "timestamp,session_id,model,input_tokens,output_tokens,cache_read_tokens,cache_creation_tokens,cost_usd,duration_ms", "header")
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(lines[1], ",s1,gpt-4o,200,300,1000,0,0.0125,1500"), lines[1])
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(lines[2], `,s2,"claude,custom",50,5,0,0,0,200`), lines[2])
+
+	missing, err := ReadLedger(filepath.Join(testingHandle.TempDir(), LedgerFileName))
+	testutil.RequireNoError(testingHandle, err, "missing ledger")
+	testutil.RequireEqual(testingHandle, len(missing), 0, "missing ledger is empty")
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// artifactsEnvVar tells commands where to write files that should be kept as session artifacts.
+const artifactsEnvVar = "OPENCLAUDE_ARTIFACTS_DIR"
+
+// truncateCommandOutput caps command output at maxCommandOutput.
+func truncateCommandOutput(toolCtx ToolContext, toolName string, output string) string {
+	return truncateToolOutput(toolCtx, toolName, output, maxCommandOutput)
+}
+
+// truncateToolOutput caps output at limit bytes (zero or less disables the
+// cap), cutting at a line break when one falls in the second half. When the
+// session store is available, the full output is saved as a session artifact
+// and the marker names the file and the Read offset that continues it.
+func truncateToolOutput(toolCtx ToolContext, toolName string, output string, limit int) string {
+	if limit <= 0 || len(output) <= limit {
+		return output
+	}
+	cut := limit
+	atLineBreak := false
+	if newline := strings.LastIndexByte(output[:limit], '\n'); newline >= limit/2 {
+		cut, atLineBreak = newline, true
+	}
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	truncated := output[:cut]
+	if toolCtx.Store == nil || toolCtx.SessionID == "" {
+		return truncated + "\n...[truncated]"
+	}
+	name := fmt.Sprintf("%s-output-%s.txt", strings.ToLower(toolName), time.Now().UTC().Format("20060102T150405"))
+	artifact, err := toolCtx.Store.WriteArtifact(toolCtx.SessionID, name, []byte(output))
+	if err != nil {
+		return truncated + "\n...[truncated]"
+	}
+	// A cut mid-line resumes at that line so nothing is skipped.
+	nextLine := strings.Count(truncated, "\n") + 1
+	if atLineBreak {
+		nextLine++
+	}
+	return fmt.Sprintf("%s\n...[truncated; full output (%d bytes) saved to %s; Read it with offset=%d to continue]", truncated, len(output), artifact.Path, nextLine)
+}
+
+// artifactsEnv returns the environment entry pointing commands at the session's
+// artifacts directory, creating it, or nil without a session store.
+func artifactsEnv(toolCtx ToolContext) []string {
+	if toolCtx.Store == nil || toolCtx.SessionID == "" {
+		return nil
+	}
+	dir := toolCtx.Store.ArtifactsDir(toolCtx.SessionID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil
+	}
+	return []string{artifactsEnvVar + "=" + dir}
+}
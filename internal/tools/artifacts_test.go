@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestTruncateCommandOutputSpillsToArtifact verifies oversized output is saved in full.
+func TestTruncateCommandOutputSpillsToArtifact(testingHandle *testing.T) {
+	testutil.RequireEqual(testingHandle, truncateCommandOutput(ToolContext{}, "Bash", "short"), "short", "short output untouched")
+	long := strings.Repeat("x", maxCommandOutput+10)
+	testutil.RequireEqual(testingHandle, truncateCommandOutput(ToolContext{}, "Bash", long), long[:maxCommandOutput]+"\n...[truncated]", "no store")
+
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	output := truncateCommandOutput(ToolContext{Store: store, SessionID: "s1"}, "Bash", long)
+	testutil.RequireTrue(testingHandle, strings.Contains(output, "...[truncated; full output (65546 bytes) saved to "+store.ArtifactsDir("s1")), "marker names the artifact: "+output[maxCommandOutput:])
+	artifacts, err := store.ListArtifacts("s1")
+	testutil.RequireNoError(testingHandle, err, "list artifacts")
+	testutil.RequireEqual(testingHandle, len(artifacts), 1, "one spill file")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(artifacts[0].Name, "bash-output-"), "spill name")
+	data, err := os.ReadFile(artifacts[0].Path)
+	testutil.RequireNoError(testingHandle, err, "read spill")
+	testutil.RequireEqual(testingHandle, string(data), long, "full output kept")
+}
+
+// TestBashExportsArtifactsDir verifies commands can write artifacts through the environment.
+func TestBashExportsArtifactsDir(testingHandle *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		testingHandle.Skip("bash not installed")
+	}
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	toolCtx := ToolContext{Sandbox: NewSandbox([]string{testingHandle.TempDir()}), CWD: testingHandle.TempDir(), Store: store, SessionID: "s1"}
+	input, _ := json.Marshal(map[string]string{"command": `echo done > "$OPENCLAUDE_ARTIFACTS_DIR/report.txt"`})
+	result, err := (&BashTool{}).Run(context.Background(), input, toolCtx)
+	testutil.RequireNoError(testingHandle, err, "run bash")
+	testutil.RequireTrue(testingHandle, !result.IsError, "bash succeeded: "+result.Content)
+	artifacts, err := store.ListArtifacts("s1")
+	testutil.RequireNoError(testingHandle, err, "list artifacts")
+	testutil.RequireEqual(testingHandle, len(artifacts), 1, "report kept")
+	testutil.RequireEqual(testingHandle, artifacts[0].Name, "report.txt", "report name")
+}
@@ -9,12 +9,40 @@ import (
 	"strings"
 )
 
+// AskUserQuestionToolName is the tool name used in tool calls.
+const AskUserQuestionToolName = "AskUserQuestion"
+
+// UserInputRequest describes a question a print-mode run paused on.
+type UserInputRequest struct {
+	// ToolUseID is the pending AskUserQuestion call; the agent fills it in.
+	ToolUseID string `json:"tool_use_id"`
+	// Question is the text to present to the user.
+	Question string `json:"question"`
+	// Options lists suggested responses.
+	Options []string `json:"options,omitempty"`
+	// Default is the response to use when the user gives none.
+	Default string `json:"default,omitempty"`
+	// AllowMultiple reports whether several options may be chosen.
+	AllowMultiple bool `json:"allow_multiple,omitempty"`
+}
+
+// UserInputRequiredError stops the agent loop so the question can be answered
+// out-of-band and the session resumed with the answer as the next prompt.
+type UserInputRequiredError struct {
+	// Request is the pending question.
+	Request UserInputRequest
+}
+
+func (e *UserInputRequiredError) Error() string {
+	return "user input required: " + e.Request.Question
+}
+
 // AskUserQuestionTool prompts the user for input during interactive runs.
 type AskUserQuestionTool struct{}
 
 // Name returns the tool identifier used in tool calls.
 func (t *AskUserQuestionTool) Name() string {
-	return "AskUserQuestion"
+	return AskUserQuestionToolName
 }
 
 // Description summarizes the interactive question behavior.
@@ -55,7 +83,6 @@ func (t *AskUserQuestionTool) Schema() map[string]any {
 func (t *AskUserQuestionTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolContext) (ToolResult, error) {
 	// The tool is synchronous, so the context is unused by design.
 	_ = ctx
-	_ = toolCtx
 
 	var payload struct {
 		Question      string   `json:"question"`
@@ -77,6 +104,14 @@ func (t *AskUserQuestionTool) Run(ctx context.Context, input json.RawMessage, to
 	}
 
 	if !stdinIsTTY() {
+		if toolCtx.DeferUserInput {
+			return ToolResult{}, &UserInputRequiredError{Request: UserInputRequest{
+				Question:      payload.Question,
+				Options:       payload.Options,
+				Default:       payload.Default,
+				AllowMultiple: payload.AllowMultiple,
+			}}
+		}
 		return ToolResult{IsError: true, Content: "AskUserQuestion requires an interactive TTY"}, nil
 	}
 
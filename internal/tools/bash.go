@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -13,14 +16,17 @@ import (
 const maxCommandOutput = 64 * 1024
 
 // BashTool runs shell commands.
-type BashTool struct{}
+type BashTool struct {
+	// OSSandbox confines commands with the OS sandbox when set.
+	OSSandbox *OSSandbox
+}
 
 func (t *BashTool) Name() string {
 	return "Bash"
 }
 
 func (t *BashTool) Description() string {
-	return "Run a shell command."
+	return "Run a shell command. Files written to $OPENCLAUDE_ARTIFACTS_DIR are kept as session artifacts." + shellDescription(hostShell())
 }
 
 func (t *BashTool) Schema() map[string]any {
@@ -61,17 +67,36 @@ func (t *BashTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 		}
 		workingDir = resolved
 	}
+	// Without the OS sandbox nothing stops a command from writing where it
+	// runs, so read-only roots cannot be a command's working directory.
+	if t.OSSandbox == nil && toolCtx.Sandbox != nil && toolCtx.Sandbox.IsReadOnly(workingDir) {
+		return ToolResult{IsError: true, Content: fmt.Sprintf("%s: %s; enable sandbox.enabled in settings to run commands there", ErrPathReadOnly, workingDir)}, nil
+	}
 
-	// Execute commands through bash -lc to match common CLI behavior.
-	cmd := exec.CommandContext(ctx, "bash", "-lc", payload.Command)
+	// Execute commands through bash -lc to match common CLI behavior, or the
+	// closest shell the system has.
+	shell := hostShell()
+	cmd, err := t.OSSandbox.Command(ctx, toolCtx, shell.Path, shell.argv(payload.Command)...)
+	if err != nil {
+		return ToolResult{IsError: true, Content: err.Error()}, nil
+	}
 	cmd.Dir = workingDir
+	if env := artifactsEnv(toolCtx); env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	// Stream both pipes live; the result still lists stdout before stderr.
+	if streamer := newOutputStreamer(toolCtx); streamer != nil {
+		defer streamer.Close()
+		cmd.Stdout = io.MultiWriter(&stdout, streamer)
+		cmd.Stderr = io.MultiWriter(&stderr, streamer)
+	}
 
-	err := cmd.Run()
+	err = cmd.Run()
 	output := strings.TrimSpace(stdout.String())
 	if stderr.Len() > 0 {
 		if output != "" {
@@ -80,15 +105,27 @@ func (t *BashTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 		output += strings.TrimSpace(stderr.String())
 	}
 
-	// Truncate to keep responses bounded.
-	if len(output) > maxCommandOutput {
-		output = output[:maxCommandOutput] + "\n...[truncated]"
-	}
+	// Truncate to keep responses bounded, spilling the full output to an artifact.
+	output = truncateCommandOutput(toolCtx, t.Name(), output)
 
+	result := ToolResult{Content: output, Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: commandExitCode(err), Interrupted: err != nil && ctx.Err() != nil}
 	// Return errors with captured output for debugging.
 	if err != nil {
-		return ToolResult{IsError: true, Content: fmt.Sprintf("command failed: %v\n%s", err, output)}, nil
+		result.IsError = true
+		result.Content = fmt.Sprintf("command failed: %v\n%s", err, output)
 	}
+	return result, nil
+}
 
-	return ToolResult{Content: output}, nil
+// commandExitCode returns a finished command's exit status for ToolResult:
+// 0 on success, the status on failure, or -1 when the command did not run.
+func commandExitCode(err error) *int {
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		code = -1
+	}
+	return &code
 }
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/openclaude/openclaude/internal/redact"
 )
 
 // EditTool applies edits to files using Claude-style old/new strings.
@@ -105,7 +107,7 @@ func (t *EditTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 	if usingOldNew && oldValue == "" {
 		requireExisting = false
 	}
-	path, err := toolCtx.Sandbox.ResolvePath(payload.FilePath, requireExisting)
+	path, err := toolCtx.Sandbox.ResolveWritePath(payload.FilePath, requireExisting)
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
@@ -154,6 +156,10 @@ func (t *EditTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 		return ToolResult{IsError: true, Content: "either old_string/new_string or patch/replacements must be provided"}, nil
 	}
 
+	if err := checkRedactionMasks(string(original), updated); err != nil {
+		return ToolResult{IsError: true, Content: err.Error()}, nil
+	}
+
 	// Backup the original file to the session directory, if available.
 	if err := backupFile(toolCtx, path); err != nil {
 		return ToolResult{IsError: true, Content: fmt.Sprintf("backup failed: %v", err)}, nil
@@ -179,6 +185,16 @@ func (t *EditTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 	return ToolResult{Content: "ok"}, nil
 }
 
+// checkRedactionMasks refuses content that adds [REDACTED:<kind>] masks the
+// file did not already have. Tool results mask credentials, so a model that
+// copies a read result back would otherwise overwrite real secrets with masks.
+func checkRedactionMasks(original string, updated string) error {
+	if redact.CountMasks(updated) <= redact.CountMasks(original) {
+		return nil
+	}
+	return fmt.Errorf("refusing to write %s masks: credentials in tool output are redacted, so keep the lines holding them unchanged (edit around them with old_string/new_string) instead of copying the masked text", redact.MaskPrefix+"...]")
+}
+
 // writeAtomic writes to a temp file and renames it into place.
 // The mode is applied before the rename so the final file has stable permissions.
 func writeAtomic(path string, data []byte, mode os.FileMode) error {
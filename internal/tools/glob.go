@@ -4,20 +4,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 )
 
+// maxGlobResults caps how many paths Glob returns; the newest files are kept.
+const maxGlobResults = 100
+
 // GlobTool performs glob searches.
-type GlobTool struct{}
+type GlobTool struct {
+	// Ignore holds extra gitignore-style patterns from settings, applied on top
+	// of .gitignore, .claudeignore, and .openclaudeignore files.
+	Ignore []string
+}
 
 func (t *GlobTool) Name() string {
 	return "Glob"
 }
 
 func (t *GlobTool) Description() string {
-	return "Find files matching a glob pattern."
+	return fmt.Sprintf("Find files matching a glob pattern such as \"**/*.go\" or \"src/**/*.{ts,tsx}\". Returns up to %d paths, most recently modified first. Files excluded by .gitignore, .claudeignore, or .openclaudeignore are skipped.", maxGlobResults)
 }
 
 func (t *GlobTool) Schema() map[string]any {
@@ -28,14 +39,25 @@ func (t *GlobTool) Schema() map[string]any {
 				"type":        "string",
 				"description": "Glob pattern to match files.",
 			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Directory to search in. Defaults to the working directory.",
+			},
 		},
 		"required": []string{"pattern"},
 	}
 }
 
+// globMatch is a matched file and its modification time.
+type globMatch struct {
+	path    string
+	modTime time.Time
+}
+
 func (t *GlobTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolContext) (ToolResult, error) {
 	var payload struct {
 		Pattern string `json:"pattern"`
+		Path    string `json:"path"`
 	}
 	if err := json.Unmarshal(input, &payload); err != nil {
 		return ToolResult{IsError: true, Content: fmt.Sprintf("invalid input: %v", err)}, nil
@@ -45,23 +67,100 @@ func (t *GlobTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 		return ToolResult{IsError: true, Content: "pattern is required"}, nil
 	}
 
-	// Use filepath.Glob to expand patterns from the current process context.
-	matches, err := filepath.Glob(payload.Pattern)
+	// Default to the current working directory.
+	root := payload.Path
+	if root == "" {
+		root = toolCtx.CWD
+	}
+	if root == "" {
+		root = "."
+	}
+	pattern := filepath.ToSlash(payload.Pattern)
+	if path.IsAbs(pattern) {
+		root, pattern = "/", strings.TrimPrefix(pattern, "/")
+	}
+	// Start the walk below the pattern's literal leading directories.
+	for {
+		head, rest, found := strings.Cut(pattern, "/")
+		if !found || strings.ContainsAny(head, "*?[{\\") {
+			break
+		}
+		root, pattern = filepath.Join(root, head), rest
+	}
+	for _, expanded := range expandBraces(pattern) {
+		if _, err := path.Match(expanded, ""); err != nil {
+			return ToolResult{IsError: true, Content: fmt.Sprintf("invalid pattern %q: %v", payload.Pattern, err)}, nil
+		}
+	}
+
+	// Validate the search root against sandbox rules.
+	root, err := toolCtx.Sandbox.ResolveFilePath(root, true)
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
 
-	// Enforce sandbox constraints on each match.
-	var filtered []string
-	for _, match := range matches {
-		resolved, err := toolCtx.Sandbox.ResolvePath(match, true)
+	ignore := NewIgnoreMatcher(root, slices.Concat(t.Ignore, toolCtx.Sandbox.HidePatterns))
+	var matches []globMatch
+	progress := newProgressReporter(toolCtx)
+	walked := int64(0)
+	err = filepath.WalkDir(root, func(current string, entry fs.DirEntry, err error) error {
 		if err != nil {
-			continue
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
 		}
-		filtered = append(filtered, resolved)
+		if current == root {
+			return nil
+		}
+		if ignore.Ignored(current, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			ignore.LoadDir(current)
+			return nil
+		}
+		walked++
+		progress.update(Progress{Current: walked, Unit: "files"})
+		rel, err := filepath.Rel(root, current)
+		if err != nil || !matchGlobPath(pattern, filepath.ToSlash(rel)) {
+			return nil
+		}
+		// Enforce sandbox constraints on each match.
+		resolved, err := toolCtx.Sandbox.ResolvePath(current, true)
+		if err != nil {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		matches = append(matches, globMatch{path: resolved, modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return ToolResult{IsError: true, Content: err.Error()}, nil
+	}
+	if len(matches) == 0 {
+		return ToolResult{Content: "No files found"}, nil
 	}
 
-	// Sort for deterministic output.
-	sort.Strings(filtered)
-	return ToolResult{Content: strings.Join(filtered, "\n")}, nil
+	// Newest first; ties sort by path for deterministic output.
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].modTime.Equal(matches[j].modTime) {
+			return matches[i].modTime.After(matches[j].modTime)
+		}
+		return matches[i].path < matches[j].path
+	})
+	lines := make([]string, 0, min(len(matches), maxGlobResults)+1)
+	for _, match := range matches[:min(len(matches), maxGlobResults)] {
+		lines = append(lines, match.path)
+	}
+	if len(matches) > maxGlobResults {
+		lines = append(lines, fmt.Sprintf("(showing the %d most recently modified of %d matches; use a narrower pattern or path to see others)", maxGlobResults, len(matches)))
+	}
+	return ToolResult{Content: strings.Join(lines, "\n")}, nil
 }
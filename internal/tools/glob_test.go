@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// writeGlobFixture creates files under root, each modified one minute after
+// the previous one so the listed order is oldest first.
+func writeGlobFixture(testingHandle *testing.T, root string, files []string) {
+	testingHandle.Helper()
+	start := time.Now().Add(-time.Hour)
+	for index, name := range files {
+		target := filepath.Join(root, filepath.FromSlash(name))
+		testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Dir(target), 0o755), "mkdir")
+		testutil.RequireNoError(testingHandle, os.WriteFile(target, []byte(name), 0o600), "write "+name)
+		modTime := start.Add(time.Duration(index) * time.Minute)
+		testutil.RequireNoError(testingHandle, os.Chtimes(target, modTime, modTime), "chtimes "+name)
+	}
+}
+
+// runGlob runs Glob in root and returns the paths relative to root.
+func runGlob(testingHandle *testing.T, tool *GlobTool, root string, pattern string) []string {
+	testingHandle.Helper()
+	input, _ := json.Marshal(map[string]any{"pattern": pattern})
+	result, err := tool.Run(context.Background(), input, ToolContext{Sandbox: NewSandbox([]string{root}), CWD: root})
+	testutil.RequireNoError(testingHandle, err, "run glob")
+	testutil.RequireTrue(testingHandle, !result.IsError, result.Content)
+	if result.Content == "No files found" {
+		return nil
+	}
+	var paths []string
+	for _, line := range strings.Split(result.Content, "\n") {
+		rel, err := filepath.Rel(root, line)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			paths = append(paths, line)
+			continue
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+// TestGlobToolMatchesNewestFirst verifies recursive patterns, braces, and mtime ordering.
+func TestGlobToolMatchesNewestFirst(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	writeGlobFixture(testingHandle, root, []string{"main.go", "src/app.ts", "src/deep/view.tsx", "src/deep/old.js", "README.md", "src/util.ts"})
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{name: "top level only", pattern: "*.go", want: []string{"main.go"}},
+		{name: "recursive braces", pattern: "**/*.{ts,tsx}", want: []string{"src/util.ts", "src/deep/view.tsx", "src/app.ts"}},
+		{name: "literal prefix", pattern: "src/deep/*", want: []string{"src/deep/old.js", "src/deep/view.tsx"}},
+		{name: "no match", pattern: "**/*.rs", want: nil},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			testutil.RequireEqual(testingHandle, runGlob(testingHandle, &GlobTool{}, root, tt.pattern), tt.want, "matches")
+		})
+	}
+}
+
+// TestGlobToolHonorsIgnoreFiles verifies .gitignore, .claudeignore,
+// .openclaudeignore, negation, and settings patterns.
+func TestGlobToolHonorsIgnoreFiles(testingHandle *testing.T) {
+	// Arrange
+	root := testingHandle.TempDir()
+	writeGlobFixture(testingHandle, root, []string{
+		"node_modules/pkg/index.js",
+		"dist/bundle.js",
+		"dist/keep.js",
+		"src/app.js",
+		"src/gen/api.js",
+		"secrets/token.js",
+		"vendor/lib.js",
+		"generated/schema.js",
+	})
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("# build output\nnode_modules/\n/dist/*\n!/dist/keep.js\n"), 0o600), "write gitignore")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, ".claudeignore"), []byte("secrets\n"), 0o600), "write claudeignore")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, ".openclaudeignore"), []byte("generated/\n"), 0o600), "write openclaudeignore")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "src", ".gitignore"), []byte("gen/\n"), 0o600), "write nested gitignore")
+
+	// Act
+	matches := runGlob(testingHandle, &GlobTool{Ignore: []string{"vendor/"}}, root, "**/*.js")
+
+	// Assert
+	testutil.RequireEqual(testingHandle, matches, []string{"src/app.js", "dist/keep.js"}, "matches")
+}
+
+// TestGlobToolCapsResults verifies only the newest matches are listed, with a note.
+func TestGlobToolCapsResults(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	var files []string
+	for index := 0; index < maxGlobResults+5; index++ {
+		files = append(files, fmt.Sprintf("file%03d.txt", index))
+	}
+	writeGlobFixture(testingHandle, root, files)
+
+	matches := runGlob(testingHandle, &GlobTool{}, root, "*.txt")
+
+	testutil.RequireEqual(testingHandle, len(matches), maxGlobResults+1, "lines")
+	testutil.RequireEqual(testingHandle, matches[0], files[len(files)-1], "newest first")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(matches[maxGlobResults], "(showing the 100 most recently modified of 105 matches"), matches[maxGlobResults])
+}
@@ -7,18 +7,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 // GrepTool searches for a string within files.
-type GrepTool struct{}
+type GrepTool struct {
+	// Ignore holds extra gitignore-style patterns from settings, applied on top
+	// of the ignore files.
+	Ignore []string
+}
 
 func (t *GrepTool) Name() string {
 	return "Grep"
 }
 
 func (t *GrepTool) Description() string {
-	return "Search for a string in files under a path."
+	return "Search for a string in files under a path. Files excluded by .gitignore, .claudeignore, or .openclaudeignore are skipped."
 }
 
 func (t *GrepTool) Schema() map[string]any {
@@ -58,20 +63,34 @@ func (t *GrepTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 	}
 
 	// Validate search path against sandbox rules.
-	root, err := toolCtx.Sandbox.ResolvePath(root, true)
+	root, err := toolCtx.Sandbox.ResolveFilePath(root, true)
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
 
-	// Walk the tree and scan files line by line.
+	// Walk the tree and scan files line by line, skipping ignored paths below
+	// the search root; a root named explicitly is searched unless it is hidden.
+	ignore := NewIgnoreMatcher(root, slices.Concat(t.Ignore, toolCtx.Sandbox.HidePatterns))
 	var matches []string
+	progress := newProgressReporter(toolCtx)
+	streamer := newOutputStreamer(toolCtx)
+	scanned := int64(0)
 	err = filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
+		if path != root && ignore.Ignored(path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if entry.IsDir() {
+			ignore.LoadDir(path)
 			return nil
 		}
+		scanned++
+		progress.update(Progress{Current: scanned, Unit: "files"})
 		info, err := entry.Info()
 		if err != nil {
 			return nil
@@ -91,11 +110,17 @@ func (t *GrepTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 			line := scanner.Text()
 			if strings.Contains(line, payload.Query) {
 				matches = append(matches, fmt.Sprintf("%s:%d:%s", path, lineNumber, line))
+				if streamer != nil {
+					fmt.Fprintln(streamer, matches[len(matches)-1])
+				}
 			}
 			lineNumber++
 		}
 		return nil
 	})
+	if streamer != nil {
+		streamer.Close()
+	}
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
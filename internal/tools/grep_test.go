@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestGrepToolHonorsIgnoreFiles verifies ignored files and directories are
+// not searched unless the search path names them, and that paths hidden by
+// .openclaudeignore are refused even then.
+func TestGrepToolHonorsIgnoreFiles(testingHandle *testing.T) {
+	// Arrange
+	root := testingHandle.TempDir()
+	for _, name := range []string{"src/app.go", "dist/bundle.js", "generated/api.go", "vendor/lib.go"} {
+		testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0o755), "mkdir "+name)
+		testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, name), []byte("needle\n"), 0o600), "write "+name)
+	}
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("dist/\n"), 0o600), "write gitignore")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, ".openclaudeignore"), []byte("generated/\n"), 0o600), "write openclaudeignore")
+	grep := func(path string) string {
+		input, _ := json.Marshal(map[string]string{"query": "needle", "path": path})
+		result, err := (&GrepTool{Ignore: []string{"vendor/"}}).Run(context.Background(), input, ToolContext{Sandbox: NewSandbox([]string{root}), CWD: root})
+		testutil.RequireNoError(testingHandle, err, "run grep")
+		return result.Content
+	}
+
+	// Act
+	all := grep(root)
+	named := grep(filepath.Join(root, "dist", "bundle.js"))
+	hidden := grep(filepath.Join(root, "generated", "api.go"))
+
+	// Assert
+	testutil.RequireEqual(testingHandle, all, filepath.Join(root, "src", "app.go")+":1:needle", "only unignored matches")
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(named, "bundle.js:1:needle"), "explicit path searched: "+named)
+	testutil.RequireTrue(testingHandle, strings.Contains(hidden, ErrPathHidden.Error()), "hidden path refused: "+hidden)
+}
@@ -0,0 +1,254 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileNames are the per-directory ignore files the file tools honor, in
+// gitignore syntax.
+var ignoreFileNames = []string{".gitignore", ".claudeignore", ".openclaudeignore"}
+
+// hiddenFileNames are the ignore files that hide paths from every file tool
+// and @-mentions, not only from searches.
+var hiddenFileNames = []string{".claudeignore", ".openclaudeignore"}
+
+// ignoreRule is one gitignore-syntax pattern.
+type ignoreRule struct {
+	// base is the absolute directory the pattern is relative to.
+	base string
+	// pattern is slash-separated and relative to base; unanchored patterns
+	// are stored with a leading "**/".
+	pattern string
+	// negate re-includes paths matched by earlier rules ("!pattern").
+	negate bool
+	// dirOnly limits the rule to directories ("pattern/").
+	dirOnly bool
+}
+
+// IgnoreMatcher decides whether paths are hidden by ignore files and by
+// patterns from settings. Rules are evaluated in load order and the last
+// matching rule wins, like git.
+type IgnoreMatcher struct {
+	// rules holds the loaded rules, parents before children.
+	rules []ignoreRule
+	// loaded records directories whose ignore files were read.
+	loaded map[string]bool
+	// names are the ignore files read from each directory.
+	names []string
+	// extra are the rules from settings, applied after every ignore file.
+	extra []ignoreRule
+	// top is the repository root the rules were loaded from.
+	top string
+}
+
+// NewIgnoreMatcher loads ignore files from the enclosing repository root (the
+// nearest ancestor with .git, or root itself) down to root. Extra patterns
+// are relative to that repository root and apply after the files.
+func NewIgnoreMatcher(root string, extra []string) *IgnoreMatcher {
+	return newIgnoreMatcher(root, ignoreFileNames, extra)
+}
+
+// NewHiddenMatcher is NewIgnoreMatcher for .claudeignore and .openclaudeignore
+// only: the paths no file tool may read or change.
+func NewHiddenMatcher(root string, extra []string) *IgnoreMatcher {
+	return newIgnoreMatcher(root, hiddenFileNames, extra)
+}
+
+// newIgnoreMatcher loads the named ignore files for NewIgnoreMatcher and NewHiddenMatcher.
+func newIgnoreMatcher(root string, names []string, extra []string) *IgnoreMatcher {
+	matcher := &IgnoreMatcher{loaded: map[string]bool{}, names: names}
+	top := root
+	var chain []string
+	for dir := root; ; dir = filepath.Dir(dir) {
+		chain = append(chain, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			top = dir
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			chain = []string{root}
+			break
+		}
+	}
+	matcher.top = top
+	for index := len(chain) - 1; index >= 0; index-- {
+		matcher.LoadDir(chain[index])
+	}
+	for _, line := range extra {
+		if rule, ok := parseIgnoreLine(top, line); ok {
+			matcher.extra = append(matcher.extra, rule)
+		}
+	}
+	return matcher
+}
+
+// LoadDir reads the ignore files in dir once; missing files are skipped.
+func (m *IgnoreMatcher) LoadDir(dir string) {
+	if m.loaded[dir] {
+		return
+	}
+	m.loaded[dir] = true
+	for _, name := range m.names {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreLine(dir, scanner.Text()); ok {
+				m.rules = append(m.rules, rule)
+			}
+		}
+		file.Close()
+	}
+}
+
+// Ignored reports whether the absolute path is excluded. The .git directory
+// is always excluded.
+func (m *IgnoreMatcher) Ignored(target string, isDir bool) bool {
+	if isDir && filepath.Base(target) == ".git" {
+		return true
+	}
+	return m.matches(target, isDir)
+}
+
+// Hidden reports whether target, or a directory between the repository root
+// and target, is excluded. It loads the ignore files of each directory on the
+// way down instead of walking the tree, and does not treat .git as excluded.
+func (m *IgnoreMatcher) Hidden(target string, isDir bool) bool {
+	if rel, err := filepath.Rel(m.top, target); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		parts := strings.Split(rel, string(filepath.Separator))
+		current := m.top
+		for _, part := range parts[:len(parts)-1] {
+			current = filepath.Join(current, part)
+			if m.matches(current, true) {
+				return true
+			}
+			m.LoadDir(current)
+		}
+	}
+	return m.matches(target, isDir)
+}
+
+// matches applies the rules to target; the last matching rule wins.
+func (m *IgnoreMatcher) matches(target string, isDir bool) bool {
+	ignored := false
+	for _, rules := range [][]ignoreRule{m.rules, m.extra} {
+		for _, rule := range rules {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			rel, err := filepath.Rel(rule.base, target)
+			if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if matchGlobPath(rule.pattern, filepath.ToSlash(rel)) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// parseIgnoreLine converts one gitignore line into a rule relative to base.
+func parseIgnoreLine(base string, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	// A slash anywhere but the end anchors the pattern to base; otherwise it
+	// matches a name at any depth.
+	if strings.Contains(line, "/") {
+		rule.pattern = strings.TrimPrefix(line, "/")
+	} else {
+		rule.pattern = "**/" + line
+	}
+	return rule, true
+}
+
+// matchGlobPath matches a slash-separated path against a glob pattern where
+// "**" spans any number of directories and "{a,b}" lists alternatives.
+func matchGlobPath(pattern string, name string) bool {
+	for _, expanded := range expandBraces(pattern) {
+		if matchGlobSegments(strings.Split(expanded, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlobSegments matches path segments, letting "**" consume zero or more of them.
+func matchGlobSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(name); skip++ {
+				if matchGlobSegments(pattern[1:], name[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// expandBraces expands "{a,b}" alternatives, including nested ones, e.g.
+// "*.{ts,tsx}" becomes "*.ts" and "*.tsx". Unbalanced braces are kept literally.
+func expandBraces(pattern string) []string {
+	open := strings.Index(pattern, "{")
+	if open < 0 {
+		return []string{pattern}
+	}
+	depth := 0
+	start := open + 1
+	var options []string
+	for index := open; index < len(pattern); index++ {
+		switch pattern[index] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				options = append(options, pattern[start:index])
+				start = index + 1
+			}
+		case '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			options = append(options, pattern[start:index])
+			var expanded []string
+			for _, option := range options {
+				for _, tail := range expandBraces(option + pattern[index+1:]) {
+					expanded = append(expanded, pattern[:open]+tail)
+				}
+			}
+			return expanded
+		}
+	}
+	return []string{pattern}
+}
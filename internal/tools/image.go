@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// MaxImageBytes caps images attached to prompts or returned by Read.
+// Gateways commonly reject larger inline images, so we fail fast instead.
+const MaxImageBytes = 5 * 1024 * 1024
+
+// ImageMediaType returns the media type for supported image file extensions.
+func ImageMediaType(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return "image/png", true
+	case ".jpg", ".jpeg":
+		return "image/jpeg", true
+	case ".gif":
+		return "image/gif", true
+	case ".webp":
+		return "image/webp", true
+	default:
+		return "", false
+	}
+}
+
+// LoadImagePart reads an image file into a base64 data URL content part.
+func LoadImagePart(path string) (llm.ContentPart, error) {
+	mediaType, ok := ImageMediaType(path)
+	if !ok {
+		return llm.ContentPart{}, fmt.Errorf("unsupported image type: %s", filepath.Ext(path))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return llm.ContentPart{}, err
+	}
+	if info.Size() > MaxImageBytes {
+		return llm.ContentPart{}, fmt.Errorf("image too large: %d bytes (max %d)", info.Size(), MaxImageBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return llm.ContentPart{}, err
+	}
+	return llm.ImagePart(llm.ImageDataURL(mediaType, data)), nil
+}
@@ -42,7 +42,7 @@ func (t *ListDirTool) Run(ctx context.Context, input json.RawMessage, toolCtx To
 	}
 
 	// Validate path against sandbox rules.
-	path, err := toolCtx.Sandbox.ResolvePath(payload.Path, true)
+	path, err := toolCtx.Sandbox.ResolveFilePath(payload.Path, true)
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
@@ -60,6 +60,10 @@ func (t *ListDirTool) Run(ctx context.Context, input json.RawMessage, toolCtx To
 
 	var list []entry
 	for _, item := range entries {
+		// Entries hidden by .claudeignore are left out.
+		if toolCtx.Sandbox.IsHidden(filepath.Join(path, item.Name())) {
+			continue
+		}
 		info, err := item.Info()
 		if err != nil {
 			continue
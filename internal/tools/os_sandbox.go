@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// osSandboxHelperArg is the hidden first argument that turns the claude binary
+// into the Linux sandbox helper (see RunOSSandboxHelper).
+const osSandboxHelperArg = "__openclaude-os-sandbox"
+
+// OSSandbox confines Bash commands with the operating system's sandbox:
+// sandbox-exec profiles on macOS, Landlock and a network namespace on Linux.
+// Commands may read anywhere but write only inside the read-write sandbox
+// roots, the session artifacts directory, the temp directory, and WritableDirs.
+type OSSandbox struct {
+	// WritableDirs lists extra directories commands may write to.
+	WritableDirs []string
+	// DisableNetwork cuts commands off from the network, loopback included.
+	DisableNetwork bool
+}
+
+// osSandboxSpec is what one confined command may do; the Linux helper receives it as JSON.
+type osSandboxSpec struct {
+	// Writable lists resolved directories that accept writes.
+	Writable []string `json:"writable"`
+	// DisableNetwork runs the command without network access.
+	DisableNetwork bool `json:"disable_network"`
+}
+
+// Command builds name with args, confined by the OS sandbox. A nil sandbox
+// returns an unconfined command. When the platform cannot sandbox, Command
+// fails instead of running the command unconfined.
+func (s *OSSandbox) Command(ctx context.Context, toolCtx ToolContext, name string, args ...string) (*exec.Cmd, error) {
+	if s == nil {
+		return exec.CommandContext(ctx, name, args...), nil
+	}
+	if err := checkOSSandbox(); err != nil {
+		return nil, fmt.Errorf("OS sandbox unavailable: %w; set sandbox.enabled to false in settings to run commands unconfined", err)
+	}
+	spec := osSandboxSpec{Writable: s.writableDirs(toolCtx), DisableNetwork: s.DisableNetwork}
+	return sandboxCommand(ctx, spec, name, args...)
+}
+
+// writableDirs collects the directories a command may write to, resolved to
+// real paths because both sandboxes match on them. Missing directories are skipped.
+func (s *OSSandbox) writableDirs(toolCtx ToolContext) []string {
+	candidates := []string{os.TempDir()}
+	if toolCtx.Sandbox != nil {
+		candidates = append(candidates, toolCtx.Sandbox.WritableRoots()...)
+	}
+	if toolCtx.Store != nil && toolCtx.SessionID != "" {
+		artifactsDir := toolCtx.Store.ArtifactsDir(toolCtx.SessionID)
+		if err := os.MkdirAll(artifactsDir, 0o700); err == nil {
+			candidates = append(candidates, artifactsDir)
+		}
+	}
+	candidates = append(candidates, s.WritableDirs...)
+
+	seen := map[string]bool{}
+	var dirs []string
+	for _, candidate := range candidates {
+		if strings.TrimSpace(candidate) == "" {
+			continue
+		}
+		absolute, err := filepath.Abs(candidate)
+		if err != nil {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(absolute)
+		if err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		dirs = append(dirs, resolved)
+	}
+	return dirs
+}
+
+// seatbeltProfile renders a macOS sandbox-exec profile for spec: everything is
+// allowed except writes outside the writable directories and, optionally, the network.
+func seatbeltProfile(spec osSandboxSpec) string {
+	var builder strings.Builder
+	builder.WriteString("(version 1)\n(allow default)\n(deny file-write*)\n(allow file-write*\n")
+	for _, dir := range spec.Writable {
+		fmt.Fprintf(&builder, "  (subpath %s)\n", seatbeltString(dir))
+	}
+	for _, device := range []string{"/dev/null", "/dev/zero", "/dev/tty", "/dev/stdout", "/dev/stderr"} {
+		fmt.Fprintf(&builder, "  (literal %s)\n", seatbeltString(device))
+	}
+	builder.WriteString("  (subpath \"/dev/fd\"))\n")
+	if spec.DisableNetwork {
+		builder.WriteString("(deny network*)\n")
+	}
+	return builder.String()
+}
+
+// seatbeltString quotes value as a sandbox profile string literal.
+func seatbeltString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,26 @@
+//go:build darwin
+
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// sandboxExecPath is the macOS sandbox launcher.
+const sandboxExecPath = "/usr/bin/sandbox-exec"
+
+// checkOSSandbox reports whether sandbox-exec is installed.
+func checkOSSandbox() error {
+	_, err := os.Stat(sandboxExecPath)
+	return err
+}
+
+// sandboxCommand runs name under sandbox-exec with a profile built from spec.
+func sandboxCommand(ctx context.Context, spec osSandboxSpec, name string, args ...string) (*exec.Cmd, error) {
+	return exec.CommandContext(ctx, sandboxExecPath, append([]string{"-p", seatbeltProfile(spec), name}, args...)...), nil
+}
+
+// RunOSSandboxHelper is a no-op on macOS, where sandbox-exec confines commands.
+func RunOSSandboxHelper() {}
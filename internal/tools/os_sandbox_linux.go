@@ -0,0 +1,154 @@
+//go:build linux
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// landlockWriteAccess are the Landlock ABI v1 rights that modify the filesystem.
+const landlockWriteAccess = unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+	unix.LANDLOCK_ACCESS_FS_REMOVE_DIR |
+	unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+	unix.LANDLOCK_ACCESS_FS_MAKE_CHAR |
+	unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+	unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+	unix.LANDLOCK_ACCESS_FS_MAKE_SOCK |
+	unix.LANDLOCK_ACCESS_FS_MAKE_FIFO |
+	unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+	unix.LANDLOCK_ACCESS_FS_MAKE_SYM
+
+// landlockWritableDevices stay writable so redirections like >/dev/null keep working.
+var landlockWritableDevices = []string{"/dev/null", "/dev/zero", "/dev/tty", "/dev/full"}
+
+// checkOSSandbox reports whether the kernel supports Landlock.
+func checkOSSandbox() error {
+	_, err := landlockABI()
+	return err
+}
+
+// landlockABI returns the kernel's Landlock ABI version.
+func landlockABI() (int, error) {
+	version, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return 0, fmt.Errorf("landlock is not enabled in this kernel (needs Linux 5.13+): %w", errno)
+	}
+	return int(version), nil
+}
+
+// sandboxCommand re-executes the current binary as the sandbox helper, which
+// applies Landlock and then execs name. Network isolation puts the helper in
+// new user and network namespaces before it starts.
+func sandboxCommand(ctx context.Context, spec osSandboxSpec, name string, args ...string) (*exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locate sandbox helper: %w", err)
+	}
+	payload, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("encode sandbox spec: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, executable, append([]string{osSandboxHelperArg, string(payload), name}, args...)...)
+	if spec.DisableNetwork {
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			Cloneflags:  syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET,
+			UidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+			GidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+		}
+	}
+	return cmd, nil
+}
+
+// RunOSSandboxHelper confines the process and execs the wrapped command when
+// the binary was started as the sandbox helper; otherwise it returns at once.
+// main calls it before anything else.
+func RunOSSandboxHelper() {
+	if len(os.Args) < 4 || os.Args[1] != osSandboxHelperArg {
+		return
+	}
+	fail := func(err error) {
+		fmt.Fprintf(os.Stderr, "openclaude sandbox: %v\n", err)
+		os.Exit(126)
+	}
+	var spec osSandboxSpec
+	if err := json.Unmarshal([]byte(os.Args[2]), &spec); err != nil {
+		fail(fmt.Errorf("decode spec: %w", err))
+	}
+	// Landlock restricts the calling thread, which then execs the command.
+	runtime.LockOSThread()
+	if err := landlockRestrictWrites(spec.Writable); err != nil {
+		fail(err)
+	}
+	path, err := exec.LookPath(os.Args[3])
+	if err != nil {
+		fail(err)
+	}
+	fail(unix.Exec(path, os.Args[3:], os.Environ()))
+}
+
+// landlockRestrictWrites limits filesystem writes to the writable directories
+// and a few device files. Reads are not restricted.
+func landlockRestrictWrites(writable []string) error {
+	abi, err := landlockABI()
+	if err != nil {
+		return err
+	}
+	handled := uint64(landlockWriteAccess)
+	fileAccess := uint64(unix.LANDLOCK_ACCESS_FS_WRITE_FILE)
+	if abi >= 2 {
+		handled |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if abi >= 3 {
+		handled |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+		fileAccess |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+	ruleset, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("create landlock ruleset: %w", errno)
+	}
+	defer unix.Close(int(ruleset))
+
+	// Directories get every handled right beneath them; files only the file rights.
+	addRule := func(path string) error {
+		fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+		if err != nil {
+			// Missing paths grant nothing.
+			return nil
+		}
+		defer unix.Close(fd)
+		access := fileAccess
+		var stat unix.Stat_t
+		if err := unix.Fstat(fd, &stat); err == nil && stat.Mode&unix.S_IFMT == unix.S_IFDIR {
+			access = handled
+		}
+		rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+		if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, ruleset, unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+			return fmt.Errorf("allow writes to %s: %w", path, errno)
+		}
+		return nil
+	}
+	paths := append(append([]string{"/dev/shm"}, landlockWritableDevices...), writable...)
+	for _, path := range paths {
+		if err := addRule(path); err != nil {
+			return err
+		}
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("set no_new_privs: %w", err)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, ruleset, 0, 0); errno != 0 {
+		return fmt.Errorf("apply landlock ruleset: %w", errno)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+//go:build linux
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestMain lets the test binary act as the sandbox helper it re-executes.
+func TestMain(m *testing.M) {
+	RunOSSandboxHelper()
+	os.Exit(m.Run())
+}
+
+// runSandboxedBash runs command through a Bash tool confined to root.
+func runSandboxedBash(testingHandle *testing.T, sandbox *OSSandbox, root string, command string) ToolResult {
+	if _, err := exec.LookPath("bash"); err != nil {
+		testingHandle.Skip("bash not installed")
+	}
+	if err := checkOSSandbox(); err != nil {
+		testingHandle.Skip(err.Error())
+	}
+	// Keep the developer's login profile out of bash -lc.
+	testingHandle.Setenv("HOME", testingHandle.TempDir())
+	input, _ := json.Marshal(map[string]string{"command": command})
+	result, err := (&BashTool{OSSandbox: sandbox}).Run(context.Background(), input, ToolContext{Sandbox: NewSandbox([]string{root}), CWD: root})
+	testutil.RequireNoError(testingHandle, err, "run bash")
+	return result
+}
+
+// TestLandlockConfinesBashWrites verifies writes succeed inside the roots and fail outside.
+func TestLandlockConfinesBashWrites(testingHandle *testing.T) {
+	base := testingHandle.TempDir()
+	root, outside, temp := filepath.Join(base, "work"), filepath.Join(base, "outside"), filepath.Join(base, "tmp")
+	for _, dir := range []string{root, outside, temp} {
+		testutil.RequireNoError(testingHandle, os.Mkdir(dir, 0o755), "mkdir "+dir)
+	}
+	testingHandle.Setenv("TMPDIR", temp)
+
+	result := runSandboxedBash(testingHandle, &OSSandbox{}, root, `echo in > inside.txt && echo tmp > "$TMPDIR/t.txt" && echo quiet > /dev/null && cat inside.txt`)
+	testutil.RequireTrue(testingHandle, !result.IsError && result.Content == "in", "writes inside the roots: "+result.Content)
+
+	result = runSandboxedBash(testingHandle, &OSSandbox{}, root, `echo out > `+filepath.Join(outside, "escape.txt"))
+	testutil.RequireTrue(testingHandle, result.IsError && strings.Contains(result.Content, "Permission denied"), "write outside denied: "+result.Content)
+	_, err := os.Stat(filepath.Join(outside, "escape.txt"))
+	testutil.RequireTrue(testingHandle, os.IsNotExist(err), "no file outside the roots")
+
+	result = runSandboxedBash(testingHandle, &OSSandbox{WritableDirs: []string{outside}}, root, `echo out > `+filepath.Join(outside, "allowed.txt"))
+	testutil.RequireTrue(testingHandle, !result.IsError, "writable dirs extend the roots: "+result.Content)
+}
+
+// TestOSSandboxDisablesNetwork verifies commands cannot reach a local server.
+func TestOSSandboxDisablesNetwork(testingHandle *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	testutil.RequireNoError(testingHandle, err, "listen")
+	defer listener.Close()
+	address := strings.Replace(listener.Addr().String(), ":", "/", 1)
+	root := testingHandle.TempDir()
+
+	result := runSandboxedBash(testingHandle, &OSSandbox{}, root, `echo hi > /dev/tcp/`+address)
+	testutil.RequireTrue(testingHandle, !result.IsError, "network allowed by default: "+result.Content)
+
+	result = runSandboxedBash(testingHandle, &OSSandbox{DisableNetwork: true}, root, `echo hi > /dev/tcp/`+address)
+	if result.IsError && strings.Contains(result.Content, "fork/exec") {
+		testingHandle.Skip("user namespaces unavailable: " + result.Content)
+	}
+	testutil.RequireTrue(testingHandle, result.IsError, "connection refused without network: "+result.Content)
+}
@@ -0,0 +1,23 @@
+//go:build !darwin && !linux
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// checkOSSandbox reports that this platform has no supported OS sandbox.
+func checkOSSandbox() error {
+	return fmt.Errorf("not supported on %s", runtime.GOOS)
+}
+
+// sandboxCommand is unreachable because checkOSSandbox always fails.
+func sandboxCommand(_ context.Context, _ osSandboxSpec, _ string, _ ...string) (*exec.Cmd, error) {
+	return nil, checkOSSandbox()
+}
+
+// RunOSSandboxHelper is a no-op on platforms without an OS sandbox.
+func RunOSSandboxHelper() {}
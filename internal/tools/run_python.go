@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+const (
+	// RunPythonToolName is the tool name used in permission rules and --tools.
+	RunPythonToolName = "RunPython"
+	// defaultPythonInterpreter is used when no interpreter is configured.
+	defaultPythonInterpreter = "python3"
+	// defaultPythonTimeout bounds snippet wall-clock time.
+	defaultPythonTimeout = 60 * time.Second
+	// defaultPythonMemoryBytes caps the interpreter address space.
+	defaultPythonMemoryBytes = 2 << 30
+	// maxPythonFigures caps how many figures are attached to one result.
+	maxPythonFigures = 4
+)
+
+// pythonHarness runs a snippet file under resource limits and saves open
+// matplotlib figures afterwards. Arguments: snippet path, figure directory,
+// address-space limit in bytes, CPU limit in seconds (0 disables a limit).
+const pythonHarness = `import os, sys
+snippet, figures, memory, cpu = sys.argv[1], sys.argv[2], int(sys.argv[3]), int(sys.argv[4])
+try:
+    import resource
+    if memory > 0:
+        resource.setrlimit(resource.RLIMIT_AS, (memory, memory))
+    if cpu > 0:
+        resource.setrlimit(resource.RLIMIT_CPU, (cpu, cpu))
+except (ImportError, ValueError, OSError):
+    pass
+sys.argv = [snippet]
+sys.path.insert(0, os.getcwd())
+try:
+    with open(snippet, encoding="utf-8") as handle:
+        code = compile(handle.read(), snippet, "exec")
+    exec(code, {"__name__": "__main__", "__file__": snippet})
+finally:
+    pyplot = sys.modules.get("matplotlib.pyplot")
+    if pyplot is not None:
+        for index, number in enumerate(pyplot.get_fignums(), 1):
+            pyplot.figure(number).savefig(os.path.join(figures, "figure-%d.png" % index))
+`
+
+// RunPythonTool executes Python snippets in a separate interpreter process.
+// It is an OpenClaude extension, so it is only offered when enabled in the
+// provider config or named in --tools.
+type RunPythonTool struct {
+	// Interpreter is the Python executable (default python3).
+	Interpreter string
+	// Venv runs snippets in a per-session virtualenv under the session store,
+	// which also allows the packages field to pip install dependencies.
+	Venv bool
+	// Timeout bounds wall-clock time per snippet (default 60s).
+	Timeout time.Duration
+	// MemoryLimitBytes caps the interpreter address space (default 2 GiB; negative disables).
+	MemoryLimitBytes int64
+}
+
+func (t *RunPythonTool) Name() string {
+	return RunPythonToolName
+}
+
+func (t *RunPythonTool) Description() string {
+	return "Run a Python snippet in an isolated interpreter with time and memory limits. Use it for calculations and data processing; print results to stdout. Open matplotlib figures are returned as images."
+}
+
+func (t *RunPythonTool) Schema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"code": map[string]any{
+				"type":        "string",
+				"description": "Python source to execute.",
+			},
+			"packages": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Packages to pip install into the session virtualenv first (requires python.venv).",
+			},
+		},
+		"required": []string{"code"},
+	}
+}
+
+func (t *RunPythonTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolContext) (ToolResult, error) {
+	var payload struct {
+		Code     string   `json:"code"`
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(input, &payload); err != nil {
+		return ToolResult{IsError: true, Content: fmt.Sprintf("invalid input: %v", err)}, nil
+	}
+	if strings.TrimSpace(payload.Code) == "" {
+		return ToolResult{IsError: true, Content: "code is required"}, nil
+	}
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = defaultPythonTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	interpreter, err := t.interpreter(ctx, toolCtx)
+	if err != nil {
+		return ToolResult{IsError: true, Content: err.Error()}, nil
+	}
+	if len(payload.Packages) > 0 {
+		if !t.Venv {
+			return ToolResult{IsError: true, Content: "packages require python.venv to be enabled in the provider config"}, nil
+		}
+		if output, err := installPythonPackages(ctx, interpreter, payload.Packages); err != nil {
+			return ToolResult{IsError: true, Content: fmt.Sprintf("pip install failed: %v\n%s", err, output)}, nil
+		}
+	}
+
+	workDir, err := os.MkdirTemp("", "openclaude-python-")
+	if err != nil {
+		return ToolResult{IsError: true, Content: fmt.Sprintf("create work dir: %v", err)}, nil
+	}
+	defer os.RemoveAll(workDir)
+	snippetPath := filepath.Join(workDir, "snippet.py")
+	if err := os.WriteFile(snippetPath, []byte(payload.Code), 0o600); err != nil {
+		return ToolResult{IsError: true, Content: fmt.Sprintf("write snippet: %v", err)}, nil
+	}
+
+	memory := t.MemoryLimitBytes
+	if memory == 0 {
+		memory = defaultPythonMemoryBytes
+	}
+	// The CPU limit backs up the wall-clock timeout for busy loops that ignore signals.
+	cpuSeconds := int64(timeout/time.Second) + 1
+	// -I isolates the interpreter from PYTHON* variables and the user site directory.
+	cmd := exec.CommandContext(ctx, interpreter, "-I", "-c", pythonHarness,
+		snippetPath, workDir, strconv.FormatInt(max(memory, 0), 10), strconv.FormatInt(cpuSeconds, 10))
+	cmd.Dir = toolCtx.CWD
+	cmd.Env = append(pythonEnvironment(interpreter, t.Venv), artifactsEnv(toolCtx)...)
+	// Do not wait on pipes held open by processes the snippet spawned.
+	cmd.WaitDelay = time.Second
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	output := strings.TrimSpace(stdout.String())
+	if stderr.Len() > 0 {
+		if output != "" {
+			output += "\n"
+		}
+		output += strings.TrimSpace(stderr.String())
+	}
+	output = truncateCommandOutput(toolCtx, t.Name(), output)
+
+	result := ToolResult{Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: commandExitCode(runErr), Interrupted: runErr != nil && ctx.Err() != nil}
+	savePythonFigures(toolCtx, workDir)
+	result.Images, result.Content = attachPythonFigures(workDir, output)
+	if runErr != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			runErr = fmt.Errorf("timed out after %s", timeout)
+		}
+		result.IsError = true
+		result.Content = fmt.Sprintf("python failed: %v\n%s", runErr, result.Content)
+	}
+	return result, nil
+}
+
+// interpreter returns the executable for this call, creating the session venv on first use.
+func (t *RunPythonTool) interpreter(ctx context.Context, toolCtx ToolContext) (string, error) {
+	base := t.Interpreter
+	if base == "" {
+		base = defaultPythonInterpreter
+	}
+	if !t.Venv {
+		return base, nil
+	}
+	if toolCtx.Store == nil || toolCtx.SessionID == "" {
+		return "", errors.New("python.venv requires a session store")
+	}
+	venvDir := filepath.Join(toolCtx.Store.BaseDir, "python", toolCtx.SessionID)
+	venvPython := filepath.Join(venvDir, "bin", "python")
+	if runtime.GOOS == "windows" {
+		venvPython = filepath.Join(venvDir, "Scripts", "python.exe")
+	}
+	if _, err := os.Stat(venvPython); err == nil {
+		return venvPython, nil
+	}
+	if output, err := exec.CommandContext(ctx, base, "-m", "venv", venvDir).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("create venv: %v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return venvPython, nil
+}
+
+// installPythonPackages pip installs packages into the interpreter's environment.
+func installPythonPackages(ctx context.Context, interpreter string, packages []string) (string, error) {
+	args := []string{"-m", "pip", "install", "--quiet", "--disable-pip-version-check", "--"}
+	for _, pkg := range packages {
+		if pkg = strings.TrimSpace(pkg); pkg != "" {
+			args = append(args, pkg)
+		}
+	}
+	output, err := exec.CommandContext(ctx, interpreter, args...).CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// pythonEnvironment builds a minimal environment so snippets never see API keys
+// or other secrets exported to the CLI.
+func pythonEnvironment(interpreter string, venv bool) []string {
+	var env []string
+	for _, name := range []string{"PATH", "HOME", "USERPROFILE", "SYSTEMROOT", "TMPDIR", "TEMP", "TMP", "LANG", "LC_ALL"} {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	// Render figures off-screen; the harness saves them as PNGs.
+	env = append(env, "MPLBACKEND=Agg", "PYTHONIOENCODING=utf-8")
+	if venv {
+		env = append(env, "VIRTUAL_ENV="+filepath.Dir(filepath.Dir(interpreter)))
+	}
+	return env
+}
+
+// savePythonFigures keeps captured figures as session artifacts, since the work
+// directory is removed when the call returns.
+func savePythonFigures(toolCtx ToolContext, workDir string) {
+	if toolCtx.Store == nil || toolCtx.SessionID == "" {
+		return
+	}
+	paths, _ := filepath.Glob(filepath.Join(workDir, "figure-*.png"))
+	for _, path := range paths {
+		if data, err := os.ReadFile(path); err == nil {
+			_, _ = toolCtx.Store.WriteArtifact(toolCtx.SessionID, "python-"+filepath.Base(path), data)
+		}
+	}
+}
+
+// attachPythonFigures loads saved figures as image parts and notes them in the output.
+func attachPythonFigures(workDir string, output string) ([]llm.ContentPart, string) {
+	paths, _ := filepath.Glob(filepath.Join(workDir, "figure-*.png"))
+	sort.Strings(paths)
+	var images []llm.ContentPart
+	for _, path := range paths {
+		if len(images) == maxPythonFigures {
+			break
+		}
+		part, err := LoadImagePart(path)
+		if err != nil {
+			continue
+		}
+		images = append(images, part)
+	}
+	if len(paths) == 0 {
+		return nil, output
+	}
+	note := fmt.Sprintf("[captured %d figure(s)", len(paths))
+	if len(paths) > len(images) {
+		note += fmt.Sprintf("; attached %d", len(images))
+	}
+	note += "]"
+	if output == "" {
+		return images, note
+	}
+	return images, output + "\n" + note
+}
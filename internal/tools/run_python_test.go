@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRunPythonTool verifies output capture, error reporting, isolation, and timeouts.
+func TestRunPythonTool(testingHandle *testing.T) {
+	if _, err := exec.LookPath(defaultPythonInterpreter); err != nil {
+		testingHandle.Skip("python3 not available")
+	}
+	testingHandle.Setenv("OPENCLAUDE_TEST_SECRET", "sk-test")
+	toolCtx := ToolContext{CWD: testingHandle.TempDir()}
+	tool := &RunPythonTool{Timeout: 5 * time.Second}
+
+	tests := []struct {
+		name      string
+		code      string
+		wantError bool
+		want      string
+	}{
+		{name: "stdout", code: "print(sum(range(10)))", want: "45"},
+		{name: "stderr and exception", code: "import sys\nprint('warn', file=sys.stderr)\n1/0", wantError: true, want: "ZeroDivisionError"},
+		{name: "environment is scrubbed", code: "import os\nprint(os.environ.get('OPENCLAUDE_TEST_SECRET', 'hidden'))", want: "hidden"},
+		{name: "empty code", code: "  ", wantError: true, want: "code is required"},
+	}
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			payload, _ := json.Marshal(map[string]string{"code": tt.code})
+			result, err := tool.Run(context.Background(), payload, toolCtx)
+			if err != nil {
+				testingHandle.Fatalf("run tool: %v", err)
+			}
+			if result.IsError != tt.wantError {
+				testingHandle.Fatalf("IsError = %v, content %q", result.IsError, result.Content)
+			}
+			if !strings.Contains(result.Content, tt.want) {
+				testingHandle.Fatalf("content %q missing %q", result.Content, tt.want)
+			}
+		})
+	}
+
+	timed := &RunPythonTool{Timeout: 500 * time.Millisecond}
+	result, err := timed.Run(context.Background(), json.RawMessage(`{"code":"while True: pass"}`), toolCtx)
+	if err != nil {
+		testingHandle.Fatalf("run tool: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content, "timed out") {
+		testingHandle.Fatalf("expected timeout, got %q", result.Content)
+	}
+}
+
+// TestRunPythonPermissionCategory verifies RunPython prompts like Bash but has its own rules.
+func TestRunPythonPermissionCategory(testingHandle *testing.T) {
+	args := json.RawMessage(`{"code":"print(1)"}`)
+	if !(Permissions{Mode: PermissionAcceptEdits}).ShouldPromptCall(RunPythonToolName, args) {
+		testingHandle.Fatalf("acceptEdits should prompt for RunPython")
+	}
+	if !(Permissions{Mode: PermissionDefault, AlwaysAllow: []string{"Bash"}}).ShouldPromptCall(RunPythonToolName, args) {
+		testingHandle.Fatalf("Bash approval must not cover RunPython")
+	}
+	if (Permissions{Mode: PermissionDefault, AlwaysAllow: []string{PermissionRuleFor(RunPythonToolName, args)}}).ShouldPromptCall(RunPythonToolName, args) {
+		testingHandle.Fatalf("RunPython rule should approve the tool")
+	}
+}
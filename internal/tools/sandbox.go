@@ -1,11 +1,14 @@
 package tools
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+
+	"github.com/openclaude/openclaude/internal/errcode"
 )
 
 // Sandbox controls filesystem access for tool operations.
@@ -14,18 +17,33 @@ type Sandbox struct {
 	Roots []string
 	// Deny is the denylist of forbidden directory prefixes.
 	Deny []string
+	// ReadOnly lists the Roots that tools may read but not modify. A path
+	// takes the access of the deepest root containing it, so a read-write
+	// root can sit inside a read-only one.
+	ReadOnly []string
+	// HidePatterns are gitignore-style patterns from settings applied after
+	// .claudeignore and .openclaudeignore files; "!pattern" re-includes a path.
+	HidePatterns []string
 }
 
+// The path errors all match errcode.ErrSandbox.
 var (
 	// ErrPathNotAllowed indicates the path is outside allowed roots.
-	ErrPathNotAllowed = errors.New("path not allowed")
+	ErrPathNotAllowed = errcode.New(errcode.Sandbox, "path not allowed")
 	// ErrPathDenied indicates the path is explicitly denied.
-	ErrPathDenied = errors.New("path denied")
+	ErrPathDenied = errcode.New(errcode.Sandbox, "path denied")
+	// ErrPathReadOnly indicates the path is inside a read-only root.
+	ErrPathReadOnly = errcode.New(errcode.Sandbox, "path is in a read-only root")
+	// ErrPathHidden indicates the path is excluded by .claudeignore or .openclaudeignore.
+	ErrPathHidden = errcode.New(errcode.Sandbox, "path is hidden by .claudeignore")
 )
 
 // NewSandbox builds a sandbox from root allowlist and default denylist.
 func NewSandbox(roots []string) *Sandbox {
-	deny := []string{"/proc", "/sys", "/dev"}
+	var deny []string
+	if runtime.GOOS != "windows" {
+		deny = []string{"/proc", "/sys", "/dev"}
+	}
 	home, err := os.UserHomeDir()
 	if err == nil {
 		// Protect SSH keys from accidental exfiltration.
@@ -34,12 +52,25 @@ func NewSandbox(roots []string) *Sandbox {
 	return &Sandbox{Roots: roots, Deny: deny}
 }
 
+// Clone returns a copy of the sandbox that shares no slices with s.
+func (s *Sandbox) Clone() *Sandbox {
+	if s == nil {
+		return nil
+	}
+	return &Sandbox{
+		Roots:        slices.Clone(s.Roots),
+		Deny:         slices.Clone(s.Deny),
+		ReadOnly:     slices.Clone(s.ReadOnly),
+		HidePatterns: slices.Clone(s.HidePatterns),
+	}
+}
+
 // ResolvePath validates and returns a normalized absolute path.
 func (s *Sandbox) ResolvePath(path string, requireExisting bool) (string, error) {
 	if path == "" {
 		return "", fmt.Errorf("empty path: %w", ErrPathNotAllowed)
 	}
-	absolute, err := filepath.Abs(path)
+	absolute, err := filepath.Abs(normalizeSandboxPath(runtime.GOOS, path))
 	if err != nil {
 		return "", fmt.Errorf("resolve path: %w", err)
 	}
@@ -70,7 +101,7 @@ func (s *Sandbox) ResolvePath(path string, requireExisting bool) (string, error)
 		if root == "" {
 			continue
 		}
-		rootAbs, err := filepath.Abs(root)
+		rootAbs, err := filepath.Abs(normalizeSandboxPath(runtime.GOOS, root))
 		if err != nil {
 			continue
 		}
@@ -82,6 +113,91 @@ func (s *Sandbox) ResolvePath(path string, requireExisting bool) (string, error)
 	return "", fmt.Errorf("%w: %s", ErrPathNotAllowed, realPath)
 }
 
+// ResolveFilePath is ResolvePath for tools that read a file or list a
+// directory: hidden paths are rejected.
+func (s *Sandbox) ResolveFilePath(path string, requireExisting bool) (string, error) {
+	resolved, err := s.ResolvePath(path, requireExisting)
+	if err != nil {
+		return "", err
+	}
+	if s.IsHidden(resolved) {
+		return "", fmt.Errorf("%w: %s", ErrPathHidden, resolved)
+	}
+	return resolved, nil
+}
+
+// ResolveWritePath is ResolveFilePath for tools that modify the file: paths
+// in read-only roots are rejected too.
+func (s *Sandbox) ResolveWritePath(path string, requireExisting bool) (string, error) {
+	resolved, err := s.ResolveFilePath(path, requireExisting)
+	if err != nil {
+		return "", err
+	}
+	if s.IsReadOnly(resolved) {
+		return "", fmt.Errorf("%w: %s", ErrPathReadOnly, resolved)
+	}
+	return resolved, nil
+}
+
+// IsHidden reports whether .claudeignore, .openclaudeignore, or HidePatterns
+// exclude the absolute path or one of its parent directories.
+func (s *Sandbox) IsHidden(path string) bool {
+	info, err := os.Stat(path)
+	isDir := err == nil && info.IsDir()
+	root := s.deepestRoot(path)
+	if root == "" {
+		root = filepath.Dir(path)
+	}
+	return NewHiddenMatcher(root, s.HidePatterns).Hidden(path, isDir)
+}
+
+// IsReadOnly reports whether the deepest root containing path is read-only.
+func (s *Sandbox) IsReadOnly(path string) bool {
+	if len(s.ReadOnly) == 0 {
+		return false
+	}
+	deepest := s.deepestRoot(path)
+	return deepest != "" && s.isReadOnlyRoot(deepest)
+}
+
+// deepestRoot returns the absolute form of the deepest root containing path,
+// or "" when no root does.
+func (s *Sandbox) deepestRoot(path string) string {
+	deepest := ""
+	for _, root := range s.Roots {
+		rootAbs, err := filepath.Abs(normalizeSandboxPath(runtime.GOOS, root))
+		if err != nil || root == "" {
+			continue
+		}
+		if isSubpath(rootAbs, path) && len(rootAbs) > len(deepest) {
+			deepest = rootAbs
+		}
+	}
+	return deepest
+}
+
+// isReadOnlyRoot reports whether the absolute root is listed in ReadOnly.
+func (s *Sandbox) isReadOnlyRoot(rootAbs string) bool {
+	for _, root := range s.ReadOnly {
+		if candidate, err := filepath.Abs(normalizeSandboxPath(runtime.GOOS, root)); err == nil && candidate == rootAbs {
+			return true
+		}
+	}
+	return false
+}
+
+// WritableRoots returns the Roots that are not read-only.
+func (s *Sandbox) WritableRoots() []string {
+	var roots []string
+	for _, root := range s.Roots {
+		rootAbs, err := filepath.Abs(normalizeSandboxPath(runtime.GOOS, root))
+		if err != nil || !s.isReadOnlyRoot(rootAbs) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
 // isSubpath returns true when target is equal to or inside root.
 func isSubpath(root string, target string) bool {
 	rel, err := filepath.Rel(root, target)
@@ -93,3 +209,32 @@ func isSubpath(root string, target string) bool {
 	}
 	return !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && rel != ".."
 }
+
+// normalizeSandboxPath rewrites the path spellings models and Git Bash use on
+// Windows: /c/Users/me, /cygdrive/c/Users/me, and /mnt/c/Users/me become
+// C:\Users\me, and forward slashes become backslashes. Drive roots keep
+// their trailing separator. Other systems get the path unchanged.
+func normalizeSandboxPath(goos string, path string) string {
+	if goos != "windows" {
+		return path
+	}
+	slashed := strings.ReplaceAll(path, `\`, "/")
+	for _, prefix := range []string{"/cygdrive/", "/mnt/", "/"} {
+		rest, ok := strings.CutPrefix(slashed, prefix)
+		if !ok || rest == "" || !isDriveLetter(rest[0]) || (len(rest) > 1 && rest[1] != '/') {
+			continue
+		}
+		slashed = strings.ToUpper(rest[:1]) + ":/" + strings.TrimPrefix(rest[1:], "/")
+		break
+	}
+	if len(slashed) == 2 && isDriveLetter(slashed[0]) && slashed[1] == ':' {
+		// "C:" alone means the drive's current directory; models mean its root.
+		slashed += "/"
+	}
+	return strings.ReplaceAll(slashed, "/", `\`)
+}
+
+// isDriveLetter reports whether c can name a Windows drive.
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestSandboxReadOnlyRoots verifies read-only roots can be read but not
+// edited, written, or used as a Bash working directory, and that a
+// read-write root nested inside one stays writable.
+func TestSandboxReadOnlyRoots(testingHandle *testing.T) {
+	// Arrange
+	work := testingHandle.TempDir()
+	reference := testingHandle.TempDir()
+	scratch := filepath.Join(reference, "scratch")
+	testutil.RequireNoError(testingHandle, os.Mkdir(scratch, 0o755), "mkdir scratch")
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(reference, "api.md"), []byte("v1"), 0o600), "write reference")
+	sandbox := NewSandbox([]string{work, reference, scratch})
+	sandbox.ReadOnly = []string{reference}
+	toolCtx := ToolContext{Sandbox: sandbox, CWD: work}
+	run := func(tool Tool, fields map[string]any) ToolResult {
+		input, _ := json.Marshal(fields)
+		result, err := tool.Run(context.Background(), input, toolCtx)
+		testutil.RequireNoError(testingHandle, err, "run "+tool.Name())
+		return result
+	}
+
+	// Act
+	read := run(&ReadTool{}, map[string]any{"file_path": filepath.Join(reference, "api.md")})
+	write := run(&WriteTool{}, map[string]any{"file_path": filepath.Join(reference, "new.md"), "content": "x"})
+	edit := run(&EditTool{}, map[string]any{"file_path": filepath.Join(reference, "api.md"), "old_string": "v1", "new_string": "v2"})
+	nested := run(&WriteTool{}, map[string]any{"file_path": filepath.Join(scratch, "notes.md"), "content": "x"})
+	bash := run(&BashTool{}, map[string]any{"command": "touch touched", "cwd": reference})
+
+	// Assert
+	testutil.RequireTrue(testingHandle, !read.IsError, "read allowed: "+read.Content)
+	testutil.RequireTrue(testingHandle, write.IsError && strings.Contains(write.Content, ErrPathReadOnly.Error()), "write rejected: "+write.Content)
+	testutil.RequireTrue(testingHandle, edit.IsError && strings.Contains(edit.Content, ErrPathReadOnly.Error()), "edit rejected: "+edit.Content)
+	testutil.RequireTrue(testingHandle, !nested.IsError, "nested read-write root writable: "+nested.Content)
+	testutil.RequireTrue(testingHandle, bash.IsError && strings.Contains(bash.Content, "sandbox.enabled"), "bash rejected: "+bash.Content)
+	_, err := os.Stat(filepath.Join(reference, "touched"))
+	testutil.RequireTrue(testingHandle, os.IsNotExist(err), "bash did not run")
+	testutil.RequireEqual(testingHandle, sandbox.WritableRoots(), []string{work, scratch}, "writable roots")
+}
+
+// TestSandboxHiddenPaths verifies .claudeignore and .openclaudeignore hide
+// paths from Read, ListDir, Write, and Grep, that hidePatterns can re-include
+// one, and that .gitignore alone hides nothing.
+func TestSandboxHiddenPaths(testingHandle *testing.T) {
+	// Arrange
+	root := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.MkdirAll(filepath.Join(root, "secrets"), 0o755), "mkdir secrets")
+	files := map[string]string{
+		".claudeignore":     "secrets/\n.env*\n",
+		".openclaudeignore": "*.pem\n",
+		".gitignore":        "build.log\n",
+		".env":              "TOKEN=1\n",
+		".env.example":      "TOKEN=\n",
+		"key.pem":           "-----\n",
+		"build.log":         "ok\n",
+		"secrets/api.txt":   "TOKEN=2\n",
+	}
+	for name, content := range files {
+		testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, name), []byte(content), 0o600), "write "+name)
+	}
+	sandbox := NewSandbox([]string{root})
+	sandbox.HidePatterns = []string{"!.env.example"}
+	toolCtx := ToolContext{Sandbox: sandbox, CWD: root}
+	run := func(tool Tool, fields map[string]any) ToolResult {
+		input, _ := json.Marshal(fields)
+		result, err := tool.Run(context.Background(), input, toolCtx)
+		testutil.RequireNoError(testingHandle, err, "run "+tool.Name())
+		return result
+	}
+
+	// Act
+	readEnv := run(&ReadTool{}, map[string]any{"file_path": filepath.Join(root, ".env")})
+	readNested := run(&ReadTool{}, map[string]any{"file_path": filepath.Join(root, "secrets", "api.txt")})
+	readPem := run(&ReadTool{}, map[string]any{"file_path": filepath.Join(root, "key.pem")})
+	readExample := run(&ReadTool{}, map[string]any{"file_path": filepath.Join(root, ".env.example")})
+	readGitignored := run(&ReadTool{}, map[string]any{"file_path": filepath.Join(root, "build.log")})
+	write := run(&WriteTool{}, map[string]any{"file_path": filepath.Join(root, "secrets", "new.txt"), "content": "x"})
+	listing := run(&ListDirTool{}, map[string]any{"path": root})
+	grep := run(&GrepTool{}, map[string]any{"query": "TOKEN"})
+
+	// Assert
+	for name, result := range map[string]ToolResult{"env": readEnv, "nested": readNested, "pem": readPem, "write": write} {
+		testutil.RequireTrue(testingHandle, result.IsError && strings.Contains(result.Content, ErrPathHidden.Error()), name+" hidden: "+result.Content)
+	}
+	testutil.RequireTrue(testingHandle, !readExample.IsError, "hidePatterns re-include: "+readExample.Content)
+	testutil.RequireTrue(testingHandle, !readGitignored.IsError, ".gitignore does not hide: "+readGitignored.Content)
+	testutil.RequireTrue(testingHandle, strings.Contains(listing.Content, ".env.example") && !strings.Contains(listing.Content, "secrets") && !strings.Contains(listing.Content, "key.pem"), "listing: "+listing.Content)
+	testutil.RequireEqual(testingHandle, grep.Content, filepath.Join(root, ".env.example")+":1:TOKEN=", "grep skips hidden files")
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// gitBashPathEnvVar points at Git Bash on Windows, as in Claude Code.
+const gitBashPathEnvVar = "CLAUDE_CODE_GIT_BASH_PATH"
+
+// Shell kinds the Bash tool can run commands with.
+const (
+	shellBash       = "bash"
+	shellSh         = "sh"
+	shellPowerShell = "powershell"
+	shellCmd        = "cmd"
+)
+
+// shellSpec is the program and leading arguments that run a command string.
+type shellSpec struct {
+	// Kind is one of the shell kinds above; it tells the model which syntax to use.
+	Kind string
+	// Path is the shell executable.
+	Path string
+	// Args precede the command string.
+	Args []string
+}
+
+// argv returns the arguments that run command with the shell.
+func (s shellSpec) argv(command string) []string {
+	return append(append([]string(nil), s.Args...), command)
+}
+
+// shellHost is what detectShell looks at, so tests can stand in for Windows.
+type shellHost struct {
+	// goos is the target operating system.
+	goos string
+	// lookPath finds executables on PATH.
+	lookPath func(string) (string, error)
+	// getenv reads environment variables.
+	getenv func(string) string
+	// exists reports whether a file exists.
+	exists func(string) bool
+}
+
+// hostShell is the shell for this machine, detected once.
+var hostShell = sync.OnceValue(func() shellSpec {
+	return detectShell(shellHost{
+		goos:     runtime.GOOS,
+		lookPath: exec.LookPath,
+		getenv:   os.Getenv,
+		exists: func(path string) bool {
+			info, err := os.Stat(path)
+			return err == nil && !info.IsDir()
+		},
+	})
+})
+
+// detectShell picks the shell for Bash tool commands. Unix systems use a bash
+// login shell, or sh without bash. Native Windows prefers Git Bash (from
+// CLAUDE_CODE_GIT_BASH_PATH, next to git, or the default install location),
+// then PowerShell, then cmd.
+func detectShell(host shellHost) shellSpec {
+	if host.goos != "windows" {
+		if path, err := host.lookPath("bash"); err == nil {
+			return shellSpec{Kind: shellBash, Path: path, Args: []string{"-lc"}}
+		}
+		return shellSpec{Kind: shellSh, Path: "/bin/sh", Args: []string{"-c"}}
+	}
+	// A login shell would move Git Bash to the home directory, so -c is used.
+	if path := gitBashPath(host); path != "" {
+		return shellSpec{Kind: shellBash, Path: path, Args: []string{"-c"}}
+	}
+	for _, name := range []string{"pwsh", "powershell"} {
+		if path, err := host.lookPath(name); err == nil {
+			return shellSpec{Kind: shellPowerShell, Path: path, Args: []string{"-NoLogo", "-NoProfile", "-NonInteractive", "-Command"}}
+		}
+	}
+	comspec := host.getenv("ComSpec")
+	if comspec == "" {
+		comspec = "cmd.exe"
+	}
+	return shellSpec{Kind: shellCmd, Path: comspec, Args: []string{"/C"}}
+}
+
+// gitBashPath finds Git for Windows' bash.exe, or returns "".
+func gitBashPath(host shellHost) string {
+	if path := host.getenv(gitBashPathEnvVar); path != "" && host.exists(path) {
+		return path
+	}
+	var candidates []string
+	if git, err := host.lookPath("git"); err == nil {
+		// git.exe lives in Git\cmd or Git\bin; bash.exe is in Git\bin.
+		root := filepath.Dir(filepath.Dir(git))
+		candidates = append(candidates, filepath.Join(root, "bin", "bash.exe"))
+	}
+	if dir := host.getenv("ProgramFiles"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "Git", "bin", "bash.exe"))
+	}
+	if dir := host.getenv("LocalAppData"); dir != "" {
+		// Per-user installs go under %LocalAppData%\Programs.
+		candidates = append(candidates, filepath.Join(dir, "Programs", "Git", "bin", "bash.exe"))
+	}
+	if bash, err := host.lookPath("bash"); err == nil && !isWSLLauncher(bash) {
+		candidates = append(candidates, bash)
+	}
+	for _, candidate := range candidates {
+		if host.exists(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// isWSLLauncher reports whether path is System32's bash.exe, which starts WSL
+// rather than a Windows shell.
+func isWSLLauncher(path string) bool {
+	return strings.Contains(strings.ToLower(strings.ReplaceAll(path, `\`, "/")), "/windows/system32/")
+}
+
+// shellDescription tells the model which shell runs its commands.
+func shellDescription(spec shellSpec) string {
+	switch spec.Kind {
+	case shellPowerShell:
+		return " Commands run in PowerShell, so use PowerShell syntax."
+	case shellCmd:
+		return " Commands run in cmd.exe, so use cmd syntax."
+	case shellSh:
+		return " Commands run in a POSIX sh shell."
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestDetectShell verifies the shell picked on Unix and native Windows hosts.
+func TestDetectShell(testingHandle *testing.T) {
+	gitBash := filepath.Join(`C:\Program Files\Git`, "bin", "bash.exe")
+	tests := []struct {
+		name  string
+		goos  string
+		path  map[string]string
+		env   map[string]string
+		files []string
+		want  shellSpec
+	}{
+		{name: "unix bash", goos: "linux", path: map[string]string{"bash": "/usr/bin/bash"}, want: shellSpec{Kind: shellBash, Path: "/usr/bin/bash", Args: []string{"-lc"}}},
+		{name: "unix without bash", goos: "linux", want: shellSpec{Kind: shellSh, Path: "/bin/sh", Args: []string{"-c"}}},
+		{name: "git bash override", goos: "windows", env: map[string]string{gitBashPathEnvVar: `D:\git\bash.exe`}, files: []string{`D:\git\bash.exe`}, want: shellSpec{Kind: shellBash, Path: `D:\git\bash.exe`, Args: []string{"-c"}}},
+		{name: "git bash next to git", goos: "windows", path: map[string]string{"git": filepath.Join(`C:\Program Files\Git`, "cmd", "git.exe")}, files: []string{gitBash}, want: shellSpec{Kind: shellBash, Path: gitBash, Args: []string{"-c"}}},
+		{name: "wsl launcher skipped", goos: "windows", path: map[string]string{"bash": `C:\Windows\System32\bash.exe`, "pwsh": `C:\pwsh\pwsh.exe`}, files: []string{`C:\Windows\System32\bash.exe`}, want: shellSpec{Kind: shellPowerShell, Path: `C:\pwsh\pwsh.exe`, Args: []string{"-NoLogo", "-NoProfile", "-NonInteractive", "-Command"}}},
+		{name: "cmd fallback", goos: "windows", env: map[string]string{"ComSpec": `C:\Windows\system32\cmd.exe`}, want: shellSpec{Kind: shellCmd, Path: `C:\Windows\system32\cmd.exe`, Args: []string{"/C"}}},
+	}
+
+	for _, tt := range tests {
+		testingHandle.Run(tt.name, func(testingHandle *testing.T) {
+			// Arrange.
+			host := shellHost{
+				goos: tt.goos,
+				lookPath: func(name string) (string, error) {
+					if path, ok := tt.path[name]; ok {
+						return path, nil
+					}
+					return "", errors.New("not found")
+				},
+				getenv: func(name string) string { return tt.env[name] },
+				exists: func(path string) bool {
+					for _, file := range tt.files {
+						if file == path {
+							return true
+						}
+					}
+					return false
+				},
+			}
+
+			// Act.
+			got := detectShell(host)
+
+			// Assert.
+			testutil.RequireEqual(testingHandle, got, tt.want, "shell")
+		})
+	}
+}
+
+// TestNormalizeSandboxPath verifies Windows path spellings are normalized and
+// other systems keep paths as given.
+func TestNormalizeSandboxPath(testingHandle *testing.T) {
+	tests := []struct {
+		goos string
+		in   string
+		want string
+	}{
+		{goos: "windows", in: "/c/Users/me/repo", want: `C:\Users\me\repo`},
+		{goos: "windows", in: "/cygdrive/d/src", want: `D:\src`},
+		{goos: "windows", in: "/mnt/c", want: `C:\`},
+		{goos: "windows", in: "C:/Users/me", want: `C:\Users\me`},
+		{goos: "windows", in: "c:", want: `c:\`},
+		{goos: "windows", in: "src/main.go", want: `src\main.go`},
+		{goos: "windows", in: "/src/main.go", want: `\src\main.go`},
+		{goos: "windows", in: `\\server\share\file`, want: `\\server\share\file`},
+		{goos: "linux", in: "/c/Users", want: "/c/Users"},
+	}
+
+	for _, tt := range tests {
+		// Act and assert.
+		testutil.RequireEqual(testingHandle, normalizeSandboxPath(tt.goos, tt.in), tt.want, tt.goos+" "+tt.in)
+	}
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+
+	"github.com/openclaude/openclaude/internal/llm"
+)
+
+// similarPromptBits is the largest fingerprint distance, in differing bits, at
+// which two task prompts count as the same request.
+const similarPromptBits = 3
+
+// PromptFingerprint returns a 64-bit similarity hash (simhash) of a prompt's
+// words and word pairs. Case, punctuation, and spacing are ignored, so
+// near-identical prompts get fingerprints only a few bits apart. An empty
+// prompt has fingerprint zero.
+func PromptFingerprint(prompt string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(prompt), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+	var weights [64]int
+	add := func(feature string) {
+		hasher := fnv.New64a()
+		_, _ = hasher.Write([]byte(feature))
+		sum := hasher.Sum64()
+		for bit := range weights {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	for index, word := range words {
+		add(word)
+		if index > 0 {
+			add(words[index-1] + " " + word)
+		}
+	}
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// taskPromptText returns the text a task request asks for: its prompt, or
+// the last user message when it carries a message history.
+func taskPromptText(request TaskRequest) string {
+	if request.Prompt != "" {
+		return request.Prompt
+	}
+	for index := len(request.Messages) - 1; index >= 0; index-- {
+		if request.Messages[index].Role == "user" {
+			return llm.ContentText(request.Messages[index].Content)
+		}
+	}
+	return ""
+}
+
+// recursiveTaskResult refuses a Task call whose prompt repeats an enclosing
+// task's prompt, returning a structured error the model can act on, or false
+// when the call may run.
+func recursiveTaskResult(fingerprint uint64, lineage []uint64) (ToolResult, bool) {
+	if fingerprint == 0 {
+		return ToolResult{}, false
+	}
+	for depth, ancestor := range lineage {
+		if bits.OnesCount64(fingerprint^ancestor) > similarPromptBits {
+			continue
+		}
+		encoded, _ := json.Marshal(map[string]any{
+			"status": "refused",
+			"error":  "recursive_task",
+			"message": fmt.Sprintf("This Task prompt is essentially identical to the prompt of the task at depth %d that is running it. "+
+				"Delegating the same work again would loop; do the work directly with the other tools, or split it into a narrower subtask.", depth+1),
+			"depth": depth + 1,
+		})
+		return ToolResult{IsError: true, Content: string(encoded)}, true
+	}
+	return ToolResult{}, false
+}
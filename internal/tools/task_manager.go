@@ -2,20 +2,65 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"sync"
 )
 
-// TaskManager tracks running task cancellation hooks.
+// ErrTaskManagerClosed is returned when a background task starts after Shutdown.
+var ErrTaskManagerClosed = errors.New("task manager is shut down")
+
+// TaskManager tracks running background tasks and their cancellation hooks.
+// Background tasks run under a manager-scoped context so Shutdown can cancel
+// them and wait until their final records are written.
 type TaskManager struct {
 	mu      sync.Mutex
 	cancels map[string]context.CancelFunc
+	// ctx is the run-scoped parent context for background tasks.
+	ctx context.Context
+	// stop cancels ctx and every task derived from it.
+	stop context.CancelFunc
+	// running counts background goroutines that have not finished.
+	running sync.WaitGroup
+	// closed rejects new background tasks once Shutdown begins.
+	closed bool
 }
 
 // NewTaskManager constructs an empty task manager.
 func NewTaskManager() *TaskManager {
+	ctx, stop := context.WithCancel(context.Background())
 	return &TaskManager{
 		cancels: map[string]context.CancelFunc{},
+		ctx:     ctx,
+		stop:    stop,
+	}
+}
+
+// Go runs fn in a background goroutine registered under taskID.
+// The context passed to fn is cancelled by Cancel(taskID) or Shutdown.
+func (m *TaskManager) Go(taskID string, fn func(ctx context.Context)) error {
+	if m == nil || fn == nil {
+		return errors.New("task manager is not configured")
 	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrTaskManagerClosed
+	}
+	taskCtx, cancel := context.WithCancel(m.ctx)
+	if taskID != "" {
+		m.cancels[taskID] = cancel
+	}
+	// Add under the lock so Shutdown never waits on a partially started task.
+	m.running.Add(1)
+	m.mu.Unlock()
+
+	go func() {
+		defer m.running.Done()
+		defer cancel()
+		defer m.Unregister(taskID)
+		fn(taskCtx)
+	}()
+	return nil
 }
 
 // Register associates a task id with its cancel function.
@@ -55,3 +100,19 @@ func (m *TaskManager) Cancel(taskID string) bool {
 	cancel()
 	return true
 }
+
+// Shutdown cancels all background tasks and waits for their goroutines to exit.
+// Tasks observe cancellation through their context and record a final status
+// before returning, so session files are never left with truncated task records.
+func (m *TaskManager) Shutdown() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	if m.stop != nil {
+		m.stop()
+	}
+	m.running.Wait()
+}
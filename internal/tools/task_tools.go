@@ -5,14 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
-	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/session"
 )
 
 // taskRecord captures task metadata persisted in the session store.
@@ -70,6 +69,13 @@ func (t *TaskTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 	if toolCtx.TaskMaxDepth > 0 && toolCtx.TaskDepth >= toolCtx.TaskMaxDepth {
 		return ToolResult{IsError: true, Content: "task nesting limit reached"}, nil
 	}
+	// A subagent delegating its own prompt again would loop until the depth or
+	// budget limit stops it, so refuse before recording anything.
+	parsed, _ := buildTaskRequest(payload)
+	fingerprint := PromptFingerprint(taskPromptText(parsed))
+	if refused, ok := recursiveTaskResult(fingerprint, toolCtx.TaskLineage); ok {
+		return refused, nil
+	}
 
 	taskID := extractTaskID(payload)
 	if taskID == "" {
@@ -92,14 +98,15 @@ func (t *TaskTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
+	request.Lineage = append(append([]uint64(nil), toolCtx.TaskLineage...), fingerprint)
+	if toolCtx.TaskBudgetUSD > 0 && (request.MaxBudgetUSD <= 0 || request.MaxBudgetUSD > toolCtx.TaskBudgetUSD) {
+		request.MaxBudgetUSD = toolCtx.TaskBudgetUSD
+	}
 
 	if isAsyncTask(payload) {
 		if toolCtx.TaskManager == nil {
 			return ToolResult{IsError: true, Content: "task manager is not configured"}, nil
 		}
-		taskCtx, cancel := context.WithCancel(context.Background())
-		toolCtx.TaskManager.Register(taskID, cancel)
-
 		_ = appendTaskRecord(toolCtx, taskRecord{
 			Type:      "output",
 			ID:        taskID,
@@ -108,8 +115,8 @@ func (t *TaskTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 			Payload:   payload,
 		})
 
-		go func() {
-			defer toolCtx.TaskManager.Unregister(taskID)
+		// The manager owns the goroutine so shutdown waits for the final record.
+		err := toolCtx.TaskManager.Go(taskID, func(taskCtx context.Context) {
 			taskResult, err := toolCtx.TaskExecutor.ExecuteTask(taskCtx, request)
 			status := "completed"
 			output := taskResult.Output
@@ -129,7 +136,18 @@ func (t *TaskTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 				Payload:   payload,
 				Output:    output,
 			})
-		}()
+		})
+		if err != nil {
+			_ = appendTaskRecord(toolCtx, taskRecord{
+				Type:      "output",
+				ID:        taskID,
+				Status:    "failed",
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+				Payload:   payload,
+				Output:    err.Error(),
+			})
+			return ToolResult{IsError: true, Content: err.Error()}, nil
+		}
 
 		response := map[string]any{
 			"id":     taskID,
@@ -173,7 +191,7 @@ func (t *TaskTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolC
 		response[key] = value
 	}
 	encoded, _ := json.Marshal(response)
-	return ToolResult{Content: string(encoded)}, nil
+	return ToolResult{Content: string(encoded), Usage: taskResult.Usage}, nil
 }
 
 // TaskOutputTool appends output metadata for a task.
@@ -362,16 +380,29 @@ func buildTaskRequest(payload map[string]any) (TaskRequest, error) {
 	if value, ok := payload["model"].(string); ok {
 		request.Model = strings.TrimSpace(value)
 	}
+	if value, ok := payload["subagent_type"].(string); ok {
+		request.SubagentType = strings.TrimSpace(value)
+	}
+	if request.SubagentType == "" {
+		if value, ok := payload["subagentType"].(string); ok {
+			request.SubagentType = strings.TrimSpace(value)
+		}
+	}
 	if value, ok := payload["max_turns"]; ok {
 		if num, ok := value.(float64); ok {
 			request.MaxTurns = int(num)
 		}
 	}
+	if value, ok := payload["max_budget_usd"]; ok {
+		if num, ok := value.(float64); ok && num > 0 {
+			request.MaxBudgetUSD = num
+		}
+	}
 
 	if rawMessages, ok := payload["messages"]; ok {
 		encoded, err := json.Marshal(rawMessages)
 		if err == nil {
-			var messages []openai.Message
+			var messages []llm.Message
 			if err := json.Unmarshal(encoded, &messages); err == nil {
 				request.Messages = messages
 			}
@@ -401,27 +432,21 @@ func loadLatestTaskOutput(toolCtx ToolContext, taskID string) (string, error) {
 	if taskID == "" {
 		return "", fmt.Errorf("task_id is required")
 	}
-	path := taskLogPath(toolCtx)
-	if path == "" {
+	if toolCtx.Store == nil || toolCtx.SessionID == "" {
 		return "", fmt.Errorf("task store unavailable")
 	}
-	data, err := os.ReadFile(path)
+	events, err := toolCtx.Store.LoadMetadataEvents(toolCtx.SessionID)
 	if err != nil {
 		return "", fmt.Errorf("read task log: %v", err)
 	}
 
 	var latest string
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+	for _, event := range events {
+		if event.Type != session.MetadataTaskUpdated || event.TaskID != taskID {
 			continue
 		}
 		var record taskRecord
-		if err := json.Unmarshal([]byte(line), &record); err != nil {
-			continue
-		}
-		if record.ID != taskID || record.Output == "" {
+		if err := json.Unmarshal(event.Task, &record); err != nil || record.Output == "" {
 			continue
 		}
 		latest = record.Output
@@ -432,36 +457,20 @@ func loadLatestTaskOutput(toolCtx ToolContext, taskID string) (string, error) {
 	return latest, nil
 }
 
-// taskLogPath returns the tasks.jsonl path.
-func taskLogPath(toolCtx ToolContext) string {
-	if toolCtx.Store == nil || toolCtx.SessionID == "" {
-		return ""
-	}
-	return filepath.Join(toolCtx.Store.BaseDir, "session-env", toolCtx.SessionID, "tasks.jsonl")
-}
-
-// appendTaskRecord appends a task record to the session store when available.
+// appendTaskRecord records a task_updated metadata event when a session store is available.
 func appendTaskRecord(toolCtx ToolContext, record taskRecord) error {
 	if toolCtx.Store == nil || toolCtx.SessionID == "" {
 		return nil
 	}
-
-	path := taskLogPath(toolCtx)
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
-	}
-	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
 	data, err := json.Marshal(record)
 	if err != nil {
 		return err
 	}
-	if _, err := file.Write(append(data, '\n')); err != nil {
-		return err
-	}
-	return nil
+	_, err = toolCtx.Store.AppendMetadata(toolCtx.SessionID, session.MetadataEvent{
+		Type:       session.MetadataTaskUpdated,
+		TaskID:     record.ID,
+		TaskStatus: record.Status,
+		Task:       data,
+	})
+	return err
 }
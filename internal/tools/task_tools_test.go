@@ -1,11 +1,8 @@
 package tools
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
-	"os"
-	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -64,6 +61,91 @@ func TestTaskToolPersistsRecord(testingHandle *testing.T) {
 	}
 }
 
+// TestTaskToolCapsBudget verifies TaskBudgetUSD lowers a larger or missing
+// requested budget and keeps a smaller one.
+func TestTaskToolCapsBudget(testingHandle *testing.T) {
+	cases := map[string]float64{`{"prompt":"a"}`: 0.5, `{"prompt":"b","max_budget_usd":2}`: 0.5, `{"prompt":"c","max_budget_usd":0.2}`: 0.2}
+	for input, want := range cases {
+		var got float64
+		toolCtx := ToolContext{
+			Store:         &session.Store{BaseDir: testingHandle.TempDir()},
+			SessionID:     "session-1",
+			TaskBudgetUSD: 0.5,
+			TaskExecutor: TaskExecutorFunc(func(ctx context.Context, request TaskRequest) (TaskResult, error) {
+				got = request.MaxBudgetUSD
+				return TaskResult{Output: "done"}, nil
+			}),
+		}
+		if _, err := (&TaskTool{}).Run(context.Background(), json.RawMessage(input), toolCtx); err != nil {
+			testingHandle.Fatalf("run tool: %v", err)
+		}
+		if got != want {
+			testingHandle.Fatalf("%s: budget %v, want %v", input, got, want)
+		}
+	}
+}
+
+// TestBuildTaskRequestSubagentType verifies subagent_type is parsed in both spellings.
+func TestBuildTaskRequestSubagentType(testingHandle *testing.T) {
+	for _, key := range []string{"subagent_type", "subagentType"} {
+		request, err := buildTaskRequest(map[string]any{
+			"prompt": "review",
+			key:      " reviewer ",
+		})
+		if err != nil {
+			testingHandle.Fatalf("build request: %v", err)
+		}
+		if request.SubagentType != "reviewer" {
+			testingHandle.Fatalf("expected reviewer for %s, got %q", key, request.SubagentType)
+		}
+	}
+}
+
+// TestTaskToolRefusesRecursivePrompt verifies a subagent cannot delegate its own prompt again.
+func TestTaskToolRefusesRecursivePrompt(testingHandle *testing.T) {
+	var requests []TaskRequest
+	parentPrompt := "Audit the repository for unused exported functions."
+	toolCtx := ToolContext{
+		Store:        &session.Store{BaseDir: testingHandle.TempDir()},
+		SessionID:    "session-1",
+		TaskDepth:    1,
+		TaskMaxDepth: 3,
+		TaskLineage:  []uint64{PromptFingerprint(parentPrompt)},
+		TaskExecutor: TaskExecutorFunc(func(ctx context.Context, request TaskRequest) (TaskResult, error) {
+			requests = append(requests, request)
+			return TaskResult{Output: "done"}, nil
+		}),
+	}
+	run := func(prompt string) ToolResult {
+		payload, _ := json.Marshal(map[string]any{"prompt": prompt})
+		result, err := (&TaskTool{}).Run(context.Background(), payload, toolCtx)
+		if err != nil {
+			testingHandle.Fatalf("run tool: %v", err)
+		}
+		return result
+	}
+
+	refused := run("  audit the repository for unused exported functions!")
+	var response map[string]any
+	if err := json.Unmarshal([]byte(refused.Content), &response); err != nil {
+		testingHandle.Fatalf("parse refusal %q: %v", refused.Content, err)
+	}
+	if !refused.IsError || response["error"] != "recursive_task" || response["depth"] != 1.0 {
+		testingHandle.Fatalf("expected a recursive_task refusal, got %s", refused.Content)
+	}
+	if len(requests) != 0 {
+		testingHandle.Fatalf("expected the executor not to run, got %d requests", len(requests))
+	}
+
+	allowed := run("List the exported functions in internal/tools and where each is called.")
+	if allowed.IsError {
+		testingHandle.Fatalf("unexpected error: %s", allowed.Content)
+	}
+	if len(requests) != 1 || len(requests[0].Lineage) != 2 || requests[0].Lineage[0] != toolCtx.TaskLineage[0] {
+		testingHandle.Fatalf("expected the subtask lineage to extend the parent's, got %+v", requests)
+	}
+}
+
 // TestTaskToolAsyncCompletes verifies async tasks report running then complete.
 func TestTaskToolAsyncCompletes(testingHandle *testing.T) {
 	store := &session.Store{BaseDir: testingHandle.TempDir()}
@@ -256,6 +338,50 @@ func TestTaskStopCancels(testingHandle *testing.T) {
 	})
 }
 
+// TestTaskManagerShutdownWaitsForRecords verifies shutdown cancels async tasks and waits for their final records.
+func TestTaskManagerShutdownWaitsForRecords(testingHandle *testing.T) {
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	manager := NewTaskManager()
+	toolCtx := ToolContext{
+		Store:        store,
+		SessionID:    "session-shutdown",
+		TaskMaxDepth: 2,
+		TaskManager:  manager,
+		TaskExecutor: TaskExecutorFunc(func(ctx context.Context, request TaskRequest) (TaskResult, error) {
+			_ = request
+			<-ctx.Done()
+			return TaskResult{}, ctx.Err()
+		}),
+	}
+	payload, err := json.Marshal(map[string]any{"task_id": "bg-1", "title": "demo", "async": true})
+	if err != nil {
+		testingHandle.Fatalf("marshal payload: %v", err)
+	}
+
+	taskTool := &TaskTool{}
+	if result, runErr := taskTool.Run(context.Background(), payload, toolCtx); runErr != nil || result.IsError {
+		testingHandle.Fatalf("run task tool: %v %s", runErr, result.Content)
+	}
+
+	manager.Shutdown()
+
+	// No polling: the cancelled record must already be on disk once Shutdown returns.
+	records := loadTaskRecords(testingHandle, store, toolCtx.SessionID)
+	last := records[len(records)-1]
+	if last.ID != "bg-1" || last.Status != "cancelled" {
+		testingHandle.Fatalf("expected final cancelled record, got %+v", last)
+	}
+
+	// Tasks started after shutdown are refused rather than leaked.
+	result, runErr := taskTool.Run(context.Background(), payload, toolCtx)
+	if runErr != nil {
+		testingHandle.Fatalf("run task tool after shutdown: %v", runErr)
+	}
+	if !result.IsError || !strings.Contains(result.Content, ErrTaskManagerClosed.Error()) {
+		testingHandle.Fatalf("expected shutdown error, got %+v", result)
+	}
+}
+
 // TestTaskStopRequiresID verifies task stop requires a task id.
 func TestTaskStopRequiresID(testingHandle *testing.T) {
 	tool := &TaskStopTool{}
@@ -273,32 +399,27 @@ func TestTaskStopRequiresID(testingHandle *testing.T) {
 	}
 }
 
-// loadTaskRecords reads task records from the session store for assertions.
+// loadTaskRecords reads task records from the session metadata log for assertions.
 func loadTaskRecords(testingHandle *testing.T, store *session.Store, sessionID string) []taskRecord {
 	testingHandle.Helper()
 
-	path := filepath.Join(store.BaseDir, "session-env", sessionID, "tasks.jsonl")
-	file, err := os.Open(path)
+	events, err := store.LoadMetadataEvents(sessionID)
 	if err != nil {
-		testingHandle.Fatalf("open tasks file: %v", err)
+		testingHandle.Fatalf("load metadata: %v", err)
 	}
-	defer file.Close()
-
 	var records []taskRecord
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
+	for _, event := range events {
+		if event.Type != session.MetadataTaskUpdated {
 			continue
 		}
 		var record taskRecord
-		if err := json.Unmarshal([]byte(line), &record); err != nil {
+		if err := json.Unmarshal(event.Task, &record); err != nil {
 			testingHandle.Fatalf("parse record: %v", err)
 		}
 		records = append(records, record)
 	}
-	if err := scanner.Err(); err != nil {
-		testingHandle.Fatalf("scan records: %v", err)
+	if len(records) == 0 {
+		testingHandle.Fatalf("no task records for %s", sessionID)
 	}
 	return records
 }
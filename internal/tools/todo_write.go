@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+
+	"github.com/openclaude/openclaude/internal/session"
 )
 
 // TodoWriteTool persists a structured todo list for the current session.
@@ -44,7 +44,7 @@ func (t *TodoWriteTool) Schema() map[string]any {
 	}
 }
 
-// Run validates the payload and persists it under the session directory.
+// Run validates the payload and records it as a todo_updated metadata event.
 func (t *TodoWriteTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolContext) (ToolResult, error) {
 	// The tool is synchronous, so the context is unused by design.
 	_ = ctx
@@ -68,20 +68,16 @@ func (t *TodoWriteTool) Run(ctx context.Context, input json.RawMessage, toolCtx
 		return ToolResult{Content: string(encoded)}, nil
 	}
 
-	path := filepath.Join(toolCtx.Store.BaseDir, "session-env", toolCtx.SessionID, "todo.json")
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return ToolResult{IsError: true, Content: fmt.Sprintf("persist todo list: %v", err)}, nil
-	}
-	encoded, err := json.MarshalIndent(payload, "", "  ")
+	todos, err := json.Marshal(payload["todos"])
 	if err != nil {
 		return ToolResult{IsError: true, Content: fmt.Sprintf("encode todo list: %v", err)}, nil
 	}
-	if err := os.WriteFile(path, encoded, 0o600); err != nil {
-		return ToolResult{IsError: true, Content: fmt.Sprintf("write todo list: %v", err)}, nil
+	if _, err := toolCtx.Store.AppendMetadata(toolCtx.SessionID, session.MetadataEvent{Type: session.MetadataTodoUpdated, Todos: todos}); err != nil {
+		return ToolResult{IsError: true, Content: fmt.Sprintf("persist todo list: %v", err)}, nil
 	}
 
 	result["persisted"] = true
-	result["path"] = path
-	encoded, _ = json.Marshal(result)
+	result["path"] = toolCtx.Store.MetadataPath(toolCtx.SessionID)
+	encoded, _ := json.Marshal(result)
 	return ToolResult{Content: string(encoded)}, nil
 }
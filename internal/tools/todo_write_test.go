@@ -3,8 +3,6 @@ package tools
 import (
 	"context"
 	"encoding/json"
-	"os"
-	"path/filepath"
 	"testing"
 
 	"github.com/openclaude/openclaude/internal/session"
@@ -33,9 +31,12 @@ func TestTodoWriteToolPersists(testingHandle *testing.T) {
 		testingHandle.Fatalf("unexpected error: %s", result.Content)
 	}
 
-	path := filepath.Join(store.BaseDir, "session-env", "session-1", "todo.json")
-	if _, err := os.Stat(path); err != nil {
-		testingHandle.Fatalf("expected todo file: %v", err)
+	metadata, err := store.LoadMetadata("session-1")
+	if err != nil {
+		testingHandle.Fatalf("load metadata: %v", err)
+	}
+	if string(metadata.Todos) != `[{"completed":false,"text":"ship it"}]` {
+		testingHandle.Fatalf("expected todo_updated event, got: %s", metadata.Todos)
 	}
 	if !json.Valid([]byte(result.Content)) {
 		testingHandle.Fatalf("expected JSON response, got: %s", result.Content)
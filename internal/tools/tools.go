@@ -1,13 +1,18 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
+	"sync"
+	"time"
 
-	"github.com/openclaude/openclaude/internal/llm/openai"
+	"github.com/openclaude/openclaude/internal/llm"
+	"github.com/openclaude/openclaude/internal/redact"
 	"github.com/openclaude/openclaude/internal/session"
 )
 
@@ -27,8 +32,181 @@ type ToolContext struct {
 	TaskDepth int
 	// TaskMaxDepth caps nested task execution depth (0 disables nesting).
 	TaskMaxDepth int
+	// TaskLineage holds the PromptFingerprint of each enclosing Task run,
+	// outermost first; Task refuses prompts that repeat one of them.
+	TaskLineage []uint64
+	// TaskBudgetUSD caps the spend of a Task subagent started with this
+	// context; zero leaves the subagent's own budget in place. The agent
+	// refuses Tasks once a budgeted run has spent it, so zero never means spent.
+	TaskBudgetUSD float64
 	// TaskManager tracks async task execution state.
 	TaskManager *TaskManager
+	// DeferUserInput makes AskUserQuestion without a TTY pause the run with a
+	// UserInputRequiredError instead of failing (print mode).
+	DeferUserInput bool
+	// Progress receives incremental progress from tools that can report it; nil ignores reports.
+	Progress func(progress Progress)
+	// Output receives output from tools that produce it gradually (Bash, Grep) as
+	// batches of complete lines while they run; nil ignores it. The final
+	// ToolResult still carries the whole output.
+	Output func(text string)
+}
+
+// Clone returns a copy of the context for a subagent. The sandbox and task
+// lineage are copied, so changes the parent makes while the subagent runs (such
+// as a settings reload) do not reach it, and the subagent cannot change the
+// parent's. The store, task executor, and task manager stay shared.
+func (c ToolContext) Clone() ToolContext {
+	clone := c
+	clone.Sandbox = c.Sandbox.Clone()
+	clone.TaskLineage = slices.Clone(c.TaskLineage)
+	return clone
+}
+
+// Progress is an incremental report from a running tool, such as bytes
+// downloaded or files scanned.
+type Progress struct {
+	// Current counts the work done so far, in Unit.
+	Current int64
+	// Total is the expected amount of work; zero when unknown.
+	Total int64
+	// Unit names what is counted: "bytes" or a plural noun such as "files".
+	Unit string
+}
+
+// Percent returns completion from 0 to 100, or -1 when the total is unknown.
+func (p Progress) Percent() int {
+	if p.Total <= 0 {
+		return -1
+	}
+	return int(min(p.Current, p.Total) * 100 / p.Total)
+}
+
+// String describes the progress, e.g. "1.5 MB of 2.0 MB" or "340 files".
+func (p Progress) String() string {
+	format := func(value int64) string {
+		return fmt.Sprintf("%d", value)
+	}
+	if p.Unit == "bytes" {
+		format = formatByteCount
+	}
+	if p.Total > 0 {
+		text := format(p.Current) + " of " + format(p.Total)
+		if p.Unit != "bytes" {
+			text += " " + p.Unit
+		}
+		return text
+	}
+	if p.Unit == "bytes" {
+		return format(p.Current)
+	}
+	return format(p.Current) + " " + p.Unit
+}
+
+// formatByteCount renders a byte count with a decimal unit.
+func formatByteCount(count int64) string {
+	switch {
+	case count >= 1_000_000_000:
+		return fmt.Sprintf("%.1f GB", float64(count)/1e9)
+	case count >= 1_000_000:
+		return fmt.Sprintf("%.1f MB", float64(count)/1e6)
+	case count >= 1_000:
+		return fmt.Sprintf("%.1f KB", float64(count)/1e3)
+	default:
+		return fmt.Sprintf("%d B", count)
+	}
+}
+
+// progressInterval is the minimum time between progress reports from one tool call.
+const progressInterval = 100 * time.Millisecond
+
+// progressReporter rate-limits a tool's progress reports.
+type progressReporter struct {
+	// report receives the reports; nil drops them.
+	report func(progress Progress)
+	// last is when the latest report was sent.
+	last time.Time
+}
+
+// newProgressReporter wraps the tool context's progress callback.
+func newProgressReporter(toolCtx ToolContext) *progressReporter {
+	return &progressReporter{report: toolCtx.Progress}
+}
+
+// update sends progress unless a report went out within progressInterval;
+// the final report of a known total is always sent.
+func (r *progressReporter) update(progress Progress) {
+	if r.report == nil {
+		return
+	}
+	now := time.Now()
+	finished := progress.Total > 0 && progress.Current >= progress.Total
+	if !finished && now.Sub(r.last) < progressInterval {
+		return
+	}
+	r.last = now
+	r.report(progress)
+}
+
+// outputStreamer forwards a running tool's output to ToolContext.Output in
+// batches of complete lines, at most once per progressInterval. It is safe for
+// concurrent writers such as a command's stdout and stderr.
+type outputStreamer struct {
+	// emit receives each batch.
+	emit func(text string)
+	// mu guards the fields below.
+	mu sync.Mutex
+	// partial holds an unterminated trailing line.
+	partial []byte
+	// pending holds complete lines not yet emitted.
+	pending []byte
+	// last is when the latest batch was emitted.
+	last time.Time
+}
+
+// newOutputStreamer wraps the tool context's output callback, returning nil
+// when there is none so callers can skip streaming entirely.
+func newOutputStreamer(toolCtx ToolContext) *outputStreamer {
+	if toolCtx.Output == nil {
+		return nil
+	}
+	return &outputStreamer{emit: toolCtx.Output}
+}
+
+// Write buffers p and emits the complete lines once progressInterval has passed.
+func (s *outputStreamer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.partial = append(s.partial, p...)
+	if cut := bytes.LastIndexByte(s.partial, '\n'); cut >= 0 {
+		s.pending = append(s.pending, s.partial[:cut+1]...)
+		s.partial = append(s.partial[:0], s.partial[cut+1:]...)
+	}
+	if len(s.pending) > 0 && time.Since(s.last) >= progressInterval {
+		s.flushLocked()
+	}
+	return len(p), nil
+}
+
+// Close emits whatever is buffered, including an unterminated last line.
+func (s *outputStreamer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, s.partial...)
+	s.partial = nil
+	s.flushLocked()
+	return nil
+}
+
+// flushLocked emits the pending lines; the caller holds mu.
+func (s *outputStreamer) flushLocked() {
+	if len(s.pending) == 0 {
+		return
+	}
+	s.last = time.Now()
+	text := string(s.pending)
+	s.pending = s.pending[:0]
+	s.emit(text)
 }
 
 // TaskRequest describes a subtask request issued via the Task tool.
@@ -36,15 +214,22 @@ type TaskRequest struct {
 	// Prompt holds a single user prompt for the task.
 	Prompt string
 	// Messages optionally provide a full message history for the task.
-	Messages []openai.Message
+	Messages []llm.Message
 	// SystemPrompt optionally overrides the default system prompt.
 	SystemPrompt string
 	// Model overrides the default model when provided.
 	Model string
+	// SubagentType names the agent definition (from .claude/agents) to run.
+	SubagentType string
 	// MaxTurns overrides the default turn limit for the task.
 	MaxTurns int
+	// MaxBudgetUSD caps the estimated cost of the task run (0 inherits the parent budget).
+	MaxBudgetUSD float64
 	// Metadata stores raw task payload fields for auditing.
 	Metadata map[string]any
+	// Lineage is the caller's TaskLineage plus this task's prompt fingerprint;
+	// executors pass it to the subagent's ToolContext.
+	Lineage []uint64
 }
 
 // TaskResult captures the output of a subtask execution.
@@ -53,6 +238,8 @@ type TaskResult struct {
 	Output string
 	// Metadata carries any extra metadata from execution.
 	Metadata map[string]any
+	// Usage reports model usage incurred by the task run, when known.
+	Usage *ToolUsage
 }
 
 // TaskExecutor runs subtasks for the Task tool.
@@ -74,6 +261,31 @@ type ToolResult struct {
 	Content string
 	// IsError reports whether the tool failed.
 	IsError bool
+	// Images holds image parts (e.g., from Read on a PNG) for vision models.
+	// Tool messages are text-only upstream, so the agent loop forwards them in a user message.
+	Images []llm.ContentPart
+	// Usage reports model usage incurred while running the tool (e.g., Task subagents).
+	// The agent loop rolls it into the parent run's cost and usage totals.
+	Usage *ToolUsage
+	// Stdout and Stderr keep a command's output streams apart for tools that
+	// run processes; Content remains the combined text sent to the model.
+	Stdout string
+	Stderr string
+	// ExitCode is the process exit status (-1 when it did not start); nil for
+	// tools that run no process.
+	ExitCode *int
+	// Interrupted reports a process stopped by cancellation or a timeout.
+	Interrupted bool
+	// Duration is how long the tool ran, set by Runner.Run.
+	Duration time.Duration
+}
+
+// ToolUsage captures model usage incurred by nested runs inside a tool.
+type ToolUsage struct {
+	// CostUSD is the estimated cost of the nested runs.
+	CostUSD float64
+	// ModelUsage breaks token usage down by model.
+	ModelUsage map[string]llm.Usage
 }
 
 // Tool defines a callable tool.
@@ -90,8 +302,19 @@ type Runner struct {
 	Tools map[string]Tool
 	// Order preserves the deterministic tool ordering for output payloads.
 	Order []string
+	// MaxResultBytes caps each tool result before it joins the conversation,
+	// keyed by tool name; the DefaultResultLimitKey entry covers unlisted
+	// tools. Zero or less disables the cap. Truncated results are saved in full
+	// as session artifacts.
+	MaxResultBytes map[string]int
+	// Redactor masks credentials in results before they are capped, saved,
+	// or sent upstream; nil leaves results as they are.
+	Redactor *redact.Redactor
 }
 
+// DefaultResultLimitKey is the MaxResultBytes entry for tools without their own.
+const DefaultResultLimitKey = "default"
+
 // NewRunner constructs a tool runner.
 func NewRunner(tools []Tool) *Runner {
 	toolMap := make(map[string]Tool, len(tools))
@@ -115,8 +338,8 @@ func NewRunner(tools []Tool) *Runner {
 }
 
 // ToolSpecs returns OpenAI-compatible tool definitions.
-func (r *Runner) ToolSpecs() []openai.Tool {
-	specs := make([]openai.Tool, 0, len(r.Tools))
+func (r *Runner) ToolSpecs() []llm.Tool {
+	specs := make([]llm.Tool, 0, len(r.Tools))
 	names := r.ToolNames()
 	if len(names) == 0 {
 		return specs
@@ -127,9 +350,9 @@ func (r *Runner) ToolSpecs() []openai.Tool {
 		if !ok {
 			continue
 		}
-		specs = append(specs, openai.Tool{
+		specs = append(specs, llm.Tool{
 			Type: "function",
-			Function: openai.ToolFunction{
+			Function: llm.ToolFunction{
 				Name:        tool.Name(),
 				Description: tool.Description(),
 				Parameters:  tool.Schema(),
@@ -167,7 +390,29 @@ func (r *Runner) Run(ctx context.Context, name string, args json.RawMessage, too
 	if !ok {
 		return ToolResult{IsError: true, Content: fmt.Sprintf("tool not found: %s", name)}, nil
 	}
-	return tool.Run(ctx, args, toolCtx)
+	start := time.Now()
+	result, err := tool.Run(ctx, args, toolCtx)
+	result.Duration = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	result.Content = r.Redactor.String(result.Content)
+	result.Stdout = r.Redactor.String(result.Stdout)
+	result.Stderr = r.Redactor.String(result.Stderr)
+	limit := r.resultLimit(name)
+	result.Content = truncateToolOutput(toolCtx, name, result.Content, limit)
+	// The full streams are already saved with Content, so they are only capped.
+	result.Stdout = truncateToolOutput(ToolContext{}, name, result.Stdout, limit)
+	result.Stderr = truncateToolOutput(ToolContext{}, name, result.Stderr, limit)
+	return result, nil
+}
+
+// resultLimit returns the MaxResultBytes entry for a tool, falling back to the default.
+func (r *Runner) resultLimit(name string) int {
+	if limit, ok := r.MaxResultBytes[name]; ok {
+		return limit
+	}
+	return r.MaxResultBytes[DefaultResultLimitKey]
 }
 
 // FilterTools applies allow/deny constraints.
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/session"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestOutputStreamerBatchesLines verifies only complete lines are emitted while
+// writing and the unterminated tail is emitted on close.
+func TestOutputStreamerBatchesLines(testingHandle *testing.T) {
+	var batches []string
+	streamer := newOutputStreamer(ToolContext{Output: func(text string) { batches = append(batches, text) }})
+
+	_, _ = streamer.Write([]byte("first\nsec"))
+	_, _ = streamer.Write([]byte("ond\nthird"))
+	streamer.Close()
+
+	testutil.RequireEqual(testingHandle, batches[0], "first\n", "first batch is the complete line")
+	testutil.RequireEqual(testingHandle, strings.Join(batches, ""), "first\nsecond\nthird", "everything emitted in order")
+	testutil.RequireTrue(testingHandle, newOutputStreamer(ToolContext{}) == nil, "no callback, no streamer")
+}
+
+// TestToolsStreamOutput verifies Bash and Grep send their output while running
+// and still return all of it in the result.
+func TestToolsStreamOutput(testingHandle *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		testingHandle.Skip("bash not installed")
+	}
+	root := testingHandle.TempDir()
+	testutil.RequireNoError(testingHandle, os.WriteFile(filepath.Join(root, "a.txt"), []byte("needle one\nhay\nneedle two\n"), 0o600), "write file")
+	var mu sync.Mutex
+	var streamed strings.Builder
+	toolCtx := ToolContext{Sandbox: NewSandbox([]string{root}), CWD: root, Output: func(text string) {
+		mu.Lock()
+		defer mu.Unlock()
+		streamed.WriteString(text)
+	}}
+
+	bashInput, _ := json.Marshal(map[string]string{"command": "echo out; echo err >&2"})
+	bashResult, bashErr := (&BashTool{}).Run(context.Background(), bashInput, toolCtx)
+	bashStreamed := streamed.String()
+	streamed.Reset()
+	grepInput, _ := json.Marshal(map[string]string{"query": "needle"})
+	grepResult, grepErr := (&GrepTool{}).Run(context.Background(), grepInput, toolCtx)
+
+	testutil.RequireNoError(testingHandle, bashErr, "run bash")
+	testutil.RequireTrue(testingHandle, strings.HasPrefix(bashResult.Content, "out\n") && strings.HasSuffix(bashResult.Content, "\nerr"), "bash result: "+bashResult.Content)
+	testutil.RequireTrue(testingHandle, strings.Contains(bashStreamed, "out\n") && strings.Contains(bashStreamed, "err\n"), "bash streamed: "+bashStreamed)
+	testutil.RequireNoError(testingHandle, grepErr, "run grep")
+	testutil.RequireEqual(testingHandle, streamed.String(), grepResult.Content+"\n", "grep streamed every match")
+}
+
+// TestBashSeparatesOutputStreams verifies Bash results keep stdout, stderr,
+// and the exit code apart from the combined content, and Runner times the call.
+func TestBashSeparatesOutputStreams(testingHandle *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		testingHandle.Skip("bash not installed")
+	}
+	// Arrange a command that writes to both streams and exits non-zero.
+	root := testingHandle.TempDir()
+	runner := NewRunner([]Tool{&BashTool{}})
+	input, _ := json.Marshal(map[string]string{"command": "echo out; echo err >&2; exit 3"})
+
+	// Act
+	result, err := runner.Run(context.Background(), "Bash", input, ToolContext{Sandbox: NewSandbox([]string{root}), CWD: root})
+
+	// Assert
+	testutil.RequireNoError(testingHandle, err, "run bash")
+	testutil.RequireTrue(testingHandle, result.IsError, "non-zero exit is an error")
+	testutil.RequireEqual(testingHandle, result.Stdout, "out\n", "stdout")
+	// Login shell profiles may write to stderr first.
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(result.Stderr, "err\n") && !strings.Contains(result.Stderr, "out"), "stderr: "+result.Stderr)
+	testutil.RequireTrue(testingHandle, result.ExitCode != nil && *result.ExitCode == 3, "exit code")
+	testutil.RequireTrue(testingHandle, !result.Interrupted, "not interrupted")
+	testutil.RequireTrue(testingHandle, result.Duration > 0, "duration recorded")
+	testutil.RequireTrue(testingHandle, strings.Contains(result.Content, "\nout\n") && strings.HasSuffix(result.Content, "\nerr"), "combined content: "+result.Content)
+}
+
+// fixedOutputTool returns the same content on every call.
+type fixedOutputTool struct {
+	name    string
+	content string
+}
+
+func (t *fixedOutputTool) Name() string           { return t.name }
+func (t *fixedOutputTool) Description() string    { return "fixed output" }
+func (t *fixedOutputTool) Schema() map[string]any { return map[string]any{"type": "object"} }
+func (t *fixedOutputTool) Run(context.Context, json.RawMessage, ToolContext) (ToolResult, error) {
+	return ToolResult{Content: t.content}, nil
+}
+
+// TestRunnerCapsToolResults verifies per-tool result budgets, line-aligned
+// truncation, and the artifact holding the full result.
+func TestRunnerCapsToolResults(testingHandle *testing.T) {
+	// Arrange 100 numbered lines of 10 bytes each.
+	var builder strings.Builder
+	for line := 1; line <= 100; line++ {
+		fmt.Fprintf(&builder, "line %04d\n", line)
+	}
+	content := builder.String()
+	store := &session.Store{BaseDir: testingHandle.TempDir()}
+	toolCtx := ToolContext{Store: store, SessionID: "s1"}
+	runner := NewRunner([]Tool{&fixedOutputTool{name: "Grep", content: content}, &fixedOutputTool{name: "Read", content: content}, &fixedOutputTool{name: "Glob", content: content}})
+	runner.MaxResultBytes = map[string]int{DefaultResultLimitKey: 255, "Read": 0, "Glob": 2000}
+
+	// Act
+	grep, err := runner.Run(context.Background(), "Grep", nil, toolCtx)
+	testutil.RequireNoError(testingHandle, err, "run grep")
+	read, err := runner.Run(context.Background(), "Read", nil, toolCtx)
+	testutil.RequireNoError(testingHandle, err, "run read")
+	glob, err := runner.Run(context.Background(), "Glob", nil, toolCtx)
+	testutil.RequireNoError(testingHandle, err, "run glob")
+
+	// Assert
+	shown, marker, _ := strings.Cut(grep.Content, "\n...[truncated; ")
+	testutil.RequireEqual(testingHandle, shown, content[:249], "cut after line 25")
+	testutil.RequireTrue(testingHandle, strings.HasSuffix(marker, "; Read it with offset=26 to continue]"), "marker: "+marker)
+	testutil.RequireEqual(testingHandle, read.Content, content, "zero disables the cap")
+	testutil.RequireEqual(testingHandle, glob.Content, content, "per-tool limit overrides the default")
+	artifacts, err := store.ListArtifacts("s1")
+	testutil.RequireNoError(testingHandle, err, "list artifacts")
+	testutil.RequireEqual(testingHandle, len(artifacts), 1, "one artifact")
+	data, err := os.ReadFile(artifacts[0].Path)
+	testutil.RequireNoError(testingHandle, err, "read artifact")
+	testutil.RequireEqual(testingHandle, string(data), content, "full result kept")
+}
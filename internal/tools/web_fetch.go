@@ -65,9 +65,6 @@ func (t *WebFetchTool) Schema() map[string]any {
 
 // Run validates the payload, performs the request, and returns the response body.
 func (t *WebFetchTool) Run(ctx context.Context, input json.RawMessage, toolCtx ToolContext) (ToolResult, error) {
-	// The tool is synchronous, so the context is only used for cancellation.
-	_ = toolCtx
-
 	var payload struct {
 		URL       string            `json:"url"`
 		Method    string            `json:"method"`
@@ -126,7 +123,11 @@ func (t *WebFetchTool) Run(ctx context.Context, input json.RawMessage, toolCtx T
 	}
 	defer resp.Body.Close()
 
-	body, truncated, readErr := readLimitedBody(resp.Body, maxBytes)
+	download := &progressReader{reader: resp.Body, progress: newProgressReporter(toolCtx)}
+	if resp.ContentLength > 0 {
+		download.total = min(resp.ContentLength, maxBytes)
+	}
+	body, truncated, readErr := readLimitedBody(download, maxBytes)
 	if readErr != nil {
 		return ToolResult{IsError: true, Content: fmt.Sprintf("read body: %v", readErr)}, nil
 	}
@@ -166,6 +167,28 @@ func readLimitedBody(reader io.Reader, maxBytes int64) (string, bool, error) {
 	return string(data), truncated, nil
 }
 
+// progressReader reports the bytes read from a response body.
+type progressReader struct {
+	// reader is the underlying body.
+	reader io.Reader
+	// progress receives byte counts.
+	progress *progressReporter
+	// read counts bytes read so far.
+	read int64
+	// total is the expected size; zero when the server sent no Content-Length.
+	total int64
+}
+
+// Read reads from the body and reports progress.
+func (r *progressReader) Read(buffer []byte) (int, error) {
+	count, err := r.reader.Read(buffer)
+	if count > 0 {
+		r.read += int64(count)
+		r.progress.update(Progress{Current: r.read, Total: r.total, Unit: "bytes"})
+	}
+	return count, err
+}
+
 // containsNullByte detects likely binary payloads by scanning for NULs.
 func containsNullByte(payload string) bool {
 	return strings.ContainsRune(payload, '\x00')
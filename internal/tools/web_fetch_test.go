@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -83,3 +85,38 @@ func TestWebFetchToolTruncates(testingHandle *testing.T) {
 		testingHandle.Fatalf("unexpected truncated output: %s", result.Content)
 	}
 }
+
+// TestWebFetchToolReportsProgress verifies byte progress against Content-Length.
+func TestWebFetchToolReportsProgress(testingHandle *testing.T) {
+	body := strings.Repeat("x", 4000)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = writer.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	var reports []Progress
+	toolCtx := ToolContext{Progress: func(progress Progress) { reports = append(reports, progress) }}
+	payload, err := json.Marshal(map[string]any{"url": server.URL})
+	if err != nil {
+		testingHandle.Fatalf("marshal payload: %v", err)
+	}
+	result, runErr := (&WebFetchTool{}).Run(context.Background(), payload, toolCtx)
+	if runErr != nil || result.IsError {
+		testingHandle.Fatalf("run tool: %v %s", runErr, result.Content)
+	}
+
+	if len(reports) == 0 {
+		testingHandle.Fatalf("expected progress reports")
+	}
+	last := reports[len(reports)-1]
+	if last != (Progress{Current: 4000, Total: 4000, Unit: "bytes"}) || last.Percent() != 100 {
+		testingHandle.Fatalf("unexpected final progress: %+v", last)
+	}
+	if text := last.String(); text != "4.0 KB of 4.0 KB" {
+		testingHandle.Fatalf("unexpected progress text: %q", text)
+	}
+	if text := (Progress{Current: 340, Unit: "files"}).String(); text != "340 files" {
+		testingHandle.Fatalf("unexpected count text: %q", text)
+	}
+}
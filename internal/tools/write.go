@@ -67,7 +67,7 @@ func (t *WriteTool) Run(ctx context.Context, input json.RawMessage, toolCtx Tool
 	}
 
 	// Validate the write path against the sandbox rules.
-	path, err := toolCtx.Sandbox.ResolvePath(payload.FilePath, false)
+	path, err := toolCtx.Sandbox.ResolveWritePath(payload.FilePath, false)
 	if err != nil {
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
@@ -89,11 +89,21 @@ func (t *WriteTool) Run(ctx context.Context, input json.RawMessage, toolCtx Tool
 			return ToolResult{IsError: true, Content: "path is a directory"}, nil
 		}
 		mode = info.Mode().Perm()
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			return ToolResult{IsError: true, Content: err.Error()}, nil
+		}
+		if err := checkRedactionMasks(string(existing), payload.Content); err != nil {
+			return ToolResult{IsError: true, Content: err.Error()}, nil
+		}
 		if err := backupFile(toolCtx, path); err != nil {
 			return ToolResult{IsError: true, Content: fmt.Sprintf("backup failed: %v", err)}, nil
 		}
 	case os.IsNotExist(err):
 		// Use default file mode for newly created files.
+		if err := checkRedactionMasks("", payload.Content); err != nil {
+			return ToolResult{IsError: true, Content: err.Error()}, nil
+		}
 	default:
 		return ToolResult{IsError: true, Content: err.Error()}, nil
 	}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaude/openclaude/internal/redact"
+	"github.com/openclaude/openclaude/internal/testutil"
+)
+
+// TestFileToolsKeepRedactedSecrets verifies a read-modify-write of a file with a
+// credential cannot replace the credential with its mask, while edits that
+// leave the masked line alone still apply.
+func TestFileToolsKeepRedactedSecrets(testingHandle *testing.T) {
+	root := testingHandle.TempDir()
+	path := filepath.Join(root, ".env")
+	secret := "sk-" + strings.Repeat("b", 32)
+	original := "OPENAI_API_KEY=" + secret + "\nDEBUG=false\n"
+	testutil.RequireNoError(testingHandle, os.WriteFile(path, []byte(original), 0o600), "write env")
+
+	redactor, err := redact.New(nil)
+	testutil.RequireNoError(testingHandle, err, "build redactor")
+	runner := NewRunner([]Tool{&ReadTool{}, &WriteTool{}, &EditTool{}})
+	runner.Redactor = redactor
+	toolCtx := ToolContext{Sandbox: NewSandbox([]string{root}), CWD: root}
+	run := func(name string, payload map[string]any) ToolResult {
+		input, _ := json.Marshal(payload)
+		result, err := runner.Run(context.Background(), name, input, toolCtx)
+		testutil.RequireNoError(testingHandle, err, "run "+name)
+		return result
+	}
+
+	read := run("Read", map[string]any{"file_path": path})
+	testutil.RequireTrue(testingHandle, strings.Contains(read.Content, "OPENAI_API_KEY=[REDACTED:api-key]"), "read masks the key")
+	testutil.RequireTrue(testingHandle, !strings.Contains(read.Content, secret), "read hides the key")
+
+	// The model rewrites the file from what it saw, mask included.
+	rewritten := "OPENAI_API_KEY=[REDACTED:api-key]\nDEBUG=true\n"
+	write := run("Write", map[string]any{"file_path": path, "content": rewritten})
+	testutil.RequireTrue(testingHandle, write.IsError, "write with a mask fails")
+	edit := run("Edit", map[string]any{"file_path": path, "old_string": "DEBUG=false", "new_string": "DEBUG=true\nOPENAI_API_KEY=[REDACTED:api-key]"})
+	testutil.RequireTrue(testingHandle, edit.IsError, "edit adding a mask fails")
+	data, err := os.ReadFile(path)
+	testutil.RequireNoError(testingHandle, err, "read env")
+	testutil.RequireEqual(testingHandle, string(data), original, "file untouched")
+
+	edit = run("Edit", map[string]any{"file_path": path, "old_string": "DEBUG=false", "new_string": "DEBUG=true"})
+	testutil.RequireTrue(testingHandle, !edit.IsError, "edit around the secret applies")
+	data, err = os.ReadFile(path)
+	testutil.RequireNoError(testingHandle, err, "read env")
+	testutil.RequireEqual(testingHandle, string(data), "OPENAI_API_KEY="+secret+"\nDEBUG=true\n", "secret kept")
+}